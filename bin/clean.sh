@@ -1557,6 +1557,23 @@ main() {
                 manage_whitelist "clean"
                 exit 0
                 ;;
+            "--rules")
+                # The YAML rules engine lives in the Go binary; hand it the
+                # rest of the command line.
+                shift
+                local go_bin="$SCRIPT_DIR/status-go"
+                if [[ ! -x "$go_bin" ]]; then
+                    echo "Bundled status binary not found. Please reinstall Mole or run mo update to restore it." >&2
+                    exit 1
+                fi
+                if [[ "$DRY_RUN" == "true" ]]; then
+                    set -- --dry-run "$@"
+                fi
+                # Deletions go back through mole_delete in this lib.
+                MOLE_LIB_DIR="$(cd "$SCRIPT_DIR/../lib" && pwd)"
+                export MOLE_LIB_DIR
+                exec "$go_bin" mole clean "$@"
+                ;;
             "--select" | "--categories" | "--exclude")
                 echo "mo clean $1 was removed in this release." >&2
                 echo "Use 'mo clean --dry-run' to preview cleanup and 'mo clean --whitelist' to protect paths." >&2
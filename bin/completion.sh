@@ -11,10 +11,42 @@ for entry in "${MOLE_COMMANDS[@]}"; do
     command_names+=("${entry%%:*}")
 done
 command_words="${command_names[*]}"
-clean_option_words="--dry-run -n --external --whitelist --debug --help -h"
+clean_option_words="--dry-run -n --external --whitelist --rules --debug --help -h"
 analyze_option_words="--json --help -h"
+status_option_words="--json --watch --help -h"
+clean_rules_option_words="--dry-run --yes --safety --only --list --json --units --debug --profile --help -h"
+
+# The Go binary lists the real analyze and status flags, so completions
+# follow new options without editing this file.
+go_bin="$SCRIPT_DIR/status-go"
+if [[ -x "$go_bin" ]]; then
+    go_flags="$("$go_bin" __flags analyze 2> /dev/null | tr '\n' ' ')"
+    [[ -n "$go_flags" ]] && analyze_option_words="${go_flags}--help -h"
+    go_flags="$("$go_bin" __flags status 2> /dev/null | tr '\n' ' ')"
+    [[ -n "$go_flags" ]] && status_option_words="${go_flags}--help -h"
+    go_flags="$("$go_bin" __flags clean 2> /dev/null | tr '\n' ' ')"
+    [[ -n "$go_flags" ]] && clean_rules_option_words="${go_flags}--help -h"
+fi
+
+# Profile names and the folders saved as analyze targets (bookmarks) are
+# read from config.toml when Tab is pressed rather than baked in here, so
+# one added later completes without regenerating.
+profiles_command="true"
+bookmarks_command="true"
+if [[ -x "$go_bin" ]]; then
+    profiles_command="$(printf '%q' "$go_bin") __profiles 2> /dev/null"
+    bookmarks_command="$(printf '%q' "$go_bin") __bookmarks 2> /dev/null"
+fi
 history_option_words="--json --limit --help -h"
 purge_option_words="--paths --dry-run -n --include-empty --debug --help -h"
+orphans_option_words="--list --dry-run -n --permanent --debug --help -h"
+screenshots_option_words="--list --archive-dir --dry-run -n --permanent --debug --help -h"
+doctor_option_words="--json --output --help -h"
+serve_option_words="--stdio --help -h"
+mcp_option_words="--help -h"
+helper_option_words="install uninstall status log size remove --help -h"
+config_option_words="path list get set --profile --help -h"
+cache_option_words="ls clear stats --help -h"
 
 emit_zsh_subcommands() {
     for entry in "${MOLE_COMMANDS[@]}"; do
@@ -22,6 +54,20 @@ emit_zsh_subcommands() {
     done
 }
 
+# emit_fish_flags completes the long flags in a word list, for commands
+# whose flags come from the Go binary and carry no description here.
+emit_fish_flags() {
+    local cmd="$1" subcommands="$2" word
+    for word in $3; do
+        case "$word" in
+            --help | --profile) ;;
+            --*)
+                printf 'complete -f -c %s -n "__fish_seen_subcommand_from %s" -l %s\n' "$cmd" "$subcommands" "${word#--}"
+                ;;
+        esac
+    done
+}
+
 emit_fish_completions() {
     local cmd="$1"
     for entry in "${MOLE_COMMANDS[@]}"; do
@@ -34,11 +80,13 @@ emit_fish_completions() {
     printf 'complete -f -c %s -n "__fish_seen_subcommand_from clean" -l dry-run -s n -d "Preview cleanup without making changes"\n' "$cmd"
     printf 'complete -c %s -n "__fish_seen_subcommand_from clean" -l external -r -a "(__fish_complete_directories)" -d "Clean OS metadata from an external volume"\n' "$cmd"
     printf 'complete -f -c %s -n "__fish_seen_subcommand_from clean" -l whitelist -d "Manage protected paths"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from clean" -l rules -d "Run the YAML cleanup rules"\n' "$cmd"
     printf 'complete -f -c %s -n "__fish_seen_subcommand_from clean" -l debug -d "Show detailed logs"\n' "$cmd"
     printf 'complete -f -c %s -n "__fish_seen_subcommand_from clean" -l help -s h -d "Show help"\n' "$cmd"
     printf 'complete -f -c %s -n "__fish_seen_subcommand_from analyze analyse" -l json -d "Output analysis as JSON"\n' "$cmd"
     printf 'complete -f -c %s -n "__fish_seen_subcommand_from analyze analyse" -l help -s h -d "Show help"\n' "$cmd"
     printf 'complete -c %s -n "__fish_seen_subcommand_from analyze analyse; and not __fish_seen_argument -l json -l help -s h" -a "(__fish_complete_directories)" -d "Path to analyze"\n' "$cmd"
+    printf 'complete -c %s -n "__fish_seen_subcommand_from analyze analyse; and not __fish_seen_argument -l json -l help -s h" -a "(%s)" -d "Saved folder"\n' "$cmd" "$bookmarks_command"
     printf 'complete -f -c %s -n "__fish_seen_subcommand_from history" -l json -d "Output history as JSON"\n' "$cmd"
     printf 'complete -f -c %s -n "__fish_seen_subcommand_from history" -l limit -r -d "Limit recent entries"\n' "$cmd"
     printf 'complete -f -c %s -n "__fish_seen_subcommand_from history" -l help -s h -d "Show help"\n' "$cmd"
@@ -47,17 +95,40 @@ emit_fish_completions() {
     printf 'complete -f -c %s -n "__fish_seen_subcommand_from purge" -l include-empty -d "Show zero-size project artifact directories"\n' "$cmd"
     printf 'complete -f -c %s -n "__fish_seen_subcommand_from purge" -l debug -d "Show detailed logs"\n' "$cmd"
     printf 'complete -f -c %s -n "__fish_seen_subcommand_from purge" -l help -s h -d "Show help"\n' "$cmd"
+    emit_fish_flags "$cmd" status "$status_option_words"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from status" -l help -s h -d "Show help"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from orphans" -l list -d "List orphaned data without prompting"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from orphans" -l dry-run -s n -d "Preview removal without making changes"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from orphans" -l permanent -d "Bypass macOS Trash"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from orphans" -l debug -d "Show detailed logs"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from orphans" -l help -s h -d "Show help"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from screenshots" -l list -d "List captures without prompting"\n' "$cmd"
+    printf 'complete -c %s -n "__fish_seen_subcommand_from screenshots" -l archive-dir -r -a "(__fish_complete_directories)" -d "Archive into this folder"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from screenshots" -l dry-run -s n -d "Preview the move without making changes"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from screenshots" -l permanent -d "Bypass macOS Trash"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from screenshots" -l debug -d "Show detailed logs"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from screenshots" -l help -s h -d "Show help"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from doctor" -l json -d "Output checks as JSON"\n' "$cmd"
+    printf 'complete -c %s -n "__fish_seen_subcommand_from doctor" -l output -r -d "Write the report to a file"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from doctor" -l help -s h -d "Show help"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from serve" -l stdio -d "Speak JSON-RPC on stdin and stdout"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from mcp" -l help -s h -d "Show help"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from helper; and not __fish_seen_subcommand_from install uninstall status log size remove" -a "install uninstall status log size remove"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from config; and not __fish_seen_subcommand_from path list get set" -a "path list get set"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from cache; and not __fish_seen_subcommand_from ls clear stats" -a "ls clear stats"\n' "$cmd"
+    printf 'complete -f -c %s -n "__fish_seen_subcommand_from analyze analyse status clean config" -l profile -r -a "(%s)" -d "Apply a profile from config.toml"\n' "$cmd" "$profiles_command"
     printf '\n'
     printf 'complete -f -c %s -n "not __fish_mole_no_subcommand" -a bash -d "generate bash completion" -n "__fish_see_subcommand_path completion"\n' "$cmd"
     printf 'complete -f -c %s -n "not __fish_mole_no_subcommand" -a zsh -d "generate zsh completion" -n "__fish_see_subcommand_path completion"\n' "$cmd"
     printf 'complete -f -c %s -n "not __fish_mole_no_subcommand" -a fish -d "generate fish completion" -n "__fish_see_subcommand_path completion"\n' "$cmd"
+    printf 'complete -f -c %s -n "not __fish_mole_no_subcommand" -l aliases -d "print short command aliases" -n "__fish_see_subcommand_path completion"\n' "$cmd"
 }
 
 remove_stale_completion_entries() {
     local config_file="$1"
     local success_message="$2"
 
-    if [[ ! -f "$config_file" ]] || ! grep -Eq "(^# Mole shell completion$|(mole|mo)[[:space:]]+completion)" "$config_file" 2> /dev/null; then
+    if [[ ! -f "$config_file" ]] || ! grep -Eq "(^# Mole shell completion$|(mole|mo)[[:space:]]+completion[[:space:]]+(bash|zsh|fish))" "$config_file" 2> /dev/null; then
         return 1
     fi
 
@@ -65,7 +136,7 @@ remove_stale_completion_entries() {
     local temp_file
     original_mode="$(stat -f '%Mp%Lp' "$config_file" 2> /dev/null || true)"
     temp_file="$(mktemp)"
-    grep -Ev "(^# Mole shell completion$|(mole|mo)[[:space:]]+completion)" "$config_file" > "$temp_file" || true
+    grep -Ev "(^# Mole shell completion$|(mole|mo)[[:space:]]+completion[[:space:]]+(bash|zsh|fish))" "$config_file" > "$temp_file" || true
     mv "$temp_file" "$config_file"
     [[ -n "$original_mode" ]] && chmod "$original_mode" "$config_file" 2> /dev/null || true
     [[ -n "$success_message" ]] && echo -e "${GREEN}${ICON_SUCCESS}${NC} $success_message"
@@ -79,6 +150,9 @@ if [[ $# -gt 0 ]]; then
             "--dry-run" | "-n")
                 export MOLE_DRY_RUN=1
                 ;;
+            "--aliases")
+                emit_aliases=1
+                ;;
             *)
                 normalized_args+=("$arg")
                 ;;
@@ -91,6 +165,65 @@ if [[ $# -gt 0 ]]; then
     fi
 fi
 
+# Short aliases for the everyday commands. Each one is skipped in a shell
+# where the name is already taken, and completes like the command it
+# stands for.
+MOLE_ALIASES=(
+    "moc:clean"
+    "moa:analyze"
+    "mos:status"
+    "mou:uninstall"
+    "moo:optimize"
+    "mop:purge"
+)
+
+emit_alias_definitions() {
+    local entry name command
+    case "$1" in
+        bash)
+            for entry in "${MOLE_ALIASES[@]}"; do
+                name="${entry%%:*}"
+                command="${entry#*:}"
+                printf 'if ! type %s > /dev/null 2>&1; then\n' "$name"
+                printf "    alias %s='mo %s'\n" "$name" "$command"
+                printf '    _mole_alias_%s() {\n' "$name"
+                printf '        declare -F _mole_completions > /dev/null || return 0\n'
+                printf '        COMP_WORDS=(mo %s "${COMP_WORDS[@]:1}")\n' "$command"
+                printf '        COMP_CWORD=$((COMP_CWORD + 1))\n'
+                printf '        _mole_completions\n'
+                printf '    }\n'
+                printf '    complete -F _mole_alias_%s %s\n' "$name" "$name"
+                printf 'fi\n'
+            done
+            ;;
+        zsh)
+            # zsh expands an alias before completing it, so no wrapper is needed.
+            for entry in "${MOLE_ALIASES[@]}"; do
+                name="${entry%%:*}"
+                printf "(( \${+commands[%s]} || \${+aliases[%s]} )) || alias %s='mo %s'\n" "$name" "$name" "$name" "${entry#*:}"
+            done
+            ;;
+        fish)
+            for entry in "${MOLE_ALIASES[@]}"; do
+                name="${entry%%:*}"
+                printf "type -q %s; or abbr -a %s 'mo %s'\n" "$name" "$name" "${entry#*:}"
+            done
+            ;;
+        *)
+            return 1
+            ;;
+    esac
+}
+
+if [[ "${emit_aliases:-0}" == "1" ]]; then
+    alias_shell="${1:-${SHELL##*/}}"
+    if ! emit_alias_definitions "$alias_shell"; then
+        log_error "Unsupported shell for aliases: ${alias_shell:-unknown}, use bash, zsh, or fish"
+        exit 1
+    fi
+    exit 0
+fi
+
 # Auto-install mode when run without arguments
 if [[ $# -eq 0 ]]; then
     if [[ "${MOLE_DRY_RUN:-0}" == "1" ]]; then
@@ -200,7 +333,7 @@ if [[ $# -eq 0 ]]; then
     esac
 
     if [[ -z "$completion_name" ]]; then
-        if [[ -f "$config_file" ]] && grep -Eq "(^# Mole shell completion$|(mole|mo)[[:space:]]+completion)" "$config_file" 2> /dev/null; then
+        if [[ -f "$config_file" ]] && grep -Eq "(^# Mole shell completion$|(mole|mo)[[:space:]]+completion[[:space:]]+(bash|zsh|fish))" "$config_file" 2> /dev/null; then
             if [[ "${MOLE_DRY_RUN:-0}" == "1" ]]; then
                 echo -e "${GRAY}${ICON_REVIEW} [DRY RUN] Would remove stale completion entries from $config_file${NC}"
                 echo ""
@@ -208,7 +341,7 @@ if [[ $# -eq 0 ]]; then
                 original_mode=""
                 original_mode="$(stat -f '%Mp%Lp' "$config_file" 2> /dev/null || true)"
                 temp_file="$(mktemp)"
-                grep -Ev "(^# Mole shell completion$|(mole|mo)[[:space:]]+completion)" "$config_file" > "$temp_file" || true
+                grep -Ev "(^# Mole shell completion$|(mole|mo)[[:space:]]+completion[[:space:]]+(bash|zsh|fish))" "$config_file" > "$temp_file" || true
                 mv "$temp_file" "$config_file"
                 if [[ -n "$original_mode" ]]; then
                     chmod "$original_mode" "$config_file" 2> /dev/null || true
@@ -222,7 +355,7 @@ if [[ $# -eq 0 ]]; then
     fi
 
     # Check if already installed and normalize to latest line
-    if [[ -f "$config_file" ]] && grep -Eq "(mole|mo)[[:space:]]+completion" "$config_file" 2> /dev/null; then
+    if [[ -f "$config_file" ]] && grep -Eq "(mole|mo)[[:space:]]+completion[[:space:]]+(bash|zsh|fish)" "$config_file" 2> /dev/null; then
         if [[ "${MOLE_DRY_RUN:-0}" == "1" ]]; then
             echo -e "${GRAY}${ICON_REVIEW} [DRY RUN] Would normalize completion entry in $config_file${NC}"
             echo ""
@@ -232,7 +365,7 @@ if [[ $# -eq 0 ]]; then
         original_mode=""
         original_mode="$(stat -f '%Mp%Lp' "$config_file" 2> /dev/null || true)"
         temp_file="$(mktemp)"
-        grep -Ev "(^# Mole shell completion$|(mole|mo)[[:space:]]+completion)" "$config_file" > "$temp_file" || true
+        grep -Ev "(^# Mole shell completion$|(mole|mo)[[:space:]]+completion[[:space:]]+(bash|zsh|fish))" "$config_file" > "$temp_file" || true
         mv "$temp_file" "$config_file"
         if [[ -n "$original_mode" ]]; then
             chmod "$original_mode" "$config_file" 2> /dev/null || true
@@ -286,7 +419,7 @@ if [[ $# -eq 0 ]]; then
         original_mode=""
         original_mode="$(stat -f '%Mp%Lp' "$config_file" 2> /dev/null || true)"
         temp_file="$(mktemp)"
-        grep -Ev "(^# Mole shell completion$|(mole|mo)[[:space:]]+completion)" "$config_file" > "$temp_file" || true
+        grep -Ev "(^# Mole shell completion$|(mole|mo)[[:space:]]+completion[[:space:]]+(bash|zsh|fish))" "$config_file" > "$temp_file" || true
         mv "$temp_file" "$config_file"
         if [[ -n "$original_mode" ]]; then
             chmod "$original_mode" "$config_file" 2> /dev/null || true
@@ -320,6 +453,8 @@ _mole_completions()
 
     if [ "\$COMP_CWORD" -eq 1 ]; then
         COMPREPLY=( \$(compgen -W "$command_words" -- "\$cur_word") )
+    elif [ "\$prev_word" = "--profile" ]; then
+        COMPREPLY=( \$(compgen -W "\$($profiles_command)" -- "\$cur_word") )
     else
         case "\$subcommand" in
             clean)
@@ -327,8 +462,15 @@ _mole_completions()
                     --external)
                         COMPREPLY=( \$(compgen -d -- "\$cur_word") )
                         ;;
+                    --safety)
+                        COMPREPLY=( \$(compgen -W "safe moderate risky" -- "\$cur_word") )
+                        ;;
                     *)
-                        COMPREPLY=( \$(compgen -W "$clean_option_words" -- "\$cur_word") )
+                        if [[ " \${COMP_WORDS[*]} " == *" --rules "* ]]; then
+                            COMPREPLY=( \$(compgen -W "$clean_rules_option_words" -- "\$cur_word") )
+                        else
+                            COMPREPLY=( \$(compgen -W "$clean_option_words" -- "\$cur_word") )
+                        fi
                         ;;
                 esac
                 ;;
@@ -336,17 +478,44 @@ _mole_completions()
                 if [[ "\$cur_word" == -* ]]; then
                     COMPREPLY=( \$(compgen -W "$analyze_option_words" -- "\$cur_word") )
                 else
-                    COMPREPLY=( \$(compgen -f -- "\$cur_word") )
+                    COMPREPLY=( \$(compgen -W "\$($bookmarks_command)" -- "\$cur_word") \$(compgen -f -- "\$cur_word") )
                 fi
                 ;;
+            status)
+                COMPREPLY=( \$(compgen -W "$status_option_words" -- "\$cur_word") )
+                ;;
             history)
                 COMPREPLY=( \$(compgen -W "$history_option_words" -- "\$cur_word") )
                 ;;
             purge)
                 COMPREPLY=( \$(compgen -W "$purge_option_words" -- "\$cur_word") )
                 ;;
+            orphans)
+                COMPREPLY=( \$(compgen -W "$orphans_option_words" -- "\$cur_word") )
+                ;;
+            screenshots)
+                COMPREPLY=( \$(compgen -W "$screenshots_option_words" -- "\$cur_word") )
+                ;;
+            doctor)
+                COMPREPLY=( \$(compgen -W "$doctor_option_words" -- "\$cur_word") )
+                ;;
+            config)
+                COMPREPLY=( \$(compgen -W "$config_option_words" -- "\$cur_word") )
+                ;;
+            cache)
+                COMPREPLY=( \$(compgen -W "$cache_option_words" -- "\$cur_word") )
+                ;;
+            serve)
+                COMPREPLY=( \$(compgen -W "$serve_option_words" -- "\$cur_word") )
+                ;;
+            mcp)
+                COMPREPLY=( \$(compgen -W "$mcp_option_words" -- "\$cur_word") )
+                ;;
+            helper)
+                COMPREPLY=( \$(compgen -W "$helper_option_words" -- "\$cur_word") )
+                ;;
             completion)
-                COMPREPLY=( \$(compgen -W "bash zsh fish" -- "\$cur_word") )
+                COMPREPLY=( \$(compgen -W "bash zsh fish --aliases" -- "\$cur_word") )
                 ;;
             *)
                 COMPREPLY=()
@@ -360,6 +529,12 @@ EOF
         ;;
     zsh)
         printf '#compdef mole mo\n\n'
+        printf '_mole_profiles() {\n'
+        printf '    compadd -- ${(f)"$(%s)"}\n' "$profiles_command"
+        printf '}\n\n'
+        printf '_mole_bookmarks() {\n'
+        printf '    compadd -- ${(f)"$(%s)"}\n' "$bookmarks_command"
+        printf '}\n\n'
         printf '_mole() {\n'
         printf '    local -a subcommands\n'
         printf '    subcommands=(\n'
@@ -369,6 +544,10 @@ EOF
         printf "        _describe 'subcommand' subcommands\n"
         printf '        return\n'
         printf '    fi\n'
+        printf "    if [[ \"\$words[CURRENT-1]\" == --profile ]]; then\n"
+        printf '        _mole_profiles\n'
+        printf '        return\n'
+        printf '    fi\n'
         printf "    case \"\$words[2]\" in\n"
         printf '        clean)\n'
         printf '            _arguments \\\n'
@@ -376,14 +555,16 @@ EOF
         printf "                '-n[Preview cleanup without making changes]' \\\\\n"
         printf "                '--external[Clean OS metadata from an external volume]:path:_files -/' \\\\\n"
         printf "                '--whitelist[Manage protected paths]' \\\\\n"
+        printf "                '--rules[Run the YAML cleanup rules]' \\\\\n"
         printf "                '--debug[Show detailed logs]' \\\\\n"
         printf "                '(-h --help)'{-h,--help}'[Show help]'\n"
         printf '            ;;\n'
         printf '        analyze|analyse)\n'
         printf '            _arguments \\\n'
         printf "                '--json[Output analysis as JSON]' \\\\\n"
+        printf "                '--profile[Apply a profile from config.toml]:profile:_mole_profiles' \\\\\n"
         printf "                '(-h --help)'{-h,--help}'[Show help]' \\\\\n"
-        printf "                '*:path:_files'\n"
+        printf "                '*:path:{_mole_bookmarks; _files}'\n"
         printf '            ;;\n'
         printf '        history)\n'
         printf '            _arguments \\\n'
@@ -400,8 +581,35 @@ EOF
         printf "                '--debug[Show detailed logs]' \\\\\n"
         printf "                '(-h --help)'{-h,--help}'[Show help]'\n"
         printf '            ;;\n'
+        printf '        status)\n'
+        printf '            compadd -- %s\n' "$status_option_words"
+        printf '            ;;\n'
+        printf '        config)\n'
+        printf '            compadd -- %s\n' "$config_option_words"
+        printf '            ;;\n'
+        printf '        cache)\n'
+        printf '            compadd -- %s\n' "$cache_option_words"
+        printf '            ;;\n'
+        printf '        orphans)\n'
+        printf '            compadd -- %s\n' "$orphans_option_words"
+        printf '            ;;\n'
+        printf '        screenshots)\n'
+        printf '            compadd -- %s\n' "$screenshots_option_words"
+        printf '            ;;\n'
+        printf '        doctor)\n'
+        printf '            compadd -- %s\n' "$doctor_option_words"
+        printf '            ;;\n'
+        printf '        serve)\n'
+        printf '            compadd -- %s\n' "$serve_option_words"
+        printf '            ;;\n'
+        printf '        mcp)\n'
+        printf '            compadd -- %s\n' "$mcp_option_words"
+        printf '            ;;\n'
+        printf '        helper)\n'
+        printf '            compadd -- %s\n' "$helper_option_words"
+        printf '            ;;\n'
         printf '        completion)\n'
-        printf "            _arguments '1:shell:(bash zsh fish)'\n"
+        printf "            _arguments '--aliases[Print short command aliases]' '1:shell:(bash zsh fish)'\n"
         printf '            ;;\n'
         printf '        *)\n'
         printf "            _describe 'subcommand' subcommands\n"
@@ -430,7 +638,7 @@ EOF
         ;;
     *)
         cat << 'EOF'
-Usage: mole completion [bash|zsh|fish]
+Usage: mole completion [--aliases] [bash|zsh|fish]
 
 Setup shell tab completion for mole and mo commands.
 
@@ -443,6 +651,12 @@ Manual install:
   mole completion zsh          # Generate zsh completion script
   mole completion fish         # Generate fish completion script
 
+Aliases:
+  mole completion --aliases [bash|zsh|fish]
+                               # Print moc, moa, mos, mou, moo, and mop
+                               # for clean, analyze, status, uninstall,
+                               # optimize, and purge; taken names are skipped
+
 Examples:
   # Auto-install (recommended)
   mole completion
@@ -455,6 +669,9 @@ Examples:
 
   # Manual install - Fish
   mole completion fish | source
+
+  # Aliases, next to the completion line in your shell startup file
+  eval "$(mole completion --aliases zsh)"
 EOF
         exit 1
         ;;
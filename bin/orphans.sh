@@ -0,0 +1,367 @@
+#!/bin/bash
+# Mole - Orphans command.
+# Lists ~/Library data left behind by apps that are no longer installed.
+# Removes the selected apps' data to Trash after confirmation.
+
+set -euo pipefail
+
+# shellcheck disable=SC2154
+# External variables set by menu_paginated.sh
+declare MOLE_SELECTION_RESULT
+
+export LC_ALL=C
+export LANG=C
+export MOLE_CURRENT_COMMAND="${MOLE_CURRENT_COMMAND:-orphans}"
+
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+source "$SCRIPT_DIR/../lib/core/common.sh"
+source "$SCRIPT_DIR/../lib/ui/menu_paginated.sh"
+source "$SCRIPT_DIR/../lib/clean/apps.sh"
+
+cleanup() {
+    show_cursor
+    cleanup_temp_files
+}
+trap cleanup EXIT
+trap 'trap - EXIT; cleanup; exit 130' INT TERM
+
+# ~/Library folders whose entries are named after the owning bundle ID.
+# Containers and Group Containers stay out for the same reasons
+# clean_orphaned_app_data leaves them out: containermanagerd owns the first
+# and TeamID prefixes make the second look orphaned.
+readonly ORPHAN_DATA_DIRS=(
+    "$HOME/Library/Application Support|Application Support"
+    "$HOME/Library/Caches|Caches"
+    "$HOME/Library/Preferences|Preferences"
+    "$HOME/Library/Logs|Logs"
+    "$HOME/Library/Saved Application State|Saved State"
+    "$HOME/Library/HTTPStorages|HTTP Storage"
+    "$HOME/Library/WebKit|WebKit"
+)
+readonly ORPHANS_EXIT_INCOMPLETE=3
+
+declare -a WHITELIST_PATTERNS=()
+
+# One row per orphaned bundle ID, largest first.
+declare -a ORPHAN_IDS=()
+declare -a ORPHAN_SIZES_KB=()
+declare -a ORPHAN_KINDS=()
+declare -a ORPHAN_PATHS=() # newline-separated per bundle ID
+
+declare -i total_removed=0
+declare -i total_freed_kb=0
+declare -a ORPHAN_DELETE_FAILURES=()
+
+# Honour `mo clean --whitelist` entries so protected data is never offered.
+load_orphan_whitelist() {
+    local file="$HOME/.config/mole/whitelist"
+    if [[ ! -f "$file" ]]; then
+        WHITELIST_PATTERNS=("${DEFAULT_WHITELIST_PATTERNS[@]+"${DEFAULT_WHITELIST_PATTERNS[@]}"}")
+    else
+        local line
+        while IFS= read -r line; do
+            # shellcheck disable=SC2295
+            line="${line#"${line%%[![:space:]]*}"}"
+            # shellcheck disable=SC2295
+            line="${line%"${line##*[![:space:]]}"}"
+            [[ -z "$line" || "$line" =~ ^# ]] && continue
+            WHITELIST_PATTERNS+=("$line")
+        done < "$file"
+    fi
+    local -a expanded=()
+    local pattern
+    for pattern in "${WHITELIST_PATTERNS[@]+"${WHITELIST_PATTERNS[@]}"}"; do
+        expanded+=("${pattern/#\~/$HOME}")
+    done
+    WHITELIST_PATTERNS=("${expanded[@]+"${expanded[@]}"}")
+}
+
+# Strip the file suffixes ~/Library entries add to a bundle ID.
+orphan_bundle_id() {
+    local name="$1"
+    name="${name%.plist}"
+    name="${name%.savedState}"
+    name="${name%.binarycookies}"
+    echo "$name"
+}
+
+# Print "bundle_id|kind|path|size_kb" for every orphaned entry.
+# Usage: scan_orphaned_data "installed_bundles_file"
+scan_orphaned_data() {
+    local installed_bundles="$1"
+    local dir_entry base_path label item
+    for dir_entry in "${ORPHAN_DATA_DIRS[@]}"; do
+        IFS='|' read -r base_path label <<< "$dir_entry"
+        [[ -d "$base_path" ]] || continue
+        while IFS= read -r -d '' item; do
+            [[ -L "$item" ]] && continue
+            local bundle_id
+            bundle_id=$(orphan_bundle_id "$(basename "$item")")
+            mole_is_reverse_dns_bundle_id "$bundle_id" || continue
+            is_path_whitelisted "$item" && continue
+            is_bundle_orphaned "$bundle_id" "$item" "$installed_bundles" || continue
+            local size_kb
+            size_kb=$(get_path_size_kb "$item")
+            [[ "$size_kb" =~ ^[0-9]+$ && "$size_kb" -gt 0 ]] || continue
+            printf '%s|%s|%s|%s\n' "$bundle_id" "$label" "$item" "$size_kb"
+        done < <(find "$base_path" -mindepth 1 -maxdepth 1 -print0 2> /dev/null || true)
+    done
+}
+
+# Group scan rows by bundle ID into the ORPHAN_* arrays, largest first.
+# Usage: group_orphaned_data < rows
+group_orphaned_data() {
+    local -a ids=() sizes=() kinds=() paths=()
+    local bundle_id label path size_kb
+    while IFS='|' read -r bundle_id label path size_kb; do
+        [[ -n "$bundle_id" ]] || continue
+        local idx=-1 i
+        for ((i = 0; i < ${#ids[@]}; i++)); do
+            if [[ "${ids[i]}" == "$bundle_id" ]]; then
+                idx=$i
+                break
+            fi
+        done
+        if [[ $idx -lt 0 ]]; then
+            ids+=("$bundle_id")
+            sizes+=("$size_kb")
+            kinds+=("$label")
+            paths+=("$path")
+            continue
+        fi
+        sizes[idx]=$((sizes[idx] + size_kb))
+        [[ ", ${kinds[idx]}, " == *", $label, "* ]] || kinds[idx]="${kinds[idx]}, $label"
+        paths[idx]="${paths[idx]}"$'\n'"$path"
+    done
+
+    ORPHAN_IDS=()
+    ORPHAN_SIZES_KB=()
+    ORPHAN_KINDS=()
+    ORPHAN_PATHS=()
+    [[ ${#ids[@]} -gt 0 ]] || return 0
+    local order
+    while IFS='|' read -r _ order; do
+        ORPHAN_IDS+=("${ids[order]}")
+        ORPHAN_SIZES_KB+=("${sizes[order]}")
+        ORPHAN_KINDS+=("${kinds[order]}")
+        ORPHAN_PATHS+=("${paths[order]}")
+    done < <(for ((i = 0; i < ${#ids[@]}; i++)); do printf '%s|%s\n' "${sizes[i]}" "$i"; done | sort -t'|' -k1,1nr)
+}
+
+# Returns: 0 if orphaned data was found, 1 if none, 2 if ~/Library could
+# not be read, so nothing was checked
+collect_orphans() {
+    if ! ls "$HOME/Library/Caches" > /dev/null 2>&1; then
+        echo -e "${GRAY}${ICON_WARNING}${NC} No permission to read ~/Library, grant Full Disk Access to your terminal"
+        return 2
+    fi
+
+    [[ -t 1 ]] && start_inline_spinner "Scanning installed apps..."
+    local installed_bundles
+    installed_bundles=$(create_temp_file)
+    scan_installed_apps "$installed_bundles"
+    [[ -t 1 ]] && stop_inline_spinner
+
+    [[ -t 1 ]] && start_inline_spinner "Scanning app data..."
+    local rows
+    rows=$(create_temp_file)
+    scan_orphaned_data "$installed_bundles" > "$rows"
+    group_orphaned_data < "$rows"
+    [[ -t 1 ]] && stop_inline_spinner
+
+    [[ ${#ORPHAN_IDS[@]} -gt 0 ]]
+}
+
+orphans_total_kb() {
+    local total=0 size
+    for size in "${ORPHAN_SIZES_KB[@]+"${ORPHAN_SIZES_KB[@]}"}"; do
+        total=$((total + size))
+    done
+    echo "$total"
+}
+
+format_orphan_row() {
+    local idx="$1"
+    local id
+    id=$(truncate_by_display_width "${ORPHAN_IDS[idx]}" 40)
+    printf "%-40s %9s  %s" "$id" "$(bytes_to_human_kb "${ORPHAN_SIZES_KB[idx]}")" "${ORPHAN_KINDS[idx]}"
+}
+
+print_orphans() {
+    local count=${#ORPHAN_IDS[@]}
+    local label="apps"
+    [[ $count -eq 1 ]] && label="app"
+    echo -e "${PURPLE_BOLD}Orphaned app data${NC}${GRAY}, $count $label, $(bytes_to_human_kb "$(orphans_total_kb)") reclaimable${NC}"
+    local i
+    for ((i = 0; i < count; i++)); do
+        echo "  $(format_orphan_row "$i")"
+    done
+}
+
+select_orphans() {
+    local -a rows=()
+    local -a sizes=()
+    local i
+    for ((i = 0; i < ${#ORPHAN_IDS[@]}; i++)); do
+        rows+=("$(format_orphan_row "$i")")
+        sizes+=("${ORPHAN_SIZES_KB[i]}")
+    done
+
+    local IFS=','
+    export MOLE_MENU_META_SIZEKB="${sizes[*]}"
+    unset IFS
+    MOLE_SELECTION_RESULT=""
+    local rc=0
+    MOLE_MENU_SORT_DEFAULT=size paginated_multi_select "Select Orphaned Data to Remove" "${rows[@]}" || rc=$?
+    unset MOLE_MENU_META_SIZEKB
+    [[ $rc -eq 0 && -n "$MOLE_SELECTION_RESULT" ]]
+}
+
+remove_selected_orphans() {
+    local -a selected=()
+    IFS=',' read -ra selected <<< "$MOLE_SELECTION_RESULT"
+
+    local confirm_kb=0 idx
+    for idx in "${selected[@]}"; do
+        confirm_kb=$((confirm_kb + ORPHAN_SIZES_KB[idx]))
+    done
+
+    echo -e "${PURPLE_BOLD}Data to be removed:${NC}"
+    for idx in "${selected[@]}"; do
+        echo -e "  ${GREEN}${ICON_SUCCESS}${NC} ${ORPHAN_IDS[idx]} ${GRAY}, $(bytes_to_human_kb "${ORPHAN_SIZES_KB[idx]}")${NC}"
+    done
+    echo ""
+    echo -ne "${PURPLE}${ICON_ARROW}${NC} Remove data of ${#selected[@]} apps, $(bytes_to_human_kb "$confirm_kb")  ${GREEN}Enter${NC} confirm, ${GRAY}ESC${NC} cancel: "
+
+    local confirm
+    IFS= read -r -s -n1 confirm || confirm=""
+    case "$confirm" in
+        "" | $'\n' | $'\r')
+            printf "\r\033[K"
+            echo ""
+            ;;
+        *)
+            printf "\r\033[K"
+            return 1
+            ;;
+    esac
+
+    [[ -t 1 ]] && start_inline_spinner "Removing orphaned data..."
+    for idx in "${selected[@]}"; do
+        local path
+        while IFS= read -r path; do
+            [[ -n "$path" ]] || continue
+            [[ -e "$path" ]] || continue
+            local size_kb
+            size_kb=$(get_path_size_kb "$path")
+            if mole_delete "$path" false; then
+                total_removed=$((total_removed + 1))
+                total_freed_kb=$((total_freed_kb + ${size_kb:-0}))
+            else
+                ORPHAN_DELETE_FAILURES+=("$path")
+            fi
+        done <<< "${ORPHAN_PATHS[idx]}"
+    done
+    [[ -t 1 ]] && stop_inline_spinner
+
+    [[ ${#ORPHAN_DELETE_FAILURES[@]} -eq 0 ]] || return "$ORPHANS_EXIT_INCOMPLETE"
+}
+
+show_summary() {
+    local heading="Orphaned data removed"
+    local -a details=()
+    if [[ "${MOLE_DRY_RUN:-0}" == "1" ]]; then
+        heading="Dry run complete - no changes made"
+        details+=("Would remove ${GREEN}$total_removed${NC} items, free ${GREEN}$(bytes_to_human_kb "$total_freed_kb")${NC}")
+    else
+        details+=("Removed ${GREEN}$total_removed${NC} items, freed ${GREEN}$(bytes_to_human_kb "$total_freed_kb")${NC}")
+        if [[ "$MOLE_DELETE_MODE" == "trash" ]]; then
+            details+=("Items are in the Trash until you empty it")
+        fi
+    fi
+    if [[ ${#ORPHAN_DELETE_FAILURES[@]} -gt 0 ]]; then
+        heading="Orphaned data cleanup incomplete"
+        details+=("Failed to remove ${YELLOW}${#ORPHAN_DELETE_FAILURES[@]}${NC} items")
+        local failure
+        for failure in "${ORPHAN_DELETE_FAILURES[@]:0:5}"; do
+            details+=("${ICON_WARNING} $failure")
+        done
+    fi
+    print_summary_block "$heading" "${details[@]}"
+    printf '\n'
+}
+
+main() {
+    local list_only=false
+    export MOLE_DELETE_MODE="${MOLE_DELETE_MODE:-trash}"
+    for arg in "$@"; do
+        case "$arg" in
+            "--help" | "-h")
+                show_orphans_help
+                exit 0
+                ;;
+            "--list")
+                list_only=true
+                ;;
+            "--dry-run" | "-n")
+                export MOLE_DRY_RUN=1
+                ;;
+            "--permanent")
+                export MOLE_DELETE_MODE="permanent"
+                ;;
+            "--debug")
+                export MO_DEBUG=1
+                ;;
+            *)
+                echo "Unknown option: $arg"
+                echo "Use 'mo orphans --help' for supported options."
+                exit 1
+                ;;
+        esac
+    done
+
+    if [[ "${MOLE_DRY_RUN:-0}" == "1" ]]; then
+        echo -e "${YELLOW}${ICON_DRY_RUN} DRY RUN MODE${NC}, No app data will be removed"
+        printf '\n'
+    fi
+
+    load_orphan_whitelist
+    local collect_status=0
+    collect_orphans || collect_status=$?
+    if [[ $collect_status -eq 2 ]]; then
+        echo -e "${YELLOW}${ICON_WARNING}${NC} Scan incomplete, app data was not checked"
+        return 1
+    fi
+    if [[ $collect_status -ne 0 ]]; then
+        echo -e "${GREEN}${ICON_SUCCESS}${NC} Great! No data left behind by uninstalled apps"
+        return 0
+    fi
+
+    print_orphans
+    if [[ "$list_only" == "true" || ! -t 0 || ! -t 1 ]]; then
+        return 0
+    fi
+    echo ""
+    echo -ne "${PURPLE}${ICON_ARROW}${NC} Choose data to remove  ${GREEN}Enter${NC} continue, ${GRAY}ESC${NC} cancel: "
+    local key
+    IFS= read -r -s -n1 key || key=""
+    printf "\r\033[K"
+    case "$key" in
+        "" | $'\n' | $'\r') ;;
+        *) return 0 ;;
+    esac
+
+    if ! select_orphans; then
+        return 0
+    fi
+    local rc=0
+    remove_selected_orphans || rc=$?
+    [[ $rc -eq 1 ]] && return 0
+    show_summary
+    [[ $rc -eq 0 ]] || return 1
+}
+
+# Only run main if not in test mode
+if [[ "${MOLE_TEST_MODE:-0}" != "1" ]]; then
+    main "$@"
+fi
@@ -0,0 +1,458 @@
+#!/bin/bash
+# Mole - Screenshots command.
+# Lists screenshots and screen recordings on the Desktop and in the capture
+# folder, grouped by age. Archives or trashes the selected groups.
+
+set -euo pipefail
+
+# shellcheck disable=SC2154
+# External variables set by menu_paginated.sh
+declare MOLE_SELECTION_RESULT
+
+export LC_ALL=C
+export LANG=C
+export MOLE_CURRENT_COMMAND="${MOLE_CURRENT_COMMAND:-screenshots}"
+
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+source "$SCRIPT_DIR/../lib/core/common.sh"
+source "$SCRIPT_DIR/../lib/ui/menu_paginated.sh"
+
+cleanup() {
+    show_cursor
+    cleanup_temp_files
+}
+trap cleanup EXIT
+trap 'trap - EXIT; cleanup; exit 130' INT TERM
+
+# Age buckets, youngest first: "max age in days|label". The last one has
+# no limit.
+readonly SCREENSHOT_BUCKETS=(
+    "7|This week"
+    "30|This month"
+    "182|1 to 6 months"
+    "0|Older than 6 months"
+)
+readonly SCREENSHOTS_EXIT_INCOMPLETE=3
+
+SCREENSHOT_ARCHIVE_DIR="$HOME/Pictures/Screenshot Archive"
+
+# One row per non-empty bucket, youngest first.
+declare -a SHOT_LABELS=()
+declare -a SHOT_COUNTS=()
+declare -a SHOT_RECORDINGS=()
+declare -a SHOT_SIZES_KB=()
+declare -a SHOT_PATHS=() # newline-separated per bucket
+
+declare -i total_done=0
+declare -i total_done_kb=0
+declare -a SHOT_FAILURES=()
+
+# Print the folders to look in: the Desktop, the folder screencapture saves
+# to when it is set to another one, and ~/Pictures/Screenshots, which many
+# people point it at. Each is printed once.
+screenshot_capture_dirs() {
+    local -a dirs=("$HOME/Desktop")
+    local location
+    location=$(defaults read com.apple.screencapture location 2> /dev/null || true)
+    location="${location/#\~/$HOME}"
+    location="${location%/}"
+    [[ -n "$location" ]] && dirs+=("$location")
+    dirs+=("$HOME/Pictures/Screenshots")
+
+    local -a seen=()
+    local dir prev dup
+    for dir in "${dirs[@]}"; do
+        [[ -d "$dir" ]] || continue
+        dup=false
+        for prev in "${seen[@]+"${seen[@]}"}"; do
+            [[ "$prev" == "$dir" ]] && dup=true
+        done
+        [[ "$dup" == "true" ]] && continue
+        seen+=("$dir")
+        echo "$dir"
+    done
+}
+
+# Print "screenshot" or "recording" when the file name is one macOS or a
+# common capture tool gives its captures. prefix is the custom name set
+# with `defaults write com.apple.screencapture name`, if any.
+# Usage: screenshot_kind "name" ["prefix"]
+screenshot_kind() {
+    local name="$1" prefix="${2:-}"
+    local lower
+    lower=$(printf '%s' "$name" | tr '[:upper:]' '[:lower:]')
+    case "$lower" in
+        "screen recording "*.mov | "screen recording "*.mp4 | "cleanshot "*.mov | "cleanshot "*.mp4)
+            echo "recording"
+            return 0
+            ;;
+        "screenshot "*.png | "screenshot "*.jpg | "screenshot "*.jpeg | "screenshot "*.heic | "screenshot "*.tiff | "screenshot "*.pdf | \
+            "screen shot "*.png | "screen shot "*.jpg | "screen shot "*.jpeg | "screen shot "*.heic | "screen shot "*.tiff | "screen shot "*.pdf | \
+            "cleanshot "*.png | "cleanshot "*.jpg)
+            echo "screenshot"
+            return 0
+            ;;
+    esac
+    if [[ -n "$prefix" && "$name" == "$prefix "* ]]; then
+        case "$lower" in
+            *.mov | *.mp4) echo "recording" ;;
+            *) echo "screenshot" ;;
+        esac
+        return 0
+    fi
+    return 1
+}
+
+# Print "mtime|kind|path|size_kb" for every capture directly in the given
+# folders. Spotlight also finds screenshots whose names are localized.
+# Usage: scan_screenshots dir...
+scan_screenshots() {
+    local prefix
+    prefix=$(defaults read com.apple.screencapture name 2> /dev/null || true)
+
+    local dir item kind
+    for dir in "$@"; do
+        local -a spotlight=()
+        if command -v mdfind > /dev/null 2>&1; then
+            while IFS= read -r item; do
+                [[ "$(dirname "$item")" == "$dir" ]] && spotlight+=("$item")
+            done < <(run_with_timeout "$MOLE_TIMEOUT_SPOTLIGHT_QUERY_SEC" mdfind -onlyin "$dir" "kMDItemIsScreenCapture == 1" 2> /dev/null || true)
+        fi
+        while IFS= read -r -d '' item; do
+            [[ -f "$item" && ! -L "$item" ]] || continue
+            if ! kind=$(screenshot_kind "$(basename "$item")" "$prefix"); then
+                local hit="" found
+                for found in "${spotlight[@]+"${spotlight[@]}"}"; do
+                    [[ "$found" == "$item" ]] && hit=1 && break
+                done
+                [[ -n "$hit" ]] || continue
+                kind="screenshot"
+            fi
+            local size_kb
+            size_kb=$(get_path_size_kb "$item")
+            printf '%s|%s|%s|%s\n' "$(get_file_mtime "$item")" "$kind" "$item" "${size_kb:-0}"
+        done < <(find "$dir" -mindepth 1 -maxdepth 1 -type f -print0 2> /dev/null || true)
+    done
+}
+
+# Group scan rows into the SHOT_* arrays by age.
+# Usage: group_screenshots now < rows
+group_screenshots() {
+    local now="$1"
+    local -a counts=() recordings=() sizes=() paths=()
+    local i
+    for ((i = 0; i < ${#SCREENSHOT_BUCKETS[@]}; i++)); do
+        counts+=(0)
+        recordings+=(0)
+        sizes+=(0)
+        paths+=("")
+    done
+
+    local mtime kind path size_kb
+    while IFS='|' read -r mtime kind path size_kb; do
+        [[ -n "$path" ]] || continue
+        local age_days=$(((now - mtime) / 86400))
+        local bucket=$((${#SCREENSHOT_BUCKETS[@]} - 1)) max_days
+        for ((i = 0; i < ${#SCREENSHOT_BUCKETS[@]} - 1; i++)); do
+            max_days="${SCREENSHOT_BUCKETS[i]%%|*}"
+            if [[ $age_days -lt $max_days ]]; then
+                bucket=$i
+                break
+            fi
+        done
+        counts[bucket]=$((counts[bucket] + 1))
+        [[ "$kind" == "recording" ]] && recordings[bucket]=$((recordings[bucket] + 1))
+        sizes[bucket]=$((sizes[bucket] + size_kb))
+        if [[ -n "${paths[bucket]}" ]]; then
+            paths[bucket]="${paths[bucket]}"$'\n'"$path"
+        else
+            paths[bucket]="$path"
+        fi
+    done
+
+    SHOT_LABELS=()
+    SHOT_COUNTS=()
+    SHOT_RECORDINGS=()
+    SHOT_SIZES_KB=()
+    SHOT_PATHS=()
+    for ((i = 0; i < ${#SCREENSHOT_BUCKETS[@]}; i++)); do
+        [[ ${counts[i]} -gt 0 ]] || continue
+        SHOT_LABELS+=("${SCREENSHOT_BUCKETS[i]#*|}")
+        SHOT_COUNTS+=("${counts[i]}")
+        SHOT_RECORDINGS+=("${recordings[i]}")
+        SHOT_SIZES_KB+=("${sizes[i]}")
+        SHOT_PATHS+=("${paths[i]}")
+    done
+}
+
+collect_screenshots() {
+    local -a dirs=()
+    local dir
+    while IFS= read -r dir; do
+        dirs+=("$dir")
+    done < <(screenshot_capture_dirs)
+    [[ ${#dirs[@]} -gt 0 ]] || return 1
+
+    [[ -t 1 ]] && start_inline_spinner "Scanning for screenshots..."
+    local rows
+    rows=$(create_temp_file)
+    scan_screenshots "${dirs[@]}" > "$rows"
+    group_screenshots "$(date +%s)" < "$rows"
+    [[ -t 1 ]] && stop_inline_spinner
+
+    [[ ${#SHOT_LABELS[@]} -gt 0 ]]
+}
+
+screenshots_total_kb() {
+    local total=0 size
+    for size in "${SHOT_SIZES_KB[@]+"${SHOT_SIZES_KB[@]}"}"; do
+        total=$((total + size))
+    done
+    echo "$total"
+}
+
+format_screenshot_row() {
+    local idx="$1"
+    local count="${SHOT_COUNTS[idx]}" recordings="${SHOT_RECORDINGS[idx]}"
+    local shots=$((count - recordings))
+    local -a parts=()
+    if [[ $shots -gt 0 ]]; then
+        local label="screenshots"
+        [[ $shots -eq 1 ]] && label="screenshot"
+        parts+=("$shots $label")
+    fi
+    if [[ $recordings -gt 0 ]]; then
+        local label="recordings"
+        [[ $recordings -eq 1 ]] && label="recording"
+        parts+=("$recordings $label")
+    fi
+    local detail="${parts[0]}"
+    [[ ${#parts[@]} -gt 1 ]] && detail="$detail, ${parts[1]}"
+    printf "%-22s %9s  %s" "${SHOT_LABELS[idx]}" "$(bytes_to_human_kb "${SHOT_SIZES_KB[idx]}")" "$detail"
+}
+
+print_screenshots() {
+    local count=0 size
+    for size in "${SHOT_COUNTS[@]}"; do
+        count=$((count + size))
+    done
+    local label="files"
+    [[ $count -eq 1 ]] && label="file"
+    echo -e "${PURPLE_BOLD}Screenshots and recordings${NC}${GRAY}, $count $label, $(bytes_to_human_kb "$(screenshots_total_kb)")${NC}"
+    local i
+    for ((i = 0; i < ${#SHOT_LABELS[@]}; i++)); do
+        echo "  $(format_screenshot_row "$i")"
+    done
+}
+
+select_screenshots() {
+    local -a rows=()
+    local -a sizes=()
+    local i
+    for ((i = 0; i < ${#SHOT_LABELS[@]}; i++)); do
+        rows+=("$(format_screenshot_row "$i")")
+        sizes+=("${SHOT_SIZES_KB[i]}")
+    done
+
+    local IFS=','
+    export MOLE_MENU_META_SIZEKB="${sizes[*]}"
+    unset IFS
+    MOLE_SELECTION_RESULT=""
+    local rc=0
+    paginated_multi_select "Select Screenshots by Age" "${rows[@]}" || rc=$?
+    unset MOLE_MENU_META_SIZEKB
+    [[ $rc -eq 0 && -n "$MOLE_SELECTION_RESULT" ]]
+}
+
+# Move a capture into the archive, in a folder per month it was taken, and
+# never over a file already there.
+# Usage: archive_screenshot "path"
+archive_screenshot() {
+    local path="$1"
+    local month
+    month=$(date -r "$(get_file_mtime "$path")" +%Y-%m 2> /dev/null || date +%Y-%m)
+    local dest_dir="$SCREENSHOT_ARCHIVE_DIR/$month"
+    local name base ext dest n=2
+    name=$(basename "$path")
+    dest="$dest_dir/$name"
+    base="${name%.*}"
+    ext="${name##*.}"
+    while [[ -e "$dest" ]]; do
+        dest="$dest_dir/$base $n.$ext"
+        n=$((n + 1))
+    done
+
+    if [[ "${MOLE_DRY_RUN:-0}" == "1" ]]; then
+        debug_log "[DRY RUN] Would archive: $path -> $dest"
+        return 0
+    fi
+    mkdir -p "$dest_dir" 2> /dev/null || return 1
+    mv -n "$path" "$dest" 2> /dev/null || return 1
+    [[ ! -e "$path" ]] || return 1
+    log_operation "$MOLE_CURRENT_COMMAND" "ARCHIVED" "$path" "$dest"
+}
+
+# Usage: act_on_selected_screenshots "archive|trash"
+act_on_selected_screenshots() {
+    local action="$1"
+    local -a selected=()
+    IFS=',' read -ra selected <<< "$MOLE_SELECTION_RESULT"
+
+    [[ -t 1 ]] && start_inline_spinner "Moving screenshots..."
+    local idx path
+    for idx in "${selected[@]}"; do
+        while IFS= read -r path; do
+            [[ -n "$path" && -e "$path" ]] || continue
+            local size_kb
+            size_kb=$(get_path_size_kb "$path")
+            local rc=0
+            if [[ "$action" == "archive" ]]; then
+                archive_screenshot "$path" || rc=$?
+            else
+                mole_delete "$path" false || rc=$?
+            fi
+            if [[ $rc -eq 0 ]]; then
+                total_done=$((total_done + 1))
+                total_done_kb=$((total_done_kb + ${size_kb:-0}))
+            else
+                SHOT_FAILURES+=("$path")
+            fi
+        done <<< "${SHOT_PATHS[idx]}"
+    done
+    [[ -t 1 ]] && stop_inline_spinner
+
+    [[ ${#SHOT_FAILURES[@]} -eq 0 ]] || return "$SCREENSHOTS_EXIT_INCOMPLETE"
+}
+
+# Ask what to do with the selection. Prints "archive" or "trash".
+choose_screenshot_action() {
+    local -a selected=()
+    IFS=',' read -ra selected <<< "$MOLE_SELECTION_RESULT"
+    local count=0 kb=0 idx
+    for idx in "${selected[@]}"; do
+        count=$((count + SHOT_COUNTS[idx]))
+        kb=$((kb + SHOT_SIZES_KB[idx]))
+    done
+
+    local trash_label="Trash"
+    [[ "$MOLE_DELETE_MODE" == "permanent" ]] && trash_label="Delete"
+    echo -ne "${PURPLE}${ICON_ARROW}${NC} $count files, $(bytes_to_human_kb "$kb")  ${GREEN}A${NC} archive to ${SCREENSHOT_ARCHIVE_DIR/#$HOME/~}, ${GREEN}T${NC} ${trash_label}, ${GRAY}ESC${NC} cancel: " >&2
+    local key
+    IFS= read -r -s -n1 key || key=""
+    printf "\r\033[K" >&2
+    case "$key" in
+        a | A) echo "archive" ;;
+        t | T) echo "trash" ;;
+        *) return 1 ;;
+    esac
+}
+
+show_summary() {
+    local action="$1"
+    local verb="Archived" would="archive" heading="Screenshots archived"
+    if [[ "$action" == "trash" ]]; then
+        verb="Removed"
+        would="remove"
+        heading="Screenshots removed"
+    fi
+    local -a details=()
+    if [[ "${MOLE_DRY_RUN:-0}" == "1" ]]; then
+        heading="Dry run complete - no changes made"
+        details+=("Would $would ${GREEN}$total_done${NC} files, $(bytes_to_human_kb "$total_done_kb")")
+    else
+        details+=("$verb ${GREEN}$total_done${NC} files, ${GREEN}$(bytes_to_human_kb "$total_done_kb")${NC}")
+        if [[ "$action" == "archive" ]]; then
+            details+=("They are in ${SCREENSHOT_ARCHIVE_DIR/#$HOME/~}, by month")
+        elif [[ "$MOLE_DELETE_MODE" == "trash" ]]; then
+            details+=("Items are in the Trash until you empty it")
+        fi
+    fi
+    if [[ ${#SHOT_FAILURES[@]} -gt 0 ]]; then
+        heading="Screenshot cleanup incomplete"
+        details+=("Failed to move ${YELLOW}${#SHOT_FAILURES[@]}${NC} files")
+        local failure
+        for failure in "${SHOT_FAILURES[@]:0:5}"; do
+            details+=("${ICON_WARNING} $failure")
+        done
+    fi
+    print_summary_block "$heading" "${details[@]}"
+    printf '\n'
+}
+
+main() {
+    local list_only=false
+    export MOLE_DELETE_MODE="${MOLE_DELETE_MODE:-trash}"
+    while [[ $# -gt 0 ]]; do
+        case "$1" in
+            "--help" | "-h")
+                show_screenshots_help
+                exit 0
+                ;;
+            "--list")
+                list_only=true
+                ;;
+            "--dry-run" | "-n")
+                export MOLE_DRY_RUN=1
+                ;;
+            "--archive-dir")
+                if [[ -z "${2:-}" ]]; then
+                    echo "--archive-dir needs a folder"
+                    exit 1
+                fi
+                SCREENSHOT_ARCHIVE_DIR="${2%/}"
+                shift
+                ;;
+            "--permanent")
+                export MOLE_DELETE_MODE="permanent"
+                ;;
+            "--debug")
+                export MO_DEBUG=1
+                ;;
+            *)
+                echo "Unknown option: $1"
+                echo "Use 'mo screenshots --help' for supported options."
+                exit 1
+                ;;
+        esac
+        shift
+    done
+
+    if [[ "${MOLE_DRY_RUN:-0}" == "1" ]]; then
+        echo -e "${YELLOW}${ICON_DRY_RUN} DRY RUN MODE${NC}, No screenshots will be moved"
+        printf '\n'
+    fi
+
+    if ! collect_screenshots; then
+        echo -e "${GREEN}${ICON_SUCCESS}${NC} Great! No screenshots or screen recordings piling up"
+        return 0
+    fi
+
+    print_screenshots
+    if [[ "$list_only" == "true" || ! -t 0 || ! -t 1 ]]; then
+        return 0
+    fi
+    echo ""
+    echo -ne "${PURPLE}${ICON_ARROW}${NC} Choose which to tidy up  ${GREEN}Enter${NC} continue, ${GRAY}ESC${NC} cancel: "
+    local key
+    IFS= read -r -s -n1 key || key=""
+    printf "\r\033[K"
+    case "$key" in
+        "" | $'\n' | $'\r') ;;
+        *) return 0 ;;
+    esac
+
+    if ! select_screenshots; then
+        return 0
+    fi
+    local action
+    action=$(choose_screenshot_action) || return 0
+    local rc=0
+    act_on_selected_screenshots "$action" || rc=$?
+    show_summary "$action"
+    [[ $rc -eq 0 ]] || return 1
+}
+
+# Only run main if not in test mode
+if [[ "${MOLE_TEST_MODE:-0}" != "1" ]]; then
+    main "$@"
+fi
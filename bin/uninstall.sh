@@ -1218,7 +1218,8 @@ cleanup() {
 trap cleanup EXIT INT TERM
 
 # Match app names from scan data against user-provided search terms.
-# Performs case-insensitive substring matching on app display names.
+# Performs case-insensitive substring matching on app display names; a
+# bundle ID (com.tinyspeck.slackmacgap) must match exactly.
 # Returns matched entries from apps_data in selected_apps.
 match_apps_by_name() {
     local -a search_terms=("$@")
@@ -1244,8 +1245,10 @@ match_apps_by_name() {
             dir_name=$(basename "$app_path" .app)
             local dir_lower
             dir_lower=$(echo "$dir_name" | tr '[:upper:]' '[:lower:]')
+            local bundle_lower
+            bundle_lower=$(echo "$bundle_id" | tr '[:upper:]' '[:lower:]')
 
-            if [[ "$name_lower" == "$search_lower" || "$dir_lower" == "$search_lower" ]]; then
+            if [[ "$name_lower" == "$search_lower" || "$dir_lower" == "$search_lower" || "$bundle_lower" == "$search_lower" ]]; then
                 # Exact match - prefer this
                 local already=false
                 local mi
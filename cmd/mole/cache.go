@@ -0,0 +1,122 @@
+//go:build darwin || linux
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/units"
+	"github.com/tw93/mole/pkg/diskscan"
+)
+
+// runCache implements `mole cache ls|clear|stats`, over the directory
+// scans mo analyze keeps in ~/.cache/mole, and returns the exit code.
+func runCache(args []string) int {
+	fs := flag.NewFlagSet("cache", flag.ContinueOnError)
+	fs.Usage = cacheUsage
+	if err := fs.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	if fs.NArg() != 1 {
+		cacheUsage()
+		return exitcode.Usage
+	}
+
+	switch fs.Arg(0) {
+	case "ls", "list":
+		scans, err := diskscan.ListCache()
+		if err != nil {
+			return exitcode.Report("cache", exitcode.Failed, fmt.Errorf("mole cache: %w", err))
+		}
+		printCacheList(os.Stdout, scans, time.Now())
+	case "stats":
+		scans, err := diskscan.ListCache()
+		if err != nil {
+			return exitcode.Report("cache", exitcode.Failed, fmt.Errorf("mole cache: %w", err))
+		}
+		dir, _ := diskscan.CacheDir()
+		printCacheStats(os.Stdout, dir, scans, time.Now())
+	case "clear":
+		files, bytes, err := diskscan.ClearCache()
+		fmt.Printf("Removed %d cache files, %s. The next mo analyze scans from scratch.\n", files, units.BytesSI(bytes))
+		if err != nil {
+			return exitcode.Report("cache", exitcode.Failed, fmt.Errorf("mole cache: %w", err))
+		}
+	default:
+		cacheUsage()
+		return exitcode.Usage
+	}
+	return exitcode.OK
+}
+
+// printCacheList writes one line per cached scan: the scanned size, its
+// age, and the directory.
+func printCacheList(w io.Writer, scans []diskscan.CachedScan, now time.Time) {
+	if len(scans) == 0 {
+		fmt.Fprintln(w, "No cached scans.")
+		return
+	}
+	for _, s := range scans {
+		path, note := s.Path, ""
+		switch {
+		case path == "":
+			path, note = s.File, "  (older format, pruned on the next mo analyze)"
+		case s.Expired:
+			note = "  (expired)"
+		}
+		fmt.Fprintf(w, "%10s  %4s  %s%s\n", units.BytesSI(s.TotalSize), shortAge(now.Sub(s.ScanTime)), path, note)
+	}
+}
+
+func printCacheStats(w io.Writer, dir string, scans []diskscan.CachedScan, now time.Time) {
+	var bytes int64
+	var expired int
+	var oldest time.Time
+	for _, s := range scans {
+		bytes += s.Bytes
+		if s.Expired {
+			expired++
+		}
+		if oldest.IsZero() || s.ScanTime.Before(oldest) {
+			oldest = s.ScanTime
+		}
+	}
+	fmt.Fprintf(w, "Directory  %s\n", dir)
+	fmt.Fprintf(w, "Scans      %d, %d expired\n", len(scans), expired)
+	fmt.Fprintf(w, "On disk    %s\n", units.BytesSI(bytes))
+	if !oldest.IsZero() {
+		fmt.Fprintf(w, "Oldest     %s ago\n", shortAge(now.Sub(oldest)))
+	}
+	fmt.Fprintf(w, "Pruning    by mo analyze, after %s or oldest first over %s\n", shortAge(diskscan.CacheTTL), units.BytesSI(diskscan.CacheBudget))
+}
+
+// shortAge rounds d to minutes, hours, or days.
+func shortAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+func cacheUsage() {
+	fmt.Fprint(os.Stderr, strings.TrimLeft(`
+Usage: mole cache ls|clear|stats
+
+mo analyze remembers each directory it scans in ~/.cache/mole, so going
+back to a folder is instant. Scans are kept for a week and the files for
+at most `+units.BytesSI(diskscan.CacheBudget)+`, oldest dropped first.
+
+  ls      list the cached directories with their size and age
+  stats   show how much space the cache takes
+  clear   delete every cached scan
+`, "\n"))
+}
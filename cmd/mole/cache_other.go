@@ -0,0 +1,15 @@
+//go:build !darwin && !linux
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/tw93/mole/internal/exitcode"
+)
+
+// runCache has nothing to show where pkg/diskscan does not build.
+func runCache([]string) int {
+	return exitcode.Report("cache", exitcode.Failed, fmt.Errorf("mole cache: scanning is not supported on %s", runtime.GOOS))
+}
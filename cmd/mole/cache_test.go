@@ -0,0 +1,48 @@
+//go:build darwin || linux
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tw93/mole/pkg/diskscan"
+)
+
+func TestPrintCacheList(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	var b strings.Builder
+	printCacheList(&b, []diskscan.CachedScan{
+		{File: "/c/1.cache", ScanTime: now.Add(-time.Hour), Expired: true},
+		{Path: "/Users/me/Library", TotalSize: 34_600_000_000, ScanTime: now.Add(-90 * time.Minute)},
+		{Path: "/Users/me/Old", TotalSize: 1000, ScanTime: now.Add(-9 * 24 * time.Hour), Expired: true},
+	}, now)
+	want := `       0 B    1h  /c/1.cache  (older format, pruned on the next mo analyze)
+   34.6 GB    1h  /Users/me/Library
+    1.0 kB    9d  /Users/me/Old  (expired)
+`
+	if b.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", b.String(), want)
+	}
+
+	b.Reset()
+	printCacheList(&b, nil, now)
+	if b.String() != "No cached scans.\n" {
+		t.Errorf("empty list = %q", b.String())
+	}
+}
+
+func TestShortAge(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		5 * time.Minute:     "5m",
+		47 * time.Hour:      "47h",
+		7 * 24 * time.Hour:  "7d",
+		0:                   "0m",
+		36*time.Hour + 59e9: "36h",
+	} {
+		if got := shortAge(d); got != want {
+			t.Errorf("shortAge(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
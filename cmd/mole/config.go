@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/tw93/mole/internal/config"
+	"github.com/tw93/mole/internal/exitcode"
+)
+
+// runConfig implements `mole config path|list|get|set` and returns the
+// exit code. With --profile, given before or after config, keys are read
+// and written under that profile.
+func runConfig(args []string) int {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	fs.Usage = configUsage
+	profile := fs.String("profile", os.Getenv("MO_PROFILE"), "")
+	if err := fs.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	args = fs.Args()
+	if len(args) == 0 {
+		configUsage()
+		return exitcode.Usage
+	}
+	path := config.Path()
+	if path == "" {
+		return exitcode.Report("config", exitcode.Failed, errors.New("mole config: no home directory"))
+	}
+
+	switch verb := args[0]; {
+	case verb == "path" && len(args) == 1:
+		fmt.Println(path)
+		return exitcode.OK
+	case verb == "list" && len(args) == 1:
+		file, err := config.Load(path)
+		if err != nil {
+			return exitcode.Report("config", exitcode.Failed, fmt.Errorf("mole config: %w", err))
+		}
+		for _, line := range file.List() {
+			fmt.Println(line)
+		}
+		return exitcode.OK
+	case verb == "get" && len(args) == 2:
+		file, err := config.Load(path)
+		if err != nil {
+			return exitcode.Report("config", exitcode.Failed, fmt.Errorf("mole config: %w", err))
+		}
+		value, ok := file.Get(profileKey(*profile, args[1]))
+		if !ok {
+			return exitcode.Partial // unset, as with git config
+		}
+		fmt.Println(value)
+		return exitcode.OK
+	case verb == "set" && len(args) == 3:
+		key := profileKey(*profile, args[1])
+		f, err := configFlag(key)
+		if err != nil {
+			return exitcode.Report("config", exitcode.Usage, fmt.Errorf("mole config: %w", err))
+		}
+		literal, err := config.Literal(f, args[2])
+		if err != nil {
+			return exitcode.Report("config", exitcode.Usage, fmt.Errorf("mole config: %w", err))
+		}
+		if err := config.Set(path, key, literal); err != nil {
+			return exitcode.Report("config", exitcode.Failed, fmt.Errorf("mole config: %w", err))
+		}
+		return exitcode.OK
+	}
+	configUsage()
+	return exitcode.Usage
+}
+
+// profileKey puts key under the --profile table, if one was given.
+func profileKey(profile, key string) string {
+	if profile == "" || strings.HasPrefix(key, "profiles.") {
+		return key
+	}
+	return "profiles." + profile + "." + key
+}
+
+// configFlag finds the flag a dotted key configures. "status.interval"
+// must be a status flag; a bare "theme" must be a flag of some command.
+func configFlag(key string) (*flag.Flag, error) {
+	parts := strings.Split(key, ".")
+	if parts[0] == "profiles" {
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("%s: expected profiles.<name>.<key>", key)
+		}
+		parts = parts[2:]
+	}
+	normalize := func(name string) string { return strings.ReplaceAll(name, "_", "-") }
+	switch len(parts) {
+	case 1:
+		for _, name := range slices.Sorted(maps.Keys(commands)) {
+			if f := commands[name].flags.Lookup(normalize(parts[0])); f != nil {
+				return f, nil
+			}
+		}
+		return nil, fmt.Errorf("%s: no command has a --%s flag", key, normalize(parts[0]))
+	case 2:
+		cmd, ok := commands[parts[0]]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown command %q", key, parts[0])
+		}
+		if f := cmd.flags.Lookup(normalize(parts[1])); f != nil {
+			return f, nil
+		}
+		return nil, fmt.Errorf("%s: %s has no --%s flag", key, parts[0], normalize(parts[1]))
+	}
+	return nil, fmt.Errorf("%s: too many dots", key)
+}
+
+func configUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole config [--profile name] <path|list|get key|set key value>
+
+Keys are flag names, at the top level for every command or prefixed with
+the command: theme, units, status.interval, analyze.exclude.
+`)
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tw93/mole/internal/clean"
+	"github.com/tw93/mole/internal/config"
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/output"
+	"github.com/tw93/mole/internal/units"
+)
+
+// Check states, worst last, matching `mole status doctor`.
+const (
+	checkOK       = "ok"
+	checkSkipped  = "skipped"
+	checkDegraded = "degraded"
+	checkFailed   = "failed"
+)
+
+// check is one line of `mole doctor`.
+type check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// doctorTool is an external command some part of Mole depends on.
+type doctorTool struct {
+	name     string
+	goos     string // "" for every platform
+	feeds    string
+	fix      string
+	optional bool
+}
+
+const macBuiltinFix = "ships with macOS; make sure /usr/bin and /usr/sbin are on PATH"
+
+var doctorTools = []doctorTool{
+	{name: "du", feeds: "analyze folder sizes", fix: "install coreutils"},
+	{name: "mdfind", goos: "darwin", feeds: "uninstall and orphans finding apps outside /Applications", fix: macBuiltinFix},
+	{name: "powermetrics", goos: "darwin", feeds: "GPU activity and power draw in sudo mo status", fix: macBuiltinFix, optional: true},
+	{name: "smartctl", feeds: "disk SMART health in status", fix: "install smartmontools (brew install smartmontools)", optional: true},
+	{name: "nvidia-smi", goos: "linux", feeds: "NVIDIA GPU usage in status", fix: "install the NVIDIA driver utilities", optional: true},
+}
+
+// scanCacheTTL is how long pkg/diskscan keeps a scan before pruning it.
+const scanCacheTTL = 7 * 24 * time.Hour
+
+// doctorHost is what the checks need from the machine, swappable in tests.
+type doctorHost struct {
+	goos   string
+	home   string
+	now    time.Time
+	exists func(string) bool
+}
+
+func hostDoctor() (doctorHost, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return doctorHost{}, err
+	}
+	return doctorHost{
+		goos: runtime.GOOS,
+		home: home,
+		now:  time.Now(),
+		exists: func(name string) bool {
+			_, err := exec.LookPath(name)
+			return err == nil
+		},
+	}, nil
+}
+
+// runDoctor implements `mole doctor` and returns the exit code:
+// exitcode.Partial when anything is degraded or failed.
+func runDoctor(args []string) int {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	flags.Usage = doctorUsage
+	asJSON := flags.Bool("json", false, "print the checks as JSON")
+	outputTo := output.AddFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	host, err := hostDoctor()
+	if err != nil {
+		return exitcode.Report("doctor", exitcode.Failed, fmt.Errorf("mole doctor: %w", err))
+	}
+
+	checks := slices.Concat(
+		diskAccessChecks(host),
+		toolChecks(host),
+		cacheChecks(host),
+		configChecks(),
+	)
+	var encoded []byte
+	if *asJSON {
+		if encoded, err = json.MarshalIndent(checks, "", "  "); err != nil {
+			return exitcode.Report("doctor", exitcode.Failed, fmt.Errorf("error encoding checks: %w", err))
+		}
+	}
+	out, err := output.Create(output.Path(*outputTo))
+	if err != nil {
+		return exitcode.Report("doctor", exitcode.Failed, err)
+	}
+	code := checksExitCode(checks)
+	if *asJSON {
+		fmt.Fprintln(out, string(encoded))
+	} else {
+		code = writeChecks(out, checks)
+		fmt.Fprintln(out, "\nRun `mo status doctor` to check the data sources behind the status dashboard.")
+	}
+	if err := out.Close(); err != nil {
+		return exitcode.Report("doctor", exitcode.Failed, err)
+	}
+	return code
+}
+
+// diskAccessChecks probes Full Disk Access with the TCC database, which
+// macOS only lets a process with the grant open.
+func diskAccessChecks(host doctorHost) []check {
+	if host.goos != "darwin" {
+		return nil
+	}
+	tcc := filepath.Join(host.home, "Library", "Application Support", "com.apple.TCC", "TCC.db")
+	f, err := os.Open(tcc)
+	switch {
+	case err == nil:
+		f.Close()
+		return []check{{Name: "full disk access", Status: checkOK}}
+	case errors.Is(err, fs.ErrNotExist):
+		return []check{{Name: "full disk access", Status: checkSkipped, Detail: "cannot tell, no TCC database"}}
+	default:
+		return []check{{
+			Name:   "full disk access",
+			Status: checkDegraded,
+			Detail: "not granted; clean and orphans skip Mail, Safari, and other protected app data",
+			Fix:    "grant Full Disk Access to your terminal in System Settings > Privacy & Security",
+		}}
+	}
+}
+
+func toolChecks(host doctorHost) []check {
+	var checks []check
+	for _, tool := range doctorTools {
+		if tool.goos != "" && tool.goos != host.goos {
+			continue
+		}
+		switch {
+		case host.exists(tool.name):
+			checks = append(checks, check{Name: tool.name, Status: checkOK, Detail: tool.feeds})
+		case tool.optional:
+			checks = append(checks, check{Name: tool.name, Status: checkSkipped, Detail: "not installed; no " + tool.feeds, Fix: tool.fix})
+		default:
+			checks = append(checks, check{Name: tool.name, Status: checkDegraded, Detail: "not found; no " + tool.feeds, Fix: tool.fix})
+		}
+	}
+	return checks
+}
+
+// cacheChecks looks at ~/.cache/mole, where analyze keeps its scans: that
+// it can be written, how much it holds, and whether a corrupt size store
+// had to be set aside.
+func cacheChecks(host doctorHost) []check {
+	dir := filepath.Join(host.home, ".cache", "mole")
+	name := "scan cache"
+	info, err := os.Stat(dir)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return []check{{Name: name, Status: checkSkipped, Detail: "empty until the first mo analyze"}}
+	case err != nil:
+		return []check{{Name: name, Status: checkFailed, Detail: err.Error()}}
+	case !info.IsDir():
+		return []check{{Name: name, Status: checkFailed, Detail: dir + " is not a directory", Fix: "remove it; analyze recreates the cache"}}
+	}
+
+	probe, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return []check{{
+			Name:   name,
+			Status: checkDegraded,
+			Detail: "not writable; every mo analyze rescans from scratch",
+			Fix:    "run `sudo chown -R $(whoami) ~/.cache/mole`",
+		}}
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	var scans, stale int
+	var total int64
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) != ".cache" || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		scans++
+		total += info.Size()
+		if host.now.Sub(info.ModTime()) > scanCacheTTL {
+			stale++
+		}
+	}
+	detail := fmt.Sprintf("%d scans, %s", scans, units.BytesSI(total))
+	if stale > 0 {
+		detail += fmt.Sprintf(", %d past their week (pruned on the next mo analyze)", stale)
+	}
+	checks := []check{{Name: name, Status: checkOK, Detail: detail}}
+
+	corrupt := filepath.Join(dir, "overview_sizes.json.corrupt")
+	if _, err := os.Stat(corrupt); err == nil {
+		checks = append(checks, check{
+			Name:   "size store",
+			Status: checkDegraded,
+			Detail: "a corrupt overview size store was set aside and rebuilt",
+			Fix:    "remove " + corrupt + " once analyze shows sizes again",
+		})
+	}
+	return checks
+}
+
+// configChecks loads config.toml the way each command does, so a bad key
+// or value shows up here instead of as a failed launch.
+func configChecks() []check {
+	var checks []check
+	path := config.Path()
+	if _, err := config.Load(path); err != nil {
+		checks = append(checks, check{Name: "config.toml", Status: checkFailed, Detail: err.Error(), Fix: "fix the TOML syntax, then check it with `mole config list`"})
+	} else {
+		var problems []string
+		for _, name := range slices.Sorted(maps.Keys(commands)) {
+			flags := commands[name].flags
+			if !hasFlags(flags) {
+				continue // analyze off macOS
+			}
+			if err := config.Apply(flags, name, ""); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+		if len(problems) > 0 {
+			checks = append(checks, check{Name: "config.toml", Status: checkFailed, Detail: strings.Join(problems, "; "), Fix: "correct it with `mole config set <key> <value>`"})
+		} else {
+			checks = append(checks, check{Name: "config.toml", Status: checkOK, Detail: path})
+		}
+	}
+
+	if _, err := units.ResolveSystem(""); err != nil {
+		checks = append(checks, check{Name: "units", Status: checkFailed, Detail: err.Error(), Fix: "set MO_UNITS to si, binary, or auto"})
+	}
+	if rules, err := clean.CheckRules(); err != nil {
+		checks = append(checks, check{Name: "clean rules", Status: checkFailed, Detail: err.Error(), Fix: "fix or remove the rule in " + rules})
+	} else {
+		checks = append(checks, check{Name: "clean rules", Status: checkOK})
+	}
+	return checks
+}
+
+func hasFlags(flags *flag.FlagSet) bool {
+	found := false
+	flags.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
+
+// writeChecks prints the report, passing checks last, and returns the exit
+// code.
+func writeChecks(w io.Writer, checks []check) int {
+	rank := map[string]int{checkFailed: 0, checkDegraded: 1, checkSkipped: 2, checkOK: 3}
+	sorted := slices.Clone(checks)
+	slices.SortStableFunc(sorted, func(a, b check) int { return rank[a.Status] - rank[b.Status] })
+
+	for _, c := range sorted {
+		line := fmt.Sprintf("%-8s %-18s %s", strings.ToUpper(c.Status), c.Name, c.Detail)
+		if c.Fix != "" && c.Status != checkOK {
+			line += " → " + c.Fix
+		}
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+	return checksExitCode(checks)
+}
+
+func checksExitCode(checks []check) int {
+	for _, c := range checks {
+		if c.Status == checkDegraded || c.Status == checkFailed {
+			return exitcode.Partial
+		}
+	}
+	return exitcode.OK
+}
+
+func doctorUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole doctor [--json] [--output file]
+
+Checks Full Disk Access, the external tools Mole uses, the analyze scan
+cache, and that config.toml and clean.yaml load, with a fix for each
+problem. Exits 1 when anything is degraded or failed. --output saves the
+report to a file instead of printing it; a name ending in .gz is gzipped.
+`)
+}
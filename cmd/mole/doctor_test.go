@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToolChecks(t *testing.T) {
+	host := doctorHost{goos: "darwin", exists: func(name string) bool { return name != "mdfind" && name != "smartctl" }}
+	got := map[string]string{}
+	for _, c := range toolChecks(host) {
+		got[c.Name] = c.Status
+	}
+	want := map[string]string{"du": checkOK, "mdfind": checkDegraded, "powermetrics": checkOK, "smartctl": checkSkipped}
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("%s = %q, want %q", name, got[name], status)
+		}
+	}
+	if _, ok := got["nvidia-smi"]; ok {
+		t.Error("nvidia-smi is linux-only but was checked on darwin")
+	}
+}
+
+func TestCacheChecks(t *testing.T) {
+	home := t.TempDir()
+	host := doctorHost{home: home, now: time.Now()}
+	if c := cacheChecks(host); len(c) != 1 || c[0].Status != checkSkipped {
+		t.Fatalf("missing cache = %+v, want skipped", c)
+	}
+
+	dir := filepath.Join(home, ".cache", "mole")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, age := range map[string]time.Duration{"a.cache": 0, "b.cache": 10 * 24 * time.Hour} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, make([]byte, 1000), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		stamp := host.now.Add(-age)
+		if err := os.Chtimes(path, stamp, stamp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "overview_sizes.json.corrupt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := cacheChecks(host)
+	if len(checks) != 2 {
+		t.Fatalf("checks = %+v, want cache and size store", checks)
+	}
+	if c := checks[0]; c.Status != checkOK || !strings.HasPrefix(c.Detail, "2 scans, 2.0 kB, 1 past their week") {
+		t.Errorf("cache check = %+v", c)
+	}
+	if c := checks[1]; c.Status != checkDegraded {
+		t.Errorf("corrupt size store = %+v, want degraded", c)
+	}
+}
+
+func TestWriteChecksOrdersAndExits(t *testing.T) {
+	var out strings.Builder
+	code := writeChecks(&out, []check{
+		{Name: "du", Status: checkOK, Detail: "analyze folder sizes"},
+		{Name: "mdfind", Status: checkDegraded, Detail: "not found", Fix: "reinstall"},
+	})
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "DEGRADED mdfind") || !strings.HasSuffix(lines[0], "→ reinstall") {
+		t.Errorf("output:\n%s", out.String())
+	}
+	if code := writeChecks(&out, []check{{Name: "du", Status: checkOK}, {Name: "smartctl", Status: checkSkipped}}); code != 0 {
+		t.Errorf("skipped-only exit code = %d, want 0", code)
+	}
+}
+
+func TestConfigChecksReportsBadValue(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("MO_PROFILE", "")
+	path := filepath.Join(home, ".config", "mole", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("[status]\nbogus = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range configChecks() {
+		if c.Name == "config.toml" && c.Status != checkFailed {
+			t.Errorf("config check = %+v, want failed for an unknown status key", c)
+		}
+	}
+}
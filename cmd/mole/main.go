@@ -0,0 +1,267 @@
+// Command mole is the single Go binary behind `mole analyze`,
+// `mole status`, the `mole clean` rules engine, and the `mole serve` and
+// `mole mcp` automation servers. Releases install it under the old
+// analyze-go and status-go names too, so the shell entrypoint keeps
+// working; the name it runs as picks the command, the way busybox does.
+//
+// Run as mole, it takes flags shared by every command before the
+// subcommand:
+//
+//	mole [--debug] [--theme name] [--no-color] [--units si|binary|auto] [--lang code] [--profile name] [--error-format text|json] [--output file] <command> [args]
+//
+// and runs analyze, status, clean, serve, mcp, and helper in process.
+// Every other command (optimize, purge, uninstall, ...) is a shell script,
+// so it is handed to the mole entrypoint installed next to the bin
+// directory. The shell's own `mo clean` reaches the rules engine through
+// `mo clean --rules`.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/tw93/mole/internal/analyze"
+	"github.com/tw93/mole/internal/clean"
+	"github.com/tw93/mole/internal/config"
+	"github.com/tw93/mole/internal/crash"
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/helper"
+	"github.com/tw93/mole/internal/serve"
+	"github.com/tw93/mole/internal/status"
+	"github.com/tw93/mole/internal/version"
+)
+
+// commands are the subcommands this binary runs itself.
+var commands = map[string]struct {
+	run   func(args []string)
+	flags *flag.FlagSet
+}{
+	"analyze": {analyze.Main, analyze.Flags},
+	"clean":   {clean.Main, clean.Flags},
+	"helper":  {helper.Main, helper.Flags},
+	"mcp":     {serve.MCPMain, serve.MCPFlags},
+	"serve":   {serve.Main, serve.Flags},
+	"status":  {status.Main, status.Flags},
+}
+
+var (
+	root       = flag.NewFlagSet("mole", flag.ExitOnError)
+	debug      = root.Bool("debug", false, "log timings and external commands (sets MO_DEBUG=1)")
+	themeArg   = root.String("theme", "", "color theme for every command (sets MO_THEME)")
+	noColor    = root.Bool("no-color", false, "plain ASCII output without color (sets NO_COLOR=1)")
+	unitsArg   = root.String("units", "", "byte units: si, binary, or auto (sets MO_UNITS)")
+	langArg    = root.String("lang", "", "interface language, e.g. en or zh (sets MO_LANG)")
+	profileArg = root.String("profile", "", "apply a profile from ~/.config/mole/config.toml (sets MO_PROFILE)")
+	errFormat  = root.String("error-format", "", "print errors as text or json (sets MO_ERROR_FORMAT)")
+	outputArg  = root.String("output", "", "write the command's report or JSON to `file`, gzipped if it ends in .gz (sets MO_OUTPUT)")
+	showVer    = root.Bool("version", false, "print the version and exit")
+)
+
+func main() {
+	defer crash.Recover()
+
+	// Installs may only have the analyze-go and status-go links, so config,
+	// __flags, __profiles, and __bookmarks answer under every name, and
+	// "status-go mole <command>" reaches any root command the way
+	// "busybox <applet>" does.
+	if name := invokedAs(os.Args[0]); name != "mole" {
+		switch {
+		case len(os.Args) > 1 && os.Args[1] == "mole":
+			os.Args = os.Args[1:]
+		case len(os.Args) > 1 && isAnyName(os.Args[1]):
+		default:
+			if cmd, ok := commands[name]; ok {
+				cmd.run(os.Args[1:])
+				return
+			}
+		}
+	}
+
+	root.Usage = usage
+	root.Parse(os.Args[1:])
+	if *showVer {
+		fmt.Println("mole", version.Version)
+		return
+	}
+	exportSharedFlags()
+	if err := exitcode.SetFormat(""); err != nil {
+		os.Exit(exitcode.Report("mole", exitcode.Usage, err))
+	}
+
+	name, args := root.Arg(0), root.Args()
+	if len(args) > 0 {
+		args = args[1:]
+	}
+	switch name {
+	case "version":
+		fmt.Println("mole", version.Version)
+		return
+	case "config":
+		os.Exit(runConfig(args))
+	case "doctor":
+		os.Exit(runDoctor(args))
+	case "setup":
+		os.Exit(runSetup(args))
+	case "cache":
+		os.Exit(runCache(args))
+	case "__flags":
+		// Used by bin/completion.sh so completions track the real flags.
+		printFlags(args)
+		return
+	case "__profiles":
+		// Completes --profile in the scripts bin/completion.sh writes.
+		printProfiles()
+		return
+	case "__bookmarks":
+		// Completes mo analyze paths with the saved overview folders.
+		printBookmarks()
+		return
+	case "", "help":
+		if name == "" && entrypoint() != "" {
+			break
+		}
+		usage()
+		return
+	}
+	if cmd, ok := commands[name]; ok {
+		cmd.run(args)
+		return
+	}
+	os.Exit(runEntrypoint(root.Args()))
+}
+
+// isAnyName reports whether a root command runs under every binary name.
+func isAnyName(name string) bool {
+	return name == "config" || name == "__flags" || name == "__profiles" || name == "__bookmarks"
+}
+
+// invokedAs names the command a binary called analyze-go or
+// status-darwin-arm64 stands for: the base name up to the first dash.
+func invokedAs(argv0 string) string {
+	base := strings.TrimSuffix(filepath.Base(argv0), ".exe")
+	name, _, _ := strings.Cut(base, "-")
+	return name
+}
+
+// exportSharedFlags passes the root flags on through the environment
+// variables analyze, status, and the shell scripts already read, so each
+// command keeps a single place where its defaults are resolved.
+func exportSharedFlags() {
+	set := func(key, value string) {
+		if value != "" {
+			os.Setenv(key, value)
+		}
+	}
+	if *debug {
+		set("MO_DEBUG", "1")
+	}
+	if *noColor {
+		set("NO_COLOR", "1")
+	}
+	set("MO_THEME", *themeArg)
+	set("MO_UNITS", *unitsArg)
+	set("MO_LANG", *langArg)
+	set("MO_PROFILE", *profileArg)
+	set("MO_ERROR_FORMAT", *errFormat)
+	set("MO_OUTPUT", *outputArg)
+}
+
+// printFlags lists the flags of the named Go subcommands, one per line.
+func printFlags(names []string) {
+	for _, name := range names {
+		cmd, ok := commands[name]
+		if !ok {
+			continue
+		}
+		cmd.flags.VisitAll(func(f *flag.Flag) { fmt.Println("--" + f.Name) })
+	}
+}
+
+// printProfiles lists the profile names in config.toml, one per line. A
+// broken file lists nothing; completion is no place for the error.
+func printProfiles() {
+	file, err := config.Load(config.Path())
+	if err != nil {
+		return
+	}
+	for _, name := range file.Profiles() {
+		fmt.Println(name)
+	}
+}
+
+// printBookmarks lists the folders saved as analyze targets in config.toml,
+// one per line. Like printProfiles, a broken file lists nothing.
+func printBookmarks() {
+	file, err := config.Load(config.Path())
+	if err != nil {
+		return
+	}
+	for _, folder := range file.Bookmarks() {
+		fmt.Println(folder)
+	}
+}
+
+// entrypoint finds the mole shell script installed one level above the
+// directory holding this binary, or returns "".
+func entrypoint() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	script := filepath.Join(filepath.Dir(filepath.Dir(exe)), "mole")
+	if info, err := os.Stat(script); err != nil || info.IsDir() {
+		return ""
+	}
+	return script
+}
+
+// runEntrypoint hands a shell-implemented command to the mole script and
+// returns its exit code.
+func runEntrypoint(args []string) int {
+	script := entrypoint()
+	if script == "" {
+		return exitcode.Report("mole", exitcode.Usage, fmt.Errorf("mole: unknown command %q; run 'mole help'", root.Arg(0)))
+	}
+	cmd := exec.Command(script, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			return exit.ExitCode()
+		}
+		return exitcode.Report("mole", exitcode.Failed, fmt.Errorf("mole: %w", err))
+	}
+	return exitcode.OK
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole [flags] <command> [args]
+
+Commands:
+  analyze     Explore disk usage
+  status      Monitor system health
+  clean       Run the YAML cleanup rules (mole clean --help)
+  config      Show or change ~/.config/mole/config.toml
+  doctor      Check permissions, tools, caches, and settings
+  setup       Walk through first-run setup again
+  cache       List, size, or clear the analyze scan cache
+  serve       Run scans and read metrics over JSON-RPC (mole serve --stdio)
+  mcp         Serve scans and metrics to AI assistants over MCP
+  helper      Install or inspect the privileged helper (mole helper --help)
+  version     Show version
+  help        Show this help
+
+Other commands (uninstall, optimize, purge, ...) run through the
+mole shell entrypoint. Flags shared by every command:
+
+`)
+	root.PrintDefaults()
+}
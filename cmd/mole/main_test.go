@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestInvokedAs(t *testing.T) {
+	for argv0, want := range map[string]string{
+		"/opt/mole/bin/analyze-go":  "analyze",
+		"status-darwin-arm64":       "status",
+		"./mole":                    "mole",
+		"/usr/local/bin/mole-go":    "mole",
+		"/tmp/go-build123/exe/mole": "mole",
+	} {
+		if got := invokedAs(argv0); got != want {
+			t.Errorf("invokedAs(%q) = %q, want %q", argv0, got, want)
+		}
+	}
+}
+
+func TestConfigFlag(t *testing.T) {
+	for key, want := range map[string]string{
+		"status.interval":              "interval",
+		"status.proc_cpu_threshold":    "proc-cpu-threshold",
+		"theme":                        "theme",
+		"profiles.work.status.compact": "compact",
+	} {
+		f, err := configFlag(key)
+		if err != nil || f.Name != want {
+			t.Errorf("configFlag(%q) = %v, %v; want --%s", key, f, err, want)
+		}
+	}
+	for _, key := range []string{"status.bogus", "bogus", "nope.theme", "profiles.work", "a.b.c"} {
+		if _, err := configFlag(key); err == nil {
+			t.Errorf("configFlag(%q) should fail", key)
+		}
+	}
+	if got := profileKey("work", "status.interval"); got != "profiles.work.status.interval" {
+		t.Errorf("profileKey = %q", got)
+	}
+}
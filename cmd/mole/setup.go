@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+
+	"github.com/tw93/mole/internal/config"
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/theme"
+)
+
+// setupMarker sits next to config.toml once the wizard has run, finished
+// or skipped, so the menu does not offer it again.
+func setupMarker(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "setup_done")
+}
+
+// targetCandidates are the folders under home the wizard offers as analyze
+// overview targets, when they exist.
+var targetCandidates = []string{"Projects", "Developer", "Code", "src", "Documents", "Desktop", "Movies"}
+
+// runSetup implements `mole setup`: a short wizard that checks Full Disk
+// Access, writes units, theme, and analyze targets to config.toml, and
+// lists the optional tools that unlock more. The menu runs it with
+// --first-run, which does nothing once Mole has a config or was set up.
+func runSetup(args []string) int {
+	flags := flag.NewFlagSet("setup", flag.ContinueOnError)
+	flags.Usage = setupUsage
+	firstRun := flags.Bool("first-run", false, "run only if Mole has never been set up")
+	if err := flags.Parse(args); err != nil {
+		return exitcode.Usage
+	}
+	path := config.Path()
+	if path == "" {
+		return exitcode.Report("setup", exitcode.Failed, errors.New("mole setup: no home directory"))
+	}
+	marker := setupMarker(path)
+	if *firstRun && (fileExists(path) || fileExists(marker)) {
+		return exitcode.OK
+	}
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		if *firstRun {
+			return exitcode.OK
+		}
+		return exitcode.Report("setup", exitcode.Usage, errors.New("mole setup: needs a terminal; use `mole config set` in scripts"))
+	}
+	host, err := hostDoctor()
+	if err != nil {
+		return exitcode.Report("setup", exitcode.Failed, fmt.Errorf("mole setup: %w", err))
+	}
+
+	w := &wizard{in: bufio.NewReader(os.Stdin), out: os.Stdout, host: host, path: path}
+	err = w.run()
+	// Skipping counts too: the menu should not ask again either way.
+	if markErr := os.MkdirAll(filepath.Dir(marker), 0o755); markErr == nil {
+		os.WriteFile(marker, []byte(time.Now().Format(time.RFC3339)+"\n"), 0o644)
+	}
+	if err != nil {
+		return exitcode.Report("setup", exitcode.Failed, fmt.Errorf("mole setup: %w", err))
+	}
+	return exitcode.OK
+}
+
+// wizard asks the setup questions on in and answers on out. Enter keeps
+// the default in brackets, and end of input keeps every default left.
+type wizard struct {
+	in   *bufio.Reader
+	out  io.Writer
+	host doctorHost
+	path string
+	eof  bool
+}
+
+func (w *wizard) run() error {
+	fmt.Fprintln(w.out, "Welcome to Mole. A few questions set it up; press Enter to keep the answer in brackets.")
+	if !w.yes("Set up Mole now?", true) {
+		fmt.Fprintln(w.out, "Skipped. Run `mole setup` whenever you like.")
+		return nil
+	}
+
+	w.diskAccess()
+	if err := w.settings(); err != nil {
+		return err
+	}
+	w.tools()
+	fmt.Fprintln(w.out, "\nDone. `mo doctor` checks all of this again, and `mole setup` changes your answers.")
+	return nil
+}
+
+// diskAccess reports Full Disk Access, which only macOS has.
+func (w *wizard) diskAccess() {
+	for _, c := range diskAccessChecks(w.host) {
+		fmt.Fprintln(w.out, "\nFull Disk Access")
+		switch c.Status {
+		case checkOK:
+			fmt.Fprintln(w.out, "  Granted to this terminal.")
+		case checkSkipped:
+			fmt.Fprintf(w.out, "  Could not check: %s.\n", c.Detail)
+		default:
+			fmt.Fprintf(w.out, "  Not granted: %s.\n", strings.TrimPrefix(c.Detail, "not granted; "))
+			fmt.Fprintf(w.out, "  To fix it, %s, then restart the terminal.\n", c.Fix)
+		}
+	}
+}
+
+// settings asks for units, theme, and analyze targets and writes the ones
+// that changed.
+func (w *wizard) settings() error {
+	file, err := config.Load(w.path)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w.out, "\nSettings")
+	if fileExists(w.path) {
+		fmt.Fprintf(w.out, "  %s exists; only the answers you change are written to it.\n", w.path)
+	} else if !w.yes("  Create "+w.path+" for your settings?", true) {
+		return nil
+	}
+
+	current := func(key, def string) string {
+		if value, ok := file.Get(key); ok {
+			return value
+		}
+		return def
+	}
+	answers := [][2]string{
+		{"units", w.choose("  Byte units: auto, si (GB), or binary (GiB)", []string{"auto", "si", "binary"}, current("units", "auto"))},
+		{"theme", w.choose("  Color theme: "+strings.Join(theme.Names(), ", "), theme.Names(), current("theme", theme.Dark.Name))},
+	}
+	if _, err := configFlag("analyze.targets"); err == nil {
+		answers = append(answers, [2]string{"analyze.targets", w.targets(current("analyze.targets", ""))})
+	}
+
+	for _, a := range answers {
+		key, value := a[0], a[1]
+		if old, ok := file.Get(key); (ok && old == value) || (!ok && value == defaultValue(key)) {
+			continue
+		}
+		f, err := configFlag(key)
+		if err != nil {
+			return err
+		}
+		literal, err := config.Literal(f, value)
+		if err != nil {
+			return err
+		}
+		if err := config.Set(w.path, key, literal); err != nil {
+			return err
+		}
+	}
+	if !fileExists(w.path) {
+		// Every answer was a default; the file still marks Mole as set up
+		// and gives `mole config set` a place to start.
+		if err := os.MkdirAll(filepath.Dir(w.path), 0o755); err != nil {
+			return err
+		}
+		header := "# Mole settings. Keys are flag names; see `mole config --help`.\n"
+		if err := os.WriteFile(w.path, []byte(header), 0o644); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(w.out, "  Saved to %s.\n", w.path)
+	return nil
+}
+
+// defaultValue is what a key means when config.toml leaves it out.
+func defaultValue(key string) string {
+	switch key {
+	case "units":
+		return "auto"
+	case "theme":
+		return theme.Dark.Name
+	}
+	return ""
+}
+
+// targets asks which folders the analyze overview lists, by number from
+// the candidates that exist or as paths.
+func (w *wizard) targets(def string) string {
+	var found []string
+	for _, name := range targetCandidates {
+		if info, err := os.Stat(filepath.Join(w.host.home, name)); err == nil && info.IsDir() {
+			found = append(found, "~/"+name)
+		}
+	}
+	fmt.Fprintln(w.out, "  Folders for the analyze overview to list next to Home and Applications:")
+	for i, name := range found {
+		fmt.Fprintf(w.out, "    %d. %s\n", i+1, name)
+	}
+	shown := def
+	if shown == "" {
+		shown = "none"
+	}
+	answer := w.ask(fmt.Sprintf("  Numbers or paths, separated by commas, or - for none [%s]: ", shown))
+	switch answer {
+	case "":
+		return def
+	case "-":
+		return ""
+	}
+	var picked []string
+	for _, item := range strings.Split(answer, ",") {
+		item = strings.TrimSpace(item)
+		if n, err := strconv.Atoi(item); err == nil && n >= 1 && n <= len(found) {
+			item = found[n-1]
+		}
+		if item != "" && !slices.Contains(picked, item) {
+			picked = append(picked, item)
+		}
+	}
+	return strings.Join(picked, ",")
+}
+
+// tools explains the optional tools that are missing and what each adds.
+func (w *wizard) tools() {
+	var missing []check
+	for _, c := range toolChecks(w.host) {
+		if c.Status != checkOK {
+			missing = append(missing, c)
+		}
+	}
+	fmt.Fprintln(w.out, "\nOptional tools")
+	if len(missing) == 0 {
+		fmt.Fprintln(w.out, "  Everything Mole can use is installed.")
+		return
+	}
+	for _, c := range missing {
+		detail := strings.TrimPrefix(strings.TrimPrefix(c.Detail, "not installed; no "), "not found; no ")
+		fmt.Fprintf(w.out, "  %-12s adds %s: %s\n", c.Name, detail, c.Fix)
+	}
+}
+
+// ask prints prompt and returns the trimmed line typed.
+func (w *wizard) ask(prompt string) string {
+	fmt.Fprint(w.out, prompt)
+	if w.eof {
+		fmt.Fprintln(w.out)
+		return ""
+	}
+	line, err := w.in.ReadString('\n')
+	if err != nil {
+		w.eof = true
+		fmt.Fprintln(w.out)
+	}
+	return strings.TrimSpace(line)
+}
+
+func (w *wizard) yes(prompt string, def bool) bool {
+	hint := " [Y/n] "
+	if !def {
+		hint = " [y/N] "
+	}
+	for {
+		switch strings.ToLower(w.ask(prompt + hint)) {
+		case "":
+			return def
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+	}
+}
+
+// choose asks until the answer is one of options.
+func (w *wizard) choose(prompt string, options []string, def string) string {
+	for {
+		answer := strings.ToLower(w.ask(fmt.Sprintf("%s [%s]: ", prompt, def)))
+		if answer == "" {
+			return def
+		}
+		if slices.Contains(options, answer) {
+			return answer
+		}
+		fmt.Fprintf(w.out, "  Pick one of %s.\n", strings.Join(options, ", "))
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func setupUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole setup
+
+Walks through Full Disk Access, creates ~/.config/mole/config.toml with
+your byte units, color theme, and analyze overview folders, and lists the
+optional tools that unlock more. The menu runs it on first launch.
+`)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runWizard(t *testing.T, input string) (path, out string) {
+	t.Helper()
+	home := t.TempDir()
+	path = filepath.Join(home, ".config", "mole", "config.toml")
+	var b strings.Builder
+	w := &wizard{
+		in:   bufio.NewReader(strings.NewReader(input)),
+		out:  &b,
+		host: doctorHost{goos: "linux", home: home, exists: func(name string) bool { return name != "smartctl" }},
+		path: path,
+	}
+	if err := w.run(); err != nil {
+		t.Fatal(err)
+	}
+	return path, b.String()
+}
+
+func TestWizardWritesChangedSettings(t *testing.T) {
+	// Set up, create the file, "gib" is refused, then binary; the theme is
+	// left at its default.
+	path, out := runWizard(t, "\ny\ngib\nbinary\n\n")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `units = "binary"`) || strings.Contains(string(data), "theme") {
+		t.Fatalf("config.toml =\n%s", data)
+	}
+	if !strings.Contains(out, "Pick one of auto, si, binary.") {
+		t.Errorf("an invalid unit was not refused:\n%s", out)
+	}
+	if !strings.Contains(out, "smartctl     adds disk SMART health") || strings.Contains(out, "  du ") {
+		t.Errorf("want only the missing tools explained:\n%s", out)
+	}
+}
+
+func TestWizardSkip(t *testing.T) {
+	path, out := runWizard(t, "n\n")
+	if fileExists(path) {
+		t.Fatal("skipping wrote config.toml")
+	}
+	if !strings.Contains(out, "Skipped.") {
+		t.Fatalf("out = %q", out)
+	}
+}
+
+func TestWizardDeclinesConfig(t *testing.T) {
+	path, out := runWizard(t, "y\nn\n")
+	if fileExists(path) {
+		t.Fatal("declining still wrote config.toml")
+	}
+	if !strings.Contains(out, "Optional tools") {
+		t.Fatalf("tools step missing:\n%s", out)
+	}
+}
+
+func TestWizardKeepsDefaults(t *testing.T) {
+	path, _ := runWizard(t, "y\ny\n\n\n")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("config.toml was not created: %v", err)
+	}
+	if strings.Contains(string(data), "=") {
+		t.Fatalf("defaults were written out:\n%s", data)
+	}
+}
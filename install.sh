@@ -623,28 +623,35 @@ create_directories() {
 }
 
 # Binary install helpers
+
+# analyze-go and status-go are both the mole binary, which picks its command
+# from the name it runs under, so a source install builds ./cmd/mole once and
+# copies the installed result for the other name.
+SOURCE_BUILT_BINARY=""
+
 build_binary_from_source() {
     local binary_name="$1"
     local target_path="$2"
-    local cmd_dir=""
 
     case "$binary_name" in
-        analyze)
-            cmd_dir="cmd/analyze"
-            ;;
-        status)
-            cmd_dir="cmd/status"
-            ;;
+        analyze | status) ;;
         *)
             return 1
             ;;
     esac
 
+    if [[ -n "$SOURCE_BUILT_BINARY" && -x "$SOURCE_BUILT_BINARY" ]] &&
+        cp "$SOURCE_BUILT_BINARY" "$target_path" 2> /dev/null; then
+        chmod +x "$target_path"
+        log_success "Built ${binary_name} from source"
+        return 0
+    fi
+
     if ! command -v go > /dev/null 2>&1; then
         return 1
     fi
 
-    if [[ ! -d "$SOURCE_DIR/$cmd_dir" ]]; then
+    if [[ ! -d "$SOURCE_DIR/cmd/mole" ]]; then
         return 1
     fi
 
@@ -654,9 +661,10 @@ build_binary_from_source() {
         echo "Building ${binary_name} from source..."
     fi
 
-    if (cd "$SOURCE_DIR" && go build -ldflags="-s -w" -o "$target_path" "./$cmd_dir" > /dev/null 2>&1); then
+    if (cd "$SOURCE_DIR" && go build -ldflags="-s -w" -o "$target_path" ./cmd/mole > /dev/null 2>&1); then
         if [[ -t 1 ]]; then stop_line_spinner; fi
         chmod +x "$target_path"
+        SOURCE_BUILT_BINARY="$CONFIG_DIR/bin/${binary_name}-go"
         log_success "Built ${binary_name} from source"
         return 0
     fi
@@ -1,6 +1,6 @@
 //go:build darwin
 
-package main
+package analyze
 
 import (
 	"slices"
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tw93/mole/pkg/diskscan"
 )
 
 func topFilesFixture() model {
@@ -170,16 +171,13 @@ func treeFixture() model {
 		{Name: "logs", Path: "/tmp/p/logs", Size: 200, IsDir: true},
 		{Name: "node_modules", Path: "/tmp/p/node_modules", Size: 100, IsDir: true},
 	}
-	var filesScanned, dirsScanned, bytesScanned int64
 	return model{
 		path:          "/tmp/p",
 		entriesAll:    entries,
 		entries:       slices.Clone(entries),
 		multiSelected: map[string]bool{},
 		cache:         map[string]historyEntry{},
-		filesScanned:  &filesScanned,
-		dirsScanned:   &dirsScanned,
-		bytesScanned:  &bytesScanned,
+		progress:      &diskscan.Progress{},
 		height:        40,
 		width:         120,
 	}
@@ -1,29 +1,22 @@
 //go:build darwin
 
-package main
+package analyze
 
 import (
-	"context"
-	"encoding/gob"
 	"fmt"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
-	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tw93/mole/internal/theme"
+	"github.com/tw93/mole/pkg/diskscan"
 )
 
-func resetOverviewSnapshotForTest() {
-	overviewSnapshotMu.Lock()
-	overviewSnapshotCache = nil
-	overviewSnapshotLoaded = false
-	overviewSnapshotMu.Unlock()
-}
-
 func runScanResultCmd(t *testing.T, cmd tea.Cmd) scanResultMsg {
 	t.Helper()
 
@@ -95,6 +88,15 @@ func cancelAndDrainLiveScan(start liveScanStartMsg) {
 	}
 }
 
+// allocatedSize is the size a scan counts for a file: its allocated
+// blocks, capped at its length.
+func allocatedSize(info os.FileInfo) int64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return min(stat.Blocks*512, info.Size())
+	}
+	return info.Size()
+}
+
 func rowContaining(view, needle string) string {
 	for line := range strings.SplitSeq(view, "\n") {
 		if strings.Contains(line, needle) {
@@ -108,139 +110,6 @@ func progressFillCount(row string) int {
 	return strings.Count(row, "█") + strings.Count(row, "▓") + strings.Count(row, "▒")
 }
 
-func TestScanPathConcurrentBasic(t *testing.T) {
-	root := t.TempDir()
-
-	rootFile := filepath.Join(root, "root.txt")
-	if err := os.WriteFile(rootFile, []byte("root-data"), 0o644); err != nil {
-		t.Fatalf("write root file: %v", err)
-	}
-
-	nested := filepath.Join(root, "nested")
-	if err := os.MkdirAll(nested, 0o755); err != nil {
-		t.Fatalf("create nested dir: %v", err)
-	}
-
-	fileOne := filepath.Join(nested, "a.bin")
-	if err := os.WriteFile(fileOne, []byte("alpha"), 0o644); err != nil {
-		t.Fatalf("write file one: %v", err)
-	}
-	fileTwo := filepath.Join(nested, "b.bin")
-	if err := os.WriteFile(fileTwo, []byte(strings.Repeat("b", 32)), 0o644); err != nil {
-		t.Fatalf("write file two: %v", err)
-	}
-
-	linkPath := filepath.Join(root, "link-to-a")
-	if err := os.Symlink(fileOne, linkPath); err != nil {
-		t.Fatalf("create symlink: %v", err)
-	}
-
-	var filesScanned, dirsScanned, bytesScanned int64
-	current := &atomic.Value{}
-	current.Store("")
-
-	result, err := scanPathConcurrent(root, &filesScanned, &dirsScanned, &bytesScanned, current)
-	if err != nil {
-		t.Fatalf("scanPathConcurrent returned error: %v", err)
-	}
-
-	linkInfo, err := os.Lstat(linkPath)
-	if err != nil {
-		t.Fatalf("stat symlink: %v", err)
-	}
-
-	expectedDirSize := int64(len("alpha") + len(strings.Repeat("b", 32)))
-	expectedRootFileSize := int64(len("root-data"))
-	expectedLinkSize := getActualFileSize(linkPath, linkInfo)
-	expectedTotal := expectedDirSize + expectedRootFileSize + expectedLinkSize
-
-	if result.TotalSize != expectedTotal {
-		t.Fatalf("expected total size %d, got %d", expectedTotal, result.TotalSize)
-	}
-
-	if got := atomic.LoadInt64(&filesScanned); got != 3 {
-		t.Fatalf("expected 3 files scanned, got %d", got)
-	}
-	if dirs := atomic.LoadInt64(&dirsScanned); dirs == 0 {
-		t.Fatalf("expected directory scan count to increase")
-	}
-	if bytes := atomic.LoadInt64(&bytesScanned); bytes == 0 {
-		t.Fatalf("expected byte counter to increase")
-	}
-	foundSymlink := false
-	for _, entry := range result.Entries {
-		if strings.HasSuffix(entry.Name, " →") {
-			foundSymlink = true
-			if entry.IsDir {
-				t.Fatalf("symlink entry should not be marked as directory")
-			}
-		}
-	}
-	if !foundSymlink {
-		t.Fatalf("expected symlink entry to be present in scan result")
-	}
-}
-
-// TestScanPathConcurrentDedupsHardlinks guards #906: a file with multiple
-// hardlinks (e.g. Final Cut Pro managed media) must be counted once, the way
-// `du` does, instead of once per link.
-func TestScanPathConcurrentDedupsHardlinks(t *testing.T) {
-	root := t.TempDir()
-
-	nested := filepath.Join(root, "nested")
-	other := filepath.Join(root, "other")
-	for _, d := range []string{nested, other} {
-		if err := os.MkdirAll(d, 0o755); err != nil {
-			t.Fatalf("mkdir %s: %v", d, err)
-		}
-	}
-
-	original := filepath.Join(nested, "media.bin")
-	if err := os.WriteFile(original, []byte(strings.Repeat("x", 4096)), 0o644); err != nil {
-		t.Fatalf("write original: %v", err)
-	}
-	// Two more hardlinks to the same inode, one in this dir and one in a
-	// sibling dir, so the shared scan-wide dedup set is exercised.
-	for _, link := range []string{
-		filepath.Join(nested, "media-copy.bin"),
-		filepath.Join(other, "media-link.bin"),
-	} {
-		if err := os.Link(original, link); err != nil {
-			t.Fatalf("hardlink %s: %v", link, err)
-		}
-	}
-	// An unrelated plain file that must still be counted in full.
-	plain := filepath.Join(other, "plain.bin")
-	if err := os.WriteFile(plain, []byte("plaindata"), 0o644); err != nil {
-		t.Fatalf("write plain: %v", err)
-	}
-
-	var filesScanned, dirsScanned, bytesScanned int64
-	current := &atomic.Value{}
-	current.Store("")
-
-	result, err := scanPathConcurrent(root, &filesScanned, &dirsScanned, &bytesScanned, current)
-	if err != nil {
-		t.Fatalf("scanPathConcurrent returned error: %v", err)
-	}
-
-	mediaInfo, err := os.Lstat(original)
-	if err != nil {
-		t.Fatalf("stat original: %v", err)
-	}
-	plainInfo, err := os.Lstat(plain)
-	if err != nil {
-		t.Fatalf("stat plain: %v", err)
-	}
-	want := getActualFileSize(original, mediaInfo) + getActualFileSize(plain, plainInfo)
-	if result.TotalSize != want {
-		t.Fatalf("expected hardlinked media counted once (total %d), got %d", want, result.TotalSize)
-	}
-	if !result.dedupedHardlink {
-		t.Fatalf("expected dedupedHardlink flag to be set when a hardlink is deduped")
-	}
-}
-
 func TestPerformScanForJSONCountsTopLevelFiles(t *testing.T) {
 	root := t.TempDir()
 
@@ -298,38 +167,6 @@ func TestDeletePathWithProgress(t *testing.T) {
 	}
 }
 
-func TestOverviewStoreAndLoad(t *testing.T) {
-	home := t.TempDir()
-	t.Setenv("HOME", home)
-	resetOverviewSnapshotForTest()
-	t.Cleanup(resetOverviewSnapshotForTest)
-
-	path := filepath.Join(home, "project")
-	want := int64(123456)
-
-	if err := storeOverviewSize(path, want); err != nil {
-		t.Fatalf("storeOverviewSize: %v", err)
-	}
-
-	got, err := loadStoredOverviewSize(path)
-	if err != nil {
-		t.Fatalf("loadStoredOverviewSize: %v", err)
-	}
-	if got != want {
-		t.Fatalf("snapshot mismatch: want %d, got %d", want, got)
-	}
-
-	// Reload from disk and ensure value persists.
-	resetOverviewSnapshotForTest()
-	got, err = loadStoredOverviewSize(path)
-	if err != nil {
-		t.Fatalf("loadStoredOverviewSize after reset: %v", err)
-	}
-	if got != want {
-		t.Fatalf("snapshot mismatch after reset: want %d, got %d", want, got)
-	}
-}
-
 func TestUpdateKeyEscGoesBackFromDirectoryView(t *testing.T) {
 	m := model{
 		path: "/tmp/child",
@@ -564,278 +401,6 @@ func TestDirectoryViewKeepsLowPercentRowsAligned(t *testing.T) {
 	}
 }
 
-func TestCacheSaveLoadRoundTrip(t *testing.T) {
-	home := t.TempDir()
-	t.Setenv("HOME", home)
-
-	target := filepath.Join(home, "cache-target")
-	if err := os.MkdirAll(target, 0o755); err != nil {
-		t.Fatalf("create target dir: %v", err)
-	}
-
-	result := scanResult{
-		Entries: []dirEntry{
-			{Name: "alpha", Path: filepath.Join(target, "alpha"), Size: 10, IsDir: true},
-		},
-		LargeFiles: []fileEntry{
-			{Name: "big.bin", Path: filepath.Join(target, "big.bin"), Size: 2048},
-		},
-		TotalSize: 42,
-	}
-
-	if err := saveCacheToDisk(target, result); err != nil {
-		t.Fatalf("saveCacheToDisk: %v", err)
-	}
-
-	cache, err := loadCacheFromDisk(target)
-	if err != nil {
-		t.Fatalf("loadCacheFromDisk: %v", err)
-	}
-	if cache.TotalSize != result.TotalSize {
-		t.Fatalf("total size mismatch: want %d, got %d", result.TotalSize, cache.TotalSize)
-	}
-	if len(cache.Entries) != len(result.Entries) {
-		t.Fatalf("entry count mismatch: want %d, got %d", len(result.Entries), len(cache.Entries))
-	}
-	if len(cache.LargeFiles) != len(result.LargeFiles) {
-		t.Fatalf("large file count mismatch: want %d, got %d", len(result.LargeFiles), len(cache.LargeFiles))
-	}
-}
-
-func TestPruneAnalyzerCacheDirRemovesOnlyExpiredCacheFiles(t *testing.T) {
-	cacheDir := t.TempDir()
-	now := time.Now()
-	oldTime := now.Add(-analyzerCacheTTL - time.Hour)
-	freshTime := now.Add(-time.Hour)
-
-	oldCache := filepath.Join(cacheDir, "old.cache")
-	freshCache := filepath.Join(cacheDir, "fresh.cache")
-	namedState := filepath.Join(cacheDir, overviewCacheFile)
-	cacheDirEntry := filepath.Join(cacheDir, "directory.cache")
-	symlinkTarget := filepath.Join(cacheDir, "target")
-	symlinkCache := filepath.Join(cacheDir, "link.cache")
-
-	for _, path := range []string{oldCache, freshCache, namedState, symlinkTarget} {
-		if err := os.WriteFile(path, []byte("cache"), 0o644); err != nil {
-			t.Fatalf("write %s: %v", path, err)
-		}
-	}
-	if err := os.Mkdir(cacheDirEntry, 0o755); err != nil {
-		t.Fatalf("mkdir cache dir entry: %v", err)
-	}
-	if err := os.Symlink(symlinkTarget, symlinkCache); err != nil {
-		t.Fatalf("symlink cache entry: %v", err)
-	}
-
-	for _, path := range []string{oldCache, namedState, cacheDirEntry, symlinkCache} {
-		if err := os.Chtimes(path, oldTime, oldTime); err != nil {
-			t.Fatalf("chtimes %s: %v", path, err)
-		}
-	}
-	if err := os.Chtimes(freshCache, freshTime, freshTime); err != nil {
-		t.Fatalf("chtimes fresh cache: %v", err)
-	}
-
-	if err := pruneAnalyzerCacheDir(cacheDir, now); err != nil {
-		t.Fatalf("pruneAnalyzerCacheDir: %v", err)
-	}
-
-	if _, err := os.Stat(oldCache); !os.IsNotExist(err) {
-		t.Fatalf("expected expired cache file to be removed, stat err: %v", err)
-	}
-	for _, path := range []string{freshCache, namedState, cacheDirEntry, symlinkCache} {
-		if _, err := os.Lstat(path); err != nil {
-			t.Fatalf("expected %s to be preserved: %v", path, err)
-		}
-	}
-}
-
-func TestPruneAnalyzerCacheDirMissingDirectory(t *testing.T) {
-	missing := filepath.Join(t.TempDir(), "missing")
-	if err := pruneAnalyzerCacheDir(missing, time.Now()); err != nil {
-		t.Fatalf("expected missing cache dir to be ignored, got: %v", err)
-	}
-}
-
-func TestPruneAnalyzerCacheDirIgnoresRemoveFailures(t *testing.T) {
-	if os.Geteuid() == 0 {
-		t.Skip("root can remove files from read-only directories")
-	}
-
-	cacheDir := t.TempDir()
-	oldCache := filepath.Join(cacheDir, "old.cache")
-	if err := os.WriteFile(oldCache, []byte("cache"), 0o644); err != nil {
-		t.Fatalf("write old cache: %v", err)
-	}
-	oldTime := time.Now().Add(-analyzerCacheTTL - time.Hour)
-	if err := os.Chtimes(oldCache, oldTime, oldTime); err != nil {
-		t.Fatalf("chtimes old cache: %v", err)
-	}
-
-	if err := os.Chmod(cacheDir, 0o555); err != nil {
-		t.Fatalf("chmod cache dir read-only: %v", err)
-	}
-	defer func() {
-		_ = os.Chmod(cacheDir, 0o755)
-	}()
-
-	if err := pruneAnalyzerCacheDir(cacheDir, time.Now()); err != nil {
-		t.Fatalf("expected remove failure to be ignored, got: %v", err)
-	}
-	if _, err := os.Stat(oldCache); err != nil {
-		t.Fatalf("expected failed removal to leave cache file in place: %v", err)
-	}
-}
-
-func TestScanPathConcurrentWarmsChildDirectoryCache(t *testing.T) {
-	home := t.TempDir()
-	t.Setenv("HOME", home)
-
-	root := filepath.Join(home, "root")
-	child := filepath.Join(root, "child")
-	if err := os.MkdirAll(child, 0o755); err != nil {
-		t.Fatalf("create child: %v", err)
-	}
-	if err := os.WriteFile(filepath.Join(root, "root.txt"), []byte("root-data"), 0o644); err != nil {
-		t.Fatalf("write root data: %v", err)
-	}
-	if err := os.WriteFile(filepath.Join(child, "data.bin"), []byte(strings.Repeat("x", 4096)), 0o644); err != nil {
-		t.Fatalf("write child data: %v", err)
-	}
-
-	var filesScanned, dirsScanned, bytesScanned int64
-	current := &atomic.Value{}
-	current.Store("")
-
-	if _, err := scanPathConcurrent(root, &filesScanned, &dirsScanned, &bytesScanned, current); err != nil {
-		t.Fatalf("scanPathConcurrent(root): %v", err)
-	}
-
-	cached, err := loadCacheFromDisk(child)
-	if err != nil {
-		t.Fatalf("expected warmed child cache, got error: %v", err)
-	}
-	if cached.TotalSize <= 0 {
-		t.Fatalf("expected positive cached child size, got %d", cached.TotalSize)
-	}
-	if len(cached.Entries) == 0 {
-		t.Fatalf("expected cached child entries to be populated")
-	}
-	if cached.TotalFiles != 1 {
-		t.Fatalf("expected warmed child cache to track local file count 1, got %d", cached.TotalFiles)
-	}
-	if !cached.NeedsRefresh {
-		t.Fatalf("expected warmed child cache to be marked for refresh")
-	}
-}
-
-func TestScanPathConcurrentUsesChildCacheLargeFiles(t *testing.T) {
-	home := t.TempDir()
-	t.Setenv("HOME", home)
-
-	root := filepath.Join(home, "root")
-	child := filepath.Join(root, "child")
-	if err := os.MkdirAll(child, 0o755); err != nil {
-		t.Fatalf("create child: %v", err)
-	}
-
-	largeFile := filepath.Join(child, "large.bin")
-	if err := os.WriteFile(largeFile, []byte(strings.Repeat("x", 2<<20)), 0o644); err != nil {
-		t.Fatalf("write large file: %v", err)
-	}
-
-	var childFiles, childDirs, childBytes int64
-	childCurrent := &atomic.Value{}
-	childCurrent.Store("")
-	childResult, err := scanPathConcurrent(child, &childFiles, &childDirs, &childBytes, childCurrent)
-	if err != nil {
-		t.Fatalf("scanPathConcurrent(child): %v", err)
-	}
-	if err := saveCacheToDisk(child, childResult); err != nil {
-		t.Fatalf("saveCacheToDisk(child): %v", err)
-	}
-
-	if err := os.Chmod(child, 0o000); err != nil {
-		t.Fatalf("chmod child unreadable: %v", err)
-	}
-	defer func() {
-		_ = os.Chmod(child, 0o755)
-	}()
-
-	var filesScanned, dirsScanned, bytesScanned int64
-	current := &atomic.Value{}
-	current.Store("")
-
-	result, err := scanPathConcurrent(root, &filesScanned, &dirsScanned, &bytesScanned, current)
-	if err != nil {
-		t.Fatalf("scanPathConcurrent(root): %v", err)
-	}
-
-	foundChild := false
-	for _, entry := range result.Entries {
-		if entry.Path == child {
-			foundChild = true
-			if entry.Size != childResult.TotalSize {
-				t.Fatalf("cached child size mismatch: want %d, got %d", childResult.TotalSize, entry.Size)
-			}
-			break
-		}
-	}
-	if !foundChild {
-		t.Fatalf("expected cached child directory in root entries")
-	}
-
-	foundLargeFile := false
-	for _, file := range result.LargeFiles {
-		if file.Path == largeFile {
-			foundLargeFile = true
-			break
-		}
-	}
-	if !foundLargeFile {
-		t.Fatalf("expected root large files to include cached child large file")
-	}
-}
-
-func TestScanPathConcurrentWarmsChildCachesWithoutRecursiveSpotlight(t *testing.T) {
-	home := t.TempDir()
-	t.Setenv("HOME", home)
-
-	root := filepath.Join(home, "root")
-	childOne := filepath.Join(root, "child-one")
-	childTwo := filepath.Join(root, "child-two")
-	for _, dir := range []string{childOne, childTwo} {
-		if err := os.MkdirAll(dir, 0o755); err != nil {
-			t.Fatalf("create dir %s: %v", dir, err)
-		}
-		if err := os.WriteFile(filepath.Join(dir, "data.bin"), []byte(strings.Repeat("x", 4096)), 0o644); err != nil {
-			t.Fatalf("write data in %s: %v", dir, err)
-		}
-	}
-
-	originalRunner := spotlightQueryRunner
-	spotlightRoots := []string{}
-	spotlightQueryRunner = func(_ context.Context, queryRoot, _ string) ([]byte, error) {
-		spotlightRoots = append(spotlightRoots, queryRoot)
-		return nil, nil
-	}
-	t.Cleanup(func() {
-		spotlightQueryRunner = originalRunner
-	})
-
-	var filesScanned, dirsScanned, bytesScanned int64
-	current := &atomic.Value{}
-	current.Store("")
-
-	if _, err := scanPathConcurrent(root, &filesScanned, &dirsScanned, &bytesScanned, current); err != nil {
-		t.Fatalf("scanPathConcurrent(root): %v", err)
-	}
-
-	if len(spotlightRoots) != 1 || spotlightRoots[0] != root {
-		t.Fatalf("expected only root spotlight invocation, got %q", spotlightRoots)
-	}
-}
-
 func TestScanCmdTreatsWarmedCacheAsStale(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
@@ -851,8 +416,8 @@ func TestScanCmdTreatsWarmedCacheAsStale(t *testing.T) {
 		TotalSize:  42,
 		TotalFiles: 1,
 	}
-	if err := saveCacheToDiskWithOptions(target, result, true); err != nil {
-		t.Fatalf("saveCacheToDiskWithOptions: %v", err)
+	if err := diskscan.SaveWarmedCache(target, result); err != nil {
+		t.Fatalf("SaveWarmedCache: %v", err)
 	}
 
 	m := newModel(target, false)
@@ -1435,15 +1000,15 @@ func TestEnterSelectedDirRefreshesStaleInMemoryCache(t *testing.T) {
 	if err != nil {
 		t.Fatalf("stat fresh file: %v", err)
 	}
-	freshSize := getActualFileSize(freshPath, freshInfo)
+	freshSize := allocatedSize(freshInfo)
 
 	warmed := scanResult{
 		Entries:    []dirEntry{{Name: "stale.bin", Path: filepath.Join(child, "stale.bin"), Size: 1}},
 		TotalSize:  1,
 		TotalFiles: 1,
 	}
-	if err := saveCacheToDiskWithOptions(child, warmed, true); err != nil {
-		t.Fatalf("saveCacheToDiskWithOptions: %v", err)
+	if err := diskscan.SaveWarmedCache(child, warmed); err != nil {
+		t.Fatalf("SaveWarmedCache: %v", err)
 	}
 
 	m := newModel(parent, false)
@@ -1501,15 +1066,15 @@ func TestGoBackRefreshesHistoryEntryNeedingRefresh(t *testing.T) {
 	if err != nil {
 		t.Fatalf("stat fresh file: %v", err)
 	}
-	freshSize := getActualFileSize(freshPath, freshInfo)
+	freshSize := allocatedSize(freshInfo)
 
 	warmed := scanResult{
 		Entries:    []dirEntry{{Name: "stale.bin", Path: filepath.Join(child, "stale.bin"), Size: 2}},
 		TotalSize:  2,
 		TotalFiles: 1,
 	}
-	if err := saveCacheToDiskWithOptions(child, warmed, true); err != nil {
-		t.Fatalf("saveCacheToDiskWithOptions: %v", err)
+	if err := diskscan.SaveWarmedCache(child, warmed); err != nil {
+		t.Fatalf("SaveWarmedCache: %v", err)
 	}
 
 	m := newModel(filepath.Join(child, "grandchild"), false)
@@ -1549,127 +1114,6 @@ func TestGoBackRefreshesHistoryEntryNeedingRefresh(t *testing.T) {
 	}
 }
 
-func TestScanPathConcurrentWarmsChildCacheWithLiveProgress(t *testing.T) {
-	home := t.TempDir()
-	t.Setenv("HOME", home)
-
-	root := filepath.Join(home, "root")
-	child := filepath.Join(root, "child")
-	if err := os.MkdirAll(child, 0o755); err != nil {
-		t.Fatalf("create child: %v", err)
-	}
-
-	const dirCount = 32
-	const filesPerDir = 256
-	for i := range dirCount {
-		dir := filepath.Join(child, fmt.Sprintf("dir-%02d", i))
-		if err := os.MkdirAll(dir, 0o755); err != nil {
-			t.Fatalf("create nested dir %s: %v", dir, err)
-		}
-		for j := range filesPerDir {
-			file := filepath.Join(dir, fmt.Sprintf("file-%03d.bin", j))
-			if err := os.WriteFile(file, []byte("x"), 0o644); err != nil {
-				t.Fatalf("write %s: %v", file, err)
-			}
-		}
-	}
-
-	var filesScanned, dirsScanned, bytesScanned int64
-	current := &atomic.Value{}
-	current.Store("")
-
-	done := make(chan struct{})
-	errCh := make(chan error, 1)
-	go func() {
-		_, err := scanPathConcurrent(root, &filesScanned, &dirsScanned, &bytesScanned, current)
-		errCh <- err
-		close(done)
-	}()
-
-	deadline := time.Now().Add(5 * time.Second)
-	sawLiveProgress := false
-	for time.Now().Before(deadline) {
-		if atomic.LoadInt64(&filesScanned) > 0 {
-			select {
-			case <-done:
-			default:
-				sawLiveProgress = true
-			}
-			if sawLiveProgress {
-				break
-			}
-		}
-		select {
-		case <-done:
-			if !sawLiveProgress {
-				t.Fatalf("expected live progress before child warm scan completed, final files=%d", atomic.LoadInt64(&filesScanned))
-			}
-		default:
-		}
-		time.Sleep(2 * time.Millisecond)
-	}
-
-	if !sawLiveProgress {
-		t.Fatalf("expected filesScanned to advance before warm child scan finished")
-	}
-
-	select {
-	case err := <-errCh:
-		if err != nil {
-			t.Fatalf("scanPathConcurrent(root): %v", err)
-		}
-	case <-time.After(5 * time.Second):
-		t.Fatalf("scan did not complete")
-	}
-}
-
-func TestMeasureOverviewSize(t *testing.T) {
-	home := t.TempDir()
-	t.Setenv("HOME", home)
-	resetOverviewSnapshotForTest()
-	t.Cleanup(resetOverviewSnapshotForTest)
-
-	target := filepath.Join(home, "measure")
-	if err := os.MkdirAll(target, 0o755); err != nil {
-		t.Fatalf("create target: %v", err)
-	}
-	content := []byte(strings.Repeat("x", 4096))
-	if err := os.WriteFile(filepath.Join(target, "data.bin"), content, 0o644); err != nil {
-		t.Fatalf("write file: %v", err)
-	}
-
-	size, err := measureOverviewSize(target)
-	if err != nil {
-		t.Fatalf("measureOverviewSize: %v", err)
-	}
-	if size <= 0 {
-		t.Fatalf("expected positive size, got %d", size)
-	}
-
-	// Ensure snapshot stored.
-	cached, err := loadStoredOverviewSize(target)
-	if err != nil {
-		t.Fatalf("loadStoredOverviewSize: %v", err)
-	}
-	if cached != size {
-		t.Fatalf("snapshot mismatch: want %d, got %d", size, cached)
-	}
-
-	// Ensure measureOverviewSize does not use cache
-	// APFS block size is 4KB, 4097 bytes should use more blocks
-	content = []byte(strings.Repeat("x", 4097))
-	if err := os.WriteFile(filepath.Join(target, "data2.bin"), content, 0o644); err != nil {
-		t.Fatalf("write file: %v", err)
-	}
-	size2, err := measureOverviewSize(target)
-	if err != nil {
-		t.Fatalf("measureOverviewSize: %v", err)
-	}
-	if size2 == size {
-		t.Fatalf("measureOverwiewSize used cache")
-	}
-}
-
 func TestIsHandledByMoClean(t *testing.T) {
 	tests := []struct {
 		name string
@@ -1756,352 +1200,90 @@ func TestIsCleanableDir(t *testing.T) {
 	}
 }
 
-func TestLoadCacheExpiresWhenDirectoryChanges(t *testing.T) {
-	home := t.TempDir()
-	t.Setenv("HOME", home)
-
-	target := filepath.Join(home, "change-target")
-	if err := os.MkdirAll(target, 0o755); err != nil {
-		t.Fatalf("create target: %v", err)
-	}
-
-	result := scanResult{TotalSize: 5}
-	if err := saveCacheToDisk(target, result); err != nil {
-		t.Fatalf("saveCacheToDisk: %v", err)
-	}
-
-	// Advance mtime beyond grace period.
-	time.Sleep(time.Millisecond * 10)
-	if err := os.Chtimes(target, time.Now(), time.Now()); err != nil {
-		t.Fatalf("chtimes: %v", err)
-	}
-
-	// Simulate older cache entry to exceed grace window.
-	cachePath, err := getCachePath(target)
-	if err != nil {
-		t.Fatalf("getCachePath: %v", err)
-	}
-	if _, err := os.Stat(cachePath); err != nil {
-		t.Fatalf("stat cache: %v", err)
-	}
-	oldTime := time.Now().Add(-cacheModTimeGrace - time.Minute)
-	if err := os.Chtimes(cachePath, oldTime, oldTime); err != nil {
-		t.Fatalf("chtimes cache: %v", err)
-	}
-
-	file, err := os.Open(cachePath)
-	if err != nil {
-		t.Fatalf("open cache: %v", err)
+func TestScanFinishedOnlyForLongScans(t *testing.T) {
+	if _, ok := scanFinished("/tmp/x", 1<<30, 5*time.Second); ok {
+		t.Fatal("a five-second scan should not be announced")
 	}
-	var entry cacheEntry
-	if err := gob.NewDecoder(file).Decode(&entry); err != nil {
-		t.Fatalf("decode cache: %v", err)
-	}
-	_ = file.Close()
-
-	entry.ScanTime = time.Now().Add(-8 * 24 * time.Hour)
-
-	tmp := cachePath + ".tmp"
-	f, err := os.Create(tmp)
-	if err != nil {
-		t.Fatalf("create tmp cache: %v", err)
-	}
-	if err := gob.NewEncoder(f).Encode(&entry); err != nil {
-		t.Fatalf("encode tmp cache: %v", err)
-	}
-	_ = f.Close()
-	if err := os.Rename(tmp, cachePath); err != nil {
-		t.Fatalf("rename tmp cache: %v", err)
-	}
-
-	if _, err := loadCacheFromDisk(target); err == nil {
-		t.Fatalf("expected cache load to fail after stale scan time")
+	msg, ok := scanFinished("/tmp/x", 1<<30, 20*time.Minute+400*time.Millisecond)
+	if !ok || !strings.HasSuffix(msg.Body, " in 20m0s") || !strings.HasPrefix(msg.Body, "/tmp/x: ") {
+		t.Fatalf("scanFinished = %+v, %v", msg, ok)
 	}
 }
 
-func TestLoadCacheReusesRecentEntryAfterDirectoryChanges(t *testing.T) {
+func TestTargetEntries(t *testing.T) {
 	home := t.TempDir()
-	t.Setenv("HOME", home)
-
-	target := filepath.Join(home, "recent-change-target")
-	if err := os.MkdirAll(target, 0o755); err != nil {
-		t.Fatalf("create target: %v", err)
+	projects := filepath.Join(home, "Projects")
+	if err := os.Mkdir(projects, 0o755); err != nil {
+		t.Fatal(err)
 	}
-
-	result := scanResult{TotalSize: 5, TotalFiles: 1}
-	if err := saveCacheToDisk(target, result); err != nil {
-		t.Fatalf("saveCacheToDisk: %v", err)
-	}
-
-	cachePath, err := getCachePath(target)
-	if err != nil {
-		t.Fatalf("getCachePath: %v", err)
-	}
-
-	file, err := os.Open(cachePath)
-	if err != nil {
-		t.Fatalf("open cache: %v", err)
-	}
-	var entry cacheEntry
-	if err := gob.NewDecoder(file).Decode(&entry); err != nil {
-		t.Fatalf("decode cache: %v", err)
-	}
-	_ = file.Close()
-
-	// Make cache entry look recently scanned, but older than mod time grace.
-	entry.ModTime = time.Now().Add(-2 * time.Hour)
-	entry.ScanTime = time.Now().Add(-1 * time.Hour)
-
-	tmp := cachePath + ".tmp"
-	f, err := os.Create(tmp)
-	if err != nil {
-		t.Fatalf("create tmp cache: %v", err)
-	}
-	if err := gob.NewEncoder(f).Encode(&entry); err != nil {
-		t.Fatalf("encode tmp cache: %v", err)
-	}
-	_ = f.Close()
-	if err := os.Rename(tmp, cachePath); err != nil {
-		t.Fatalf("rename tmp cache: %v", err)
-	}
-
-	if err := os.Chtimes(target, time.Now(), time.Now()); err != nil {
-		t.Fatalf("chtimes target: %v", err)
-	}
-
-	if _, err := loadCacheFromDisk(target); err != nil {
-		t.Fatalf("expected recent cache to be reused, got error: %v", err)
+	entries := targetEntries(" ~/Projects, ~/Missing, relative, ~, "+projects+"/", home)
+	if len(entries) != 1 || entries[0].Name != "Projects" || entries[0].Path != projects || entries[0].Size != -1 {
+		t.Fatalf("targetEntries = %+v, want ~/Projects once", entries)
 	}
 }
 
-func TestLoadCacheExpiresWhenModifiedAndReuseWindowPassed(t *testing.T) {
-	home := t.TempDir()
-	t.Setenv("HOME", home)
-
-	target := filepath.Join(home, "reuse-window-target")
-	if err := os.MkdirAll(target, 0o755); err != nil {
-		t.Fatalf("create target: %v", err)
-	}
-
-	result := scanResult{TotalSize: 5, TotalFiles: 1}
-	if err := saveCacheToDisk(target, result); err != nil {
-		t.Fatalf("saveCacheToDisk: %v", err)
-	}
-
-	cachePath, err := getCachePath(target)
-	if err != nil {
-		t.Fatalf("getCachePath: %v", err)
-	}
-
-	file, err := os.Open(cachePath)
-	if err != nil {
-		t.Fatalf("open cache: %v", err)
-	}
-	var entry cacheEntry
-	if err := gob.NewDecoder(file).Decode(&entry); err != nil {
-		t.Fatalf("decode cache: %v", err)
-	}
-	_ = file.Close()
-
-	// Within overall 7-day TTL but beyond reuse window.
-	entry.ModTime = time.Now().Add(-48 * time.Hour)
-	entry.ScanTime = time.Now().Add(-(cacheReuseWindow + time.Hour))
-
-	tmp := cachePath + ".tmp"
-	f, err := os.Create(tmp)
-	if err != nil {
-		t.Fatalf("create tmp cache: %v", err)
-	}
-	if err := gob.NewEncoder(f).Encode(&entry); err != nil {
-		t.Fatalf("encode tmp cache: %v", err)
-	}
-	_ = f.Close()
-	if err := os.Rename(tmp, cachePath); err != nil {
-		t.Fatalf("rename tmp cache: %v", err)
-	}
-
-	if err := os.Chtimes(target, time.Now(), time.Now()); err != nil {
-		t.Fatalf("chtimes target: %v", err)
-	}
-
-	if _, err := loadCacheFromDisk(target); err == nil {
-		t.Fatalf("expected cache load to fail after reuse window passes")
-	}
-}
-
-func TestLoadStaleCacheFromDiskAllowsRecentExpiredCache(t *testing.T) {
-	home := t.TempDir()
-	t.Setenv("HOME", home)
-
-	target := filepath.Join(home, "stale-cache-target")
-	if err := os.MkdirAll(target, 0o755); err != nil {
-		t.Fatalf("create target: %v", err)
-	}
-
-	result := scanResult{TotalSize: 7, TotalFiles: 2}
-	if err := saveCacheToDisk(target, result); err != nil {
-		t.Fatalf("saveCacheToDisk: %v", err)
-	}
-
-	cachePath, err := getCachePath(target)
-	if err != nil {
-		t.Fatalf("getCachePath: %v", err)
-	}
-	file, err := os.Open(cachePath)
-	if err != nil {
-		t.Fatalf("open cache: %v", err)
-	}
-	var entry cacheEntry
-	if err := gob.NewDecoder(file).Decode(&entry); err != nil {
-		t.Fatalf("decode cache: %v", err)
-	}
-	_ = file.Close()
-
-	// Expired for normal cache validation but still inside stale fallback window.
-	entry.ModTime = time.Now().Add(-48 * time.Hour)
-	entry.ScanTime = time.Now().Add(-48 * time.Hour)
-
-	tmp := cachePath + ".tmp"
-	f, err := os.Create(tmp)
-	if err != nil {
-		t.Fatalf("create tmp cache: %v", err)
-	}
-	if err := gob.NewEncoder(f).Encode(&entry); err != nil {
-		t.Fatalf("encode tmp cache: %v", err)
+func TestTopFilesViewListsSparseFiles(t *testing.T) {
+	m := topFilesFixture()
+	full := m.largeViewport()
+	m.sparseFiles = []sparseFile{
+		{Name: "disk.img", Path: "/tmp/p/vm/disk.img", Size: 4 << 20, Logical: 64 << 30},
+		{Name: "core", Path: "/tmp/p/core", Size: 1 << 20, Logical: 2 << 30},
 	}
-	_ = f.Close()
-	if err := os.Rename(tmp, cachePath); err != nil {
-		t.Fatalf("rename tmp cache: %v", err)
+	if got := m.largeViewport(); got != full-4 {
+		t.Errorf("largeViewport = %d, want %d with two sparse rows", got, full-4)
 	}
-
-	if err := os.Chtimes(target, time.Now(), time.Now()); err != nil {
-		t.Fatalf("chtimes target: %v", err)
+	view := m.View()
+	for _, want := range []string{"Sparse files", humanizeBytes(4<<20) + " / " + humanizeBytes(64<<30), "/tmp/p/vm/disk.img"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
 	}
 
-	if _, err := loadCacheFromDisk(target); err == nil {
-		t.Fatalf("expected normal cache load to fail")
-	}
-	if _, err := loadStaleCacheFromDisk(target); err != nil {
-		t.Fatalf("expected stale cache load to succeed, got error: %v", err)
+	m.removePathFromView("/tmp/p/core")
+	if len(m.sparseFiles) != 1 || m.sparseFiles[0].Name != "disk.img" {
+		t.Errorf("sparseFiles after delete = %+v", m.sparseFiles)
 	}
 }
 
-func TestLoadStaleCacheFromDiskExpiresByStaleTTL(t *testing.T) {
-	home := t.TempDir()
-	t.Setenv("HOME", home)
-
-	target := filepath.Join(home, "stale-cache-expired-target")
-	if err := os.MkdirAll(target, 0o755); err != nil {
-		t.Fatalf("create target: %v", err)
-	}
-
-	result := scanResult{TotalSize: 9, TotalFiles: 3}
-	if err := saveCacheToDisk(target, result); err != nil {
-		t.Fatalf("saveCacheToDisk: %v", err)
-	}
-
-	cachePath, err := getCachePath(target)
-	if err != nil {
-		t.Fatalf("getCachePath: %v", err)
-	}
-	file, err := os.Open(cachePath)
-	if err != nil {
-		t.Fatalf("open cache: %v", err)
-	}
-	var entry cacheEntry
-	if err := gob.NewDecoder(file).Decode(&entry); err != nil {
-		t.Fatalf("decode cache: %v", err)
-	}
-	_ = file.Close()
-
-	entry.ScanTime = time.Now().Add(-(staleCacheTTL + time.Hour))
-
-	tmp := cachePath + ".tmp"
-	f, err := os.Create(tmp)
-	if err != nil {
-		t.Fatalf("create tmp cache: %v", err)
-	}
-	if err := gob.NewEncoder(f).Encode(&entry); err != nil {
-		t.Fatalf("encode tmp cache: %v", err)
+func TestAgesView(t *testing.T) {
+	m := model{path: "/tmp/p", height: 40, width: 120, totalSize: 1000, ages: diskscan.Ages{100, 0, 200, 300, 300}}
+	m, _ = filterRune(t, m, 'a')
+	if !m.showAges {
+		t.Fatal("a did not open the age chart")
 	}
-	_ = f.Close()
-	if err := os.Rename(tmp, cachePath); err != nil {
-		t.Fatalf("rename tmp cache: %v", err)
-	}
-
-	if _, err := loadStaleCacheFromDisk(target); err == nil {
-		t.Fatalf("expected stale cache load to fail after stale TTL")
-	}
-}
-
-func TestScanPathPermissionError(t *testing.T) {
-	root := t.TempDir()
-	lockedDir := filepath.Join(root, "locked")
-	if err := os.Mkdir(lockedDir, 0o755); err != nil {
-		t.Fatalf("create locked dir: %v", err)
+	view := m.View()
+	for _, want := range []string{"Bytes by last modified", "Under 30 days", "Over 3 years", " 33.3%", "in folders sized without listing files"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
 	}
-
-	// Create a file before locking.
-	if err := os.WriteFile(filepath.Join(lockedDir, "secret.txt"), []byte("shh"), 0o644); err != nil {
-		t.Fatalf("write secret: %v", err)
+	if m, _ = filterRune(t, m, 'j'); !m.showAges {
+		t.Error("j closed the age chart")
 	}
-
-	// Remove permissions.
-	if err := os.Chmod(lockedDir, 0o000); err != nil {
-		t.Fatalf("chmod 000: %v", err)
+	if m, _ = filterKey(t, m, tea.KeyMsg{Type: tea.KeyEsc}); m.showAges {
+		t.Error("Esc left the age chart open")
 	}
-	defer func() {
-		// Restore permissions for cleanup.
-		_ = os.Chmod(lockedDir, 0o755)
-	}()
-
-	var files, dirs, bytes int64
-	current := &atomic.Value{}
-	current.Store("")
 
-	// Scanning the locked dir itself should fail.
-	_, err := scanPathConcurrent(lockedDir, &files, &dirs, &bytes, current)
-	if err == nil {
-		t.Fatalf("expected error scanning locked directory, got nil")
-	}
-	if !os.IsPermission(err) {
-		t.Logf("unexpected error type: %v", err)
+	m.scanning = true
+	if m, _ = filterRune(t, m, 'a'); m.showAges {
+		t.Error("the age chart opened mid-scan")
 	}
 }
 
-func TestCalculateDirSizeFastHighFanoutCompletes(t *testing.T) {
-	root := t.TempDir()
-
-	// Reproduce high fan-out nested directory pattern that previously risked semaphore deadlock.
-	const fanout = 256
-	for i := range fanout {
-		nested := filepath.Join(root, fmt.Sprintf("dir-%03d", i), "nested")
-		if err := os.MkdirAll(nested, 0o755); err != nil {
-			t.Fatalf("create nested dir: %v", err)
-		}
-		if err := os.WriteFile(filepath.Join(nested, "data.bin"), []byte("x"), 0o644); err != nil {
-			t.Fatalf("write nested file: %v", err)
-		}
+func TestColorProfile(t *testing.T) {
+	getenv := func(name string) string {
+		return map[string]string{"TERM": "xterm-256color"}[name]
 	}
-
-	var files, dirs, bytes int64
-	current := &atomic.Value{}
-	current.Store("")
-
-	done := make(chan int64, 1)
-	go func() {
-		done <- calculateDirSizeFast(root, &files, &dirs, &bytes, current)
-	}()
-
-	select {
-	case total := <-done:
-		if total <= 0 {
-			t.Fatalf("expected positive total size, got %d", total)
-		}
-		if got := atomic.LoadInt64(&files); got < fanout {
-			t.Fatalf("expected at least %d files scanned, got %d", fanout, got)
+	for _, tc := range []struct {
+		noColor, terminal bool
+		want              theme.Profile
+	}{
+		{false, true, theme.ANSI256},
+		{true, true, theme.NoColor},
+		{false, false, theme.NoColor}, // --output, or stdout piped
+	} {
+		if got := colorProfile(getenv, tc.noColor, tc.terminal); got != tc.want {
+			t.Errorf("colorProfile(noColor %v, terminal %v) = %v, want %v", tc.noColor, tc.terminal, got, tc.want)
 		}
-	case <-time.After(5 * time.Second):
-		t.Fatalf("calculateDirSizeFast did not complete under high fan-out")
 	}
 }
@@ -0,0 +1,246 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/output"
+)
+
+// backupStaleAge is how long a device can go without a backup before its
+// backups are flagged as belonging to a device no longer in use.
+const backupStaleAge = 365 * 24 * time.Hour
+
+var (
+	backupsFlags = flag.NewFlagSet("analyze backups", flag.ExitOnError)
+	backupsJSON  = backupsFlags.Bool("json", false, "print the devices as JSON")
+)
+
+func init() { backupsFlags.Usage = backupsUsage }
+
+func backupsUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole analyze backups [--json] [path]
+
+Lists the iPhone and iPad backups Finder keeps in path
+(~/Library/Application Support/MobileSync/Backup by default) by device,
+with their size and when each device was last backed up, and flags the
+devices not backed up in over a year. Nothing is changed.
+
+`)
+	backupsFlags.PrintDefaults()
+}
+
+// runBackupsMode implements `mole analyze backups` and exits on errors.
+func runBackupsMode(args []string) {
+	backupsFlags.Parse(args)
+	dir := ""
+	if backupsFlags.NArg() > 0 {
+		dir = backupsFlags.Arg(0)
+	} else if home, err := os.UserHomeDir(); err == nil {
+		dir = filepath.Join(home, "Library", "Application Support", "MobileSync", "Backup")
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		exitcode.Exit("analyze backups", exitcode.Usage, err)
+	}
+
+	devices, err := readBackups(abs)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		exitcode.Exit("analyze backups", exitcode.Failed, err)
+	}
+	report := backupsReport{Path: abs, Devices: devices}
+	now := time.Now()
+	for i := range report.Devices {
+		d := &report.Devices[i]
+		d.Stale = now.Sub(d.LastBackup) > backupStaleAge
+		report.TotalSize += d.Size
+		if d.Stale {
+			report.StaleSize += d.Size
+		}
+	}
+
+	out, err := output.Create(output.Path(*outputTo))
+	if err != nil {
+		exitcode.Exit("analyze backups", exitcode.Failed, err)
+	}
+	if *backupsJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		report.write(out)
+	}
+	if err != nil {
+		out.Abort()
+		exitcode.Exit("analyze backups", exitcode.Failed, err)
+	}
+	if err := out.Close(); err != nil {
+		exitcode.Exit("analyze backups", exitcode.Failed, err)
+	}
+}
+
+// deviceBackups are the backups of one device.
+type deviceBackups struct {
+	Name           string    `json:"name"`
+	ProductType    string    `json:"product_type,omitempty"`
+	ProductVersion string    `json:"product_version,omitempty"`
+	ID             string    `json:"id"`
+	Size           int64     `json:"size"`
+	LastBackup     time.Time `json:"last_backup"`
+	Stale          bool      `json:"stale"`
+	Paths          []string  `json:"paths"`
+}
+
+// backupInfo is what a backup's Info.plist says about it.
+type backupInfo struct {
+	DeviceName     string
+	ProductType    string
+	ProductVersion string
+	ID             string
+	LastBackup     time.Time
+}
+
+// readBackups reads each backup folder in dir and groups them by device,
+// the largest first. Finder keeps one backup per device, plus the ones
+// archived with a date after the device ID.
+func readBackups(dir string) ([]deviceBackups, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	byID := map[string]*deviceBackups{}
+	var devices []*deviceBackups
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		info, err := readBackupInfo(filepath.Join(path, "Info.plist"))
+		if err != nil {
+			continue
+		}
+		if info.ID == "" {
+			info.ID, _, _ = strings.Cut(e.Name(), "-")
+		}
+		if info.LastBackup.IsZero() {
+			if fi, err := e.Info(); err == nil {
+				info.LastBackup = fi.ModTime()
+			}
+		}
+		d := byID[info.ID]
+		if d == nil {
+			d = &deviceBackups{ID: info.ID}
+			byID[info.ID] = d
+			devices = append(devices, d)
+		}
+		if info.LastBackup.After(d.LastBackup) {
+			d.LastBackup = info.LastBackup
+			d.Name, d.ProductType, d.ProductVersion = info.DeviceName, info.ProductType, info.ProductVersion
+		}
+		d.Size += folderSize(path)
+		d.Paths = append(d.Paths, path)
+	}
+
+	result := make([]deviceBackups, 0, len(devices))
+	for _, d := range devices {
+		if d.Name == "" {
+			d.Name = d.ID
+		}
+		result = append(result, *d)
+	}
+	slices.SortFunc(result, func(a, b deviceBackups) int {
+		return cmp.Or(cmp.Compare(b.Size, a.Size), strings.Compare(a.Name, b.Name))
+	})
+	return result, nil
+}
+
+// readBackupInfo reads an Info.plist, converting it with plutil first when
+// it is a binary plist.
+func readBackupInfo(path string) (backupInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return backupInfo{}, err
+	}
+	if bytes.HasPrefix(data, []byte("bplist")) {
+		ctx, cancel := context.WithTimeout(context.Background(), openCommandTimeout)
+		defer cancel()
+		if data, err = exec.CommandContext(ctx, "plutil", "-convert", "xml1", "-o", "-", path).Output(); err != nil {
+			return backupInfo{}, err
+		}
+	}
+	return parseBackupInfo(data)
+}
+
+// parseBackupInfo reads the top-level keys of an XML Info.plist that
+// describe the device and the backup's date.
+func parseBackupInfo(data []byte) (backupInfo, error) {
+	values, err := parsePlistDict(data)
+	if err != nil {
+		return backupInfo{}, err
+	}
+	info := backupInfo{
+		DeviceName:     values["Device Name"],
+		ProductType:    values["Product Type"],
+		ProductVersion: values["Product Version"],
+		ID:             strings.ToLower(cmp.Or(values["Unique Identifier"], values["Target Identifier"])),
+	}
+	if at, err := time.Parse(time.RFC3339, values["Last Backup Date"]); err == nil {
+		info.LastBackup = at
+	}
+	if info.DeviceName == "" && info.ID == "" {
+		return backupInfo{}, errors.New("not a device backup")
+	}
+	return info, nil
+}
+
+// backupsReport is the result of `mole analyze backups`.
+type backupsReport struct {
+	Path      string          `json:"path"`
+	Devices   []deviceBackups `json:"devices"`
+	TotalSize int64           `json:"total_size"`
+	StaleSize int64           `json:"stale_size"`
+}
+
+func (r backupsReport) write(w io.Writer) {
+	if len(r.Devices) == 0 {
+		fmt.Fprintf(w, "No device backups in %s\n", displayPath(r.Path))
+		return
+	}
+	fmt.Fprintf(w, "Device backups in %s: %d devices, %s\n", displayPath(r.Path), len(r.Devices), humanizeBytes(r.TotalSize))
+	fmt.Fprintf(w, "\n%10s  %-11s  %s\n", "SIZE", "LAST BACKUP", "DEVICE")
+	for _, d := range r.Devices {
+		device := d.Name
+		if d.ProductType != "" {
+			device += fmt.Sprintf(" (%s", d.ProductType)
+			if d.ProductVersion != "" {
+				device += ", " + d.ProductVersion
+			}
+			device += ")"
+		}
+		if len(d.Paths) > 1 {
+			device += fmt.Sprintf(", %d backups", len(d.Paths))
+		}
+		if d.Stale {
+			device += colorYellow + ", not backed up in over a year" + colorReset
+		}
+		fmt.Fprintf(w, "%10s  %-11s  %s\n", humanizeBytes(d.Size), d.LastBackup.Local().Format("2006-01-02"), device)
+	}
+	if r.StaleSize > 0 {
+		fmt.Fprintf(w, "\nBackups of devices not seen in over a year take %s. Remove them in Finder: select a\ndevice in the sidebar, then Manage Backups.\n", humanizeBytes(r.StaleSize))
+	}
+}
@@ -0,0 +1,106 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeBackup(t *testing.T, dir, name, device, udid, date string, size int) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Join(path, "00"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	plist := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Applications</key>
+	<dict>
+		<key>Device Name</key>
+		<string>nested</string>
+	</dict>
+	<key>Device Name</key>
+	<string>` + device + `</string>
+	<key>iTunes Files</key>
+	<data>AAAA</data>
+	<key>Last Backup Date</key>
+	<date>` + date + `</date>
+	<key>Product Type</key>
+	<string>iPhone15,2</string>
+	<key>Product Version</key>
+	<string>18.1</string>
+	<key>Unique Identifier</key>
+	<string>` + udid + `</string>
+</dict>
+</plist>
+`
+	if err := os.WriteFile(filepath.Join(path, "Info.plist"), []byte(plist), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "00", "blob"), make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadBackups(t *testing.T) {
+	dir := t.TempDir()
+	writeBackup(t, dir, "00008110-AAA", "Work Phone", "00008110-AAA", "2026-10-12T08:00:00Z", 1000)
+	writeBackup(t, dir, "00008110-AAA-20250301-101010", "Old Name", "00008110-AAA", "2025-03-01T10:10:10Z", 500)
+	writeBackup(t, dir, "00008030-BBB", "Old iPad", "00008030-BBB", "2024-02-01T12:00:00Z", 3000)
+	os.MkdirAll(filepath.Join(dir, "empty"), 0o755)
+
+	devices, err := readBackups(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) != 2 {
+		t.Fatalf("devices = %+v", devices)
+	}
+	ipad, phone := devices[0], devices[1]
+	if ipad.Name != "Old iPad" || ipad.Size < 3000 || len(ipad.Paths) != 1 {
+		t.Errorf("ipad = %+v", ipad)
+	}
+	if phone.Name != "Work Phone" || phone.ID != "00008110-aaa" || len(phone.Paths) != 2 || phone.Size < 1500 {
+		t.Errorf("phone = %+v", phone)
+	}
+	if !phone.LastBackup.Equal(time.Date(2026, 10, 12, 8, 0, 0, 0, time.UTC)) || phone.ProductType != "iPhone15,2" {
+		t.Errorf("phone last backup %v, type %q", phone.LastBackup, phone.ProductType)
+	}
+}
+
+func TestBackupsReportFlagsStaleDevices(t *testing.T) {
+	report := backupsReport{
+		Path: "/tmp/Backup",
+		Devices: []deviceBackups{
+			{Name: "Old iPad", Size: 3 << 30, LastBackup: time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC), Stale: true, Paths: []string{"a"}},
+			{Name: "Work Phone", ProductType: "iPhone15,2", ProductVersion: "18.1", Size: 1 << 30, LastBackup: time.Date(2026, 10, 12, 12, 0, 0, 0, time.UTC), Paths: []string{"b", "c"}},
+		},
+		TotalSize: 4 << 30,
+		StaleSize: 3 << 30,
+	}
+	var buf bytes.Buffer
+	report.write(&buf)
+	out := buf.String()
+	for _, want := range []string{"2 devices", "Old iPad", "not backed up in over a year", "Work Phone (iPhone15,2, 18.1), 2 backups", "Manage Backups"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "over a year,") > 0 || strings.Count(out, "not backed up") != 1 {
+		t.Errorf("stale flag on the wrong device:\n%s", out)
+	}
+
+	plainColors(t)
+	buf.Reset()
+	report.write(&buf)
+	if strings.Contains(buf.String(), "\x1b") {
+		t.Errorf("plain report has escape codes: %q", buf.String())
+	}
+}
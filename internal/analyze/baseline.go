@@ -0,0 +1,232 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/i18n"
+	"github.com/tw93/mole/internal/output"
+)
+
+// baselineMinGrowth is how much a folder must have grown past its baseline
+// before it counts as unexpectedly big, so a few megabytes doubling to a
+// few more stays quiet.
+const baselineMinGrowth = 100 << 20
+
+var (
+	baselineFlags  = flag.NewFlagSet("analyze baseline", flag.ExitOnError)
+	baselineCheck  = baselineFlags.Bool("check", false, "compare a fresh scan with the baseline instead of recording one")
+	baselineRemove = baselineFlags.Bool("remove", false, "forget the baseline")
+	baselineJSON   = baselineFlags.Bool("json", false, "with --check, print the comparison as JSON")
+)
+
+func init() { baselineFlags.Usage = baselineUsage }
+
+func baselineUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole analyze baseline [flags] [path]
+
+Records the size of each folder in path (your home folder by default),
+best right after a cleanup. Later scans of path flag the folders that have
+grown to --baseline-factor times their recorded size or more, and by
+at least 100 MB, so a runaway log folder stands out from a Photos library
+that was always big. --check scans now and lists them.
+
+`)
+	baselineFlags.PrintDefaults()
+}
+
+// runBaselineMode implements `mole analyze baseline` and exits on errors.
+func runBaselineMode(args []string) {
+	baselineFlags.Parse(args)
+	home, err := os.UserHomeDir()
+	if err != nil {
+		exitcode.Exit("analyze baseline", exitcode.Failed, err)
+	}
+	target := home
+	if baselineFlags.NArg() > 0 {
+		if target, err = filepath.Abs(baselineFlags.Arg(0)); err != nil {
+			exitcode.Exit("analyze baseline", exitcode.Usage, err)
+		}
+	}
+	file := snapshotFile(baselineDir(home), target)
+
+	switch {
+	case *baselineRemove:
+		if err := os.Remove(file); errors.Is(err, os.ErrNotExist) {
+			exitcode.Exit("analyze baseline", exitcode.Usage, fmt.Errorf("no baseline for %s", displayPath(target)))
+		} else if err != nil {
+			exitcode.Exit("analyze baseline", exitcode.Failed, err)
+		}
+		fmt.Printf("Baseline for %s removed.\n", displayPath(target))
+	case *baselineCheck:
+		base := readSnapshot(file, target)
+		if base == nil {
+			exitcode.Exit("analyze baseline", exitcode.Usage, fmt.Errorf(
+				"no baseline for %s; record one with mole analyze baseline %s", displayPath(target), displayPath(target)))
+		}
+		cur, err := takeSnapshot(target)
+		if err != nil {
+			exitcode.Exit("analyze baseline", exitcode.Failed, err)
+		}
+		report := newBaselineReport(*base, cur, *baselineFactor)
+
+		out, err := output.Create(output.Path(*outputTo))
+		if err != nil {
+			exitcode.Exit("analyze baseline", exitcode.Failed, err)
+		}
+		if *baselineJSON {
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(report)
+		} else {
+			report.write(out)
+		}
+		if err != nil {
+			out.Abort()
+			exitcode.Exit("analyze baseline", exitcode.Failed, err)
+		}
+		if err := out.Close(); err != nil {
+			exitcode.Exit("analyze baseline", exitcode.Failed, err)
+		}
+	default:
+		cur, err := takeSnapshot(target)
+		if err != nil {
+			exitcode.Exit("analyze baseline", exitcode.Failed, err)
+		}
+		if err := writeSnapshot(file, cur); err != nil {
+			exitcode.Exit("analyze baseline", exitcode.Failed, err)
+		}
+		fmt.Printf("Recorded the baseline of %s: %s in %d items. Scans now flag folders that grow to %g× their size.\n",
+			displayPath(target), humanizeBytes(cur.Total), len(cur.Entries), *baselineFactor)
+	}
+}
+
+// baselineDir keeps one snapshot per baselined folder. It is under
+// ~/.config rather than the cache, which mole clean may empty.
+func baselineDir(home string) string {
+	return filepath.Join(home, ".config", "mole", "baselines")
+}
+
+// unexpectedSize reports whether a folder that took baseline bytes when the
+// baseline was recorded is unexpectedly big at size.
+func unexpectedSize(baseline, size int64, factor float64) bool {
+	return size-baseline >= baselineMinGrowth && float64(size) >= factor*float64(baseline)
+}
+
+// baselineRatio is how many times its baseline size a folder is now, or
+// "new" for one that was not there.
+func baselineRatio(baseline, size int64) string {
+	if baseline <= 0 {
+		return "new"
+	}
+	ratio := float64(size) / float64(baseline)
+	if ratio >= 10 {
+		return strconv.FormatFloat(ratio, 'f', 0, 64) + "×"
+	}
+	return strconv.FormatFloat(ratio, 'f', 1, 64) + "×"
+}
+
+var (
+	baselineMu    sync.Mutex
+	baselineCache = map[string]*scanSnapshot{}
+)
+
+// baselineOf returns the baseline recorded for dir, or nil. Each folder's
+// file is read once.
+func baselineOf(dir string) *scanSnapshot {
+	baselineMu.Lock()
+	defer baselineMu.Unlock()
+	if base, ok := baselineCache[dir]; ok {
+		return base
+	}
+	var base *scanSnapshot
+	if home, err := os.UserHomeDir(); err == nil {
+		base = readSnapshot(snapshotFile(baselineDir(home), dir), dir)
+	}
+	baselineCache[dir] = base
+	return base
+}
+
+// unexpectedEntry reports whether entry is unexpectedly big against the
+// baseline of the folder it is in, and what it took then.
+func unexpectedEntry(entry dirEntry) (int64, bool) {
+	if entry.Size <= 0 {
+		return 0, false
+	}
+	base := baselineOf(filepath.Dir(entry.Path))
+	if base == nil {
+		return 0, false
+	}
+	baseline := base.Entries[entry.Name]
+	return baseline, unexpectedSize(baseline, entry.Size, *baselineFactor)
+}
+
+// baselineHint is the label next to an entry unexpectedly big against its
+// baseline in the TUI.
+func baselineHint(baseline, size int64) string {
+	if baseline <= 0 {
+		return i18n.T("new since baseline")
+	}
+	return i18n.Tf("%s its baseline", baselineRatio(baseline, size))
+}
+
+// baselineChange is a folder unexpectedly big against its baseline.
+type baselineChange struct {
+	Name     string `json:"name"`
+	Baseline int64  `json:"baseline"`
+	Size     int64  `json:"size"`
+}
+
+// baselineReport is the result of `mole analyze baseline --check`.
+type baselineReport struct {
+	Path       string           `json:"path"`
+	Since      time.Time        `json:"since"`
+	Baseline   int64            `json:"baseline"`
+	Total      int64            `json:"total"`
+	Factor     float64          `json:"factor"`
+	Unexpected []baselineChange `json:"unexpected"`
+}
+
+// newBaselineReport lists the entries of cur unexpectedly big against base,
+// the most grown first.
+func newBaselineReport(base, cur scanSnapshot, factor float64) baselineReport {
+	report := baselineReport{Path: cur.Path, Since: base.Time, Baseline: base.Total, Total: cur.Total, Factor: factor, Unexpected: []baselineChange{}}
+	for name, size := range cur.Entries {
+		if baseline := base.Entries[name]; unexpectedSize(baseline, size, factor) {
+			report.Unexpected = append(report.Unexpected, baselineChange{Name: name, Baseline: baseline, Size: size})
+		}
+	}
+	slices.SortFunc(report.Unexpected, func(a, b baselineChange) int {
+		return cmp.Or(cmp.Compare(b.Size-b.Baseline, a.Size-a.Baseline), strings.Compare(a.Name, b.Name))
+	})
+	return report
+}
+
+func (r baselineReport) write(w io.Writer) {
+	fmt.Fprintf(w, "Baseline of %s from %s: %s, now %s (%s)\n", displayPath(r.Path),
+		r.Since.Local().Format("2006-01-02 15:04"), humanizeBytes(r.Baseline), humanizeBytes(r.Total), signedBytes(r.Total-r.Baseline))
+	if len(r.Unexpected) == 0 {
+		fmt.Fprintf(w, "\nNothing has grown to %g× its baseline; the big folders are the usual ones.\n", r.Factor)
+		return
+	}
+	fmt.Fprintf(w, "\n%sUnexpectedly big%s, %g× their baseline or more:\n", colorBold, colorReset, r.Factor)
+	fmt.Fprintf(w, "\n%10s  %10s  %6s  %s\n", "BASELINE", "NOW", "GROWTH", "NAME")
+	for _, c := range r.Unexpected {
+		fmt.Fprintf(w, "%10s  %10s  %s%6s%s  %s\n", humanizeBytes(c.Baseline), humanizeBytes(c.Size),
+			colorRed, baselineRatio(c.Baseline, c.Size), colorReset, c.Name)
+	}
+}
@@ -0,0 +1,101 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnexpectedSize(t *testing.T) {
+	for _, tc := range []struct {
+		baseline, size int64
+		want           bool
+	}{
+		{200 << 30, 230 << 30, false}, // Photos: big, but always was
+		{50 << 20, 2 << 30, true},     // a runaway log folder
+		{10 << 20, 60 << 20, false},   // six times, but only 50 MB more
+		{0, 300 << 20, true},          // new since the baseline
+		{0, 20 << 20, false},
+		{300 << 20, 500 << 20, false},
+	} {
+		if got := unexpectedSize(tc.baseline, tc.size, 2); got != tc.want {
+			t.Errorf("unexpectedSize(%d, %d) = %v, want %v", tc.baseline, tc.size, got, tc.want)
+		}
+	}
+}
+
+func TestBaselineRatio(t *testing.T) {
+	for _, tc := range []struct {
+		baseline, size int64
+		want           string
+	}{
+		{100, 250, "2.5×"},
+		{100, 4200, "42×"},
+		{0, 100, "new"},
+	} {
+		if got := baselineRatio(tc.baseline, tc.size); got != tc.want {
+			t.Errorf("baselineRatio(%d, %d) = %q, want %q", tc.baseline, tc.size, got, tc.want)
+		}
+	}
+}
+
+func TestBaselineReport(t *testing.T) {
+	then := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	base := scanSnapshot{Path: "/Users/me", Time: then, Total: 210 << 30, Entries: map[string]int64{
+		"Pictures": 200 << 30, "Library": 10 << 30, "logs": 40 << 20,
+	}}
+	cur := scanSnapshot{Path: "/Users/me", Time: then.AddDate(0, 0, 14), Total: 245 << 30, Entries: map[string]int64{
+		"Pictures": 220 << 30, "Library": 21 << 30, "logs": 3 << 30, "dumps": 1 << 30,
+	}}
+	report := newBaselineReport(base, cur, 2)
+	var names []string
+	for _, c := range report.Unexpected {
+		names = append(names, c.Name)
+	}
+	if strings.Join(names, ",") != "Library,logs,dumps" {
+		t.Errorf("unexpected = %v", names)
+	}
+
+	var buf bytes.Buffer
+	report.write(&buf)
+	for _, want := range []string{"Unexpectedly big", "BASELINE", "2.1×", "77×", "new", "dumps"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report lacks %q:\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "Pictures") {
+		t.Errorf("report flags Pictures:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	newBaselineReport(base, base, 2).write(&buf)
+	if !strings.Contains(buf.String(), "Nothing has grown") {
+		t.Errorf("unchanged report:\n%s", buf.String())
+	}
+}
+
+func TestUnexpectedEntry(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, "Projects")
+	base := scanSnapshot{Path: dir, Time: time.Now(), Entries: map[string]int64{"app": 1 << 30, "logs": 10 << 20}}
+	if err := writeSnapshot(snapshotFile(baselineDir(home), dir), base); err != nil {
+		t.Fatal(err)
+	}
+	clear(baselineCache)
+	t.Cleanup(func() { clear(baselineCache) })
+
+	if baseline, ok := unexpectedEntry(dirEntry{Name: "logs", Path: filepath.Join(dir, "logs"), Size: 900 << 20}); !ok || baseline != 10<<20 {
+		t.Errorf("logs: %d, %v", baseline, ok)
+	}
+	if _, ok := unexpectedEntry(dirEntry{Name: "app", Path: filepath.Join(dir, "app"), Size: 1200 << 20}); ok {
+		t.Error("app is flagged")
+	}
+	if _, ok := unexpectedEntry(dirEntry{Name: "logs", Path: filepath.Join(home, "Other", "logs"), Size: 900 << 20}); ok {
+		t.Error("a folder without a baseline is flagged")
+	}
+}
@@ -0,0 +1,101 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/tw93/mole/pkg/diskscan"
+)
+
+func snapshotFromModel(m model) historyEntry {
+	return historyEntry{
+		Path:          m.path,
+		Entries:       slices.Clone(m.entries),
+		LargeFiles:    slices.Clone(m.largeFiles),
+		SparseFiles:   slices.Clone(m.sparseFiles),
+		Ages:          m.ages,
+		TotalSize:     m.totalSize,
+		TotalFiles:    m.totalFiles,
+		Selected:      m.selected,
+		EntryOffset:   m.offset,
+		LargeSelected: m.largeSelected,
+		LargeOffset:   m.largeOffset,
+		NeedsRefresh:  m.viewNeedsRefresh || m.scanning,
+		IsOverview:    m.isOverview,
+	}
+}
+
+func filterNonEmptyEntries(entries []dirEntry) []dirEntry {
+	filtered := make([]dirEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Size > 0 {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+func historyEntryFromScanResult(path string, result scanResult, previous historyEntry, needsRefresh bool) historyEntry {
+	entry := historyEntry{
+		Path:          path,
+		Entries:       slices.Clone(result.Entries),
+		LargeFiles:    slices.Clone(result.LargeFiles),
+		SparseFiles:   slices.Clone(result.SparseFiles),
+		Ages:          result.Ages,
+		TotalSize:     result.TotalSize,
+		TotalFiles:    result.TotalFiles,
+		Selected:      previous.Selected,
+		EntryOffset:   previous.EntryOffset,
+		LargeSelected: previous.LargeSelected,
+		LargeOffset:   previous.LargeOffset,
+		NeedsRefresh:  needsRefresh,
+		IsOverview:    previous.IsOverview,
+	}
+	return entry
+}
+
+// prefetchOverviewCache warms overview cache in background.
+func prefetchOverviewCache(ctx context.Context) {
+	entries := createOverviewEntries()
+
+	var needScan []string
+	for _, entry := range entries {
+		if size, err := diskscan.StoredSize(entry.Path); err == nil && size > 0 {
+			continue
+		}
+		needScan = append(needScan, entry.Path)
+	}
+
+	if len(needScan) == 0 {
+		return
+	}
+
+	sem := make(chan struct{}, maxConcurrentOverview)
+	var wg sync.WaitGroup
+	for _, path := range needScan {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		default:
+		}
+
+		wg.Go(func() {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			size, err := diskscan.Measure(path)
+			if err == nil && size > 0 {
+				_ = diskscan.StoreSize(path, size)
+			}
+		})
+	}
+	wg.Wait()
+}
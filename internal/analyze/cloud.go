@@ -0,0 +1,256 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/output"
+)
+
+// evictTimeout bounds `fileproviderctl evict`, which waits for the sync
+// app to upload pending changes before it drops the local copies.
+const evictTimeout = 5 * time.Minute
+
+var (
+	cloudFlags = flag.NewFlagSet("analyze cloud", flag.ExitOnError)
+	cloudFree  = cloudFlags.String("free", "", "free up space on `folder`, keeping its files online only")
+	cloudJSON  = cloudFlags.Bool("json", false, "print the breakdown as JSON")
+)
+
+func init() { cloudFlags.Usage = cloudUsage }
+
+func cloudUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole analyze cloud [flags]
+
+Finds the Dropbox, Google Drive, and OneDrive folders and, for each
+folder in them, reports how much is stored on this Mac and how much is
+online only. --free then frees up space on a folder the way Finder's
+Free Up Space does: its files stay in the cloud and download again when
+opened.
+
+`)
+	cloudFlags.PrintDefaults()
+}
+
+// cloudServices maps the folder names sync apps create, in
+// ~/Library/CloudStorage or the home folder, to the service.
+var cloudServices = []struct{ prefix, service string }{
+	{"Dropbox", "Dropbox"},
+	{"GoogleDrive-", "Google Drive"},
+	{"Google Drive", "Google Drive"},
+	{"OneDrive", "OneDrive"},
+}
+
+// cloudBytes is how much of a folder is stored on this Mac and how much is
+// online only.
+type cloudBytes struct {
+	Size       int64 `json:"size"`
+	Local      int64 `json:"local"`
+	OnlineOnly int64 `json:"online_only"`
+}
+
+func (b *cloudBytes) add(o cloudBytes) {
+	b.Size += o.Size
+	b.Local += o.Local
+	b.OnlineOnly += o.OnlineOnly
+}
+
+type cloudFolder struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	cloudBytes
+}
+
+// cloudRoot is a sync folder. FileProvider is set for the ones in
+// ~/Library/CloudStorage, whose files macOS can make online only.
+type cloudRoot struct {
+	Service      string        `json:"service"`
+	Path         string        `json:"path"`
+	FileProvider bool          `json:"file_provider"`
+	Folders      []cloudFolder `json:"folders"`
+	cloudBytes
+}
+
+// runCloudMode implements `mole analyze cloud` and exits on errors.
+func runCloudMode(args []string) {
+	cloudFlags.Parse(args)
+	home, _ := os.UserHomeDir()
+	roots := findCloudRoots(home)
+
+	if *cloudFree != "" {
+		if err := freeCloudFolder(roots, *cloudFree); err != nil {
+			exitcode.Exit("analyze cloud", exitcode.Failed, err)
+		}
+		fmt.Fprintf(os.Stderr, "Freed up space on %s; its files are online only now.\n", displayPath(*cloudFree))
+		return
+	}
+
+	for i := range roots {
+		measureCloudRoot(&roots[i])
+	}
+	out, err := output.Create(output.Path(*outputTo))
+	if err != nil {
+		exitcode.Exit("analyze cloud", exitcode.Failed, err)
+	}
+	if *cloudJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(roots)
+	} else {
+		writeCloudRoots(out, roots)
+	}
+	if err != nil {
+		out.Abort()
+		exitcode.Exit("analyze cloud", exitcode.Failed, err)
+	}
+	if err := out.Close(); err != nil {
+		exitcode.Exit("analyze cloud", exitcode.Failed, err)
+	}
+}
+
+// findCloudRoots lists the sync folders in ~/Library/CloudStorage, then
+// the ones older sync apps kept in the home folder. Those are often
+// links into CloudStorage now, and links are skipped.
+func findCloudRoots(home string) []cloudRoot {
+	var roots []cloudRoot
+	for _, dir := range []string{filepath.Join(home, "Library", "CloudStorage"), home} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				continue
+			}
+			for _, s := range cloudServices {
+				if strings.HasPrefix(e.Name(), s.prefix) {
+					roots = append(roots, cloudRoot{Service: s.service, Path: filepath.Join(dir, e.Name()), FileProvider: dir != home})
+					break
+				}
+			}
+		}
+	}
+	return roots
+}
+
+// measureCloudRoot totals each folder at the top of root, and the files
+// there as one more entry, largest on this Mac first. A file is online
+// only when it is dataless; otherwise its allocated blocks are local.
+func measureCloudRoot(root *cloudRoot) {
+	entries, err := os.ReadDir(root.Path)
+	if err != nil {
+		return
+	}
+	loose := cloudFolder{Name: "Files in " + filepath.Base(root.Path), Path: root.Path}
+	for _, e := range entries {
+		path := filepath.Join(root.Path, e.Name())
+		if !e.IsDir() {
+			if info, err := e.Info(); err == nil {
+				loose.add(cloudFileBytes(info))
+			}
+			continue
+		}
+		folder := cloudFolder{Name: e.Name(), Path: path}
+		filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				folder.add(cloudFileBytes(info))
+			}
+			return nil
+		})
+		root.Folders = append(root.Folders, folder)
+	}
+	if loose.Size > 0 {
+		root.Folders = append(root.Folders, loose)
+	}
+	for _, folder := range root.Folders {
+		root.add(folder.cloudBytes)
+	}
+	slices.SortFunc(root.Folders, func(a, b cloudFolder) int {
+		return cmp.Or(cmp.Compare(b.Local, a.Local), cmp.Compare(b.Size, a.Size), strings.Compare(a.Name, b.Name))
+	})
+}
+
+func cloudFileBytes(info fs.FileInfo) cloudBytes {
+	if !info.Mode().IsRegular() {
+		return cloudBytes{}
+	}
+	b := cloudBytes{Size: info.Size(), Local: info.Size()}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		if stat.Flags&sfDataless != 0 {
+			b.Local, b.OnlineOnly = 0, info.Size()
+		} else {
+			b.Local = min(stat.Blocks*512, info.Size())
+		}
+	}
+	return b
+}
+
+// freeCloudFolder asks the sync app, through the File Provider framework,
+// to drop the local copies of the files in path. Only folders in a sync
+// folder under ~/Library/CloudStorage can be freed that way.
+func freeCloudFolder(roots []cloudRoot, path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	inRoot := slices.ContainsFunc(roots, func(r cloudRoot) bool {
+		return r.FileProvider && (abs == r.Path || strings.HasPrefix(abs, r.Path+string(filepath.Separator)))
+	})
+	if !inRoot {
+		return fmt.Errorf("%s is not in a Dropbox, Google Drive, or OneDrive folder in ~/Library/CloudStorage", displayPath(abs))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), evictTimeout)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, "fileproviderctl", "evict", abs).CombinedOutput(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timed out freeing up space on %s", displayPath(abs))
+		}
+		return fmt.Errorf("fileproviderctl evict: %s", cmp.Or(strings.TrimSpace(string(out)), err.Error()))
+	}
+	return nil
+}
+
+func writeCloudRoots(w io.Writer, roots []cloudRoot) {
+	if len(roots) == 0 {
+		fmt.Fprintln(w, "No Dropbox, Google Drive, or OneDrive folders found.")
+		return
+	}
+	canFree := false
+	for i, root := range roots {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s%s%s %s: %s, %s on this Mac\n", colorBold, root.Service, colorReset,
+			displayPath(root.Path), humanizeBytes(root.Size), humanizeBytes(root.Local))
+		if len(root.Folders) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%10s  %10s  %11s  %s\n", "SIZE", "ON MAC", "ONLINE ONLY", "FOLDER")
+		for _, folder := range root.Folders {
+			fmt.Fprintf(w, "%10s  %10s  %11s  %s\n", humanizeBytes(folder.Size), humanizeBytes(folder.Local), humanizeBytes(folder.OnlineOnly), folder.Name)
+		}
+		canFree = canFree || root.FileProvider && root.Local > 0
+	}
+	if canFree {
+		fmt.Fprintln(w, "\nRun mole analyze cloud --free <folder> to keep a folder online only and free its space on this Mac.")
+	}
+}
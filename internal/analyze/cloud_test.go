@@ -0,0 +1,80 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindCloudRoots(t *testing.T) {
+	home := t.TempDir()
+	storage := filepath.Join(home, "Library", "CloudStorage")
+	for _, dir := range []string{"Dropbox", "GoogleDrive-me@example.com", "OneDrive-Personal", "Box-Box"} {
+		os.MkdirAll(filepath.Join(storage, dir), 0o755)
+	}
+	os.Symlink(filepath.Join(storage, "Dropbox"), filepath.Join(home, "Dropbox"))
+	os.MkdirAll(filepath.Join(home, "Google Drive"), 0o755)
+
+	var got []string
+	for _, root := range findCloudRoots(home) {
+		got = append(got, root.Service+"="+strings.TrimPrefix(root.Path, home))
+		if root.FileProvider != strings.HasPrefix(root.Path, storage) {
+			t.Errorf("%s: file provider %v", root.Path, root.FileProvider)
+		}
+	}
+	want := "Dropbox=/Library/CloudStorage/Dropbox,Google Drive=/Library/CloudStorage/GoogleDrive-me@example.com,OneDrive=/Library/CloudStorage/OneDrive-Personal,Google Drive=/Google Drive"
+	if strings.Join(got, ",") != want {
+		t.Errorf("roots = %v", got)
+	}
+}
+
+func TestMeasureCloudRoot(t *testing.T) {
+	dir := t.TempDir()
+	for path, size := range map[string]int{"Photos/2024/a.jpg": 64 << 10, "Photos/b.jpg": 64 << 10, "Work/report.pdf": 8 << 10, "notes.txt": 4 << 10} {
+		full := filepath.Join(dir, path)
+		os.MkdirAll(filepath.Dir(full), 0o755)
+		if err := os.WriteFile(full, bytes.Repeat([]byte("x"), size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root := cloudRoot{Service: "Dropbox", Path: dir, FileProvider: true}
+	measureCloudRoot(&root)
+
+	var names []string
+	for _, folder := range root.Folders {
+		names = append(names, folder.Name)
+		if folder.Local != folder.Size || folder.OnlineOnly != 0 {
+			t.Errorf("%s: %+v", folder.Name, folder.cloudBytes)
+		}
+	}
+	if strings.Join(names, ",") != "Photos,Work,Files in "+filepath.Base(dir) {
+		t.Errorf("folders = %v", names)
+	}
+	if root.Size != 140<<10 || root.Local != root.Size {
+		t.Errorf("root = %+v", root.cloudBytes)
+	}
+
+	var buf bytes.Buffer
+	writeCloudRoots(&buf, []cloudRoot{root})
+	for _, want := range []string{"Dropbox", "ONLINE ONLY", "Photos", "--free <folder>"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report lacks %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestFreeCloudFolderStaysInSyncFolders(t *testing.T) {
+	roots := []cloudRoot{
+		{Service: "Dropbox", Path: "/Users/me/Library/CloudStorage/Dropbox", FileProvider: true},
+		{Service: "Google Drive", Path: "/Users/me/Google Drive"},
+	}
+	for _, path := range []string{"/Users/me/Documents", "/Users/me/Library/CloudStorage/Dropbox-Old", "/Users/me/Google Drive/Photos"} {
+		if err := freeCloudFolder(roots, path); err == nil || !strings.Contains(err.Error(), "is not in a Dropbox") {
+			t.Errorf("freeCloudFolder(%q) = %v", path, err)
+		}
+	}
+}
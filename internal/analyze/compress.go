@@ -0,0 +1,312 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"cmp"
+	"compress/flate"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/output"
+)
+
+const (
+	// compressSamples blocks of compressSampleSize bytes are read from each
+	// file, spread evenly from its start to its end. APFS compresses in
+	// blocks of the same size, so a sample compresses about as well as the
+	// file's blocks do.
+	compressSamples    = 16
+	compressSampleSize = 64 << 10
+
+	// Samples above compressMaxEntropy bits per byte hold compressed or
+	// encrypted data, and the file is not compressed again to check.
+	compressMaxEntropy = 7.5
+
+	// A file is a candidate when compressing it saves at least
+	// compressMinSavings and compressMinShare of its size.
+	compressMinSavings = 50 << 20
+	compressMinShare   = 0.25
+
+	compressWorkers = 4
+
+	// Flags from sys/stat.h: APFS already compressed the file, or its data
+	// is in the cloud and reading it would download it.
+	ufCompressed = 0x00000020
+	sfDataless   = 0x40000000
+)
+
+var (
+	compressFlags = flag.NewFlagSet("analyze compress", flag.ExitOnError)
+	compressMinMB = compressFlags.Int64("min-mb", 100, "sample files of at least this many MB")
+	compressTop   = compressFlags.Int("top", 20, "list at most this many candidates")
+	compressJSON  = compressFlags.Bool("json", false, "print the candidates as JSON")
+)
+
+func init() { compressFlags.Usage = compressUsage }
+
+func compressUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole analyze compress [flags] [path]
+
+Samples the large files under path (your home folder by default) and
+lists those whose data would compress well, such as logs, JSON exports,
+and VM disk images, with how much compressing them, or storing them
+with APFS compression, would save. Nothing is changed.
+
+`)
+	compressFlags.PrintDefaults()
+}
+
+// compressedExtensions are file types whose data is already compressed;
+// they are not sampled.
+var compressedExtensions = map[string]bool{
+	".zip": true, ".gz": true, ".tgz": true, ".bz2": true, ".xz": true, ".zst": true,
+	".7z": true, ".rar": true, ".dmg": true, ".pkg": true, ".xip": true, ".ipa": true,
+	".jpg": true, ".jpeg": true, ".png": true, ".heic": true, ".webp": true,
+	".mp3": true, ".m4a": true, ".aac": true, ".flac": true,
+}
+
+// runCompressMode implements `mole analyze compress` and exits on errors.
+func runCompressMode(args []string) {
+	compressFlags.Parse(args)
+	if *compressMinMB < 1 || *compressTop < 1 {
+		exitcode.Exit("analyze compress", exitcode.Usage, errors.New("--min-mb and --top must be at least 1"))
+	}
+	target, err := os.UserHomeDir()
+	if compressFlags.NArg() > 0 {
+		target, err = filepath.Abs(compressFlags.Arg(0))
+	}
+	if err != nil {
+		exitcode.Exit("analyze compress", exitcode.Usage, err)
+	}
+
+	files, err := findLargeFiles(target, *compressMinMB<<20, func(name string) bool {
+		ext := strings.ToLower(filepath.Ext(name))
+		return !compressedExtensions[ext] && !videoExtensions[ext]
+	})
+	if err != nil {
+		exitcode.Exit("analyze compress", exitcode.Failed, err)
+	}
+	candidates := sampleFiles(files)
+	report := compressReport{Path: target, Sampled: len(files), Candidates: candidates}
+	for _, c := range candidates {
+		report.TotalSavings += c.Savings
+	}
+	report.Candidates = candidates[:min(len(candidates), *compressTop)]
+
+	out, err := output.Create(output.Path(*outputTo))
+	if err != nil {
+		exitcode.Exit("analyze compress", exitcode.Failed, err)
+	}
+	if *compressJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		report.write(out, len(candidates))
+	}
+	if err != nil {
+		out.Abort()
+		exitcode.Exit("analyze compress", exitcode.Failed, err)
+	}
+	if err := out.Close(); err != nil {
+		exitcode.Exit("analyze compress", exitcode.Failed, err)
+	}
+}
+
+// compressCandidate is a file and what compressing it would save.
+type compressCandidate struct {
+	Path      string  `json:"path"`
+	Size      int64   `json:"size"` // on disk
+	Kind      string  `json:"kind"`
+	Entropy   float64 `json:"entropy"` // bits per byte, averaged over the samples
+	Estimated int64   `json:"estimated_size"`
+	Savings   int64   `json:"savings"`
+}
+
+// sampleFiles samples files a few at a time and returns the compressible
+// ones, the largest savings first.
+func sampleFiles(files []largeFile) []compressCandidate {
+	var (
+		mu         sync.Mutex
+		wg         sync.WaitGroup
+		sem        = make(chan struct{}, compressWorkers)
+		candidates []compressCandidate
+	)
+	for _, file := range files {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			if c, ok := sampleFile(file.path); ok {
+				mu.Lock()
+				candidates = append(candidates, c)
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	slices.SortFunc(candidates, func(a, b compressCandidate) int {
+		return cmp.Or(cmp.Compare(b.Savings, a.Savings), strings.Compare(a.Path, b.Path))
+	})
+	return candidates
+}
+
+// sampleFile reads samples of the file at path and returns it as a
+// candidate when they compress well enough. Files APFS already compressed
+// and files left in the cloud are skipped.
+func sampleFile(path string) (compressCandidate, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return compressCandidate{}, false
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return compressCandidate{}, false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || stat.Flags&(ufCompressed|sfDataless) != 0 {
+		return compressCandidate{}, false
+	}
+	entropy, ratio, err := sampleCompression(f, info.Size())
+	if err != nil || entropy > compressMaxEntropy {
+		return compressCandidate{}, false
+	}
+
+	// Sparse files are measured by what they take up on disk, since their
+	// holes take up nothing already.
+	onDisk := min(stat.Blocks*512, info.Size())
+	estimated := int64(float64(info.Size()) * ratio)
+	savings := onDisk - estimated
+	if savings < compressMinSavings || float64(savings) < compressMinShare*float64(onDisk) {
+		return compressCandidate{}, false
+	}
+	return compressCandidate{
+		Path: path, Size: onDisk, Kind: compressKind(path),
+		Entropy: math.Round(entropy*100) / 100, Estimated: estimated, Savings: savings,
+	}, true
+}
+
+// sampleCompression reads compressSamples blocks of r, which is size bytes
+// long, and returns their mean entropy in bits per byte and the ratio
+// they deflate to. The ratio is 1 when the entropy alone shows the data
+// is already dense.
+func sampleCompression(r io.ReaderAt, size int64) (entropy, ratio float64, err error) {
+	sampleSize := int64(compressSampleSize)
+	count := int64(compressSamples)
+	if size <= sampleSize*count {
+		sampleSize, count = max(size/count, 1), min(count, size)
+	}
+	if count == 0 {
+		return 0, 0, errors.New("empty file")
+	}
+
+	samples := make([][]byte, 0, count)
+	for i := range count {
+		var offset int64
+		if count > 1 {
+			offset = i * (size - sampleSize) / (count - 1)
+		}
+		buf := make([]byte, sampleSize)
+		n, err := r.ReadAt(buf, offset)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, 0, err
+		}
+		samples = append(samples, buf[:n])
+		entropy += byteEntropy(buf[:n])
+	}
+	entropy /= float64(count)
+	if entropy > compressMaxEntropy {
+		return entropy, 1, nil
+	}
+
+	var raw int64
+	var packed countingWriter
+	for _, sample := range samples {
+		// Each sample is compressed on its own, as APFS does each block.
+		w, _ := flate.NewWriter(&packed, flate.BestSpeed)
+		w.Write(sample)
+		w.Close()
+		raw += int64(len(sample))
+	}
+	if raw == 0 {
+		return entropy, 1, nil
+	}
+	return entropy, min(float64(packed)/float64(raw), 1), nil
+}
+
+// byteEntropy is the Shannon entropy of data in bits per byte, from 0 for
+// a single repeated byte to 8 for random data.
+func byteEntropy(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	var counts [256]int
+	for _, b := range data {
+		counts[b]++
+	}
+	var entropy float64
+	n := float64(len(data))
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / n
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+type countingWriter int64
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
+
+// compressKind names what kind of data a file holds, by its extension.
+func compressKind(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".log", ".txt", ".out":
+		return "log"
+	case ".json", ".ndjson", ".jsonl", ".csv", ".tsv", ".xml", ".sql":
+		return "export"
+	case ".vmdk", ".vdi", ".qcow2", ".img", ".raw", ".vhd", ".vhdx", ".hdd", ".sparseimage", ".sparsebundle", ".iso":
+		return "disk image"
+	}
+	return "data"
+}
+
+// compressReport is the result of `mole analyze compress`.
+type compressReport struct {
+	Path         string              `json:"path"`
+	Sampled      int                 `json:"sampled"`
+	Candidates   []compressCandidate `json:"candidates"`
+	TotalSavings int64               `json:"total_savings"`
+}
+
+// write prints the report as a table; found is how many candidates there
+// were before the list was cut to --top.
+func (r compressReport) write(w io.Writer, found int) {
+	fmt.Fprintf(w, "Compressible files under %s: %d of %d large files sampled\n", displayPath(r.Path), found, r.Sampled)
+	if found == 0 {
+		fmt.Fprintln(w, "Nothing would shrink much by compressing it.")
+		return
+	}
+	fmt.Fprintf(w, "\n%10s  %10s  %-10s  %7s  %s\n", "SAVES", "SIZE", "KIND", "ENTROPY", "FILE")
+	for _, c := range r.Candidates {
+		fmt.Fprintf(w, "%10s  %10s  %-10s  %7.2f  %s\n",
+			humanizeBytes(c.Savings), humanizeBytes(c.Size), c.Kind, c.Entropy, displayPath(c.Path))
+	}
+	fmt.Fprintf(w, "\nCompressing all %d would save about %s. Files still in use can be stored with APFS\ncompression, which apps read as usual: ditto --hfsCompression FILE COPY, then replace FILE\nwith COPY. Old logs and exports can be gzipped instead.\n", found, humanizeBytes(r.TotalSavings))
+}
@@ -0,0 +1,69 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func TestByteEntropy(t *testing.T) {
+	if got := byteEntropy(make([]byte, 1024)); got != 0 {
+		t.Errorf("zeros: %v bits", got)
+	}
+	all := make([]byte, 256*4)
+	for i := range all {
+		all[i] = byte(i)
+	}
+	if got := byteEntropy(all); got != 8 {
+		t.Errorf("every byte alike: %v bits", got)
+	}
+}
+
+func TestSampleCompression(t *testing.T) {
+	var log bytes.Buffer
+	for i := 0; log.Len() < 4<<20; i++ {
+		fmt.Fprintf(&log, "2026-10-16T09:%02d:%02d INFO request %d served in %dms\n", i/60%60, i%60, i, i%250)
+	}
+	entropy, ratio, err := sampleCompression(bytes.NewReader(log.Bytes()), int64(log.Len()))
+	if err != nil || entropy > 5 || ratio > 0.3 {
+		t.Errorf("log: entropy %.2f, ratio %.2f, %v", entropy, ratio, err)
+	}
+
+	random := make([]byte, 4<<20)
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := range random {
+		random[i] = byte(r.Uint32())
+	}
+	entropy, ratio, err = sampleCompression(bytes.NewReader(random), int64(len(random)))
+	if err != nil || entropy < compressMaxEntropy || ratio != 1 {
+		t.Errorf("random: entropy %.2f, ratio %.2f, %v", entropy, ratio, err)
+	}
+
+	// Files smaller than the samples are read in smaller pieces.
+	if _, ratio, err := sampleCompression(bytes.NewReader(make([]byte, 10)), 10); err != nil || ratio > 1 {
+		t.Errorf("tiny: ratio %.2f, %v", ratio, err)
+	}
+	if _, _, err := sampleCompression(bytes.NewReader(nil), 0); err == nil {
+		t.Error("an empty file was sampled")
+	}
+}
+
+func TestCompressReport(t *testing.T) {
+	if compressKind("/v/Ubuntu.VMDK") != "disk image" || compressKind("/l/app.log") != "log" || compressKind("/x/blob") != "data" {
+		t.Error("compressKind misnamed a file")
+	}
+	r := compressReport{Path: "/data", Sampled: 5, TotalSavings: 3 << 30, Candidates: []compressCandidate{
+		{Path: "/data/vm.vmdk", Size: 4 << 30, Kind: "disk image", Entropy: 1.25, Estimated: 1 << 30, Savings: 3 << 30},
+	}}
+	var b strings.Builder
+	r.write(&b, 1)
+	for _, want := range []string{"1 of 5 large files sampled", "disk image", "1.25", "ditto --hfsCompression"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("report lacks %q:\n%s", want, b.String())
+		}
+	}
+}
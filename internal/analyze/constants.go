@@ -0,0 +1,62 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"time"
+
+	"github.com/tw93/mole/internal/theme"
+)
+
+const (
+	barWidth              = 24
+	defaultViewport       = 12
+	maxSparseRows         = 5
+	maxConcurrentOverview = 8
+	openCommandTimeout    = 10 * time.Second
+	uiTickInterval        = 100 * time.Millisecond
+)
+
+var spinnerFrames = []string{"|", "/", "-", "\\", "|", "/", "-", "\\"}
+
+// Escape sequences for the active theme. main applies --theme before the
+// first frame; until then they are the dark theme at 16 colors.
+var (
+	colorPurple, colorPurpleBold, colorGray, colorRed, colorYellow string
+	colorGreen, colorBlue, colorCyan, colorReset, colorBold        string
+
+	// barGlyphs draw the size bars: full, over half, under half, and the
+	// sliver shown for entries too small to fill a cell.
+	barGlyphs [4]string
+
+	_ = applyTheme(theme.Dark, theme.ANSI)
+)
+
+// colorProfile is the color depth to draw at: the terminal's, or none when
+// --no-color is set or the output is not a terminal, so a report saved with
+// --output or piped into a file is plain text.
+func colorProfile(getenv func(string) string, noColor, terminal bool) theme.Profile {
+	if noColor || !terminal {
+		return theme.NoColor
+	}
+	return theme.Detect(getenv)
+}
+
+// applyTheme points every color at p, drawn at the terminal's color depth.
+func applyTheme(p theme.Palette, profile theme.Profile) theme.Palette {
+	colorPurple = p.Primary.Sequence(profile, false)
+	colorPurpleBold = p.Primary.Sequence(profile, true)
+	colorGray = p.Subtle.Sequence(profile, false)
+	colorRed = p.Danger.Sequence(profile, false)
+	colorYellow = p.Warn.Sequence(profile, false)
+	colorGreen = p.OK.Sequence(profile, false)
+	colorBlue = p.Info.Sequence(profile, false)
+	colorCyan = p.Accent.Sequence(profile, false)
+	colorReset, colorBold = "\033[0m", "\033[1m"
+	barGlyphs = [...]string{"█", "▓", "▒", "▏"}
+	if profile == theme.NoColor {
+		colorReset, colorBold = "", ""
+		barGlyphs = [...]string{"#", "=", "-", "|"}
+	}
+	return p
+}
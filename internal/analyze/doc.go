@@ -0,0 +1,4 @@
+// Package analyze is the disk usage explorer behind `mole analyze`: a
+// concurrent scanner, its cache, and the Bubble Tea TUI that browses and
+// deletes what it finds. It only runs on macOS.
+package analyze
@@ -0,0 +1,689 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/tw93/mole/internal/crash"
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/i18n"
+)
+
+// downloadKind is the type group a download is listed under.
+type downloadKind int
+
+const (
+	kindInstaller downloadKind = iota
+	kindArchive
+	kindUnfinished
+	kindDocument
+	kindImage
+	kindVideo
+	kindAudio
+	kindFolder
+	kindOther
+	downloadKinds
+)
+
+var downloadKindLabels = [downloadKinds]string{
+	"Installers", "Archives", "Unfinished downloads", "Documents", "Images", "Videos", "Audio", "Folders", "Other",
+}
+
+var downloadKindByExt = map[string]downloadKind{
+	".dmg": kindInstaller, ".pkg": kindInstaller, ".mpkg": kindInstaller, ".iso": kindInstaller, ".xip": kindInstaller, ".app": kindInstaller,
+	".zip": kindArchive, ".tar": kindArchive, ".gz": kindArchive, ".tgz": kindArchive, ".bz2": kindArchive, ".xz": kindArchive, ".7z": kindArchive, ".rar": kindArchive,
+	".crdownload": kindUnfinished, ".download": kindUnfinished, ".part": kindUnfinished, ".partial": kindUnfinished,
+	".pdf": kindDocument, ".doc": kindDocument, ".docx": kindDocument, ".xls": kindDocument, ".xlsx": kindDocument,
+	".ppt": kindDocument, ".pptx": kindDocument, ".pages": kindDocument, ".numbers": kindDocument, ".key": kindDocument,
+	".txt": kindDocument, ".md": kindDocument, ".rtf": kindDocument, ".csv": kindDocument, ".epub": kindDocument,
+	".jpg": kindImage, ".jpeg": kindImage, ".png": kindImage, ".gif": kindImage, ".heic": kindImage, ".webp": kindImage, ".svg": kindImage, ".tiff": kindImage,
+	".mp3": kindAudio, ".m4a": kindAudio, ".wav": kindAudio, ".aac": kindAudio, ".flac": kindAudio,
+}
+
+// downloadKindOf groups a download by its extension. Safari and Chrome
+// keep unfinished downloads in .download and .crdownload bundles, so the
+// extension decides before IsDir does.
+func downloadKindOf(name string, isDir bool) downloadKind {
+	ext := strings.ToLower(filepath.Ext(name))
+	if kind, ok := downloadKindByExt[ext]; ok {
+		return kind
+	}
+	if videoExtensions[ext] {
+		return kindVideo
+	}
+	if isDir {
+		return kindFolder
+	}
+	return kindOther
+}
+
+// downloadAgeLabels name the age groups; downloadAgeLimits are their upper
+// bounds, the last group having none.
+var (
+	downloadAgeLabels = [...]string{"Today", "This week", "This month", "Older"}
+	downloadAgeLimits = [...]time.Duration{24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour}
+)
+
+func downloadAge(added, now time.Time) int {
+	age := now.Sub(added)
+	for i, limit := range downloadAgeLimits {
+		if age < limit {
+			return i
+		}
+	}
+	return len(downloadAgeLimits)
+}
+
+// downloadAgeText is how long ago a download arrived, shortly: 5h, 3d,
+// 6w, 4mo, 2yr.
+func downloadAgeText(added, now time.Time) string {
+	hours := int(now.Sub(added).Hours())
+	switch days := hours / 24; {
+	case days < 1:
+		return fmt.Sprintf("%dh", max(hours, 0))
+	case days < 14:
+		return fmt.Sprintf("%dd", days)
+	case days < 60:
+		return fmt.Sprintf("%dw", days/7)
+	case days < 365:
+		return fmt.Sprintf("%dmo", days/30)
+	default:
+		return fmt.Sprintf("%dyr", days/365)
+	}
+}
+
+// triageMark is what the user decided for a download.
+type triageMark int
+
+const (
+	markNone triageMark = iota
+	markKeep
+	markTrash
+)
+
+// downloadItem is one entry directly in the downloads folder.
+type downloadItem struct {
+	Name  string
+	Path  string
+	Size  int64
+	IsDir bool
+	Added time.Time
+	Kind  downloadKind
+	// Done says why the download is no longer needed: "installed" for an
+	// installer or archive whose app is in /Applications, "expanded" for
+	// an archive unpacked next to it.
+	Done string
+	Mark triageMark
+}
+
+// readDownloads lists dir, sizing folders, and notes the downloads that
+// were installed or expanded already. apps are the installed app names.
+func readDownloads(dir string, apps []string) ([]downloadItem, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		names[e.Name()] = true
+	}
+	appKeys := make(map[string]bool, len(apps))
+	for _, app := range apps {
+		appKeys[appKey(app)] = true
+	}
+
+	var items []downloadItem
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		info, err := os.Lstat(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		item := downloadItem{
+			Name:  name,
+			Path:  filepath.Join(dir, name),
+			Size:  info.Size(),
+			IsDir: info.IsDir(),
+			Added: info.ModTime(),
+			Kind:  downloadKindOf(name, info.IsDir()),
+		}
+		// A file's birth time is when it landed here; the modification time
+		// may be the server's.
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			item.Added = time.Unix(stat.Birthtimespec.Unix())
+		}
+		if item.IsDir {
+			item.Size = folderSize(item.Path)
+		}
+		switch item.Kind {
+		case kindArchive:
+			if expandedNextTo(name, names) {
+				item.Done = "expanded"
+			} else if installedApp(name, appKeys) {
+				item.Done = "installed"
+			}
+		case kindInstaller:
+			if installedApp(name, appKeys) {
+				item.Done = "installed"
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func folderSize(root string) int64 {
+	var size int64
+	_ = filepath.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// archiveStem is an archive's name without its extensions, as Archive
+// Utility names the folder it expands the archive into.
+func archiveStem(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range []string{".tar.gz", ".tar.bz2", ".tar.xz"} {
+		if strings.HasSuffix(lower, ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// expandedNextTo reports whether names, the folder's entries, holds what
+// the archive expanded to: a folder or app named after it, possibly with
+// the " 2" Archive Utility adds when the name is taken.
+func expandedNextTo(archive string, names map[string]bool) bool {
+	stem := archiveStem(archive)
+	for _, candidate := range []string{stem, stem + " 2", stem + ".app"} {
+		if candidate != archive && names[candidate] {
+			return true
+		}
+	}
+	return false
+}
+
+// installerWords end the app name part of an installer's file name.
+var installerWords = map[string]bool{
+	"mac": true, "macos": true, "osx": true, "darwin": true, "universal": true, "arm64": true,
+	"x64": true, "x86": true, "intel": true, "installer": true, "setup": true, "latest": true,
+}
+
+// installerStem is the app name an installer's file name starts with,
+// lowercased with separators dropped: "Google Chrome-131.0.dmg" gives
+// "googlechrome". The name ends at a version number or a platform word.
+func installerStem(name string) string {
+	base := archiveStem(name)
+	var stem strings.Builder
+	for i, token := range strings.FieldsFunc(base, func(r rune) bool {
+		return strings.ContainsRune(" _-.+()[]", r)
+	}) {
+		lower := strings.ToLower(token)
+		isVersion := lower[0] >= '0' && lower[0] <= '9' || len(lower) > 1 && lower[0] == 'v' && lower[1] >= '0' && lower[1] <= '9'
+		if i > 0 && (isVersion || installerWords[lower]) {
+			break
+		}
+		stem.WriteString(lower)
+	}
+	return appKey(stem.String())
+}
+
+// appKey is an app's name as installerStem would spell it.
+func appKey(app string) string {
+	return strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || '0' <= r && r <= '9' {
+			return r
+		}
+		return -1
+	}, strings.ToLower(strings.TrimSuffix(app, ".app")))
+}
+
+// installedApp reports whether the installer named name is for an app
+// whose key is in appKeys. A stem of four letters or more also matches the
+// start of a key, so Zoom.pkg matches zoom.us.app.
+func installedApp(name string, appKeys map[string]bool) bool {
+	stem := installerStem(name)
+	if stem == "" {
+		return false
+	}
+	if appKeys[stem] {
+		return true
+	}
+	if len(stem) < 4 {
+		return false
+	}
+	for key := range appKeys {
+		if strings.HasPrefix(key, stem) {
+			return true
+		}
+	}
+	return false
+}
+
+// installedApps lists the apps in /Applications and ~/Applications.
+func installedApps() []string {
+	dirs := []string{"/Applications"}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, "Applications"))
+	}
+	var apps []string
+	for _, dir := range dirs {
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			if strings.HasSuffix(e.Name(), ".app") {
+				apps = append(apps, e.Name())
+			}
+		}
+	}
+	return apps
+}
+
+var downloadsFlags = flag.NewFlagSet("analyze downloads", flag.ExitOnError)
+
+func init() { downloadsFlags.Usage = downloadsUsage }
+
+func downloadsUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole analyze downloads [path]
+
+Lists what is in path (~/Downloads by default) grouped by type, or by age
+with G, and points out installers whose app is installed and archives
+already expanded. Mark each download to keep or to trash as you go down
+the list, then press Enter to move the marked ones to Trash.
+`)
+	downloadsFlags.PrintDefaults()
+}
+
+// runDownloadsMode implements `mole analyze downloads` and exits on errors.
+func runDownloadsMode(args []string) {
+	downloadsFlags.Parse(args)
+	dir := ""
+	if downloadsFlags.NArg() > 0 {
+		dir = downloadsFlags.Arg(0)
+	} else if home, err := os.UserHomeDir(); err == nil {
+		dir = filepath.Join(home, "Downloads")
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		exitcode.Exit("analyze downloads", exitcode.Usage, err)
+	}
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		exitcode.Exit("analyze downloads", exitcode.Usage, fmt.Errorf("not a folder: %s", abs))
+	}
+	p := tea.NewProgram(crash.Model(newDownloadsModel(abs)), tea.WithAltScreen(), tea.WithoutCatchPanics())
+	if _, err := p.Run(); err != nil {
+		exitcode.Exit("analyze downloads", exitcode.Failed, fmt.Errorf("analyzer error: %w", err))
+	}
+}
+
+// downloadsModel is the triage view of a downloads folder: its entries
+// grouped by type or by age, each marked to keep or to trash.
+type downloadsModel struct {
+	dir        string
+	items      []downloadItem
+	byAge      bool
+	selected   int
+	offset     int
+	width      int
+	height     int
+	now        time.Time
+	loading    bool
+	confirm    bool
+	deleting   bool
+	deleted    *int64
+	spinner    int
+	status     string
+	trashPaths []string
+}
+
+type downloadsLoadedMsg struct {
+	items []downloadItem
+	err   error
+}
+
+func newDownloadsModel(dir string) downloadsModel {
+	return downloadsModel{dir: dir, loading: true, now: time.Now(), status: i18n.T("Reading downloads...")}
+}
+
+func (m downloadsModel) Init() tea.Cmd {
+	dir := m.dir
+	return tea.Batch(func() tea.Msg {
+		items, err := readDownloads(dir, installedApps())
+		return downloadsLoadedMsg{items: items, err: err}
+	}, tickCmd())
+}
+
+// sortItems orders the items by group, newest first within one.
+func (m *downloadsModel) sortItems() {
+	slices.SortStableFunc(m.items, func(a, b downloadItem) int {
+		return cmp.Or(cmp.Compare(m.group(a), m.group(b)), b.Added.Compare(a.Added), strings.Compare(a.Name, b.Name))
+	})
+}
+
+func (m downloadsModel) group(item downloadItem) int {
+	if m.byAge {
+		return downloadAge(item.Added, m.now)
+	}
+	return int(item.Kind)
+}
+
+func (m downloadsModel) groupLabel(group int) string {
+	if m.byAge {
+		return i18n.T(downloadAgeLabels[group])
+	}
+	return i18n.T(downloadKindLabels[group])
+}
+
+func (m downloadsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tickMsg:
+		if m.loading || m.deleting {
+			m.spinner = (m.spinner + 1) % len(spinnerFrames)
+			return m, tickCmd()
+		}
+	case downloadsLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.status = i18n.Tf("Scan failed: %v", msg.err)
+			return m, nil
+		}
+		m.items = msg.items
+		m.sortItems()
+		m.status = m.summary()
+	case deleteProgressMsg:
+		if !msg.done {
+			return m, nil
+		}
+		m.deleting = false
+		var freed int64
+		kept := m.items[:0]
+		for _, item := range m.items {
+			if item.Mark == markTrash {
+				if _, err := os.Lstat(item.Path); os.IsNotExist(err) {
+					freed += item.Size
+					continue
+				}
+			}
+			kept = append(kept, item)
+		}
+		m.items = kept
+		m.selected = min(m.selected, max(len(m.items)-1, 0))
+		m.status = i18n.Tf("Moved %d items to Trash, %s freed", msg.count, humanizeBytes(freed))
+		if msg.err != nil {
+			m.status = i18n.Tf("Failed to delete: %v", msg.err)
+		}
+	case tea.KeyMsg:
+		return m.updateKey(msg)
+	}
+	return m, nil
+}
+
+func (m downloadsModel) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.deleting {
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		return m, nil
+	}
+	if m.confirm {
+		switch msg.String() {
+		case "enter":
+			m.confirm = false
+			m.deleting = true
+			var count int64
+			m.deleted = &count
+			return m, tea.Batch(deleteMultiplePathsCmd(m.trashPaths, m.deleted), tickCmd())
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc", "q":
+			m.confirm = false
+			m.status = i18n.T("Cancelled")
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "q", "Q", "esc", "ctrl+c":
+		return m, tea.Quit
+	case "up", "k", "K":
+		m.selected = max(m.selected-1, 0)
+	case "down", "j", "J":
+		m.selected = min(m.selected+1, max(len(m.items)-1, 0))
+	case "x", "X", "delete", "backspace":
+		m.markSelected(markTrash)
+	case " ":
+		m.markSelected(markKeep)
+	case "u", "U":
+		m.markSelected(markNone)
+	case "m", "M":
+		marked := 0
+		for i := range m.items {
+			if m.items[i].Done != "" && m.items[i].Mark == markNone {
+				m.items[i].Mark = markTrash
+				marked++
+			}
+		}
+		m.status = i18n.Tf("Marked %d installed or expanded downloads for Trash", marked)
+	case "g", "G":
+		if len(m.items) > 0 {
+			path := m.items[m.selected].Path
+			m.byAge = !m.byAge
+			m.sortItems()
+			m.selected = slices.IndexFunc(m.items, func(item downloadItem) bool { return item.Path == path })
+		} else {
+			m.byAge = !m.byAge
+		}
+	case "o", "O", "f", "F":
+		if len(m.items) > 0 {
+			item := m.items[m.selected]
+			reveal := msg.String() == "f" || msg.String() == "F"
+			go func() { _ = safeOpen(item.Path, reveal) }()
+			if reveal {
+				m.status = i18n.Tf("Showing %s in Finder...", item.Name)
+			} else {
+				m.status = i18n.Tf("Opening %s...", item.Name)
+			}
+		}
+	case "enter":
+		m.trashPaths = m.trashPaths[:0]
+		for _, item := range m.items {
+			if item.Mark == markTrash {
+				m.trashPaths = append(m.trashPaths, item.Path)
+			}
+		}
+		if len(m.trashPaths) == 0 {
+			m.status = i18n.T("Mark downloads with X first")
+			return m, nil
+		}
+		m.confirm = true
+	}
+	m.offset = m.scrollOffset()
+	return m, nil
+}
+
+// markSelected marks the selected download, or clears its mark when it
+// already has this one, and moves on to the next.
+func (m *downloadsModel) markSelected(mark triageMark) {
+	if len(m.items) == 0 {
+		return
+	}
+	item := &m.items[m.selected]
+	if item.Mark == mark {
+		item.Mark = markNone
+		return
+	}
+	item.Mark = mark
+	m.selected = min(m.selected+1, len(m.items)-1)
+	m.status = m.summary()
+}
+
+// summary counts the marks for the status line.
+func (m downloadsModel) summary() string {
+	var count, kept int
+	var size int64
+	for _, item := range m.items {
+		switch item.Mark {
+		case markTrash:
+			count++
+			size += item.Size
+		case markKeep:
+			kept++
+		}
+	}
+	return i18n.Tf("%d to Trash (%s), %d kept, %d to go", count, humanizeBytes(size), kept, len(m.items)-count-kept)
+}
+
+// downloadRow is one line of the list: a group heading, or the item at
+// index item.
+type downloadRow struct {
+	group int
+	item  int
+}
+
+func (m downloadsModel) rows() []downloadRow {
+	var rows []downloadRow
+	for i, item := range m.items {
+		group := m.group(item)
+		if i == 0 || m.group(m.items[i-1]) != group {
+			rows = append(rows, downloadRow{group: group, item: -1})
+		}
+		rows = append(rows, downloadRow{group: group, item: i})
+	}
+	return rows
+}
+
+func (m downloadsModel) viewport() int {
+	if m.height <= 0 {
+		return defaultViewport + 4
+	}
+	return max(m.height-7, 3)
+}
+
+// scrollOffset keeps the selected row, and the heading above it when it
+// starts a group, in view.
+func (m downloadsModel) scrollOffset() int {
+	rows := m.rows()
+	at := slices.IndexFunc(rows, func(r downloadRow) bool { return r.item == m.selected })
+	if at > 0 && rows[at-1].item < 0 {
+		at--
+	}
+	offset, viewport := m.offset, m.viewport()
+	if at < offset {
+		offset = at
+	}
+	if sel := at + 1; sel-offset >= viewport && rows[at].item < 0 {
+		offset = sel - viewport + 1
+	} else if at-offset >= viewport {
+		offset = at - viewport + 1
+	}
+	return max(offset, 0)
+}
+
+func (m downloadsModel) View() string {
+	var b strings.Builder
+	fmt.Fprintln(&b)
+	var total int64
+	for _, item := range m.items {
+		total += item.Size
+	}
+	fmt.Fprintf(&b, "%s%s%s  %s%s%s", colorPurpleBold, i18n.T("Triage Downloads"), colorReset, colorGray, displayPath(m.dir), colorReset)
+	if !m.loading {
+		fmt.Fprintf(&b, "  |  %s", i18n.Tf("Total: %s", humanizeBytes(total)))
+	}
+	fmt.Fprintf(&b, "\n\n")
+
+	switch {
+	case m.loading:
+		fmt.Fprintf(&b, "%s%s%s%s %s\n", colorCyan, colorBold, spinnerFrames[m.spinner], colorReset, m.status)
+		return b.String()
+	case m.deleting:
+		count := int64(0)
+		if m.deleted != nil {
+			count = atomic.LoadInt64(m.deleted)
+		}
+		fmt.Fprintf(&b, "%s%s%s%s %s\n", colorCyan, colorBold, spinnerFrames[m.spinner], colorReset,
+			i18n.Tf("Deleting: %s items removed, please wait...", colorYellow+formatNumber(count)+colorReset))
+		return b.String()
+	case len(m.items) == 0:
+		fmt.Fprintf(&b, "  %s\n\n%s%s%s\n", i18n.T("Downloads is empty"), colorGray, i18n.T("Q/Ctrl+C Quit"), colorReset)
+		return b.String()
+	}
+
+	nameWidth := calculateNameWidth(m.width)
+	rows := m.rows()
+	end := min(m.offset+m.viewport(), len(rows))
+	for _, row := range rows[m.offset:end] {
+		if row.item < 0 {
+			var count int
+			var size int64
+			for _, item := range m.items {
+				if m.group(item) == row.group {
+					count++
+					size += item.Size
+				}
+			}
+			fmt.Fprintf(&b, "%s%s%s  %s%s%s\n", colorCyan, m.groupLabel(row.group), colorReset,
+				colorGray, i18n.Tf("%d items, %s", count, humanizeBytes(size)), colorReset)
+			continue
+		}
+		item := m.items[row.item]
+		prefix, nameColor := "  ", ""
+		if row.item == m.selected {
+			prefix, nameColor = colorCyan+colorBold+"▶ "+colorReset, colorCyan
+		}
+		mark := colorGray + "○" + colorReset
+		switch item.Mark {
+		case markKeep:
+			mark = colorGreen + "✓" + colorReset
+		case markTrash:
+			mark, nameColor = colorRed+"✗"+colorReset, colorGray
+		}
+		hint := ""
+		if item.Done != "" {
+			hint = fmt.Sprintf("  %s%s%s", colorYellow, i18n.T(item.Done), colorReset)
+		}
+		fmt.Fprintf(&b, "%s%s %s%s%s %10s  %s%-10s%s%s\n", prefix, mark,
+			nameColor, linkName(item.Path, trimNameWithWidth(item.Name, nameWidth), nameWidth), colorReset,
+			humanizeBytes(item.Size), colorGray, downloadAgeText(item.Added, m.now), colorReset, hint)
+	}
+
+	fmt.Fprintf(&b, "\n%s%s%s\n", colorGray, m.status, colorReset)
+	fmt.Fprintf(&b, "%s%s%s\n", colorGray, i18n.T("↑↓ | X Trash | Space Keep | U Unmark | M Mark installed | G Group | O Open | F File | Enter Apply | Q Quit"), colorReset)
+	if m.confirm {
+		var size int64
+		for _, item := range m.items {
+			if item.Mark == markTrash {
+				size += item.Size
+			}
+		}
+		fmt.Fprintf(&b, "\n%s%s%s %s  %s%s%s\n",
+			colorRed, i18n.T("Delete:"), colorReset,
+			i18n.Tf("%d items, %s", len(m.trashPaths), humanizeBytes(size)),
+			colorGray, i18n.T("Press Enter to confirm  |  ESC cancel"), colorReset)
+	}
+	return b.String()
+}
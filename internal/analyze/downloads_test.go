@@ -0,0 +1,165 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestInstallerStem(t *testing.T) {
+	for name, want := range map[string]string{
+		"Google Chrome-131.0.6778.dmg": "googlechrome",
+		"Firefox 131.0.dmg":            "firefox",
+		"1Password-8.10.48.dmg":        "1password",
+		"Zoom.pkg":                     "zoom",
+		"VSCode-darwin-universal.zip":  "vscode",
+		"rectangle_v0.80.tar.gz":       "rectangle",
+	} {
+		if got := installerStem(name); got != want {
+			t.Errorf("installerStem(%q) = %q, want %q", name, got, want)
+		}
+	}
+	keys := map[string]bool{appKey("Google Chrome.app"): true, appKey("zoom.us.app"): true, appKey("Arc.app"): true}
+	for name, want := range map[string]bool{
+		"googlechrome.dmg": true,
+		"Zoom.pkg":         true,
+		"Arc-1.2.dmg":      true,
+		"Ar.dmg":           false,
+		"Slack-4.41.dmg":   false,
+	} {
+		if got := installedApp(name, keys); got != want {
+			t.Errorf("installedApp(%q) = %v", name, got)
+		}
+	}
+}
+
+func TestReadDownloads(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"photos.zip", "notes.tar.gz", "Slack-4.41.dmg", "Zoom.pkg", "report.pdf", "movie.mp4", "big.iso.crdownload", ".DS_Store"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.MkdirAll(filepath.Join(dir, "photos"), 0o755)
+	os.WriteFile(filepath.Join(dir, "photos", "a.jpg"), make([]byte, 100), 0o644)
+
+	items, err := readDownloads(dir, []string{"zoom.us.app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]downloadItem{}
+	for _, item := range items {
+		got[item.Name] = item
+	}
+	if len(got) != 8 || got[".DS_Store"].Name != "" {
+		t.Fatalf("items = %+v", items)
+	}
+	for name, want := range map[string]struct {
+		kind downloadKind
+		done string
+	}{
+		"photos.zip":         {kindArchive, "expanded"},
+		"notes.tar.gz":       {kindArchive, ""},
+		"Slack-4.41.dmg":     {kindInstaller, ""},
+		"Zoom.pkg":           {kindInstaller, "installed"},
+		"report.pdf":         {kindDocument, ""},
+		"movie.mp4":          {kindVideo, ""},
+		"big.iso.crdownload": {kindUnfinished, ""},
+		"photos":             {kindFolder, ""},
+	} {
+		if item := got[name]; item.Kind != want.kind || item.Done != want.done {
+			t.Errorf("%s: kind %d done %q, want %d %q", name, item.Kind, item.Done, want.kind, want.done)
+		}
+	}
+	if got["photos"].Size != 100 {
+		t.Errorf("folder size = %d", got["photos"].Size)
+	}
+}
+
+func triageModel() downloadsModel {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local)
+	m := newDownloadsModel("/Users/me/Downloads")
+	m.now, m.loading = now, false
+	m.items = []downloadItem{
+		{Name: "old.pdf", Path: "/Users/me/Downloads/old.pdf", Size: 10, Kind: kindDocument, Added: now.AddDate(-1, 0, 0)},
+		{Name: "App.dmg", Path: "/Users/me/Downloads/App.dmg", Size: 500, Kind: kindInstaller, Added: now.Add(-time.Hour), Done: "installed"},
+		{Name: "new.pdf", Path: "/Users/me/Downloads/new.pdf", Size: 20, Kind: kindDocument, Added: now.Add(-2 * time.Hour)},
+		{Name: "x.zip", Path: "/Users/me/Downloads/x.zip", Size: 30, Kind: kindArchive, Added: now.AddDate(0, 0, -3), Done: "expanded"},
+	}
+	m.sortItems()
+	return m
+}
+
+func pressKey(m downloadsModel, key string) downloadsModel {
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	switch key {
+	case "enter":
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	case " ":
+		msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	}
+	next, _ := m.Update(msg)
+	return next.(downloadsModel)
+}
+
+func TestDownloadsTriage(t *testing.T) {
+	m := triageModel()
+	var order []string
+	for _, item := range m.items {
+		order = append(order, item.Name)
+	}
+	if strings.Join(order, ",") != "App.dmg,x.zip,new.pdf,old.pdf" {
+		t.Fatalf("by type = %v", order)
+	}
+
+	// Keep the installer, trash the archive; each mark moves down.
+	m = pressKey(m, " ")
+	m = pressKey(m, "x")
+	if m.items[0].Mark != markKeep || m.items[1].Mark != markTrash || m.selected != 2 {
+		t.Fatalf("marks = %d %d, selected %d", m.items[0].Mark, m.items[1].Mark, m.selected)
+	}
+	// M leaves the kept installer alone.
+	m = pressKey(m, "m")
+	if m.items[0].Mark != markKeep {
+		t.Error("M overrode a keep")
+	}
+	if !strings.Contains(m.status, "Marked 0 installed or expanded") {
+		t.Errorf("status = %q", m.status)
+	}
+
+	m = pressKey(m, "g")
+	order = order[:0]
+	for _, item := range m.items {
+		order = append(order, item.Name)
+	}
+	if strings.Join(order, ",") != "App.dmg,new.pdf,x.zip,old.pdf" || m.items[m.selected].Name != "new.pdf" {
+		t.Fatalf("by age = %v, selected %d", order, m.selected)
+	}
+	view := m.View()
+	for _, want := range []string{"Today", "This week", "Older", "installed", "expanded"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	m = pressKey(m, "enter")
+	if !m.confirm || len(m.trashPaths) != 1 || m.trashPaths[0] != "/Users/me/Downloads/x.zip" {
+		t.Fatalf("confirm %v, paths %v", m.confirm, m.trashPaths)
+	}
+	if !strings.Contains(m.View(), "1 items, 30 B") {
+		t.Errorf("confirm line missing:\n%s", m.View())
+	}
+}
+
+func TestDownloadsEnterNeedsMarks(t *testing.T) {
+	m := pressKey(triageModel(), "enter")
+	if m.confirm || !strings.Contains(m.status, "Mark downloads with X first") {
+		t.Errorf("confirm %v, status %q", m.confirm, m.status)
+	}
+}
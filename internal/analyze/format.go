@@ -1,6 +1,6 @@
 //go:build darwin
 
-package main
+package analyze
 
 import (
 	"fmt"
@@ -9,6 +9,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/rivo/uniseg"
+
+	"github.com/tw93/mole/internal/hyperlink"
 	"github.com/tw93/mole/internal/units"
 )
 
@@ -23,21 +26,20 @@ func displayPath(path string) string {
 	return path
 }
 
-// truncateMiddle trims the middle, keeping head and tail.
+// truncateMiddle trims the middle, keeping head and tail. It only cuts
+// between grapheme clusters, so a flag or an accented letter is never split.
 func truncateMiddle(s string, maxWidth int) string {
-	runes := []rune(s)
-	currentWidth := displayWidth(s)
-
-	if currentWidth <= maxWidth {
+	if displayWidth(s) <= maxWidth {
 		return s
 	}
+	clusters, widths := graphemeWidths(s)
 
 	if maxWidth < 10 {
 		width := 0
-		for i, r := range runes {
-			width += runeWidth(r)
+		for i, w := range widths {
+			width += w
 			if width > maxWidth {
-				return string(runes[:i])
+				return strings.Join(clusters[:i], "")
 			}
 		}
 		return s
@@ -48,8 +50,7 @@ func truncateMiddle(s string, maxWidth int) string {
 
 	headWidth := 0
 	headIdx := 0
-	for i, r := range runes {
-		w := runeWidth(r)
+	for i, w := range widths {
 		if headWidth+w > targetHeadWidth {
 			break
 		}
@@ -58,9 +59,8 @@ func truncateMiddle(s string, maxWidth int) string {
 	}
 
 	tailWidth := 0
-	tailIdx := len(runes)
-	for i, r := range slices.Backward(runes) {
-		w := runeWidth(r)
+	tailIdx := len(widths)
+	for i, w := range slices.Backward(widths) {
 		if tailWidth+w > targetTailWidth {
 			break
 		}
@@ -68,7 +68,7 @@ func truncateMiddle(s string, maxWidth int) string {
 		tailIdx = i
 	}
 
-	return string(runes[:headIdx]) + "..." + string(runes[tailIdx:])
+	return strings.Join(clusters[:headIdx], "") + "..." + strings.Join(clusters[tailIdx:], "")
 }
 
 func formatNumber(n int64) string {
@@ -81,7 +81,13 @@ func formatNumber(n int64) string {
 	return fmt.Sprintf("%.1fM", float64(n)/1000000)
 }
 
+// unitSystem is --units; Auto keeps SI sizes, as Finder shows them.
+var unitSystem = units.Auto
+
 func humanizeBytes(size int64) string {
+	if unitSystem == units.Binary {
+		return units.BytesIEC(uint64(max(size, 0)))
+	}
 	return units.BytesSI(size)
 }
 
@@ -116,7 +122,7 @@ func coloredProgressBar(value, maxValue int64, percent float64) string {
 		barColor = colorGreen
 	}
 	if filled == 0 {
-		return barColor + "▏" + strings.Repeat(" ", barWidth-1) + colorReset
+		return barColor + barGlyphs[3] + strings.Repeat(" ", barWidth-1) + colorReset
 	}
 
 	var bar strings.Builder
@@ -124,15 +130,15 @@ func coloredProgressBar(value, maxValue int64, percent float64) string {
 	for i := range barWidth {
 		if i < filled {
 			if i < filled-1 {
-				bar.WriteString("█")
+				bar.WriteString(barGlyphs[0])
 			} else {
 				remainder := (value * int64(barWidth)) % maxValue
 				if remainder > maxValue/2 {
-					bar.WriteString("█")
+					bar.WriteString(barGlyphs[0])
 				} else if remainder > maxValue/4 {
-					bar.WriteString("▓")
+					bar.WriteString(barGlyphs[1])
 				} else {
-					bar.WriteString("▒")
+					bar.WriteString(barGlyphs[2])
 				}
 			}
 		} else {
@@ -142,35 +148,27 @@ func coloredProgressBar(value, maxValue int64, percent float64) string {
 	return bar.String() + colorReset
 }
 
-// runeWidth returns display width for wide characters and emoji.
-func runeWidth(r rune) int {
-	if r >= 0x4E00 && r <= 0x9FFF || // CJK Unified Ideographs
-		r >= 0x3400 && r <= 0x4DBF || // CJK Extension A
-		r >= 0x20000 && r <= 0x2A6DF || // CJK Extension B
-		r >= 0x2A700 && r <= 0x2B73F || // CJK Extension C
-		r >= 0x2B740 && r <= 0x2B81F || // CJK Extension D
-		r >= 0x2B820 && r <= 0x2CEAF || // CJK Extension E
-		r >= 0x3040 && r <= 0x30FF || // Hiragana and Katakana
-		r >= 0x31F0 && r <= 0x31FF || // Katakana Phonetic Extensions
-		r >= 0xAC00 && r <= 0xD7AF || // Hangul Syllables
-		r >= 0xFF00 && r <= 0xFFEF || // Fullwidth Forms
-		r >= 0x1F300 && r <= 0x1F6FF || // Miscellaneous Symbols and Pictographs (includes Transport)
-		r >= 0x1F900 && r <= 0x1F9FF || // Supplemental Symbols and Pictographs
-		r >= 0x2600 && r <= 0x26FF || // Miscellaneous Symbols
-		r >= 0x2700 && r <= 0x27BF || // Dingbats
-		r >= 0xFE10 && r <= 0xFE1F || // Vertical Forms
-		r >= 0x1F000 && r <= 0x1F02F { // Mahjong Tiles
-		return 2
-	}
-	return 1
+// displayWidth is how many terminal cells s takes. Widths follow UAX #11 per
+// grapheme cluster (UAX #29): wide East Asian characters and emoji take two
+// cells, and combining marks, variation selectors, skin-tone modifiers and
+// zero-width joiners add nothing to the character they attach to, so a ZWJ
+// family or a flag counts as one two-cell emoji.
+func displayWidth(s string) int {
+	return uniseg.StringWidth(s)
 }
 
-func displayWidth(s string) int {
-	width := 0
-	for _, r := range s {
-		width += runeWidth(r)
-	}
-	return width
+// graphemeWidths splits s into grapheme clusters and their widths, the
+// units that truncation may cut between.
+func graphemeWidths(s string) (clusters []string, widths []int) {
+	state := -1
+	for s != "" {
+		var cluster string
+		var width int
+		cluster, s, width, state = uniseg.FirstGraphemeClusterInString(s, state)
+		clusters = append(clusters, cluster)
+		widths = append(widths, width)
+	}
+	return clusters, widths
 }
 
 // calculateNameWidth computes name column width from terminal width.
@@ -193,11 +191,7 @@ func trimNameWithWidth(name string, maxWidth int) string {
 		ellipsisWidth = 3
 	)
 
-	runes := []rune(name)
-	widths := make([]int, len(runes))
-	for i, r := range runes {
-		widths[i] = runeWidth(r)
-	}
+	clusters, widths := graphemeWidths(name)
 
 	currentWidth := 0
 	for i, w := range widths {
@@ -211,7 +205,7 @@ func trimNameWithWidth(name string, maxWidth int) string {
 			if j == 0 {
 				return ellipsis
 			}
-			return string(runes[:j]) + ellipsis
+			return strings.Join(clusters[:j], "") + ellipsis
 		}
 		currentWidth += w
 	}
@@ -227,6 +221,12 @@ func padName(name string, targetWidth int) string {
 	return name + strings.Repeat(" ", targetWidth-currentWidth)
 }
 
+// linkName is padName with name linked to path when the terminal shows
+// hyperlinks. The padding stays outside the link so it is not underlined.
+func linkName(path, name string, targetWidth int) string {
+	return hyperlink.File(path, name) + padName(name, targetWidth)[len(name):]
+}
+
 // formatUnusedTime formats time since last access.
 func formatUnusedTime(lastAccess time.Time) string {
 	if lastAccess.IsZero() {
@@ -1,6 +1,6 @@
 //go:build darwin
 
-package main
+package analyze
 
 import (
 	"strings"
@@ -10,27 +10,30 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-func TestRuneWidth(t *testing.T) {
+func TestDisplayWidthCountsGraphemeClusters(t *testing.T) {
 	tests := []struct {
 		name  string
-		input rune
+		input string
 		want  int
 	}{
-		{"ASCII letter", 'a', 1},
-		{"ASCII digit", '5', 1},
-		{"Chinese character", '中', 2},
-		{"Japanese hiragana", 'あ', 2},
-		{"Korean hangul", '한', 2},
-		{"CJK ideograph", '語', 2},
-		{"Full-width number", '１', 2},
-		{"ASCII space", ' ', 1},
-		{"Tab", '	', 1},
+		{"ASCII letter", "a", 1},
+		{"Chinese character", "中", 2},
+		{"Japanese hiragana", "あ", 2},
+		{"Korean hangul", "한", 2},
+		{"Full-width number", "１", 2},
+		{"Combining accent", "e\u0301", 1},
+		{"Flag", "🇯🇵", 2},
+		{"Skin tone modifier", "👍🏽", 2},
+		{"ZWJ family", "👨\u200d👩\u200d👧", 2},
+		{"Variation selector emoji", "❤️", 2},
+		{"Text-style symbol", "☺", 1},
+		{"Zero-width space", "a\u200bb", 2},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := runeWidth(tt.input); got != tt.want {
-				t.Errorf("runeWidth(%q) = %d, want %d", tt.input, got, tt.want)
+			if got := displayWidth(tt.input); got != tt.want {
+				t.Errorf("displayWidth(%q) = %d, want %d", tt.input, got, tt.want)
 			}
 		})
 	}
@@ -194,6 +197,19 @@ func TestTruncateMiddle(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:     "Truncate skin-tone emoji names",
+			input:    "👍🏽👍🏽👍🏽👍🏽👍🏽👍🏽👍🏽👍🏽👍🏽👍🏽.png",
+			maxWidth: 12,
+			check: func(t *testing.T, result string) {
+				if displayWidth(result) > 12 {
+					t.Errorf("Truncated width %d exceeds max %d", displayWidth(result), 12)
+				}
+				if strings.Count(result, "\U0001F3FD") != strings.Count(result, "👍🏽") {
+					t.Errorf("skin tone modifier split from its emoji: %q", result)
+				}
+			},
+		},
 		{
 			name:     "Very small width",
 			input:    "longname",
@@ -270,6 +286,7 @@ func TestPadName(t *testing.T) {
 		{"No padding needed", "longname", 5, 8},
 		{"Pad CJK", "中文", 10, 10},
 		{"Mixed CJK and ASCII", "hello世", 15, 15},
+		{"Flag and ZWJ emoji", "🇯🇵👨\u200d👩\u200d👧", 10, 10},
 	}
 
 	for _, tt := range tests {
@@ -313,6 +330,16 @@ func TestTrimNameWithWidth(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:     "Trim keeps emoji sequences whole",
+			input:    "trip🇯🇵👨\u200d👩\u200d👧photos.zip",
+			maxWidth: 9,
+			check: func(t *testing.T, result string) {
+				if result != "trip🇯🇵..." {
+					t.Errorf("expected the cut after the flag, got %q", result)
+				}
+			},
+		},
 		{
 			name:     "No trimming needed",
 			input:    "short.txt",
@@ -0,0 +1,322 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/output"
+)
+
+var (
+	gamesFlags      = flag.NewFlagSet("analyze games", flag.ExitOnError)
+	gamesUnusedDays = gamesFlags.Int("unused-days", 180, "count games not launched in this many days as unplayed")
+	gamesJSON       = gamesFlags.Bool("json", false, "print the games as JSON")
+)
+
+func init() { gamesFlags.Usage = gamesUsage }
+
+func gamesUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole analyze games [flags]
+
+Lists the games Steam, the Epic Games Launcher, and Battle.net installed,
+in every library folder, by size with when each was last played, and
+totals the space taken by games not launched in --unused-days. Nothing
+is changed; uninstall games from their launcher.
+
+`)
+	gamesFlags.PrintDefaults()
+}
+
+// Launchers of installedGame.
+const (
+	launcherSteam     = "Steam"
+	launcherEpic      = "Epic"
+	launcherBattleNet = "Battle.net"
+)
+
+// installedGame is a game a launcher installed. LastPlayed is zero when it
+// was never launched.
+type installedGame struct {
+	Launcher   string    `json:"launcher"`
+	Name       string    `json:"name"`
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	LastPlayed time.Time `json:"last_played,omitzero"`
+	Unplayed   bool      `json:"unplayed"`
+}
+
+// runGamesMode implements `mole analyze games` and exits on errors.
+func runGamesMode(args []string) {
+	gamesFlags.Parse(args)
+	if *gamesUnusedDays < 1 {
+		exitcode.Exit("analyze games", exitcode.Usage, fmt.Errorf("--unused-days: %d is not positive", *gamesUnusedDays))
+	}
+
+	home, _ := os.UserHomeDir()
+	support := filepath.Join(home, "Library", "Application Support")
+	var games []installedGame
+	games = append(games, readSteamGames(filepath.Join(support, "Steam"))...)
+	games = append(games, readEpicGames(filepath.Join(support, "Epic", "EpicGamesLauncher", "Data", "Manifests"))...)
+	games = append(games, readBattleNetGames("/Applications")...)
+	report := newGamesReport(games, time.Now(), time.Duration(*gamesUnusedDays)*24*time.Hour)
+
+	out, err := output.Create(output.Path(*outputTo))
+	if err != nil {
+		exitcode.Exit("analyze games", exitcode.Failed, err)
+	}
+	if *gamesJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		report.write(out, *gamesUnusedDays)
+	}
+	if err != nil {
+		out.Abort()
+		exitcode.Exit("analyze games", exitcode.Failed, err)
+	}
+	if err := out.Close(); err != nil {
+		exitcode.Exit("analyze games", exitcode.Failed, err)
+	}
+}
+
+// vdf is a node of Valve's KeyValues text format: every value is either a
+// string or a nested vdf.
+type vdf map[string]any
+
+// parseVDF reads the KeyValues text Steam writes to libraryfolders.vdf and
+// the appmanifest_ID.acf files. Keys are compared case-insensitively, so
+// they are lowered.
+func parseVDF(data string) vdf {
+	root := vdf{}
+	stack := []vdf{root}
+	var pending *string
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == '"':
+			var b strings.Builder
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' && i+1 < len(data) {
+					i++
+				}
+				b.WriteByte(data[i])
+			}
+			s := b.String()
+			if pending == nil {
+				pending = &s
+			} else {
+				stack[len(stack)-1][strings.ToLower(*pending)] = s
+				pending = nil
+			}
+		case c == '{':
+			child := vdf{}
+			if pending != nil {
+				stack[len(stack)-1][strings.ToLower(*pending)] = child
+				pending = nil
+			}
+			stack = append(stack, child)
+		case c == '}':
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+		}
+	}
+	return root
+}
+
+func (v vdf) child(key string) vdf {
+	child, _ := v[key].(vdf)
+	return child
+}
+
+func (v vdf) text(key string) string {
+	text, _ := v[key].(string)
+	return text
+}
+
+// steamLibraries returns the library folders libraryfolders.vdf in the
+// Steam folder lists, the Steam folder itself first. Older clients wrote
+// each path as the value of a numbered key instead of a "path" entry.
+func steamLibraries(steam string) []string {
+	libraries := []string{steam}
+	data, err := os.ReadFile(filepath.Join(steam, "steamapps", "libraryfolders.vdf"))
+	if err != nil {
+		return libraries
+	}
+	folders := parseVDF(string(data)).child("libraryfolders")
+	var keys []int
+	for key := range folders {
+		if n, err := strconv.Atoi(key); err == nil {
+			keys = append(keys, n)
+		}
+	}
+	slices.Sort(keys)
+	for _, n := range keys {
+		key := strconv.Itoa(n)
+		path := folders.text(key)
+		if folder := folders.child(key); folder != nil {
+			path = folder.text("path")
+		}
+		if path != "" && !slices.Contains(libraries, filepath.Clean(path)) {
+			libraries = append(libraries, filepath.Clean(path))
+		}
+	}
+	return libraries
+}
+
+// readSteamGames reads the appmanifest_ID.acf files of every Steam
+// library. Steam records the size and the last launch in each manifest.
+func readSteamGames(steam string) []installedGame {
+	var games []installedGame
+	for _, library := range steamLibraries(steam) {
+		manifests, _ := filepath.Glob(filepath.Join(library, "steamapps", "appmanifest_*.acf"))
+		for _, manifest := range manifests {
+			data, err := os.ReadFile(manifest)
+			if err != nil {
+				continue
+			}
+			app := parseVDF(string(data)).child("appstate")
+			if app == nil || app.text("installdir") == "" {
+				continue
+			}
+			game := installedGame{
+				Launcher: launcherSteam,
+				Name:     cmp.Or(app.text("name"), app.text("installdir")),
+				Path:     filepath.Join(library, "steamapps", "common", app.text("installdir")),
+			}
+			game.Size, _ = strconv.ParseInt(app.text("sizeondisk"), 10, 64)
+			if game.Size == 0 {
+				game.Size = folderSize(game.Path)
+			}
+			if played, _ := strconv.ParseInt(app.text("lastplayed"), 10, 64); played > 0 {
+				game.LastPlayed = time.Unix(played, 0)
+			}
+			games = append(games, game)
+		}
+	}
+	return games
+}
+
+// readEpicGames reads the launcher's .item manifests in dir. They hold no
+// play time, so the last read of the game's executable stands in for it.
+func readEpicGames(dir string) []installedGame {
+	items, _ := filepath.Glob(filepath.Join(dir, "*.item"))
+	var games []installedGame
+	for _, item := range items {
+		data, err := os.ReadFile(item)
+		if err != nil {
+			continue
+		}
+		var manifest struct {
+			DisplayName      string
+			AppName          string
+			InstallLocation  string
+			InstallSize      int64
+			LaunchExecutable string
+		}
+		if json.Unmarshal(data, &manifest) != nil || manifest.InstallLocation == "" {
+			continue
+		}
+		game := installedGame{
+			Launcher: launcherEpic,
+			Name:     cmp.Or(manifest.DisplayName, manifest.AppName),
+			Path:     manifest.InstallLocation,
+			Size:     manifest.InstallSize,
+		}
+		if game.Size == 0 {
+			game.Size = folderSize(game.Path)
+		}
+		if manifest.LaunchExecutable != "" {
+			game.LastPlayed = lastTouched(filepath.Join(game.Path, manifest.LaunchExecutable))
+		}
+		games = append(games, game)
+	}
+	return games
+}
+
+// readBattleNetGames finds the game folders Battle.net installed in dir,
+// which it marks with a .build.info file. Its own records are binary, so
+// the last read of a game app's executables stands in for the last launch.
+func readBattleNetGames(dir string) []installedGame {
+	infos, _ := filepath.Glob(filepath.Join(dir, "*", ".build.info"))
+	var games []installedGame
+	for _, info := range infos {
+		path := filepath.Dir(info)
+		game := installedGame{Launcher: launcherBattleNet, Name: filepath.Base(path), Path: path, Size: folderSize(path)}
+		executables, _ := filepath.Glob(filepath.Join(path, "*.app", "Contents", "MacOS"))
+		for _, executable := range executables {
+			if played := lastTouched(executable); played.After(game.LastPlayed) {
+				game.LastPlayed = played
+			}
+		}
+		games = append(games, game)
+	}
+	return games
+}
+
+// gamesReport is the result of `mole analyze games`.
+type gamesReport struct {
+	Games        []installedGame `json:"games"`
+	TotalSize    int64           `json:"total_size"`
+	UnplayedSize int64           `json:"unplayed_size"`
+}
+
+// newGamesReport marks the games not played within unusedAfter of now and
+// sorts them largest first.
+func newGamesReport(games []installedGame, now time.Time, unusedAfter time.Duration) gamesReport {
+	slices.SortFunc(games, func(a, b installedGame) int {
+		return cmp.Or(cmp.Compare(b.Size, a.Size), strings.Compare(a.Name, b.Name))
+	})
+	report := gamesReport{Games: games}
+	for i := range report.Games {
+		game := &report.Games[i]
+		game.Unplayed = game.LastPlayed.IsZero() || now.Sub(game.LastPlayed) > unusedAfter
+		report.TotalSize += game.Size
+		if game.Unplayed {
+			report.UnplayedSize += game.Size
+		}
+	}
+	return report
+}
+
+func (r gamesReport) write(w io.Writer, unusedDays int) {
+	if len(r.Games) == 0 {
+		fmt.Fprintln(w, "No Steam, Epic, or Battle.net games found.")
+		return
+	}
+	fmt.Fprintf(w, "Games: %d installed, %s\n", len(r.Games), humanizeBytes(r.TotalSize))
+	fmt.Fprintf(w, "\n%10s  %-11s  %-10s  %s\n", "SIZE", "LAST PLAYED", "LAUNCHER", "GAME")
+	unplayed := 0
+	for _, game := range r.Games {
+		played := "never"
+		if !game.LastPlayed.IsZero() {
+			played = game.LastPlayed.Local().Format("2006-01-02")
+		}
+		name := game.Name
+		if game.Unplayed {
+			unplayed++
+			name += colorYellow + ", unplayed" + colorReset
+		}
+		fmt.Fprintf(w, "%10s  %-11s  %-10s  %s\n", humanizeBytes(game.Size), played, game.Launcher, name)
+	}
+	if unplayed > 0 {
+		fmt.Fprintf(w, "\n%d games not launched in %d days take %s. Uninstall them from their launcher.\n",
+			unplayed, unusedDays, humanizeBytes(r.UnplayedSize))
+	}
+}
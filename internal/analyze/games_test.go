@@ -0,0 +1,123 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSteamLibraries(t *testing.T) {
+	steam, external := t.TempDir(), t.TempDir()
+	os.MkdirAll(filepath.Join(steam, "steamapps"), 0o755)
+	vdf := `"libraryfolders"
+{
+	"0"
+	{
+		"path"		"` + steam + `"
+		"apps" { "570" "30000" }
+	}
+	// An external drive.
+	"1"
+	{
+		"path"		"` + external + `/"
+	}
+}
+`
+	os.WriteFile(filepath.Join(steam, "steamapps", "libraryfolders.vdf"), []byte(vdf), 0o644)
+	if got := steamLibraries(steam); len(got) != 2 || got[1] != external {
+		t.Errorf("libraries = %v", got)
+	}
+
+	legacy := `"LibraryFolders"
+{
+	"TimeNextStatsReport"		"1700000000"
+	"1"		"` + external + `"
+}
+`
+	os.WriteFile(filepath.Join(steam, "steamapps", "libraryfolders.vdf"), []byte(legacy), 0o644)
+	if got := steamLibraries(steam); len(got) != 2 || got[1] != external {
+		t.Errorf("legacy libraries = %v", got)
+	}
+}
+
+func TestReadGames(t *testing.T) {
+	steam, epic, apps := t.TempDir(), t.TempDir(), t.TempDir()
+	played := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+
+	os.MkdirAll(filepath.Join(steam, "steamapps", "common", "dota 2 beta"), 0o755)
+	os.WriteFile(filepath.Join(steam, "steamapps", "appmanifest_570.acf"), []byte(`"AppState"
+{
+	"appid"		"570"
+	"name"		"Dota 2"
+	"installdir"		"dota 2 beta"
+	"LastPlayed"		"`+strconv.FormatInt(played.Unix(), 10)+`"
+	"SizeOnDisk"		"40000"
+}
+`), 0o644)
+	os.WriteFile(filepath.Join(steam, "steamapps", "appmanifest_620.acf"), []byte(`"AppState"
+{
+	"name"		"Portal 2"
+	"installdir"		"Portal 2"
+	"LastPlayed"		"0"
+	"SizeOnDisk"		"12000"
+}
+`), 0o644)
+
+	install := filepath.Join(t.TempDir(), "Fortnite")
+	writeModelFile(t, filepath.Join(install, "FortniteClient.app", "Contents", "MacOS", "FortniteClient"), 10, time.Now().AddDate(-1, 0, 0))
+	os.WriteFile(filepath.Join(epic, "ABC.item"), []byte(`{"DisplayName":"Fortnite","AppName":"Fortnite","InstallLocation":"`+install+`","InstallSize":90000,"LaunchExecutable":"FortniteClient.app/Contents/MacOS/FortniteClient"}`), 0o644)
+
+	diablo := filepath.Join(apps, "Diablo IV")
+	writeModelFile(t, filepath.Join(diablo, ".build.info"), 1, played)
+	writeModelFile(t, filepath.Join(diablo, "Diablo IV.app", "Contents", "MacOS", "Diablo IV"), 500, played)
+	os.MkdirAll(filepath.Join(apps, "Safari.app"), 0o755)
+
+	var games []installedGame
+	games = append(games, readSteamGames(steam)...)
+	games = append(games, readEpicGames(epic)...)
+	games = append(games, readBattleNetGames(apps)...)
+	report := newGamesReport(games, time.Now(), 180*24*time.Hour)
+
+	var names []string
+	for _, game := range report.Games {
+		names = append(names, game.Launcher+":"+game.Name)
+	}
+	if strings.Join(names, ",") != "Epic:Fortnite,Steam:Dota 2,Steam:Portal 2,Battle.net:Diablo IV" {
+		t.Fatalf("games = %v", names)
+	}
+	dota, portal, diabloGame := report.Games[1], report.Games[2], report.Games[3]
+	if !dota.LastPlayed.Equal(played) || dota.Unplayed || dota.Path != filepath.Join(steam, "steamapps", "common", "dota 2 beta") {
+		t.Errorf("dota = %+v", dota)
+	}
+	if !portal.Unplayed || !portal.LastPlayed.IsZero() {
+		t.Errorf("portal = %+v", portal)
+	}
+	if !report.Games[0].Unplayed || diabloGame.Unplayed || diabloGame.Size != 501 {
+		t.Errorf("fortnite = %+v, diablo = %+v", report.Games[0], diabloGame)
+	}
+	if report.UnplayedSize != 102000 {
+		t.Errorf("unplayed size = %d", report.UnplayedSize)
+	}
+
+	var buf bytes.Buffer
+	report.write(&buf, 180)
+	out := buf.String()
+	for _, want := range []string{"Games: 4 installed", "LAST PLAYED", "never", "2 games not launched in 180 days"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q:\n%s", want, out)
+		}
+	}
+
+	plainColors(t)
+	buf.Reset()
+	report.write(&buf, 180)
+	if strings.Contains(buf.String(), "\x1b") {
+		t.Errorf("plain report has escape codes: %q", buf.String())
+	}
+}
@@ -1,6 +1,6 @@
 //go:build darwin
 
-package main
+package analyze
 
 import (
 	"context"
@@ -10,6 +10,9 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/tw93/mole/internal/debuglog"
+	"github.com/tw93/mole/pkg/diskscan"
 )
 
 // createInsightEntries returns the list of hidden-space insight entries
@@ -63,6 +66,8 @@ func createInsightEntries() []dirEntry {
 		{"Spotify Cache", filepath.Join(home, "Library", "Application Support", "Spotify", "PersistentCache")},
 		{"JetBrains Cache", filepath.Join(home, "Library", "Caches", "JetBrains")},
 		{"Docker Data", filepath.Join(home, "Library", "Containers", "com.docker.docker", "Data")},
+		{"Mail Downloads", filepath.Join(mailLibrary(home), "Mail Downloads")},
+		{"Mail Attachment Cache", filepath.Join(mailLibrary(home), "Caches")},
 		{"pip Cache", filepath.Join(home, "Library", "Caches", "pip")},
 		{"Gradle Cache", filepath.Join(home, ".gradle", "caches")},
 		{"CocoaPods Cache", filepath.Join(home, "Library", "Caches", "CocoaPods")},
@@ -92,6 +97,28 @@ func createInsightEntries() []dirEntry {
 	return entries
 }
 
+// mailLibrary is the Library folder inside Mail's sandbox container.
+func mailLibrary(home string) string {
+	return filepath.Join(home, "Library", "Containers", "com.apple.mail", "Data", "Library")
+}
+
+// insightHint says how to reclaim an insight that mo clean leaves alone,
+// or returns "" for any other path. Mail's folders sit under a container
+// the shell layer protects, so they are only suggested, never cleaned.
+func insightHint(path string) string {
+	home := os.Getenv("HOME")
+	if home == "" {
+		return ""
+	}
+	switch path {
+	case filepath.Join(mailLibrary(home), "Mail Downloads"):
+		return "opened attachments, safe to delete in Finder"
+	case filepath.Join(mailLibrary(home), "Caches"):
+		return "Mail rebuilds it, quit Mail and delete in Finder"
+	}
+	return ""
+}
+
 // measureInsightSize measures the size of a path.
 // Old Downloads is treated specially: only files older than 90 days are counted.
 func measureInsightSize(path string) (int64, error) {
@@ -101,7 +128,7 @@ func measureInsightSize(path string) (int64, error) {
 		return measureOldDownloads(path, 90)
 	}
 
-	return measureOverviewSize(path)
+	return diskscan.Measure(path)
 }
 
 // measureOldDownloads calculates total size of files in a directory
@@ -147,7 +174,9 @@ func getDirSizeFast(path string) (int64, error) {
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "du", "-sk", path)
+	start := time.Now()
 	output, err := cmd.Output()
+	debuglog.Command("du", []string{"-sk", path}, time.Since(start), err)
 	if err != nil {
 		return 0, err
 	}
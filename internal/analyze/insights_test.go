@@ -1,6 +1,6 @@
 //go:build darwin
 
-package main
+package analyze
 
 import (
 	"os"
@@ -55,6 +55,49 @@ func TestCreateInsightEntriesIncludesOrbStackData(t *testing.T) {
 	t.Fatal("OrbStack Data insight not found")
 }
 
+func TestCreateInsightEntriesIncludesMail(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	library := filepath.Join(home, "Library", "Containers", "com.apple.mail", "Data", "Library")
+	want := map[string]string{
+		"Mail Downloads":        filepath.Join(library, "Mail Downloads"),
+		"Mail Attachment Cache": filepath.Join(library, "Caches"),
+	}
+	for _, path := range want {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(path, "invoice.pdf"), make([]byte, 4096), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found := map[string]bool{}
+	for _, entry := range createInsightEntries() {
+		path, ok := want[entry.Name]
+		if !ok {
+			continue
+		}
+		found[entry.Name] = true
+		if entry.Path != path {
+			t.Errorf("%s path = %q, want %q", entry.Name, entry.Path, path)
+		}
+		if insightHint(entry.Path) == "" {
+			t.Errorf("%s has no cleanup hint", entry.Name)
+		}
+		if size, err := measureInsightSize(entry.Path); err != nil || size == 0 {
+			t.Errorf("%s size = %d, %v", entry.Name, size, err)
+		}
+	}
+	if len(found) != len(want) {
+		t.Fatalf("Mail insights found = %v, want %v", found, want)
+	}
+	if hint := insightHint(filepath.Join(home, "Downloads")); hint != "" {
+		t.Errorf("Downloads hint = %q", hint)
+	}
+}
+
 func TestMeasureOldDownloads(t *testing.T) {
 	// Create a temp directory with old and new files.
 	dir := t.TempDir()
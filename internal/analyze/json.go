@@ -1,15 +1,20 @@
 //go:build darwin
 
-package main
+package analyze
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"io/fs"
 	"sort"
 	"sync"
-	"sync/atomic"
 	"time"
+
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/output"
+	"github.com/tw93/mole/pkg/diskscan"
 )
 
 type jsonOutput struct {
@@ -17,8 +22,18 @@ type jsonOutput struct {
 	Overview   bool            `json:"overview"`
 	Entries    []jsonEntry     `json:"entries"`
 	LargeFiles []jsonFileEntry `json:"large_files,omitempty"`
-	TotalSize  int64           `json:"total_size"`
-	TotalFiles int64           `json:"total_files,omitempty"`
+	// SparseFiles lists files far longer than the space they take; their
+	// size is what they take, which is what TotalSize counts.
+	SparseFiles []jsonSparseFile `json:"sparse_files,omitempty"`
+	Ages        *jsonAges        `json:"ages,omitempty"`
+	TotalSize   int64            `json:"total_size"`
+	TotalFiles  int64            `json:"total_files,omitempty"`
+	// Unreadable counts the folders the scan was refused and counted as
+	// empty; when it is set, analyze exits with exitcode.Partial.
+	Unreadable int64 `json:"unreadable,omitempty"`
+	// Volume is set when the path is on an external volume, which is
+	// scanned with fewer workers and without its system folders.
+	Volume *volumeProfile `json:"volume,omitempty"`
 }
 
 type jsonEntry struct {
@@ -29,6 +44,12 @@ type jsonEntry struct {
 	Insight    bool   `json:"insight,omitempty"`
 	Cleanable  bool   `json:"cleanable,omitempty"`
 	LastAccess string `json:"last_access,omitempty"`
+	// Unexpected marks an entry that grew to --baseline-factor times its
+	// size in the folder's `mole analyze baseline`, BaselineSize.
+	Unexpected   bool  `json:"unexpected,omitempty"`
+	BaselineSize int64 `json:"baseline_size,omitempty"`
+	// Hint says how to reclaim an insight mo clean does not remove.
+	Hint string `json:"hint,omitempty"`
 }
 
 type jsonFileEntry struct {
@@ -37,14 +58,46 @@ type jsonFileEntry struct {
 	Size int64  `json:"size"`
 }
 
+// jsonAges adds up file bytes by how long ago they were modified.
+type jsonAges struct {
+	Under30Days int64 `json:"under_30_days"`
+	To90Days    int64 `json:"30_to_90_days"`
+	To1Year     int64 `json:"90_days_to_1_year"`
+	To3Years    int64 `json:"1_to_3_years"`
+	Over3Years  int64 `json:"over_3_years"`
+}
+
+type jsonSparseFile struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Logical int64  `json:"logical_size"`
+}
+
 func runJSONMode(path string, isOverview bool) {
+	start := time.Now()
 	result := performScanForJSON(path, isOverview)
+	took := time.Since(start)
 
-	encoder := json.NewEncoder(os.Stdout)
+	out, err := output.Create(output.Path(*outputTo))
+	if err != nil {
+		exitcode.Exit("analyze", exitcode.Failed, err)
+	}
+	encoder := json.NewEncoder(out)
 	encoder.SetIndent("", "  ")
 	if err := encoder.Encode(result); err != nil {
-		fmt.Fprintf(os.Stderr, "failed to encode JSON: %v\n", err)
-		os.Exit(1)
+		out.Abort()
+		exitcode.Exit("analyze", exitcode.Failed, fmt.Errorf("failed to encode JSON: %w", err))
+	}
+	if err := out.Close(); err != nil {
+		exitcode.Exit("analyze", exitcode.Failed, err)
+	}
+	if msg, ok := scanFinished(path, result.TotalSize, took); ok {
+		notifier.Send(context.Background(), msg)
+	}
+	if result.Unreadable > 0 {
+		exitcode.Exit("analyze", exitcode.Partial, fmt.Errorf(
+			"%d folders could not be read and count as empty; run mo doctor to check Full Disk Access", result.Unreadable))
 	}
 }
 
@@ -56,23 +109,27 @@ func performScanForJSON(path string, isOverview bool) jsonOutput {
 }
 
 func performDirectoryScanForJSON(path string) jsonOutput {
-	var filesScanned, dirsScanned, bytesScanned int64
-	currentPath := &atomic.Value{}
-	currentPath.Store("")
-
-	result, err := scanPathConcurrentAllEntries(path, &filesScanned, &dirsScanned, &bytesScanned, currentPath)
+	scanner := diskscan.New(withScanFlags(diskscan.Options{Spotlight: useSpotlight, Cache: true, Exclude: excludeDirs}))
+	result, err := scanner.Scan(context.Background(), path)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to scan directory: %v\n", err)
-		os.Exit(1)
+		code := exitcode.Failed
+		if errors.Is(err, fs.ErrPermission) {
+			code = exitcode.Denied
+		}
+		exitcode.Exit("analyze", code, fmt.Errorf("failed to scan directory: %w", err))
 	}
 
 	return jsonOutput{
-		Path:       path,
-		Overview:   false,
-		Entries:    jsonEntriesFromDirEntries(result.Entries, false, nil),
-		LargeFiles: jsonFileEntriesFromFileEntries(result.LargeFiles),
-		TotalSize:  result.TotalSize,
-		TotalFiles: result.TotalFiles,
+		Path:        path,
+		Overview:    false,
+		Entries:     jsonEntriesFromDirEntries(result.Entries, false, nil),
+		LargeFiles:  jsonFileEntriesFromFileEntries(result.LargeFiles),
+		SparseFiles: jsonSparseFiles(result.SparseFiles),
+		Ages:        &jsonAges{result.Ages[0], result.Ages[1], result.Ages[2], result.Ages[3], result.Ages[4]},
+		TotalSize:   result.TotalSize,
+		TotalFiles:  result.TotalFiles,
+		Unreadable:  result.Unreadable,
+		Volume:      scanVolume,
 	}
 }
 
@@ -132,12 +189,12 @@ func measureOverviewEntriesForJSON(overviewEntries []dirEntry, insightPaths map[
 				err  error
 			)
 
-			if cached, cacheErr := loadOverviewCachedSize(item.Path); cacheErr == nil && cached > 0 {
+			if cached, cacheErr := diskscan.CachedSize(item.Path); cacheErr == nil && cached > 0 {
 				size = cached
 			} else if insightPaths[item.Path] {
 				size, err = measureInsightSize(item.Path)
 			} else {
-				size, err = measureOverviewSize(item.Path)
+				size, err = diskscan.Measure(item.Path)
 			}
 
 			if err == nil {
@@ -169,6 +226,13 @@ func jsonEntriesFromDirEntries(entries []dirEntry, isOverview bool, insightPaths
 
 		if isOverview {
 			item.Insight = insightPaths[entry.Path]
+			if item.Insight {
+				item.Hint = insightHint(entry.Path)
+			}
+		}
+
+		if baseline, ok := unexpectedEntry(entry); ok {
+			item.Unexpected, item.BaselineSize = true, baseline
 		}
 
 		if !entry.LastAccess.IsZero() {
@@ -187,3 +251,11 @@ func jsonFileEntriesFromFileEntries(files []fileEntry) []jsonFileEntry {
 	}
 	return output
 }
+
+func jsonSparseFiles(files []diskscan.SparseFile) []jsonSparseFile {
+	output := make([]jsonSparseFile, 0, len(files))
+	for _, f := range files {
+		output = append(output, jsonSparseFile(f))
+	}
+	return output
+}
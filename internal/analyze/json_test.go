@@ -1,6 +1,6 @@
 //go:build darwin
 
-package main
+package analyze
 
 import (
 	"fmt"
@@ -8,12 +8,14 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/tw93/mole/pkg/diskscan"
 )
 
 func TestPerformScanForJSONIncludesAllEntriesAndLargeFiles(t *testing.T) {
 	root := t.TempDir()
 
-	totalFiles := maxEntries + 6
+	totalFiles := diskscan.DefaultMaxEntries + 6
 	for i := 0; i < totalFiles-1; i++ {
 		path := filepath.Join(root, fmt.Sprintf("small-%02d.txt", i))
 		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
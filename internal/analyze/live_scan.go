@@ -0,0 +1,97 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"context"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tw93/mole/pkg/diskscan"
+)
+
+var nextLiveScanID atomic.Int64
+
+// excludeDirs are the --exclude names every scan skips.
+var excludeDirs []string
+
+// withScanFlags applies the --workers, --dir-workers, --fold-workers, and
+// --follow-symlinks flags to opts.
+func withScanFlags(opts diskscan.Options) diskscan.Options {
+	opts.Workers, opts.DirWorkers, opts.FoldWorkers = *workersFlag, *dirWorkersFlag, *foldWorkersFlag
+	opts.FollowSymlinks = *followLinks
+	return opts
+}
+
+// newScanner returns a scanner configured the way the TUI scans: the top
+// entries only, Spotlight for large files except on external volumes, and
+// the shared cache.
+func newScanner(progress *diskscan.Progress) *diskscan.Scanner {
+	return diskscan.New(withScanFlags(diskscan.Options{
+		MaxEntries: diskscan.DefaultMaxEntries,
+		Spotlight:  useSpotlight,
+		Cache:      true,
+		Exclude:    excludeDirs,
+		Progress:   progress,
+	}))
+}
+
+func startLiveScanCmd(path string, progress *diskscan.Progress) tea.Cmd {
+	return func() tea.Msg {
+		id := nextLiveScanID.Add(1)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		live, err := newScanner(progress).Live(ctx, path)
+		if err != nil {
+			cancel()
+			return liveScanStartMsg{id: id, path: path, err: err}
+		}
+
+		events := make(chan liveScanEventMsg, cap(live.Events))
+		go forwardLiveScanEvents(ctx, id, path, live.Events, events)
+
+		return liveScanStartMsg{
+			id:            id,
+			path:          path,
+			entries:       live.Entries,
+			totalSize:     live.TotalSize,
+			totalFiles:    live.TotalFiles,
+			largeFiles:    live.LargeFiles,
+			scanningPaths: live.Pending,
+			events:        events,
+			cancel:        cancel,
+		}
+	}
+}
+
+// forwardLiveScanEvents tags each scanner event with the scan it belongs
+// to, so the model can drop events from a scan it has navigated away from.
+// After cancel it only passes on the final Canceled event, if there is
+// room, and drains the rest so the scanner can finish and close.
+func forwardLiveScanEvents(ctx context.Context, id int64, path string, in <-chan diskscan.Event, out chan<- liveScanEventMsg) {
+	defer close(out)
+	for event := range in {
+		msg := liveScanEventMsg{id: id, path: path, kind: event.Kind, entry: event.Entry, result: event.Result, err: event.Err}
+		if event.Kind == diskscan.Canceled {
+			select {
+			case out <- msg:
+			default:
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case out <- msg:
+		}
+	}
+}
+
+func waitLiveScanEventCmd(events <-chan liveScanEventMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-events
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
@@ -0,0 +1,394 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-isatty"
+	"github.com/tw93/mole/internal/config"
+	"github.com/tw93/mole/internal/crash"
+	"github.com/tw93/mole/internal/debuglog"
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/helper"
+	"github.com/tw93/mole/internal/hyperlink"
+	"github.com/tw93/mole/internal/i18n"
+	"github.com/tw93/mole/internal/notify"
+	"github.com/tw93/mole/internal/output"
+	"github.com/tw93/mole/internal/theme"
+	"github.com/tw93/mole/internal/units"
+	"github.com/tw93/mole/pkg/diskscan"
+)
+
+// subcommands are the words analyze takes in place of a path.
+var subcommands = map[string]func(args []string){
+	"backups":    runBackupsMode,
+	"baseline":   runBaselineMode,
+	"cloud":      runCloudMode,
+	"compress":   runCompressMode,
+	"downloads":  runDownloadsMode,
+	"games":      runGamesMode,
+	"media":      runMediaMode,
+	"models":     runModelsMode,
+	"schedule":   runScheduleMode,
+	"simulators": runSimulatorsMode,
+	"vms":        runVMsMode,
+}
+
+// Flags are analyze's command-line flags. The mole root command parses them
+// from the arguments after "analyze" and lists them for shell completion.
+var Flags = flag.NewFlagSet("analyze", flag.ExitOnError)
+
+func init() { Flags.Usage = usage }
+
+func usage() {
+	names := slices.Sorted(maps.Keys(subcommands))
+	fmt.Fprintf(os.Stderr, `Usage: mole analyze [flags] [path]
+       mole analyze <subcommand> [flags] [path]
+
+Explores disk usage under path, or an overview of the disk without one.
+Subcommands, each with its own --help: %s.
+
+A subcommand's name always runs the subcommand, even when a folder of that
+name is in the current directory, as ~/Downloads is for "downloads" on a
+case-insensitive disk. To scan the folder, pass ./downloads or a full path.
+
+`, strings.Join(names, ", "))
+	Flags.PrintDefaults()
+}
+
+var (
+	jsonMode = Flags.Bool("json", false, "output analysis as JSON instead of TUI")
+	outputTo = output.AddFlag(Flags)
+	debugLog = Flags.Bool("debug", false, "log scan timings, du/mdfind invocations, and cache hits")
+	logFile  = Flags.String("log-file", "", "write the log to `file` instead of stderr (the TUI defaults to a file in the temp dir)")
+
+	themeName = Flags.String("theme", "", "color theme: dark, light, solarized, or high-contrast (defaults to $MO_THEME, then dark)")
+	noColor   = Flags.Bool("no-color", false, "plain ASCII output without color, as with NO_COLOR")
+	unitsFlag = Flags.String("units", "", "byte units: si (GB), binary (GiB), or auto (defaults to $MO_UNITS, then ~/.config/mole/units, then auto)")
+	langFlag  = Flags.String("lang", "", "interface language, e.g. en or zh (defaults to $MO_LANG, then the locale)")
+
+	configProfile = Flags.String("profile", "", "apply the named profile from ~/.config/mole/config.toml (defaults to $MO_PROFILE)")
+
+	excludeFlag = Flags.String("exclude", "", "comma-separated directory names to skip while scanning, on top of the built-in list")
+	targetsFlag = Flags.String("targets", "", "comma-separated folders the overview lists next to Home and Applications, e.g. ~/Projects")
+	followLinks = Flags.Bool("follow-symlinks", false, "count what symlinks point to, each folder and file once, instead of the links themselves; skips the scan cache")
+	errorFormat = exitcode.AddFlag(Flags)
+
+	// The CPU-based worker defaults suit an internal SSD; a USB disk or
+	// network share often scans faster with fewer.
+	workersFlag     = Flags.Int("workers", 0, "children of a folder to size at once (default: one per CPU, 2 to 12)")
+	dirWorkersFlag  = Flags.Int("dir-workers", 0, "directories walked at once inside those children (default: two per CPU, up to 6)")
+	foldWorkersFlag = Flags.Int("fold-workers", 0, "du processes sizing folded folders such as node_modules at once (default: one per CPU, up to 4)")
+
+	baselineFactor = Flags.Float64("baseline-factor", 2, "flag folders that grew to this many times their `mole analyze baseline` size")
+
+	notifyFlags = notify.AddFlags(Flags)
+	notifyAfter = Flags.Duration("notify-after", time.Minute, "with --notify, announce only scans that take at least this long")
+)
+
+// Main runs analyze with args, the command line after the program or
+// subcommand name, and exits the process when it is done.
+func Main(args []string) {
+	Flags.Parse(args)
+	explicit := map[string]bool{}
+	Flags.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	if err := exitcode.SetFormat(*errorFormat); err != nil {
+		exitcode.Exit("analyze", exitcode.Usage, err)
+	}
+	if err := config.Apply(Flags, "analyze", *configProfile); err != nil {
+		exitcode.Exit("analyze", exitcode.Usage, err)
+	}
+	var err error
+	if notifier, err = notifyFlags.Notifier(); err != nil {
+		exitcode.Exit("analyze", exitcode.Usage, err)
+	}
+	// A report on disk is the JSON one; there is no TUI to save.
+	if output.Path(*outputTo) != "" {
+		*jsonMode = true
+	}
+	if err := checkWorkerFlags(); err != nil {
+		exitcode.Exit("analyze", exitcode.Usage, err)
+	}
+	if *baselineFactor <= 1 {
+		exitcode.Exit("analyze", exitcode.Usage, fmt.Errorf("--baseline-factor: %g is not greater than 1", *baselineFactor))
+	}
+	excludeDirs = append(excludeDirs, splitNames(*excludeFlag)...)
+
+	palette, err := theme.Resolve(*themeName)
+	if err != nil {
+		exitcode.Exit("analyze", exitcode.Usage, fmt.Errorf("--theme: %w", err))
+	}
+	profile := colorProfile(os.Getenv, *noColor, output.Path(*outputTo) == "" && isatty.IsTerminal(os.Stdout.Fd()))
+	applyTheme(palette, profile)
+	lang := *langFlag
+	if lang == "" {
+		lang = i18n.Detect(os.Getenv)
+	}
+	i18n.Set(lang)
+	hyperlink.Enable(!*jsonMode && profile != theme.NoColor && hyperlink.Supported(os.Getenv))
+	if unitSystem, err = units.ResolveSystem(*unitsFlag); err != nil {
+		exitcode.Exit("analyze", exitcode.Usage, fmt.Errorf("--units: %w", err))
+	}
+
+	// The TUI owns the terminal, so --debug without --log-file logs to a
+	// temp file there and names it on exit.
+	debug, logPath := debuglog.Requested(*debugLog), *logFile
+	if debug && logPath == "" && !*jsonMode {
+		logPath = filepath.Join(os.TempDir(), "mole-analyze.log")
+		defer fmt.Fprintf(os.Stderr, "debug log written to %s\n", logPath)
+	}
+	logCloser, err := debuglog.Setup(debug, logPath)
+	if err != nil {
+		exitcode.Exit("analyze", exitcode.Usage, fmt.Errorf("--log-file: %w", err))
+	}
+	defer logCloser.Close()
+
+	// The privileged helper, when installed, sizes folders this user
+	// cannot read instead of counting them as empty.
+	if helper.Available() {
+		diskscan.PrivilegedSize = helper.Size
+	}
+
+	// A bare word is the subcommand even when a folder of that name is
+	// here; the folder is ./name.
+	if run, ok := subcommands[Flags.Arg(0)]; ok {
+		if _, err := os.Lstat(Flags.Arg(0)); err == nil {
+			fmt.Fprintf(os.Stderr, "Running mole analyze %s; to scan the folder %[1]s here, pass ./%[1]s.\n", Flags.Arg(0))
+		}
+		run(Flags.Args()[1:])
+		return
+	}
+
+	target := os.Getenv("MO_ANALYZE_PATH")
+	if target == "" && len(Flags.Args()) > 0 {
+		target = Flags.Args()[0]
+	}
+
+	var abs string
+	var isOverview bool
+
+	if target == "" {
+		isOverview = true
+		abs = "/"
+	} else {
+		var err error
+		abs, err = filepath.Abs(target)
+		if err != nil {
+			exitcode.Exit("analyze", exitcode.Usage, fmt.Errorf("cannot resolve %q: %w", target, err))
+		}
+		isOverview = false
+
+		if err := applyVolumeProfile(abs, explicit); err != nil {
+			exitcode.Exit("analyze", exitcode.Usage, err)
+		}
+		if err := checkWorkerFlags(); err != nil {
+			exitcode.Exit("analyze", exitcode.Usage, fmt.Errorf("%s: %w", config.Path(), err))
+		}
+		if scanVolume != nil && scanVolume.UUID != "" {
+			if err := rememberVolumeFlags(scanVolume.UUID, explicit); err != nil {
+				exitcode.Exit("analyze", exitcode.Failed, fmt.Errorf("remembering settings for %s: %w", scanVolume.Name, err))
+			}
+		}
+	}
+
+	go diskscan.PruneCache()
+	if *jsonMode {
+		runJSONMode(abs, isOverview)
+	} else {
+		runTUIMode(abs, isOverview)
+	}
+}
+
+// checkWorkerFlags rejects worker counts the scanner cannot use.
+func checkWorkerFlags() error {
+	for name, n := range map[string]int{"workers": *workersFlag, "dir-workers": *dirWorkersFlag, "fold-workers": *foldWorkersFlag} {
+		if n < 0 || n > diskscan.MaxWorkers {
+			return fmt.Errorf("--%s: %d is not between 0 and %d", name, n, diskscan.MaxWorkers)
+		}
+	}
+	return nil
+}
+
+func runTUIMode(path string, isOverview bool) {
+	// Warm overview cache only when the user opens a specific directory.
+	// Overview mode already schedules the same measurements for the foreground UI;
+	// running the prefetcher there doubles the du/io workload on cold start.
+	if !isOverview {
+		prefetchCtx, prefetchCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer prefetchCancel()
+		go prefetchOverviewCache(prefetchCtx)
+	}
+
+	p := tea.NewProgram(crash.Model(newModel(path, isOverview)), tea.WithAltScreen(), tea.WithoutCatchPanics())
+	if _, err := p.Run(); err != nil {
+		exitcode.Exit("analyze", exitcode.Failed, fmt.Errorf("analyzer error: %w", err))
+	}
+}
+
+func newModel(path string, isOverview bool) model {
+	var diskFreeBytes int64
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err == nil {
+		diskFreeBytes = int64(stat.Bavail) * int64(stat.Bsize)
+	}
+
+	m := model{
+		path:                path,
+		selected:            0,
+		status:              i18n.T("Preparing scan..."),
+		diskFree:            diskFreeBytes,
+		scanning:            !isOverview,
+		progress:            &diskscan.Progress{},
+		showLargeFiles:      false,
+		isOverview:          isOverview,
+		cache:               make(map[string]historyEntry),
+		overviewSizeCache:   make(map[string]int64),
+		overviewScanningSet: make(map[string]bool),
+		multiSelected:       make(map[string]bool),
+		largeMultiSelected:  make(map[string]bool),
+		liveSortMode:        liveScanSortModeFromEnv(),
+	}
+
+	if isOverview {
+		m.scanning = false
+		m.hydrateOverviewEntries()
+		m.selected = 0
+		m.offset = 0
+		if nextPendingOverviewIndex(m.entries) >= 0 {
+			m.overviewScanning = true
+			m.status = i18n.T("Checking system folders...")
+		} else {
+			m.status = i18n.T("Ready")
+		}
+	}
+
+	// Try to peek last total files for progress bar, even if cache is stale
+	if !isOverview {
+		if total, err := diskscan.CachedFileCount(path); err == nil && total > 0 {
+			m.lastTotalFiles = total
+		}
+	}
+
+	return m
+}
+
+func createOverviewEntries() []dirEntry {
+	return createOverviewEntriesWithInsights(createInsightEntries())
+}
+
+func createOverviewEntriesWithInsights(insightEntries []dirEntry) []dirEntry {
+	home := os.Getenv("HOME")
+	entries := []dirEntry{}
+
+	// Separate Home and ~/Library to avoid double counting.
+	if home != "" {
+		entries = append(entries, dirEntry{Name: "Home", Path: home, IsDir: true, Size: -1})
+
+		userLibrary := filepath.Join(home, "Library")
+		if _, err := os.Stat(userLibrary); err == nil {
+			// Renamed from "App Library" to "User Library" so it parallels
+			// "System Library" (`/Library`) and is not confused with
+			// `/Applications`. Path unchanged.
+			entries = append(entries, dirEntry{Name: "User Library", Path: userLibrary, IsDir: true, Size: -1})
+		}
+	}
+
+	entries = append(entries,
+		dirEntry{Name: "Applications", Path: "/Applications", IsDir: true, Size: -1},
+		dirEntry{Name: "System Library", Path: "/Library", IsDir: true, Size: -1},
+	)
+	entries = append(entries, targetEntries(*targetsFlag, home)...)
+
+	// Hidden space insights: paths that silently accumulate disk usage.
+	entries = append(entries, insightEntries...)
+
+	return entries
+}
+
+// targetEntries lists the --targets folders that exist. Like the insights
+// they may sit inside Home, so their sizes overlap it.
+func targetEntries(targets, home string) []dirEntry {
+	var entries []dirEntry
+	seen := map[string]bool{home: true}
+	for _, path := range strings.Split(targets, ",") {
+		path = strings.TrimSpace(path)
+		if rest, ok := strings.CutPrefix(path, "~"); ok && home != "" && (rest == "" || rest[0] == '/') {
+			path = home + rest
+		}
+		if path == "" || !filepath.IsAbs(path) {
+			continue
+		}
+		if path = filepath.Clean(path); seen[path] {
+			continue
+		}
+		seen[path] = true
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			continue
+		}
+		entries = append(entries, dirEntry{Name: filepath.Base(path), Path: path, IsDir: true, Size: -1})
+	}
+	return entries
+}
+
+func sumKnownEntrySizes(entries []dirEntry) int64 {
+	var total int64
+	for _, entry := range entries {
+		if entry.Size > 0 {
+			total += entry.Size
+		}
+	}
+	return total
+}
+
+func nextPendingOverviewIndex(entries []dirEntry) int {
+	for i, entry := range entries {
+		if entry.Size < 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasPendingOverviewEntries(entries []dirEntry) bool {
+	for _, entry := range entries {
+		if entry.Size < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func safeOpen(path string, reveal bool) error {
+	if err := validatePath(path); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), openCommandTimeout)
+	defer cancel()
+	args := []string{path}
+	if reveal {
+		args = []string{"-R", path}
+	}
+	return exec.CommandContext(ctx, "open", args...).Run()
+}
+
+// safePreview opens the file with the default macOS application.
+func safePreview(path string) error {
+	if err := validatePath(path); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), openCommandTimeout)
+	defer cancel()
+	return exec.CommandContext(ctx, "open", path).Run()
+}
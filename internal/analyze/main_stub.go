@@ -0,0 +1,18 @@
+//go:build !darwin
+
+package analyze
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/tw93/mole/internal/exitcode"
+)
+
+// Flags is empty off macOS, where analyze does not run.
+var Flags = flag.NewFlagSet("analyze", flag.ExitOnError)
+
+// Main reports that analyze needs macOS.
+func Main(args []string) {
+	exitcode.Exit("analyze", exitcode.Failed, errors.New("analyze is only supported on macOS"))
+}
@@ -0,0 +1,472 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/output"
+)
+
+const (
+	// mediaProbeWorkers is how many files are probed at once, and
+	// mediaProbeTimeout how long one probe may take.
+	mediaProbeWorkers = 4
+	mediaProbeTimeout = 15 * time.Second
+
+	// A file is a candidate when re-encoding saves at least
+	// mediaMinSavings and mediaMinShare of its size.
+	mediaMinSavings = 50 << 20
+	mediaMinShare   = 0.2
+
+	// defaultAudioBitRate stands in for an audio track whose rate the
+	// probe did not report.
+	defaultAudioBitRate = 192_000
+)
+
+var (
+	mediaFlags   = flag.NewFlagSet("analyze media", flag.ExitOnError)
+	mediaMinMB   = mediaFlags.Int64("min-mb", 200, "probe videos of at least this many MB")
+	mediaTop     = mediaFlags.Int("top", 20, "list at most this many candidates")
+	mediaJSON    = mediaFlags.Bool("json", false, "print the candidates as JSON")
+	mediaFFprobe = mediaFlags.String("ffprobe", "", "ffprobe binary (defaults to the one on PATH; Spotlight metadata is used without one)")
+)
+
+func init() { mediaFlags.Usage = mediaUsage }
+
+func mediaUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole analyze media [flags] [path]
+
+Finds the large videos under path (your home folder by default), reads
+their codec, resolution, and bit rate with ffprobe, or from Spotlight
+when ffprobe is not installed, and ranks them by how much space
+re-encoding to HEVC would save. Nothing is changed.
+
+`)
+	mediaFlags.PrintDefaults()
+}
+
+// videoExtensions are the file types media probes.
+var videoExtensions = map[string]bool{
+	".mov": true, ".mp4": true, ".m4v": true, ".mkv": true, ".avi": true,
+	".wmv": true, ".mpg": true, ".mpeg": true, ".mts": true, ".m2ts": true,
+	".ts": true, ".flv": true, ".webm": true, ".3gp": true, ".dv": true,
+}
+
+// runMediaMode implements `mole analyze media` and exits on errors.
+func runMediaMode(args []string) {
+	mediaFlags.Parse(args)
+	if *mediaMinMB < 1 || *mediaTop < 1 {
+		exitcode.Exit("analyze media", exitcode.Usage, errors.New("--min-mb and --top must be at least 1"))
+	}
+	target, err := os.UserHomeDir()
+	if mediaFlags.NArg() > 0 {
+		target, err = filepath.Abs(mediaFlags.Arg(0))
+	}
+	if err != nil {
+		exitcode.Exit("analyze media", exitcode.Usage, err)
+	}
+	probe, prober := probeWithSpotlight, "spotlight"
+	if path, err := exec.LookPath(cmp.Or(*mediaFFprobe, "ffprobe")); err == nil {
+		probe, prober = ffprobeWith(path), "ffprobe"
+	} else if *mediaFFprobe != "" {
+		exitcode.Exit("analyze media", exitcode.Usage, fmt.Errorf("--ffprobe: %w", err))
+	}
+
+	videos, err := findLargeFiles(target, *mediaMinMB<<20, isVideo)
+	if err != nil {
+		exitcode.Exit("analyze media", exitcode.Failed, err)
+	}
+	candidates := probeVideos(context.Background(), videos, probe)
+	report := mediaReport{Path: target, Prober: prober, Probed: len(videos), Candidates: candidates}
+	for _, c := range candidates {
+		report.TotalSavings += c.Savings
+	}
+	report.Candidates = candidates[:min(len(candidates), *mediaTop)]
+
+	out, err := output.Create(output.Path(*outputTo))
+	if err != nil {
+		exitcode.Exit("analyze media", exitcode.Failed, err)
+	}
+	if *mediaJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		report.write(out, len(candidates))
+	}
+	if err != nil {
+		out.Abort()
+		exitcode.Exit("analyze media", exitcode.Failed, err)
+	}
+	if err := out.Close(); err != nil {
+		exitcode.Exit("analyze media", exitcode.Failed, err)
+	}
+}
+
+// largeFile is a file findLargeFiles found.
+type largeFile struct {
+	path string
+	size int64
+}
+
+// findLargeFiles walks root for regular files of at least minSize bytes
+// whose names match, skipping hidden folders, --exclude names,
+// node_modules, and photo libraries, whose files belong to Photos.
+func findLargeFiles(root string, minSize int64, match func(name string) bool) ([]largeFile, error) {
+	var files []largeFile
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") || slices.Contains(excludeDirs, name) ||
+				name == "node_modules" || strings.HasSuffix(name, ".photoslibrary")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !match(name) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Size() >= minSize {
+			files = append(files, largeFile{path: path, size: info.Size()})
+		}
+		return nil
+	})
+	return files, err
+}
+
+// isVideo reports whether name has one of videoExtensions.
+func isVideo(name string) bool {
+	return videoExtensions[strings.ToLower(filepath.Ext(name))]
+}
+
+// mediaInfo is what a probe learns about a video. Rates are in bits per
+// second and zero when unknown.
+type mediaInfo struct {
+	Codec         string
+	Width, Height int
+	Duration      float64 // seconds
+	VideoBitRate  int64
+	AudioBitRate  int64
+	HasAudio      bool
+}
+
+type mediaProbe func(ctx context.Context, path string) (mediaInfo, error)
+
+// probeVideos probes videos a few at a time and returns the transcode
+// candidates among them, the largest savings first.
+func probeVideos(ctx context.Context, videos []largeFile, probe mediaProbe) []transcodeCandidate {
+	var (
+		mu         sync.Mutex
+		wg         sync.WaitGroup
+		sem        = make(chan struct{}, mediaProbeWorkers)
+		candidates []transcodeCandidate
+	)
+	for _, v := range videos {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			probeCtx, cancel := context.WithTimeout(ctx, mediaProbeTimeout)
+			defer cancel()
+			info, err := probe(probeCtx, v.path)
+			if err != nil {
+				return
+			}
+			if c, ok := estimateHEVC(v.path, v.size, info); ok {
+				mu.Lock()
+				candidates = append(candidates, c)
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	slices.SortFunc(candidates, func(a, b transcodeCandidate) int {
+		return cmp.Or(cmp.Compare(b.Savings, a.Savings), strings.Compare(a.Path, b.Path))
+	})
+	return candidates
+}
+
+// ffprobeWith probes with the ffprobe binary at bin.
+func ffprobeWith(bin string) mediaProbe {
+	return func(ctx context.Context, path string) (mediaInfo, error) {
+		out, err := exec.CommandContext(ctx, bin, "-v", "error",
+			"-show_entries", "format=duration,bit_rate:stream=codec_type,codec_name,width,height,bit_rate",
+			"-of", "json", path).Output()
+		if err != nil {
+			return mediaInfo{}, err
+		}
+		return parseFFprobe(out)
+	}
+}
+
+func parseFFprobe(data []byte) (mediaInfo, error) {
+	var probe struct {
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+			CodecName string `json:"codec_name"`
+			Width     int    `json:"width"`
+			Height    int    `json:"height"`
+			BitRate   string `json:"bit_rate"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+			BitRate  string `json:"bit_rate"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return mediaInfo{}, err
+	}
+	var info mediaInfo
+	for _, s := range probe.Streams {
+		rate, _ := strconv.ParseInt(s.BitRate, 10, 64)
+		switch {
+		case s.CodecType == "video" && info.Codec == "" && s.CodecName != "mjpeg" && s.CodecName != "png":
+			// Cover art is a video stream too; the first real one counts.
+			info.Codec, info.Width, info.Height, info.VideoBitRate = s.CodecName, s.Width, s.Height, rate
+		case s.CodecType == "audio":
+			info.HasAudio = true
+			info.AudioBitRate += rate
+		}
+	}
+	if info.Codec == "" {
+		return mediaInfo{}, errors.New("no video stream")
+	}
+	info.Duration, _ = strconv.ParseFloat(probe.Format.Duration, 64)
+	if info.VideoBitRate == 0 {
+		total, _ := strconv.ParseInt(probe.Format.BitRate, 10, 64)
+		info.VideoBitRate = max(total-info.audioRate(), 0)
+	}
+	return info, nil
+}
+
+// probeWithSpotlight reads the metadata Spotlight keeps for path, which
+// AVFoundation fills in for every video it can open.
+func probeWithSpotlight(ctx context.Context, path string) (mediaInfo, error) {
+	out, err := exec.CommandContext(ctx, "mdls",
+		"-name", "kMDItemCodecs", "-name", "kMDItemDurationSeconds",
+		"-name", "kMDItemPixelWidth", "-name", "kMDItemPixelHeight",
+		"-name", "kMDItemVideoBitRate", "-name", "kMDItemAudioBitRate",
+		"-name", "kMDItemTotalBitRate", path).Output()
+	if err != nil {
+		return mediaInfo{}, err
+	}
+	return parseMdls(string(out))
+}
+
+// parseMdls reads mdls output: `key = value` lines, where a list value
+// spans lines in parentheses and a missing one is (null).
+func parseMdls(text string) (mediaInfo, error) {
+	values := map[string][]string{}
+	var key string
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if k, v, ok := strings.Cut(line, "="); ok && key == "" {
+			k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+			switch v {
+			case "(":
+				key = k
+			case "(null)":
+			default:
+				values[k] = []string{strings.Trim(v, `"`)}
+			}
+			continue
+		}
+		if line == ")" {
+			key = ""
+		} else if key != "" {
+			values[key] = append(values[key], strings.Trim(strings.TrimSuffix(line, ","), `"`))
+		}
+	}
+	number := func(k string) float64 {
+		if v := values[k]; len(v) > 0 {
+			n, _ := strconv.ParseFloat(v[0], 64)
+			return n
+		}
+		return 0
+	}
+
+	var info mediaInfo
+	for _, codec := range values["kMDItemCodecs"] {
+		if name, video := spotlightCodec(codec); video && info.Codec == "" {
+			info.Codec = name
+		} else if !video {
+			info.HasAudio = true
+		}
+	}
+	if info.Codec == "" {
+		return mediaInfo{}, errors.New("no video codec in Spotlight metadata")
+	}
+	info.Width, info.Height = int(number("kMDItemPixelWidth")), int(number("kMDItemPixelHeight"))
+	info.Duration = number("kMDItemDurationSeconds")
+	info.VideoBitRate = int64(number("kMDItemVideoBitRate"))
+	info.AudioBitRate = int64(number("kMDItemAudioBitRate"))
+	if info.VideoBitRate == 0 {
+		info.VideoBitRate = max(int64(number("kMDItemTotalBitRate"))-info.audioRate(), 0)
+	}
+	return info, nil
+}
+
+// spotlightCodec maps a codec as Spotlight names it to ffprobe's name,
+// and reports whether it is a video codec.
+func spotlightCodec(name string) (string, bool) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.Contains(lower, "audio"):
+		return "", false
+	case strings.Contains(lower, "hevc") || strings.Contains(lower, "h.265"):
+		return "hevc", true
+	case strings.Contains(lower, "h.264") || strings.Contains(lower, "avc"):
+		return "h264", true
+	case strings.Contains(lower, "prores"):
+		return "prores", true
+	case strings.Contains(lower, "av1"):
+		return "av1", true
+	case strings.Contains(lower, "vp9"):
+		return "vp9", true
+	case strings.Contains(lower, "mpeg-4 video"):
+		return "mpeg4", true
+	case strings.Contains(lower, "mpeg-2") || strings.Contains(lower, "mpeg-1"):
+		return "mpeg2video", true
+	case strings.Contains(lower, "dv"):
+		return "dvvideo", true
+	case strings.Contains(lower, "jpeg"):
+		return "mjpeg", true
+	}
+	return "", false
+}
+
+// audioRate is the audio bit rate, guessed when the probe did not say.
+func (i mediaInfo) audioRate() int64 {
+	if i.AudioBitRate == 0 && i.HasAudio {
+		return defaultAudioBitRate
+	}
+	return i.AudioBitRate
+}
+
+// hevcBitRate is a video bit rate at which HEVC looks about as good as
+// typical camera and download sources, by frame size.
+func hevcBitRate(width, height int) int64 {
+	switch pixels := width * height; {
+	case pixels <= 640*480:
+		return 1_500_000
+	case pixels <= 1280*720:
+		return 3_000_000
+	case pixels <= 1920*1080:
+		return 6_000_000
+	case pixels <= 2560*1440:
+		return 10_000_000
+	}
+	return 16_000_000
+}
+
+// hevcShare is the share of a codec's bit rate HEVC needs for the same
+// picture: half of H.264's, less of the older codecs'. Intra-frame codecs
+// such as ProRes are far above hevcBitRate, which decides for them.
+var hevcShare = map[string]float64{
+	"h264":       0.5,
+	"mpeg4":      0.4,
+	"msmpeg4v3":  0.4,
+	"wmv3":       0.4,
+	"mpeg2video": 0.35,
+	"mpeg1video": 0.3,
+}
+
+// efficientCodecs already compress about as well as HEVC.
+var efficientCodecs = map[string]bool{"hevc": true, "av1": true, "vp9": true}
+
+// transcodeCandidate is a video and what re-encoding it to HEVC would save.
+type transcodeCandidate struct {
+	Path      string  `json:"path"`
+	Size      int64   `json:"size"`
+	Codec     string  `json:"codec"`
+	Width     int     `json:"width,omitempty"`
+	Height    int     `json:"height,omitempty"`
+	Duration  float64 `json:"duration_seconds"`
+	BitRate   int64   `json:"video_bit_rate"`
+	Estimated int64   `json:"estimated_size"`
+	Savings   int64   `json:"savings"`
+}
+
+// estimateHEVC estimates the size of the video at path after re-encoding
+// its picture to HEVC with the audio kept as it is, and returns it as a
+// candidate when that saves enough to be worth doing.
+func estimateHEVC(path string, size int64, info mediaInfo) (transcodeCandidate, bool) {
+	if efficientCodecs[info.Codec] || info.Duration <= 0 {
+		return transcodeCandidate{}, false
+	}
+	video := info.VideoBitRate
+	if video == 0 {
+		video = max(int64(float64(size)*8/info.Duration)-info.audioRate(), 0)
+	}
+	target := hevcBitRate(info.Width, info.Height)
+	if share, ok := hevcShare[info.Codec]; ok {
+		target = min(target, int64(float64(video)*share))
+	}
+	if target >= video {
+		return transcodeCandidate{}, false
+	}
+	// The container adds about a percent.
+	estimated := int64(float64(target+info.audioRate()) / 8 * info.Duration * 1.01)
+	savings := size - estimated
+	if savings < mediaMinSavings || float64(savings) < mediaMinShare*float64(size) {
+		return transcodeCandidate{}, false
+	}
+	return transcodeCandidate{
+		Path: path, Size: size, Codec: info.Codec, Width: info.Width, Height: info.Height,
+		Duration: info.Duration, BitRate: video, Estimated: estimated, Savings: savings,
+	}, true
+}
+
+// mediaReport is the result of `mole analyze media`.
+type mediaReport struct {
+	Path         string               `json:"path"`
+	Prober       string               `json:"prober"`
+	Probed       int                  `json:"probed"`
+	Candidates   []transcodeCandidate `json:"candidates"`
+	TotalSavings int64                `json:"total_savings"`
+}
+
+// write prints the report as a table; found is how many candidates there
+// were before the list was cut to --top.
+func (r mediaReport) write(w io.Writer, found int) {
+	fmt.Fprintf(w, "Transcode candidates under %s: %d of %d videos probed with %s\n", displayPath(r.Path), found, r.Probed, r.Prober)
+	if found == 0 {
+		fmt.Fprintln(w, "Nothing would shrink much by re-encoding to HEVC.")
+		return
+	}
+	fmt.Fprintf(w, "\n%10s  %10s  %-10s  %-9s  %9s  %s\n", "SAVES", "SIZE", "CODEC", "FRAME", "BIT RATE", "FILE")
+	for _, c := range r.Candidates {
+		frame := "?"
+		if c.Width > 0 && c.Height > 0 {
+			frame = fmt.Sprintf("%dx%d", c.Width, c.Height)
+		}
+		fmt.Fprintf(w, "%10s  %10s  %-10s  %-9s  %9s  %s\n",
+			humanizeBytes(c.Savings), humanizeBytes(c.Size), c.Codec, frame,
+			fmt.Sprintf("%.1f Mb/s", float64(c.BitRate)/1e6), displayPath(c.Path))
+	}
+	fmt.Fprintf(w, "\nRe-encoding all %d to HEVC would save about %s. Estimates assume HEVC at about half the\nbit rate of H.264, capped by frame size, with the audio kept.\n", found, humanizeBytes(r.TotalSavings))
+}
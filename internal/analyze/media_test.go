@@ -0,0 +1,130 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFFprobe(t *testing.T) {
+	info, err := parseFFprobe([]byte(`{
+		"streams": [
+			{"codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080, "bit_rate": "20000000"},
+			{"codec_type": "audio", "codec_name": "aac", "bit_rate": "256000"},
+			{"codec_type": "video", "codec_name": "mjpeg", "width": 300, "height": 300}
+		],
+		"format": {"duration": "600.5", "bit_rate": "20300000"}
+	}`))
+	want := mediaInfo{Codec: "h264", Width: 1920, Height: 1080, Duration: 600.5, VideoBitRate: 20_000_000, AudioBitRate: 256_000, HasAudio: true}
+	if err != nil || info != want {
+		t.Fatalf("parseFFprobe = %+v, %v", info, err)
+	}
+
+	// Without a stream rate, the video rate is the total less the audio.
+	info, err = parseFFprobe([]byte(`{"streams": [{"codec_type": "video", "codec_name": "mpeg4"}, {"codec_type": "audio"}],
+		"format": {"duration": "10", "bit_rate": "5192000"}}`))
+	if err != nil || info.VideoBitRate != 5_000_000 {
+		t.Fatalf("parseFFprobe = %+v, %v", info, err)
+	}
+	if _, err := parseFFprobe([]byte(`{"streams": [{"codec_type": "audio"}]}`)); err == nil {
+		t.Error("an audio-only file parsed")
+	}
+}
+
+func TestParseMdls(t *testing.T) {
+	info, err := parseMdls(`kMDItemAudioBitRate    = (null)
+kMDItemCodecs          = (
+    "H.264",
+    AAC
+)
+kMDItemDurationSeconds = 1200
+kMDItemPixelHeight     = 2160
+kMDItemPixelWidth      = 3840
+kMDItemTotalBitRate    = 45192000
+kMDItemVideoBitRate    = (null)
+`)
+	want := mediaInfo{Codec: "h264", Width: 3840, Height: 2160, Duration: 1200, VideoBitRate: 45_000_000, HasAudio: true}
+	if err != nil || info != want {
+		t.Fatalf("parseMdls = %+v, %v", info, err)
+	}
+	if _, err := parseMdls("kMDItemCodecs = (null)\n"); err == nil {
+		t.Error("a file without codecs parsed")
+	}
+}
+
+func TestEstimateHEVC(t *testing.T) {
+	const size = 1500 << 20
+	// Ten minutes of 1080p H.264 at 20 Mb/s: HEVC at 6 Mb/s, the cap for
+	// 1080p, since half the current rate is more.
+	info := mediaInfo{Codec: "h264", Width: 1920, Height: 1080, Duration: 600, VideoBitRate: 20_000_000, AudioBitRate: 256_000, HasAudio: true}
+	c, ok := estimateHEVC("/m/a.mov", size, info)
+	wantEstimated := int64(float64(6_000_000+256_000) / 8 * 600 * 1.01)
+	if !ok || c.Estimated != wantEstimated || c.Savings != size-wantEstimated {
+		t.Fatalf("estimateHEVC = %+v, %v", c, ok)
+	}
+
+	// A low-rate H.264 file shrinks to half, which is below the cap.
+	info.VideoBitRate = 8_000_000
+	if c, ok := estimateHEVC("/m/a.mov", size, info); !ok || c.Estimated != int64(float64(4_000_000+256_000)/8*600*1.01) {
+		t.Errorf("half-rate estimate = %+v, %v", c, ok)
+	}
+
+	for name, info := range map[string]mediaInfo{
+		"hevc":         {Codec: "hevc", Width: 1920, Height: 1080, Duration: 600, VideoBitRate: 20_000_000},
+		"no duration":  {Codec: "h264", Width: 1920, Height: 1080, VideoBitRate: 20_000_000},
+		"already lean": {Codec: "theora", Width: 1920, Height: 1080, Duration: 600, VideoBitRate: 1_000_000},
+	} {
+		if c, ok := estimateHEVC("/m/a.mov", size, info); ok {
+			t.Errorf("%s: estimateHEVC = %+v", name, c)
+		}
+	}
+	// Savings under the floor are not worth listing.
+	small := mediaInfo{Codec: "h264", Width: 1920, Height: 1080, Duration: 20, VideoBitRate: 20_000_000}
+	if c, ok := estimateHEVC("/m/a.mov", 50<<20, small); ok {
+		t.Errorf("small file estimate = %+v", c)
+	}
+}
+
+func TestProbeVideosRanksBySavings(t *testing.T) {
+	dir := t.TempDir()
+	for name, size := range map[string]int64{"a.MOV": 3 << 20, "b.mp4": 2 << 20, "tiny.mp4": 1 << 10, "notes.txt": 4 << 20} {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Truncate(size)
+		f.Close()
+	}
+	os.Mkdir(filepath.Join(dir, ".hidden"), 0o755)
+	os.WriteFile(filepath.Join(dir, ".hidden", "c.mp4"), make([]byte, 2<<20), 0o644)
+
+	videos, err := findLargeFiles(dir, 1<<20, isVideo)
+	if err != nil || len(videos) != 2 {
+		t.Fatalf("findLargeFiles = %+v, %v", videos, err)
+	}
+	// Pretend each file is a long 4K H.264 recording so the floors pass.
+	probe := func(_ context.Context, path string) (mediaInfo, error) {
+		if strings.HasSuffix(path, "b.mp4") {
+			return mediaInfo{}, errors.New("unreadable")
+		}
+		return mediaInfo{Codec: "h264", Width: 3840, Height: 2160, Duration: 3600, VideoBitRate: 100_000_000}, nil
+	}
+	videos = append(videos, largeFile{path: "/m/big.mov", size: 60 << 30})
+	videos[0].size = 40 << 30
+	got := probeVideos(context.Background(), videos, probe)
+	if len(got) != 2 || got[0].Path != "/m/big.mov" || !strings.HasSuffix(got[1].Path, "a.MOV") {
+		t.Fatalf("probeVideos = %+v", got)
+	}
+
+	var b strings.Builder
+	mediaReport{Path: dir, Prober: "ffprobe", Probed: 3, Candidates: got[:1], TotalSavings: got[0].Savings + got[1].Savings}.write(&b, len(got))
+	if !strings.Contains(b.String(), "2 of 3 videos probed with ffprobe") || !strings.Contains(b.String(), "3840x2160") ||
+		!strings.Contains(b.String(), "Re-encoding all 2 to HEVC") {
+		t.Errorf("report:\n%s", b.String())
+	}
+}
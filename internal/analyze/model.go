@@ -1,59 +1,30 @@
 //go:build darwin
 
-package main
+package analyze
 
 import (
 	"context"
 	"sort"
 	"strings"
-	"sync/atomic"
 	"time"
-)
-
-type dirEntry struct {
-	Name       string
-	Path       string
-	Size       int64
-	IsDir      bool
-	LastAccess time.Time
-}
-
-type fileEntry struct {
-	Name string
-	Path string
-	Size int64
-}
 
-type scanResult struct {
-	Entries    []dirEntry
-	LargeFiles []fileEntry
-	TotalSize  int64
-	TotalFiles int64
-	// dedupedHardlink is true when a hardlinked file in this subtree was
-	// counted as zero because another link was seen earlier in the same
-	// scan. Such a result is scan-order dependent and must not be written
-	// to the on-disk cache. In-memory only; never serialized to cacheEntry.
-	dedupedHardlink bool
-}
+	"github.com/tw93/mole/pkg/diskscan"
+)
 
-type cacheEntry struct {
-	Entries      []dirEntry
-	LargeFiles   []fileEntry
-	TotalSize    int64
-	TotalFiles   int64
-	ModTime      time.Time
-	ScanTime     time.Time
-	NeedsRefresh bool
-	// SchemaVersion guards against reusing cache written by an older binary
-	// with different sizing semantics. Entries not at cacheSchemaVersion are
-	// rejected on load. Old caches decode this as 0.
-	SchemaVersion int
-}
+// The scanner's types, under the names the TUI has always used.
+type (
+	dirEntry   = diskscan.Entry
+	fileEntry  = diskscan.File
+	sparseFile = diskscan.SparseFile
+	scanResult = diskscan.Result
+)
 
 type historyEntry struct {
 	Path          string
 	Entries       []dirEntry
 	LargeFiles    []fileEntry
+	SparseFiles   []sparseFile
+	Ages          diskscan.Ages
 	TotalSize     int64
 	TotalFiles    int64
 	Selected      int
@@ -84,20 +55,19 @@ type liveScanStartMsg struct {
 	err           error
 }
 
-type liveScanEventKind int
-
 const (
-	liveScanChildProgress liveScanEventKind = iota + 1
-	liveScanChildDone
-	liveScanComplete
-	liveScanFailed
-	liveScanCanceled
+	liveScanChildProgress = diskscan.ChildProgress
+	liveScanChildDone     = diskscan.ChildDone
+	liveScanComplete      = diskscan.Complete
+	liveScanFailed        = diskscan.Failed
+	liveScanCanceled      = diskscan.Canceled
 )
 
+// liveScanEventMsg is a diskscan.Event tagged with the scan it belongs to.
 type liveScanEventMsg struct {
 	id     int64
 	path   string
-	kind   liveScanEventKind
+	kind   diskscan.EventKind
 	entry  dirEntry
 	result scanResult
 	err    error
@@ -137,10 +107,7 @@ type model struct {
 	totalSize           int64
 	scanning            bool
 	spinner             int
-	filesScanned        *int64
-	dirsScanned         *int64
-	bytesScanned        *int64
-	currentPath         *atomic.Value
+	progress            *diskscan.Progress
 	showLargeFiles      bool
 	isOverview          bool
 	deleteConfirm       bool
@@ -168,6 +135,13 @@ type model struct {
 	largeFilesAll  []fileEntry
 	largeFilter    string
 	largeFiltering bool
+	// sparseFiles are listed under the top files, far longer than the
+	// space they take.
+	sparseFiles []sparseFile
+	// ages adds up the scanned bytes by modification age, which the A
+	// view charts.
+	ages     diskscan.Ages
+	showAges bool
 	// Directory (drill-down) view incremental filter, mirroring the Top-files
 	// one. entriesAll is the full non-empty entry list; entries is the rendered,
 	// possibly filtered view. Disabled in overview mode.
@@ -175,6 +149,7 @@ type model struct {
 	entryFilter         string
 	entryFiltering      bool
 	liveScanID          int64
+	liveScanStarted     time.Time
 	liveScanCancel      context.CancelFunc
 	liveScanEvents      <-chan liveScanEventMsg
 	liveScanningPaths   map[string]bool
@@ -196,7 +171,7 @@ func (m *model) hydrateOverviewEntries() {
 			m.entries[i].Size = size
 			continue
 		}
-		if size, err := loadOverviewCachedSize(m.entries[i].Path); err == nil {
+		if size, err := diskscan.CachedSize(m.entries[i].Path); err == nil {
 			m.entries[i].Size = size
 			m.overviewSizeCache[m.entries[i].Path] = size
 		}
@@ -212,18 +187,20 @@ func (m *model) sortOverviewEntriesBySize() {
 }
 
 func (m *model) getScanProgress() (files, dirs, bytes int64) {
-	if m.filesScanned != nil {
-		files = atomic.LoadInt64(m.filesScanned)
-	}
-	if m.dirsScanned != nil {
-		dirs = atomic.LoadInt64(m.dirsScanned)
-	}
-	if m.bytesScanned != nil {
-		bytes = atomic.LoadInt64(m.bytesScanned)
+	if m.progress != nil {
+		files, dirs, bytes = m.progress.Files.Load(), m.progress.Dirs.Load(), m.progress.Bytes.Load()
 	}
 	return
 }
 
+// progressPath is the path the scan is in, or "" before it has one.
+func (m *model) progressPath() string {
+	if m.progress == nil {
+		return ""
+	}
+	return m.progress.Path()
+}
+
 func (m *model) clampEntrySelection() {
 	if len(m.entries) == 0 {
 		m.selected = 0
@@ -261,7 +238,7 @@ func (m *model) clampLargeSelection() {
 	if m.largeSelected < 0 {
 		m.largeSelected = 0
 	}
-	viewport := calculateViewport(m.height, true)
+	viewport := m.largeViewport()
 	maxOffset := max(len(m.largeFiles)-viewport, 0)
 	if m.largeOffset > maxOffset {
 		m.largeOffset = maxOffset
@@ -295,6 +272,7 @@ func (m *model) removePathFromView(path string) {
 	// keeps the view, the query, and the selection consistent.
 	m.entriesAll = removeByPath(m.entriesAll, path, dirEntryPath)
 	m.largeFilesAll = removeByPath(m.largeFilesAll, path, fileEntryPath)
+	m.sparseFiles = removeByPath(m.sparseFiles, path, sparseFilePath)
 
 	if removedSize > 0 {
 		if removedSize > m.totalSize {
@@ -308,10 +286,11 @@ func (m *model) removePathFromView(path string) {
 	m.applyLargeFilter()
 }
 
-func fileEntryName(f fileEntry) string { return f.Name }
-func fileEntryPath(f fileEntry) string { return f.Path }
-func dirEntryName(e dirEntry) string   { return e.Name }
-func dirEntryPath(e dirEntry) string   { return e.Path }
+func fileEntryName(f fileEntry) string   { return f.Name }
+func fileEntryPath(f fileEntry) string   { return f.Path }
+func sparseFilePath(f sparseFile) string { return f.Path }
+func dirEntryName(e dirEntry) string     { return e.Name }
+func dirEntryPath(e dirEntry) string     { return e.Path }
 
 // filterMatches reports whether an item with the given name and path matches a
 // case-insensitive substring query. Single source of truth for both the
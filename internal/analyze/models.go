@@ -0,0 +1,352 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/output"
+)
+
+// ollamaRemoveTimeout bounds each `ollama rm`, which needs the Ollama
+// server and starts it when it is not running.
+const ollamaRemoveTimeout = 30 * time.Second
+
+var (
+	modelsFlags      = flag.NewFlagSet("analyze models", flag.ExitOnError)
+	modelsUnusedDays = modelsFlags.Int("unused-days", 60, "call models not read in this many days unused")
+	modelsClean      = modelsFlags.Bool("clean", false, "offer to remove the unused models")
+	modelsYes        = modelsFlags.Bool("yes", false, "with --clean, remove them without asking")
+	modelsJSON       = modelsFlags.Bool("json", false, "print the models as JSON")
+)
+
+func init() { modelsFlags.Usage = modelsUsage }
+
+func modelsUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole analyze models [flags]
+
+Lists the machine learning models cached by Hugging Face, Ollama,
+LM Studio, and PyTorch, largest first, with when each was last read.
+With --clean, it then offers to remove the ones unused for
+--unused-days: Ollama models with ollama rm, the others by moving
+them to Trash.
+
+`)
+	modelsFlags.PrintDefaults()
+}
+
+// Sources of mlModel.
+const (
+	sourceHuggingFace = "Hugging Face"
+	sourceOllama      = "Ollama"
+	sourceLMStudio    = "LM Studio"
+	sourcePyTorch     = "PyTorch"
+)
+
+// mlModel is one cached model. Ollama models are removed with ollama rm
+// because they share blobs, which each one's Size counts; Path is their
+// manifest. The others are removed by trashing Path.
+type mlModel struct {
+	Source   string    `json:"source"`
+	Name     string    `json:"name"`
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"last_used,omitzero"`
+	Unused   bool      `json:"unused"`
+}
+
+// runModelsMode implements `mole analyze models` and exits on errors.
+func runModelsMode(args []string) {
+	modelsFlags.Parse(args)
+	if *modelsUnusedDays < 1 {
+		exitcode.Exit("analyze models", exitcode.Usage, fmt.Errorf("--unused-days: %d is not positive", *modelsUnusedDays))
+	}
+	if *modelsClean && !*modelsYes && (*modelsJSON || !isatty.IsTerminal(os.Stdin.Fd())) {
+		exitcode.Exit("analyze models", exitcode.Usage, errors.New("pass --yes to clean without a terminal"))
+	}
+
+	home, _ := os.UserHomeDir()
+	var models []mlModel
+	models = append(models, readHuggingFaceModels(huggingFaceHubDir(home))...)
+	models = append(models, readOllamaModels(cmp.Or(os.Getenv("OLLAMA_MODELS"), filepath.Join(home, ".ollama", "models")))...)
+	for _, dir := range []string{filepath.Join(home, ".lmstudio", "models"), filepath.Join(home, ".cache", "lm-studio", "models")} {
+		models = append(models, readLMStudioModels(dir)...)
+	}
+	models = append(models, readPyTorchModels(cmp.Or(os.Getenv("TORCH_HOME"), filepath.Join(home, ".cache", "torch")))...)
+	report := newModelsReport(models, time.Now(), time.Duration(*modelsUnusedDays)*24*time.Hour)
+
+	out, err := output.Create(output.Path(*outputTo))
+	if err != nil {
+		exitcode.Exit("analyze models", exitcode.Failed, err)
+	}
+	if *modelsJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		report.write(out, *modelsUnusedDays, *modelsClean)
+	}
+	if err != nil {
+		out.Abort()
+		exitcode.Exit("analyze models", exitcode.Failed, err)
+	}
+	if err := out.Close(); err != nil {
+		exitcode.Exit("analyze models", exitcode.Failed, err)
+	}
+
+	if !*modelsClean || !slices.ContainsFunc(report.Models, func(m mlModel) bool { return m.Unused }) {
+		return
+	}
+	if !*modelsYes && !confirmPrompt(os.Stdin, os.Stderr, fmt.Sprintf("\nRemove the unused models, %s? [y/N] ", humanizeBytes(report.UnusedSize))) {
+		fmt.Fprintln(os.Stderr, "Nothing removed.")
+		return
+	}
+	var failed []string
+	for _, m := range report.Models {
+		if !m.Unused {
+			continue
+		}
+		if err := removeModel(m); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", m.Name, err))
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Removed %s (%s)\n", m.Name, humanizeBytes(m.Size))
+	}
+	if len(failed) > 0 {
+		exitcode.Exit("analyze models", exitcode.Partial, errors.New(strings.Join(failed, "; ")))
+	}
+}
+
+func confirmPrompt(in io.Reader, out io.Writer, prompt string) bool {
+	fmt.Fprint(out, prompt)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func removeModel(m mlModel) error {
+	if m.Source != sourceOllama {
+		return moveToTrash(m.Path)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ollamaRemoveTimeout)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, "ollama", "rm", m.Name).CombinedOutput(); err != nil {
+		return fmt.Errorf("ollama rm: %s", cmp.Or(strings.TrimSpace(string(out)), err.Error()))
+	}
+	return nil
+}
+
+// huggingFaceHubDir follows the variables huggingface_hub reads.
+func huggingFaceHubDir(home string) string {
+	if dir := os.Getenv("HF_HUB_CACHE"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("HF_HOME"); dir != "" {
+		return filepath.Join(dir, "hub")
+	}
+	return filepath.Join(home, ".cache", "huggingface", "hub")
+}
+
+// readHuggingFaceModels reads the models--ORG--NAME and datasets--ORG--NAME
+// folders of the hub cache. Their snapshots link into blobs, which hold
+// the data.
+func readHuggingFaceModels(dir string) []mlModel {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var models []mlModel
+	for _, e := range entries {
+		kind, repo, ok := strings.Cut(e.Name(), "--")
+		if !ok || !e.IsDir() || kind != "models" && kind != "datasets" {
+			continue
+		}
+		name := strings.ReplaceAll(repo, "--", "/")
+		if kind == "datasets" {
+			name += " (dataset)"
+		}
+		path := filepath.Join(dir, e.Name())
+		models = append(models, mlModel{Source: sourceHuggingFace, Name: name, Path: path, Size: folderSize(path), LastUsed: lastTouched(path)})
+	}
+	return models
+}
+
+// readOllamaModels reads the manifests under dir/manifests, one per
+// model and tag, and sizes each model by the blobs its manifest lists.
+func readOllamaModels(dir string) []mlModel {
+	root := filepath.Join(dir, "manifests")
+	var models []mlModel
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		var manifest struct {
+			Config struct {
+				Digest string `json:"digest"`
+				Size   int64  `json:"size"`
+			} `json:"config"`
+			Layers []struct {
+				Digest string `json:"digest"`
+				Size   int64  `json:"size"`
+			} `json:"layers"`
+		}
+		if json.Unmarshal(data, &manifest) != nil || len(manifest.Layers) == 0 {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		m := mlModel{Source: sourceOllama, Name: ollamaModelName(filepath.ToSlash(rel)), Path: path, Size: manifest.Config.Size}
+		for _, layer := range manifest.Layers {
+			m.Size += layer.Size
+			blob := filepath.Join(dir, "blobs", strings.Replace(layer.Digest, ":", "-", 1))
+			if used := lastTouched(blob); used.After(m.LastUsed) {
+				m.LastUsed = used
+			}
+		}
+		models = append(models, m)
+		return nil
+	})
+	return models
+}
+
+// ollamaModelName turns a manifest path such as
+// registry.ollama.ai/library/llama3/8b into the name ollama takes,
+// llama3:8b, keeping the host and namespace of models from elsewhere.
+func ollamaModelName(rel string) string {
+	rel = strings.TrimPrefix(rel, "registry.ollama.ai/")
+	rel = strings.TrimPrefix(rel, "library/")
+	if i := strings.LastIndex(rel, "/"); i >= 0 {
+		rel = rel[:i] + ":" + rel[i+1:]
+	}
+	return rel
+}
+
+// readLMStudioModels reads the PUBLISHER/MODEL folders LM Studio downloads
+// models into.
+func readLMStudioModels(dir string) []mlModel {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*", "*"))
+	var models []mlModel
+	for _, path := range paths {
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			continue
+		}
+		name := filepath.Base(filepath.Dir(path)) + "/" + filepath.Base(path)
+		models = append(models, mlModel{Source: sourceLMStudio, Name: name, Path: path, Size: folderSize(path), LastUsed: lastTouched(path)})
+	}
+	return models
+}
+
+// readPyTorchModels reads the weights torch.hub downloads into
+// hub/checkpoints under the torch cache in dir.
+func readPyTorchModels(dir string) []mlModel {
+	entries, err := os.ReadDir(filepath.Join(dir, "hub", "checkpoints"))
+	if err != nil {
+		return nil
+	}
+	var models []mlModel
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, "hub", "checkpoints", e.Name())
+		models = append(models, mlModel{Source: sourcePyTorch, Name: e.Name(), Path: path, Size: info.Size(), LastUsed: lastTouched(path)})
+	}
+	return models
+}
+
+// lastTouched is the latest time a file under root was read or written.
+// Loading a model reads its weights, which updates their access time.
+func lastTouched(root string) time.Time {
+	var latest time.Time
+	filepath.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if latest.Before(info.ModTime()) {
+			latest = info.ModTime()
+		}
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			if at := time.Unix(stat.Atimespec.Unix()); at.After(latest) {
+				latest = at
+			}
+		}
+		return nil
+	})
+	return latest
+}
+
+// modelsReport is the result of `mole analyze models`.
+type modelsReport struct {
+	Models     []mlModel `json:"models"`
+	TotalSize  int64     `json:"total_size"`
+	UnusedSize int64     `json:"unused_size"`
+}
+
+// newModelsReport marks the models not used within unusedAfter of now and
+// sorts them largest first.
+func newModelsReport(models []mlModel, now time.Time, unusedAfter time.Duration) modelsReport {
+	slices.SortFunc(models, func(a, b mlModel) int {
+		return cmp.Or(cmp.Compare(b.Size, a.Size), strings.Compare(a.Name, b.Name))
+	})
+	report := modelsReport{Models: models}
+	for i := range report.Models {
+		m := &report.Models[i]
+		m.Unused = m.LastUsed.IsZero() || now.Sub(m.LastUsed) > unusedAfter
+		report.TotalSize += m.Size
+		if m.Unused {
+			report.UnusedSize += m.Size
+		}
+	}
+	return report
+}
+
+func (r modelsReport) write(w io.Writer, unusedDays int, cleaning bool) {
+	if len(r.Models) == 0 {
+		fmt.Fprintln(w, "No Hugging Face, Ollama, LM Studio, or PyTorch models found.")
+		return
+	}
+	fmt.Fprintf(w, "ML models: %d models, %s, %s unused for %d days\n",
+		len(r.Models), humanizeBytes(r.TotalSize), humanizeBytes(r.UnusedSize), unusedDays)
+	fmt.Fprintf(w, "\n%10s  %-10s  %-12s  %s\n", "SIZE", "LAST USED", "SOURCE", "MODEL")
+	unused := 0
+	for _, m := range r.Models {
+		used := "never"
+		if !m.LastUsed.IsZero() {
+			used = m.LastUsed.Local().Format("2006-01-02")
+		}
+		name := m.Name
+		if m.Unused {
+			unused++
+			name += colorYellow + ", unused" + colorReset
+		}
+		fmt.Fprintf(w, "%10s  %-10s  %-12s  %s\n", humanizeBytes(m.Size), used, m.Source, name)
+	}
+	if unused > 0 && !cleaning {
+		fmt.Fprintf(w, "\nRun mole analyze models --clean to remove the %d unused models.\n", unused)
+	}
+}
@@ -0,0 +1,105 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeModelFile(t *testing.T, path string, size int, used time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, used, used); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOllamaModelName(t *testing.T) {
+	for rel, want := range map[string]string{
+		"registry.ollama.ai/library/llama3/8b":      "llama3:8b",
+		"registry.ollama.ai/jmorgan/phi/latest":     "jmorgan/phi:latest",
+		"hf.co/bartowski/Qwen2.5-7B-GGUF/Q4_K_M":    "hf.co/bartowski/Qwen2.5-7B-GGUF:Q4_K_M",
+		"registry.ollama.ai/library/nomic-embed/v1": "nomic-embed:v1",
+	} {
+		if got := ollamaModelName(rel); got != want {
+			t.Errorf("ollamaModelName(%q) = %q, want %q", rel, got, want)
+		}
+	}
+}
+
+func TestReadModelCaches(t *testing.T) {
+	root := t.TempDir()
+	recent := time.Now().Add(-24 * time.Hour)
+	old := time.Now().AddDate(-1, 0, 0)
+
+	hub := filepath.Join(root, "hub")
+	writeModelFile(t, filepath.Join(hub, "models--meta-llama--Llama-3.1-8B", "blobs", "abc"), 300, old)
+	writeModelFile(t, filepath.Join(hub, "datasets--squad--v2", "blobs", "def"), 50, recent)
+	writeModelFile(t, filepath.Join(hub, "version.txt"), 1, recent)
+
+	ollama := filepath.Join(root, "ollama")
+	writeModelFile(t, filepath.Join(ollama, "blobs", "sha256-aaa"), 10, recent)
+	writeModelFile(t, filepath.Join(ollama, "blobs", "sha256-bbb"), 10, old)
+	os.MkdirAll(filepath.Join(ollama, "manifests", "registry.ollama.ai", "library", "llama3"), 0o755)
+	os.WriteFile(filepath.Join(ollama, "manifests", "registry.ollama.ai", "library", "llama3", "8b"),
+		[]byte(`{"config":{"digest":"sha256:ccc","size":5},"layers":[{"digest":"sha256:aaa","size":1000},{"digest":"sha256:bbb","size":20}]}`), 0o644)
+
+	lmstudio := filepath.Join(root, "lmstudio")
+	writeModelFile(t, filepath.Join(lmstudio, "lmstudio-community", "gemma-2-9b-GGUF", "gemma.gguf"), 200, old)
+
+	torch := filepath.Join(root, "torch")
+	writeModelFile(t, filepath.Join(torch, "hub", "checkpoints", "resnet50-0676ba61.pth"), 80, recent)
+
+	var models []mlModel
+	models = append(models, readHuggingFaceModels(hub)...)
+	models = append(models, readOllamaModels(ollama)...)
+	models = append(models, readLMStudioModels(lmstudio)...)
+	models = append(models, readPyTorchModels(torch)...)
+	report := newModelsReport(models, time.Now(), 60*24*time.Hour)
+
+	var names []string
+	unused := map[string]bool{}
+	for _, m := range report.Models {
+		names = append(names, m.Name)
+		unused[m.Name] = m.Unused
+	}
+	want := "llama3:8b,meta-llama/Llama-3.1-8B,lmstudio-community/gemma-2-9b-GGUF,resnet50-0676ba61.pth,squad/v2 (dataset)"
+	if strings.Join(names, ",") != want {
+		t.Fatalf("models = %v", names)
+	}
+	if report.Models[0].Size != 1025 || report.Models[0].Source != sourceOllama {
+		t.Errorf("ollama model = %+v", report.Models[0])
+	}
+	if unused["llama3:8b"] || !unused["meta-llama/Llama-3.1-8B"] || !unused["lmstudio-community/gemma-2-9b-GGUF"] || unused["resnet50-0676ba61.pth"] {
+		t.Errorf("unused = %v", unused)
+	}
+	if report.UnusedSize != 500 {
+		t.Errorf("unused size = %d", report.UnusedSize)
+	}
+
+	var buf bytes.Buffer
+	report.write(&buf, 60, false)
+	out := buf.String()
+	for _, want := range []string{"5 models", "Hugging Face", "LM Studio", "PyTorch", "--clean to remove the 2 unused models"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q:\n%s", want, out)
+		}
+	}
+
+	plainColors(t)
+	buf.Reset()
+	report.write(&buf, 60, false)
+	if strings.Contains(buf.String(), "\x1b") {
+		t.Errorf("plain report has escape codes: %q", buf.String())
+	}
+}
@@ -0,0 +1,40 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/tw93/mole/internal/notify"
+)
+
+// notifier announces long scans on the --notify channels; nil without them.
+var notifier *notify.Notifier
+
+// scanFinished is the notification for a scan of path that found size
+// bytes in took, or false when the scan was quicker than --notify-after
+// and the user is likely still watching.
+func scanFinished(path string, size int64, took time.Duration) (notify.Message, bool) {
+	if took < *notifyAfter {
+		return notify.Message{}, false
+	}
+	return notify.Message{
+		Title: "Mole: scan finished",
+		Body:  displayPath(path) + ": " + humanizeBytes(size) + " in " + took.Round(time.Second).String(),
+	}, true
+}
+
+// scanFinishedCmd sends scanFinished off the update loop.
+func scanFinishedCmd(path string, size int64, took time.Duration) tea.Cmd {
+	msg, ok := scanFinished(path, size, took)
+	if !ok || notifier == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		notifier.Send(context.Background(), msg)
+		return nil
+	}
+}
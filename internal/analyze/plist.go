@@ -0,0 +1,74 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+// parsePlistDict reads the top-level dict of an XML property list into its
+// scalar values: strings, numbers, and dates as written, and booleans as
+// "true" or "false". Nested dicts, arrays, and data are skipped.
+func parsePlistDict(data []byte) (map[string]string, error) {
+	values := map[string]string{}
+	d := xml.NewDecoder(bytes.NewReader(data))
+	depth := 0
+	key := ""
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			// plist > dict > key/value: only the top dict's entries count.
+			if depth != 3 {
+				if depth > 3 {
+					if err := d.Skip(); err != nil {
+						return nil, err
+					}
+					depth--
+				}
+				continue
+			}
+			var text string
+			switch t.Name.Local {
+			case "dict", "array", "data":
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+				depth--
+				key = ""
+				continue
+			case "true", "false":
+				text = t.Name.Local
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+			default:
+				if err := d.DecodeElement(&text, &t); err != nil {
+					return nil, err
+				}
+			}
+			depth--
+			if t.Name.Local == "key" {
+				key = text
+				continue
+			}
+			if key != "" {
+				values[key] = text
+			}
+			key = ""
+		case xml.EndElement:
+			depth--
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,533 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/notify"
+	"github.com/tw93/mole/internal/output"
+	"github.com/tw93/mole/pkg/diskscan"
+)
+
+// scheduleLabel names the LaunchAgent that runs scheduled scans.
+const scheduleLabel = "com.tw93.mole.analyze-schedule"
+
+// scheduleTop is how many grown and shrunk folders a report lists.
+const scheduleTop = 10
+
+var (
+	scheduleFlags  = flag.NewFlagSet("analyze schedule", flag.ExitOnError)
+	scheduleWeekly = scheduleFlags.Bool("weekly", false, "scan once a week, on --day at --at")
+	scheduleDaily  = scheduleFlags.Bool("daily", false, "scan every day at --at")
+	scheduleDay    = scheduleFlags.String("day", "mon", "weekday for --weekly: sun, mon, tue, wed, thu, fri, or sat")
+	scheduleAt     = scheduleFlags.String("at", "09:00", "time of day to scan, as HH:MM")
+	scheduleReport = scheduleFlags.String("report", "", "after each scan, replace `file` with the growth report (gzipped if it ends in .gz)")
+	scheduleRemove = scheduleFlags.Bool("remove", false, "remove the schedule")
+	scheduleRun    = scheduleFlags.Bool("run", false, "scan now and deliver the report, as the launchd job does")
+	scheduleNotify = notify.AddFlags(scheduleFlags)
+)
+
+func init() { scheduleFlags.Usage = scheduleUsage }
+
+func scheduleUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole analyze schedule --weekly|--daily [flags] [path]
+       mole analyze schedule --remove
+       mole analyze schedule
+
+Installs a launchd job that scans path (your home folder by default) on a
+schedule and reports how much it grew since the last scan, and which
+folders grew most, through --notify or a --report file. Without flags it
+shows the schedule.
+
+`)
+	scheduleFlags.PrintDefaults()
+}
+
+// runScheduleMode implements `mole analyze schedule` and exits on errors.
+func runScheduleMode(args []string) {
+	scheduleFlags.Parse(args)
+	home, err := os.UserHomeDir()
+	if err != nil {
+		exitcode.Exit("analyze schedule", exitcode.Failed, err)
+	}
+	target := home
+	if scheduleFlags.NArg() > 0 {
+		if target, err = filepath.Abs(scheduleFlags.Arg(0)); err != nil {
+			exitcode.Exit("analyze schedule", exitcode.Usage, err)
+		}
+	}
+	plist := filepath.Join(home, "Library", "LaunchAgents", scheduleLabel+".plist")
+
+	switch {
+	case *scheduleRemove:
+		if err := removeSchedule(plist); err != nil {
+			exitcode.Exit("analyze schedule", exitcode.Failed, err)
+		}
+		fmt.Println("Scheduled scans removed.")
+	case *scheduleRun:
+		if notifier, err = scheduleNotify.Notifier(); err != nil {
+			exitcode.Exit("analyze schedule", exitcode.Usage, err)
+		}
+		state := filepath.Join(home, ".cache", "mole", "schedule")
+		if err := runScheduledScan(target, state, *scheduleReport); err != nil {
+			exitcode.Exit("analyze schedule", exitcode.Failed, err)
+		}
+	case *scheduleWeekly || *scheduleDaily:
+		s, err := installSchedule(plist, target, filepath.Join(home, "Library", "Logs", "mole"))
+		if err != nil {
+			exitcode.Exit("analyze schedule", exitcode.Usage, err)
+		}
+		fmt.Printf("Scanning %s %s. The first scan sets the baseline; growth shows from the second.\n", displayPath(target), s)
+	default:
+		showSchedule(os.Stdout, plist)
+	}
+}
+
+// schedule is when launchd runs the job.
+type schedule struct {
+	weekday      int // 0 is Sunday; -1 means every day
+	hour, minute int
+}
+
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+func parseSchedule(weekly, daily bool, day, at string) (schedule, error) {
+	if weekly == daily {
+		return schedule{}, errors.New("pick one of --weekly and --daily")
+	}
+	s := schedule{weekday: -1}
+	if weekly {
+		s.weekday = slices.Index(weekdays, strings.ToLower(day))
+		if s.weekday < 0 {
+			return schedule{}, fmt.Errorf("--day: %q must be one of %s", day, strings.Join(weekdays, ", "))
+		}
+	}
+	t, err := time.Parse("15:04", at)
+	if err != nil {
+		return schedule{}, fmt.Errorf("--at: %q must be HH:MM, e.g. 09:00", at)
+	}
+	s.hour, s.minute = t.Hour(), t.Minute()
+	return s, nil
+}
+
+func (s schedule) String() string {
+	at := fmt.Sprintf("%02d:%02d", s.hour, s.minute)
+	if s.weekday < 0 {
+		return "daily at " + at
+	}
+	return "weekly on " + time.Weekday(s.weekday).String() + " at " + at
+}
+
+// installSchedule writes the LaunchAgent and loads it in place of an older
+// one.
+func installSchedule(plist, target, logDir string) (schedule, error) {
+	s, err := parseSchedule(*scheduleWeekly, *scheduleDaily, *scheduleDay, *scheduleAt)
+	if err != nil {
+		return s, err
+	}
+	if _, err := scheduleNotify.Notifier(); err != nil {
+		return s, err
+	}
+	args, err := selfCommand()
+	if err != nil {
+		return s, err
+	}
+	args = append(args, "schedule", "--run")
+	var delivers bool
+	var flagErr error
+	scheduleFlags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "report":
+			if abs, err := filepath.Abs(f.Value.String()); err == nil {
+				args = append(args, "--report="+abs)
+				delivers = true
+			}
+		case "notify", "notify-webhook":
+			value, err := scheduleNotifyArg(f.Name, f.Value.String())
+			if err != nil {
+				flagErr = err
+				return
+			}
+			args = append(args, "--"+f.Name+"="+value)
+			delivers = true
+		}
+	})
+	if flagErr != nil {
+		return s, flagErr
+	}
+	if !delivers {
+		return s, errors.New("pick --notify, --notify-webhook, or --report so the report has somewhere to go")
+	}
+	args = append(args, target)
+	for _, arg := range args {
+		if !plistSafe(arg) {
+			return s, fmt.Errorf("%q has characters a launchd job cannot hold", arg)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(plist), 0o755); err != nil {
+		return s, err
+	}
+	if err := os.MkdirAll(logDir, 0o755); err != nil {
+		return s, err
+	}
+	text := schedulePlist(args, s, filepath.Join(logDir, "analyze-schedule.log"))
+	if err := os.WriteFile(plist, []byte(text), 0o644); err != nil {
+		return s, err
+	}
+	domain := "gui/" + strconv.Itoa(os.Getuid())
+	launchctl("bootout", domain+"/"+scheduleLabel)
+	if out, err := launchctl("bootstrap", domain, plist); err != nil {
+		return s, fmt.Errorf("launchctl bootstrap: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return s, nil
+}
+
+// scheduleNotifyArg checks a --notify or --notify-webhook value before it
+// is written into the job, and returns it in canonical form: the channel
+// names trimmed, or the webhook URL re-encoded.
+func scheduleNotifyArg(name, value string) (string, error) {
+	if name == "notify" {
+		var channels []string
+		for _, channel := range strings.Split(value, ",") {
+			channel = strings.TrimSpace(channel)
+			if channel == "" {
+				continue
+			}
+			if !slices.Contains(notify.Channels, channel) {
+				return "", fmt.Errorf("--notify: unknown channel %q (want %s)", channel, strings.Join(notify.Channels, ", "))
+			}
+			channels = append(channels, channel)
+		}
+		return strings.Join(channels, ","), nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("--notify-webhook: %q is not an http or https URL", value)
+	}
+	return u.String(), nil
+}
+
+// plistSafe reports whether s can go into a plist string as it is: valid
+// UTF-8 without control characters, which XML cannot carry.
+func plistSafe(s string) bool {
+	return utf8.ValidString(s) && !strings.ContainsFunc(s, unicode.IsControl)
+}
+
+// launchctl runs launchctl with args. Under MOLE_TEST_MODE or
+// MOLE_TEST_NO_AUTH it does nothing, so tests never load or unload a real
+// job.
+func launchctl(args ...string) ([]byte, error) {
+	if os.Getenv("MOLE_TEST_MODE") == "1" || os.Getenv("MOLE_TEST_NO_AUTH") == "1" {
+		return nil, nil
+	}
+	return exec.Command("launchctl", args...).CombinedOutput()
+}
+
+// selfCommand starts this binary's analyze. Run as analyze-go it is
+// analyze already; as mole it needs the command, and under any other name
+// the busybox root as well.
+func selfCommand() ([]string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	name, _, _ := strings.Cut(filepath.Base(exe), "-")
+	switch name {
+	case "analyze":
+		return []string{exe}, nil
+	case "mole":
+		return []string{exe, "analyze"}, nil
+	}
+	return []string{exe, "mole", "analyze"}, nil
+}
+
+// schedulePlist is the LaunchAgent that runs args on s. Its first comment
+// describes the schedule for showSchedule.
+func schedulePlist(args []string, s schedule, logPath string) string {
+	var b strings.Builder
+	// Comments cannot hold "--", so the path in the first one is escaped
+	// by hand.
+	esc := func(text string) string {
+		var e strings.Builder
+		xml.EscapeText(&e, []byte(text))
+		return e.String()
+	}
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<!-- mole analyze schedule: %s -->
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+`, esc(strings.ReplaceAll(s.String()+", scanning "+displayPath(args[len(args)-1]), "--", "- -")), scheduleLabel)
+	for _, arg := range args {
+		fmt.Fprintf(&b, "\t\t<string>%s</string>\n", esc(arg))
+	}
+	b.WriteString("\t</array>\n\t<key>StartCalendarInterval</key>\n\t<dict>\n")
+	if s.weekday >= 0 {
+		fmt.Fprintf(&b, "\t\t<key>Weekday</key>\n\t\t<integer>%d</integer>\n", s.weekday)
+	}
+	fmt.Fprintf(&b, "\t\t<key>Hour</key>\n\t\t<integer>%d</integer>\n\t\t<key>Minute</key>\n\t\t<integer>%d</integer>\n\t</dict>\n", s.hour, s.minute)
+	fmt.Fprintf(&b, `	<key>LowPriorityIO</key>
+	<true/>
+	<key>Nice</key>
+	<integer>10</integer>
+	<key>StandardOutPath</key>
+	<string>%[1]s</string>
+	<key>StandardErrorPath</key>
+	<string>%[1]s</string>
+</dict>
+</plist>
+`, esc(logPath))
+	return b.String()
+}
+
+var scheduleComment = regexp.MustCompile(`<!-- mole analyze schedule: (.*) -->`)
+
+// showSchedule says what is scheduled, from the plist's comment.
+func showSchedule(w io.Writer, plist string) {
+	data, err := os.ReadFile(plist)
+	if err != nil {
+		fmt.Fprintln(w, "No scans are scheduled. Add one with `mo analyze schedule --weekly --notify center`.")
+		return
+	}
+	what := "a scan"
+	if m := scheduleComment.FindSubmatch(data); m != nil {
+		what = string(m[1])
+	}
+	fmt.Fprintf(w, "Scheduled: %s (%s).\n", what, plist)
+	// A Homebrew upgrade moves the binary the job points at.
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if exe, ok := strings.CutPrefix(line, "<string>/"); ok {
+			exe = "/" + strings.TrimSuffix(exe, "</string>")
+			if _, err := os.Stat(exe); err != nil {
+				fmt.Fprintf(w, "The job runs %s, which is gone; run the schedule command again to update it.\n", exe)
+			}
+			break
+		}
+	}
+}
+
+func removeSchedule(plist string) error {
+	launchctl("bootout", "gui/"+strconv.Itoa(os.Getuid())+"/"+scheduleLabel)
+	if err := os.Remove(plist); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// scanSnapshot is what a scheduled scan keeps for the next one to compare
+// against.
+type scanSnapshot struct {
+	Path    string           `json:"path"`
+	Time    time.Time        `json:"time"`
+	Total   int64            `json:"total"`
+	Entries map[string]int64 `json:"entries"`
+}
+
+// takeSnapshot scans target fresh, bypassing the cache, and records the
+// size of each entry in it.
+func takeSnapshot(target string) (scanSnapshot, error) {
+	scanner := diskscan.New(withScanFlags(diskscan.Options{Exclude: excludeDirs}))
+	result, err := scanner.Scan(context.Background(), target)
+	if err != nil {
+		return scanSnapshot{}, fmt.Errorf("scanning %s: %w", target, err)
+	}
+	s := scanSnapshot{Path: target, Time: time.Now(), Total: result.TotalSize, Entries: map[string]int64{}}
+	for _, e := range result.Entries {
+		s.Entries[e.Name] = e.Size
+	}
+	return s, nil
+}
+
+// snapshotFile is the file in dir that keeps the snapshot of target.
+func snapshotFile(dir, target string) string {
+	sum := sha256.Sum256([]byte(target))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// readSnapshot returns the snapshot of target kept in file, or nil when
+// there is none.
+func readSnapshot(file, target string) *scanSnapshot {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var s scanSnapshot
+	if json.Unmarshal(data, &s) != nil || s.Path != target {
+		return nil
+	}
+	return &s
+}
+
+func writeSnapshot(file string, s scanSnapshot) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0o644)
+}
+
+// runScheduledScan scans target fresh, compares it with the last scheduled
+// scan of it, and delivers the result to the notifier and report.
+func runScheduledScan(target, stateDir, report string) error {
+	cur, err := takeSnapshot(target)
+	if err != nil {
+		return err
+	}
+	state := snapshotFile(stateDir, target)
+	g := compareScans(readSnapshot(state, target), cur)
+
+	var errs []error
+	if report != "" {
+		errs = append(errs, writeGrowthReport(report, g))
+	}
+	errs = append(errs, notifier.Send(context.Background(), g.message()))
+	errs = append(errs, writeSnapshot(state, cur))
+	return errors.Join(errs...)
+}
+
+func writeGrowthReport(path string, g growth) error {
+	out, err := output.Create(path)
+	if err != nil {
+		return err
+	}
+	g.writeReport(out)
+	return out.Close()
+}
+
+// growth is one scheduled scan against the one before it.
+type growth struct {
+	path      string
+	now, then time.Time // then is zero on the first scan
+	total     int64
+	delta     int64
+	changes   []folderChange // largest change first
+}
+
+type folderChange struct {
+	name  string
+	delta int64
+}
+
+func compareScans(prev *scanSnapshot, cur scanSnapshot) growth {
+	g := growth{path: cur.Path, now: cur.Time, total: cur.Total}
+	if prev == nil {
+		return g
+	}
+	g.then, g.delta = prev.Time, cur.Total-prev.Total
+	for name, size := range cur.Entries {
+		if d := size - prev.Entries[name]; d != 0 {
+			g.changes = append(g.changes, folderChange{name, d})
+		}
+	}
+	for name, size := range prev.Entries {
+		if _, ok := cur.Entries[name]; !ok && size != 0 {
+			g.changes = append(g.changes, folderChange{name, -size})
+		}
+	}
+	slices.SortFunc(g.changes, func(a, b folderChange) int {
+		if c := cmpAbs(b.delta, a.delta); c != 0 {
+			return c
+		}
+		return strings.Compare(a.name, b.name)
+	})
+	return g
+}
+
+func cmpAbs(a, b int64) int {
+	a, b = max(a, -a), max(b, -b)
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func signedBytes(n int64) string {
+	if n < 0 {
+		return "-" + humanizeBytes(-n)
+	}
+	return "+" + humanizeBytes(n)
+}
+
+// message is the notification: the total, the change, and the three
+// folders that grew most.
+func (g growth) message() notify.Message {
+	msg := notify.Message{Title: "Mole: disk growth", Body: displayPath(g.path) + ": " + humanizeBytes(g.total)}
+	if g.then.IsZero() {
+		msg.Body += "; growth shows after the next scan"
+		return msg
+	}
+	msg.Body += ", " + signedBytes(g.delta) + " since " + g.then.Format("Jan 2")
+	var grew []string
+	for _, c := range g.changes {
+		if c.delta > 0 && len(grew) < 3 {
+			grew = append(grew, c.name+" "+signedBytes(c.delta))
+		}
+	}
+	if len(grew) > 0 {
+		msg.Body += " (" + strings.Join(grew, ", ") + ")"
+	}
+	return msg
+}
+
+// writeReport writes the growth report: the total, then the folders that
+// grew and shrank most.
+func (g growth) writeReport(w io.Writer) {
+	fmt.Fprintf(w, "Mole scan of %s, %s\n", displayPath(g.path), g.now.Format("2006-01-02 15:04"))
+	if g.then.IsZero() {
+		fmt.Fprintf(w, "Total: %s\n\nThis is the first scan; the next one reports growth.\n", humanizeBytes(g.total))
+		return
+	}
+	fmt.Fprintf(w, "Total: %s (%s since %s)\n", humanizeBytes(g.total), signedBytes(g.delta), g.then.Format("2006-01-02 15:04"))
+	for _, section := range []struct {
+		title string
+		keep  func(int64) bool
+	}{
+		{"Grew", func(d int64) bool { return d > 0 }},
+		{"Shrank", func(d int64) bool { return d < 0 }},
+	} {
+		n := 0
+		for _, c := range g.changes {
+			if !section.keep(c.delta) || n == scheduleTop {
+				continue
+			}
+			if n == 0 {
+				fmt.Fprintf(w, "\n%s:\n", section.title)
+			}
+			fmt.Fprintf(w, "  %10s  %s\n", signedBytes(c.delta), c.name)
+			n++
+		}
+	}
+}
@@ -0,0 +1,135 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	s, err := parseSchedule(true, false, "Fri", "18:30")
+	if err != nil || s != (schedule{weekday: 5, hour: 18, minute: 30}) {
+		t.Fatalf("parseSchedule = %+v, %v", s, err)
+	}
+	if s.String() != "weekly on Friday at 18:30" {
+		t.Errorf("String() = %q", s)
+	}
+	if s, _ := parseSchedule(false, true, "mon", "07:05"); s.String() != "daily at 07:05" {
+		t.Errorf("daily String() = %q", s)
+	}
+	for _, bad := range []struct {
+		weekly, daily bool
+		day, at       string
+	}{
+		{false, false, "mon", "09:00"},
+		{true, true, "mon", "09:00"},
+		{true, false, "someday", "09:00"},
+		{true, false, "mon", "9am"},
+	} {
+		if _, err := parseSchedule(bad.weekly, bad.daily, bad.day, bad.at); err == nil {
+			t.Errorf("parseSchedule(%+v) accepted", bad)
+		}
+	}
+}
+
+func TestSchedulePlist(t *testing.T) {
+	args := []string{"/opt/mole/bin/analyze-go", "schedule", "--run", "--report=/tmp/a&b.txt", "/Volumes/x--y"}
+	text := schedulePlist(args, schedule{weekday: 1, hour: 9}, "/tmp/log")
+	for _, want := range []string{
+		"<string>" + scheduleLabel + "</string>",
+		"<string>--report=/tmp/a&amp;b.txt</string>",
+		"<key>Weekday</key>\n\t\t<integer>1</integer>",
+		"<key>Minute</key>\n\t\t<integer>0</integer>",
+		"<!-- mole analyze schedule: weekly on Monday at 09:00, scanning /Volumes/x- -y -->",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("plist lacks %q:\n%s", want, text)
+		}
+	}
+	if daily := schedulePlist(args, schedule{weekday: -1}, "/tmp/log"); strings.Contains(daily, "Weekday") {
+		t.Errorf("daily plist has a Weekday:\n%s", daily)
+	}
+}
+
+func TestScheduleNotifyArg(t *testing.T) {
+	for _, tc := range []struct{ name, value, want string }{
+		{"notify", " center, bell ", "center,bell"},
+		{"notify-webhook", "https://hooks.example.com/T0/B1?x=1 2", "https://hooks.example.com/T0/B1?x=1 2"},
+		{"notify-webhook", "https://hooks.example.com/a b", "https://hooks.example.com/a%20b"},
+	} {
+		if got, err := scheduleNotifyArg(tc.name, tc.value); err != nil || got != tc.want {
+			t.Errorf("scheduleNotifyArg(%q, %q) = %q, %v, want %q", tc.name, tc.value, got, err, tc.want)
+		}
+	}
+	for _, tc := range []struct{ name, value string }{
+		{"notify", "center,pager"},
+		{"notify-webhook", "file:///etc/passwd"},
+		{"notify-webhook", "https://hooks.example.com/\x00</string>"},
+		{"notify-webhook", "https:///no-host"},
+	} {
+		if got, err := scheduleNotifyArg(tc.name, tc.value); err == nil {
+			t.Errorf("scheduleNotifyArg(%q, %q) = %q, want an error", tc.name, tc.value, got)
+		}
+	}
+	if plistSafe("/Volumes/a\nb") || !plistSafe("/Volumes/Café & <Co>") {
+		t.Error("plistSafe misjudges control characters")
+	}
+}
+
+func TestInstallScheduleTestMode(t *testing.T) {
+	t.Setenv("MOLE_TEST_MODE", "1")
+	dir := t.TempDir()
+	scheduleFlags.Parse([]string{"--weekly", "--notify=bell", "--notify-webhook=https://hooks.example.com/x?a=<b>&c"})
+	t.Cleanup(func() {
+		scheduleFlags.VisitAll(func(f *flag.Flag) { f.Value.Set(f.DefValue) })
+	})
+	plist := filepath.Join(dir, "agent.plist")
+	if _, err := installSchedule(plist, dir, dir); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(plist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "<string>--notify-webhook=https://hooks.example.com/x?a=&lt;b&gt;&amp;c</string>") {
+		t.Errorf("plist:\n%s", data)
+	}
+}
+
+func TestCompareScans(t *testing.T) {
+	then := time.Date(2026, 10, 9, 9, 0, 0, 0, time.Local)
+	prev := &scanSnapshot{Path: "/data", Time: then, Total: 1000, Entries: map[string]int64{
+		"Library": 500, "Movies": 300, "Old": 200,
+	}}
+	cur := scanSnapshot{Path: "/data", Time: then.AddDate(0, 0, 7), Total: 1500, Entries: map[string]int64{
+		"Library": 1100, "Movies": 300, "New": 100,
+	}}
+	g := compareScans(prev, cur)
+	want := []folderChange{{"Library", 600}, {"Old", -200}, {"New", 100}}
+	if len(g.changes) != len(want) {
+		t.Fatalf("changes = %+v, want %+v", g.changes, want)
+	}
+	for i := range want {
+		if g.changes[i] != want[i] {
+			t.Fatalf("changes = %+v, want %+v", g.changes, want)
+		}
+	}
+	if body := g.message().Body; !strings.Contains(body, "+500 B since Oct 9 (Library +600 B, New +100 B)") {
+		t.Errorf("message = %q", body)
+	}
+	var b strings.Builder
+	g.writeReport(&b)
+	if !strings.Contains(b.String(), "Grew:") || !strings.Contains(b.String(), "Shrank:\n      -200 B  Old") {
+		t.Errorf("report:\n%s", b.String())
+	}
+
+	first := compareScans(nil, cur)
+	if body := first.message().Body; !strings.Contains(body, "growth shows after the next scan") {
+		t.Errorf("first message = %q", body)
+	}
+}
@@ -0,0 +1,372 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/output"
+)
+
+// simctlTimeout bounds each simctl call; CoreSimulatorService can take a
+// few seconds to start on a cold boot.
+const simctlTimeout = 20 * time.Second
+
+var (
+	simulatorsFlags      = flag.NewFlagSet("analyze simulators", flag.ExitOnError)
+	simulatorsUnusedDays = simulatorsFlags.Int("unused-days", 90, "call what has not been booted or used in this many days unused")
+	simulatorsJSON       = simulatorsFlags.Bool("json", false, "print the report as JSON")
+)
+
+func init() { simulatorsFlags.Usage = simulatorsUsage }
+
+func simulatorsUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole analyze simulators [flags]
+
+Sizes the iOS Simulator runtimes and devices Xcode installed and the
+Android virtual devices and system images the Android SDK keeps, marks
+the ones not used recently, and prints the command that deletes each
+unused one. Nothing is deleted.
+
+`)
+	simulatorsFlags.PrintDefaults()
+}
+
+// Kinds of emulatorItem, in the order the report lists them.
+const (
+	kindSimRuntime   = "simulator-runtime"
+	kindSimDevice    = "simulator-device"
+	kindAVD          = "android-avd"
+	kindAndroidImage = "android-system-image"
+)
+
+var emulatorKindTitles = map[string]string{
+	kindSimRuntime:   "iOS Simulator runtimes",
+	kindSimDevice:    "iOS Simulator devices",
+	kindAVD:          "Android virtual devices",
+	kindAndroidImage: "Android system images",
+}
+
+// emulatorItem is a simulator runtime or device, an Android virtual device,
+// or an Android system image. LastUsed is zero when it was never booted;
+// Image is the system image an Android virtual device runs.
+type emulatorItem struct {
+	Kind     string    `json:"kind"`
+	Name     string    `json:"name"`
+	ID       string    `json:"id"`
+	Image    string    `json:"system_image,omitempty"`
+	Path     string    `json:"path,omitempty"`
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"last_used,omitzero"`
+	Unused   bool      `json:"unused"`
+	Delete   string    `json:"delete_command,omitempty"`
+}
+
+// runSimulatorsMode implements `mole analyze simulators` and exits on errors.
+func runSimulatorsMode(args []string) {
+	simulatorsFlags.Parse(args)
+	if *simulatorsUnusedDays < 1 {
+		exitcode.Exit("analyze simulators", exitcode.Usage, fmt.Errorf("--unused-days: %d is not positive", *simulatorsUnusedDays))
+	}
+
+	var items []emulatorItem
+	if data, err := simctl("runtime", "list", "-j"); err == nil {
+		items = append(items, parseSimRuntimes(data)...)
+	}
+	if data, err := simctl("list", "devices", "-j"); err == nil {
+		items = append(items, parseSimDevices(data)...)
+	}
+	avds := readAVDs(androidAVDHome())
+	items = append(items, avds...)
+	items = append(items, readSystemImages(androidSDKHome(), avds)...)
+
+	report := newEmulatorReport(items, time.Now(), time.Duration(*simulatorsUnusedDays)*24*time.Hour)
+	out, err := output.Create(output.Path(*outputTo))
+	if err != nil {
+		exitcode.Exit("analyze simulators", exitcode.Failed, err)
+	}
+	if *simulatorsJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		report.write(out, *simulatorsUnusedDays)
+	}
+	if err != nil {
+		out.Abort()
+		exitcode.Exit("analyze simulators", exitcode.Failed, err)
+	}
+	if err := out.Close(); err != nil {
+		exitcode.Exit("analyze simulators", exitcode.Failed, err)
+	}
+}
+
+func simctl(args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), simctlTimeout)
+	defer cancel()
+	return exec.CommandContext(ctx, "xcrun", append([]string{"simctl"}, args...)...).Output()
+}
+
+// parseSimRuntimes reads `xcrun simctl runtime list -j`, the runtimes Xcode
+// downloaded as disk images. Runtimes bundled with Xcode are not listed
+// there and cannot be deleted on their own.
+func parseSimRuntimes(data []byte) []emulatorItem {
+	var runtimes map[string]struct {
+		Identifier        string    `json:"identifier"`
+		Version           string    `json:"version"`
+		Build             string    `json:"build"`
+		RuntimeIdentifier string    `json:"runtimeIdentifier"`
+		Path              string    `json:"path"`
+		SizeBytes         int64     `json:"sizeBytes"`
+		LastUsedAt        time.Time `json:"lastUsedAt"`
+		Deletable         bool      `json:"deletable"`
+	}
+	if json.Unmarshal(data, &runtimes) != nil {
+		return nil
+	}
+	var items []emulatorItem
+	for id, r := range runtimes {
+		item := emulatorItem{
+			Kind:     kindSimRuntime,
+			Name:     simRuntimeName(r.RuntimeIdentifier, r.Version),
+			ID:       cmp.Or(r.Identifier, id),
+			Path:     r.Path,
+			Size:     r.SizeBytes,
+			LastUsed: r.LastUsedAt,
+		}
+		if r.Build != "" {
+			item.Name += " (" + r.Build + ")"
+		}
+		if r.Deletable {
+			item.Delete = "xcrun simctl runtime delete " + item.ID
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// simRuntimeName turns com.apple.CoreSimulator.SimRuntime.iOS-17-0 into
+// "iOS 17.0", preferring version, which carries the patch number.
+func simRuntimeName(identifier, version string) string {
+	platform, number, _ := strings.Cut(identifier[strings.LastIndex(identifier, ".")+1:], "-")
+	if version == "" {
+		version = strings.ReplaceAll(number, "-", ".")
+	}
+	return strings.TrimSpace(platform + " " + version)
+}
+
+// parseSimDevices reads `xcrun simctl list devices -j`. Devices that were
+// never booted have no lastBootedAt.
+func parseSimDevices(data []byte) []emulatorItem {
+	var list struct {
+		Devices map[string][]struct {
+			UDID         string    `json:"udid"`
+			Name         string    `json:"name"`
+			DataPath     string    `json:"dataPath"`
+			DataPathSize int64     `json:"dataPathSize"`
+			LastBootedAt time.Time `json:"lastBootedAt"`
+			IsAvailable  bool      `json:"isAvailable"`
+		} `json:"devices"`
+	}
+	if json.Unmarshal(data, &list) != nil {
+		return nil
+	}
+	var items []emulatorItem
+	for runtime, devices := range list.Devices {
+		for _, d := range devices {
+			item := emulatorItem{
+				Kind:     kindSimDevice,
+				Name:     fmt.Sprintf("%s (%s)", d.Name, simRuntimeName(runtime, "")),
+				ID:       d.UDID,
+				Path:     filepath.Dir(d.DataPath),
+				Size:     d.DataPathSize,
+				LastUsed: d.LastBootedAt,
+				Delete:   "xcrun simctl delete " + d.UDID,
+			}
+			if d.DataPath == "" {
+				item.Path = ""
+			} else if item.Size == 0 {
+				item.Size = folderSize(item.Path)
+			}
+			if !d.IsAvailable {
+				item.Name += ", runtime missing"
+			}
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// androidAVDHome is where the emulator keeps virtual devices, following
+// the variables the Android tools read.
+func androidAVDHome() string {
+	if dir := os.Getenv("ANDROID_AVD_HOME"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("ANDROID_USER_HOME"); dir != "" {
+		return filepath.Join(dir, "avd")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".android", "avd")
+}
+
+// androidSDKHome is the Android SDK, by default where Android Studio
+// installs it.
+func androidSDKHome() string {
+	if dir := cmp.Or(os.Getenv("ANDROID_HOME"), os.Getenv("ANDROID_SDK_ROOT")); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "Library", "Android", "sdk")
+}
+
+// readAVDs reads the NAME.avd folders in dir. The emulator rewrites
+// hardware-qemu.ini on every boot, so its time is the last boot.
+func readAVDs(dir string) []emulatorItem {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var items []emulatorItem
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".avd")
+		if !ok || !e.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		item := emulatorItem{
+			Kind:   kindAVD,
+			Name:   name,
+			ID:     name,
+			Image:  avdSystemImage(filepath.Join(path, "config.ini")),
+			Path:   path,
+			Size:   folderSize(path),
+			Delete: "avdmanager delete avd -n " + name,
+		}
+		if fi, err := os.Stat(filepath.Join(path, "hardware-qemu.ini")); err == nil {
+			item.LastUsed = fi.ModTime()
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// avdSystemImage returns image.sysdir.1 from an AVD's config.ini, such as
+// system-images/android-34/google_apis/arm64-v8a, without the trailing slash.
+func avdSystemImage(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok && strings.TrimSpace(key) == "image.sysdir.1" {
+			return strings.Trim(filepath.ToSlash(strings.TrimSpace(value)), "/")
+		}
+	}
+	return ""
+}
+
+// readSystemImages lists the system-images/API/TAG/ABI folders of the SDK
+// in sdk. An image was last used when the last AVD running it was booted.
+func readSystemImages(sdk string, avds []emulatorItem) []emulatorItem {
+	dirs, _ := filepath.Glob(filepath.Join(sdk, "system-images", "*", "*", "*"))
+	var items []emulatorItem
+	for _, path := range dirs {
+		rel, err := filepath.Rel(sdk, path)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		pkg := strings.ReplaceAll(rel, "/", ";")
+		item := emulatorItem{
+			Kind:   kindAndroidImage,
+			Name:   strings.TrimPrefix(pkg, "system-images;"),
+			ID:     pkg,
+			Path:   path,
+			Size:   folderSize(path),
+			Delete: fmt.Sprintf("sdkmanager --uninstall %q", pkg),
+		}
+		for _, avd := range avds {
+			if avd.Image == rel && avd.LastUsed.After(item.LastUsed) {
+				item.LastUsed = avd.LastUsed
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// emulatorReport is the result of `mole analyze simulators`.
+type emulatorReport struct {
+	Items      []emulatorItem `json:"items"`
+	TotalSize  int64          `json:"total_size"`
+	UnusedSize int64          `json:"unused_size"`
+}
+
+// newEmulatorReport marks the items not used within unusedAfter of now and
+// sorts them by kind, then size.
+func newEmulatorReport(items []emulatorItem, now time.Time, unusedAfter time.Duration) emulatorReport {
+	kinds := []string{kindSimRuntime, kindSimDevice, kindAVD, kindAndroidImage}
+	slices.SortFunc(items, func(a, b emulatorItem) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(kinds, a.Kind), slices.Index(kinds, b.Kind)),
+			cmp.Compare(b.Size, a.Size),
+			strings.Compare(a.Name, b.Name),
+		)
+	})
+	report := emulatorReport{Items: items}
+	for i := range report.Items {
+		item := &report.Items[i]
+		item.Unused = item.LastUsed.IsZero() || now.Sub(item.LastUsed) > unusedAfter
+		report.TotalSize += item.Size
+		if item.Unused {
+			report.UnusedSize += item.Size
+		}
+	}
+	return report
+}
+
+func (r emulatorReport) write(w io.Writer, unusedDays int) {
+	if len(r.Items) == 0 {
+		fmt.Fprintln(w, "No iOS Simulator or Android emulator storage found.")
+		return
+	}
+	fmt.Fprintf(w, "Simulators and emulators: %d items, %s, %s unused for %d days\n",
+		len(r.Items), humanizeBytes(r.TotalSize), humanizeBytes(r.UnusedSize), unusedDays)
+	kind := ""
+	for _, item := range r.Items {
+		if item.Kind != kind {
+			kind = item.Kind
+			fmt.Fprintf(w, "\n%s%s%s\n", colorBold, emulatorKindTitles[kind], colorReset)
+			fmt.Fprintf(w, "%10s  %-10s  %s\n", "SIZE", "LAST USED", "NAME")
+		}
+		used := "never"
+		if !item.LastUsed.IsZero() {
+			used = item.LastUsed.Local().Format("2006-01-02")
+		}
+		name := item.Name
+		if item.Unused {
+			name += colorYellow + ", unused" + colorReset
+		}
+		fmt.Fprintf(w, "%10s  %-10s  %s\n", humanizeBytes(item.Size), used, name)
+		if item.Unused && item.Delete != "" {
+			fmt.Fprintf(w, "%24s%s%s%s\n", "", colorGray, item.Delete, colorReset)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSimulators(t *testing.T) {
+	runtimes := parseSimRuntimes([]byte(`{
+  "3A8C": {
+    "build": "21A328",
+    "deletable": true,
+    "identifier": "3A8C",
+    "lastUsedAt": "2024-01-10T09:00:00Z",
+    "path": "/Library/Developer/CoreSimulator/Images/3A8C.dmg",
+    "runtimeIdentifier": "com.apple.CoreSimulator.SimRuntime.iOS-17-0",
+    "sizeBytes": 7096295018,
+    "version": "17.0.1"
+  }
+}`))
+	if len(runtimes) != 1 {
+		t.Fatalf("runtimes = %+v", runtimes)
+	}
+	if r := runtimes[0]; r.Name != "iOS 17.0.1 (21A328)" || r.Size != 7096295018 || r.Delete != "xcrun simctl runtime delete 3A8C" {
+		t.Errorf("runtime = %+v", r)
+	}
+
+	devices := parseSimDevices([]byte(`{
+  "devices": {
+    "com.apple.CoreSimulator.SimRuntime.iOS-18-1": [
+      {"udid": "AAAA", "name": "iPhone 16", "isAvailable": true, "dataPath": "/sim/AAAA/data", "dataPathSize": 4096, "lastBootedAt": "2026-10-01T10:00:00Z"},
+      {"udid": "BBBB", "name": "iPad Air", "isAvailable": false, "dataPath": "/sim/BBBB/data", "dataPathSize": 2048}
+    ]
+  }
+}`))
+	if len(devices) != 2 {
+		t.Fatalf("devices = %+v", devices)
+	}
+	if d := devices[0]; d.Name != "iPhone 16 (iOS 18.1)" || d.Path != "/sim/AAAA" || d.LastUsed.IsZero() || d.Delete != "xcrun simctl delete AAAA" {
+		t.Errorf("device = %+v", d)
+	}
+	if d := devices[1]; d.Name != "iPad Air (iOS 18.1), runtime missing" || !d.LastUsed.IsZero() {
+		t.Errorf("device = %+v", d)
+	}
+}
+
+func TestReadAndroidEmulators(t *testing.T) {
+	avdHome, sdk := t.TempDir(), t.TempDir()
+	booted := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	for name, image := range map[string]string{"Pixel_7": "system-images/android-34/google_apis/arm64-v8a/", "Old_Tablet": "system-images/android-30/default/arm64-v8a/"} {
+		dir := filepath.Join(avdHome, name+".avd")
+		os.MkdirAll(dir, 0o755)
+		os.WriteFile(filepath.Join(dir, "config.ini"), []byte("hw.lcd.density=420\nimage.sysdir.1 = "+image+"\n"), 0o644)
+		if name == "Pixel_7" {
+			qemu := filepath.Join(dir, "hardware-qemu.ini")
+			os.WriteFile(qemu, []byte("x"), 0o644)
+			os.Chtimes(qemu, booted, booted)
+		}
+	}
+	os.WriteFile(filepath.Join(avdHome, "Pixel_7.ini"), []byte("path=x\n"), 0o644)
+	for _, image := range []string{"android-34/google_apis/arm64-v8a", "android-30/default/arm64-v8a", "android-28/default/x86"} {
+		dir := filepath.Join(sdk, "system-images", image)
+		os.MkdirAll(dir, 0o755)
+		os.WriteFile(filepath.Join(dir, "system.img"), make([]byte, 100), 0o644)
+	}
+
+	avds := readAVDs(avdHome)
+	if len(avds) != 2 {
+		t.Fatalf("avds = %+v", avds)
+	}
+	images := readSystemImages(sdk, avds)
+	if len(images) != 3 {
+		t.Fatalf("images = %+v", images)
+	}
+
+	report := newEmulatorReport(append(avds, images...), booted.Add(24*time.Hour), 90*24*time.Hour)
+	got := map[string]emulatorItem{}
+	for _, item := range report.Items {
+		got[item.Name] = item
+	}
+	if pixel := got["Pixel_7"]; pixel.Unused || !pixel.LastUsed.Equal(booted) || pixel.Image != "system-images/android-34/google_apis/arm64-v8a" {
+		t.Errorf("Pixel_7 = %+v", pixel)
+	}
+	if old := got["Old_Tablet"]; !old.Unused || old.Delete != "avdmanager delete avd -n Old_Tablet" {
+		t.Errorf("Old_Tablet = %+v", old)
+	}
+	if image := got["android-34;google_apis;arm64-v8a"]; image.Unused || image.Size != 100 {
+		t.Errorf("used image = %+v", image)
+	}
+	if image := got["android-28;default;x86"]; !image.Unused || image.Delete != `sdkmanager --uninstall "system-images;android-28;default;x86"` {
+		t.Errorf("unused image = %+v", image)
+	}
+	if report.Items[0].Kind != kindAVD || report.UnusedSize != report.TotalSize-got["Pixel_7"].Size-100 {
+		t.Errorf("report = %+v", report)
+	}
+
+	var buf bytes.Buffer
+	report.write(&buf, 90)
+	out := buf.String()
+	for _, want := range []string{"Android virtual devices", "Android system images", "never", "avdmanager delete avd -n Old_Tablet"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "avdmanager delete avd -n Pixel_7") {
+		t.Errorf("report offers to delete a device in use:\n%s", out)
+	}
+
+	plainColors(t)
+	buf.Reset()
+	report.write(&buf, 90)
+	if strings.Contains(buf.String(), "\x1b") {
+		t.Errorf("plain report has escape codes: %q", buf.String())
+	}
+}
@@ -1,6 +1,6 @@
 //go:build darwin
 
-package main
+package analyze
 
 import (
 	"context"
@@ -9,6 +9,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/tw93/mole/internal/theme"
 )
 
 func skipIfFinderUnavailable(t *testing.T) {
@@ -44,3 +46,11 @@ func skipIfFinderUnavailable(t *testing.T) {
 		t.Skipf("Skipping Finder-dependent test, Finder unavailable: %s", reason)
 	}
 }
+
+// plainColors draws without color for the rest of the test, as a report
+// saved with --output or piped into a file does.
+func plainColors(t *testing.T) {
+	t.Helper()
+	applyTheme(theme.Dark, theme.NoColor)
+	t.Cleanup(func() { applyTheme(theme.Dark, theme.ANSI) })
+}
@@ -1,9 +1,8 @@
 //go:build darwin
 
-package main
+package analyze
 
 import (
-	"fmt"
 	"path/filepath"
 	"slices"
 	"strings"
@@ -11,6 +10,9 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/tw93/mole/internal/i18n"
+	"github.com/tw93/mole/pkg/diskscan"
 )
 
 func (m *model) scheduleOverviewScans() tea.Cmd {
@@ -32,7 +34,7 @@ func (m *model) scheduleOverviewScans() tea.Cmd {
 		m.overviewScanning = false
 		if !hasPendingOverviewEntries(m.entries) {
 			m.sortOverviewEntriesBySize()
-			m.status = "Ready"
+			m.status = i18n.T("Ready")
 		}
 		return nil
 	}
@@ -55,9 +57,9 @@ func (m *model) scheduleOverviewScans() tea.Cmd {
 	if len(pendingIndices) > 0 {
 		firstEntry := m.entries[pendingIndices[0]]
 		if len(pendingIndices) == 1 {
-			m.status = fmt.Sprintf("Scanning %s..., %d left", firstEntry.Name, remaining)
+			m.status = i18n.Tf("Scanning %s..., %d left", firstEntry.Name, remaining)
 		} else {
-			m.status = fmt.Sprintf("Scanning %d directories..., %d left", len(pendingIndices), remaining)
+			m.status = i18n.Tf("Scanning %d directories..., %d left", len(pendingIndices), remaining)
 		}
 	}
 
@@ -74,12 +76,18 @@ func (m model) Init() tea.Cmd {
 
 func (m model) scanCmd(path string) tea.Cmd {
 	return func() tea.Msg {
-		if cached, err := loadCacheFromDisk(path); err == nil {
+		// The cache holds link sizes, not what the links point to.
+		if *followLinks {
+			return startLiveScanCmd(path, m.progress)()
+		}
+		if cached, err := diskscan.LoadCache(path); err == nil {
 			result := scanResult{
-				Entries:    cached.Entries,
-				LargeFiles: cached.LargeFiles,
-				TotalSize:  cached.TotalSize,
-				TotalFiles: cached.TotalFiles,
+				Entries:     cached.Entries,
+				LargeFiles:  cached.LargeFiles,
+				SparseFiles: cached.SparseFiles,
+				Ages:        cached.Ages,
+				TotalSize:   cached.TotalSize,
+				TotalFiles:  cached.TotalFiles,
 			}
 			if cached.NeedsRefresh {
 				return scanResultMsg{path: path, result: result, err: nil, stale: true}
@@ -87,23 +95,25 @@ func (m model) scanCmd(path string) tea.Cmd {
 			return scanResultMsg{path: path, result: result, err: nil}
 		}
 
-		if stale, err := loadStaleCacheFromDisk(path); err == nil {
+		if stale, err := diskscan.LoadStaleCache(path); err == nil {
 			result := scanResult{
-				Entries:    stale.Entries,
-				LargeFiles: stale.LargeFiles,
-				TotalSize:  stale.TotalSize,
-				TotalFiles: stale.TotalFiles,
+				Entries:     stale.Entries,
+				LargeFiles:  stale.LargeFiles,
+				SparseFiles: stale.SparseFiles,
+				Ages:        stale.Ages,
+				TotalSize:   stale.TotalSize,
+				TotalFiles:  stale.TotalFiles,
 			}
 			return scanResultMsg{path: path, result: result, err: nil, stale: true}
 		}
 
-		return startLiveScanCmd(path, m.filesScanned, m.dirsScanned, m.bytesScanned, m.currentPath)()
+		return startLiveScanCmd(path, m.progress)()
 	}
 }
 
 func (m model) scanFreshCmd(path string) tea.Cmd {
 	return func() tea.Msg {
-		return startLiveScanCmd(path, m.filesScanned, m.dirsScanned, m.bytesScanned, m.currentPath)()
+		return startLiveScanCmd(path, m.progress)()
 	}
 }
 
@@ -189,7 +199,7 @@ func (m *model) applyLiveChildSize(entry dirEntry, complete bool, result scanRes
 		m.applyEntryFilter()
 	}
 	m.clampEntrySelection()
-	m.status = fmt.Sprintf("Scanning %s...", displayPath(m.path))
+	m.status = i18n.Tf("Scanning %s...", displayPath(m.path))
 }
 
 func (m *model) finishLiveScan(result scanResult) {
@@ -206,6 +216,8 @@ func (m *model) finishLiveScan(result scanResult) {
 	selectedPath := m.selectedEntryPath()
 	m.entriesAll = filteredEntries
 	m.largeFilesAll = result.LargeFiles
+	m.sparseFiles = result.SparseFiles
+	m.ages = result.Ages
 	m.totalSize = result.TotalSize
 	m.totalFiles = result.TotalFiles
 	m.viewNeedsRefresh = false
@@ -218,19 +230,28 @@ func (m *model) finishLiveScan(result scanResult) {
 		m.selectEntryPath(selectedPath)
 	}
 	m.cache[m.path] = historyEntryFromScanResult(m.path, result, m.cache[m.path], false)
+	// Sizes through links stay out of the cache and the overview, which
+	// hold what the disk itself uses.
+	if !*followLinks {
+		m.storeScan(result)
+	}
+	m.status = i18n.Tf("Scanned %s", humanizeBytes(m.totalSize))
+}
+
+// storeScan saves a finished scan to the overview sizes and the cache.
+func (m *model) storeScan(result scanResult) {
 	if m.totalSize > 0 {
 		if m.overviewSizeCache == nil {
 			m.overviewSizeCache = make(map[string]int64)
 		}
 		m.overviewSizeCache[m.path] = m.totalSize
 		go func(path string, size int64) {
-			_ = storeOverviewSize(path, size)
+			_ = diskscan.StoreSize(path, size)
 		}(m.path, m.totalSize)
 	}
 	go func(path string, scan scanResult) {
-		_ = saveCacheToDisk(path, scan)
+		_ = diskscan.SaveCache(path, scan)
 	}(m.path, result)
-	m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
 }
 
 func (m *model) finishCanceledLiveScan() {
@@ -240,7 +261,7 @@ func (m *model) finishCanceledLiveScan() {
 	m.liveScanEvents = nil
 	m.liveScanningPaths = nil
 	m.autoSortLiveEntries = false
-	m.status = "Scan cancelled"
+	m.status = i18n.T("Scan cancelled")
 }
 
 func (m *model) sortLiveEntriesForActiveMode() {
@@ -249,7 +270,7 @@ func (m *model) sortLiveEntriesForActiveMode() {
 	if m.liveSortMode == liveSortContinuous {
 		selectedPath = m.selectedEntryPath()
 	}
-	sortDirEntriesBySize(m.entriesAll)
+	diskscan.SortEntries(m.entriesAll)
 	m.applyEntryFilter()
 	if m.liveSortMode == liveSortFreezeOnMove {
 		m.selected = 0
@@ -293,14 +314,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.multiSelected = make(map[string]bool)
 			m.largeMultiSelected = make(map[string]bool)
 			if msg.err != nil {
-				m.status = fmt.Sprintf("Failed to delete: %v", msg.err)
+				m.status = i18n.Tf("Failed to delete: %v", msg.err)
 			} else {
 				if msg.path != "" {
 					m.removePathFromView(msg.path)
-					invalidateCache(msg.path)
+					diskscan.InvalidateCache(msg.path)
 				}
-				invalidateCache(m.path)
-				m.status = fmt.Sprintf("Deleted %d items", msg.count)
+				diskscan.InvalidateCache(m.path)
+				m.status = i18n.Tf("Deleted %d items", msg.count)
 
 				// Selective invalidation: only mark current path and ancestors as needing refresh
 				currentPath := m.path
@@ -322,12 +343,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 				m.cancelLiveScan()
 				m.scanning = true
-				atomic.StoreInt64(m.filesScanned, 0)
-				atomic.StoreInt64(m.dirsScanned, 0)
-				atomic.StoreInt64(m.bytesScanned, 0)
-				if m.currentPath != nil {
-					m.currentPath.Store("")
-				}
+				m.progress.Reset()
 				return m, tea.Batch(m.scanCmd(m.path), tickCmd())
 			}
 		}
@@ -344,7 +360,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.scanning = false
 		if msg.err != nil {
-			m.status = fmt.Sprintf("Scan failed: %v", msg.err)
+			m.status = i18n.Tf("Scan failed: %v", msg.err)
 			return m, nil
 		}
 		filteredEntries := filterNonEmptyEntries(msg.result.Entries)
@@ -354,6 +370,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.entries = filteredEntries
 		m.largeFilesAll = msg.result.LargeFiles
 		m.largeFiles = msg.result.LargeFiles
+		m.sparseFiles = msg.result.SparseFiles
+		m.ages = msg.result.Ages
 		m.totalSize = msg.result.TotalSize
 		m.totalFiles = msg.result.TotalFiles
 		m.viewNeedsRefresh = msg.stale
@@ -369,26 +387,21 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			m.overviewSizeCache[m.path] = m.totalSize
 			go func(path string, size int64) {
-				_ = storeOverviewSize(path, size)
+				_ = diskscan.StoreSize(path, size)
 			}(m.path, m.totalSize)
 		}
 
 		if msg.stale {
-			m.status = fmt.Sprintf("Loaded cached data for %s, refreshing...", displayPath(m.path))
+			m.status = i18n.Tf("Loaded cached data for %s, refreshing...", displayPath(m.path))
 			m.scanning = true
 			if m.totalFiles > 0 {
 				m.lastTotalFiles = m.totalFiles
 			}
-			atomic.StoreInt64(m.filesScanned, 0)
-			atomic.StoreInt64(m.dirsScanned, 0)
-			atomic.StoreInt64(m.bytesScanned, 0)
-			if m.currentPath != nil {
-				m.currentPath.Store("")
-			}
+			m.progress.Reset()
 			return m, tea.Batch(m.scanFreshCmd(m.path), tickCmd())
 		}
 
-		m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
+		m.status = i18n.Tf("Scanned %s", humanizeBytes(m.totalSize))
 		return m, nil
 	case liveScanStartMsg:
 		if msg.path != m.path {
@@ -400,10 +413,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.cancelLiveScan()
 		if msg.err != nil {
 			m.scanning = false
-			m.status = fmt.Sprintf("Scan failed: %v", msg.err)
+			m.status = i18n.Tf("Scan failed: %v", msg.err)
 			return m, nil
 		}
 		m.liveScanID = msg.id
+		m.liveScanStarted = time.Now()
 		m.liveScanCancel = msg.cancel
 		m.liveScanEvents = msg.events
 		m.liveScanningPaths = make(map[string]bool, len(msg.scanningPaths))
@@ -417,11 +431,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.entriesAll = slices.Clone(msg.entries)
 		m.largeFilesAll = slices.Clone(msg.largeFiles)
+		m.sparseFiles = nil
+		m.ages = diskscan.Ages{}
 		m.totalSize = msg.totalSize
 		m.totalFiles = msg.totalFiles
 		m.viewNeedsRefresh = false
 		m.scanning = true
-		m.status = fmt.Sprintf("Scanning %s...", displayPath(m.path))
+		m.status = i18n.Tf("Scanning %s...", displayPath(m.path))
 		m.sortLiveEntriesForActiveMode()
 		m.applyLargeFilter()
 		if selectedPath != "" {
@@ -442,9 +458,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, waitLiveScanEventCmd(m.liveScanEvents)
 		case liveScanComplete:
 			m.finishLiveScan(msg.result)
-			return m, nil
+			return m, scanFinishedCmd(m.path, m.totalSize, time.Since(m.liveScanStarted))
 		case liveScanFailed:
-			m.status = fmt.Sprintf("Scan failed: %v", msg.err)
+			m.status = i18n.Tf("Scan failed: %v", msg.err)
 			return m, waitLiveScanEventCmd(m.liveScanEvents)
 		case liveScanCanceled:
 			m.finishCanceledLiveScan()
@@ -476,7 +492,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.totalSize = sumKnownEntrySizes(m.entries)
 
 			if msg.Err != nil {
-				m.status = fmt.Sprintf("Unable to measure %s: %v", displayPath(msg.Path), msg.Err)
+				m.status = i18n.Tf("Unable to measure %s: %v", displayPath(msg.Path), msg.Err)
 			}
 
 			cmd := m.scheduleOverviewScans()
@@ -498,7 +514,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.deleting && m.deleteCount != nil {
 				count := atomic.LoadInt64(m.deleteCount)
 				if count > 0 {
-					m.status = fmt.Sprintf("Moving to Trash... %s items", formatNumber(count))
+					m.status = i18n.Tf("Moving to Trash... %s items", formatNumber(count))
 				}
 			}
 			return m, tickCmd()
@@ -542,20 +558,20 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.deleteTarget = nil
 			if len(pathsToDelete) == 0 {
 				m.deleting = false
-				m.status = "Nothing to delete"
+				m.status = i18n.T("Nothing to delete")
 				return m, nil
 			}
 
 			if len(pathsToDelete) == 1 {
 				targetPath := pathsToDelete[0]
-				m.status = fmt.Sprintf("Deleting %s...", filepath.Base(targetPath))
+				m.status = i18n.Tf("Deleting %s...", filepath.Base(targetPath))
 				return m, tea.Batch(deletePathCmd(targetPath, m.deleteCount), tickCmd())
 			}
 
-			m.status = fmt.Sprintf("Deleting %d items...", len(pathsToDelete))
+			m.status = i18n.Tf("Deleting %d items...", len(pathsToDelete))
 			return m, tea.Batch(deleteMultiplePathsCmd(pathsToDelete, m.deleteCount), tickCmd())
 		case "esc", "q":
-			m.status = "Cancelled"
+			m.status = i18n.T("Cancelled")
 			m.deleteConfirm = false
 			m.deleteTarget = nil
 			return m, nil
@@ -573,6 +589,16 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.entryFiltering {
 		return m.updateEntryFilterInput(msg)
 	}
+	// The age chart covers the list; it only closes or quits.
+	if m.showAges {
+		switch msg.String() {
+		case "q", "Q", "ctrl+c":
+			return m, tea.Quit
+		case "a", "A", "esc", "left", "h", "H", "b", "B":
+			m.showAges = false
+		}
+		return m, nil
+	}
 
 	switch msg.String() {
 	case "q", "Q", "ctrl+c":
@@ -582,7 +608,7 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if m.largeFilter != "" {
 				m.resetLargeFilter()
 				m.clampLargeSelection()
-				m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
+				m.status = i18n.Tf("Scanned %s", humanizeBytes(m.totalSize))
 				return m, nil
 			}
 			m.showLargeFiles = false
@@ -591,7 +617,7 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.entryFilter != "" {
 			m.resetEntryFilter()
 			m.clampEntrySelection()
-			m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
+			m.status = i18n.Tf("Scanned %s", humanizeBytes(m.totalSize))
 			return m, nil
 		}
 		return m.goBack()
@@ -618,7 +644,7 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.showLargeFiles {
 			if m.largeSelected < len(m.largeFiles)-1 {
 				m.largeSelected++
-				viewport := calculateViewport(m.height, true)
+				viewport := m.largeViewport()
 				if m.largeSelected >= m.largeOffset+viewport {
 					m.largeOffset = m.largeSelected - viewport + 1
 				}
@@ -655,7 +681,7 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.inOverviewMode() {
 			// Explicitly invalidate cache for all overview entries to force re-scan
 			for _, entry := range m.entries {
-				invalidateCache(entry.Path)
+				diskscan.InvalidateCache(entry.Path)
 			}
 
 			m.overviewSizeCache = make(map[string]int64)
@@ -669,27 +695,22 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			m.totalSize = 0
 
-			m.status = "Refreshing..."
+			m.status = i18n.T("Refreshing...")
 			m.overviewScanning = true
 			return m, tea.Batch(m.scheduleOverviewScans(), tickCmd())
 		}
 
-		invalidateCacheTree(m.path)
-		m.status = "Refreshing..."
+		diskscan.InvalidateCacheTree(m.path)
+		m.status = i18n.T("Refreshing...")
 		m.scanning = true
 		if m.totalFiles > 0 {
 			m.lastTotalFiles = m.totalFiles
 		}
-		atomic.StoreInt64(m.filesScanned, 0)
-		atomic.StoreInt64(m.dirsScanned, 0)
-		atomic.StoreInt64(m.bytesScanned, 0)
-		if m.currentPath != nil {
-			m.currentPath.Store("")
-		}
+		m.progress.Reset()
 		return m, tea.Batch(m.scanFreshCmd(m.path), tickCmd())
 	case "t", "T":
 		if m.scanning {
-			m.status = "Top files are available after the scan finishes"
+			m.status = i18n.T("Top files are available after the scan finishes")
 			return m, nil
 		}
 		if !m.inOverviewMode() {
@@ -703,8 +724,17 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			} else {
 				m.multiSelected = make(map[string]bool)
 			}
-			m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
+			m.status = i18n.Tf("Scanned %s", humanizeBytes(m.totalSize))
+		}
+	case "a", "A":
+		if m.inOverviewMode() {
+			break
 		}
+		if m.scanning {
+			m.status = i18n.T("Ages are available after the scan finishes")
+			return m, nil
+		}
+		m.showAges = true
 	case "/":
 		if m.inOverviewMode() {
 			break
@@ -712,11 +742,11 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.showLargeFiles {
 			if len(m.largeFilesAll) > 0 {
 				m.largeFiltering = true
-				m.status = "Filter: type to match, Enter to apply, Esc to clear"
+				m.status = i18n.T("Filter: type to match, Enter to apply, Esc to clear")
 			}
 		} else if len(m.entriesAll) > 0 {
 			m.entryFiltering = true
-			m.status = "Filter: type to match, Enter to apply, Esc to clear"
+			m.status = i18n.T("Filter: type to match, Enter to apply, Esc to clear")
 		}
 	case "s", "S":
 		if m.scanning && !m.inOverviewMode() {
@@ -725,7 +755,7 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if m.autoSortLiveEntries {
 				m.sortLiveEntriesForActiveMode()
 			}
-			m.status = fmt.Sprintf("Live sort: %s", liveSortModeLabel(m.liveSortMode))
+			m.status = i18n.Tf("Live sort: %s", liveSortModeLabel(m.liveSortMode))
 		}
 	case "o", "O":
 		// Open selected entries (multi-select aware).
@@ -735,7 +765,7 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				if len(m.largeMultiSelected) > 0 {
 					count := len(m.largeMultiSelected)
 					if count > maxBatchOpen {
-						m.status = fmt.Sprintf("Too many items to open, max %d, selected %d", maxBatchOpen, count)
+						m.status = i18n.Tf("Too many items to open, max %d, selected %d", maxBatchOpen, count)
 						return m, nil
 					}
 					for path := range m.largeMultiSelected {
@@ -743,20 +773,20 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 							_ = safeOpen(p, false)
 						}(path)
 					}
-					m.status = fmt.Sprintf("Opening %d items...", count)
+					m.status = i18n.Tf("Opening %d items...", count)
 				} else {
 					selected := m.largeFiles[m.largeSelected]
 					go func(path string) {
 						_ = safeOpen(path, false)
 					}(selected.Path)
-					m.status = fmt.Sprintf("Opening %s...", selected.Name)
+					m.status = i18n.Tf("Opening %s...", selected.Name)
 				}
 			}
 		} else if len(m.entries) > 0 {
 			if len(m.multiSelected) > 0 {
 				count := len(m.multiSelected)
 				if count > maxBatchOpen {
-					m.status = fmt.Sprintf("Too many items to open, max %d, selected %d", maxBatchOpen, count)
+					m.status = i18n.Tf("Too many items to open, max %d, selected %d", maxBatchOpen, count)
 					return m, nil
 				}
 				for path := range m.multiSelected {
@@ -764,13 +794,13 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 						_ = safeOpen(p, false)
 					}(path)
 				}
-				m.status = fmt.Sprintf("Opening %d items...", count)
+				m.status = i18n.Tf("Opening %d items...", count)
 			} else {
 				selected := m.entries[m.selected]
 				go func(path string) {
 					_ = safeOpen(path, false)
 				}(selected.Path)
-				m.status = fmt.Sprintf("Opening %s...", selected.Name)
+				m.status = i18n.Tf("Opening %s...", selected.Name)
 			}
 		}
 	case "f", "F":
@@ -781,7 +811,7 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				if len(m.largeMultiSelected) > 0 {
 					count := len(m.largeMultiSelected)
 					if count > maxBatchReveal {
-						m.status = fmt.Sprintf("Too many items to reveal, max %d, selected %d", maxBatchReveal, count)
+						m.status = i18n.Tf("Too many items to reveal, max %d, selected %d", maxBatchReveal, count)
 						return m, nil
 					}
 					for path := range m.largeMultiSelected {
@@ -789,20 +819,20 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 							_ = safeOpen(p, true)
 						}(path)
 					}
-					m.status = fmt.Sprintf("Showing %d items in Finder...", count)
+					m.status = i18n.Tf("Showing %d items in Finder...", count)
 				} else {
 					selected := m.largeFiles[m.largeSelected]
 					go func(path string) {
 						_ = safeOpen(path, true)
 					}(selected.Path)
-					m.status = fmt.Sprintf("Showing %s in Finder...", selected.Name)
+					m.status = i18n.Tf("Showing %s in Finder...", selected.Name)
 				}
 			}
 		} else if len(m.entries) > 0 {
 			if len(m.multiSelected) > 0 {
 				count := len(m.multiSelected)
 				if count > maxBatchReveal {
-					m.status = fmt.Sprintf("Too many items to reveal, max %d, selected %d", maxBatchReveal, count)
+					m.status = i18n.Tf("Too many items to reveal, max %d, selected %d", maxBatchReveal, count)
 					return m, nil
 				}
 				for path := range m.multiSelected {
@@ -810,13 +840,13 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 						_ = safeOpen(p, true)
 					}(path)
 				}
-				m.status = fmt.Sprintf("Showing %d items in Finder...", count)
+				m.status = i18n.Tf("Showing %d items in Finder...", count)
 			} else {
 				selected := m.entries[m.selected]
 				go func(path string) {
 					_ = safeOpen(path, true)
 				}(selected.Path)
-				m.status = fmt.Sprintf("Showing %s in Finder...", selected.Name)
+				m.status = i18n.Tf("Showing %s in Finder...", selected.Name)
 			}
 		}
 	case "p", "P":
@@ -827,7 +857,7 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				go func(path string) {
 					_ = safePreview(path)
 				}(selected.Path)
-				m.status = fmt.Sprintf("Previewing %s...", selected.Name)
+				m.status = i18n.Tf("Previewing %s...", selected.Name)
 			}
 		} else if len(m.entries) > 0 {
 			selected := m.entries[m.selected]
@@ -835,12 +865,12 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				go func(path string) {
 					_ = safePreview(path)
 				}(selected.Path)
-				m.status = fmt.Sprintf("Previewing %s...", selected.Name)
+				m.status = i18n.Tf("Previewing %s...", selected.Name)
 			}
 		}
 	case " ":
 		if m.scanning {
-			m.status = "Selection is available after the scan finishes"
+			m.status = i18n.T("Selection is available after the scan finishes")
 			return m, nil
 		}
 		// Toggle multi-select (paths as keys).
@@ -866,9 +896,9 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 							}
 						}
 					}
-					m.status = fmt.Sprintf("%d selected, %s", count, humanizeBytes(totalSize))
+					m.status = i18n.Tf("%d selected, %s", count, humanizeBytes(totalSize))
 				} else {
-					m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
+					m.status = i18n.Tf("Scanned %s", humanizeBytes(m.totalSize))
 				}
 			}
 		} else if len(m.entries) > 0 && !m.inOverviewMode() && m.selected < len(m.entries) {
@@ -892,14 +922,14 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 						}
 					}
 				}
-				m.status = fmt.Sprintf("%d selected, %s", count, humanizeBytes(totalSize))
+				m.status = i18n.Tf("%d selected, %s", count, humanizeBytes(totalSize))
 			} else {
-				m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
+				m.status = i18n.Tf("Scanned %s", humanizeBytes(m.totalSize))
 			}
 		}
 	case "delete", "backspace":
 		if m.scanning {
-			m.status = "Delete is available after the scan finishes"
+			m.status = i18n.T("Delete is available after the scan finishes")
 			return m, nil
 		}
 		if m.showLargeFiles {
@@ -967,14 +997,14 @@ func (m model) updateLargeFilterInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case tea.KeyEsc:
 		m.resetLargeFilter()
 		m.clampLargeSelection()
-		m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
+		m.status = i18n.Tf("Scanned %s", humanizeBytes(m.totalSize))
 		return m, nil
 	case tea.KeyEnter:
 		m.largeFiltering = false
 		if m.largeFilter == "" {
-			m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
+			m.status = i18n.Tf("Scanned %s", humanizeBytes(m.totalSize))
 		} else {
-			m.status = fmt.Sprintf("Filter %q, %d matches", m.largeFilter, len(m.largeFiles))
+			m.status = i18n.Tf("Filter %q, %d matches", m.largeFilter, len(m.largeFiles))
 		}
 		return m, nil
 	case tea.KeyBackspace, tea.KeyDelete:
@@ -1010,14 +1040,14 @@ func (m model) updateEntryFilterInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case tea.KeyEsc:
 		m.resetEntryFilter()
 		m.clampEntrySelection()
-		m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
+		m.status = i18n.Tf("Scanned %s", humanizeBytes(m.totalSize))
 		return m, nil
 	case tea.KeyEnter:
 		m.entryFiltering = false
 		if m.entryFilter == "" {
-			m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
+			m.status = i18n.Tf("Scanned %s", humanizeBytes(m.totalSize))
 		} else {
-			m.status = fmt.Sprintf("Filter %q, %d matches", m.entryFilter, len(m.entries))
+			m.status = i18n.Tf("Filter %q, %d matches", m.entryFilter, len(m.entries))
 		}
 		return m, nil
 	case tea.KeyBackspace, tea.KeyDelete:
@@ -1065,6 +1095,8 @@ func (m model) goBack() (tea.Model, tea.Cmd) {
 	m.entries = last.Entries
 	m.largeFilesAll = last.LargeFiles
 	m.largeFiles = last.LargeFiles
+	m.sparseFiles = last.SparseFiles
+	m.ages = last.Ages
 	m.totalSize = last.TotalSize
 	m.totalFiles = last.TotalFiles
 	m.viewNeedsRefresh = last.NeedsRefresh
@@ -1079,20 +1111,15 @@ func (m model) goBack() (tea.Model, tea.Cmd) {
 		m.selected = 0
 	}
 	if last.NeedsRefresh {
-		m.status = fmt.Sprintf("Loaded cached data for %s, refreshing...", displayPath(m.path))
+		m.status = i18n.Tf("Loaded cached data for %s, refreshing...", displayPath(m.path))
 		m.scanning = true
 		if m.totalFiles > 0 {
 			m.lastTotalFiles = m.totalFiles
 		}
-		atomic.StoreInt64(m.filesScanned, 0)
-		atomic.StoreInt64(m.dirsScanned, 0)
-		atomic.StoreInt64(m.bytesScanned, 0)
-		if m.currentPath != nil {
-			m.currentPath.Store("")
-		}
+		m.progress.Reset()
 		return m, tea.Batch(m.scanFreshCmd(m.path), tickCmd())
 	}
-	m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
+	m.status = i18n.Tf("Scanned %s", humanizeBytes(m.totalSize))
 	m.scanning = false
 	return m, nil
 }
@@ -1108,6 +1135,9 @@ func (m *model) switchToOverviewMode() tea.Cmd {
 	m.resetLargeFilter()
 	m.largeFilesAll = nil
 	m.largeFiles = nil
+	m.sparseFiles = nil
+	m.ages = diskscan.Ages{}
+	m.showAges = false
 	m.largeSelected = 0
 	m.largeOffset = 0
 	m.deleteConfirm = false
@@ -1117,7 +1147,7 @@ func (m *model) switchToOverviewMode() tea.Cmd {
 	m.hydrateOverviewEntries()
 	cmd := m.scheduleOverviewScans()
 	if cmd == nil {
-		m.status = "Ready"
+		m.status = i18n.T("Ready")
 		return nil
 	}
 	return tea.Batch(cmd, tickCmd())
@@ -1150,19 +1180,14 @@ func (m model) enterSelectedDir() (tea.Model, tea.Cmd) {
 		m.path = selected.Path
 		m.selected = 0
 		m.offset = 0
-		m.status = "Scanning..."
+		m.status = i18n.T("Scanning...")
 		m.scanning = true
 		m.isOverview = false
 		m.viewNeedsRefresh = false
 		m.multiSelected = make(map[string]bool)
 		m.largeMultiSelected = make(map[string]bool)
 
-		atomic.StoreInt64(m.filesScanned, 0)
-		atomic.StoreInt64(m.dirsScanned, 0)
-		atomic.StoreInt64(m.bytesScanned, 0)
-		if m.currentPath != nil {
-			m.currentPath.Store("")
-		}
+		m.progress.Reset()
 
 		m.resetLargeFilter()
 		if cached, ok := m.cache[m.path]; ok {
@@ -1170,6 +1195,8 @@ func (m model) enterSelectedDir() (tea.Model, tea.Cmd) {
 			m.entries = m.entriesAll
 			m.largeFilesAll = slices.Clone(cached.LargeFiles)
 			m.largeFiles = m.largeFilesAll
+			m.sparseFiles = slices.Clone(cached.SparseFiles)
+			m.ages = cached.Ages
 			m.totalSize = cached.TotalSize
 			m.totalFiles = cached.TotalFiles
 			m.viewNeedsRefresh = cached.NeedsRefresh
@@ -1180,24 +1207,24 @@ func (m model) enterSelectedDir() (tea.Model, tea.Cmd) {
 			m.clampEntrySelection()
 			m.clampLargeSelection()
 			if cached.NeedsRefresh {
-				m.status = fmt.Sprintf("Loaded cached data for %s, refreshing...", displayPath(m.path))
+				m.status = i18n.Tf("Loaded cached data for %s, refreshing...", displayPath(m.path))
 				m.scanning = true
 				if m.totalFiles > 0 {
 					m.lastTotalFiles = m.totalFiles
 				}
 				return m, tea.Batch(m.scanFreshCmd(m.path), tickCmd())
 			}
-			m.status = fmt.Sprintf("Cached view for %s", displayPath(m.path))
+			m.status = i18n.Tf("Cached view for %s", displayPath(m.path))
 			m.scanning = false
 			return m, nil
 		}
 		m.lastTotalFiles = 0
-		if total, err := peekCacheTotalFiles(m.path); err == nil && total > 0 {
+		if total, err := diskscan.CachedFileCount(m.path); err == nil && total > 0 {
 			m.lastTotalFiles = total
 		}
 		return m, tea.Batch(m.scanCmd(m.path), tickCmd())
 	}
-	m.status = fmt.Sprintf("File: %s, %s", selected.Name, humanizeBytes(selected.Size))
+	m.status = i18n.Tf("File: %s, %s", selected.Name, humanizeBytes(selected.Size))
 	return m, nil
 }
 
@@ -1,11 +1,15 @@
 //go:build darwin
 
-package main
+package analyze
 
 import (
 	"fmt"
 	"strings"
 	"sync/atomic"
+
+	"github.com/tw93/mole/internal/hyperlink"
+	"github.com/tw93/mole/internal/i18n"
+	"github.com/tw93/mole/pkg/diskscan"
 )
 
 // View renders the TUI.
@@ -23,30 +27,33 @@ func (m model) View() string {
 	if m.inOverviewMode() {
 		freeLabel := ""
 		if m.diskFree > 0 {
-			freeLabel = fmt.Sprintf("  %s(%s free)%s", colorGray, humanizeBytes(m.diskFree), colorReset)
+			freeLabel = fmt.Sprintf("  %s(%s)%s", colorGray, i18n.Tf("%s free", humanizeBytes(m.diskFree)), colorReset)
 		}
-		fmt.Fprintf(&b, "%sAnalyze Disk%s%s\n", colorPurpleBold, colorReset, freeLabel)
+		fmt.Fprintf(&b, "%s%s%s%s\n", colorPurpleBold, i18n.T("Analyze Disk"), colorReset, freeLabel)
 		if m.overviewScanning {
 			if allOverviewEntriesPending(m.entries) {
-				fmt.Fprintf(&b, "%sSelect a location to explore:%s  ", colorGray, colorReset)
-				fmt.Fprintf(&b, "%s%s%s%s Analyzing disk usage...\n\n",
-					colorCyan, colorBold, spinnerFrames[m.spinner], colorReset)
+				fmt.Fprintf(&b, "%s%s%s  ", colorGray, i18n.T("Select a location to explore:"), colorReset)
+				fmt.Fprintf(&b, "%s%s%s%s %s\n\n",
+					colorCyan, colorBold, spinnerFrames[m.spinner], colorReset, i18n.T("Analyzing disk usage..."))
 			} else {
-				fmt.Fprintf(&b, "%sSelect a location to explore:%s  ", colorGray, colorReset)
+				fmt.Fprintf(&b, "%s%s%s  ", colorGray, i18n.T("Select a location to explore:"), colorReset)
 				fmt.Fprintf(&b, "%s%s%s%s %s\n\n", colorCyan, colorBold, spinnerFrames[m.spinner], colorReset, m.status)
 			}
 		} else {
 			if hasPendingOverviewEntries(m.entries) {
-				fmt.Fprintf(&b, "%sSelect a location to explore:%s  ", colorGray, colorReset)
+				fmt.Fprintf(&b, "%s%s%s  ", colorGray, i18n.T("Select a location to explore:"), colorReset)
 				fmt.Fprintf(&b, "%s%s%s%s %s\n\n", colorCyan, colorBold, spinnerFrames[m.spinner], colorReset, m.status)
 			} else {
-				fmt.Fprintf(&b, "%sSelect a location to explore:%s\n\n", colorGray, colorReset)
+				fmt.Fprintf(&b, "%s%s%s\n\n", colorGray, i18n.T("Select a location to explore:"), colorReset)
 			}
 		}
 	} else {
-		fmt.Fprintf(&b, "%sAnalyze Disk%s  %s%s%s", colorPurpleBold, colorReset, colorGray, displayPath(m.path), colorReset)
+		fmt.Fprintf(&b, "%s%s%s  %s%s%s", colorPurpleBold, i18n.T("Analyze Disk"), colorReset, colorGray, displayPath(m.path), colorReset)
 		if !m.scanning || m.totalSize > 0 {
-			fmt.Fprintf(&b, "  |  Total: %s", humanizeBytes(m.totalSize))
+			fmt.Fprintf(&b, "  |  %s", i18n.Tf("Total: %s", humanizeBytes(m.totalSize)))
+		}
+		if scanVolume != nil {
+			fmt.Fprintf(&b, "\n%s%s%s", colorGray, volumeLabel(scanVolume), colorReset)
 		}
 		fmt.Fprintf(&b, "\n\n")
 	}
@@ -57,11 +64,11 @@ func (m model) View() string {
 			count = atomic.LoadInt64(m.deleteCount)
 		}
 
-		fmt.Fprintf(&b, "%s%s%s%s Deleting: %s%s items%s removed, please wait...\n",
+		fmt.Fprintf(&b, "%s%s%s%s %s\n",
 			colorCyan, colorBold,
 			spinnerFrames[m.spinner],
 			colorReset,
-			colorYellow, formatNumber(count), colorReset)
+			i18n.Tf("Deleting: %s items removed, please wait...", colorYellow+formatNumber(count)+colorReset))
 
 		return b.String()
 	}
@@ -83,52 +90,52 @@ func (m model) View() string {
 			progressPrefix = fmt.Sprintf(" %s%.0f%%%s", colorCyan, percent, colorReset)
 		}
 
-		fmt.Fprintf(&b, "%s%s%s%s Scanning%s: %s%s files%s, %s%s dirs%s, %s%s%s\n",
+		fmt.Fprintf(&b, "%s%s%s%s %s%s: %s\n",
 			colorCyan, colorBold,
 			spinnerFrames[m.spinner],
 			colorReset,
-			progressPrefix,
-			colorYellow, formatNumber(filesScanned), colorReset,
-			colorYellow, formatNumber(dirsScanned), colorReset,
-			colorGreen, humanizeBytes(bytesScanned), colorReset)
-
-		if m.currentPath != nil {
-			currentPath, _ := m.currentPath.Load().(string)
-			if currentPath != "" {
-				shortPath := displayPath(currentPath)
-				shortPath = truncateMiddle(shortPath, 50)
-				fmt.Fprintf(&b, "%s%s%s\n", colorGray, shortPath, colorReset)
-			}
+			i18n.T("Scanning"), progressPrefix,
+			i18n.Tf("%s files, %s dirs, %s",
+				colorYellow+formatNumber(filesScanned)+colorReset,
+				colorYellow+formatNumber(dirsScanned)+colorReset,
+				colorGreen+humanizeBytes(bytesScanned)+colorReset))
+
+		if currentPath := m.progressPath(); currentPath != "" {
+			shortPath := displayPath(currentPath)
+			shortPath = truncateMiddle(shortPath, 50)
+			fmt.Fprintf(&b, "%s%s%s\n", colorGray, shortPath, colorReset)
 		}
 
 		if !showingCachedView && !showingLiveScanView {
 			return b.String()
 		}
 		if showingCachedView {
-			fmt.Fprintf(&b, "%sShowing cached results while refreshing...%s\n\n", colorGray, colorReset)
+			fmt.Fprintf(&b, "%s%s%s\n\n", colorGray, i18n.T("Showing cached results while refreshing..."), colorReset)
 		} else {
 			fmt.Fprintln(&b)
 		}
 	}
 
-	if m.showLargeFiles {
+	if m.showAges {
+		m.writeAges(&b)
+	} else if m.showLargeFiles {
 		if m.largeFiltering || m.largeFilter != "" {
 			cursor := ""
 			if m.largeFiltering {
 				cursor = "▌"
 			}
-			fmt.Fprintf(&b, "  %sFilter:%s %s%s  %s(%d matches)%s\n\n",
-				colorCyan, colorReset, m.largeFilter, cursor,
-				colorGray, len(m.largeFiles), colorReset)
+			fmt.Fprintf(&b, "  %s%s%s %s%s  %s(%s)%s\n\n",
+				colorCyan, i18n.T("Filter:"), colorReset, m.largeFilter, cursor,
+				colorGray, i18n.Tf("%d matches", len(m.largeFiles)), colorReset)
 		}
 		if len(m.largeFiles) == 0 {
 			if m.largeFilter != "" {
-				fmt.Fprintf(&b, "  No matches for %q\n", m.largeFilter)
+				fmt.Fprintf(&b, "  %s\n", i18n.Tf("No matches for %q", m.largeFilter))
 			} else {
-				fmt.Fprintln(&b, "  No large files found")
+				fmt.Fprintln(&b, "  "+i18n.T("No large files found"))
 			}
 		} else {
-			viewport := calculateViewport(m.height, true)
+			viewport := m.largeViewport()
 			start := max(m.largeOffset, 0)
 			end := min(start+viewport, len(m.largeFiles))
 			maxLargeSize := maxLargeFileSize(m.largeFiles)
@@ -137,7 +144,7 @@ func (m model) View() string {
 				file := m.largeFiles[idx]
 				shortPath := displayPath(file.Path)
 				shortPath = truncateMiddle(shortPath, nameWidth)
-				paddedPath := padName(shortPath, nameWidth)
+				paddedPath := linkName(file.Path, shortPath, nameWidth)
 				entryPrefix := "   "
 				nameColor := ""
 				sizeColor := colorGray
@@ -164,21 +171,22 @@ func (m model) View() string {
 					entryPrefix, selectIcon, numColor, idx+1, colorReset, bar, nameColor, paddedPath, colorReset, sizeColor, size, colorReset)
 			}
 		}
+		m.writeSparseFiles(&b)
 	} else {
 		if !m.inOverviewMode() && (m.entryFiltering || m.entryFilter != "") {
 			cursor := ""
 			if m.entryFiltering {
 				cursor = "▌"
 			}
-			fmt.Fprintf(&b, "  %sFilter:%s %s%s  %s(%d matches)%s\n\n",
-				colorCyan, colorReset, m.entryFilter, cursor,
-				colorGray, len(m.entries), colorReset)
+			fmt.Fprintf(&b, "  %s%s%s %s%s  %s(%s)%s\n\n",
+				colorCyan, i18n.T("Filter:"), colorReset, m.entryFilter, cursor,
+				colorGray, i18n.Tf("%d matches", len(m.entries)), colorReset)
 		}
 		if len(m.entries) == 0 {
 			if !m.inOverviewMode() && m.entryFilter != "" {
-				fmt.Fprintf(&b, "  No matches for %q\n", m.entryFilter)
+				fmt.Fprintf(&b, "  %s\n", i18n.Tf("No matches for %q", m.entryFilter))
 			} else {
-				fmt.Fprintln(&b, "  Empty directory")
+				fmt.Fprintln(&b, "  "+i18n.T("Empty directory"))
 			}
 		} else {
 			if m.inOverviewMode() {
@@ -218,7 +226,7 @@ func (m model) View() string {
 					}
 					entryPrefix := "   "
 					name := trimNameWithWidth(entry.Name, nameWidth)
-					paddedName := padName(name, nameWidth)
+					paddedName := linkName(entry.Path, name, nameWidth)
 					nameSegment := paddedName
 					numColor := ""
 					percentColor := ""
@@ -264,7 +272,7 @@ func (m model) View() string {
 						icon = "📁"
 					}
 					name := trimNameWithWidth(entry.Name, nameWidth)
-					paddedName := padName(name, nameWidth)
+					paddedName := linkName(entry.Path, name, nameWidth)
 
 					sizeValue := max(entry.Size, 0)
 					percent := 0.0
@@ -332,41 +340,43 @@ func (m model) View() string {
 	fmt.Fprintln(&b)
 	if m.inOverviewMode() {
 		if len(m.history) > 0 {
-			fmt.Fprintf(&b, "%s↑↓←→ | Enter | R Refresh | O Open | P Preview | F File | Esc Back | Q/Ctrl+C Quit%s\n", colorGray, colorReset)
+			fmt.Fprintf(&b, "%s%s%s\n", colorGray, i18n.T("↑↓←→ | Enter | R Refresh | O Open | P Preview | F File | Esc Back | Q/Ctrl+C Quit"), colorReset)
 		} else {
-			fmt.Fprintf(&b, "%s↑↓→ | Enter | R Refresh | O Open | P Preview | F File | Esc/Q Quit%s\n", colorGray, colorReset)
+			fmt.Fprintf(&b, "%s%s%s\n", colorGray, i18n.T("↑↓→ | Enter | R Refresh | O Open | P Preview | F File | Esc/Q Quit"), colorReset)
 		}
+	} else if m.showAges {
+		fmt.Fprintf(&b, "%s%s%s\n", colorGray, i18n.T("A/Esc Back | Q/Ctrl+C Quit"), colorReset)
 	} else if m.showLargeFiles {
 		if m.largeFiltering {
-			fmt.Fprintf(&b, "%sType to filter  |  Enter Apply  |  Esc Clear  |  Ctrl+C Quit%s\n", colorGray, colorReset)
+			fmt.Fprintf(&b, "%s%s%s\n", colorGray, i18n.T("Type to filter  |  Enter Apply  |  Esc Clear  |  Ctrl+C Quit"), colorReset)
 		} else if m.largeFilter != "" {
-			fmt.Fprintf(&b, "%s↑↓← | Space Select | / Edit | Esc Clear filter | O Open | P Preview | F File | ⌫ Del | Q Quit%s\n", colorGray, colorReset)
+			fmt.Fprintf(&b, "%s%s%s\n", colorGray, i18n.T("↑↓← | Space Select | / Edit | Esc Clear filter | O Open | P Preview | F File | ⌫ Del | Q Quit"), colorReset)
 		} else {
 			selectCount := len(m.largeMultiSelected)
 			if selectCount > 0 {
-				fmt.Fprintf(&b, "%s↑↓← | Space Select | / Filter | R Refresh | O Open | P Preview | F File | ⌫ Del %d | Esc Back | Q/Ctrl+C Quit%s\n", colorGray, selectCount, colorReset)
+				fmt.Fprintf(&b, "%s%s%s\n", colorGray, i18n.Tf("↑↓← | Space Select | / Filter | R Refresh | O Open | P Preview | F File | ⌫ Del %d | Esc Back | Q/Ctrl+C Quit", selectCount), colorReset)
 			} else {
-				fmt.Fprintf(&b, "%s↑↓← | Space Select | / Filter | R Refresh | O Open | P Preview | F File | ⌫ Del | Esc Back | Q/Ctrl+C Quit%s\n", colorGray, colorReset)
+				fmt.Fprintf(&b, "%s%s%s\n", colorGray, i18n.T("↑↓← | Space Select | / Filter | R Refresh | O Open | P Preview | F File | ⌫ Del | Esc Back | Q/Ctrl+C Quit"), colorReset)
 			}
 		}
 	} else if m.entryFiltering {
-		fmt.Fprintf(&b, "%sType to filter  |  Enter Apply  |  Esc Clear  |  Ctrl+C Quit%s\n", colorGray, colorReset)
+		fmt.Fprintf(&b, "%s%s%s\n", colorGray, i18n.T("Type to filter  |  Enter Apply  |  Esc Clear  |  Ctrl+C Quit"), colorReset)
 	} else if m.entryFilter != "" {
-		fmt.Fprintf(&b, "%s↑↓←→ | Enter | Space Select | / Edit | Esc Clear filter | O Open | P Preview | F File | ⌫ Del | Q Quit%s\n", colorGray, colorReset)
+		fmt.Fprintf(&b, "%s%s%s\n", colorGray, i18n.T("↑↓←→ | Enter | Space Select | / Edit | Esc Clear filter | O Open | P Preview | F File | ⌫ Del | Q Quit"), colorReset)
 	} else {
 		largeFileCount := len(m.largeFiles)
 		selectCount := len(m.multiSelected)
 		if selectCount > 0 {
 			if largeFileCount > 0 {
-				fmt.Fprintf(&b, "%s↑↓←→ | Space Select | Enter | / Filter | R Refresh | O Open | P Preview | F File | ⌫ Del %d | T Top %d | Esc Back | Q/Ctrl+C Quit%s\n", colorGray, selectCount, largeFileCount, colorReset)
+				fmt.Fprintf(&b, "%s%s%s\n", colorGray, i18n.Tf("↑↓←→ | Space Select | Enter | / Filter | R Refresh | O Open | P Preview | F File | ⌫ Del %d | T Top %d | A Ages | Esc Back | Q/Ctrl+C Quit", selectCount, largeFileCount), colorReset)
 			} else {
-				fmt.Fprintf(&b, "%s↑↓←→ | Space Select | Enter | / Filter | R Refresh | O Open | P Preview | F File | ⌫ Del %d | Esc Back | Q/Ctrl+C Quit%s\n", colorGray, selectCount, colorReset)
+				fmt.Fprintf(&b, "%s%s%s\n", colorGray, i18n.Tf("↑↓←→ | Space Select | Enter | / Filter | R Refresh | O Open | P Preview | F File | ⌫ Del %d | Esc Back | Q/Ctrl+C Quit", selectCount), colorReset)
 			}
 		} else {
 			if largeFileCount > 0 {
-				fmt.Fprintf(&b, "%s↑↓←→ | Space Select | Enter | / Filter | R Refresh | O Open | P Preview | F File | ⌫ Del | T Top %d | Esc Back | Q/Ctrl+C Quit%s\n", colorGray, largeFileCount, colorReset)
+				fmt.Fprintf(&b, "%s%s%s\n", colorGray, i18n.Tf("↑↓←→ | Space Select | Enter | / Filter | R Refresh | O Open | P Preview | F File | ⌫ Del | T Top %d | A Ages | Esc Back | Q/Ctrl+C Quit", largeFileCount), colorReset)
 			} else {
-				fmt.Fprintf(&b, "%s↑↓←→ | Space Select | Enter | / Filter | R Refresh | O Open | P Preview | F File | ⌫ Del | Esc Back | Q/Ctrl+C Quit%s\n", colorGray, colorReset)
+				fmt.Fprintf(&b, "%s%s%s\n", colorGray, i18n.T("↑↓←→ | Space Select | Enter | / Filter | R Refresh | O Open | P Preview | F File | ⌫ Del | Esc Back | Q/Ctrl+C Quit"), colorReset)
 			}
 		}
 	}
@@ -397,15 +407,15 @@ func (m model) View() string {
 		}
 
 		if deleteCount > 1 {
-			fmt.Fprintf(&b, "%sDelete:%s %d items, %s  %sPress Enter to confirm  |  ESC cancel%s\n",
-				colorRed, colorReset,
-				deleteCount, humanizeBytes(totalDeleteSize),
-				colorGray, colorReset)
+			fmt.Fprintf(&b, "%s%s%s %s  %s%s%s\n",
+				colorRed, i18n.T("Delete:"), colorReset,
+				i18n.Tf("%d items, %s", deleteCount, humanizeBytes(totalDeleteSize)),
+				colorGray, i18n.T("Press Enter to confirm  |  ESC cancel"), colorReset)
 		} else {
-			fmt.Fprintf(&b, "%sDelete:%s %s, %s  %sPress Enter to confirm  |  ESC cancel%s\n",
-				colorRed, colorReset,
+			fmt.Fprintf(&b, "%s%s%s %s, %s  %s%s%s\n",
+				colorRed, i18n.T("Delete:"), colorReset,
 				m.deleteTarget.Name, humanizeBytes(m.deleteTarget.Size),
-				colorGray, colorReset)
+				colorGray, i18n.T("Press Enter to confirm  |  ESC cancel"), colorReset)
 		}
 	}
 	return b.String()
@@ -420,6 +430,65 @@ func allOverviewEntriesPending(entries []dirEntry) bool {
 	return true
 }
 
+// ageLabels name the buckets of diskscan.Ages.
+var ageLabels = [len(diskscan.Ages{})]string{"Under 30 days", "30 to 90 days", "90 days to 1 year", "1 to 3 years", "Over 3 years"}
+
+// writeAges charts the scanned bytes by how long ago they were modified.
+// Folders du sized are not in any bucket; their bytes are listed apart.
+func (m model) writeAges(b *strings.Builder) {
+	fmt.Fprintf(b, "  %s%s%s\n\n", colorCyan, i18n.T("Bytes by last modified"), colorReset)
+	total := m.ages.Total()
+	if total <= 0 {
+		fmt.Fprintln(b, "  "+i18n.T("No file ages in this scan"))
+		return
+	}
+	var most int64 = 1
+	for _, n := range m.ages {
+		most = max(most, n)
+	}
+	for i, n := range m.ages {
+		percent := float64(n) * 100 / float64(total)
+		fmt.Fprintf(b, "  %s %s  %10s  %s%5.1f%%%s\n",
+			padName(i18n.T(ageLabels[i]), 20), coloredProgressBar(n, most, percent), humanizeBytes(n), colorGray, percent, colorReset)
+	}
+	if rest := m.totalSize - total; rest > 0 {
+		fmt.Fprintf(b, "\n  %s%s%s\n", colorGray, i18n.Tf("%s in folders sized without listing files, such as node_modules", humanizeBytes(rest)), colorReset)
+	}
+}
+
+// sparseRows is how many sparse files the Top-files view lists under the
+// top files.
+func (m model) sparseRows() int {
+	return min(len(m.sparseFiles), maxSparseRows)
+}
+
+// largeViewport is the rows left for the top files once the sparse files
+// have theirs.
+func (m model) largeViewport() int {
+	viewport := calculateViewport(m.height, true)
+	if rows := m.sparseRows(); rows > 0 {
+		viewport = max(viewport-rows-2, 1)
+	}
+	return viewport
+}
+
+// writeSparseFiles lists the sparse files with what each takes on disk,
+// which the sizes above count, next to the length the file claims.
+func (m model) writeSparseFiles(b *strings.Builder) {
+	rows := m.sparseRows()
+	if rows == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n  %s%s%s  %s%s%s\n", colorCyan, i18n.T("Sparse files"), colorReset,
+		colorGray, i18n.T("on disk / length"), colorReset)
+	nameWidth := calculateNameWidth(m.width)
+	for _, file := range m.sparseFiles[:rows] {
+		shortPath := truncateMiddle(displayPath(file.Path), nameWidth)
+		fmt.Fprintf(b, "     %10s / %-10s  📄 %s\n",
+			humanizeBytes(file.Size), humanizeBytes(file.Logical), hyperlink.File(file.Path, shortPath))
+	}
+}
+
 func maxLargeFileSize(files []fileEntry) int64 {
 	var maxSize int64 = 1
 	for _, file := range files {
@@ -454,9 +523,15 @@ func sizeColorForPercent(percent float64) string {
 }
 
 func entryHintLabel(entry dirEntry) string {
+	if baseline, ok := unexpectedEntry(entry); ok {
+		return fmt.Sprintf("%s⚠ %s%s", colorRed, baselineHint(baseline, entry.Size), colorReset)
+	}
 	if entry.IsDir && isCleanableDir(entry.Path) {
 		return fmt.Sprintf("%s🧹%s", colorYellow, colorReset)
 	}
+	if hint := insightHint(entry.Path); hint != "" {
+		return fmt.Sprintf("%s%s%s", colorGray, hint, colorReset)
+	}
 	if unusedTime := formatUnusedTime(entry.LastAccess); unusedTime != "" {
 		return fmt.Sprintf("%s%s%s", colorGray, unusedTime, colorReset)
 	}
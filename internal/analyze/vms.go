@@ -0,0 +1,299 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tw93/mole/internal/debuglog"
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/output"
+)
+
+// vmSpotlightTimeout bounds the Spotlight search for VM bundles and disk
+// images across every indexed volume.
+const vmSpotlightTimeout = 30 * time.Second
+
+var (
+	vmsFlags      = flag.NewFlagSet("analyze vms", flag.ExitOnError)
+	vmsUnusedDays = vmsFlags.Int("unused-days", 90, "flag virtual machines not run in this many days")
+	vmsJSON       = vmsFlags.Bool("json", false, "print the inventory as JSON")
+)
+
+func init() { vmsFlags.Usage = vmsUsage }
+
+func vmsUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole analyze vms [flags]
+
+Finds Parallels, UTM, and VMware Fusion virtual machines, QEMU disk
+images, Vagrant boxes, and Docker Desktop's disk, in their usual folders
+and wherever Spotlight finds them, and lists how much space each was
+given next to how much it takes on disk, with when it last ran. Nothing
+is changed.
+
+`)
+	vmsFlags.PrintDefaults()
+}
+
+// vmKinds maps the extension of a VM bundle or disk image to what made it.
+var vmKinds = map[string]string{
+	".pvm":      "Parallels",
+	".utm":      "UTM",
+	".vmwarevm": "VMware",
+	".qcow2":    "QEMU",
+	".qcow":     "QEMU",
+}
+
+const (
+	kindVagrantBox = "Vagrant box"
+	kindDocker     = "Docker Desktop"
+)
+
+// vmImage is a virtual machine or disk image. Provisioned counts disks at
+// the size the guest sees, and OnDisk the blocks they take, which is less
+// for sparse and growing disks. LastRun is the last write to any of its
+// files; it is zero for Vagrant boxes, which are templates and never run.
+type vmImage struct {
+	Kind        string    `json:"kind"`
+	Name        string    `json:"name"`
+	Path        string    `json:"path"`
+	Provisioned int64     `json:"provisioned"`
+	OnDisk      int64     `json:"on_disk"`
+	LastRun     time.Time `json:"last_run,omitzero"`
+	Unused      bool      `json:"unused"`
+}
+
+// runVMsMode implements `mole analyze vms` and exits on errors.
+func runVMsMode(args []string) {
+	vmsFlags.Parse(args)
+	if *vmsUnusedDays < 1 {
+		exitcode.Exit("analyze vms", exitcode.Usage, fmt.Errorf("--unused-days: %d is not positive", *vmsUnusedDays))
+	}
+
+	home, _ := os.UserHomeDir()
+	paths := knownVMPaths(home)
+	paths = append(paths, spotlightVMPaths()...)
+	var vms []vmImage
+	for _, path := range dedupeVMPaths(paths) {
+		vms = append(vms, measureVM(path, vmKinds[strings.ToLower(filepath.Ext(path))]))
+	}
+	docker := filepath.Join(home, "Library", "Containers", "com.docker.docker", "Data", "vms", "0", "data", "Docker.raw")
+	if _, err := os.Stat(docker); err == nil {
+		vm := measureVM(docker, kindDocker)
+		vm.Name = "Docker.raw"
+		vms = append(vms, vm)
+	}
+	vms = append(vms, readVagrantBoxes(cmp.Or(os.Getenv("VAGRANT_HOME"), filepath.Join(home, ".vagrant.d")))...)
+	report := newVMsReport(vms, time.Now(), time.Duration(*vmsUnusedDays)*24*time.Hour)
+
+	out, err := output.Create(output.Path(*outputTo))
+	if err != nil {
+		exitcode.Exit("analyze vms", exitcode.Failed, err)
+	}
+	if *vmsJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		report.write(out, *vmsUnusedDays)
+	}
+	if err != nil {
+		out.Abort()
+		exitcode.Exit("analyze vms", exitcode.Failed, err)
+	}
+	if err := out.Close(); err != nil {
+		exitcode.Exit("analyze vms", exitcode.Failed, err)
+	}
+}
+
+// knownVMPaths lists the bundles and disk images in the folders the VM
+// apps use by default; Spotlight does not index UTM's container.
+func knownVMPaths(home string) []string {
+	var paths []string
+	for _, dir := range []string{
+		filepath.Join(home, "Parallels"),
+		filepath.Join(home, "Virtual Machines.localized"),
+		filepath.Join(home, "Virtual Machines"),
+		filepath.Join(home, "Library", "Containers", "com.utmapp.UTM", "Data", "Documents"),
+	} {
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			if vmKinds[strings.ToLower(filepath.Ext(e.Name()))] != "" {
+				paths = append(paths, filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+	return paths
+}
+
+func spotlightVMPaths() []string {
+	var terms []string
+	for ext := range vmKinds {
+		terms = append(terms, fmt.Sprintf(`kMDItemFSName == "*%s"c`, ext))
+	}
+	slices.Sort(terms)
+	query := strings.Join(terms, " || ")
+	ctx, cancel := context.WithTimeout(context.Background(), vmSpotlightTimeout)
+	defer cancel()
+	start := time.Now()
+	out, err := exec.CommandContext(ctx, "mdfind", query).Output()
+	debuglog.Command("mdfind", []string{query}, time.Since(start), err)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(string(out)), "\n")
+}
+
+// dedupeVMPaths sorts paths and drops repeats and anything inside another
+// path, such as the qcow2 disks in a UTM bundle.
+func dedupeVMPaths(paths []string) []string {
+	var kept []string
+	for _, path := range paths {
+		if path != "" && vmKinds[strings.ToLower(filepath.Ext(path))] != "" {
+			kept = append(kept, filepath.Clean(path))
+		}
+	}
+	slices.Sort(kept)
+	kept = slices.Compact(kept)
+	return slices.DeleteFunc(kept, func(path string) bool {
+		return slices.ContainsFunc(kept, func(other string) bool {
+			return strings.HasPrefix(path, other+string(filepath.Separator))
+		})
+	})
+}
+
+// measureVM walks a bundle, or stats a single disk image.
+func measureVM(path, kind string) vmImage {
+	vm := vmImage{Kind: kind, Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), Path: path}
+	if kind == "QEMU" {
+		vm.Name = filepath.Base(path)
+	}
+	filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			return nil
+		}
+		size, onDisk := info.Size(), info.Size()
+		if ext := strings.ToLower(filepath.Ext(file)); ext == ".qcow2" || ext == ".qcow" {
+			size = max(size, qcowVirtualSize(file))
+		}
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			onDisk = stat.Blocks * 512
+		}
+		vm.Provisioned += size
+		vm.OnDisk += onDisk
+		if info.ModTime().After(vm.LastRun) {
+			vm.LastRun = info.ModTime()
+		}
+		return nil
+	})
+	return vm
+}
+
+// qcowVirtualSize reads the disk size the guest sees from a QEMU image's
+// header: the magic "QFI\xfb", then the size as a big-endian uint64 at
+// byte 24.
+func qcowVirtualSize(path string) int64 {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	header := make([]byte, 32)
+	if _, err := io.ReadFull(f, header); err != nil || !bytes.HasPrefix(header, []byte("QFI\xfb")) {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(header[24:]))
+}
+
+// readVagrantBoxes lists each version of each box in the Vagrant home's
+// boxes/NAME/VERSION folders. Box names have "/" written as
+// "-VAGRANTSLASH-".
+func readVagrantBoxes(vagrantHome string) []vmImage {
+	versions, _ := filepath.Glob(filepath.Join(vagrantHome, "boxes", "*", "*"))
+	var boxes []vmImage
+	for _, path := range versions {
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			continue
+		}
+		name := strings.ReplaceAll(filepath.Base(filepath.Dir(path)), "-VAGRANTSLASH-", "/")
+		box := measureVM(path, kindVagrantBox)
+		box.Name, box.LastRun = name+" "+filepath.Base(path), time.Time{}
+		boxes = append(boxes, box)
+	}
+	return boxes
+}
+
+// vmsReport is the result of `mole analyze vms`.
+type vmsReport struct {
+	VMs         []vmImage `json:"vms"`
+	Provisioned int64     `json:"provisioned"`
+	OnDisk      int64     `json:"on_disk"`
+	UnusedSize  int64     `json:"unused_on_disk"`
+}
+
+// newVMsReport flags the VMs not run within unusedAfter of now and sorts
+// them by the space they take.
+func newVMsReport(vms []vmImage, now time.Time, unusedAfter time.Duration) vmsReport {
+	slices.SortFunc(vms, func(a, b vmImage) int {
+		return cmp.Or(cmp.Compare(b.OnDisk, a.OnDisk), strings.Compare(a.Name, b.Name))
+	})
+	report := vmsReport{VMs: vms}
+	for i := range report.VMs {
+		vm := &report.VMs[i]
+		vm.Unused = vm.Kind != kindVagrantBox && now.Sub(vm.LastRun) > unusedAfter
+		report.Provisioned += vm.Provisioned
+		report.OnDisk += vm.OnDisk
+		if vm.Unused {
+			report.UnusedSize += vm.OnDisk
+		}
+	}
+	return report
+}
+
+func (r vmsReport) write(w io.Writer, unusedDays int) {
+	if len(r.VMs) == 0 {
+		fmt.Fprintln(w, "No virtual machines or disk images found.")
+		return
+	}
+	fmt.Fprintf(w, "Virtual machines and images: %d, %s provisioned, %s on disk\n",
+		len(r.VMs), humanizeBytes(r.Provisioned), humanizeBytes(r.OnDisk))
+	fmt.Fprintf(w, "\n%11s  %10s  %-10s  %-14s  %s\n", "PROVISIONED", "ON DISK", "LAST RUN", "KIND", "NAME")
+	unused := 0
+	for _, vm := range r.VMs {
+		run := "-"
+		if !vm.LastRun.IsZero() {
+			run = vm.LastRun.Local().Format("2006-01-02")
+		}
+		name := displayPath(vm.Path)
+		if vm.Kind == kindVagrantBox {
+			name = vm.Name
+		}
+		if vm.Unused {
+			unused++
+			name += fmt.Sprintf("%s, not run in %d days%s", colorYellow, unusedDays, colorReset)
+		}
+		fmt.Fprintf(w, "%11s  %10s  %-10s  %-14s  %s\n", humanizeBytes(vm.Provisioned), humanizeBytes(vm.OnDisk), run, vm.Kind, name)
+	}
+	if unused > 0 {
+		fmt.Fprintf(w, "\n%d not run in %d days take %s on disk.\n", unused, unusedDays, humanizeBytes(r.UnusedSize))
+	}
+}
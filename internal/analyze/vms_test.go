@@ -0,0 +1,95 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeQcow(t *testing.T, path string, virtual uint64) {
+	t.Helper()
+	header := make([]byte, 64)
+	copy(header, "QFI\xfb")
+	binary.BigEndian.PutUint64(header[24:], virtual)
+	os.MkdirAll(filepath.Dir(path), 0o755)
+	if err := os.WriteFile(path, header, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDedupeVMPaths(t *testing.T) {
+	got := dedupeVMPaths([]string{
+		"/Users/me/Library/Containers/com.utmapp.UTM/Data/Documents/Linux.utm",
+		"/Users/me/Library/Containers/com.utmapp.UTM/Data/Documents/Linux.utm/Data/disk.qcow2",
+		"/Users/me/Parallels/Windows 11.pvm/",
+		"/Users/me/Parallels/Windows 11.pvm",
+		"/Users/me/images/alpine.QCOW2",
+		"/Users/me/notes.txt",
+		"",
+	})
+	want := "/Users/me/Library/Containers/com.utmapp.UTM/Data/Documents/Linux.utm,/Users/me/Parallels/Windows 11.pvm,/Users/me/images/alpine.QCOW2"
+	if strings.Join(got, ",") != want {
+		t.Errorf("paths = %v", got)
+	}
+}
+
+func TestMeasureVMs(t *testing.T) {
+	home := t.TempDir()
+	utm := filepath.Join(home, "Library", "Containers", "com.utmapp.UTM", "Data", "Documents", "Linux.utm")
+	writeQcow(t, filepath.Join(utm, "Data", "disk.qcow2"), 64<<30)
+	os.WriteFile(filepath.Join(utm, "config.plist"), []byte("<plist/>"), 0o644)
+	old := time.Now().AddDate(0, -6, 0)
+	for _, file := range []string{filepath.Join(utm, "Data", "disk.qcow2"), filepath.Join(utm, "config.plist")} {
+		os.Chtimes(file, old, old)
+	}
+	pvm := filepath.Join(home, "Parallels", "Windows 11.pvm")
+	os.MkdirAll(pvm, 0o755)
+	os.WriteFile(filepath.Join(pvm, "config.pvs"), make([]byte, 4096), 0o644)
+
+	paths := knownVMPaths(home)
+	if len(paths) != 2 {
+		t.Fatalf("known paths = %v", paths)
+	}
+	var vms []vmImage
+	for _, path := range dedupeVMPaths(paths) {
+		vms = append(vms, measureVM(path, vmKinds[strings.ToLower(filepath.Ext(path))]))
+	}
+	box := filepath.Join(home, ".vagrant.d", "boxes", "ubuntu-VAGRANTSLASH-jammy64", "20240101.0.0", "virtualbox")
+	os.MkdirAll(box, 0o755)
+	os.WriteFile(filepath.Join(box, "box.vmdk"), make([]byte, 1024), 0o644)
+	vms = append(vms, readVagrantBoxes(filepath.Join(home, ".vagrant.d"))...)
+
+	report := newVMsReport(vms, time.Now(), 90*24*time.Hour)
+	byKind := map[string]vmImage{}
+	for _, vm := range report.VMs {
+		byKind[vm.Kind] = vm
+	}
+	linux, windows, ubuntu := byKind["UTM"], byKind["Parallels"], byKind[kindVagrantBox]
+	if linux.Name != "Linux" || linux.Provisioned < 64<<30 || linux.OnDisk >= 1<<20 || !linux.Unused {
+		t.Errorf("utm = %+v", linux)
+	}
+	if windows.Name != "Windows 11" || windows.Unused || windows.Provisioned != 4096 {
+		t.Errorf("parallels = %+v", windows)
+	}
+	if ubuntu.Name != "ubuntu/jammy64 20240101.0.0" || ubuntu.Unused || !ubuntu.LastRun.IsZero() {
+		t.Errorf("vagrant = %+v", ubuntu)
+	}
+	if report.UnusedSize != linux.OnDisk {
+		t.Errorf("unused = %d, want %d", report.UnusedSize, linux.OnDisk)
+	}
+
+	var buf bytes.Buffer
+	report.write(&buf, 90)
+	out := buf.String()
+	for _, want := range []string{"3, ", "PROVISIONED", "Linux.utm", "not run in 90 days", "ubuntu/jammy64", "1 not run in 90 days"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q:\n%s", want, out)
+		}
+	}
+}
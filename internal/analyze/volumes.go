@@ -0,0 +1,231 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tw93/mole/internal/config"
+	"github.com/tw93/mole/internal/debuglog"
+	"github.com/tw93/mole/internal/i18n"
+	"github.com/tw93/mole/pkg/diskscan"
+)
+
+// externalPreset is how analyze scans an external volume. USB disks and
+// network shares seek slowly and fall behind when many walkers compete for
+// them, so a scan there runs a few at a time; a flag on the command line,
+// or a setting remembered for the volume, beats these.
+var externalPreset = map[string]string{
+	"workers":      "2",
+	"dir-workers":  "2",
+	"fold-workers": "1",
+}
+
+// volumeSettings are the flags remembered per volume, in the config file's
+// [volumes.<uuid>] table.
+var volumeSettings = []string{"workers", "dir-workers", "fold-workers", "exclude"}
+
+// volumeSystemDirs are the folders macOS keeps at the top of a volume for
+// the Spotlight index and the file system event log. A scan of an
+// external volume skips them, and reports their sizes instead.
+var volumeSystemDirs = []string{".Spotlight-V100", ".fseventsd"}
+
+// scanVolume is the external volume the scan target is on, or nil.
+var scanVolume *volumeProfile
+
+// useSpotlight is false when the scan target is on an external volume,
+// which Spotlight often does not index.
+var useSpotlight = true
+
+// volumeProfile describes the external volume a scan runs on, for the
+// header and the JSON report.
+type volumeProfile struct {
+	Name       string       `json:"name"`
+	UUID       string       `json:"uuid,omitempty"`
+	MountPoint string       `json:"mount_point"`
+	Skipped    []skippedDir `json:"skipped,omitempty"`
+}
+
+type skippedDir struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// mountPoint returns the top of the volume path is on: the last folder
+// walking up from path that is on the same device.
+func mountPoint(path string) string {
+	dev := func(p string) (uint64, bool) {
+		info, err := os.Stat(p)
+		if err != nil {
+			return 0, false
+		}
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			return 0, false
+		}
+		return uint64(stat.Dev), true
+	}
+	path = filepath.Clean(path)
+	want, ok := dev(path)
+	if !ok {
+		return ""
+	}
+	for path != "/" {
+		parent := filepath.Dir(path)
+		if got, ok := dev(parent); !ok || got != want {
+			return path
+		}
+		path = parent
+	}
+	return path
+}
+
+// readVolumeInfo asks diskutil for the volume mounted at mount and returns
+// its name and UUID and whether it is on an internal disk. Network shares
+// have no diskutil entry and count as external.
+func readVolumeInfo(mount string) (name, uuid string, internal bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), openCommandTimeout)
+	defer cancel()
+	args := []string{"info", "-plist", mount}
+	start := time.Now()
+	out, err := exec.CommandContext(ctx, "diskutil", args...).Output()
+	debuglog.Command("diskutil", args, time.Since(start), err)
+	if err != nil {
+		return filepath.Base(mount), "", false
+	}
+	return parseVolumeInfo(out, mount)
+}
+
+func parseVolumeInfo(data []byte, mount string) (name, uuid string, internal bool) {
+	values, err := parsePlistDict(data)
+	if err != nil {
+		return filepath.Base(mount), "", false
+	}
+	name = values["VolumeName"]
+	if name == "" {
+		name = filepath.Base(mount)
+	}
+	return name, strings.ToUpper(values["VolumeUUID"]), values["Internal"] == "true"
+}
+
+// applyVolumeProfile checks whether path is on an external volume under
+// /Volumes and, if so, tunes the scan for it and sets scanVolume: Spotlight
+// off, the volume's remembered settings or the preset for the flags the
+// command line left alone, and the volume's system folders skipped and
+// measured. explicit names the flags the command line set.
+func applyVolumeProfile(path string, explicit map[string]bool) error {
+	mount := mountPoint(path)
+	if !strings.HasPrefix(mount, "/Volumes/") {
+		return nil
+	}
+	name, uuid, internal := readVolumeInfo(mount)
+	if internal {
+		return nil
+	}
+
+	file, err := config.Load(config.Path())
+	if err != nil {
+		return err
+	}
+	values := volumeFlagValues(file, uuid, explicit)
+	for _, flagName := range volumeSettings {
+		value, ok := values[flagName]
+		if !ok {
+			continue
+		}
+		if err := Flags.Set(flagName, value); err != nil {
+			return fmt.Errorf("%s: volumes.%s.%s: %w", config.Path(), uuid, flagName, err)
+		}
+		if flagName == "exclude" {
+			excludeDirs = append(excludeDirs, splitNames(value)...)
+		}
+	}
+
+	useSpotlight = false
+	excludeDirs = append(excludeDirs, volumeSystemDirs...)
+	scanVolume = &volumeProfile{Name: name, UUID: uuid, MountPoint: mount}
+	for _, dir := range volumeSystemDirs {
+		dirPath := filepath.Join(mount, dir)
+		if _, err := os.Lstat(dirPath); err != nil {
+			continue
+		}
+		// Both folders are readable only as root; without it they count
+		// as empty.
+		size, _ := diskscan.Measure(dirPath)
+		scanVolume.Skipped = append(scanVolume.Skipped, skippedDir{Name: dir, Path: dirPath, Size: size})
+	}
+	return nil
+}
+
+// volumeFlagValues returns the values to give the volume flags the command
+// line left alone: the ones remembered for the volume, then the preset.
+func volumeFlagValues(file config.File, uuid string, explicit map[string]bool) map[string]string {
+	values := map[string]string{}
+	for _, name := range volumeSettings {
+		if explicit[name] {
+			continue
+		}
+		if value, ok := file.Get("volumes." + uuid + "." + name); uuid != "" && ok {
+			values[name] = value
+		} else if preset, ok := externalPreset[name]; ok {
+			values[name] = preset
+		}
+	}
+	return values
+}
+
+// rememberVolumeFlags writes the volume flags the command line set to the
+// volume's table in the config file, so the next scan of it uses them.
+func rememberVolumeFlags(uuid string, explicit map[string]bool) error {
+	path := config.Path()
+	if path == "" {
+		return nil
+	}
+	for _, name := range volumeSettings {
+		if !explicit[name] {
+			continue
+		}
+		f := Flags.Lookup(name)
+		literal, err := config.Literal(f, f.Value.String())
+		if err != nil {
+			return err
+		}
+		if err := config.Set(path, "volumes."+uuid+"."+name, literal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// volumeLabel is the header line under the path when it is on an external
+// volume.
+func volumeLabel(v *volumeProfile) string {
+	label := i18n.Tf("External volume %s, scanned without Spotlight", v.Name)
+	if len(v.Skipped) == 0 {
+		return label
+	}
+	parts := make([]string, 0, len(v.Skipped))
+	for _, dir := range v.Skipped {
+		parts = append(parts, dir.Name+" "+humanizeBytes(dir.Size))
+	}
+	return label + "  |  " + i18n.Tf("Skipped %s", strings.Join(parts, ", "))
+}
+
+// splitNames splits a comma-separated list such as --exclude's.
+func splitNames(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
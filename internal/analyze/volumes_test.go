@@ -0,0 +1,96 @@
+//go:build darwin
+
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tw93/mole/internal/config"
+)
+
+const diskutilInfo = `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>Internal</key>
+	<false/>
+	<key>MediaType</key>
+	<string>Generic</string>
+	<key>Partitions</key>
+	<array><dict><key>Internal</key><true/></dict></array>
+	<key>TotalSize</key>
+	<integer>2000398934016</integer>
+	<key>VolumeName</key>
+	<string>Archive</string>
+	<key>VolumeUUID</key>
+	<string>5f1d2c3b-9a8e-4d7c-b6a5-0f1e2d3c4b5a</string>
+</dict>
+</plist>`
+
+func TestParseVolumeInfo(t *testing.T) {
+	name, uuid, internal := parseVolumeInfo([]byte(diskutilInfo), "/Volumes/Archive 1")
+	if name != "Archive" || uuid != "5F1D2C3B-9A8E-4D7C-B6A5-0F1E2D3C4B5A" || internal {
+		t.Errorf("volume = %q, %q, internal %v", name, uuid, internal)
+	}
+	name, uuid, internal = parseVolumeInfo([]byte("not a plist"), "/Volumes/share")
+	if name != "share" || uuid != "" || internal {
+		t.Errorf("bad plist: volume = %q, %q, internal %v", name, uuid, internal)
+	}
+}
+
+func TestMountPoint(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "a", "b")
+	os.MkdirAll(dir, 0o755)
+	mount := mountPoint(dir)
+	if mount == "" || mount != "/" && !strings.HasPrefix(dir, mount+"/") && dir != mount {
+		t.Errorf("mountPoint(%q) = %q", dir, mount)
+	}
+	if got := mountPoint(filepath.Join(dir, "missing")); got != "" {
+		t.Errorf("missing path: mountPoint = %q", got)
+	}
+}
+
+func TestVolumeFlagValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	os.WriteFile(path, []byte(`workers = 8
+
+[volumes.5F1D2C3B-9A8E-4D7C-B6A5-0F1E2D3C4B5A]
+dir-workers = 4
+exclude = ["Backups.backupdb"]
+`), 0o644)
+	file, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := volumeFlagValues(file, "5F1D2C3B-9A8E-4D7C-B6A5-0F1E2D3C4B5A", map[string]bool{"fold-workers": true})
+	want := map[string]string{"workers": "2", "dir-workers": "4", "exclude": "Backups.backupdb"}
+	if len(got) != len(want) {
+		t.Errorf("values = %v, want %v", got, want)
+	}
+	for name, value := range want {
+		if got[name] != value {
+			t.Errorf("%s = %q, want %q", name, got[name], value)
+		}
+	}
+
+	// A volume without a UUID, such as a network share, gets the preset.
+	got = volumeFlagValues(file, "", nil)
+	if len(got) != 3 || got["workers"] != "2" || got["dir-workers"] != "2" || got["fold-workers"] != "1" {
+		t.Errorf("no uuid: values = %v", got)
+	}
+}
+
+func TestVolumeLabel(t *testing.T) {
+	label := volumeLabel(&volumeProfile{Name: "Archive", MountPoint: "/Volumes/Archive", Skipped: []skippedDir{
+		{Name: ".Spotlight-V100", Size: 3 << 20},
+		{Name: ".fseventsd", Size: 0},
+	}})
+	for _, want := range []string{"External volume Archive", "Skipped .Spotlight-V100 ", ".fseventsd "} {
+		if !strings.Contains(label, want) {
+			t.Errorf("label lacks %q: %s", want, label)
+		}
+	}
+}
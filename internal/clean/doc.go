@@ -0,0 +1,6 @@
+// Package clean is the rules engine behind `mole clean`: declarative YAML
+// rules that name cache paths, how old an entry must be before it goes,
+// how safe removing it is, and the tool's own cleanup command when it has
+// one. Bundled defaults ship in defaults.yaml; ~/.config/mole/clean.yaml
+// adds rules or overrides them by name.
+package clean
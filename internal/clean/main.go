@@ -0,0 +1,287 @@
+package clean
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/tw93/mole/internal/config"
+	"github.com/tw93/mole/internal/debuglog"
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/output"
+	"github.com/tw93/mole/internal/units"
+)
+
+// Flags are clean's command-line flags. The mole root command parses them
+// from the arguments after "clean" and lists them for shell completion.
+var Flags = flag.NewFlagSet("clean", flag.ExitOnError)
+
+var (
+	dryRun     = Flags.Bool("dry-run", false, "print the plan without removing anything")
+	assumeYes  = Flags.Bool("yes", false, "clean without asking, as needed when stdin is not a terminal")
+	permanent  = Flags.Bool("permanent", false, "delete matched paths instead of moving them to Trash")
+	safetyFlag = Flags.String("safety", safetyModerate, "run rules up to this safety level: safe, moderate, or risky")
+	onlyFlag   = Flags.String("only", "", "comma-separated rule names to run instead of every rule")
+	listRules  = Flags.Bool("list", false, "list the loaded rules and exit")
+	jsonOutput = Flags.Bool("json", false, "print the plan or report as JSON")
+	outputTo   = output.AddFlag(Flags)
+	unitsFlag  = Flags.String("units", "", "byte units: si (GB), binary (GiB), or auto (defaults to $MO_UNITS, then ~/.config/mole/units, then auto)")
+	debugLog   = Flags.Bool("debug", false, "log the cleanup commands run and how long they took")
+
+	configProfile = Flags.String("profile", "", "apply the named profile from ~/.config/mole/config.toml (defaults to $MO_PROFILE)")
+	errorFormat   = exitcode.AddFlag(Flags)
+)
+
+func init() { Flags.Usage = usage }
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole clean [flags]
+
+Runs the cleanup rules: the bundled defaults, plus ~/.config/mole/clean.yaml,
+where a rule with a bundled rule's name replaces it and "disabled: true"
+turns it off. Without --yes the plan is shown and confirmed first.
+
+Matched paths go through the same checks as the rest of mo clean: the
+whitelist (mo clean --whitelist) and Mole's protected paths keep them, and
+what is removed moves to Trash and is logged to operations.log.
+
+`)
+	Flags.PrintDefaults()
+}
+
+// Exit codes: a rule that failed is a partial clean, bad flags or rules
+// are a usage error, and anything that stops the run early is a failure.
+const (
+	exitOK      = exitcode.OK
+	exitPartial = exitcode.Partial
+	exitUsage   = exitcode.Usage
+	exitFailed  = exitcode.Failed
+)
+
+// Main runs clean with args and exits the process when it is done.
+func Main(args []string) {
+	os.Exit(run(args))
+}
+
+func run(args []string) int {
+	Flags.Parse(args)
+	if err := exitcode.SetFormat(*errorFormat); err != nil {
+		return exitcode.Report("clean", exitUsage, err)
+	}
+	if err := config.Apply(Flags, "clean", *configProfile); err != nil {
+		return exitcode.Report("clean", exitUsage, err)
+	}
+	if safetyRank(*safetyFlag) == len(safetyLevels) {
+		return exitcode.Report("clean", exitUsage, fmt.Errorf("--safety: %q must be safe, moderate, or risky", *safetyFlag))
+	}
+	system, err := units.ResolveSystem(*unitsFlag)
+	if err != nil {
+		return exitcode.Report("clean", exitUsage, fmt.Errorf("--units: %w", err))
+	}
+	logCloser, err := debuglog.Setup(debuglog.Requested(*debugLog), "")
+	if err != nil {
+		return exitcode.Report("clean", exitUsage, err)
+	}
+	defer logCloser.Close()
+
+	rules, err := loadRules(userRulesPath())
+	if err != nil {
+		return exitcode.Report("clean", exitUsage, err)
+	}
+	if *listRules {
+		return emit(func(w io.Writer) int { return printRules(w, rules) })
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return exitcode.Report("clean", exitFailed, fmt.Errorf("mole clean: %w", err))
+	}
+	opts := planOptions{
+		home:      home,
+		goos:      runtime.GOOS,
+		now:       time.Now(),
+		maxSafety: *safetyFlag,
+		only:      splitNames(*onlyFlag),
+		lookPath:  exec.LookPath,
+		running:   processRunning,
+		vet: func(paths []string) (map[string]string, error) {
+			return runShell(context.Background(), "check", paths, *permanent)
+		},
+		preview: previewCommand,
+	}
+	for _, name := range opts.only {
+		if !hasRule(rules, name) {
+			return exitcode.Report("clean", exitUsage, fmt.Errorf("--only: no rule named %q; see mole clean --list", name))
+		}
+	}
+	format := bytesFormatter(system)
+	plan := buildPlan(rules, opts)
+
+	if *dryRun {
+		if *jsonOutput {
+			return emit(func(w io.Writer) int { return writeJSON(w, plan) })
+		}
+		return emit(func(w io.Writer) int {
+			writePlan(w, plan, format)
+			fmt.Fprintf(w, "\nDry run: would free %s. Run without --dry-run to clean.\n", format(plan.Bytes))
+			return exitOK
+		})
+	}
+	if !*assumeYes {
+		if *jsonOutput || !isatty.IsTerminal(os.Stdin.Fd()) {
+			return exitcode.Report("clean", exitUsage, errors.New("mole clean: pass --yes to clean without a terminal, or --dry-run to see the plan"))
+		}
+		writePlan(os.Stdout, plan, format)
+		if !confirm(os.Stdin, os.Stdout, fmt.Sprintf("\nFree %s? [y/N] ", format(plan.Bytes))) {
+			fmt.Println("Nothing removed.")
+			return exitOK
+		}
+	}
+
+	report := execute(context.Background(), plan, home)
+	code := exitOK
+	if report.failed() {
+		code = exitPartial
+	}
+	written := emit(func(w io.Writer) int {
+		if *jsonOutput {
+			return writeJSON(w, report)
+		}
+		writeReport(w, report, format)
+		return exitOK
+	})
+	if written != exitOK {
+		return exitFailed
+	}
+	return code
+}
+
+// emit runs write against --output, or stdout without it. The plan shown
+// before the confirmation prompt always goes to the terminal.
+func emit(write func(w io.Writer) int) int {
+	out, err := output.Create(output.Path(*outputTo))
+	if err != nil {
+		return exitcode.Report("clean", exitFailed, err)
+	}
+	code := write(out)
+	if code == exitFailed {
+		out.Abort()
+		return code
+	}
+	if err := out.Close(); err != nil {
+		return exitcode.Report("clean", exitFailed, err)
+	}
+	return code
+}
+
+func splitNames(s string) []string {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func hasRule(rules []rule, name string) bool {
+	for _, r := range rules {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// bytesFormatter follows analyze: SI unless --units asks for binary.
+func bytesFormatter(system units.System) func(int64) string {
+	if system == units.Binary {
+		return func(n int64) string { return units.BytesIEC(uint64(max(n, 0))) }
+	}
+	return units.BytesSI
+}
+
+func confirm(in io.Reader, out io.Writer, prompt string) bool {
+	fmt.Fprint(out, prompt)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func writeJSON(w io.Writer, v any) int {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return exitcode.Report("clean", exitFailed, fmt.Errorf("error encoding JSON: %w", err))
+	}
+	fmt.Fprintln(w, string(out))
+	return exitOK
+}
+
+func printRules(w io.Writer, rules []rule) int {
+	for _, r := range rules {
+		action := "remove"
+		if len(r.Command) > 0 {
+			action = strings.Join(r.Command, " ")
+		} else if r.OlderThan != "" {
+			action += " after " + r.OlderThan
+		}
+		platforms := "all"
+		if len(r.Platforms) > 0 {
+			platforms = strings.Join(r.Platforms, ",")
+		}
+		fmt.Fprintf(w, "%-30s %-9s %-7s %s\n", r.Name, r.Safety, platforms, action)
+	}
+	return exitOK
+}
+
+// writePlan prints one line per rule, what would run first.
+func writePlan(w io.Writer, plan cleanPlan, format func(int64) string) {
+	for _, e := range plan.Rules {
+		if e.Skipped != "" {
+			continue
+		}
+		detail := fmt.Sprintf("%d items", len(e.Items))
+		switch {
+		case len(e.Command) > 0:
+			detail = strings.Join(e.Command, " ")
+		case len(e.Items) == 1:
+			detail = "1 item"
+		}
+		if len(e.Kept) > 0 {
+			detail += fmt.Sprintf(", %d kept by the whitelist or protected paths", len(e.Kept))
+		}
+		fmt.Fprintf(w, "%-9s %-30s %10s  %s\n", e.Safety, e.Rule, format(e.Bytes), detail)
+		for _, line := range e.Preview {
+			fmt.Fprintf(w, "%-9s   %s\n", "", line)
+		}
+	}
+	for _, e := range plan.Rules {
+		if e.Skipped != "" {
+			fmt.Fprintf(w, "%-9s %-30s %10s  %s\n", "skip", e.Rule, "", e.Skipped)
+		}
+	}
+}
+
+func writeReport(w io.Writer, report cleanReport, format func(int64) string) {
+	for _, r := range report.Rules {
+		if r.Error != "" {
+			fmt.Fprintf(w, "%-6s %-30s %10s  %s\n", "FAIL", r.Rule, format(r.Freed), r.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%-6s %-30s %10s\n", "OK", r.Rule, format(r.Freed))
+	}
+	fmt.Fprintf(w, "\nFreed %s.\n", format(report.Freed))
+	if !*permanent && report.removed() > 0 {
+		fmt.Fprintln(w, "Removed paths are in the Trash; empty it to get the space back.")
+	}
+}
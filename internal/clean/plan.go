@@ -0,0 +1,272 @@
+package clean
+
+import (
+	"cmp"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// protectedPaths are home-relative trees no rule may reach, whatever its
+// glob says: user documents, synced and sandboxed app data, and secrets.
+var protectedPaths = []string{
+	"Desktop",
+	"Documents",
+	"Downloads",
+	"Library/Application Support/MobileSync",
+	"Library/Containers",
+	"Library/Group Containers",
+	"Library/Keychains",
+	"Library/Mobile Documents",
+	".gnupg",
+	".ssh",
+}
+
+// planItem is one path a deletion rule would remove.
+type planItem struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+}
+
+// keptItem is a matched path the shell layer's whitelist or protection
+// rules keep.
+type keptItem struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// planEntry is what one rule would do. Skipped says why it will not run.
+type planEntry struct {
+	Rule        string     `json:"rule"`
+	Description string     `json:"description,omitempty"`
+	Safety      string     `json:"safety"`
+	Command     []string   `json:"command,omitempty"`
+	Preview     []string   `json:"preview,omitempty"`
+	Items       []planItem `json:"items,omitempty"`
+	Kept        []keptItem `json:"kept,omitempty"`
+	Bytes       int64      `json:"bytes"`
+	Skipped     string     `json:"skipped,omitempty"`
+}
+
+type cleanPlan struct {
+	Rules []planEntry `json:"rules"`
+	Bytes int64       `json:"bytes"`
+}
+
+// planOptions is what buildPlan needs from the host, swappable in tests.
+type planOptions struct {
+	home      string
+	goos      string
+	now       time.Time
+	maxSafety string
+	only      []string // rule names; empty runs every rule
+	lookPath  func(string) (string, error)
+	running   func(string) bool // nil treats every process as stopped
+	// vet returns the shell layer's verdict for each path a deletion rule
+	// matched; nil keeps them all.
+	vet func(paths []string) (map[string]string, error)
+	// preview runs a command rule's dry-run command and returns its lines;
+	// nil skips previews.
+	preview func(command []string) ([]string, error)
+}
+
+// buildPlan matches every rule for this platform against the disk without
+// changing anything.
+func buildPlan(rules []rule, opts planOptions) cleanPlan {
+	var plan cleanPlan
+	for _, r := range rules {
+		if !r.runsOn(opts.goos) || len(opts.only) > 0 && !slices.Contains(opts.only, r.Name) {
+			continue
+		}
+		entry := planEntry{Rule: r.Name, Description: r.Description, Safety: r.Safety, Command: r.Command}
+		process := runningProcess(r, opts)
+		switch {
+		case safetyRank(r.Safety) > safetyRank(opts.maxSafety):
+			entry.Skipped = "above --safety " + opts.maxSafety
+		case process != "":
+			entry.Skipped = process + " is running"
+		case len(r.Command) > 0 && !found(opts.lookPath, r.Command[0]):
+			entry.Skipped = r.Command[0] + " not installed"
+		default:
+			items, err := matchRule(r, opts)
+			if err != nil {
+				entry.Skipped = err.Error()
+				break
+			}
+			if len(r.Command) == 0 {
+				if items, entry.Kept, err = vetItems(items, opts); err != nil {
+					entry.Skipped = err.Error()
+					break
+				}
+			} else if len(r.Preview) > 0 && opts.preview != nil {
+				if entry.Preview, err = opts.preview(r.Preview); err != nil {
+					entry.Skipped = fmt.Sprintf("%s: %v", strings.Join(r.Preview, " "), err)
+					break
+				}
+				if len(entry.Preview) == 0 {
+					entry.Skipped = "nothing to clean"
+					break
+				}
+			}
+			entry.Items = items
+			for _, item := range items {
+				entry.Bytes += item.Bytes
+			}
+			if len(items) == 0 && len(r.Command) == 0 {
+				entry.Skipped = "nothing to clean"
+			}
+		}
+		if entry.Skipped == "" {
+			plan.Bytes += entry.Bytes
+		}
+		plan.Rules = append(plan.Rules, entry)
+	}
+	return plan
+}
+
+// vetItems splits a deletion rule's matches into those the shell layer
+// would delete and those its whitelist or protection rules keep, so the
+// plan, dry run or not, shows what the run will really remove.
+func vetItems(items []planItem, opts planOptions) ([]planItem, []keptItem, error) {
+	if opts.vet == nil || len(items) == 0 {
+		return items, nil, nil
+	}
+	paths := make([]string, len(items))
+	for i, item := range items {
+		paths[i] = item.Path
+	}
+	verdicts, err := opts.vet(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+	var keep []planItem
+	var kept []keptItem
+	for _, item := range items {
+		if verdict := verdicts[item.Path]; verdict != verdictOK {
+			kept = append(kept, keptItem{Path: item.Path, Reason: cmp.Or(verdict, verdictProtected)})
+			continue
+		}
+		keep = append(keep, item)
+	}
+	return keep, kept, nil
+}
+
+func found(lookPath func(string) (string, error), name string) bool {
+	_, err := lookPath(name)
+	return err == nil
+}
+
+// runningProcess returns the first of a rule's skip_if_running processes
+// that is running, or "".
+func runningProcess(r rule, opts planOptions) string {
+	if opts.running == nil {
+		return ""
+	}
+	for _, name := range r.SkipIfRunning {
+		if opts.running(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// matchRule expands a rule's globs and keeps the entries old enough to go.
+// A glob that reaches a protected path fails the whole rule rather than
+// quietly dropping the match, since the rule is wrong. So does a match
+// that a symlinked directory on the way leads outside the rule's root or
+// into a protected path; items carry the resolved path.
+func matchRule(r rule, opts planOptions) ([]planItem, error) {
+	home := opts.home
+	if resolved, err := filepath.EvalSymlinks(home); err == nil {
+		home = resolved
+	}
+	var items []planItem
+	for _, pattern := range r.Paths {
+		pattern = filepath.Join(opts.home, strings.TrimPrefix(pattern, "~/"))
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("bad path %q: %w", pattern, err)
+		}
+		root := globRoot(pattern)
+		if resolved, err := filepath.EvalSymlinks(root); err == nil {
+			root = resolved
+		}
+		for _, path := range matches {
+			if err := guardPath(path, opts.home); err != nil {
+				return nil, err
+			}
+			info, err := os.Lstat(path)
+			if err != nil || info.Mode()&fs.ModeSymlink != 0 {
+				continue
+			}
+			resolved, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				continue
+			}
+			if resolved != root && !strings.HasPrefix(resolved, root+string(filepath.Separator)) {
+				return nil, fmt.Errorf("refusing %s: it resolves to %s, outside %s", path, resolved, root)
+			}
+			if err := guardPath(resolved, home); err != nil {
+				return nil, err
+			}
+			path = resolved
+			if r.minAge > 0 && opts.now.Sub(info.ModTime()) < r.minAge {
+				continue
+			}
+			if size := pathSize(path); size > 0 || len(r.Command) > 0 {
+				items = append(items, planItem{Path: path, Bytes: size})
+			}
+		}
+	}
+	return items, nil
+}
+
+// globRoot is the part of a glob pattern before its first wildcard
+// component: the directory every match must stay inside.
+func globRoot(pattern string) string {
+	root := pattern
+	for root != filepath.Dir(root) && strings.ContainsAny(root, `*?[\`) {
+		root = filepath.Dir(root)
+	}
+	return root
+}
+
+// guardPath refuses anything outside home, home itself or its top-level
+// folders, and the protected trees.
+func guardPath(path, home string) error {
+	rel, err := filepath.Rel(home, filepath.Clean(path))
+	rel = filepath.ToSlash(rel)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return fmt.Errorf("refusing %s: outside the home directory", path)
+	}
+	if rel == "." || !strings.Contains(rel, "/") {
+		return fmt.Errorf("refusing %s: too close to the home directory", path)
+	}
+	for _, protected := range protectedPaths {
+		if rel == protected || strings.HasPrefix(rel, protected+"/") {
+			return fmt.Errorf("refusing %s: protected", path)
+		}
+	}
+	return nil
+}
+
+// pathSize is the apparent size of a file or tree, not following symlinks.
+func pathSize(path string) int64 {
+	var total int64
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
@@ -0,0 +1,313 @@
+package clean
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tw93/mole/internal/units"
+)
+
+func writeFile(t *testing.T, path string, size int, modTime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{path, filepath.Dir(path)} {
+		if err := os.Chtimes(p, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// tempHome is a temporary home folder with its symlinks resolved, since
+// plan items carry resolved paths and macOS temp folders sit behind /var.
+func tempHome(t *testing.T) string {
+	t.Helper()
+	home, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return home
+}
+
+func testOptions(home string, now time.Time) planOptions {
+	return planOptions{
+		home:      home,
+		goos:      "darwin",
+		now:       now,
+		maxSafety: safetyModerate,
+		lookPath: func(name string) (string, error) {
+			if name == "missing" {
+				return "", errors.New("not found")
+			}
+			return "/usr/bin/" + name, nil
+		},
+	}
+}
+
+func TestBuildPlan(t *testing.T) {
+	home := tempHome(t)
+	now := time.Now()
+	old := now.Add(-40 * 24 * time.Hour)
+	writeFile(t, filepath.Join(home, "cache", "old", "blob"), 300, old)
+	writeFile(t, filepath.Join(home, "cache", "fresh", "blob"), 500, now)
+	writeFile(t, filepath.Join(home, ".tool", "cache", "data"), 700, now)
+
+	rules := []rule{
+		{Name: "old-cache", Paths: []string{"~/cache/*"}, Safety: safetySafe, minAge: 30 * 24 * time.Hour},
+		{Name: "tool", Paths: []string{"~/.tool/cache"}, Safety: safetySafe, Command: []string{"tool", "gc"}},
+		{Name: "absent-tool", Paths: []string{"~/.tool/cache"}, Safety: safetySafe, Command: []string{"missing"}},
+		{Name: "risky", Paths: []string{"~/cache/*"}, Safety: safetyRisky},
+		{Name: "linux-only", Paths: []string{"~/cache/*"}, Safety: safetySafe, Platforms: []string{"linux"}},
+	}
+	plan := buildPlan(rules, testOptions(home, now))
+
+	if len(plan.Rules) != 4 {
+		t.Fatalf("got %d entries, want 4 (linux-only filtered): %+v", len(plan.Rules), plan.Rules)
+	}
+	byName := map[string]planEntry{}
+	for _, e := range plan.Rules {
+		byName[e.Rule] = e
+	}
+	if e := byName["old-cache"]; len(e.Items) != 1 || e.Items[0].Path != filepath.Join(home, "cache", "old") || e.Bytes != 300 {
+		t.Errorf("old-cache = %+v, want only the 40-day-old entry", e)
+	}
+	if e := byName["tool"]; e.Skipped != "" || e.Bytes != 700 {
+		t.Errorf("tool = %+v, want 700 bytes measured", e)
+	}
+	if e := byName["absent-tool"]; e.Skipped != "missing not installed" {
+		t.Errorf("absent-tool skipped = %q", e.Skipped)
+	}
+	if e := byName["risky"]; e.Skipped != "above --safety moderate" {
+		t.Errorf("risky skipped = %q", e.Skipped)
+	}
+	if plan.Bytes != 1000 {
+		t.Errorf("plan bytes = %d, want 1000", plan.Bytes)
+	}
+
+	opts := testOptions(home, now)
+	opts.only = []string{"tool"}
+	if got := buildPlan(rules, opts).Rules; len(got) != 1 || got[0].Rule != "tool" {
+		t.Errorf("--only tool planned %+v", got)
+	}
+}
+
+func TestUserLogsAgePerFile(t *testing.T) {
+	rules, err := loadRules("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := slices.IndexFunc(rules, func(r rule) bool { return r.Name == "user-logs" })
+	if i < 0 {
+		t.Fatal("no user-logs rule")
+	}
+	home := tempHome(t)
+	now := time.Now()
+	old := now.Add(-40 * 24 * time.Hour)
+	logs := filepath.Join(home, "Library", "Logs")
+	// An app still logging: its folder is fresh, one file in it is old.
+	writeFile(t, filepath.Join(logs, "Busy", "old.log"), 300, old)
+	writeFile(t, filepath.Join(logs, "Busy", "today.log"), 500, now)
+	// An idle app whose folder is old but whose nested log is fresh.
+	writeFile(t, filepath.Join(logs, "Idle", "run", "current.log"), 700, now)
+	os.Chtimes(filepath.Join(logs, "Idle"), old, old)
+
+	plan := buildPlan([]rule{rules[i]}, testOptions(home, now))
+	if len(plan.Rules) != 1 {
+		t.Fatalf("plan = %+v", plan.Rules)
+	}
+	e := plan.Rules[0]
+	if len(e.Items) != 1 || e.Items[0].Path != filepath.Join(logs, "Busy", "old.log") {
+		t.Errorf("user-logs items = %+v, want only Busy/old.log", e.Items)
+	}
+}
+
+func TestBuildPlanRefusesProtectedPaths(t *testing.T) {
+	home := tempHome(t)
+	writeFile(t, filepath.Join(home, "Documents", "thesis", "draft"), 10, time.Now())
+	writeFile(t, filepath.Join(home, "top"), 10, time.Now())
+
+	for _, pattern := range []string{"~/Documents/*", "~/*"} {
+		plan := buildPlan([]rule{{Name: "bad", Paths: []string{pattern}, Safety: safetySafe}}, testOptions(home, time.Now()))
+		if e := plan.Rules[0]; e.Skipped == "" || len(e.Items) != 0 {
+			t.Errorf("%s: planned %+v, want the rule refused", pattern, e)
+		}
+	}
+}
+
+func TestGuardPath(t *testing.T) {
+	home := "/Users/me"
+	for path, ok := range map[string]bool{
+		"/Users/me/Library/Caches/x":           true,
+		"/Users/me/.npm/_cacache":              true,
+		"/Users/me/Library":                    false,
+		"/Users/me":                            false,
+		"/Users/other/Library/Caches/x":        false,
+		"/Users/me/Library/Containers/x":       false,
+		"/Users/me/Library/Mobile Documents/x": false,
+		"/Users/me/.ssh/known_hosts":           false,
+
+		"/Users/me/Library/Containers/com.apple.mail/Data/Library/Mail Downloads/1A2B": false,
+		"/Users/me/Library/Containers/com.apple.mail/Data/Library/Caches/x":            false,
+	} {
+		if err := guardPath(path, home); (err == nil) != ok {
+			t.Errorf("guardPath(%q) = %v, want ok=%v", path, err, ok)
+		}
+	}
+}
+
+func TestBuildPlanSkipsWhileOwnerRuns(t *testing.T) {
+	home := tempHome(t)
+	old := time.Now().Add(-40 * 24 * time.Hour)
+	writeFile(t, filepath.Join(home, "Library", "Mail Downloads", "1A2B", "invoice.pdf"), 300, old)
+
+	rules := []rule{{Name: "mail-downloads", Paths: []string{"~/Library/Mail Downloads/*"}, Safety: safetySafe, SkipIfRunning: []string{"Mail"}}}
+	opts := testOptions(home, time.Now())
+	if e := buildPlan(rules, opts).Rules[0]; e.Skipped != "" || e.Bytes != 300 {
+		t.Errorf("Mail stopped: planned %+v, want the download", e)
+	}
+	opts.running = func(name string) bool { return name == "Mail" }
+	if e := buildPlan(rules, opts).Rules[0]; e.Skipped != "Mail is running" || len(e.Items) != 0 {
+		t.Errorf("Mail running: planned %+v, want the rule skipped", e)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	home := tempHome(t)
+	now := time.Now()
+	writeFile(t, filepath.Join(home, "cache", "a", "blob"), 300, now)
+	writeFile(t, filepath.Join(home, ".tool", "cache", "data"), 700, now)
+
+	var ran []string
+	saved := runCmd
+	runCmd = func(_ context.Context, name string, args ...string) error {
+		ran = append(ran, name)
+		return os.Remove(filepath.Join(home, ".tool", "cache", "data"))
+	}
+	t.Cleanup(func() { runCmd = saved })
+	var deleted []string
+	savedShell := runShell
+	runShell = func(_ context.Context, mode string, paths []string, permanent bool) (map[string]string, error) {
+		verdicts := map[string]string{}
+		for _, path := range paths {
+			deleted = append(deleted, mode+" "+path)
+			verdicts[path] = verdictOK
+			os.RemoveAll(path)
+		}
+		return verdicts, nil
+	}
+	t.Cleanup(func() { runShell = savedShell })
+
+	plan := buildPlan([]rule{
+		{Name: "cache", Paths: []string{"~/cache/*"}, Safety: safetySafe},
+		{Name: "tool", Paths: []string{"~/.tool/cache"}, Safety: safetySafe, Command: []string{"tool", "gc"}},
+	}, testOptions(home, now))
+	report := execute(context.Background(), plan, home)
+
+	if report.failed() || report.Freed != 1000 {
+		t.Fatalf("report = %+v, want 1000 bytes freed without errors", report)
+	}
+	if !slices.Equal(ran, []string{"tool"}) {
+		t.Errorf("ran %v, want [tool]", ran)
+	}
+	if !slices.Equal(deleted, []string{"delete " + filepath.Join(home, "cache", "a")}) {
+		t.Errorf("deleted %v, want cache/a through the shell", deleted)
+	}
+	if _, err := os.Stat(filepath.Join(home, "cache", "a")); !os.IsNotExist(err) {
+		t.Errorf("cache/a still exists: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".tool", "cache")); err != nil {
+		t.Errorf("command rule removed its measured path: %v", err)
+	}
+}
+
+func TestBuildPlanKeepsWhitelistedPaths(t *testing.T) {
+	home := tempHome(t)
+	now := time.Now()
+	writeFile(t, filepath.Join(home, "cache", "a", "blob"), 300, now)
+	writeFile(t, filepath.Join(home, "cache", "keep", "blob"), 500, now)
+
+	opts := testOptions(home, now)
+	opts.vet = func(paths []string) (map[string]string, error) {
+		verdicts := map[string]string{}
+		for _, path := range paths {
+			verdicts[path] = verdictOK
+			if filepath.Base(path) == "keep" {
+				verdicts[path] = verdictWhitelist
+			}
+		}
+		return verdicts, nil
+	}
+	rules := []rule{{Name: "cache", Paths: []string{"~/cache/*"}, Safety: safetySafe}}
+	e := buildPlan(rules, opts).Rules[0]
+	if len(e.Items) != 1 || e.Bytes != 300 || len(e.Kept) != 1 || e.Kept[0].Reason != verdictWhitelist {
+		t.Errorf("planned %+v, want cache/a removed and cache/keep kept", e)
+	}
+
+	opts.vet = func([]string) (map[string]string, error) { return nil, errors.New("no shell library") }
+	if e := buildPlan(rules, opts).Rules[0]; e.Skipped != "no shell library" || len(e.Items) != 0 {
+		t.Errorf("without the shell: planned %+v, want the rule skipped", e)
+	}
+}
+
+func TestBuildPlanRefusesSymlinkedDirectories(t *testing.T) {
+	home := tempHome(t)
+	now := time.Now()
+	writeFile(t, filepath.Join(home, "Documents", "thesis", "draft"), 10, now)
+	writeFile(t, filepath.Join(home, "elsewhere", "logs", "old"), 10, now)
+	if err := os.MkdirAll(filepath.Join(home, "cache"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(home, "Documents"), filepath.Join(home, "cache", "docs")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(home, "elsewhere"), filepath.Join(home, "cache", "out")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pattern := range []string{"~/cache/docs/*", "~/cache/*/logs"} {
+		plan := buildPlan([]rule{{Name: "bad", Paths: []string{pattern}, Safety: safetySafe}}, testOptions(home, now))
+		if e := plan.Rules[0]; e.Skipped == "" || len(e.Items) != 0 {
+			t.Errorf("%s: planned %+v, want the rule refused", pattern, e)
+		}
+	}
+}
+
+func TestBuildPlanPreview(t *testing.T) {
+	home := tempHome(t)
+	writeFile(t, filepath.Join(home, "Library", "Caches", "Homebrew", "wget.bottle.tar.gz"), 700, time.Now())
+	rules := []rule{{Name: "homebrew", Paths: []string{"~/Library/Caches/Homebrew"}, Safety: safetySafe,
+		Command: []string{"brew", "cleanup"}, Preview: []string{"brew", "cleanup", "-n"}}}
+
+	opts := testOptions(home, time.Now())
+	var previewed []string
+	lines := []string{"Would remove: ~/Library/Caches/Homebrew/wget.bottle.tar.gz (700B)"}
+	opts.preview = func(command []string) ([]string, error) {
+		previewed = command
+		return lines, nil
+	}
+	e := buildPlan(rules, opts).Rules[0]
+	if !slices.Equal(previewed, []string{"brew", "cleanup", "-n"}) || !slices.Equal(e.Preview, lines) || e.Skipped != "" {
+		t.Errorf("ran %v, planned %+v", previewed, e)
+	}
+	var buf bytes.Buffer
+	writePlan(&buf, cleanPlan{Rules: []planEntry{e}}, units.BytesSI)
+	if !strings.Contains(buf.String(), "Would remove: ~/Library/Caches/Homebrew/wget.bottle.tar.gz") {
+		t.Errorf("plan lacks the preview:\n%s", buf.String())
+	}
+
+	lines = nil
+	if e := buildPlan(rules, opts).Rules[0]; e.Skipped != "nothing to clean" {
+		t.Errorf("empty preview: planned %+v", e)
+	}
+}
@@ -0,0 +1,217 @@
+package clean
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed defaults.yaml
+var defaultRules []byte
+
+// Safety levels, in the order --safety compares them.
+const (
+	safetySafe     = "safe"
+	safetyModerate = "moderate"
+	safetyRisky    = "risky"
+)
+
+var safetyLevels = []string{safetySafe, safetyModerate, safetyRisky}
+
+// rule is one entry of a rules file.
+type rule struct {
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description" json:"description,omitempty"`
+	Paths       []string `yaml:"paths" json:"paths"`
+	OlderThan   string   `yaml:"older_than" json:"older_than,omitempty"`
+	Safety      string   `yaml:"safety" json:"safety"`
+	Command     []string `yaml:"command" json:"command,omitempty"`
+	// Preview is the command's dry run, such as brew cleanup -n; the plan
+	// lists what it prints before the command runs.
+	Preview   []string `yaml:"preview" json:"preview,omitempty"`
+	Platforms []string `yaml:"platforms" json:"platforms,omitempty"`
+	// SkipIfRunning names processes, such as Mail, that own the paths; the
+	// rule waits until none of them is running.
+	SkipIfRunning []string `yaml:"skip_if_running" json:"skip_if_running,omitempty"`
+	// Disabled drops a bundled rule of the same name; it needs no other field.
+	Disabled bool `yaml:"disabled" json:"-"`
+
+	minAge time.Duration
+}
+
+type ruleFile struct {
+	Rules []rule `yaml:"rules"`
+}
+
+var ruleName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// userRulesPath is the file that adds to and overrides the bundled rules,
+// or "" when there is no home directory.
+func userRulesPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "mole", "clean.yaml")
+}
+
+// loadRules returns the bundled rules with the user file at path layered
+// on top: a rule with a bundled rule's name replaces it, and
+// `disabled: true` removes it. A missing user file is fine.
+func loadRules(path string) ([]rule, error) {
+	rules, err := parseRules(bytes.NewReader(defaultRules), "defaults.yaml")
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return rules, nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return rules, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	user, err := parseRules(f, path)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range user {
+		i := slices.IndexFunc(rules, func(existing rule) bool { return existing.Name == r.Name })
+		switch {
+		case r.Disabled && i >= 0:
+			rules = slices.Delete(rules, i, i+1)
+		case r.Disabled:
+		case i >= 0:
+			rules[i] = r
+		default:
+			rules = append(rules, r)
+		}
+	}
+	return rules, nil
+}
+
+// parseRules decodes and validates one rules file. Unknown keys are errors,
+// so a misspelled older_than cannot silently widen what a rule removes.
+func parseRules(r io.Reader, source string) ([]rule, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	var file ruleFile
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	seen := map[string]bool{}
+	for i := range file.Rules {
+		r := &file.Rules[i]
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("%s: rule %d: %w", source, i+1, err)
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("%s: rule %q is defined twice", source, r.Name)
+		}
+		seen[r.Name] = true
+	}
+	return file.Rules, nil
+}
+
+func (r *rule) validate() error {
+	if !ruleName.MatchString(r.Name) {
+		return fmt.Errorf("name %q must be lowercase letters, digits, and dashes", r.Name)
+	}
+	if r.Disabled {
+		return nil
+	}
+	if len(r.Paths) == 0 {
+		return fmt.Errorf("%s: no paths", r.Name)
+	}
+	for _, p := range r.Paths {
+		if !strings.HasPrefix(p, "~/") || len(p) == 2 {
+			return fmt.Errorf("%s: path %q must start with ~/; mole clean only touches your home directory", r.Name, p)
+		}
+	}
+	if !slices.Contains(safetyLevels, r.Safety) {
+		return fmt.Errorf("%s: safety %q must be safe, moderate, or risky", r.Name, r.Safety)
+	}
+	if len(r.Command) > 0 && r.OlderThan != "" {
+		return fmt.Errorf("%s: older_than cannot be combined with command; the command decides what it removes", r.Name)
+	}
+	if len(r.Preview) > 0 && len(r.Command) == 0 {
+		return fmt.Errorf("%s: preview needs a command to preview", r.Name)
+	}
+	if r.OlderThan != "" {
+		age, err := parseAge(r.OlderThan)
+		if err != nil {
+			return fmt.Errorf("%s: older_than: %w", r.Name, err)
+		}
+		r.minAge = age
+	}
+	for _, name := range r.SkipIfRunning {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("%s: skip_if_running has an empty process name", r.Name)
+		}
+	}
+	for _, goos := range r.Platforms {
+		if goos != "darwin" && goos != "linux" {
+			return fmt.Errorf("%s: platform %q must be darwin or linux", r.Name, goos)
+		}
+	}
+	return nil
+}
+
+// parseAge reads an older_than value: a Go duration, or a whole number of
+// days or weeks such as 30d or 2w.
+func parseAge(s string) (time.Duration, error) {
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit != 0 {
+		count, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || count <= 0 {
+			return 0, fmt.Errorf("%q is not a positive duration like 30d, 2w, or 12h", s)
+		}
+		return time.Duration(count) * unit, nil
+	}
+	age, err := time.ParseDuration(s)
+	if err != nil || age <= 0 {
+		return 0, fmt.Errorf("%q is not a positive duration like 30d, 2w, or 12h", s)
+	}
+	return age, nil
+}
+
+// safetyRank orders levels for --safety; unknown levels rank last.
+func safetyRank(level string) int {
+	if i := slices.Index(safetyLevels, level); i >= 0 {
+		return i
+	}
+	return len(safetyLevels)
+}
+
+func (r rule) runsOn(goos string) bool {
+	return len(r.Platforms) == 0 || slices.Contains(r.Platforms, goos)
+}
+
+// CheckRules loads the bundled rules and the user file, so `mole doctor`
+// can report a broken clean.yaml before clean trips over it. It returns the
+// user file's path.
+func CheckRules() (string, error) {
+	path := userRulesPath()
+	_, err := loadRules(path)
+	return path, err
+}
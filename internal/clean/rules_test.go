@@ -0,0 +1,98 @@
+package clean
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultRulesParse(t *testing.T) {
+	rules, err := loadRules("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) == 0 {
+		t.Fatal("no bundled rules")
+	}
+	for _, r := range rules {
+		if r.Description == "" {
+			t.Errorf("%s: bundled rules need a description", r.Name)
+		}
+	}
+}
+
+func TestLoadRulesLayersUserFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clean.yaml")
+	user := `rules:
+  - name: npm-cache
+    disabled: true
+  - name: go-build-cache
+    paths: ["~/.cache/go-build"]
+    command: [go, clean, -cache]
+    safety: moderate
+  - name: scratch
+    paths: ["~/scratch/*"]
+    older_than: 2w
+    safety: risky
+`
+	if err := os.WriteFile(path, []byte(user), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := loadRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]rule{}
+	for _, r := range rules {
+		byName[r.Name] = r
+	}
+	if _, ok := byName["npm-cache"]; ok {
+		t.Error("disabled rule npm-cache still loaded")
+	}
+	if got := byName["go-build-cache"].Safety; got != safetyModerate {
+		t.Errorf("override safety = %q, want moderate", got)
+	}
+	if got := byName["scratch"].minAge; got != 14*24*time.Hour {
+		t.Errorf("scratch minAge = %v, want 2w", got)
+	}
+}
+
+func TestParseRulesRejects(t *testing.T) {
+	for name, body := range map[string]string{
+		"unknown key":             "rules:\n  - name: a\n    paths: [\"~/a/b\"]\n    safety: safe\n    older_then: 3d\n",
+		"absolute path":           "rules:\n  - name: a\n    paths: [\"/tmp/x\"]\n    safety: safe\n",
+		"bad safety":              "rules:\n  - name: a\n    paths: [\"~/a/b\"]\n    safety: yolo\n",
+		"age with command":        "rules:\n  - name: a\n    paths: [\"~/a/b\"]\n    safety: safe\n    older_than: 3d\n    command: [true]\n",
+		"preview without command": "rules:\n  - name: a\n    paths: [\"~/a/b\"]\n    safety: safe\n    preview: [brew, cleanup, -n]\n",
+		"bad age":                 "rules:\n  - name: a\n    paths: [\"~/a/b\"]\n    safety: safe\n    older_than: soon\n",
+		"duplicate":               "rules:\n  - name: a\n    paths: [\"~/a/b\"]\n    safety: safe\n  - name: a\n    paths: [\"~/a/c\"]\n    safety: safe\n",
+		"bad name":                "rules:\n  - name: My Rule\n    paths: [\"~/a/b\"]\n    safety: safe\n",
+		"unknown platform":        "rules:\n  - name: a\n    paths: [\"~/a/b\"]\n    safety: safe\n    platforms: [windows]\n",
+		"missing paths":           "rules:\n  - name: a\n    safety: safe\n",
+		"home itself":             "rules:\n  - name: a\n    paths: [\"~/\"]\n    safety: safe\n",
+		"empty process":           "rules:\n  - name: a\n    paths: [\"~/a/b\"]\n    safety: safe\n    skip_if_running: [\"\"]\n",
+	} {
+		if _, err := parseRules(strings.NewReader(body), "test.yaml"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"30d": 30 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+	} {
+		if got, err := parseAge(in); err != nil || got != want {
+			t.Errorf("parseAge(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "0d", "-1w", "d", "tomorrow"} {
+		if _, err := parseAge(in); err == nil {
+			t.Errorf("parseAge(%q) should fail", in)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package clean
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/tw93/mole/internal/debuglog"
+)
+
+// commandTimeout bounds a tool's own cleanup; brew cleanup on a large
+// Cellar is the slow case.
+const commandTimeout = 10 * time.Minute
+
+// runCmd runs a rule's cleanup command. Tests swap it.
+var runCmd = func(ctx context.Context, name string, args ...string) error {
+	start := time.Now()
+	output, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	debuglog.Command(name, args, time.Since(start), err)
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%w: %s", err, lastLine(msg))
+		}
+		return err
+	}
+	return nil
+}
+
+// previewLines is how many lines of a command's preview the plan keeps.
+const previewLines = 200
+
+// previewCommand runs a rule's dry-run command and returns the lines it
+// printed.
+func previewCommand(command []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	start := time.Now()
+	output, err := exec.CommandContext(ctx, command[0], command[1:]...).Output()
+	debuglog.Command(command[0], command[1:], time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" && len(lines) < previewLines {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// processRunning reports whether a process is running under exactly name.
+func processRunning(name string) bool {
+	return exec.Command("pgrep", "-x", name).Run() == nil
+}
+
+func lastLine(s string) string {
+	return s[strings.LastIndex(s, "\n")+1:]
+}
+
+// ruleResult is what one rule reclaimed.
+type ruleResult struct {
+	Rule    string `json:"rule"`
+	Freed   int64  `json:"freed"`
+	Removed int    `json:"removed"`
+	Kept    int    `json:"kept,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type cleanReport struct {
+	Rules []ruleResult `json:"rules"`
+	Freed int64        `json:"freed"`
+}
+
+// removed is how many paths deletion rules removed.
+func (r cleanReport) removed() int {
+	n := 0
+	for _, result := range r.Rules {
+		n += result.Removed
+	}
+	return n
+}
+
+// failed reports whether any rule hit an error.
+func (r cleanReport) failed() bool {
+	for _, result := range r.Rules {
+		if result.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// execute carries out a plan. Deletion rules hand exactly the paths the
+// plan listed, re-checked against the guard, to the shell layer's
+// mole_delete; command rules run the tool and count what disappeared from
+// their paths.
+func execute(ctx context.Context, plan cleanPlan, home string) cleanReport {
+	var report cleanReport
+	for _, entry := range plan.Rules {
+		if entry.Skipped != "" {
+			continue
+		}
+		result := ruleResult{Rule: entry.Rule}
+		if len(entry.Command) > 0 {
+			runCommandRule(ctx, entry, &result)
+		} else {
+			removeItems(ctx, entry, home, &result)
+		}
+		report.Freed += result.Freed
+		report.Rules = append(report.Rules, result)
+	}
+	return report
+}
+
+func runCommandRule(ctx context.Context, entry planEntry, result *ruleResult) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	if err := runCmd(ctx, entry.Command[0], entry.Command[1:]...); err != nil {
+		result.Error = fmt.Sprintf("%s: %v", strings.Join(entry.Command, " "), err)
+	}
+	var after int64
+	for _, item := range entry.Items {
+		after += pathSize(item.Path)
+	}
+	result.Freed = max(entry.Bytes-after, 0)
+}
+
+// removeItems deletes a rule's items through mole_delete, so they go to
+// Trash, land in operations.log, and pass the whitelist and
+// should_protect_path like every other deletion Mole makes.
+func removeItems(ctx context.Context, entry planEntry, home string, result *ruleResult) {
+	var errs []error
+	var paths []string
+	for _, item := range entry.Items {
+		if err := guardPath(item.Path, home); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		paths = append(paths, item.Path)
+	}
+	verdicts := map[string]string{}
+	if len(paths) > 0 {
+		var err error
+		if verdicts, err = runShell(ctx, "delete", paths, *permanent); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, item := range entry.Items {
+		switch verdicts[item.Path] {
+		case verdictOK:
+			result.Removed++
+			result.Freed += item.Bytes
+		case verdictWhitelist, verdictProtected:
+			result.Kept++
+		case verdictFailed:
+			errs = append(errs, fmt.Errorf("could not delete %s", item.Path))
+		}
+	}
+	if len(errs) > 0 {
+		result.Error = errs[0].Error()
+		if len(errs) > 1 {
+			result.Error += fmt.Sprintf(" (and %d more)", len(errs)-1)
+		}
+	}
+}
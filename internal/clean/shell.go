@@ -0,0 +1,111 @@
+package clean
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tw93/mole/internal/debuglog"
+)
+
+// Verdicts the shell layer returns for a path.
+const (
+	verdictOK        = "ok"
+	verdictWhitelist = "whitelist"
+	verdictProtected = "protected"
+	verdictFailed    = "failed"
+)
+
+// shellScript puts each path after the library directory and the mode
+// through the shell layer's deletion policy: the clean whitelist, then
+// validate_path_for_deletion and should_protect_path. In "check" mode it
+// only decides; in "delete" mode it removes what passes with mole_delete,
+// which moves it to Trash unless MOLE_DELETE_MODE is "permanent" and logs
+// it to operations.log. It prints a NUL-terminated "verdict<TAB>path"
+// record per path.
+const shellScript = `source "$1/manage/whitelist.sh" || exit 1
+load_whitelist clean
+if [[ ${#WHITELIST_PATTERNS[@]} -gt 0 ]]; then
+    for i in "${!WHITELIST_PATTERNS[@]}"; do
+        WHITELIST_PATTERNS[i]="${WHITELIST_PATTERNS[i]/#\~/$HOME}"
+    done
+fi
+mode=$2
+shift 2
+for path in "$@"; do
+    if is_path_whitelisted "$path"; then
+        verdict=whitelist
+        if [[ "$mode" == "delete" ]]; then log_operation clean SKIPPED "$path" whitelist; fi
+    elif ! validate_path_for_deletion "$path" 2> /dev/null; then
+        verdict=protected
+        if [[ "$mode" == "delete" ]]; then log_operation clean SKIPPED "$path" protected; fi
+    elif [[ "$mode" == "check" ]] || mole_delete "$path"; then
+        verdict=ok
+    else
+        verdict=failed
+    fi
+    printf '%s\t%s\0' "$verdict" "$path"
+done
+`
+
+// runShell runs shellScript over paths and returns each path's verdict.
+// Tests swap it.
+var runShell = func(ctx context.Context, mode string, paths []string, permanent bool) (map[string]string, error) {
+	lib, err := libDir()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, "bash", append([]string{"-c", shellScript, "mole-clean", lib, mode}, paths...)...)
+	deleteMode := "trash"
+	if permanent {
+		deleteMode = "permanent"
+	}
+	cmd.Env = append(os.Environ(), "MOLE_CURRENT_COMMAND=clean", "MOLE_DELETE_MODE="+deleteMode, "MOLE_DRY_RUN=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	start := time.Now()
+	out, err := cmd.Output()
+	debuglog.Command("bash", []string{mode, fmt.Sprintf("%d paths", len(paths))}, time.Since(start), err)
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("mole's deletion checks: %w: %s", err, lastLine(msg))
+		}
+		return nil, fmt.Errorf("mole's deletion checks: %w", err)
+	}
+	verdicts := map[string]string{}
+	for _, record := range strings.Split(string(out), "\x00") {
+		if verdict, path, ok := strings.Cut(record, "\t"); ok {
+			verdicts[path] = verdict
+		}
+	}
+	// mole_delete explains a refused Trash move on stderr.
+	os.Stderr.Write(stderr.Bytes())
+	return verdicts, nil
+}
+
+// libDir finds Mole's shell library: $MOLE_LIB_DIR, which bin/clean.sh
+// sets, or the lib directory next to the bin directory holding this binary.
+func libDir() (string, error) {
+	dirs := []string{os.Getenv("MOLE_LIB_DIR")}
+	if exe, err := os.Executable(); err == nil {
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+		dirs = append(dirs, filepath.Join(filepath.Dir(exe), "..", "lib"))
+	}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, "manage", "whitelist.sh")); err == nil {
+			return filepath.Clean(dir), nil
+		}
+	}
+	return "", errors.New("mole's shell library is not installed next to this binary; run mo clean --rules")
+}
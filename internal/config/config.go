@@ -0,0 +1,186 @@
+// Package config reads ~/.config/mole/config.toml, the settings file shared
+// by analyze and status, and edits it for `mole config`.
+//
+// Keys are the commands' own flag names, so any flag can be given a default
+// without this package learning about it:
+//
+//	theme = "solarized"          # top level: every command with the flag
+//	units = "binary"
+//
+//	[status]
+//	interval = "2s"
+//	proc-cpu-threshold = 80
+//
+//	[analyze]
+//	exclude = ["node_modules", ".git"]
+//
+//	[profiles.work]              # --profile work layers these on top
+//	theme = "light"
+//
+//	[profiles.work.status]
+//	ping-targets = ["gateway", "10.0.0.1"]
+//
+// A flag given on the command line wins over the file, and so does the
+// environment variable of a flag that has one (MO_THEME, MO_UNITS, ...), the
+// order the units file already used.
+package config
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Path is the config file, or "" when there is no home directory.
+func Path() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "mole", "config.toml")
+}
+
+// envOverrides names the environment variable that beats the file for
+// flags that have one.
+var envOverrides = map[string]string{
+	"theme":    "MO_THEME",
+	"units":    "MO_UNITS",
+	"lang":     "MO_LANG",
+	"no-color": "NO_COLOR",
+	"debug":    "MO_DEBUG",
+}
+
+// File is a parsed config file.
+type File struct {
+	data map[string]any
+}
+
+// Load parses the file at path. A missing file is an empty config.
+func Load(path string) (File, error) {
+	data := map[string]any{}
+	if path == "" {
+		return File{data}, nil
+	}
+	if _, err := toml.DecodeFile(path, &data); err != nil && !os.IsNotExist(err) {
+		return File{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return File{data}, nil
+}
+
+// table returns the table at a dotted path, or nil.
+func (f File) table(path ...string) map[string]any {
+	t := f.data
+	for _, name := range path {
+		next, ok := t[name].(map[string]any)
+		if !ok {
+			return nil
+		}
+		t = next
+	}
+	return t
+}
+
+// Setting is one merged value. Scoped is true when it came from a
+// [command] table, where a key that is not a flag of the command is an
+// error; top-level keys may belong to the other command.
+type Setting struct {
+	Value  string
+	Scoped bool
+}
+
+// Settings merges the values for command: the top level, then [command],
+// then [profiles.<profile>] and [profiles.<profile>.command], later layers
+// winning.
+func (f File) Settings(command, profile string) (map[string]Setting, error) {
+	merged := map[string]Setting{}
+	layers := [][]string{nil, {command}}
+	if profile != "" {
+		if f.table("profiles", profile) == nil {
+			return nil, fmt.Errorf("no profile %q in %s", profile, Path())
+		}
+		layers = append(layers, []string{"profiles", profile}, []string{"profiles", profile, command})
+	}
+	for i, path := range layers {
+		for key, value := range f.table(path...) {
+			if _, isTable := value.(map[string]any); isTable {
+				continue
+			}
+			s, err := stringValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", strings.Join(append(path, key), "."), err)
+			}
+			merged[strings.ReplaceAll(key, "_", "-")] = Setting{Value: s, Scoped: i%2 == 1}
+		}
+	}
+	return merged, nil
+}
+
+// stringValue renders a TOML value as a flag argument. Arrays become
+// comma-separated lists, the form list flags like --ping-targets take.
+func stringValue(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := stringValue(item)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, s)
+		}
+		return strings.Join(parts, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", v)
+	}
+}
+
+// Apply loads the config file and sets every flag of fs that the command
+// line left alone. Call it right after fs.Parse. profile comes from
+// --profile, or MO_PROFILE when that is empty.
+func Apply(fs *flag.FlagSet, command, profile string) error {
+	if profile == "" {
+		profile = os.Getenv("MO_PROFILE")
+	}
+	file, err := Load(Path())
+	if err != nil {
+		return err
+	}
+	settings, err := file.Settings(command, profile)
+	if err != nil {
+		return err
+	}
+
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	for _, name := range slices.Sorted(maps.Keys(settings)) {
+		setting := settings[name]
+		if fs.Lookup(name) == nil {
+			if setting.Scoped {
+				return fmt.Errorf("%s: %s has no --%s flag", Path(), command, name)
+			}
+			continue
+		}
+		if env := envOverrides[name]; explicit[name] || env != "" && os.Getenv(env) != "" {
+			continue
+		}
+		if err := fs.Set(name, setting.Value); err != nil {
+			return fmt.Errorf("%s: %s: %w", Path(), name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sample = `# shared by every command
+theme = "solarized"
+units = "binary"
+
+[status]
+interval = "2s"
+ping_targets = ["gateway", "1.1.1.1"]
+
+[profiles.work]
+theme = "light"
+
+[profiles.work.status]
+interval = "5s"
+`
+
+func writeConfig(t *testing.T, text string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("MO_PROFILE", "")
+	t.Setenv("MO_THEME", "")
+	path := filepath.Join(home, ".config", "mole", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func statusFlags() (*flag.FlagSet, *string, *string, *time.Duration) {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	theme := fs.String("theme", "", "")
+	targets := fs.String("ping-targets", "", "")
+	interval := fs.Duration("interval", time.Second, "")
+	return fs, theme, targets, interval
+}
+
+func TestApplyLayersProfiles(t *testing.T) {
+	writeConfig(t, sample)
+
+	fs, theme, targets, interval := statusFlags()
+	fs.Parse(nil)
+	if err := Apply(fs, "status", ""); err != nil {
+		t.Fatal(err)
+	}
+	if *theme != "solarized" || *targets != "gateway,1.1.1.1" || *interval != 2*time.Second {
+		t.Fatalf("base: theme %q, targets %q, interval %v", *theme, *targets, *interval)
+	}
+
+	fs, theme, _, interval = statusFlags()
+	fs.Parse(nil)
+	if err := Apply(fs, "status", "work"); err != nil {
+		t.Fatal(err)
+	}
+	if *theme != "light" || *interval != 5*time.Second {
+		t.Fatalf("work: theme %q, interval %v", *theme, *interval)
+	}
+
+	if err := Apply(fs, "status", "missing"); err == nil {
+		t.Fatal("an unknown profile should be an error")
+	}
+}
+
+func TestApplyKeepsCommandLineAndEnvironment(t *testing.T) {
+	writeConfig(t, sample)
+	t.Setenv("MO_THEME", "dark")
+
+	fs, theme, _, interval := statusFlags()
+	fs.Parse([]string{"--interval", "3s"})
+	if err := Apply(fs, "status", ""); err != nil {
+		t.Fatal(err)
+	}
+	if *interval != 3*time.Second {
+		t.Fatalf("--interval should beat the file, got %v", *interval)
+	}
+	if *theme != "" {
+		t.Fatalf("MO_THEME should beat the file, got theme %q", *theme)
+	}
+}
+
+func TestApplyRejectsUnknownCommandKeys(t *testing.T) {
+	writeConfig(t, "exclude = \"node_modules\"\n[status]\nbogus = 1\n")
+
+	fs, _, _, _ := statusFlags()
+	fs.Parse(nil)
+	err := Apply(fs, "status", "")
+	if err == nil || !strings.Contains(err.Error(), "--bogus") {
+		t.Fatalf("Apply = %v, want an error naming --bogus", err)
+	}
+}
+
+func TestSetKeepsComments(t *testing.T) {
+	path := writeConfig(t, sample)
+
+	for _, kv := range [][2]string{
+		{"status.interval", `"3s"`},
+		{"units", `"si"`},
+		{"profiles.work.status.public-ip", "true"},
+		{"analyze.exclude", `"node_modules"`},
+	} {
+		if err := Set(path, kv[0], kv[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	raw, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(raw), "# shared by every command\n") {
+		t.Fatalf("comment lost:\n%s", raw)
+	}
+	file, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"status.interval":                "3s",
+		"units":                          "si",
+		"theme":                          "solarized",
+		"profiles.work.status.interval":  "5s",
+		"profiles.work.status.public-ip": "true",
+		"analyze.exclude":                "node_modules",
+	} {
+		if got, _ := file.Get(key); got != want {
+			t.Errorf("%s = %q, want %q\n%s", key, got, want, raw)
+		}
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	path := writeConfig(t, sample+`
+[profiles.battery]
+interval = "10s"
+
+[profiles]
+stray = "not a profile"
+`)
+	file, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(file.Profiles(), ","); got != "battery,work" {
+		t.Fatalf("Profiles = %q, want battery,work", got)
+	}
+}
+
+func TestBookmarks(t *testing.T) {
+	path := writeConfig(t, `targets = ["~/Projects", "~/Developer"]
+
+[analyze]
+targets = "~/Movies, ~/Projects"
+
+[profiles.usb.analyze]
+targets = ["/Volumes/Archive"]
+`)
+	file, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "/Volumes/Archive,~/Developer,~/Movies,~/Projects"
+	if got := strings.Join(file.Bookmarks(), ","); got != want {
+		t.Fatalf("Bookmarks = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Get returns the value at a dotted key such as "status.interval".
+func (f File) Get(key string) (string, bool) {
+	parts := strings.Split(key, ".")
+	value, ok := f.table(parts[:len(parts)-1]...)[parts[len(parts)-1]]
+	if !ok {
+		return "", false
+	}
+	if _, isTable := value.(map[string]any); isTable {
+		return "", false
+	}
+	s, err := stringValue(value)
+	return s, err == nil
+}
+
+// List returns every value as a sorted "dotted.key = value" line.
+func (f File) List() []string {
+	var lines []string
+	var walk func(prefix string, t map[string]any)
+	walk = func(prefix string, t map[string]any) {
+		for key, value := range t {
+			if sub, isTable := value.(map[string]any); isTable {
+				walk(prefix+key+".", sub)
+				continue
+			}
+			if s, err := stringValue(value); err == nil {
+				lines = append(lines, prefix+key+" = "+s)
+			}
+		}
+	}
+	walk("", f.data)
+	slices.Sort(lines)
+	return lines
+}
+
+// Profiles returns the names of the [profiles.<name>] tables, sorted, for
+// shell completion of --profile.
+func (f File) Profiles() []string {
+	var names []string
+	for name, value := range f.table("profiles") {
+		if _, isTable := value.(map[string]any); isTable {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Bookmarks returns the folders saved as analyze targets, at the top level,
+// in [analyze], and in every profile, sorted and without duplicates, for
+// shell completion of mo analyze paths.
+func (f File) Bookmarks() []string {
+	tables := [][]string{nil, {"analyze"}}
+	for _, name := range f.Profiles() {
+		tables = append(tables, []string{"profiles", name}, []string{"profiles", name, "analyze"})
+	}
+	var folders []string
+	for _, path := range tables {
+		switch value := f.table(path...)["targets"].(type) {
+		case string:
+			folders = append(folders, strings.Split(value, ",")...)
+		case []any:
+			for _, item := range value {
+				if s, ok := item.(string); ok {
+					folders = append(folders, s)
+				}
+			}
+		}
+	}
+	for i, folder := range folders {
+		folders[i] = strings.TrimSpace(folder)
+	}
+	folders = slices.DeleteFunc(folders, func(s string) bool { return s == "" })
+	slices.Sort(folders)
+	return slices.Compact(folders)
+}
+
+// Literal validates value against the flag it configures and renders it as
+// a TOML value: booleans and numbers bare, everything else quoted.
+func Literal(f *flag.Flag, value string) (string, error) {
+	if err := f.Value.Set(value); err != nil {
+		return "", fmt.Errorf("--%s: %w", f.Name, err)
+	}
+	if getter, ok := f.Value.(flag.Getter); ok {
+		switch getter.Get().(type) {
+		case bool, int, int64, uint, uint64, float64:
+			return f.Value.String(), nil
+		}
+	}
+	return strconv.Quote(value), nil
+}
+
+// Set writes key = literal into the file at path, creating it if needed.
+// It edits the text line by line rather than re-encoding the whole file, so
+// comments and ordering survive.
+func Set(path, key, literal string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	parts := strings.Split(key, ".")
+	table, name := strings.Join(parts[:len(parts)-1], "."), parts[len(parts)-1]
+	updated := setLine(string(raw), table, name, literal)
+
+	var check map[string]any
+	if _, err := toml.Decode(updated, &check); err != nil {
+		return fmt.Errorf("%s: setting %s would not parse: %w", path, key, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(updated), 0o644)
+}
+
+// setLine replaces name = ... inside [table] ("" is the top level), adds
+// it at the end of the table, or appends the table.
+func setLine(text, table, name, literal string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if text == "" {
+		lines = nil
+	}
+	entry := name + " = " + literal
+
+	current, last := "", -1
+	if table == "" {
+		last = 0
+	}
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			header, _, _ := strings.Cut(strings.Trim(trimmed, "[]"), "]")
+			current = strings.TrimSpace(header)
+			continue
+		}
+		if current != table {
+			continue
+		}
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			last = i + 1
+		}
+		if key, _, ok := strings.Cut(trimmed, "="); ok && strings.TrimSpace(key) == name {
+			lines[i] = entry
+			return strings.Join(lines, "\n") + "\n"
+		}
+	}
+	if last < 0 {
+		// Header without entries, or no header at all.
+		for i, line := range lines {
+			if strings.TrimSpace(line) == "["+table+"]" {
+				last = i + 1
+			}
+		}
+	}
+	if last < 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+table+"]", entry)
+		return strings.Join(lines, "\n") + "\n"
+	}
+	lines = slices.Insert(lines, last, entry)
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,225 @@
+// Package crash turns a panic anywhere in mole into a usable bug report.
+// Recover, deferred at the top of main and around every Bubble Tea
+// command, puts the terminal back the way the program found it, writes a
+// report to a temp file, and tells the user how to file it:
+//
+//	defer crash.Recover()
+//
+// The report holds the stack, versions, the command line, config.toml with
+// secrets redacted, the last warnings from debuglog, and the tail of the
+// shell's operations log. The home directory is written as ~ throughout.
+package crash
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
+	"github.com/mattn/go-isatty"
+
+	"github.com/tw93/mole/internal/config"
+	"github.com/tw93/mole/internal/debuglog"
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/version"
+)
+
+// ExitCode is the status a crashed mole exits with, EX_SOFTWARE from
+// sysexits.h.
+const ExitCode = exitcode.Crash
+
+// IssueURL is where the printed instructions send the user.
+const IssueURL = "https://github.com/tw93/mole/issues/new?template=bug_report.md"
+
+// logTail is how many lines of the operations log a report keeps.
+const logTail = 40
+
+// resetTerminal leaves the alternate screen, shows the cursor, stops mouse
+// reporting, and clears colors: everything a TUI may have switched on.
+const resetTerminal = "\x1b[?1049l\x1b[?25h\x1b[?1000l\x1b[?1002l\x1b[?1003l\x1b[?1006l\x1b[0m"
+
+// saved is the terminal mode from before any TUI made it raw.
+var saved *term.State
+
+func init() {
+	if fd := os.Stdin.Fd(); term.IsTerminal(fd) {
+		saved, _ = term.GetState(fd)
+	}
+}
+
+// Recover reports a panic and exits with ExitCode. It must be deferred
+// directly; with no panic in flight it does nothing.
+func Recover() {
+	value := recover()
+	if value == nil {
+		return
+	}
+	stack := debug.Stack()
+	restoreTerminal()
+
+	home, _ := os.UserHomeDir()
+	r := report{
+		value:   value,
+		stack:   stack,
+		command: os.Args,
+		config:  readFile(config.Path()),
+		recent:  debuglog.Recent(),
+		log:     tail(readFile(filepath.Join(home, "Library", "Logs", "mole", "mole.log")), logTail),
+		home:    home,
+		now:     time.Now(),
+	}
+	fmt.Fprintf(os.Stderr, "\nmole crashed: %v\n", value)
+	path, err := r.write(os.TempDir())
+	if err != nil {
+		// Without a file the report still has to reach the user somehow.
+		fmt.Fprintf(os.Stderr, "Could not save a crash report (%v); here it is:\n\n%s\n", err, r)
+		fmt.Fprintf(os.Stderr, "Please open an issue at %s and paste the report above.\n", IssueURL)
+	} else {
+		fmt.Fprintf(os.Stderr, "A crash report was saved to %s\n", path)
+		fmt.Fprintf(os.Stderr, "Please open an issue at %s and attach it.\n", IssueURL)
+		fmt.Fprintln(os.Stderr, "Secrets in config.toml are redacted, but look it over before you share it.")
+	}
+	os.Exit(ExitCode)
+}
+
+func restoreTerminal() {
+	if saved != nil {
+		term.Restore(os.Stdin.Fd(), saved)
+	}
+	if isatty.IsTerminal(os.Stdout.Fd()) {
+		os.Stdout.WriteString(resetTerminal)
+	}
+}
+
+// Model runs m with every command it returns guarded by Recover. Bubble
+// Tea runs commands on goroutines of their own, where the Recover deferred
+// in main cannot see a panic. Start the program with tea.WithoutCatchPanics
+// so panics in Update and View reach main instead of Bubble Tea's handler.
+func Model(m tea.Model) tea.Model { return model{m} }
+
+type model struct{ next tea.Model }
+
+func (m model) Init() tea.Cmd { return guard(m.next.Init()) }
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.next.Update(msg)
+	return model{next}, guard(cmd)
+}
+
+func (m model) View() string { return m.next.View() }
+
+// guard wraps cmd, and each command of a batch, in Recover.
+func guard(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		defer Recover()
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			for i, c := range batch {
+				batch[i] = guard(c)
+			}
+		}
+		return msg
+	}
+}
+
+// report is everything a crash report says.
+type report struct {
+	value   any
+	stack   []byte
+	command []string
+	config  string
+	recent  []string
+	log     string
+	home    string
+	now     time.Time
+}
+
+// write saves the report as mole-crash-<time>.txt in dir.
+func (r report) write(dir string) (string, error) {
+	f, err := os.CreateTemp(dir, "mole-crash-"+r.now.Format("20060102-150405")+"-*.txt")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(r.String()); err != nil {
+		f.Close()
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+func (r report) String() string {
+	var b strings.Builder
+	section := func(title, body string) {
+		if strings.TrimSpace(body) == "" {
+			body = "(none)"
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", title, strings.TrimRight(body, "\n"))
+	}
+	fmt.Fprintf(&b, "Mole crash report, %s\n\n", r.now.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Version:  mole %s\n", version.Version)
+	fmt.Fprintf(&b, "Go:       %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "Command:  %s\n", strings.Join(r.command, " "))
+	fmt.Fprintf(&b, "Panic:    %v\n", r.value)
+	section("Stack", string(r.stack))
+	section("config.toml", redactConfig(r.config))
+	section("Recent warnings", strings.Join(r.recent, "\n"))
+	section("mole.log (last lines)", r.log)
+
+	out := b.String()
+	if r.home != "" && r.home != "/" {
+		out = strings.ReplaceAll(out, r.home, "~")
+	}
+	return out
+}
+
+// secretKey matches config keys whose values must not leave the machine.
+var secretKey = regexp.MustCompile(`(?i)(token|secret|password|passwd|key|webhook|url)`)
+
+// redactConfig blanks the value of every key = value line whose key looks
+// like it holds a secret. Section headers and comments pass through.
+func redactConfig(text string) string {
+	var b strings.Builder
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for sc.Scan() {
+		line := sc.Text()
+		key, _, found := strings.Cut(line, "=")
+		trimmed := strings.TrimSpace(key)
+		if found && !strings.HasPrefix(trimmed, "#") && secretKey.MatchString(trimmed) {
+			line = key + `= "[redacted]"`
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// tail returns the last n lines of text.
+func tail(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// readFile returns the file at path, or "" when it cannot be read.
+func readFile(path string) string {
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,100 @@
+package crash
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestReportRedactsAndShortensHome(t *testing.T) {
+	r := report{
+		value:   "index out of range [3] with length 3",
+		stack:   []byte("goroutine 1 [running]:\nmain.main()\n\t/Users/ada/src/mole/cmd/mole/main.go:42\n"),
+		command: []string{"/Users/ada/.local/bin/mole", "analyze", "/Users/ada/Downloads"},
+		config: `# Mole settings
+[status]
+interval = "2s"
+webhook_url = "https://hooks.example.com/T000/secret"
+
+[notify]
+api_token="abc123"
+`,
+		recent: []string{"12:00:01 WARN collector failed name=gpu err=exit status 1"},
+		log:    "[2026-10-16 12:00:00] [clean] REMOVED /Users/ada/Library/Caches/foo",
+		home:   "/Users/ada",
+		now:    time.Date(2026, 10, 16, 12, 0, 2, 0, time.UTC),
+	}
+	out := r.String()
+
+	for _, want := range []string{
+		"Panic:    index out of range [3] with length 3",
+		"Command:  ~/.local/bin/mole analyze ~/Downloads",
+		"~/src/mole/cmd/mole/main.go:42",
+		`interval = "2s"`,
+		`webhook_url = "[redacted]"`,
+		`api_token= "[redacted]"`,
+		"WARN collector failed name=gpu",
+		"REMOVED ~/Library/Caches/foo",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report is missing %q:\n%s", want, out)
+		}
+	}
+	for _, leak := range []string{"/Users/ada", "abc123", "hooks.example.com"} {
+		if strings.Contains(out, leak) {
+			t.Errorf("report leaks %q:\n%s", leak, out)
+		}
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	dir := t.TempDir()
+	r := report{value: "boom", now: time.Date(2026, 10, 16, 12, 0, 2, 0, time.UTC)}
+	path, err := r.write(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(path) != dir || !strings.HasPrefix(filepath.Base(path), "mole-crash-20261016-120002-") {
+		t.Fatalf("report written to %s", path)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "## config.toml\n\n(none)") {
+		t.Fatalf("empty sections should say so:\n%s", data)
+	}
+}
+
+func TestTail(t *testing.T) {
+	if got := tail("a\nb\nc\n", 2); got != "b\nc" {
+		t.Fatalf("tail = %q", got)
+	}
+	if got := tail("a\n", 5); got != "a" {
+		t.Fatalf("tail = %q", got)
+	}
+}
+
+type quitModel struct{}
+
+func (quitModel) Init() tea.Cmd                       { return tea.Batch(tea.Quit, tea.Quit) }
+func (quitModel) Update(tea.Msg) (tea.Model, tea.Cmd) { return quitModel{}, nil }
+func (quitModel) View() string                        { return "" }
+
+func TestModelGuardsBatchedCommands(t *testing.T) {
+	m := Model(quitModel{})
+	msg := m.Init()()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("Init() message = %#v, want a batch of two", msg)
+	}
+	for _, cmd := range batch {
+		if _, ok := cmd().(tea.QuitMsg); !ok {
+			t.Fatalf("guarded command lost its message")
+		}
+	}
+	if _, cmd := m.Update(nil); cmd != nil {
+		t.Fatalf("a nil command should stay nil")
+	}
+}
@@ -0,0 +1,171 @@
+// Package debuglog is the --debug/--log-file tracing shared by the analyze
+// and status commands. It records how long collectors and scans take, every
+// external command run, and which caches answered, so a "status is slow"
+// report can come with a log that shows where the time went.
+//
+// Logging is off until Setup enables it; until then every call is a level
+// check against a discarding handler. Warnings are the exception: the last
+// few are always kept for Recent, so a crash report can include them.
+package debuglog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// off is the logger before Setup: it drops everything but keeps warnings
+// for Recent.
+var off = slog.New(recentHandler{slog.DiscardHandler})
+
+var logger = off
+
+// recentSize is how many warnings Recent keeps.
+const recentSize = 50
+
+var recent struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+// Recent returns the last warnings logged in this process, oldest first,
+// whether or not logging was set up.
+func Recent() []string {
+	recent.mu.Lock()
+	defer recent.mu.Unlock()
+	return append([]string(nil), recent.lines...)
+}
+
+// recentHandler remembers warnings and passes every record on to next.
+type recentHandler struct{ next slog.Handler }
+
+func (h recentHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelWarn || h.next.Enabled(ctx, level)
+}
+
+func (h recentHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelWarn {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s %s %s", r.Time.Format(time.TimeOnly), r.Level, r.Message)
+		r.Attrs(func(a slog.Attr) bool {
+			fmt.Fprintf(&b, " %s=%v", a.Key, a.Value)
+			return true
+		})
+		recent.mu.Lock()
+		recent.lines = append(recent.lines, b.String())
+		if len(recent.lines) > recentSize {
+			recent.lines = recent.lines[len(recent.lines)-recentSize:]
+		}
+		recent.mu.Unlock()
+	}
+	if !h.next.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h recentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return recentHandler{h.next.WithAttrs(attrs)}
+}
+
+func (h recentHandler) WithGroup(name string) slog.Handler {
+	return recentHandler{h.next.WithGroup(name)}
+}
+
+// Requested reports whether debug logging was asked for, either by the
+// command's own --debug flag or by `mo --debug`, which the wrapper strips
+// and passes on as MO_DEBUG=1.
+func Requested(flagSet bool) bool {
+	return flagSet || os.Getenv("MO_DEBUG") == "1"
+}
+
+// Setup routes records to path, or to stderr when path is empty. debug
+// enables the per-command and per-cache records; without it only failures
+// and timeouts are logged. With neither set logging stays off. Close the
+// result on exit to release the log file.
+func Setup(debug bool, path string) (io.Closer, error) {
+	if !debug && path == "" {
+		return nopWriteCloser{}, nil
+	}
+	var out io.WriteCloser = nopWriteCloser{os.Stderr}
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, err
+		}
+		out = f
+	}
+	level := slog.LevelInfo
+	if debug {
+		level = slog.LevelDebug
+	}
+	logger = slog.New(recentHandler{slog.NewTextHandler(out, &slog.HandlerOptions{Level: level})})
+	return out, nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// Enabled reports whether debug records are kept, for callers that would
+// otherwise do work just to build one.
+func Enabled() bool {
+	return logger.Enabled(context.Background(), slog.LevelDebug)
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// Command records one external command.
+func Command(name string, args []string, took time.Duration, err error) {
+	if err != nil {
+		logger.Debug("exec", "cmd", name, "args", strings.Join(args, " "), "ms", ms(took), "err", err)
+		return
+	}
+	logger.Debug("exec", "cmd", name, "args", strings.Join(args, " "), "ms", ms(took))
+}
+
+// Collector records one collector run. Failures are logged without --debug.
+func Collector(name string, took time.Duration, err error) {
+	if err != nil {
+		logger.Warn("collector failed", "name", name, "ms", ms(took), "err", err)
+		return
+	}
+	logger.Debug("collector", "name", name, "ms", ms(took))
+}
+
+// Timeout records a collector a refresh stopped waiting for.
+func Timeout(name string, budget time.Duration) {
+	logger.Warn("collector timeout", "name", name, "budget", budget)
+}
+
+// Notify records one notification delivery. Failures are logged without
+// --debug.
+func Notify(channel string, took time.Duration, err error) {
+	if err != nil {
+		logger.Warn("notify failed", "channel", channel, "ms", ms(took), "err", err)
+		return
+	}
+	logger.Debug("notify", "channel", channel, "ms", ms(took))
+}
+
+// Cache records whether a cache answered a read; age is how old the value
+// it served was, zero on a miss.
+func Cache(name string, hit bool, age time.Duration) {
+	if hit {
+		logger.Debug("cache", "name", name, "hit", true, "age_ms", ms(age))
+		return
+	}
+	logger.Debug("cache", "name", name, "hit", false)
+}
+
+// Phase records one stage of a longer operation, such as a directory scan.
+func Phase(name string, took time.Duration, attrs ...any) {
+	logger.Debug(name, append([]any{"ms", ms(took)}, attrs...)...)
+}
@@ -0,0 +1,83 @@
+package debuglog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetupOffByDefault(t *testing.T) {
+	t.Setenv("MO_DEBUG", "")
+	defer func() { logger = off }()
+
+	if _, err := Setup(false, ""); err != nil {
+		t.Fatal(err)
+	}
+	if Enabled() {
+		t.Fatal("logging enabled without --debug or --log-file")
+	}
+	if Requested(false) {
+		t.Fatal("Requested() without flag or MO_DEBUG")
+	}
+	t.Setenv("MO_DEBUG", "1")
+	if !Requested(false) {
+		t.Fatal("MO_DEBUG=1 from `mo --debug` should request debug logging")
+	}
+}
+
+func TestSetupLevels(t *testing.T) {
+	defer func() { logger = off }()
+	path := filepath.Join(t.TempDir(), "status.log")
+
+	// --log-file alone keeps only failures and timeouts.
+	closer, err := Setup(false, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Command("ioreg", []string{"-r"}, 12*time.Millisecond, nil)
+	Collector("gpu", 3*time.Second, errors.New("exit status 1"))
+	closer.Close()
+
+	raw, _ := os.ReadFile(path)
+	log := string(raw)
+	if strings.Contains(log, "msg=exec") || !strings.Contains(log, `msg="collector failed" name=gpu`) {
+		t.Fatalf("info log = %q", log)
+	}
+
+	closer, err = Setup(true, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Command("ioreg", []string{"-r", "-c", "IOAccelerator"}, 12*time.Millisecond, nil)
+	Cache("gpu_info", true, 1500*time.Millisecond)
+	closer.Close()
+
+	raw, _ = os.ReadFile(path)
+	log = string(raw)
+	for _, want := range []string{`msg=exec cmd=ioreg args="-r -c IOAccelerator" ms=12`, "msg=cache name=gpu_info hit=true age_ms=1500"} {
+		if !strings.Contains(log, want) {
+			t.Fatalf("debug log missing %q:\n%s", want, log)
+		}
+	}
+}
+
+func TestRecentKeepsWarningsWhileOff(t *testing.T) {
+	if Enabled() {
+		t.Fatal("debug records enabled before Setup")
+	}
+	Collector("smart", time.Second, errors.New("exit status 2"))
+	Command("ioreg", nil, time.Millisecond, nil)
+
+	lines := Recent()
+	if len(lines) == 0 || !strings.Contains(lines[len(lines)-1], "WARN collector failed name=smart") {
+		t.Fatalf("Recent() = %q", lines)
+	}
+	for _, line := range lines {
+		if strings.Contains(line, "exec") {
+			t.Fatalf("debug record kept: %q", line)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Package exitcode is the exit status every Go mole command ends with, and
+// how it reports the error behind a nonzero one. Wrapper scripts can rely
+// on the codes:
+//
+//	0   ok        done, and the result is complete
+//	1   partial   done, but something is missing or degraded: folders the
+//	              scan could not read, a rule that failed, a check or
+//	              doctor finding
+//	2   usage     a bad flag, argument, or config file; nothing ran
+//	3   failed    a scan, collector, or write failed and left no result
+//	4   denied    not allowed: an unreadable scan root, or a path the
+//	              privileged helper will not touch
+//	70  crash     a panic; see the crash report
+//
+// With --error-format json, or MO_ERROR_FORMAT=json, the error is one JSON
+// object on stderr instead of text:
+//
+//	{"error":{"command":"analyze","code":4,"kind":"denied","message":"..."}}
+package exitcode
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// The exit codes.
+const (
+	OK      = 0
+	Partial = 1
+	Usage   = 2
+	Failed  = 3
+	Denied  = 4
+	Crash   = 70
+)
+
+// kinds names each code in JSON errors.
+var kinds = map[int]string{
+	OK:      "ok",
+	Partial: "partial",
+	Usage:   "usage",
+	Failed:  "failed",
+	Denied:  "denied",
+	Crash:   "crash",
+}
+
+// Kind names code, or returns "unknown".
+func Kind(code int) string {
+	if kind, ok := kinds[code]; ok {
+		return kind
+	}
+	return "unknown"
+}
+
+// Error formats, as --error-format takes them.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var format = FormatText
+
+// AddFlag registers --error-format on fs. Pass its value to SetFormat.
+func AddFlag(fs *flag.FlagSet) *string {
+	return fs.String("error-format", "", "print errors as text or json (defaults to $MO_ERROR_FORMAT, then text)")
+}
+
+// SetFormat picks how Report prints errors: name, or MO_ERROR_FORMAT when
+// name is empty. An unknown name leaves text in place and is a usage
+// error.
+func SetFormat(name string) error {
+	if name == "" {
+		name = os.Getenv("MO_ERROR_FORMAT")
+	}
+	switch name {
+	case "", FormatText:
+		format = FormatText
+	case FormatJSON:
+		format = FormatJSON
+	default:
+		format = FormatText
+		return fmt.Errorf("--error-format: %q must be text or json", name)
+	}
+	return nil
+}
+
+// Report prints err for command to stderr in the chosen format and
+// returns code, for commands that return their exit status.
+func Report(command string, code int, err error) int {
+	write(os.Stderr, command, code, err)
+	return code
+}
+
+// Exit reports err and exits with code.
+func Exit(command string, code int, err error) {
+	os.Exit(Report(command, code, err))
+}
+
+// jsonError is the --error-format json line.
+type jsonError struct {
+	Command string `json:"command"`
+	Code    int    `json:"code"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+func write(w io.Writer, command string, code int, err error) {
+	if err == nil {
+		err = errors.New(Kind(code))
+	}
+	if format != FormatJSON {
+		fmt.Fprintln(w, err)
+		return
+	}
+	line, _ := json.Marshal(map[string]jsonError{"error": {
+		Command: command,
+		Code:    code,
+		Kind:    Kind(code),
+		Message: err.Error(),
+	}})
+	fmt.Fprintf(w, "%s\n", line)
+}
@@ -0,0 +1,58 @@
+package exitcode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestKind(t *testing.T) {
+	for code, want := range map[int]string{OK: "ok", Partial: "partial", Usage: "usage", Failed: "failed", Denied: "denied", Crash: "crash", 9: "unknown"} {
+		if got := Kind(code); got != want {
+			t.Errorf("Kind(%d) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestSetFormat(t *testing.T) {
+	defer SetFormat(FormatText)
+
+	t.Setenv("MO_ERROR_FORMAT", "json")
+	if err := SetFormat(""); err != nil || format != FormatJSON {
+		t.Fatalf("SetFormat from env: format %q, err %v", format, err)
+	}
+	if err := SetFormat("text"); err != nil || format != FormatText {
+		t.Fatalf("--error-format text over env: format %q, err %v", format, err)
+	}
+	if err := SetFormat("yaml"); err == nil || format != FormatText {
+		t.Fatalf("SetFormat(yaml): format %q, err %v", format, err)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	defer SetFormat(FormatText)
+	SetFormat(FormatJSON)
+
+	var buf bytes.Buffer
+	write(&buf, "analyze", Denied, errors.New("open /private/var/db: permission denied"))
+	if bytes.Count(buf.Bytes(), []byte("\n")) != 1 {
+		t.Fatalf("want one line, got %q", buf.String())
+	}
+	var got map[string]jsonError
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := jsonError{Command: "analyze", Code: 4, Kind: "denied", Message: "open /private/var/db: permission denied"}
+	if got["error"] != want {
+		t.Fatalf("got %+v, want %+v", got["error"], want)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	write(&buf, "clean", Usage, errors.New("mole clean: bad rule"))
+	if buf.String() != "mole clean: bad rule\n" {
+		t.Fatalf("got %q", buf.String())
+	}
+}
@@ -0,0 +1,62 @@
+package helper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// auditMaxSize is when the audit log rolls over to helper.log.old, the
+// same limit as operations.log.
+const auditMaxSize = 5 << 20
+
+// audit appends to the helper's log. Lines follow operations.log,
+//
+//	[2026-01-02 15:04:05] [helper] REMOVED /Library/Caches/com.example (uid 501, 12.3 MB)
+//
+// so one grep reads both.
+type audit struct {
+	mu   sync.Mutex
+	path string
+	now  func() time.Time
+}
+
+func newAudit(path string) *audit {
+	return &audit{path: path, now: time.Now}
+}
+
+// record logs one action. A log that cannot be written is reported on
+// stderr, which launchd keeps, but does not stop the daemon.
+func (a *audit) record(action, subject string, uid int, detail string) {
+	line := fmt.Sprintf("[%s] [helper] %s %s (uid %d", a.now().Format(time.DateTime), action, subject, uid)
+	if detail != "" {
+		line += ", " + detail
+	}
+	line += ")\n"
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.append(line); err != nil {
+		fmt.Fprintf(os.Stderr, "mole helper: audit log: %v\n", err)
+	}
+}
+
+func (a *audit) append(line string) error {
+	if info, err := os.Stat(a.path); err == nil && info.Size() > auditMaxSize {
+		os.Rename(a.path, a.path+".old")
+	}
+	if err := os.MkdirAll(filepath.Dir(a.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,201 @@
+//go:build darwin || linux
+
+package helper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/tw93/mole/internal/units"
+	"github.com/tw93/mole/pkg/sysmetrics"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// maxRequest bounds what the daemon reads from a client.
+	maxRequest = 64 << 10
+	// requestTimeout bounds one connection; sizing a large protected
+	// directory is the slowest thing a client can ask for.
+	requestTimeout     = 2 * time.Minute
+	powermetricsBudget = 5 * time.Second
+)
+
+// daemon answers requests from root and from uid, the user who installed
+// the helper.
+type daemon struct {
+	uid       int
+	removable []string
+	audit     *audit
+}
+
+// runDaemon listens on the helper socket until ctx ends. launchd starts it
+// as root and restarts it if it exits.
+func runDaemon(ctx context.Context, uid int) error {
+	if os.Geteuid() != 0 {
+		return errors.New("the helper daemon runs as root; install it with sudo mo helper install")
+	}
+	os.Remove(socketPath)
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(socketPath)
+	// Anyone may connect; serve checks who did.
+	if err := os.Chmod(socketPath, 0o666); err != nil {
+		ln.Close()
+		return err
+	}
+	d := &daemon{uid: uid, removable: removableRoots, audit: newAudit(AuditPath)}
+	return d.serve(ctx, ln)
+}
+
+func (d *daemon) serve(ctx context.Context, ln net.Listener) error {
+	stop := context.AfterFunc(ctx, func() { ln.Close() })
+	defer stop()
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go d.handle(ctx, c)
+	}
+}
+
+func (d *daemon) handle(ctx context.Context, c net.Conn) {
+	defer c.Close()
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	c.SetDeadline(time.Now().Add(requestTimeout))
+
+	var resp Response
+	var req Request
+	uid, err := peerUID(c)
+	switch {
+	case err != nil:
+		resp = Response{Error: fmt.Sprintf("reading caller credentials: %v", err), Refused: true}
+	case json.NewDecoder(io.LimitReader(c, maxRequest)).Decode(&req) != nil:
+		resp = Response{Error: "malformed request"}
+	default:
+		resp = d.do(ctx, uid, req)
+	}
+	json.NewEncoder(c).Encode(resp)
+}
+
+// do runs one request and records it in the audit log.
+func (d *daemon) do(ctx context.Context, uid int, req Request) Response {
+	if uid != 0 && uid != d.uid {
+		d.audit.record("DENIED", req.Op+" "+req.Path, uid, "caller not allowed")
+		return Response{Error: fmt.Sprintf("uid %d may not use the helper", uid), Refused: true}
+	}
+
+	switch req.Op {
+	case OpPing:
+		return Response{}
+	case OpPowermetrics:
+		if runtime.GOOS != "darwin" {
+			return Response{Error: "powermetrics is macOS only", Refused: true}
+		}
+		ctx, cancel := context.WithTimeout(ctx, powermetricsBudget)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "powermetrics", sysmetrics.PowermetricsArgs...).Output()
+		if err != nil {
+			d.audit.record("FAILED", "powermetrics", uid, err.Error())
+			return Response{Error: err.Error()}
+		}
+		d.audit.record("SAMPLED", "powermetrics", uid, "")
+		return Response{Output: string(out)}
+	case OpSize:
+		return d.size(ctx, uid, req.Path)
+	case OpRemove:
+		return d.remove(ctx, uid, req.Path)
+	}
+	d.audit.record("DENIED", req.Op, uid, "unknown operation")
+	return Response{Error: fmt.Sprintf("unknown operation %q", req.Op), Refused: true}
+}
+
+// size measures a tree the caller cannot read. Outside the removable roots
+// it only measures trees the caller owns, so the helper does not reveal
+// the size and layout of other users' folders.
+func (d *daemon) size(ctx context.Context, uid int, path string) Response {
+	resolved, err := resolveParent(path)
+	if err != nil {
+		d.audit.record("DENIED", path, uid, "size: "+err.Error())
+		return Response{Error: err.Error(), Refused: true}
+	}
+	dirfd, err := openDir(filepath.Dir(resolved))
+	if err != nil {
+		d.audit.record("FAILED", path, uid, "size: "+err.Error())
+		return Response{Error: err.Error()}
+	}
+	defer unix.Close(dirfd)
+	name := filepath.Base(resolved)
+	st, err := statAt(dirfd, name)
+	if err != nil {
+		d.audit.record("FAILED", path, uid, "size: "+err.Error())
+		return Response{Error: err.Error()}
+	}
+	if uid != 0 && int(st.Uid) != uid && !within(resolved, d.removable) {
+		d.audit.record("DENIED", path, uid, "size: not owned by the caller")
+		return Response{Error: fmt.Sprintf("%s belongs to another user", path), Refused: true}
+	}
+	size, err := walkAt(ctx, dirfd, name, false)
+	if err != nil {
+		d.audit.record("FAILED", path, uid, "size: "+err.Error())
+		return Response{Error: err.Error()}
+	}
+	d.audit.record("SIZED", path, uid, units.BytesSI(size))
+	return Response{Bytes: size}
+}
+
+// remove deletes a path below the removable roots that is not protected,
+// whoever owns it: the system caches and logs it exists for belong to
+// root. It works from a descriptor for the checked parent directory and
+// never follows a symlink, so nothing swapped in after the check is touched.
+func (d *daemon) remove(ctx context.Context, uid int, path string) Response {
+	resolved, err := checkRemovable(path, d.removable)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return Response{}
+	case err != nil:
+		d.audit.record("DENIED", path, uid, err.Error())
+		return Response{Error: err.Error(), Refused: true}
+	}
+	dirfd, err := openDir(filepath.Dir(resolved))
+	if err != nil {
+		d.audit.record("FAILED", path, uid, err.Error())
+		return Response{Error: err.Error()}
+	}
+	defer unix.Close(dirfd)
+	name := filepath.Base(resolved)
+	st, err := statAt(dirfd, name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return Response{}
+	case err != nil:
+		d.audit.record("FAILED", path, uid, err.Error())
+		return Response{Error: err.Error()}
+	case st.Mode&unix.S_IFMT == unix.S_IFLNK:
+		d.audit.record("DENIED", path, uid, "is a symlink")
+		return Response{Error: fmt.Sprintf("%s is a symlink", path), Refused: true}
+	}
+	size, err := walkAt(ctx, dirfd, name, true)
+	if err != nil {
+		d.audit.record("FAILED", path, uid, err.Error())
+		return Response{Error: err.Error()}
+	}
+	d.audit.record("REMOVED", path, uid, units.BytesSI(size))
+	return Response{Bytes: size}
+}
@@ -0,0 +1,13 @@
+//go:build !darwin && !linux
+
+package helper
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+func runDaemon(context.Context, int) error {
+	return fmt.Errorf("the privileged helper is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,159 @@
+//go:build darwin || linux
+
+package helper
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestDaemonRequests(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	caches := filepath.Join(root, "Caches")
+	if err := os.MkdirAll(filepath.Join(caches, "com.example"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(caches, "com.example", "blob"), make([]byte, 4096), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unix socket paths are short; t.TempDir can exceed the limit on macOS.
+	sockDir, err := os.MkdirTemp("", "mh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(sockDir)
+	old := socketPath
+	socketPath = filepath.Join(sockDir, "s")
+	defer func() { socketPath = old }()
+
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &daemon{uid: os.Getuid(), removable: []string{caches}, audit: newAudit(filepath.Join(root, "helper.log"))}
+	d.audit.now = func() time.Time { return time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC) }
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- d.serve(ctx, ln) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	if !Available() {
+		t.Fatal("Available() = false with the daemon listening")
+	}
+	if _, err := Call(ctx, Request{Op: OpPing}); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	if size, err := Size(ctx, caches); err != nil || size != 4096 {
+		t.Fatalf("Size = %d, %v; want 4096", size, err)
+	}
+	if _, err := Remove(ctx, root); !errors.Is(err, ErrRefused) {
+		t.Fatalf("Remove outside the roots: err = %v, want ErrRefused", err)
+	}
+	if size, err := Remove(ctx, filepath.Join(caches, "com.example")); err != nil || size != 4096 {
+		t.Fatalf("Remove = %d, %v; want 4096", size, err)
+	}
+	if _, err := os.Stat(filepath.Join(caches, "com.example")); !os.IsNotExist(err) {
+		t.Fatalf("cache still there: %v", err)
+	}
+	if resp := d.do(ctx, os.Getuid()+1, Request{Op: OpRemove, Path: caches + "/x"}); !resp.Refused {
+		t.Fatalf("another user's request was not refused: %+v", resp)
+	}
+
+	log, err := os.ReadFile(d.audit.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"[2026-01-02 15:04:05] [helper] SIZED " + caches,
+		"[2026-01-02 15:04:05] [helper] DENIED " + root,
+		"[2026-01-02 15:04:05] [helper] REMOVED " + filepath.Join(caches, "com.example"),
+		"[2026-01-02 15:04:05] [helper] DENIED remove " + caches + "/x",
+	}
+	lines := strings.Split(strings.TrimSpace(string(log)), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("audit log:\n%s\nwant %d lines", log, len(want))
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("audit line %d = %q, want prefix %q", i, lines[i], prefix)
+		}
+	}
+}
+
+func TestDaemonOwnership(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("needs root to create files the caller does not own")
+	}
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	caches := filepath.Join(root, "Caches")
+	theirs := filepath.Join(root, "theirs")
+	for _, dir := range []string{filepath.Join(caches, "com.example"), filepath.Join(caches, "com.apple.coreaudio"), theirs} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(caches, "com.example", "blob"), make([]byte, 4096), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	const uid = 4242
+	d := &daemon{uid: uid, removable: []string{caches}, audit: newAudit(filepath.Join(root, "helper.log"))}
+	ctx := context.Background()
+
+	// Root owns the system caches the helper is there to delete.
+	if resp := d.do(ctx, uid, Request{Op: OpRemove, Path: filepath.Join(caches, "com.example")}); resp.Error != "" || resp.Bytes != 4096 {
+		t.Errorf("removing root's cache for uid %d: %+v", uid, resp)
+	}
+	if _, err := os.Stat(filepath.Join(caches, "com.example")); !os.IsNotExist(err) {
+		t.Errorf("root's cache is still there: %v", err)
+	}
+	if resp := d.do(ctx, uid, Request{Op: OpRemove, Path: filepath.Join(caches, "com.apple.coreaudio")}); !resp.Refused {
+		t.Errorf("removing a protected cache: %+v", resp)
+	}
+	if resp := d.do(ctx, uid, Request{Op: OpSize, Path: theirs}); !resp.Refused {
+		t.Errorf("sizing root's folder for uid %d: %+v", uid, resp)
+	}
+	if resp := d.do(ctx, uid, Request{Op: OpSize, Path: caches}); resp.Error != "" {
+		t.Errorf("sizing a cache root: %+v", resp)
+	}
+}
+
+func TestOpenDirRefusesSymlinks(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	real := filepath.Join(root, "real", "inner")
+	if err := os.MkdirAll(real, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "real"), filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	fd, err := openDir(real)
+	if err != nil {
+		t.Fatalf("openDir(%q): %v", real, err)
+	}
+	unix.Close(fd)
+	if fd, err := openDir(filepath.Join(root, "link", "inner")); err == nil {
+		unix.Close(fd)
+		t.Error("openDir followed a symlink")
+	}
+}
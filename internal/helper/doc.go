@@ -0,0 +1,32 @@
+// Package helper is Mole's optional privileged helper: a root launchd
+// daemon for the few things Mole otherwise needs sudo for. With it,
+// `mo status` reads GPU and power without sudo, analyze can size the
+// system cache and log folders, and the system cleanup in `mo clean`
+// deletes caches and logs through it instead of sudo.
+//
+//	sudo mo helper install    # copy the binary, load the LaunchDaemon
+//	mo helper status          # installed? answering?
+//	mo helper log             # every privileged action it has taken
+//	sudo mo helper uninstall
+//
+// SMJobBless installs a helper that an app bundle embeds and signs; Mole is
+// a command-line tool, so install does by hand what SMJobBless does: it
+// copies the running binary to /Library/PrivilegedHelperTools, where only
+// root can replace it, and loads a plist from /Library/LaunchDaemons.
+//
+// The daemon listens on a Unix socket and answers one JSON request per
+// connection. It only serves root and the user who installed it, checked
+// from the socket's peer credentials, and it only knows three operations:
+//
+//	powermetrics  the fixed sample `mo status` reads GPU and power from
+//	size          the size of a directory the caller cannot read, in the
+//	              system cache and log directories or owned by the caller
+//	remove        delete a path under the system cache and log directories
+//	              that Mole's cleanup does not protect
+//
+// Both walk from a directory descriptor opened without following symlinks,
+// so a link swapped in after a path is checked cannot redirect them.
+//
+// Every request, including refused ones, is appended to AuditPath in the
+// operations.log format, and the log is only writable by root.
+package helper
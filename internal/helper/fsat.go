@@ -0,0 +1,102 @@
+//go:build darwin || linux
+
+package helper
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const dirFlags = unix.O_RDONLY | unix.O_DIRECTORY | unix.O_NOFOLLOW | unix.O_CLOEXEC
+
+// openDir opens the directory at a clean absolute path one component at a
+// time with O_NOFOLLOW, so a symlink swapped into any part of it after the
+// path was checked makes the open fail instead of leading somewhere else.
+func openDir(path string) (int, error) {
+	fd, err := unix.Open("/", dirFlags, 0)
+	if err != nil {
+		return -1, &fs.PathError{Op: "open", Path: "/", Err: err}
+	}
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		if name == "" {
+			continue
+		}
+		next, err := unix.Openat(fd, name, dirFlags, 0)
+		unix.Close(fd)
+		if err != nil {
+			return -1, &fs.PathError{Op: "open", Path: path, Err: err}
+		}
+		fd = next
+	}
+	return fd, nil
+}
+
+// statAt reads name in the directory dirfd without following a symlink.
+func statAt(dirfd int, name string) (unix.Stat_t, error) {
+	var st unix.Stat_t
+	err := unix.Fstatat(dirfd, name, &st, unix.AT_SYMLINK_NOFOLLOW)
+	if err != nil {
+		return st, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return st, nil
+}
+
+// walkAt adds up the regular files at name in dirfd and everything below
+// it, never following a symlink. With remove set it deletes each entry
+// once it is counted, directories after their contents. Entries it cannot
+// open are skipped when sizing and fail the removal.
+func walkAt(ctx context.Context, dirfd int, name string, remove bool) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	st, err := statAt(dirfd, name)
+	if err != nil {
+		return 0, err
+	}
+	if st.Mode&unix.S_IFMT != unix.S_IFDIR {
+		var size int64
+		if st.Mode&unix.S_IFMT == unix.S_IFREG {
+			size = st.Size
+		}
+		if remove {
+			if err := unix.Unlinkat(dirfd, name, 0); err != nil {
+				return 0, &fs.PathError{Op: "unlink", Path: name, Err: err}
+			}
+		}
+		return size, nil
+	}
+
+	fd, err := unix.Openat(dirfd, name, dirFlags, 0)
+	if err != nil {
+		if remove {
+			return 0, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		return 0, nil
+	}
+	dir := os.NewFile(uintptr(fd), name)
+	names, err := dir.Readdirnames(-1)
+	if err != nil && remove {
+		dir.Close()
+		return 0, err
+	}
+	var total int64
+	for _, child := range names {
+		size, err := walkAt(ctx, fd, child, remove)
+		total += size
+		if err != nil && (remove || ctx.Err() != nil) {
+			dir.Close()
+			return total, err
+		}
+	}
+	dir.Close()
+	if remove {
+		if err := unix.Unlinkat(dirfd, name, unix.AT_REMOVEDIR); err != nil {
+			return total, &fs.PathError{Op: "unlink", Path: name, Err: err}
+		}
+	}
+	return total, nil
+}
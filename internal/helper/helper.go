@@ -0,0 +1,112 @@
+package helper
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// Where the helper lives once installed.
+const (
+	Label     = "com.tw93.mole.helper"
+	AuditPath = "/Library/Logs/Mole/helper.log"
+
+	toolPath  = "/Library/PrivilegedHelperTools/" + Label
+	plistPath = "/Library/LaunchDaemons/" + Label + ".plist"
+	errPath   = "/Library/Logs/Mole/helper.err"
+)
+
+// socketPath is where the daemon listens; tests point it elsewhere.
+var socketPath = "/var/run/" + Label + ".sock"
+
+// Operations the daemon understands.
+const (
+	OpPing         = "ping"
+	OpPowermetrics = "powermetrics"
+	OpSize         = "size"
+	OpRemove       = "remove"
+)
+
+// Request is what a client sends, one JSON object per connection.
+type Request struct {
+	Op   string `json:"op"`
+	Path string `json:"path,omitempty"`
+}
+
+// Response is the daemon's answer. Refused is set when the request was
+// turned away by policy rather than failing, so the caller can fall back.
+type Response struct {
+	Output  string `json:"output,omitempty"`
+	Bytes   int64  `json:"bytes,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Refused bool   `json:"refused,omitempty"`
+}
+
+// ErrRefused wraps the error for a request the helper will not do for
+// anyone: a path outside its directories, a symlink, a caller it does not
+// serve.
+var ErrRefused = errors.New("refused by the privileged helper")
+
+// Available reports whether the helper's socket is up. It does not say the
+// helper will serve this user; Call does.
+func Available() bool {
+	info, err := os.Stat(socketPath)
+	return err == nil && info.Mode()&os.ModeSocket != 0
+}
+
+// Call sends req to the helper and waits for its answer or ctx.
+func Call(ctx context.Context, req Request) (Response, error) {
+	var d net.Dialer
+	c, err := d.DialContext(ctx, "unix", socketPath)
+	if err != nil {
+		return Response{}, fmt.Errorf("privileged helper not running: %w", err)
+	}
+	defer c.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		c.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { c.SetDeadline(time.Now()) })
+	defer stop()
+
+	if err := json.NewEncoder(c).Encode(req); err != nil {
+		return Response{}, err
+	}
+	var resp Response
+	if err := json.NewDecoder(bufio.NewReader(c)).Decode(&resp); err != nil {
+		if ctx.Err() != nil {
+			return Response{}, ctx.Err()
+		}
+		return Response{}, fmt.Errorf("privileged helper: %w", err)
+	}
+	switch {
+	case resp.Refused:
+		return resp, fmt.Errorf("%w: %s", ErrRefused, resp.Error)
+	case resp.Error != "":
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// Powermetrics returns the output of powermetrics run with
+// sysmetrics.PowermetricsArgs.
+func Powermetrics(ctx context.Context) (string, error) {
+	resp, err := Call(ctx, Request{Op: OpPowermetrics})
+	return resp.Output, err
+}
+
+// Size returns the bytes under path, counted as root.
+func Size(ctx context.Context, path string) (int64, error) {
+	resp, err := Call(ctx, Request{Op: OpSize, Path: path})
+	return resp.Bytes, err
+}
+
+// Remove deletes path as root and returns the bytes it freed.
+func Remove(ctx context.Context, path string) (int64, error) {
+	resp, err := Call(ctx, Request{Op: OpRemove, Path: path})
+	return resp.Bytes, err
+}
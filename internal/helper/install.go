@@ -0,0 +1,156 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// install copies the running binary to toolPath and loads it as a
+// LaunchDaemon that serves uid.
+func install(uid int) error {
+	if err := checkInstallable(); err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	if err := os.MkdirAll(filepath.Dir(AuditPath), 0o755); err != nil {
+		return err
+	}
+	if err := copyTool(exe, toolPath); err != nil {
+		return fmt.Errorf("copying the helper: %w", err)
+	}
+	if err := os.WriteFile(plistPath, []byte(launchdPlist(uid)), 0o644); err != nil {
+		return err
+	}
+	// A helper from an older install is still loaded; replace it.
+	launchctl("bootout", "system/"+Label)
+	os.Remove(socketPath)
+	if out, err := launchctl("bootstrap", "system", plistPath); err != nil {
+		return fmt.Errorf("launchctl bootstrap: %v: %s", err, out)
+	}
+	if testMode() {
+		return nil
+	}
+	for range 30 {
+		if Available() {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return errors.New("the helper was loaded but its socket never appeared; see " + errPath)
+}
+
+// uninstall unloads the daemon and removes its files. The audit log stays.
+func uninstall() error {
+	if err := checkInstallable(); err != nil {
+		return err
+	}
+	launchctl("bootout", "system/"+Label)
+	for _, path := range []string{plistPath, toolPath, socketPath} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// installed reports whether the LaunchDaemon plist is in place.
+func installed() bool {
+	_, err := os.Stat(plistPath)
+	return err == nil
+}
+
+// launchctl runs launchctl with args. Under MOLE_TEST_MODE or
+// MOLE_TEST_NO_AUTH it does nothing, so tests never load or unload a real
+// system daemon.
+func launchctl(args ...string) ([]byte, error) {
+	if testMode() {
+		return nil, nil
+	}
+	return exec.Command("launchctl", args...).CombinedOutput()
+}
+
+func testMode() bool {
+	return os.Getenv("MOLE_TEST_MODE") == "1" || os.Getenv("MOLE_TEST_NO_AUTH") == "1"
+}
+
+func checkInstallable() error {
+	if runtime.GOOS != "darwin" {
+		return fmt.Errorf("the privileged helper runs under launchd, which %s does not have", runtime.GOOS)
+	}
+	if os.Geteuid() != 0 {
+		return errors.New("run it with sudo")
+	}
+	return nil
+}
+
+// copyTool writes a root-owned copy of src at dst, so a user who can write
+// to the Homebrew or install directory cannot swap the binary root runs.
+func copyTool(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Chown(tmp, 0, 0); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+// launchdPlist is the LaunchDaemon that keeps the helper running for uid.
+func launchdPlist(uid int) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>` + Label + `</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>` + toolPath + `</string>
+		<string>helper</string>
+		<string>run</string>
+		<string>--uid</string>
+		<string>` + strconv.Itoa(uid) + `</string>
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardErrorPath</key>
+	<string>` + errPath + `</string>
+</dict>
+</plist>
+`
+}
@@ -0,0 +1,140 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/units"
+)
+
+// Flags are helper's command-line flags; the verbs take their own.
+var Flags = flag.NewFlagSet("helper", flag.ExitOnError)
+
+func init() { Flags.Usage = usage }
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole helper <command>
+
+Commands:
+  install      Install the privileged helper (run with sudo)
+  uninstall    Unload and remove it (run with sudo)
+  status       Show whether it is installed and answering
+  log          Print the audit log of every privileged action
+  size PATH    Size a directory you cannot read, through the helper
+  remove PATH  Delete a system cache or log, through the helper
+
+The helper is a root LaunchDaemon that samples powermetrics for mo status,
+sizes folders analyze cannot open, and deletes system caches and logs for
+mo clean. Every request it handles is logged to %s.
+`, AuditPath)
+}
+
+// Main runs `mole helper` with args and exits the process. remove exits
+// with exitcode.Denied when the helper refuses the path, so mo clean knows
+// to fall back to sudo.
+func Main(args []string) {
+	Flags.Parse(args)
+	exitcode.SetFormat("")
+	args = Flags.Args()
+	if len(args) == 0 {
+		usage()
+		os.Exit(exitcode.Usage)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch verb := args[0]; {
+	case verb == "install" && len(args) == 1:
+		err = runInstall()
+	case verb == "uninstall" && len(args) == 1:
+		if err = uninstall(); err == nil {
+			fmt.Println("Privileged helper removed. Its audit log stays at " + AuditPath + ".")
+		}
+	case verb == "status" && len(args) == 1:
+		printStatus(ctx)
+	case verb == "log" && len(args) == 1:
+		err = printLog()
+	case verb == "size" && len(args) == 2:
+		var size int64
+		if size, err = Size(ctx, args[1]); err == nil {
+			fmt.Printf("%s\t%s\n", units.BytesSI(size), args[1])
+		}
+	case verb == "remove" && len(args) == 2:
+		_, err = Remove(ctx, args[1])
+	case verb == "run":
+		err = run(ctx, args[1:])
+	default:
+		usage()
+		os.Exit(exitcode.Usage)
+	}
+	if err != nil {
+		code := exitcode.Failed
+		if errors.Is(err, ErrRefused) {
+			code = exitcode.Denied
+		}
+		exitcode.Exit("helper", code, fmt.Errorf("mole helper %s: %w", args[0], err))
+	}
+}
+
+// runInstall installs the helper for the user sudo was run from.
+func runInstall() error {
+	uid, err := strconv.Atoi(os.Getenv("SUDO_UID"))
+	if err != nil || uid == 0 {
+		return errors.New("run it with sudo from the account that will use it")
+	}
+	if err := install(uid); err != nil {
+		return err
+	}
+	fmt.Println("Privileged helper installed. Actions it takes are logged to " + AuditPath + ".")
+	return nil
+}
+
+func printStatus(ctx context.Context) {
+	switch {
+	case !installed():
+		fmt.Println("Privileged helper: not installed (sudo mo helper install)")
+	case !Available():
+		fmt.Println("Privileged helper: installed, not running")
+	default:
+		if _, err := Call(ctx, Request{Op: OpPing}); err != nil {
+			fmt.Printf("Privileged helper: running, not answering: %v\n", err)
+		} else {
+			fmt.Println("Privileged helper: running")
+		}
+	}
+	fmt.Println("Audit log: " + AuditPath)
+}
+
+func printLog() error {
+	data, err := os.ReadFile(AuditPath)
+	if os.IsNotExist(err) {
+		fmt.Println("The helper has not taken any action yet.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// run is the daemon launchd starts: `mole helper run --uid N`.
+func run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("helper run", flag.ContinueOnError)
+	uid := fs.Int("uid", -1, "the user the helper serves besides root")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *uid < 0 {
+		return errors.New("--uid is required")
+	}
+	return runDaemon(ctx, *uid)
+}
@@ -0,0 +1,122 @@
+package helper
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// removableRoots are the directories the helper deletes under: the system
+// caches, logs, and updates `mo clean` removes with sudo. Only paths
+// strictly below a root qualify, never a root itself. World-writable temp
+// directories such as /private/tmp and /private/var/folders are left out:
+// any local user can plant entries there, so root must not delete in them
+// on someone's behalf.
+var removableRoots = []string{
+	"/Library/Caches",
+	"/Library/Logs",
+	"/Library/Updates",
+	"/private/var/log",
+}
+
+// protectedNames are the parts of should_protect_path in
+// lib/core/app_protection.sh that can match below removableRoots: system UI,
+// audio, and account caches whose loss breaks macOS, license and
+// device-management state kept under cache-like names, and Mole's own logs,
+// which hold the helper's audit trail. A path containing one of these,
+// ignoring case, is never removed.
+var protectedNames = []string{
+	"systemsettings",
+	"systempreferences",
+	"controlcenter",
+	"com.apple.settings",
+	"com.apple.notes",
+	"com.apple.finder",
+	"com.apple.dock",
+	"com.apple.coreaudio",
+	"com.apple.audio.",
+	"coreaudiod",
+	"com.apple.containermanagerd",
+	"com.apple.homed",
+	"com.apple.homekit",
+	"com.apple.ap.adprivacyd",
+	"familycircle",
+	"shortcutssandboxcache",
+	"ms-playwright",
+	"com.displaylink.displaylinkuseragent",
+	"com.lasersoft-imaging.silverfast",
+	"adobe",
+	"com.openai.codex",
+	"com.crowdstrike.",
+	"com.sentinelone.",
+	"com.jamf",
+	"/library/logs/mole",
+}
+
+// protected reports whether path matches protectedNames.
+func protected(path string) bool {
+	lower := strings.ToLower(path)
+	for _, name := range protectedNames {
+		if strings.Contains(lower, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRemovable refuses anything but a clean absolute path below one of
+// roots that protectedNames does not cover. The parent directory is
+// resolved first, so a symlink planted inside a cache directory cannot
+// point the delete somewhere else; the delete itself then opens that
+// resolved parent without following links, so swapping one in after this
+// check fails too.
+func checkRemovable(path string, roots []string) (string, error) {
+	resolved, err := resolveParent(path)
+	if err != nil {
+		return "", err
+	}
+	if !under(resolved, roots) {
+		return "", fmt.Errorf("%s is outside the directories the helper cleans", path)
+	}
+	if protected(path) || protected(resolved) {
+		return "", fmt.Errorf("%s is protected", path)
+	}
+	info, err := os.Lstat(resolved)
+	if err != nil {
+		return "", err
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		return "", fmt.Errorf("%s is a symlink", path)
+	}
+	return resolved, nil
+}
+
+// resolveParent checks that path is clean and absolute and returns it with
+// its parent directory's symlinks resolved.
+func resolveParent(path string) (string, error) {
+	if !filepath.IsAbs(path) || filepath.Clean(path) != path || path == "/" || strings.ContainsRune(path, 0) {
+		return "", fmt.Errorf("%q is not a clean absolute path", path)
+	}
+	parent, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(parent, filepath.Base(path)), nil
+}
+
+// within reports whether path is one of roots or below one.
+func within(path string, roots []string) bool {
+	return slices.Contains(roots, path) || under(path, roots)
+}
+
+func under(path string, roots []string) bool {
+	for _, root := range roots {
+		if strings.HasPrefix(path, root+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package helper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckRemovable(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	caches := filepath.Join(root, "Caches")
+	outside := filepath.Join(root, "Documents")
+	for _, dir := range []string{filepath.Join(caches, "com.example"), outside} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(caches, "link")); err != nil {
+		t.Fatal(err)
+	}
+	roots := []string{caches}
+
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{filepath.Join(caches, "com.example"), true},
+		{caches, false},
+		{outside, false},
+		{filepath.Join(caches, "..", "Documents"), false},
+		{"Caches/com.example", false},
+		{filepath.Join(caches, "link"), false},
+		{filepath.Join(caches, "link", "secret"), false},
+		{filepath.Join(caches, "com.apple.coreaudio"), false},
+		{filepath.Join(caches, "Adobe Camera Raw"), false},
+	}
+	for _, tt := range tests {
+		_, err := checkRemovable(tt.path, roots)
+		if (err == nil) != tt.ok {
+			t.Errorf("checkRemovable(%q) = %v, want ok=%v", tt.path, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Package hyperlink wraps paths in OSC 8 escape sequences so terminals that
+// support them (iTerm2, kitty, WezTerm, ...) open the file in Finder on
+// Cmd-click. Terminals that do not understand OSC 8 may print the escape as
+// garbage, so links stay off until a command calls Enable, and the commands
+// only do that for a terminal Supported recognizes.
+package hyperlink
+
+import (
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var enabled bool
+
+// Enable turns links on or off for every later File call.
+func Enable(on bool) { enabled = on }
+
+// Enabled reports whether File emits links.
+func Enabled() bool { return enabled }
+
+// linkPrograms are TERM_PROGRAM values of terminals known to handle OSC 8.
+var linkPrograms = map[string]bool{
+	"iTerm.app": true,
+	"WezTerm":   true,
+	"vscode":    true,
+	"ghostty":   true,
+	"Hyper":     true,
+	"rio":       true,
+}
+
+// Supported guesses from the environment whether the terminal renders OSC 8
+// links. MO_HYPERLINKS=1 or 0 overrides the guess. tmux and screen swallow
+// or mangle the sequence unless configured for it, so they count as
+// unsupported.
+func Supported(getenv func(string) string) bool {
+	if force := getenv("MO_HYPERLINKS"); force != "" {
+		on, err := strconv.ParseBool(force)
+		return err == nil && on
+	}
+	term := getenv("TERM")
+	switch {
+	case term == "dumb", getenv("TMUX") != "", strings.HasPrefix(term, "screen"):
+		return false
+	case linkPrograms[getenv("TERM_PROGRAM")]:
+		return true
+	case strings.Contains(term, "kitty"), getenv("KITTY_WINDOW_ID") != "":
+		return true
+	case getenv("WT_SESSION") != "":
+		return true
+	}
+	// GNOME Terminal and other VTE terminals since 0.50.
+	vte, _ := strconv.Atoi(getenv("VTE_VERSION"))
+	return vte >= 5000
+}
+
+var hostname = sync.OnceValue(func() string {
+	name, _ := os.Hostname()
+	return name
+})
+
+// FileURL is the file:// URL for an absolute path on this machine.
+func FileURL(path string) string {
+	return (&url.URL{Scheme: "file", Host: hostname(), Path: path}).String()
+}
+
+// File returns text linked to path, or text unchanged when links are off or
+// there is no path. The escape takes no columns, so callers pad and trim
+// text before wrapping it.
+func File(path, text string) string {
+	if !enabled || path == "" || text == "" {
+		return text
+	}
+	return "\033]8;;" + FileURL(path) + "\033\\" + text + "\033]8;;\033\\"
+}
@@ -0,0 +1,48 @@
+package hyperlink
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFileWrapsOnlyWhenEnabled(t *testing.T) {
+	defer Enable(false)
+
+	if got := File("/Users/me/Movies", "Movies"); got != "Movies" {
+		t.Fatalf("links off should return the text, got %q", got)
+	}
+
+	Enable(true)
+	got := File("/Users/me/My Movies", "My Movies")
+	if !strings.HasPrefix(got, "\033]8;;file://") || !strings.HasSuffix(got, "My Movies\033]8;;\033\\") {
+		t.Fatalf("File = %q, want an OSC 8 link around the text", got)
+	}
+	if !strings.Contains(got, "/Users/me/My%20Movies\033\\") {
+		t.Fatalf("File should escape the path in the URL, got %q", got)
+	}
+	if got := File("", "Movies"); got != "Movies" {
+		t.Fatalf("no path should return the text, got %q", got)
+	}
+}
+
+func TestSupported(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+	for _, tc := range []struct {
+		vars map[string]string
+		want bool
+	}{
+		{map[string]string{"TERM_PROGRAM": "iTerm.app", "TERM": "xterm-256color"}, true},
+		{map[string]string{"TERM": "xterm-kitty"}, true},
+		{map[string]string{"VTE_VERSION": "7200"}, true},
+		{map[string]string{"TERM_PROGRAM": "Apple_Terminal"}, false},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app", "TMUX": "/tmp/tmux-501/default,1,0"}, false},
+		{map[string]string{"TERM_PROGRAM": "Apple_Terminal", "MO_HYPERLINKS": "1"}, true},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app", "MO_HYPERLINKS": "0"}, false},
+	} {
+		if got := Supported(env(tc.vars)); got != tc.want {
+			t.Errorf("Supported(%v) = %v, want %v", tc.vars, got, tc.want)
+		}
+	}
+}
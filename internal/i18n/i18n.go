@@ -0,0 +1,106 @@
+// Package i18n translates the user-facing strings of the analyze and status
+// TUIs. Messages are keyed by their English text, gettext style, so a call
+// site reads the same as before it was extracted and a string missing from
+// a catalog falls back to English instead of to a key name.
+//
+// Catalogs live in locales/<lang>.json as a flat object from English text to
+// the translation. A format string keeps its verbs, in order, so
+// Tf can fill the translation with the same arguments.
+//
+// Machine-readable output (--json, --watch, check, doctor) is not translated:
+// scripts and bug reports depend on it staying the same everywhere.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// English is the source language; it has no catalog.
+const English = "en"
+
+var (
+	current = English
+	catalog map[string]string
+)
+
+// Languages lists the languages Set accepts, English first.
+func Languages() []string {
+	langs := []string{English}
+	entries, _ := locales.ReadDir("locales")
+	for _, e := range entries {
+		langs = append(langs, strings.TrimSuffix(e.Name(), ".json"))
+	}
+	return langs
+}
+
+// load reads one embedded catalog.
+func load(lang string) (map[string]string, error) {
+	raw, err := locales.ReadFile(path.Join("locales", lang+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var messages map[string]string
+	if err := json.Unmarshal(raw, &messages); err != nil {
+		return nil, fmt.Errorf("locales/%s.json: %w", lang, err)
+	}
+	return messages, nil
+}
+
+// Set switches every later T and Tf call to lang. An unknown language, or
+// one whose catalog does not parse, leaves English in place. It returns the
+// language now in use.
+func Set(lang string) string {
+	current, catalog = English, nil
+	if lang == English || !slices.Contains(Languages(), lang) {
+		return current
+	}
+	if messages, err := load(lang); err == nil {
+		current, catalog = lang, messages
+	}
+	return current
+}
+
+// Current is the language T translates into.
+func Current() string { return current }
+
+// Detect picks the language from MO_LANG, then the POSIX locale variables
+// in their usual order of precedence. "zh_CN.UTF-8" and "zh-Hans" both
+// mean "zh"; C, POSIX, and anything unset mean English.
+func Detect(getenv func(string) string) string {
+	for _, key := range []string{"MO_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := getenv(key)
+		if value == "" {
+			continue
+		}
+		lang, _, _ := strings.Cut(value, ".")
+		lang, _, _ = strings.Cut(lang, "_")
+		lang, _, _ = strings.Cut(lang, "-")
+		lang = strings.ToLower(lang)
+		if lang == "c" || lang == "posix" {
+			return English
+		}
+		return lang
+	}
+	return English
+}
+
+// T returns the translation of msg, or msg when there is none.
+func T(msg string) string {
+	if translated, ok := catalog[msg]; ok && translated != "" {
+		return translated
+	}
+	return msg
+}
+
+// Tf translates format and then formats it like fmt.Sprintf.
+func Tf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
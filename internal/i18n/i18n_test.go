@@ -0,0 +1,67 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+var verb = regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]+)?[a-zA-Z%]`)
+
+func TestCatalogsKeepFormatVerbs(t *testing.T) {
+	for _, lang := range Languages()[1:] {
+		messages, err := load(lang)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(messages) == 0 {
+			t.Fatalf("%s catalog is empty", lang)
+		}
+		for key, translated := range messages {
+			if want, got := verb.FindAllString(key, -1), verb.FindAllString(translated, -1); !slices.Equal(want, got) {
+				t.Errorf("%s: %q has verbs %v, translation %q has %v", lang, key, want, translated, got)
+			}
+		}
+	}
+}
+
+func TestSetAndTranslate(t *testing.T) {
+	defer Set(English)
+
+	if got := Set("zh"); got != "zh" {
+		t.Fatalf("Set(zh) = %q", got)
+	}
+	if got := T("Disk"); got != "磁盘" {
+		t.Fatalf("T(Disk) = %q", got)
+	}
+	if got := Tf("Disk low, %s free", "12G"); got != "磁盘空间不足，可用 12G" {
+		t.Fatalf("Tf = %q", got)
+	}
+	if got := T("not in any catalog"); got != "not in any catalog" {
+		t.Fatalf("missing message should fall back to English, got %q", got)
+	}
+
+	if got := Set("xx"); got != English || T("Disk") != "Disk" {
+		t.Fatalf("unknown language should reset to English, got %q, %q", got, T("Disk"))
+	}
+}
+
+func TestDetect(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+	for _, tc := range []struct {
+		vars map[string]string
+		want string
+	}{
+		{map[string]string{}, English},
+		{map[string]string{"LANG": "zh_CN.UTF-8"}, "zh"},
+		{map[string]string{"LANG": "zh_CN.UTF-8", "LC_ALL": "C"}, English},
+		{map[string]string{"LANG": "en_US.UTF-8", "LC_MESSAGES": "zh-Hans"}, "zh"},
+		{map[string]string{"LANG": "zh_CN.UTF-8", "MO_LANG": "en"}, English},
+	} {
+		if got := Detect(env(tc.vars)); got != tc.want {
+			t.Errorf("Detect(%v) = %q, want %q", tc.vars, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+// Package notify tells the user something happened while they were looking
+// elsewhere: a long scan finished, or a status alert fired. analyze and
+// status share it, and each registers the same flags:
+//
+//	--notify center,bell,webhook
+//	--notify-webhook https://hooks.example.com/...
+//
+// center posts to macOS Notification Center, bell rings the terminal, and
+// webhook POSTs JSON to a URL. Each channel is rate limited on its own, so
+// a burst of alerts rings the bell a few times but posts one webhook;
+// messages inside a channel's quiet period are counted and mentioned in
+// the next one it delivers.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tw93/mole/internal/debuglog"
+)
+
+// Channel names, as --notify takes them.
+const (
+	Center  = "center"
+	Bell    = "bell"
+	Webhook = "webhook"
+)
+
+// Channels lists every channel name.
+var Channels = []string{Center, Bell, Webhook}
+
+// quiet is the least time between two deliveries on a channel.
+var quiet = map[string]time.Duration{
+	Center:  30 * time.Second,
+	Bell:    5 * time.Second,
+	Webhook: 5 * time.Minute,
+}
+
+// sendTimeout bounds one delivery; a hung webhook must not hold a scan's
+// exit or a TUI command for long.
+const sendTimeout = 10 * time.Second
+
+// Message is one notification.
+type Message struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// Notifier delivers messages to the channels it was built with. A nil
+// Notifier sends nothing, so callers need not check whether --notify was
+// given.
+type Notifier struct {
+	mu       sync.Mutex
+	channels []*channel
+	now      func() time.Time
+}
+
+type channel struct {
+	name    string
+	quiet   time.Duration
+	last    time.Time
+	dropped int
+	send    func(ctx context.Context, msg Message) error
+}
+
+// New builds a Notifier for the named channels. webhook is the URL the
+// webhook channel posts to; naming one adds the channel.
+func New(names []string, webhook string) (*Notifier, error) {
+	if webhook != "" && !slices.Contains(names, Webhook) {
+		names = append(names, Webhook)
+	}
+	n := &Notifier{now: time.Now}
+	seen := map[string]bool{}
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		var send func(context.Context, Message) error
+		switch name {
+		case Center:
+			if runtime.GOOS != "darwin" {
+				return nil, fmt.Errorf("notification center is macOS only")
+			}
+			send = sendCenter
+		case Bell:
+			send = sendBell
+		case Webhook:
+			u, err := url.Parse(webhook)
+			if webhook == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, fmt.Errorf("the webhook channel needs an http or https URL in --notify-webhook")
+			}
+			send = func(ctx context.Context, msg Message) error { return sendWebhook(ctx, webhook, msg) }
+		default:
+			return nil, fmt.Errorf("unknown channel %q (want %s)", name, strings.Join(Channels, ", "))
+		}
+		n.channels = append(n.channels, &channel{name: name, quiet: quiet[name], send: send})
+	}
+	return n, nil
+}
+
+// Send delivers msg on every channel outside its quiet period and waits
+// for the deliveries. It returns the errors joined; each is also logged.
+func (n *Notifier) Send(ctx context.Context, msg Message) error {
+	if n == nil {
+		return nil
+	}
+	type delivery struct {
+		ch  *channel
+		msg Message
+	}
+	var due []delivery
+	n.mu.Lock()
+	now := n.now()
+	for _, ch := range n.channels {
+		if !ch.last.IsZero() && now.Sub(ch.last) < ch.quiet {
+			ch.dropped++
+			continue
+		}
+		m := msg
+		if ch.dropped > 0 {
+			m.Body += fmt.Sprintf(" (+%d more)", ch.dropped)
+		}
+		ch.last, ch.dropped = now, 0
+		due = append(due, delivery{ch, m})
+	}
+	n.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	errs := make([]error, len(due))
+	var wg sync.WaitGroup
+	for i, d := range due {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := d.ch.send(ctx, d.msg)
+			debuglog.Notify(d.ch.name, time.Since(start), err)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", d.ch.name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// sendCenter posts msg with osascript. The text goes in as arguments, not
+// spliced into the script, so quotes in a path cannot break out of it.
+func sendCenter(ctx context.Context, msg Message) error {
+	out, err := exec.CommandContext(ctx, "osascript",
+		"-e", "on run argv",
+		"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+		"-e", "end run",
+		msg.Title, msg.Body).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("osascript: %v: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// sendBell rings the controlling terminal, which is still there when
+// stdout and stderr are redirected or a TUI owns them.
+func sendBell(context.Context, Message) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+	_, err = tty.WriteString("\a")
+	return err
+}
+
+// webhookPayload is what the webhook channel posts. text carries both
+// lines, so Slack and Discord style incoming webhooks show it as is.
+type webhookPayload struct {
+	Text string `json:"text"`
+	Message
+	Source string    `json:"source"`
+	Time   time.Time `json:"time"`
+}
+
+func sendWebhook(ctx context.Context, target string, msg Message) error {
+	host, _ := os.Hostname()
+	body, err := json.Marshal(webhookPayload{
+		Text:    msg.Title + ": " + msg.Body,
+		Message: msg,
+		Source:  "mole@" + host,
+		Time:    time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// The URL may hold a token; report the host only.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("posting to %s: %w", req.URL.Host, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s answered %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
+
+// Flags are the notification flags of one command.
+type Flags struct {
+	channels *string
+	webhook  *string
+}
+
+// AddFlags registers --notify and --notify-webhook on fs.
+func AddFlags(fs *flag.FlagSet) Flags {
+	return Flags{
+		channels: fs.String("notify", "", "comma-separated channels to notify on: center, bell, webhook"),
+		webhook:  fs.String("notify-webhook", "", "POST notifications as JSON to this `url` (adds the webhook channel)"),
+	}
+}
+
+// Notifier builds the Notifier the flags ask for, or nil when they ask for
+// none.
+func (f Flags) Notifier() (*Notifier, error) {
+	var names []string
+	for _, name := range strings.Split(*f.channels, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 && *f.webhook == "" {
+		return nil, nil
+	}
+	n, err := New(names, *f.webhook)
+	if err != nil {
+		return nil, fmt.Errorf("--notify: %w", err)
+	}
+	return n, nil
+}
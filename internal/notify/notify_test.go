@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendRateLimitsEachChannel(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	got := map[string][]string{}
+	record := func(name string) func(context.Context, Message) error {
+		return func(_ context.Context, msg Message) error {
+			got[name] = append(got[name], msg.Body)
+			return nil
+		}
+	}
+	n := &Notifier{
+		now: func() time.Time { return now },
+		channels: []*channel{
+			{name: "fast", quiet: 5 * time.Second, send: record("fast")},
+			{name: "slow", quiet: time.Minute, send: record("slow")},
+		},
+	}
+	for i, body := range []string{"a", "b", "c", "d"} {
+		if err := n.Send(context.Background(), Message{Title: "t", Body: body}); err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 {
+			now = now.Add(10 * time.Second)
+		}
+		if i == 2 {
+			now = now.Add(time.Minute)
+		}
+	}
+	if want := []string{"a", "c (+1 more)", "d"}; strings.Join(got["fast"], ",") != strings.Join(want, ",") {
+		t.Errorf("fast channel got %q, want %q", got["fast"], want)
+	}
+	if want := []string{"a", "d (+2 more)"}; strings.Join(got["slow"], ",") != strings.Join(want, ",") {
+		t.Errorf("slow channel got %q, want %q", got["slow"], want)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var payload map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer srv.Close()
+
+	n, err := New(nil, srv.URL+"/hook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Send(context.Background(), Message{Title: "Scan finished", Body: "~/Projects: 12 GB in 20m"}); err != nil {
+		t.Fatal(err)
+	}
+	if payload["text"] != "Scan finished: ~/Projects: 12 GB in 20m" || payload["title"] != "Scan finished" {
+		t.Fatalf("payload = %v", payload)
+	}
+}
+
+func TestWebhookErrorHidesURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	n, _ := New([]string{Webhook}, srv.URL+"/T000/secret-token")
+	err := n.Send(context.Background(), Message{Title: "t"})
+	if err == nil || !strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "secret-token") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestFlags(t *testing.T) {
+	for _, tc := range []struct {
+		args    []string
+		want    int
+		wantErr string
+	}{
+		{nil, 0, ""},
+		{[]string{"--notify", "bell, bell"}, 1, ""},
+		{[]string{"--notify-webhook", "https://hooks.example.com/x"}, 1, ""},
+		{[]string{"--notify", "bell,webhook", "--notify-webhook", "https://hooks.example.com/x"}, 2, ""},
+		{[]string{"--notify", "webhook"}, 0, "needs an http or https URL"},
+		{[]string{"--notify-webhook", "file:///etc/passwd"}, 0, "needs an http or https URL"},
+		{[]string{"--notify", "pager"}, 0, `unknown channel "pager"`},
+	} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		f := AddFlags(fs)
+		if err := fs.Parse(tc.args); err != nil {
+			t.Fatal(err)
+		}
+		n, err := f.Notifier()
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("%v: err = %v, want %q", tc.args, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: %v", tc.args, err)
+			continue
+		}
+		count := 0
+		if n != nil {
+			count = len(n.channels)
+		}
+		if count != tc.want {
+			t.Errorf("%v: %d channels, want %d", tc.args, count, tc.want)
+		}
+	}
+}
+
+func TestNilNotifier(t *testing.T) {
+	var n *Notifier
+	if err := n.Send(context.Background(), Message{Title: "t"}); err != nil {
+		t.Fatal(err)
+	}
+}
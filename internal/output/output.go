@@ -0,0 +1,129 @@
+// Package output sends a command's report to the file --output names
+// instead of stdout, so progress and warnings on the terminal stay out of
+// it. The file appears all at once: the report is written to a temp file
+// beside the target and renamed over it on Close, so a reader never sees
+// half a report and a failed run leaves the previous one in place. A name
+// ending in .gz is gzipped.
+//
+//	out, err := output.Create(output.Path(*outputFlag))
+//	...
+//	if err := encode(out); err != nil {
+//		out.Abort()
+//		...
+//	}
+//	err = out.Close()
+package output
+
+import (
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AddFlag registers --output on fs. Pass its value to Path.
+func AddFlag(fs *flag.FlagSet) *string {
+	return fs.String("output", "", "write the report to `file` instead of stdout, replacing it only when complete; gzipped if it ends in .gz (defaults to $MO_OUTPUT)")
+}
+
+// Path returns name, or MO_OUTPUT when name is empty. "" and "-" both
+// mean stdout.
+func Path(name string) string {
+	if name == "" {
+		name = os.Getenv("MO_OUTPUT")
+	}
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// File is where a report goes: stdout, or a temp file that becomes path on
+// Close.
+type File struct {
+	path string
+	tmp  *os.File
+	gz   *gzip.Writer
+	w    io.Writer
+	done bool
+}
+
+// Create opens the destination for path, stdout when path is empty.
+func Create(path string) (*File, error) {
+	if path == "" {
+		return &File{w: os.Stdout}, nil
+	}
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("--output: %w", err)
+	}
+	f := &File{path: path, tmp: tmp, w: tmp}
+	if strings.HasSuffix(path, ".gz") {
+		f.gz = gzip.NewWriter(tmp)
+		f.w = f.gz
+	}
+	return f, nil
+}
+
+// Stdout reports whether the report goes to stdout.
+func (f *File) Stdout() bool { return f.tmp == nil }
+
+func (f *File) Write(p []byte) (int, error) { return f.w.Write(p) }
+
+// Close finishes the report and moves it into place. A file that already
+// existed keeps its permissions; a new one is 0644. On error the target is
+// left as it was.
+func (f *File) Close() error {
+	if f.tmp == nil || f.done {
+		return nil
+	}
+	f.done = true
+	err := f.finish()
+	if err != nil {
+		os.Remove(f.tmp.Name())
+		return fmt.Errorf("--output: %w", err)
+	}
+	return nil
+}
+
+func (f *File) finish() error {
+	if f.gz != nil {
+		if err := f.gz.Close(); err != nil {
+			f.tmp.Close()
+			return err
+		}
+	}
+	if err := f.tmp.Sync(); err != nil {
+		f.tmp.Close()
+		return err
+	}
+	if err := f.tmp.Close(); err != nil {
+		return err
+	}
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(f.path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(f.tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(f.tmp.Name(), f.path)
+}
+
+// Abort drops an unfinished report and leaves the target alone. After
+// Close it does nothing.
+func (f *File) Abort() {
+	if f.tmp == nil || f.done {
+		return
+	}
+	f.done = true
+	f.tmp.Close()
+	os.Remove(f.tmp.Name())
+}
@@ -0,0 +1,99 @@
+package output
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCloseReplacesTargetAtOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := os.WriteFile(path, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(f, `{"total_size":1}`)
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Fatalf("target changed before Close: %q", data)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != `{"total_size":1}` {
+		t.Fatalf("target = %q, %v", data, err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want the old file's 0600", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("temp file left behind: %v", entries)
+	}
+}
+
+func TestAbortKeepsTarget(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.json")
+	os.WriteFile(path, []byte("old"), 0o644)
+
+	f, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(f, "half a rep")
+	f.Abort()
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close after Abort: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Fatalf("target = %q, want it untouched", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("temp file left behind: %v", entries)
+	}
+}
+
+func TestGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.json.gz")
+	f, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(f, "hello")
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	in, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	zr, err := gzip.NewReader(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("gunzipped = %q, %v", data, err)
+	}
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv("MO_OUTPUT", "env.json")
+	for name, want := range map[string]string{"": "env.json", "flag.json": "flag.json", "-": ""} {
+		if got := Path(name); got != want {
+			t.Errorf("Path(%q) = %q, want %q", name, got, want)
+		}
+	}
+	f, err := Create("")
+	if err != nil || !f.Stdout() {
+		t.Fatalf("Create(\"\") = %v, %v; want stdout", f, err)
+	}
+}
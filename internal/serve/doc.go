@@ -0,0 +1,29 @@
+// Package serve is `mole serve --stdio` and `mole mcp`: JSON-RPC 2.0 on
+// stdin and stdout, one message per line, so editors, launchers, scripts,
+// and AI assistants can run scans and read metrics without scraping the
+// TUIs.
+//
+//	→ {"jsonrpc":"2.0","id":1,"method":"scan.start","params":{"path":"/Users/me/Projects"}}
+//	← {"jsonrpc":"2.0","id":1,"result":{"id":"1"}}
+//	→ {"jsonrpc":"2.0","id":2,"method":"scan.progress","params":{"id":"1"}}
+//	← {"jsonrpc":"2.0","id":2,"result":{"id":"1","state":"running","files":18231,...}}
+//	← {"jsonrpc":"2.0","method":"scan.finished","params":{"id":"1","state":"done"}}
+//	→ {"jsonrpc":"2.0","id":3,"method":"scan.result","params":{"id":"1"}}
+//
+// Methods:
+//
+//	scan.start     {path, max_entries?, exclude?} → {id}; the scan runs in the background
+//	scan.progress  {id} → {id, path, state, files, dirs, bytes, current, elapsed_ms}
+//	scan.result    {id} → the `mole analyze --json` shape, once the scan is done
+//	scan.cancel    {id} → {}
+//	metrics.read   {fast?} → the `mole status --json` snapshot
+//
+// Requests are handled concurrently, so a slow metrics.read does not hold
+// up scan.progress; match responses by id. When stdin closes the server
+// answers the requests already read, cancels the scans still running, and
+// exits.
+//
+// `mole mcp` speaks the Model Context Protocol over the same transport,
+// with the read-only tools scan_path, get_large_files, and
+// get_system_status.
+package serve
@@ -0,0 +1,117 @@
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/helper"
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+// Flags are serve's command-line flags.
+var Flags = flag.NewFlagSet("serve", flag.ExitOnError)
+
+var stdio = Flags.Bool("stdio", false, "speak JSON-RPC 2.0 on stdin and stdout, one message per line")
+
+func init() { Flags.Usage = usage }
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole serve --stdio
+
+Runs scans and reads metrics for another program over JSON-RPC 2.0, one
+message per line: scan.start, scan.progress, scan.result, scan.cancel, and
+metrics.read. Exits when stdin closes.
+
+`)
+	Flags.PrintDefaults()
+}
+
+// Main runs serve with args and exits the process when stdin closes.
+func Main(args []string) {
+	Flags.Parse(args)
+	exitcode.SetFormat("")
+	if !*stdio {
+		exitcode.Exit("serve", exitcode.Usage, errors.New("mole serve: --stdio is the only transport"))
+	}
+	s := newServer(&conn{out: os.Stdout})
+	err := s.conn.serve(context.Background(), os.Stdin, s.methods())
+	s.close()
+	if err != nil {
+		exitcode.Exit("serve", exitcode.Failed, fmt.Errorf("mole serve: %w", err))
+	}
+}
+
+// server holds what outlives one request: the scans and the metrics
+// collector, whose rates need the previous sample.
+type server struct {
+	conn  *conn
+	scans *scans
+
+	metricsMu sync.Mutex
+	collector *sysmetrics.Collector
+}
+
+func newServer(c *conn) *server {
+	return &server{conn: c, scans: newScans(c)}
+}
+
+func (s *server) methods() map[string]handler {
+	return map[string]handler{
+		"scan.start":    s.scans.start,
+		"scan.progress": s.scans.progress,
+		"scan.result":   s.scans.result,
+		"scan.cancel":   s.scans.cancel,
+		"metrics.read":  s.readMetrics,
+	}
+}
+
+// close cancels the scans still running and waits for them.
+func (s *server) close() {
+	s.scans.close()
+	s.metricsMu.Lock()
+	defer s.metricsMu.Unlock()
+	if s.collector != nil {
+		s.collector.Close()
+	}
+}
+
+type metricsParams struct {
+	// Fast reads only the per-second metrics, filling the rest from the
+	// last full read. The first read is always full.
+	Fast bool `json:"fast"`
+}
+
+func (s *server) readMetrics(ctx context.Context, params json.RawMessage) (any, error) {
+	var p metricsParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	return s.metrics(ctx, p.Fast)
+}
+
+// metrics returns a snapshot even when some collectors failed; its
+// collectors list says which.
+func (s *server) metrics(ctx context.Context, fast bool) (sysmetrics.MetricsSnapshot, error) {
+	s.metricsMu.Lock()
+	defer s.metricsMu.Unlock()
+	if s.collector == nil {
+		s.collector = sysmetrics.NewCollector(sysmetrics.ProcessWatchOptions{})
+		if helper.Available() {
+			s.collector.SetPowermetricsRunner(helper.Powermetrics)
+		}
+		fast = false
+	}
+	var snap sysmetrics.MetricsSnapshot
+	if fast {
+		snap, _ = s.collector.CollectFast(ctx)
+	} else {
+		snap, _ = s.collector.Collect(ctx)
+	}
+	return snap, ctx.Err()
+}
@@ -0,0 +1,284 @@
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/units"
+	"github.com/tw93/mole/internal/version"
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+// MCPFlags are mcp's command-line flags; it takes none of its own.
+var MCPFlags = flag.NewFlagSet("mcp", flag.ExitOnError)
+
+func init() { MCPFlags.Usage = mcpUsage }
+
+func mcpUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: mole mcp
+
+Runs a Model Context Protocol server on stdin and stdout, so a local AI
+assistant can call scan_path, get_large_files, and get_system_status to
+answer questions such as what is filling the disk. Register it in the
+assistant's MCP settings with the command "mo" and the argument "mcp".
+`)
+}
+
+// MCPMain runs the MCP server with args and exits the process when stdin
+// closes.
+func MCPMain(args []string) {
+	MCPFlags.Parse(args)
+	exitcode.SetFormat("")
+	if MCPFlags.NArg() > 0 {
+		mcpUsage()
+		os.Exit(exitcode.Usage)
+	}
+	s := newServer(&conn{out: os.Stdout})
+	err := s.conn.serve(context.Background(), os.Stdin, s.mcpMethods())
+	s.close()
+	if err != nil {
+		exitcode.Exit("mcp", exitcode.Failed, fmt.Errorf("mole mcp: %w", err))
+	}
+}
+
+// mcpVersions are the protocol revisions this server speaks, newest first.
+var mcpVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// Result limits: the defaults, and the most a call may ask for.
+// pkg/diskscan keeps only the 20 largest files of a scan.
+const (
+	defaultScanLimit = 30
+	maxScanLimit     = 200
+	maxLargeFiles    = 20
+)
+
+// mcpTool is one tool a client can list and call. call returns the text
+// the assistant reads and, optionally, the same data as a JSON object.
+type mcpTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+
+	call func(ctx context.Context, s *server, args json.RawMessage) (text string, structured any, err error)
+}
+
+var pathSchema = map[string]any{
+	"type":        "string",
+	"description": "Directory to scan; ~ is the home directory.",
+}
+
+func limitSchema(def, most int) map[string]any {
+	return map[string]any{
+		"type":        "integer",
+		"description": fmt.Sprintf("How many to return (default %d, at most %d).", def, most),
+		"minimum":     1,
+		"maximum":     most,
+	}
+}
+
+var mcpTools = []mcpTool{
+	{
+		Name:        "scan_path",
+		Description: "Size the folders and files directly inside a directory, largest first, the way `mole analyze` does. Start at ~ or / to see what is filling the disk, then scan the biggest entries.",
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"path": pathSchema, "limit": limitSchema(defaultScanLimit, maxScanLimit)},
+			"required":   []string{"path"},
+		},
+		call: callScanPath,
+	},
+	{
+		Name:        "get_large_files",
+		Description: "List the largest single files anywhere under a directory, largest first.",
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"path": pathSchema, "limit": limitSchema(maxLargeFiles, maxLargeFiles)},
+			"required":   []string{"path"},
+		},
+		call: callLargeFiles,
+	},
+	{
+		Name:        "get_system_status",
+		Description: "Read the machine's health: CPU, memory, disk space per volume, Trash size, and the busiest processes, as `mole status` shows them.",
+		InputSchema: map[string]any{"type": "object", "properties": map[string]any{}},
+		call:        callSystemStatus,
+	},
+}
+
+func (s *server) mcpMethods() map[string]handler {
+	return map[string]handler{
+		"initialize": mcpInitialize,
+		"ping":       func(context.Context, json.RawMessage) (any, error) { return struct{}{}, nil },
+		"tools/list": func(context.Context, json.RawMessage) (any, error) {
+			return map[string]any{"tools": mcpTools}, nil
+		},
+		"tools/call": s.callTool,
+	}
+}
+
+func mcpInitialize(_ context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := decodeMCPParams(params, &p); err != nil {
+		return nil, err
+	}
+	// Answer with the client's revision when we speak it, else our newest
+	// and let the client decide.
+	protocol := mcpVersions[0]
+	if slices.Contains(mcpVersions, p.ProtocolVersion) {
+		protocol = p.ProtocolVersion
+	}
+	return map[string]any{
+		"protocolVersion": protocol,
+		"capabilities":    map[string]any{"tools": map[string]any{}},
+		"serverInfo":      map[string]string{"name": "mole", "version": version.Version},
+		"instructions":    "Mole reads disk usage and system health on this machine. Tools only read; nothing is deleted.",
+	}, nil
+}
+
+// decodeMCPParams reads a request's own params. Unlike decodeParams it
+// ignores unknown fields, since clients add _meta and newer fields.
+func decodeMCPParams(params json.RawMessage, v any) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return errorf(codeInvalidParams, "params: %v", err)
+	}
+	return nil
+}
+
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type mcpCallResult struct {
+	Content           []mcpContent `json:"content"`
+	StructuredContent any          `json:"structuredContent,omitempty"`
+	IsError           bool         `json:"isError,omitempty"`
+}
+
+// callTool runs a tool. A tool that fails returns its error as text with
+// isError set, as MCP asks, so the assistant can read it and try again;
+// only an unknown tool is a protocol error.
+func (s *server) callTool(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := decodeMCPParams(params, &p); err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(mcpTools, func(t mcpTool) bool { return t.Name == p.Name })
+	if i < 0 {
+		return nil, errorf(codeInvalidParams, "no tool %q", p.Name)
+	}
+	text, structured, err := mcpTools[i].call(ctx, s, p.Arguments)
+	if err != nil {
+		return mcpCallResult{Content: []mcpContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	return mcpCallResult{Content: []mcpContent{{Type: "text", Text: text}}, StructuredContent: structured}, nil
+}
+
+type scanArgs struct {
+	Path  string `json:"path"`
+	Limit int    `json:"limit"`
+}
+
+func (a *scanArgs) decode(args json.RawMessage, def, most int) (string, error) {
+	if err := decodeParams(args, a); err != nil {
+		return "", err
+	}
+	if a.Limit <= 0 {
+		a.Limit = def
+	}
+	a.Limit = min(a.Limit, most)
+	return scanPath(a.Path)
+}
+
+func callScanPath(ctx context.Context, _ *server, args json.RawMessage) (string, any, error) {
+	var a scanArgs
+	path, err := a.decode(args, defaultScanLimit, maxScanLimit)
+	if err != nil {
+		return "", nil, err
+	}
+	result, err := scanOnce(ctx, path, a.Limit)
+	if err != nil {
+		return "", nil, err
+	}
+	result.LargeFiles = nil // get_large_files answers that
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s in %d files\n", result.Path, units.BytesSI(result.TotalSize), result.TotalFiles)
+	for _, e := range result.Entries {
+		name := e.Name
+		if e.IsDir {
+			name += "/"
+		}
+		fmt.Fprintf(&b, "%10s  %s\n", units.BytesSI(e.Size), name)
+	}
+	return b.String(), result, nil
+}
+
+func callLargeFiles(ctx context.Context, _ *server, args json.RawMessage) (string, any, error) {
+	var a scanArgs
+	path, err := a.decode(args, maxLargeFiles, maxLargeFiles)
+	if err != nil {
+		return "", nil, err
+	}
+	result, err := scanOnce(ctx, path, defaultScanLimit)
+	if err != nil {
+		return "", nil, err
+	}
+	files := result.LargeFiles[:min(len(result.LargeFiles), a.Limit)]
+	if len(files) == 0 {
+		return fmt.Sprintf("No large files found under %s.\n", path), map[string]any{"path": path, "files": []scanFile{}}, nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Largest files under %s:\n", path)
+	for _, f := range files {
+		fmt.Fprintf(&b, "%10s  %s\n", units.BytesSI(f.Size), f.Path)
+	}
+	return b.String(), map[string]any{"path": path, "files": files}, nil
+}
+
+// callSystemStatus summarizes a snapshot in text; the full snapshot is
+// `mole status --json`, too large to hand an assistant whole.
+func callSystemStatus(ctx context.Context, s *server, _ json.RawMessage) (string, any, error) {
+	snap, err := s.metrics(ctx, false)
+	if err != nil {
+		return "", nil, err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s, %s, up %s\n", snap.Host, snap.Platform, snap.Uptime)
+	fmt.Fprintf(&b, "Health: %d/100 %s\n", snap.HealthScore, snap.HealthScoreMsg)
+	fmt.Fprintf(&b, "CPU: %.0f%% used, load %.2f %.2f %.2f on %d cores\n", snap.CPU.Usage, snap.CPU.Load1, snap.CPU.Load5, snap.CPU.Load15, snap.CPU.LogicalCPU)
+	fmt.Fprintf(&b, "Memory: %s of %s used (%.0f%%)", units.BytesSI(int64(snap.Memory.Used)), units.BytesSI(int64(snap.Memory.Total)), snap.Memory.UsedPercent)
+	if snap.Memory.Pressure != "" {
+		fmt.Fprintf(&b, ", pressure %s", snap.Memory.Pressure)
+	}
+	b.WriteString("\n")
+	for _, d := range snap.Disks {
+		fmt.Fprintf(&b, "Disk %s: %s of %s used (%.0f%%), %s free\n", d.Mount,
+			units.BytesSI(int64(d.Used)), units.BytesSI(int64(d.Total)), d.UsedPercent, units.BytesSI(int64(d.Total-d.Used)))
+	}
+	if snap.TrashSize > 0 {
+		fmt.Fprintf(&b, "Trash: %s\n", units.BytesSI(int64(snap.TrashSize)))
+	}
+	procs := sysmetrics.SortProcesses(snap.TopProcesses, sysmetrics.ProcessSortCPU)
+	if len(procs) > 0 {
+		b.WriteString("Busiest processes:\n")
+		for _, p := range procs[:min(len(procs), 5)] {
+			fmt.Fprintf(&b, "  %5.1f%% CPU  %s (pid %d)\n", p.CPU, p.Name, p.PID)
+		}
+	}
+	return b.String(), nil, nil
+}
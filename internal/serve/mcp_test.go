@@ -0,0 +1,54 @@
+package serve
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMCPHandshakeAndTools(t *testing.T) {
+	c := newTestClient(t)
+	s := newServer(c.conn)
+	defer s.close()
+
+	missing := filepath.Join(t.TempDir(), "missing")
+	c.send(s.mcpMethods(),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"rm_rf","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"scan_path","arguments":{"path":"`+missing+`"},"_meta":{"progressToken":1}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"ping"}`,
+	)
+	r, _ := c.messages()
+
+	if got := r["1"].Result.(map[string]any)["protocolVersion"]; got != "2024-11-05" {
+		t.Errorf("initialize echoed %v, want the client's 2024-11-05", got)
+	}
+	if got := r["2"].Result.(map[string]any)["protocolVersion"]; got != mcpVersions[0] {
+		t.Errorf("initialize with an unknown revision = %v, want %s", got, mcpVersions[0])
+	}
+
+	var names []string
+	for _, tool := range r["3"].Result.(map[string]any)["tools"].([]any) {
+		names = append(names, tool.(map[string]any)["name"].(string))
+	}
+	if got := strings.Join(names, ","); got != "scan_path,get_large_files,get_system_status" {
+		t.Errorf("tools = %s", got)
+	}
+
+	if r["4"].Error == nil || r["4"].Error.Code != codeInvalidParams {
+		t.Errorf("unknown tool = %+v, want invalid params", r["4"])
+	}
+	call, _ := r["5"].Result.(map[string]any)
+	if call["isError"] != true || !strings.Contains(call["content"].([]any)[0].(map[string]any)["text"].(string), "does not exist") {
+		t.Errorf("scan of a missing path = %+v, want an isError result", r["5"])
+	}
+	if r["6"].Error != nil {
+		t.Errorf("ping = %+v", r["6"])
+	}
+	if len(r) != 6 {
+		t.Errorf("got %d replies, want 6 (none for the notification)", len(r))
+	}
+}
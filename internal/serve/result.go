@@ -0,0 +1,78 @@
+package serve
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// scanResult matches `mole analyze --json` for a directory.
+type scanResult struct {
+	Path        string       `json:"path"`
+	Entries     []scanEntry  `json:"entries"`
+	LargeFiles  []scanFile   `json:"large_files,omitempty"`
+	SparseFiles []sparseFile `json:"sparse_files,omitempty"`
+	Ages        *scanAges    `json:"ages,omitempty"`
+	TotalSize   int64        `json:"total_size"`
+	TotalFiles  int64        `json:"total_files,omitempty"`
+}
+
+type scanEntry struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Size       int64  `json:"size"`
+	IsDir      bool   `json:"is_dir"`
+	LastAccess string `json:"last_access,omitempty"`
+}
+
+type scanFile struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+type scanAges struct {
+	Under30Days int64 `json:"under_30_days"`
+	To90Days    int64 `json:"30_to_90_days"`
+	To1Year     int64 `json:"90_days_to_1_year"`
+	To3Years    int64 `json:"1_to_3_years"`
+	Over3Years  int64 `json:"over_3_years"`
+}
+
+type sparseFile struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Logical int64  `json:"logical_size"`
+}
+
+// scanPath resolves a requested path, expanding a leading ~/, and checks
+// that it is a directory.
+func scanPath(path string) (string, error) {
+	if path == "" {
+		return "", errorf(codeInvalidParams, "params: path is required")
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", errorf(codeInvalidParams, "path %q: %v", path, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", errorf(codeInvalidParams, "path %q does not exist", path)
+		}
+		return "", errorf(codeInvalidParams, "path %q: %v", path, err)
+	}
+	if !info.IsDir() {
+		return "", errorf(codeInvalidParams, "path %q is not a directory", path)
+	}
+	return abs, nil
+}
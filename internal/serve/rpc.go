@@ -0,0 +1,161 @@
+package serve
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// maxMessage bounds one request line.
+const maxMessage = 1 << 20
+
+// JSON-RPC error codes: the spec's, then this server's own.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+
+	codeNoScan          = -32001
+	codeScanRunning     = -32002
+	codeScanUnsupported = -32003
+	codeScanCanceled    = -32004
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+// rpcError is an error a handler reports as is; any other error becomes
+// an internal error.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+func errorf(code int, format string, args ...any) *rpcError {
+	return &rpcError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// handler answers one method. Its result must not be nil.
+type handler func(ctx context.Context, params json.RawMessage) (any, error)
+
+// conn reads requests and writes responses and notifications, one JSON
+// value per line. Writes are serialized, since handlers answer from their
+// own goroutines.
+type conn struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (c *conn) write(v any) {
+	line, err := json.Marshal(v)
+	if err != nil {
+		line, _ = json.Marshal(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: errorf(codeInternalError, "encoding reply: %v", err)})
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.out.Write(append(line, '\n'))
+}
+
+func (c *conn) notify(method string, params any) {
+	c.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (c *conn) reply(id json.RawMessage, result any, err error) {
+	if id == nil {
+		return // a notification gets no reply, not even an error
+	}
+	resp := response{JSONRPC: "2.0", ID: id}
+	if err != nil {
+		var rpcErr *rpcError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = errorf(codeInternalError, "%v", err)
+		}
+		resp.Error = rpcErr
+	} else {
+		resp.Result = result
+	}
+	c.write(resp)
+}
+
+// serve dispatches each line of in to methods until in ends, then waits
+// for the handlers still running, so a client may close its end as soon
+// as it has written its last request.
+func (c *conn) serve(ctx context.Context, in io.Reader, methods map[string]handler) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxMessage)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			code := codeParseError
+			if json.Valid(line) {
+				code = codeInvalidRequest // a batch, or not an object
+			}
+			c.reply(json.RawMessage("null"), nil, errorf(code, "%v", err))
+			continue
+		}
+		if req.JSONRPC != "2.0" || req.Method == "" {
+			id := req.ID
+			if id == nil {
+				id = json.RawMessage("null")
+			}
+			c.reply(id, nil, errorf(codeInvalidRequest, `want "jsonrpc":"2.0" and a method`))
+			continue
+		}
+		h, ok := methods[req.Method]
+		if !ok {
+			c.reply(req.ID, nil, errorf(codeMethodNotFound, "no method %q", req.Method))
+			continue
+		}
+		wg.Go(func() {
+			result, err := h(ctx, req.Params)
+			c.reply(req.ID, result, err)
+		})
+	}
+	return scanner.Err()
+}
+
+// decodeParams reads params into v; absent params leave v as is.
+func decodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return errorf(codeInvalidParams, "params: %v", err)
+	}
+	return nil
+}
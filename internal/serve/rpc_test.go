@@ -0,0 +1,94 @@
+package serve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// testClient feeds request lines to a conn and reads back what it wrote.
+type testClient struct {
+	t    *testing.T
+	out  bytes.Buffer
+	conn *conn
+}
+
+func newTestClient(t *testing.T) *testClient {
+	c := &testClient{t: t}
+	c.conn = &conn{out: &c.out}
+	return c
+}
+
+func (c *testClient) send(methods map[string]handler, lines ...string) {
+	c.t.Helper()
+	if err := c.conn.serve(context.Background(), strings.NewReader(strings.Join(lines, "\n")), methods); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// messages returns the replies written so far keyed by id, and the
+// notification methods in order, and forgets them.
+func (c *testClient) messages() (map[string]response, []string) {
+	c.t.Helper()
+	replies := map[string]response{}
+	var notes []string
+	for _, line := range strings.Split(strings.TrimSpace(c.out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var msg struct {
+			response
+			Method string `json:"method"`
+		}
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			c.t.Fatalf("bad reply %q: %v", line, err)
+		}
+		if msg.Method != "" {
+			notes = append(notes, msg.Method)
+			continue
+		}
+		replies[string(msg.ID)] = msg.response
+	}
+	c.out.Reset()
+	return replies, notes
+}
+
+func TestServeErrors(t *testing.T) {
+	echo := func(_ context.Context, params json.RawMessage) (any, error) {
+		var p struct {
+			Say string `json:"say"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return p.Say, nil
+	}
+	c := newTestClient(t)
+	c.send(map[string]handler{"echo": echo},
+		`{"jsonrpc":"2.0","id":1,"method":"echo","params":{"say":"hi"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"missing"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"echo","params":{"shout":"hi"}}`,
+		`{"jsonrpc":"1.0","id":4,"method":"echo"}`,
+		`{"jsonrpc":"2.0","method":"echo","params":{"say":"nobody listens"}}`,
+		`not json`,
+	)
+	replies, _ := c.messages()
+	if r := replies["1"]; r.Error != nil || r.Result != "hi" {
+		t.Errorf("echo = %+v", r)
+	}
+	for id, code := range map[string]int{
+		"2":    codeMethodNotFound,
+		"3":    codeInvalidParams,
+		"4":    codeInvalidRequest,
+		"null": codeParseError,
+	} {
+		if r := replies[id]; r.Error == nil || r.Error.Code != code {
+			t.Errorf("id %s: got %+v, want code %d", id, r, code)
+		}
+	}
+	if len(replies) != 5 {
+		t.Errorf("got %d replies, want 5 (none for the notification)", len(replies))
+	}
+}
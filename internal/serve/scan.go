@@ -0,0 +1,251 @@
+//go:build darwin || linux
+
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tw93/mole/pkg/diskscan"
+)
+
+// Scan states.
+const (
+	scanRunning  = "running"
+	scanDone     = "done"
+	scanFailed   = "failed"
+	scanCanceled = "canceled"
+)
+
+// scanJob is one background scan.
+type scanJob struct {
+	id       string
+	path     string
+	started  time.Time
+	progress *diskscan.Progress
+	cancel   context.CancelFunc
+
+	mu       sync.Mutex
+	state    string
+	finished time.Time
+	result   diskscan.Result
+	err      error
+}
+
+// scans are the jobs started on this connection, kept until it closes so
+// their results can be fetched more than once.
+type scans struct {
+	conn *conn
+	wg   sync.WaitGroup
+
+	mu   sync.Mutex
+	next int
+	jobs map[string]*scanJob
+}
+
+func newScans(c *conn) *scans {
+	return &scans{conn: c, jobs: map[string]*scanJob{}}
+}
+
+type scanStartParams struct {
+	Path       string   `json:"path"`
+	MaxEntries int      `json:"max_entries"`
+	Exclude    []string `json:"exclude"`
+}
+
+type scanIDParams struct {
+	ID string `json:"id"`
+}
+
+type scanStarted struct {
+	ID string `json:"id"`
+}
+
+type scanProgress struct {
+	ID        string `json:"id"`
+	Path      string `json:"path"`
+	State     string `json:"state"`
+	Files     int64  `json:"files"`
+	Dirs      int64  `json:"dirs"`
+	Bytes     int64  `json:"bytes"`
+	Current   string `json:"current,omitempty"`
+	ElapsedMS int64  `json:"elapsed_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// start checks the path and scans it in the background. The scan outlives
+// the request's context; only scan.cancel or closing the connection stops
+// it.
+func (s *scans) start(_ context.Context, params json.RawMessage) (any, error) {
+	var p scanStartParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	path, err := scanPath(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	if p.MaxEntries <= 0 {
+		p.MaxEntries = diskscan.DefaultMaxEntries
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	progress := &diskscan.Progress{}
+	scanner := diskscan.New(diskscan.Options{
+		MaxEntries: p.MaxEntries,
+		Spotlight:  true,
+		Cache:      true,
+		Exclude:    p.Exclude,
+		Progress:   progress,
+	})
+
+	s.mu.Lock()
+	s.next++
+	job := &scanJob{
+		id:       strconv.Itoa(s.next),
+		path:     path,
+		started:  time.Now(),
+		progress: progress,
+		cancel:   cancel,
+		state:    scanRunning,
+	}
+	s.jobs[job.id] = job
+	s.mu.Unlock()
+
+	s.wg.Go(func() {
+		result, err := scanner.Scan(ctx, path)
+		job.mu.Lock()
+		job.finished = time.Now()
+		switch {
+		case ctx.Err() != nil:
+			job.state = scanCanceled
+		case err != nil:
+			job.state, job.err = scanFailed, err
+		default:
+			job.state, job.result = scanDone, result
+		}
+		state := job.state
+		job.mu.Unlock()
+		cancel()
+		s.conn.notify("scan.finished", map[string]string{"id": job.id, "state": state})
+	})
+	return scanStarted{ID: job.id}, nil
+}
+
+func (s *scans) job(params json.RawMessage) (*scanJob, error) {
+	var p scanIDParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[p.ID]
+	if !ok {
+		return nil, errorf(codeNoScan, "no scan %q", p.ID)
+	}
+	return job, nil
+}
+
+func (s *scans) progress(_ context.Context, params json.RawMessage) (any, error) {
+	job, err := s.job(params)
+	if err != nil {
+		return nil, err
+	}
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	end := job.finished
+	if end.IsZero() {
+		end = time.Now()
+	}
+	p := scanProgress{
+		ID:        job.id,
+		Path:      job.path,
+		State:     job.state,
+		Files:     job.progress.Files.Load(),
+		Dirs:      job.progress.Dirs.Load(),
+		Bytes:     job.progress.Bytes.Load(),
+		ElapsedMS: end.Sub(job.started).Milliseconds(),
+	}
+	if job.state == scanRunning {
+		p.Current = job.progress.Path()
+	}
+	if job.err != nil {
+		p.Error = job.err.Error()
+	}
+	return p, nil
+}
+
+func (s *scans) result(_ context.Context, params json.RawMessage) (any, error) {
+	job, err := s.job(params)
+	if err != nil {
+		return nil, err
+	}
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	switch job.state {
+	case scanRunning:
+		return nil, errorf(codeScanRunning, "scan %s is still running", job.id)
+	case scanFailed:
+		return nil, errorf(codeInternalError, "scan %s failed: %v", job.id, job.err)
+	case scanCanceled:
+		return nil, errorf(codeScanCanceled, "scan %s was canceled", job.id)
+	}
+	return newScanResult(job.path, job.result), nil
+}
+
+func (s *scans) cancel(_ context.Context, params json.RawMessage) (any, error) {
+	job, err := s.job(params)
+	if err != nil {
+		return nil, err
+	}
+	job.cancel()
+	return struct{}{}, nil
+}
+
+// close cancels every scan and waits for them to stop.
+func (s *scans) close() {
+	s.mu.Lock()
+	for _, job := range s.jobs {
+		job.cancel()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// scanOnce scans path and waits for the result, for callers that block on
+// a scan instead of polling it.
+func scanOnce(ctx context.Context, path string, maxEntries int) (scanResult, error) {
+	scanner := diskscan.New(diskscan.Options{MaxEntries: maxEntries, Spotlight: true, Cache: true})
+	result, err := scanner.Scan(ctx, path)
+	if err != nil {
+		return scanResult{}, err
+	}
+	return newScanResult(path, result), nil
+}
+
+func newScanResult(path string, result diskscan.Result) scanResult {
+	out := scanResult{
+		Path:       path,
+		Entries:    make([]scanEntry, 0, len(result.Entries)),
+		Ages:       &scanAges{result.Ages[0], result.Ages[1], result.Ages[2], result.Ages[3], result.Ages[4]},
+		TotalSize:  result.TotalSize,
+		TotalFiles: result.TotalFiles,
+	}
+	for _, e := range result.Entries {
+		entry := scanEntry{Name: e.Name, Path: e.Path, Size: e.Size, IsDir: e.IsDir}
+		if !e.LastAccess.IsZero() {
+			entry.LastAccess = e.LastAccess.UTC().Format(time.RFC3339)
+		}
+		out.Entries = append(out.Entries, entry)
+	}
+	for _, f := range result.LargeFiles {
+		out.LargeFiles = append(out.LargeFiles, scanFile(f))
+	}
+	for _, f := range result.SparseFiles {
+		out.SparseFiles = append(out.SparseFiles, sparseFile(f))
+	}
+	return out
+}
@@ -0,0 +1,41 @@
+//go:build !darwin && !linux
+
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+)
+
+// scans answers every scan method with an error where pkg/diskscan does
+// not build; metrics.read still works.
+type scans struct{}
+
+func newScans(*conn) *scans { return &scans{} }
+
+func (*scans) unsupported(context.Context, json.RawMessage) (any, error) {
+	return nil, errorf(codeScanUnsupported, "scanning is not supported on %s", runtime.GOOS)
+}
+
+func (s *scans) start(ctx context.Context, params json.RawMessage) (any, error) {
+	return s.unsupported(ctx, params)
+}
+
+func (s *scans) progress(ctx context.Context, params json.RawMessage) (any, error) {
+	return s.unsupported(ctx, params)
+}
+
+func (s *scans) result(ctx context.Context, params json.RawMessage) (any, error) {
+	return s.unsupported(ctx, params)
+}
+
+func (s *scans) cancel(ctx context.Context, params json.RawMessage) (any, error) {
+	return s.unsupported(ctx, params)
+}
+
+func (*scans) close() {}
+
+func scanOnce(context.Context, string, int) (scanResult, error) {
+	return scanResult{}, errorf(codeScanUnsupported, "scanning is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,56 @@
+//go:build darwin || linux
+
+package serve
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScanLifecycle(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // keep the scan cache out of the real home
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "big"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "big", "blob"), make([]byte, 64*1024), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestClient(t)
+	s := newServer(c.conn)
+	defer s.close()
+
+	c.send(s.methods(), `{"jsonrpc":"2.0","id":1,"method":"scan.start","params":{"path":"`+root+`"}}`)
+	s.scans.wg.Wait()
+	r, notes := c.messages()
+	if r["1"].Error != nil {
+		t.Fatalf("scan.start: %v", r["1"].Error)
+	}
+	if len(notes) != 1 || notes[0] != "scan.finished" {
+		t.Fatalf("notifications = %v, want one scan.finished", notes)
+	}
+
+	c.send(s.methods(),
+		`{"jsonrpc":"2.0","id":2,"method":"scan.progress","params":{"id":"1"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"scan.result","params":{"id":"1"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"scan.result","params":{"id":"9"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"scan.start","params":{"path":"`+filepath.Join(root, "missing")+`"}}`,
+	)
+	r, _ = c.messages()
+	if p, _ := r["2"].Result.(map[string]any); p["state"] != scanDone {
+		t.Errorf("progress = %+v", r["2"])
+	}
+	result, _ := r["3"].Result.(map[string]any)
+	entries, _ := result["entries"].([]any)
+	if len(entries) != 1 || entries[0].(map[string]any)["name"] != "big" {
+		t.Errorf("result = %+v", r["3"])
+	}
+	if r["4"].Error == nil || r["4"].Error.Code != codeNoScan {
+		t.Errorf("unknown scan = %+v", r["4"])
+	}
+	if r["5"].Error == nil || r["5"].Error.Code != codeInvalidParams {
+		t.Errorf("missing path = %+v", r["5"])
+	}
+}
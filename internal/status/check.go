@@ -0,0 +1,217 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+// Exit codes for `status check`, aligned with Nagios plugin conventions so
+// the command drops into existing monitoring without a wrapper. They are
+// also mole's own codes: a failed check is a partial result, and a metric
+// that could not be read is a collector failure.
+const (
+	checkExitOK      = exitcode.OK
+	checkExitFailed  = exitcode.Partial
+	checkExitUsage   = exitcode.Usage
+	checkExitUnknown = exitcode.Failed
+)
+
+// metricCheck is one `<metric> <condition>` assertion, e.g. cpu.usage '<90'.
+type metricCheck struct {
+	metric string
+	op     string
+	want   string
+}
+
+type checkResult struct {
+	check  metricCheck
+	value  any
+	passed bool
+	err    error
+}
+
+var checkOperators = []string{"<=", ">=", "==", "!=", "<", ">", "="}
+
+func parseMetricChecks(args []string) ([]metricCheck, error) {
+	if len(args) == 0 || len(args)%2 != 0 {
+		return nil, errors.New("usage: status check <metric> <condition> [<metric> <condition>...], e.g. status check cpu.usage '<90'")
+	}
+	checks := make([]metricCheck, 0, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		metric := strings.TrimSpace(args[i])
+		if metric == "" {
+			return nil, errors.New("empty metric name")
+		}
+		op, want, err := parseCheckCondition(args[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", metric, err)
+		}
+		checks = append(checks, metricCheck{metric: metric, op: op, want: want})
+	}
+	return checks, nil
+}
+
+func parseCheckCondition(raw string) (string, string, error) {
+	cond := strings.TrimSpace(raw)
+	for _, op := range checkOperators {
+		if rest, ok := strings.CutPrefix(cond, op); ok {
+			want := strings.TrimSpace(rest)
+			if want == "" {
+				return "", "", fmt.Errorf("condition %q has no value", raw)
+			}
+			if op == "=" {
+				op = "=="
+			}
+			return op, want, nil
+		}
+	}
+	return "", "", fmt.Errorf("condition %q must start with one of < <= > >= == !=", raw)
+}
+
+// lookupMetric resolves a dotted path against the snapshot's JSON form, so
+// check paths always match the documented --json field names.
+func lookupMetric(snapshot map[string]any, path string) (any, error) {
+	var cur any = snapshot
+	for part := range strings.SplitSeq(path, ".") {
+		switch node := cur.(type) {
+		case map[string]any:
+			next, ok := node[part]
+			if !ok {
+				return nil, fmt.Errorf("unknown metric %q", path)
+			}
+			cur = next
+		case []any:
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, fmt.Errorf("metric %q: index %q out of range", path, part)
+			}
+			cur = node[idx]
+		default:
+			return nil, fmt.Errorf("unknown metric %q", path)
+		}
+	}
+	switch cur.(type) {
+	case float64, string, bool:
+		return cur, nil
+	case nil:
+		return nil, fmt.Errorf("metric %q is unavailable", path)
+	default:
+		return nil, fmt.Errorf("metric %q is not a scalar value", path)
+	}
+}
+
+func evaluateCheck(value any, check metricCheck) (bool, error) {
+	if num, ok := value.(float64); ok {
+		want, err := strconv.ParseFloat(check.want, 64)
+		if err != nil {
+			return false, fmt.Errorf("%s is numeric, cannot compare with %q", check.metric, check.want)
+		}
+		switch check.op {
+		case "<":
+			return num < want, nil
+		case "<=":
+			return num <= want, nil
+		case ">":
+			return num > want, nil
+		case ">=":
+			return num >= want, nil
+		case "==":
+			return num == want, nil
+		case "!=":
+			return num != want, nil
+		}
+	}
+
+	got := fmt.Sprint(value)
+	switch check.op {
+	case "==":
+		return strings.EqualFold(got, check.want), nil
+	case "!=":
+		return !strings.EqualFold(got, check.want), nil
+	}
+	return false, fmt.Errorf("%s is not numeric, only == and != are supported", check.metric)
+}
+
+func runMetricChecks(snapshot sysmetrics.MetricsSnapshot, checks []metricCheck) ([]checkResult, error) {
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]any
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return nil, err
+	}
+
+	results := make([]checkResult, 0, len(checks))
+	for _, check := range checks {
+		result := checkResult{check: check}
+		result.value, result.err = lookupMetric(tree, check.metric)
+		if result.err == nil {
+			result.passed, result.err = evaluateCheck(result.value, check)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// writeCheckResults prints one line per check and returns the exit code:
+// unknown metrics outrank failures, which outrank passes.
+func writeCheckResults(w io.Writer, results []checkResult) int {
+	code := checkExitOK
+	for _, r := range results {
+		condition := r.check.op + r.check.want
+		switch {
+		case r.err != nil:
+			fmt.Fprintf(w, "UNKNOWN %s %s: %v\n", r.check.metric, condition, r.err)
+			code = checkExitUnknown
+		case r.passed:
+			fmt.Fprintf(w, "OK %s=%s %s\n", r.check.metric, formatCheckValue(r.value), condition)
+		default:
+			fmt.Fprintf(w, "FAIL %s=%s %s\n", r.check.metric, formatCheckValue(r.value), condition)
+			if code == checkExitOK {
+				code = checkExitFailed
+			}
+		}
+	}
+	return code
+}
+
+func formatCheckValue(value any) string {
+	if num, ok := value.(float64); ok {
+		return strconv.FormatFloat(math.Round(num*100)/100, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// runCheckMode collects one full snapshot and evaluates `status check` args.
+func runCheckMode(args []string) {
+	checks, err := parseMetricChecks(args)
+	if err != nil {
+		exitcode.Exit("status check", checkExitUsage, err)
+	}
+
+	collector := newCollectorFromFlags()
+	data, err := collector.Collect(context.Background())
+	if err != nil && data.CollectedAt.IsZero() {
+		exitcode.Exit("status check", checkExitUnknown, fmt.Errorf("error collecting metrics: %w", err))
+	}
+
+	results, err := runMetricChecks(data, checks)
+	if err != nil {
+		exitcode.Exit("status check", checkExitUnknown, fmt.Errorf("error evaluating checks: %w", err))
+	}
+	out := openOutput("status check")
+	code := writeCheckResults(out, results)
+	closeOutput("status check", out)
+	os.Exit(code)
+}
@@ -0,0 +1,97 @@
+package status
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+func TestParseMetricChecks(t *testing.T) {
+	checks, err := parseMetricChecks([]string{"cpu.usage", "<90", "memory.pressure", "= normal"})
+	if err != nil {
+		t.Fatalf("parseMetricChecks() error = %v", err)
+	}
+	if len(checks) != 2 {
+		t.Fatalf("len(checks) = %d, want 2", len(checks))
+	}
+	if checks[0] != (metricCheck{metric: "cpu.usage", op: "<", want: "90"}) {
+		t.Fatalf("checks[0] = %+v", checks[0])
+	}
+	if checks[1] != (metricCheck{metric: "memory.pressure", op: "==", want: "normal"}) {
+		t.Fatalf("checks[1] = %+v", checks[1])
+	}
+
+	for _, args := range [][]string{
+		nil,
+		{"cpu.usage"},
+		{"cpu.usage", "90"},
+		{"cpu.usage", "<"},
+	} {
+		if _, err := parseMetricChecks(args); err == nil {
+			t.Errorf("parseMetricChecks(%q) should fail", args)
+		}
+	}
+}
+
+func TestRunMetricChecks(t *testing.T) {
+	snapshot := sysmetrics.MetricsSnapshot{
+		CPU:    sysmetrics.CPUStatus{Usage: 42.345},
+		Memory: sysmetrics.MemoryStatus{UsedPercent: 91, Pressure: "warn"},
+		Disks:  []sysmetrics.DiskStatus{{Mount: "/", UsedPercent: 70}},
+	}
+	checks := []metricCheck{
+		{metric: "cpu.usage", op: "<", want: "90"},
+		{metric: "memory.used_percent", op: "<=", want: "85"},
+		{metric: "memory.pressure", op: "!=", want: "critical"},
+		{metric: "disks.0.used_percent", op: ">=", want: "70"},
+	}
+
+	results, err := runMetricChecks(snapshot, checks)
+	if err != nil {
+		t.Fatalf("runMetricChecks() error = %v", err)
+	}
+	want := []bool{true, false, true, true}
+	for i, r := range results {
+		if r.err != nil {
+			t.Fatalf("%s: unexpected error %v", r.check.metric, r.err)
+		}
+		if r.passed != want[i] {
+			t.Errorf("%s passed = %v, want %v", r.check.metric, r.passed, want[i])
+		}
+	}
+
+	var out bytes.Buffer
+	if code := writeCheckResults(&out, results); code != checkExitFailed {
+		t.Fatalf("exit code = %d, want %d", code, checkExitFailed)
+	}
+	if !strings.Contains(out.String(), "OK cpu.usage=42.35 <90") {
+		t.Fatalf("output missing formatted pass line:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "FAIL memory.used_percent=91 <=85") {
+		t.Fatalf("output missing fail line:\n%s", out.String())
+	}
+}
+
+func TestRunMetricChecksUnknownMetric(t *testing.T) {
+	results, err := runMetricChecks(sysmetrics.MetricsSnapshot{}, []metricCheck{
+		{metric: "cpu.nope", op: "<", want: "1"},
+		{metric: "disks.3.used_percent", op: "<", want: "1"},
+		{metric: "cpu", op: "<", want: "1"},
+		{metric: "memory.pressure", op: "<", want: "1"},
+	})
+	if err != nil {
+		t.Fatalf("runMetricChecks() error = %v", err)
+	}
+	for _, r := range results {
+		if r.err == nil {
+			t.Errorf("%s: expected an error", r.check.metric)
+		}
+	}
+
+	var out bytes.Buffer
+	if code := writeCheckResults(&out, results); code != checkExitUnknown {
+		t.Fatalf("exit code = %d, want %d", code, checkExitUnknown)
+	}
+}
@@ -0,0 +1,83 @@
+package status
+
+import (
+	"strings"
+
+	"github.com/tw93/mole/internal/i18n"
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+func statusDiagnosisLine(m sysmetrics.MetricsSnapshot) string {
+	if m.CPU.Usage > sysmetrics.CPUHighThreshold {
+		if proc, ok := leadingCPUProcess(m.TopProcesses, 50); ok {
+			return i18n.Tf("%s high CPU", shorten(proc.Name, 18))
+		}
+		return i18n.T("CPU load high")
+	}
+	if m.Memory.Pressure == "warn" || m.Memory.Pressure == "critical" || m.Memory.UsedPercent > sysmetrics.MemHighThreshold {
+		if proc, ok := leadingMemoryProcess(m.TopProcesses); ok && proc.Memory > 0 {
+			return i18n.Tf("%s memory pressure", shorten(proc.Name, 18))
+		}
+		return i18n.T("Memory pressure high")
+	}
+	if disk, ok := rootDisk(m.Disks); ok && disk.UsedPercent > sysmetrics.DiskCritThreshold {
+		free := uint64(0)
+		if disk.Total > disk.Used {
+			free = disk.Total - disk.Used
+		}
+		return i18n.Tf("Disk low, %s free", humanBytesShort(free))
+	}
+	for _, battery := range m.Batteries {
+		if battery.Capacity > 0 && battery.Capacity < sysmetrics.BatteryCapWarn {
+			return i18n.T("Battery health low")
+		}
+		if battery.CycleCount > sysmetrics.BatteryCycleWarn {
+			return i18n.T("Battery cycles high")
+		}
+	}
+	if m.Thermal.CPUTemp > sysmetrics.ThermalNormalThreshold {
+		return i18n.T("CPU temperature high")
+	}
+	if totalIO := m.DiskIO.ReadRate + m.DiskIO.WriteRate; totalIO > sysmetrics.IOHighThreshold {
+		return i18n.T("Disk I/O busy")
+	}
+	if strings.Contains(m.HealthScoreMsg, ":") {
+		return m.HealthScoreMsg
+	}
+	return i18n.T("All clear")
+}
+
+func leadingCPUProcess(procs []sysmetrics.ProcessInfo, threshold float64) (sysmetrics.ProcessInfo, bool) {
+	var best sysmetrics.ProcessInfo
+	for i, proc := range procs {
+		if i == 0 || proc.CPU > best.CPU {
+			best = proc
+		}
+	}
+	if best.CPU < threshold {
+		return sysmetrics.ProcessInfo{}, false
+	}
+	return best, true
+}
+
+func leadingMemoryProcess(procs []sysmetrics.ProcessInfo) (sysmetrics.ProcessInfo, bool) {
+	var best sysmetrics.ProcessInfo
+	for i, proc := range procs {
+		if i == 0 || proc.Memory > best.Memory {
+			best = proc
+		}
+	}
+	return best, len(procs) > 0
+}
+
+func rootDisk(disks []sysmetrics.DiskStatus) (sysmetrics.DiskStatus, bool) {
+	for _, disk := range disks {
+		if disk.Mount == "/" {
+			return disk, true
+		}
+	}
+	if len(disks) == 0 {
+		return sysmetrics.DiskStatus{}, false
+	}
+	return disks[0], true
+}
@@ -0,0 +1,4 @@
+// Package status is the system dashboard behind `mole status`: the Bubble
+// Tea TUI, and the JSON, watch, check, and doctor modes, all built on
+// pkg/sysmetrics snapshots.
+package status
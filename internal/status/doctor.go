@@ -0,0 +1,240 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/helper"
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+// Diagnostic states, worst last. A skipped source is an optional tool that
+// is not installed; its card simply stays hidden.
+const (
+	diagOK       = "ok"
+	diagSkipped  = "skipped"
+	diagDegraded = "degraded"
+	diagFailed   = "failed"
+)
+
+// diagnostic is one line of `status doctor`: a data source, whether it
+// works, and what to do when it does not.
+type diagnostic struct {
+	Source string `json:"source"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// doctorTool is an external command a collector shells out to.
+type doctorTool struct {
+	name     string
+	goos     string // "" for every platform
+	feeds    string
+	fix      string
+	optional bool // Only matters when the user runs the thing it reports on
+}
+
+const macBuiltinFix = "ships with macOS; make sure /usr/bin and /usr/sbin are on PATH"
+
+var doctorTools = []doctorTool{
+	{name: "ps", feeds: "process list, stuck processes", fix: "install procps"},
+	{name: "lsof", feeds: "listening ports, open file counts", fix: "install lsof"},
+	{name: "ping", feeds: "network latency", fix: "install iputils-ping"},
+	{name: "smartctl", feeds: "disk SMART health", fix: "install smartmontools (brew install smartmontools)"},
+	{name: "nvidia-smi", goos: "linux", feeds: "NVIDIA GPU usage", fix: "install the NVIDIA driver utilities", optional: true},
+	{name: "bluetoothctl", goos: "linux", feeds: "Bluetooth devices", fix: "install bluez", optional: true},
+	{name: "pactl", goos: "linux", feeds: "Bluetooth headset battery", fix: "install pulseaudio-utils", optional: true},
+	{name: "timedatectl", goos: "linux", feeds: "clock sync", fix: "available on systemd hosts", optional: true},
+	{name: "chronyc", goos: "linux", feeds: "clock offset", fix: "install chrony", optional: true},
+	{name: "xrandr", goos: "linux", feeds: "displays", fix: "install x11-xserver-utils", optional: true},
+	{name: "system_profiler", goos: "darwin", feeds: "Bluetooth, GPU and display fallback", fix: macBuiltinFix},
+	{name: "ioreg", goos: "darwin", feeds: "battery health, GPU usage fallback", fix: macBuiltinFix},
+	{name: "diskutil", goos: "darwin", feeds: "volumes, APFS containers", fix: macBuiltinFix},
+	{name: "pmset", goos: "darwin", feeds: "battery, power events", fix: macBuiltinFix},
+	{name: "scutil", goos: "darwin", feeds: "DNS, proxy", fix: macBuiltinFix},
+	{name: "tmutil", goos: "darwin", feeds: "Time Machine", fix: macBuiltinFix},
+	{name: "nettop", goos: "darwin", feeds: "per-process network", fix: macBuiltinFix},
+	{name: "brew", goos: "darwin", feeds: "Homebrew services", fix: "install Homebrew from https://brew.sh", optional: true},
+	{name: "docker", feeds: "containers", fix: "install Docker", optional: true},
+	{name: "kubectl", feeds: "Kubernetes context", fix: "install kubectl", optional: true},
+	{name: "tailscale", feeds: "Tailscale VPN", fix: "install Tailscale", optional: true},
+}
+
+// doctorProbe is what diagnose needs from the host, swappable in tests.
+type doctorProbe struct {
+	goos     string
+	exists   func(string) bool
+	root     bool
+	helper   bool // the privileged helper is running
+	readable func(string) error
+}
+
+func hostDoctorProbe() doctorProbe {
+	return doctorProbe{
+		goos:   runtime.GOOS,
+		exists: sysmetrics.CommandExists,
+		root:   os.Geteuid() == 0,
+		helper: helper.Available(),
+		readable: func(path string) error {
+			f, err := os.Open(path)
+			if err == nil {
+				f.Close()
+			}
+			return err
+		},
+	}
+}
+
+// Placeholder names the GPU collector returns instead of failing outright. A
+// missing nvidia-smi is already reported by the tool check.
+var gpuPlaceholders = []string{"GPU read failed", "GPU info unavailable"}
+
+// diagnose explains every degraded data source behind a snapshot: missing
+// tools, missing permissions, collectors that failed or timed out, and
+// cards that fell back to a placeholder.
+func diagnose(snapshot sysmetrics.MetricsSnapshot, probe doctorProbe) []diagnostic {
+	var diags []diagnostic
+	for _, tool := range doctorTools {
+		if tool.goos != "" && tool.goos != probe.goos {
+			continue
+		}
+		switch {
+		case probe.exists(tool.name):
+			diags = append(diags, diagnostic{Source: tool.name, Status: diagOK, Detail: tool.feeds})
+		case tool.optional:
+			diags = append(diags, diagnostic{Source: tool.name, Status: diagSkipped, Detail: "not installed; no " + tool.feeds, Fix: tool.fix})
+		default:
+			diags = append(diags, diagnostic{Source: tool.name, Status: diagDegraded, Detail: "not found; no " + tool.feeds, Fix: tool.fix})
+		}
+	}
+
+	if probe.goos == "darwin" {
+		switch {
+		case probe.root:
+			diags = append(diags, diagnostic{Source: "powermetrics", Status: diagOK, Detail: "GPU activity, power draw"})
+		case probe.helper:
+			diags = append(diags, diagnostic{Source: "powermetrics", Status: diagOK, Detail: "GPU activity, power draw, through the privileged helper"})
+		default:
+			diags = append(diags, diagnostic{
+				Source: "powermetrics",
+				Status: diagDegraded,
+				Detail: "needs root; no GPU activity or power draw",
+				Fix:    "run `sudo mo status`, or `sudo mo helper install` once",
+			})
+		}
+	}
+	for _, path := range sysmetrics.SourcePaths(probe.goos) {
+		diags = append(diags, pathDiagnostic(path, probe.readable(path), probe.goos))
+	}
+
+	for _, stat := range snapshot.Collectors {
+		if stat.Failures == 0 && stat.Timeouts == 0 {
+			continue
+		}
+		d := diagnostic{Source: stat.Name + " collector", Status: diagFailed}
+		switch {
+		case stat.Failures > 0:
+			d.Detail = fmt.Sprintf("%s (%d of %d runs failed)", stat.LastError, stat.Failures, stat.Runs)
+		default:
+			d.Status = diagDegraded
+			d.Detail = fmt.Sprintf("over its %s budget %d times, slowest %.0fms", sysmetrics.CollectorBudget, stat.Timeouts, stat.MaxMs)
+			d.Fix = "the card shows its last good reading until the tool answers in time"
+		}
+		diags = append(diags, d)
+	}
+
+	noBluetooth := len(snapshot.Bluetooth) == 1 && snapshot.Bluetooth[0].Name == "No Bluetooth info"
+	if noBluetooth && (probe.goos == "darwin" || probe.exists("bluetoothctl")) {
+		fix := "start bluetoothd and check that an adapter is present"
+		if probe.goos == "darwin" {
+			fix = "grant Bluetooth access to your terminal in System Settings > Privacy & Security"
+		}
+		diags = append(diags, diagnostic{Source: "bluetooth", Status: diagDegraded, Detail: "no adapter or devices readable", Fix: fix})
+	}
+	for _, gpu := range snapshot.GPU {
+		if slices.Contains(gpuPlaceholders, gpu.Name) {
+			diags = append(diags, diagnostic{Source: "gpu", Status: diagDegraded, Detail: strings.ToLower(gpu.Name), Fix: gpu.Note})
+		}
+	}
+	return diags
+}
+
+func pathDiagnostic(path string, err error, goos string) diagnostic {
+	switch {
+	case err == nil:
+		return diagnostic{Source: path, Status: diagOK}
+	case errors.Is(err, fs.ErrNotExist):
+		// Nothing written there yet, e.g. no crash reports or no backups.
+		return diagnostic{Source: path, Status: diagSkipped, Detail: "does not exist"}
+	case errors.Is(err, fs.ErrPermission):
+		fix := "run as a user that can read it"
+		if goos == "darwin" {
+			fix = "grant Full Disk Access to your terminal in System Settings > Privacy & Security"
+		}
+		return diagnostic{Source: path, Status: diagDegraded, Detail: "permission denied", Fix: fix}
+	default:
+		return diagnostic{Source: path, Status: diagDegraded, Detail: err.Error()}
+	}
+}
+
+// writeDiagnostics prints the report, working sources last, and returns the
+// exit code: 1 when anything is degraded or failed.
+func writeDiagnostics(w io.Writer, diags []diagnostic) int {
+	rank := map[string]int{diagFailed: 0, diagDegraded: 1, diagSkipped: 2, diagOK: 3}
+	sorted := slices.Clone(diags)
+	slices.SortStableFunc(sorted, func(a, b diagnostic) int { return rank[a.Status] - rank[b.Status] })
+
+	for _, d := range sorted {
+		line := fmt.Sprintf("%-8s %-20s %s", strings.ToUpper(d.Status), d.Source, d.Detail)
+		if d.Fix != "" && d.Status != diagOK {
+			line += " → " + d.Fix
+		}
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+	return doctorExitCode(diags)
+}
+
+func doctorExitCode(diags []diagnostic) int {
+	for _, d := range diags {
+		if d.Status == diagDegraded || d.Status == diagFailed {
+			return exitcode.Partial
+		}
+	}
+	return exitcode.OK
+}
+
+// runDoctorMode collects one full snapshot and explains which data sources
+// are missing or failing, and how to fix them.
+func runDoctorMode() {
+	collector := newCollectorFromFlags()
+	data, err := collector.Collect(context.Background())
+	if err != nil && data.CollectedAt.IsZero() {
+		exitcode.Exit("status doctor", exitcode.Failed, fmt.Errorf("error collecting metrics: %w", err))
+	}
+
+	diags := diagnose(data, hostDoctorProbe())
+	if *jsonOutput {
+		encoded, err := json.MarshalIndent(diags, "", "  ")
+		if err != nil {
+			exitcode.Exit("status doctor", exitcode.Failed, fmt.Errorf("error encoding diagnostics: %w", err))
+		}
+		out := openOutput("status doctor")
+		fmt.Fprintln(out, string(encoded))
+		closeOutput("status doctor", out)
+		os.Exit(doctorExitCode(diags))
+	}
+	out := openOutput("status doctor")
+	code := writeDiagnostics(out, diags)
+	closeOutput("status doctor", out)
+	os.Exit(code)
+}
@@ -0,0 +1,96 @@
+package status
+
+import (
+	"bytes"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+func TestDiagnoseExplainsMissingToolsAndFailures(t *testing.T) {
+	probe := doctorProbe{
+		goos:     "linux",
+		exists:   func(name string) bool { return name != "smartctl" && name != "docker" },
+		readable: func(string) error { return nil },
+	}
+	snapshot := sysmetrics.MetricsSnapshot{
+		Collectors: []sysmetrics.CollectorStat{
+			{Name: "cpu", Runs: 3},
+			{Name: "disk_health", Runs: 3, Failures: 2, LastError: "smartctl: exit status 2"},
+			{Name: "latency", Runs: 3, Timeouts: 1, MaxMs: 4100},
+		},
+		Bluetooth: []sysmetrics.BluetoothDevice{{Name: "No Bluetooth info"}},
+		GPU:       []sysmetrics.GPUStatus{{Name: "GPU read failed", Note: "Verify nvidia-smi availability"}},
+	}
+
+	byStatus := map[string][]string{}
+	for _, d := range diagnose(snapshot, probe) {
+		byStatus[d.Status] = append(byStatus[d.Status], d.Source)
+	}
+	for status, want := range map[string][]string{
+		diagFailed:   {"disk_health collector"},
+		diagDegraded: {"smartctl", "latency collector", "bluetooth", "gpu"},
+		diagSkipped:  {"docker"},
+	} {
+		for _, source := range want {
+			if !strings.Contains(strings.Join(byStatus[status], ","), source) {
+				t.Errorf("%s not reported %s: %v", source, status, byStatus)
+			}
+		}
+	}
+	if strings.Contains(strings.Join(byStatus[diagFailed], ","), "cpu") {
+		t.Errorf("healthy collector reported: %v", byStatus)
+	}
+}
+
+func TestDiagnoseFlagsUnreadablePathsOnMac(t *testing.T) {
+	timeMachinePrefs := sysmetrics.SourcePaths("darwin")[0]
+	probe := doctorProbe{
+		goos:   "darwin",
+		exists: func(string) bool { return true },
+		readable: func(path string) error {
+			if path == timeMachinePrefs {
+				return fs.ErrPermission
+			}
+			return fs.ErrNotExist
+		},
+	}
+	var gotPerm, gotRoot bool
+	for _, d := range diagnose(sysmetrics.MetricsSnapshot{}, probe) {
+		if d.Source == timeMachinePrefs && d.Status == diagDegraded && strings.Contains(d.Fix, "Full Disk Access") {
+			gotPerm = true
+		}
+		if d.Source == "powermetrics" && d.Status == diagDegraded {
+			gotRoot = true
+		}
+	}
+	if !gotPerm || !gotRoot {
+		t.Fatalf("permission diagnostics missing: perm=%v root=%v", gotPerm, gotRoot)
+	}
+}
+
+func TestWriteDiagnosticsOrdersWorstFirst(t *testing.T) {
+	var buf bytes.Buffer
+	code := writeDiagnostics(&buf, []diagnostic{
+		{Source: "ps", Status: diagOK, Detail: "process list"},
+		{Source: "docker", Status: diagSkipped, Detail: "not installed", Fix: "install Docker"},
+		{Source: "gpu collector", Status: diagFailed, Detail: "boom"},
+	})
+	if code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "FAILED") || !strings.HasPrefix(lines[2], "OK") {
+		t.Fatalf("unexpected report:\n%s", buf.String())
+	}
+	if !strings.Contains(lines[1], "→ install Docker") {
+		t.Fatalf("fix hint missing: %q", lines[1])
+	}
+
+	buf.Reset()
+	if code := writeDiagnostics(&buf, []diagnostic{{Source: "ps", Status: diagOK}}); code != 0 {
+		t.Fatalf("healthy exit code = %d", code)
+	}
+}
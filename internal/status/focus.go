@@ -0,0 +1,174 @@
+package status
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tw93/mole/internal/i18n"
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+// focusGPU is the focus-only panel for GPU usage, which the dashboard folds
+// into the Memory and Processes cards.
+const focusGPU = "gpu"
+
+// focusPanels lists the panels `f` and tab step through: the cards the
+// dashboard shows, in its order, plus GPU when one reports usage.
+func (m model) focusPanels() []string {
+	var ids []string
+	for _, c := range m.layout.arrange(buildCards(m.metrics, colWidth)) {
+		ids = append(ids, panelID(c.title))
+		if panelID(c.title) == "processes" && len(m.metrics.GPU) > 0 {
+			ids = append(ids, focusGPU)
+		}
+	}
+	return ids
+}
+
+// stepFocus moves the focus by delta panels, wrapping at either end. A
+// panel that went away since it was focused restarts from the first.
+func (m model) stepFocus(delta int) string {
+	ids := m.focusPanels()
+	if len(ids) == 0 {
+		return ""
+	}
+	i := slices.Index(ids, m.focus)
+	if i < 0 {
+		return ids[0]
+	}
+	return ids[(i+delta+len(ids))%len(ids)]
+}
+
+// renderFocusCard draws one panel across the whole terminal. Processes,
+// Network, GPU and Sensors get extra rows and full-length history graphs;
+// any other card is shown whole at the wider width.
+func renderFocusCard(m sysmetrics.MetricsSnapshot, id string, width int) cardData {
+	switch id {
+	case "processes":
+		return renderProcessFocus(m, width)
+	case "network":
+		return renderNetworkFocus(m, width)
+	case focusGPU:
+		return renderGPUFocus(m.GPU)
+	case "sensors":
+		card := renderSensorsCard(nil)
+		for _, r := range m.Sensors {
+			line := fmt.Sprintf("%-*s %s°C", metricLabelWidth, r.Label, colorizeTemp(r.Value))
+			if len(r.History) > 1 {
+				line += "  " + colorizeTempGraph(temperatureGraph(r.History, min(width-20, sysmetrics.SensorHistorySize)), r.Value)
+			}
+			card.lines = append(card.lines, line)
+		}
+		return card
+	}
+	for _, c := range buildCards(m, width) {
+		if panelID(c.title) == id {
+			return c
+		}
+	}
+	return cardData{title: id, lines: []string{subtleStyle.Render(i18n.T("Nothing to show right now"))}}
+}
+
+// renderProcessFocus lists every collected process with its PID and full
+// command line, then the GPU, stuck and Spotlight lines from the card.
+func renderProcessFocus(m sysmetrics.MetricsSnapshot, width int) cardData {
+	lines := []string{subtleStyle.Render(fmt.Sprintf("%-3s %7s  %-16s %6s %*s  %s", "#", "PID", "", "CPU", processMemoryWidth, "MEM", "COMMAND"))}
+	for i, p := range m.TopProcesses {
+		barValue := p.CPU
+		switch m.ProcessSort {
+		case sysmetrics.ProcessSortMemory:
+			barValue = p.Memory
+		case sysmetrics.ProcessSortEnergy:
+			barValue = min(p.Energy, 100)
+		}
+		line := fmt.Sprintf("%-3s %7d  %s %5.1f%% %*s  ",
+			fmt.Sprintf("#%d", i+1), p.PID, progressBar(barValue), p.CPU, processMemoryWidth, processMemoryText(p))
+		command := cmp.Or(p.Command, p.Name)
+		if m.ProcessSort == sysmetrics.ProcessSortEnergy {
+			command = fmt.Sprintf("E%.1f ", p.Energy) + command
+		}
+		if rest := remainingLineWidth(width, line); rest > 0 {
+			line += shorten(command, rest)
+		}
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	if len(m.TopProcesses) == 0 {
+		lines = append(lines, subtleStyle.Render(i18n.T("Collecting...")))
+	}
+	card := cardData{icon: iconProcs, title: renderProcessCard(nil, width, m.ProcessSort).title, lines: lines}
+	return withStuckProcesses(withSpotlight(withGPUProcesses(card, m.GPU, m.CollectedAt), m.Spotlight), m.Stuck)
+}
+
+// renderNetworkFocus draws the whole rate history and breaks traffic down by
+// interface and by process.
+func renderNetworkFocus(m sysmetrics.MetricsSnapshot, width int) cardData {
+	card := renderNetworkCard(m.Network, m.NetworkHistory, nil, m.Proxy, width)
+	if len(m.Network) == 0 {
+		return card
+	}
+	var totalRx, totalTx float64
+	for _, n := range m.Network {
+		totalRx += n.RxRateMBs
+		totalTx += n.TxRateMBs
+	}
+	graphWidth := max(min(width-22, sysmetrics.NetworkHistorySize), 5)
+	lines := []string{
+		fmt.Sprintf("Down   %s  %s", sparkline(m.NetworkHistory.RxHistory, totalRx, graphWidth), formatRate(totalRx)),
+		fmt.Sprintf("Up     %s  %s", sparkline(m.NetworkHistory.TxHistory, totalTx, graphWidth), formatRate(totalTx)),
+		fmt.Sprintf("Peak   ↓%s ↑%s over the last %d samples",
+			formatRate(slices.Max(append([]float64{0}, m.NetworkHistory.RxHistory...))),
+			formatRate(slices.Max(append([]float64{0}, m.NetworkHistory.TxHistory...))),
+			max(len(m.NetworkHistory.RxHistory), len(m.NetworkHistory.TxHistory))),
+		"",
+	}
+	for _, n := range m.Network {
+		lines = append(lines, fmt.Sprintf("%-10s ↓%s ↑%s  %s", shorten(n.Name, 10), formatRate(n.RxRateMBs), formatRate(n.TxRateMBs), n.IP))
+	}
+	if len(m.NetworkProcs) > 0 {
+		lines = append(lines, "")
+	}
+	for _, p := range m.NetworkProcs {
+		lines = append(lines, fmt.Sprintf("%7d  %-24s ↓%s ↑%s MB/s", p.PID, shorten(p.Name, 24), formatRateCompact(p.RxRateMBs), formatRateCompact(p.TxRateMBs)))
+	}
+	// Keep the proxy and IP lines the card adds after its graphs.
+	lines = append(lines, card.lines[2:]...)
+	card.lines = lines
+	return withClock(withICloud(withSpeedTest(withPublicIP(card, m.PublicIP), m.SpeedTests), m.ICloud), m.Clock)
+}
+
+// renderGPUFocus gives each GPU its own usage, memory and client rows.
+func renderGPUFocus(gpus []sysmetrics.GPUStatus) cardData {
+	var lines []string
+	for i, gpu := range gpus {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		name := gpu.Name
+		if gpu.CoreCount > 0 {
+			name += fmt.Sprintf(" · %d cores", gpu.CoreCount)
+		}
+		lines = append(lines, name)
+		if gpu.Usage >= 0 {
+			lines = append(lines, fmt.Sprintf("%-*s %s  %5.1f%%", metricLabelWidth, "Usage", progressBar(gpu.Usage), gpu.Usage))
+		}
+		// Memory figures are in MiB.
+		used := humanBytesShort(uint64(gpu.MemoryUsed * (1 << 20)))
+		switch {
+		case gpu.MemoryTotal > 0:
+			percent := gpu.MemoryUsed / gpu.MemoryTotal * 100
+			lines = append(lines, fmt.Sprintf("%-*s %s  %5.1f%% %s / %s", metricLabelWidth, "VRAM", progressBar(percent), percent,
+				used, humanBytesShort(uint64(gpu.MemoryTotal*(1<<20)))))
+		case gpu.SharedMemory && gpu.MemoryUsed > 0:
+			lines = append(lines, fmt.Sprintf("%-*s %s of unified memory", metricLabelWidth, "Wired", used))
+		}
+		for _, p := range gpu.Processes {
+			lines = append(lines, fmt.Sprintf("%7d  %-24s %5.1f%%", p.PID, shorten(p.Name, 24), p.Usage))
+		}
+		if gpu.Note != "" {
+			lines = append(lines, subtleStyle.Render(gpu.Note))
+		}
+	}
+	return cardData{icon: iconGPU, title: "GPU", lines: lines}
+}
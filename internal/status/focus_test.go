@@ -0,0 +1,76 @@
+package status
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+func TestFocusCyclesPanelsAndReturns(t *testing.T) {
+	m := model{ready: true, width: 120, height: 40, metrics: sysmetrics.MetricsSnapshot{
+		GPU: []sysmetrics.GPUStatus{{Name: "RTX 4090", Usage: 87, MemoryUsed: 6144, MemoryTotal: 24576,
+			Processes: []sysmetrics.GPUProcess{{PID: 4242, Name: "blender", Usage: 80}}}},
+	}}
+	press := func(key tea.KeyMsg) {
+		t.Helper()
+		updated, _ := m.Update(key)
+		m = updated.(model)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	if m.focus != "cpu" {
+		t.Fatalf("f should focus the first panel, got %q", m.focus)
+	}
+	press(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if m.focus != "network" {
+		t.Fatalf("shift+tab should wrap to the last panel, got %q", m.focus)
+	}
+	press(tea.KeyMsg{Type: tea.KeyLeft})
+	if m.focus != "gpu" {
+		t.Fatalf("GPU should follow Processes, got %q", m.focus)
+	}
+
+	view := stripANSI(m.View())
+	for _, want := range []string{"RTX 4090", "87.0%", "6G / 24G", "4242", "blender", "esc back to dashboard"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("GPU focus view missing %q:\n%s", want, view)
+		}
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.focus != "" {
+		t.Fatalf("esc should return to the dashboard, got %q", m.focus)
+	}
+}
+
+func TestRenderFocusCardShowsMoreThanTheCard(t *testing.T) {
+	const fullSnapshot = 15 // TopProcesses a collector keeps
+	var snapshot sysmetrics.MetricsSnapshot
+	for i := range fullSnapshot {
+		snapshot.TopProcesses = append(snapshot.TopProcesses, sysmetrics.ProcessInfo{
+			PID: 100 + i, Name: fmt.Sprintf("proc%d", i), Command: fmt.Sprintf("/usr/bin/proc%d --serve", i), CPU: float64(50 - i),
+		})
+	}
+	procs := stripANSI(strings.Join(renderFocusCard(snapshot, "processes", 118).lines, "\n"))
+	if got := strings.Count(procs, "--serve"); got != fullSnapshot {
+		t.Fatalf("process focus shows %d command lines, want %d:\n%s", got, fullSnapshot, procs)
+	}
+
+	snapshot.Network = []sysmetrics.NetworkStatus{{Name: "en0", RxRateMBs: 1.5, TxRateMBs: 0.2, IP: "192.168.1.20"}}
+	for i := range sysmetrics.NetworkHistorySize {
+		snapshot.NetworkHistory.RxHistory = append(snapshot.NetworkHistory.RxHistory, float64(i%7))
+		snapshot.NetworkHistory.TxHistory = append(snapshot.NetworkHistory.TxHistory, 0.1)
+	}
+	network := renderFocusCard(snapshot, "network", 118)
+	down := stripANSI(network.lines[0])
+	if graph := strings.Count(down, "") - 1; graph < 90 {
+		t.Fatalf("network focus graph should span the terminal, got %q", down)
+	}
+	if text := stripANSI(strings.Join(network.lines, "\n")); !strings.Contains(text, "en0") || !strings.Contains(text, "120 samples") {
+		t.Fatalf("network focus missing per-interface or peak rows:\n%s", text)
+	}
+}
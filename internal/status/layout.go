@@ -0,0 +1,209 @@
+package status
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/tw93/mole/internal/i18n"
+)
+
+// defaultPanelOrder is the order buildCards appends cards in. Panel IDs are
+// card titles in lowercase with dashes, e.g. "Crash Reports" is
+// "crash-reports".
+var defaultPanelOrder = []string{
+	"cpu", "memory", "disk", "power", "processes", "network",
+	"system", "limits", "sleep", "sensors", "latency", "containers",
+	"kubernetes", "virtual-machines", "peripherals", "displays", "services",
+	"crash-reports", "time-machine", "login-items", "dns", "vpn", "ports",
+	"storage",
+}
+
+// maxGridColumns caps the `c` cycle; wider grids need a very wide terminal
+// before the cards stop clipping.
+const maxGridColumns = 3
+
+// panelLayout is which cards the dashboard shows and how it arranges them,
+// saved in status_prefs as
+//
+//	panels=power,cpu,memory        # shown first, the rest follow in default order
+//	hidden_panels=sensors,vpn
+//	columns=auto                   # or 1, 2, 3
+//
+// Hiding a panel only keeps its card off the grid; its collectors still run
+// so --json and --watch are unchanged.
+type panelLayout struct {
+	order   []string
+	hidden  []string
+	columns int // 0 picks one or two columns from the terminal width
+}
+
+func loadPanelLayout() panelLayout {
+	return parsePanelLayout(loadPrefs())
+}
+
+func parsePanelLayout(prefs map[string]string) panelLayout {
+	var layout panelLayout
+	for _, id := range splitPanelList(prefs["panels"]) {
+		if slices.Contains(defaultPanelOrder, id) && !slices.Contains(layout.order, id) {
+			layout.order = append(layout.order, id)
+		}
+	}
+	for _, id := range defaultPanelOrder {
+		if !slices.Contains(layout.order, id) {
+			layout.order = append(layout.order, id)
+		}
+	}
+	for _, id := range splitPanelList(prefs["hidden_panels"]) {
+		if slices.Contains(defaultPanelOrder, id) && !slices.Contains(layout.hidden, id) {
+			layout.hidden = append(layout.hidden, id)
+		}
+	}
+	if n, err := strconv.Atoi(prefs["columns"]); err == nil && n >= 1 && n <= maxGridColumns {
+		layout.columns = n
+	}
+	return layout
+}
+
+func splitPanelList(raw string) []string {
+	var ids []string
+	for field := range strings.SplitSeq(raw, ",") {
+		if id := strings.ToLower(strings.TrimSpace(field)); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (l panelLayout) save() {
+	columns := "auto"
+	if l.columns > 0 {
+		columns = strconv.Itoa(l.columns)
+	}
+	savePrefs(map[string]string{
+		"panels":        strings.Join(l.order, ","),
+		"hidden_panels": strings.Join(l.hidden, ","),
+		"columns":       columns,
+	})
+}
+
+// panelID maps a card title to its layout ID. The Processes title carries
+// the sort key ("Processes by mem"), which is not part of the ID.
+func panelID(title string) string {
+	title, _, _ = strings.Cut(title, " by ")
+	return strings.ReplaceAll(strings.ToLower(title), " ", "-")
+}
+
+// arrange drops hidden cards and sorts the rest into the saved order. A
+// zero layout keeps buildCards' order.
+func (l panelLayout) arrange(cards []cardData) []cardData {
+	arranged := slices.DeleteFunc(slices.Clone(cards), func(c cardData) bool {
+		return slices.Contains(l.hidden, panelID(c.title))
+	})
+	if len(l.order) == 0 {
+		return arranged
+	}
+	rank := func(c cardData) int {
+		if i := slices.Index(l.order, panelID(c.title)); i >= 0 {
+			return i
+		}
+		return len(l.order)
+	}
+	slices.SortStableFunc(arranged, func(a, b cardData) int { return rank(a) - rank(b) })
+	return arranged
+}
+
+// gridColumns is how many card columns fit: auto keeps the single column
+// below 80 cells, and a fixed count shrinks when the terminal cannot hold
+// that many cards at their minimum width.
+func (l panelLayout) gridColumns(termWidth int) int {
+	if l.columns == 0 {
+		if termWidth <= 80 {
+			return 1
+		}
+		return 2
+	}
+	return max(1, min(l.columns, termWidth/(colWidth+2)))
+}
+
+// layoutEditor is the model state while the layout panel is open.
+type layoutEditor struct {
+	cursor int
+}
+
+// handleLayoutKey routes keys while the layout panel is open. Every change
+// is saved right away so quitting never loses an edit.
+func (m model) handleLayoutKey(key string) (tea.Model, tea.Cmd) {
+	le := m.layoutEdit
+	layout := &m.layout
+	if len(layout.order) == 0 {
+		*layout = parsePanelLayout(nil)
+	}
+	layout.order = slices.Clone(layout.order)
+	le.cursor = min(le.cursor, len(layout.order)-1)
+	id := layout.order[le.cursor]
+
+	switch key {
+	case "esc", "l":
+		m.layoutEdit = nil
+		return m, nil
+	case "up":
+		le.cursor = max(le.cursor-1, 0)
+		return m, nil
+	case "down":
+		le.cursor = min(le.cursor+1, len(layout.order)-1)
+		return m, nil
+	case "u":
+		if le.cursor == 0 {
+			return m, nil
+		}
+		layout.order[le.cursor-1], layout.order[le.cursor] = id, layout.order[le.cursor-1]
+		le.cursor--
+	case "d":
+		if le.cursor == len(layout.order)-1 {
+			return m, nil
+		}
+		layout.order[le.cursor+1], layout.order[le.cursor] = id, layout.order[le.cursor+1]
+		le.cursor++
+	case " ":
+		if i := slices.Index(layout.hidden, id); i >= 0 {
+			layout.hidden = slices.Delete(slices.Clone(layout.hidden), i, i+1)
+		} else {
+			layout.hidden = append(slices.Clone(layout.hidden), id)
+		}
+	case "c":
+		layout.columns = (layout.columns + 1) % (maxGridColumns + 1)
+	case "r":
+		*layout = parsePanelLayout(nil)
+	default:
+		return m, nil
+	}
+	layout.save()
+	return m, nil
+}
+
+func renderLayoutEditor(le *layoutEditor, layout panelLayout) cardData {
+	if len(layout.order) == 0 {
+		layout = parsePanelLayout(nil)
+	}
+	columns := "auto"
+	if layout.columns > 0 {
+		columns = strconv.Itoa(layout.columns)
+	}
+	lines := []string{i18n.Tf("Columns %s · panels without data stay hidden", columns)}
+	for i, id := range layout.order {
+		marker := "  "
+		if i == min(le.cursor, len(layout.order)-1) {
+			marker = "› "
+		}
+		if slices.Contains(layout.hidden, id) {
+			lines = append(lines, marker+subtleStyle.Render("[ ] "+id))
+		} else {
+			lines = append(lines, marker+"[x] "+id)
+		}
+	}
+	lines = append(lines, subtleStyle.Render(i18n.T("↑/↓ select · u/d move · space show/hide · c columns · r reset · esc done")))
+	return cardData{icon: iconLayout, title: "Layout", lines: lines}
+}
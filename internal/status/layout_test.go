@@ -0,0 +1,75 @@
+package status
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPanelLayoutArrangesAndHidesCards(t *testing.T) {
+	layout := parsePanelLayout(map[string]string{
+		"panels":        "Power, processes,bogus,power",
+		"hidden_panels": "network,sensors",
+		"columns":       "3",
+	})
+	if layout.columns != 3 || len(layout.order) != len(defaultPanelOrder) {
+		t.Fatalf("layout = %+v", layout)
+	}
+
+	cards := []cardData{{title: "CPU"}, {title: "Memory"}, {title: "Processes by mem"}, {title: "Network"}, {title: "Power"}, {title: "Sensors"}}
+	var titles []string
+	for _, c := range layout.arrange(cards) {
+		titles = append(titles, c.title)
+	}
+	if want := []string{"Power", "Processes by mem", "CPU", "Memory"}; !slices.Equal(titles, want) {
+		t.Fatalf("arrange() = %v, want %v", titles, want)
+	}
+
+	if got := layout.gridColumns(200); got != 3 {
+		t.Fatalf("gridColumns(200) = %d, want 3", got)
+	}
+	if got := layout.gridColumns(90); got != 2 {
+		t.Fatalf("gridColumns(90) = %d, want 2 (3 cards do not fit)", got)
+	}
+	if got := (panelLayout{}).gridColumns(80); got != 1 {
+		t.Fatalf("auto gridColumns(80) = %d, want 1", got)
+	}
+	if got := parsePanelLayout(map[string]string{"columns": "auto"}).columns; got != 0 {
+		t.Fatalf("columns=auto parsed as %d", got)
+	}
+}
+
+func TestLayoutEditorSavesAlongsideCatPreference(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	// Files written before the layout keys existed hold a single line.
+	path := getConfigPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("cat_hidden=true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := model{layout: loadPanelLayout(), layoutEdit: &layoutEditor{}}
+	for _, key := range []string{"down", "u", "down", "down", "down", " ", "c"} {
+		updated, _ := m.handleLayoutKey(key)
+		m = updated.(model)
+	}
+
+	saved := loadPanelLayout()
+	if saved.order[0] != "memory" || saved.order[1] != "cpu" {
+		t.Fatalf("u should move memory above cpu, order = %v", saved.order[:3])
+	}
+	if !slices.Equal(saved.hidden, []string{"power"}) || saved.columns != 1 {
+		t.Fatalf("saved layout = %+v", saved)
+	}
+	if !loadCatHidden() {
+		t.Fatal("saving the layout dropped cat_hidden")
+	}
+
+	updated, _ := m.handleLayoutKey("esc")
+	if updated.(model).layoutEdit != nil {
+		t.Fatal("esc should close the layout editor")
+	}
+}
@@ -0,0 +1,868 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tw93/mole/internal/config"
+	"github.com/tw93/mole/internal/crash"
+	"github.com/tw93/mole/internal/debuglog"
+	"github.com/tw93/mole/internal/exitcode"
+	"github.com/tw93/mole/internal/helper"
+	"github.com/tw93/mole/internal/hyperlink"
+	"github.com/tw93/mole/internal/i18n"
+	"github.com/tw93/mole/internal/notify"
+	"github.com/tw93/mole/internal/output"
+	"github.com/tw93/mole/internal/theme"
+	"github.com/tw93/mole/internal/units"
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+const (
+	refreshInterval      = time.Second
+	processWatchInterval = refreshInterval
+	slowRefreshInterval  = 30 * time.Second
+)
+
+// Flags are status's command-line flags. The mole root command parses them
+// from the arguments after "status" and lists them for shell completion.
+var Flags = flag.NewFlagSet("status", flag.ExitOnError)
+
+var (
+	// Command-line flags
+	jsonOutput       = Flags.Bool("json", false, "output metrics as JSON instead of TUI")
+	outputTo         = output.AddFlag(Flags)
+	procCPUThreshold = Flags.Float64("proc-cpu-threshold", 100, "alert when a process stays above this CPU percent")
+	procCPUWindow    = Flags.Duration("proc-cpu-window", 5*time.Minute, "continuous duration a process must exceed the CPU threshold")
+	procCPUAlerts    = Flags.Bool("proc-cpu-alerts", true, "enable persistent high-CPU process alerts")
+	procSort         = Flags.String("proc-sort", sysmetrics.ProcessSortCPU, "rank top processes by cpu, mem, or energy")
+	pingTargets      = Flags.String("ping-targets", "gateway,1.1.1.1", "comma-separated hosts to ping for latency, jitter, and loss (\"gateway\" is the default route, \"none\" disables)")
+	publicIP         = Flags.Bool("public-ip", false, "look up the public IP, location, and ASN via ipinfo.io (sends a request off this machine)")
+
+	// Alert notifications: Notification Center, terminal bell, or webhook.
+	notifyFlags = notify.AddFlags(Flags)
+
+	// Watch mode: stream NDJSON (one snapshot per line) from a single warm collector.
+	watchMode    = Flags.Bool("watch", false, "stream metrics continuously as newline-delimited JSON instead of the one-shot TUI/JSON")
+	intervalFlag = Flags.String("interval", "", "collection interval for the TUI and --watch (e.g. 1s, 2s); defaults to 1s")
+
+	// Session capture: record TUI snapshots to NDJSON and replay them elsewhere.
+	recordSession  = Flags.String("record-session", "", "record snapshots shown in the TUI to `file` as newline-delimited JSON")
+	recordDuration = Flags.Duration("record-duration", defaultRecordDuration, "with --record-session, stop recording after this long (0 records until quit)")
+	replaySession  = Flags.String("replay", "", "replay a recorded session `file` in the TUI instead of collecting live metrics")
+
+	// Temperature coloring thresholds (°C) for the CPU and Sensors cards.
+	tempWarn   = Flags.Float64("temp-warn", sysmetrics.ThermalNormalThreshold, "color temperatures at or above this many °C as warnings")
+	tempDanger = Flags.Float64("temp-danger", sysmetrics.ThermalHighThreshold, "color temperatures at or above this many °C as critical")
+
+	// Narrow terminals collapse each card to one summary line.
+	compactMode = Flags.String("compact", compactAuto, "collapse cards to one-line summaries: auto (below 100 columns), on, or off")
+
+	clockDriftWarn = Flags.Duration("clock-drift-warn", sysmetrics.DefaultClockDriftWarn, "flag the clock when its NTP offset exceeds this (0 disables)")
+	backupWarnDays = Flags.Int("backup-warn-days", sysmetrics.DefaultBackupWarnDays, "flag Time Machine when no backup has completed in this many days (0 disables)")
+
+	themeName = Flags.String("theme", "", "color theme: dark, light, solarized, or high-contrast (defaults to $MO_THEME, then dark)")
+	noColor   = Flags.Bool("no-color", false, "plain ASCII output without color, as with NO_COLOR")
+	unitsFlag = Flags.String("units", "", "byte units: si (GB), binary (GiB), or auto (defaults to $MO_UNITS, then ~/.config/mole/units, then auto)")
+	langFlag  = Flags.String("lang", "", "interface language, e.g. en or zh (defaults to $MO_LANG, then the locale)")
+
+	configProfile = Flags.String("profile", "", "apply the named profile from ~/.config/mole/config.toml (defaults to $MO_PROFILE)")
+
+	// Tracing for performance reports.
+	debugLog = Flags.Bool("debug", false, "log collector timings, external commands, and cache hits")
+	logFile  = Flags.String("log-file", "", "write the log to `file` instead of stderr (the TUI defaults to a file in the temp dir)")
+
+	errorFormat = exitcode.AddFlag(Flags)
+)
+
+func shouldUseJSONOutput(forceJSON bool, stdout *os.File) bool {
+	if forceJSON {
+		return true
+	}
+	if stdout == nil {
+		return false
+	}
+	info, err := stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return (info.Mode() & os.ModeCharDevice) == 0
+}
+
+type tickMsg struct{}
+type animTickMsg struct{}
+
+type collectionMode int
+
+const (
+	collectionFast collectionMode = iota
+	collectionProcess
+	collectionFull
+	collectionBoost // Fast with processes, plus the boosted panel's collectors
+)
+
+type metricsMsg struct {
+	data sysmetrics.MetricsSnapshot
+	err  error
+	mode collectionMode
+}
+
+type model struct {
+	collector     *sysmetrics.Collector
+	width         int
+	height        int
+	metrics       sysmetrics.MetricsSnapshot
+	errMessage    string
+	ready         bool
+	lastUpdated   time.Time
+	lastFullAt    time.Time
+	lastProcessAt time.Time
+	collecting    bool
+	animFrame     int
+	catHidden     bool // true = hidden, false = visible
+	recorder      *sessionRecorder
+	replay        *sessionReplay
+	replayDelay   time.Duration
+	inspect       *processInspect
+	services      *serviceControl
+	layout        panelLayout
+	layoutEdit    *layoutEditor
+	focus         string // panel ID shown full screen, "" for the dashboard
+	speedTesting  bool
+	speedTestNote string // progress or last error, shown under the header
+	interval      time.Duration
+	paused        bool
+	boost         *refreshBoost
+	alerts        *alertNotifier
+}
+
+// padViewToHeight ensures the rendered frame always overwrites the full
+// terminal region by padding with empty lines up to the current height.
+func padViewToHeight(view string, height int) string {
+	if height <= 0 {
+		return view
+	}
+
+	contentHeight := lipgloss.Height(view)
+	if contentHeight >= height {
+		return view
+	}
+
+	return view + strings.Repeat("\n", height-contentHeight)
+}
+
+// getConfigPath returns the path to the status preferences file.
+func getConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "mole", "status_prefs")
+}
+
+// loadPrefs reads status_prefs, one key=value per line.
+func loadPrefs() map[string]string {
+	prefs := make(map[string]string)
+	path := getConfigPath()
+	if path == "" {
+		return prefs
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return prefs
+	}
+	for line := range strings.Lines(string(data)) {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok && key != "" && !strings.HasPrefix(key, "#") {
+			prefs[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return prefs
+}
+
+// savePrefs writes the given preferences and keeps the others.
+func savePrefs(updates map[string]string) {
+	path := getConfigPath()
+	if path == "" {
+		return
+	}
+	// Ensure directory exists
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	prefs := loadPrefs()
+	maps.Copy(prefs, updates)
+	var out strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(prefs)) {
+		fmt.Fprintf(&out, "%s=%s\n", key, prefs[key])
+	}
+	_ = os.WriteFile(path, []byte(out.String()), 0644)
+}
+
+// loadCatHidden loads the cat hidden preference from config file.
+func loadCatHidden() bool {
+	return loadPrefs()["cat_hidden"] == "true"
+}
+
+// saveCatHidden saves the cat hidden preference to config file.
+func saveCatHidden(hidden bool) {
+	savePrefs(map[string]string{"cat_hidden": strconv.FormatBool(hidden)})
+}
+
+func newModel(interval time.Duration) model {
+	return model{
+		collector: newCollectorFromFlags(),
+		catHidden: loadCatHidden(),
+		layout:    loadPanelLayout(),
+		interval:  interval,
+		alerts:    &alertNotifier{},
+	}
+}
+
+func processWatchOptionsFromFlags() sysmetrics.ProcessWatchOptions {
+	return sysmetrics.ProcessWatchOptions{
+		Enabled:      *procCPUAlerts,
+		CPUThreshold: *procCPUThreshold,
+		Window:       *procCPUWindow,
+	}
+}
+
+// newCollectorFromFlags builds the collector every mode shares.
+func newCollectorFromFlags() *sysmetrics.Collector {
+	collector := sysmetrics.NewCollector(processWatchOptionsFromFlags())
+	collector.SetProcessSort(*procSort)
+	collector.SetSpeedTests(loadSpeedTestHistory())
+	collector.SetPingTargets(sysmetrics.ParsePingTargets(*pingTargets))
+	collector.SetBackupWarnAge(time.Duration(*backupWarnDays) * 24 * time.Hour)
+	collector.SetClockDriftWarn(*clockDriftWarn)
+	if helper.Available() {
+		collector.SetPowermetricsRunner(helper.Powermetrics)
+	}
+	if *publicIP {
+		collector.EnablePublicIP()
+	}
+	return collector
+}
+
+func validateFlags() error {
+	if !sysmetrics.ValidProcessSort(*procSort) {
+		return fmt.Errorf("--proc-sort must be one of %s", strings.Join(sysmetrics.ProcessSortKeys, ", "))
+	}
+	if *procCPUThreshold < 0 {
+		return fmt.Errorf("--proc-cpu-threshold must be >= 0")
+	}
+	if *procCPUWindow <= 0 {
+		return fmt.Errorf("--proc-cpu-window must be > 0")
+	}
+	if *recordDuration < 0 {
+		return fmt.Errorf("--record-duration must be >= 0")
+	}
+	if *tempWarn <= 0 || *tempDanger <= *tempWarn {
+		return fmt.Errorf("--temp-warn must be > 0 and below --temp-danger")
+	}
+	if *clockDriftWarn < 0 {
+		return fmt.Errorf("--clock-drift-warn must be >= 0")
+	}
+	if *backupWarnDays < 0 {
+		return fmt.Errorf("--backup-warn-days must be >= 0")
+	}
+	if !slices.Contains([]string{compactAuto, compactOn, compactOff}, *compactMode) {
+		return fmt.Errorf("--compact must be auto, on, or off")
+	}
+	if _, err := theme.Resolve(*themeName); err != nil {
+		return fmt.Errorf("--theme: %w", err)
+	}
+	if _, err := units.ResolveSystem(*unitsFlag); err != nil {
+		return fmt.Errorf("--units: %w", err)
+	}
+	if *replaySession != "" && *recordSession != "" {
+		return fmt.Errorf("--replay and --record-session cannot be combined")
+	}
+	if (*replaySession != "" || *recordSession != "") && (*watchMode || *jsonOutput) {
+		return fmt.Errorf("--record-session and --replay only apply to the interactive TUI; use --watch > file for headless capture")
+	}
+	return nil
+}
+
+func (m model) Init() tea.Cmd {
+	return tea.Batch(tickAfter(0), animTick())
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		key := msg.String()
+		if m.inspect != nil && key != "q" && key != "ctrl+c" {
+			return m.handleInspectKey(key)
+		}
+		if m.services != nil && key != "q" && key != "ctrl+c" {
+			return m.handleServicesKey(key)
+		}
+		if m.layoutEdit != nil && key != "q" && key != "ctrl+c" {
+			return m.handleLayoutKey(key)
+		}
+		if m.focus != "" {
+			switch key {
+			case "esc", "f":
+				m.focus = ""
+				return m, nil
+			case "tab", "right":
+				m.focus = m.stepFocus(1)
+				return m, nil
+			case "shift+tab", "left":
+				m.focus = m.stepFocus(-1)
+				return m, nil
+			}
+		}
+		switch key {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "1", "2", "3":
+			return m.openInspect(int(key[0] - '0'))
+		case "a":
+			return m.openFlagged()
+		case "k":
+			// Toggle cat visibility and persist preference
+			m.catHidden = !m.catHidden
+			saveCatHidden(m.catHidden)
+			return m, nil
+		case "s":
+			m.cycleProcessSort()
+			return m, nil
+		case "v":
+			return m.openServices()
+		case "l":
+			m.layoutEdit = &layoutEditor{}
+			return m, nil
+		case "f":
+			m.focus = m.stepFocus(0)
+			return m, nil
+		case "p":
+			m.paused = !m.paused
+			if !m.paused {
+				return m, tickAfter(0)
+			}
+			return m, nil
+		case ".":
+			return m.step()
+		case "b":
+			if m.replay != nil {
+				return m, nil
+			}
+			m.boost = nextBoost(m.boost, time.Now())
+			return m, nil
+		case "n":
+			if m.replay != nil || m.speedTesting {
+				return m, nil
+			}
+			m.speedTesting = true
+			m.speedTestNote = subtleStyle.Render(i18n.T("Running speed test..."))
+			return m, speedTestCmd()
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+	case tickMsg:
+		if m.boost != nil && !time.Now().Before(m.boost.until) {
+			m.boost = nil
+		}
+		if m.collecting || m.paused {
+			return m, nil
+		}
+		if m.replay != nil {
+			return m.nextReplayFrame()
+		}
+		m.collecting = true
+		return m, m.collectCmd(m.nextCollectionMode(time.Now()))
+	case metricsMsg:
+		wasReady := m.ready
+		if msg.err != nil {
+			m.errMessage = msg.err.Error()
+		} else {
+			m.errMessage = ""
+		}
+		m.metrics = msg.data
+		m.lastUpdated = msg.data.CollectedAt
+		if msg.err == nil {
+			recordCollectionFreshness(msg.mode, msg.data.CollectedAt, &m.lastFullAt, &m.lastProcessAt)
+			if _, err := m.recorder.Record(msg.data); err != nil {
+				m.errMessage = err.Error()
+			}
+		}
+		m.collecting = false
+		// Mark ready after first successful data collection.
+		if !m.ready {
+			m.ready = true
+		}
+		if m.paused {
+			return m, nil
+		}
+		var notifyCmd tea.Cmd
+		if m.alerts != nil && msg.err == nil {
+			notifyCmd = m.alerts.cmd(msg.data.ProcessAlerts)
+		}
+		delay := m.refreshInterval()
+		if m.replay != nil {
+			if m.replay.Done() {
+				return m, nil
+			}
+			delay = m.replayDelay
+		} else if !wasReady {
+			delay = 0
+		}
+		return m, tea.Batch(tickAfter(delay), notifyCmd)
+	case speedTestMsg:
+		m.speedTesting = false
+		m.speedTestNote = ""
+		if msg.err != nil {
+			m.speedTestNote = dangerStyle.Render(msg.err.Error())
+			return m, nil
+		}
+		history := m.collector.AddSpeedTest(msg.result)
+		saveSpeedTestHistory(history)
+		m.metrics.SpeedTests = slices.Clone(history)
+		return m, nil
+	case processDetailMsg:
+		if m.inspect == nil || m.inspect.target.PID != msg.pid {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.inspect.message = msg.err.Error()
+		} else {
+			m.inspect.detail = &msg.detail
+		}
+		return m, nil
+	case processActionMsg:
+		if m.inspect != nil {
+			m.inspect.message = msg.message
+			if msg.err != nil {
+				m.inspect.message = dangerStyle.Render(msg.err.Error())
+			}
+		}
+		return m, nil
+	case serviceActionMsg:
+		if m.services != nil {
+			m.services.message = msg.message
+			if msg.err != nil {
+				m.services.message = dangerStyle.Render(msg.err.Error())
+			}
+		}
+		return m, nil
+	case animTickMsg:
+		m.animFrame++
+		return m, animTickWithSpeed(m.metrics.CPU.Usage)
+	}
+	return m, nil
+}
+
+func (m model) View() string {
+	if !m.ready {
+		return i18n.T("Loading...")
+	}
+
+	termWidth := m.width
+	if termWidth <= 0 {
+		termWidth = 80
+	}
+
+	header, mole := renderHeader(m.metrics, m.errMessage, m.animFrame, termWidth, m.catHidden)
+	alertBar := renderProcessAlertBar(m.metrics.ProcessAlerts, termWidth)
+	sessionLine := renderSessionLine(m.recorder, m.replay, termWidth)
+	refreshLine := renderRefreshLine(m.paused, m.boost, m.refreshInterval(), time.Now(), termWidth)
+
+	var cardContent string
+	if useCompactLayout(*compactMode, termWidth) {
+		cards := m.layout.arrange(buildCards(m.metrics, max(24, termWidth-2)))
+		cardContent = renderCompactCards(cards, termWidth)
+	} else if columns := m.layout.gridColumns(termWidth); columns == 1 {
+		cardWidth := termWidth
+		if cardWidth > 2 {
+			cardWidth -= 2
+		}
+		cards := m.layout.arrange(buildCards(m.metrics, cardWidth))
+
+		var rendered []string
+		for i, c := range cards {
+			if i > 0 {
+				rendered = append(rendered, "")
+			}
+			rendered = append(rendered, renderCard(c, cardWidth, 0))
+		}
+		cardContent = lipgloss.JoinVertical(lipgloss.Left, rendered...)
+	} else {
+		cardWidth := max(24, termWidth/columns-4)
+		cards := m.layout.arrange(buildCards(m.metrics, cardWidth))
+		cardContent = renderColumns(cards, termWidth, columns)
+	}
+
+	if m.focus != "" {
+		focused := renderCard(renderFocusCard(m.metrics, m.focus, termWidth-2), termWidth-2, 0)
+		hint := subtleStyle.Render("  " + i18n.T("tab next panel · shift+tab previous · esc back to dashboard"))
+		output := lipgloss.JoinVertical(lipgloss.Left, focused, hint)
+		return padViewToHeight(output, m.height)
+	}
+
+	// Combine header, mole, and cards with consistent spacing
+	parts := []string{header}
+	if sessionLine != "" {
+		parts = append(parts, sessionLine)
+	}
+	if refreshLine != "" {
+		parts = append(parts, refreshLine)
+	}
+	if m.speedTestNote != "" {
+		parts = append(parts, "  "+m.speedTestNote)
+	}
+	if alertBar != "" {
+		parts = append(parts, alertBar)
+	}
+	if mole != "" {
+		parts = append(parts, mole)
+	}
+	if m.inspect != nil {
+		parts = append(parts, renderCard(renderInspectCard(m.inspect), max(24, termWidth-2), 0))
+	}
+	if m.services != nil {
+		parts = append(parts, renderCard(renderServicesPanel(m.services, m.metrics.Services), max(24, termWidth-2), 0))
+	}
+	if m.layoutEdit != nil {
+		parts = append(parts, renderCard(renderLayoutEditor(m.layoutEdit, m.layout), max(24, termWidth-2), 0))
+	}
+	parts = append(parts, cardContent)
+	output := lipgloss.JoinVertical(lipgloss.Left, parts...)
+	return padViewToHeight(output, m.height)
+}
+
+func (m model) nextCollectionMode(now time.Time) collectionMode {
+	mode := nextCollectionMode(m.ready, m.lastFullAt, m.lastProcessAt, now)
+	if mode != collectionFull && m.boost != nil && now.Before(m.boost.until) {
+		return collectionBoost
+	}
+	return mode
+}
+
+func (m model) refreshInterval() time.Duration {
+	if m.interval > 0 {
+		return m.interval
+	}
+	return refreshInterval
+}
+
+// step runs one collection while paused: the next recorded frame in a
+// replay, otherwise a full refresh so every card updates.
+func (m model) step() (tea.Model, tea.Cmd) {
+	if !m.paused || m.collecting {
+		return m, nil
+	}
+	if m.replay != nil {
+		return m.nextReplayFrame()
+	}
+	m.collecting = true
+	return m, m.collectCmd(collectionFull)
+}
+
+func nextCollectionMode(ready bool, lastFullAt, lastProcessAt, now time.Time) collectionMode {
+	if !ready {
+		return collectionFast
+	}
+	if lastFullAt.IsZero() || now.Sub(lastFullAt) >= slowRefreshInterval {
+		return collectionFull
+	}
+	if lastProcessAt.IsZero() || now.Sub(lastProcessAt) >= processWatchInterval {
+		return collectionProcess
+	}
+	return collectionFast
+}
+
+func recordCollectionFreshness(mode collectionMode, collectedAt time.Time, lastFullAt, lastProcessAt *time.Time) {
+	if mode == collectionFull {
+		*lastFullAt = collectedAt
+	}
+	if mode == collectionProcess || mode == collectionFull || mode == collectionBoost {
+		*lastProcessAt = collectedAt
+	}
+}
+
+// nextReplayFrame feeds the next recorded snapshot through the same
+// metricsMsg path live collection uses, so rendering stays identical.
+func (m model) nextReplayFrame() (tea.Model, tea.Cmd) {
+	frame, delay, ok := m.replay.Next()
+	if !ok {
+		return m, nil
+	}
+	m.collecting = true
+	m.replayDelay = delay
+	return m, func() tea.Msg {
+		return metricsMsg{data: frame, mode: collectionFull}
+	}
+}
+
+// cycleProcessSort moves to the next sort key and re-sorts the processes on
+// screen right away; the collector picks the new ranking up next refresh.
+func (m *model) cycleProcessSort() {
+	key := sysmetrics.NextProcessSort(m.metrics.ProcessSort)
+	m.metrics.ProcessSort = key
+	m.metrics.TopProcesses = sysmetrics.SortProcesses(m.metrics.TopProcesses, key)
+	if m.collector != nil {
+		m.collector.SetProcessSort(key)
+	}
+}
+
+func (m model) collectCmd(mode collectionMode) tea.Cmd {
+	return func() tea.Msg {
+		var (
+			data sysmetrics.MetricsSnapshot
+			err  error
+		)
+		switch mode {
+		case collectionFull:
+			data, err = m.collector.Collect(context.Background())
+		case collectionProcess:
+			data, err = m.collector.CollectProcesses(context.Background())
+		case collectionBoost:
+			data, err = m.collector.CollectBoosted(context.Background(), m.boost.name())
+		default:
+			data, err = m.collector.CollectFast(context.Background())
+		}
+		return metricsMsg{data: data, err: err, mode: mode}
+	}
+}
+
+func tickAfter(delay time.Duration) tea.Cmd {
+	return tea.Tick(delay, func(time.Time) tea.Msg { return tickMsg{} })
+}
+
+func animTick() tea.Cmd {
+	return tea.Tick(200*time.Millisecond, func(time.Time) tea.Msg { return animTickMsg{} })
+}
+
+func animTickWithSpeed(cpuUsage float64) tea.Cmd {
+	// Higher CPU = faster animation.
+	interval := max(300-int(cpuUsage*2.5), 50)
+	return tea.Tick(time.Duration(interval)*time.Millisecond, func(time.Time) tea.Msg { return animTickMsg{} })
+}
+
+// runJSONMode collects metrics once and outputs as JSON.
+func runJSONMode() {
+	collector := newCollectorFromFlags()
+
+	data, err := collector.Collect(context.Background())
+	if err != nil {
+		exitcode.Exit("status", exitcode.Failed, fmt.Errorf("error collecting metrics: %w", err))
+	}
+
+	out := openOutput("status")
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		out.Abort()
+		exitcode.Exit("status", exitcode.Failed, fmt.Errorf("error encoding JSON: %w", err))
+	}
+	closeOutput("status", out)
+}
+
+// openOutput opens where --output sends command's report, stdout without
+// it.
+func openOutput(command string) *output.File {
+	out, err := output.Create(output.Path(*outputTo))
+	if err != nil {
+		exitcode.Exit(command, exitcode.Failed, err)
+	}
+	return out
+}
+
+// closeOutput moves a finished report into place.
+func closeOutput(command string, out *output.File) {
+	if err := out.Close(); err != nil {
+		exitcode.Exit(command, exitcode.Failed, err)
+	}
+}
+
+// runTUIMode runs the interactive terminal UI.
+func runTUIMode(interval time.Duration) {
+	m := newModel(interval)
+	if *recordSession != "" {
+		recorder, err := newSessionRecorder(*recordSession, *recordDuration, time.Now())
+		if err != nil {
+			exitcode.Exit("status", exitcode.Failed, err)
+		}
+		m.recorder = recorder
+	}
+
+	p := tea.NewProgram(crash.Model(m), tea.WithAltScreen(), tea.WithoutCatchPanics())
+	_, err := p.Run()
+	m.collector.Close()
+	if closeErr := m.recorder.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		exitcode.Exit("status", exitcode.Failed, fmt.Errorf("system status error: %w", err))
+	}
+	if m.recorder != nil {
+		fmt.Fprintf(os.Stderr, "Recorded %d snapshots to %s\n", m.recorder.frames, *recordSession)
+	}
+}
+
+// runReplayMode plays a recorded session back in the TUI without collecting.
+func runReplayMode(path string) {
+	replay, err := loadSessionReplay(path)
+	if err != nil {
+		exitcode.Exit("status", exitcode.Failed, err)
+	}
+
+	m := model{catHidden: loadCatHidden(), layout: loadPanelLayout(), replay: replay}
+	p := tea.NewProgram(crash.Model(m), tea.WithAltScreen(), tea.WithoutCatchPanics())
+	if _, err := p.Run(); err != nil {
+		exitcode.Exit("status", exitcode.Failed, fmt.Errorf("system status error: %w", err))
+	}
+}
+
+func parseInterval(raw string) (time.Duration, error) {
+	if raw == "" {
+		return refreshInterval, nil
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --interval %q (want e.g. 1s, 2s): %w", raw, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid --interval %q (must be > 0)", raw)
+	}
+	return d, nil
+}
+
+// jsonRequested reports whether --json was given, or --output, which saves
+// the JSON snapshot since a TUI cannot be saved.
+func jsonRequested() bool {
+	return *jsonOutput || output.Path(*outputTo) != ""
+}
+
+// tuiRequested reports whether main will hand the terminal to the TUI.
+func tuiRequested() bool {
+	if Flags.Arg(0) != "" || *watchMode {
+		return false
+	}
+	return *replaySession != "" || *recordSession != "" || !shouldUseJSONOutput(jsonRequested(), os.Stdout)
+}
+
+// setupLogging starts the --debug/--log-file log. The TUI owns the terminal,
+// so a --debug TUI run without --log-file logs to a temp file and returns its
+// path to print on exit.
+func setupLogging() (io.Closer, string, error) {
+	debug, path, defaulted := debuglog.Requested(*debugLog), *logFile, false
+	if debug && path == "" && tuiRequested() {
+		path, defaulted = filepath.Join(os.TempDir(), "mole-status.log"), true
+	}
+	closer, err := debuglog.Setup(debug, path)
+	if err != nil {
+		return nil, "", fmt.Errorf("--log-file: %w", err)
+	}
+	if !defaulted {
+		path = ""
+	}
+	return closer, path, nil
+}
+
+// Main runs status with args, the command line after the program or
+// subcommand name. Like the old main, it exits the process on errors and
+// for check and doctor.
+func Main(args []string) {
+	Flags.Parse(args)
+	if err := exitcode.SetFormat(*errorFormat); err != nil {
+		exitcode.Exit("status", exitcode.Usage, err)
+	}
+	if err := config.Apply(Flags, "status", *configProfile); err != nil {
+		exitcode.Exit("status", exitcode.Usage, err)
+	}
+	if err := validateFlags(); err != nil {
+		exitcode.Exit("status", exitcode.Usage, err)
+	}
+	var err error
+	if notifier, err = notifyFlags.Notifier(); err != nil {
+		exitcode.Exit("status", exitcode.Usage, err)
+	}
+
+	// validateFlags already rejected an unknown theme or units.
+	p, _ := theme.Resolve(*themeName)
+	palette = applyTheme(p)
+	unitSystem, _ = units.ResolveSystem(*unitsFlag)
+	profile := theme.Detect(os.Getenv)
+	if *noColor {
+		profile = theme.NoColor
+	}
+	if profile == theme.NoColor {
+		useASCIIGlyphs()
+	}
+	if tuiRequested() || profile == theme.NoColor {
+		lipgloss.SetColorProfile(colorProfiles[profile])
+	}
+	lang := *langFlag
+	if lang == "" {
+		lang = i18n.Detect(os.Getenv)
+	}
+	i18n.Set(lang)
+	hyperlink.Enable(tuiRequested() && profile != theme.NoColor && hyperlink.Supported(os.Getenv))
+
+	logCloser, logPath, err := setupLogging()
+	if err != nil {
+		exitcode.Exit("status", exitcode.Usage, err)
+	}
+	defer logCloser.Close()
+	if logPath != "" {
+		defer fmt.Fprintf(os.Stderr, "debug log written to %s\n", logPath)
+	}
+
+	if Flags.Arg(0) == "check" {
+		runCheckMode(Flags.Args()[1:])
+		return
+	}
+	if Flags.Arg(0) == "doctor" {
+		runDoctorMode()
+		return
+	}
+
+	if *replaySession != "" {
+		runReplayMode(*replaySession)
+		return
+	}
+
+	interval, err := parseInterval(*intervalFlag)
+	if err != nil {
+		exitcode.Exit("status", exitcode.Usage, err)
+	}
+	if *watchMode {
+		runWatchMode(interval)
+		return
+	}
+
+	if *recordSession == "" && shouldUseJSONOutput(jsonRequested(), os.Stdout) {
+		runJSONMode()
+	} else {
+		runTUIMode(interval)
+	}
+}
+
+func activeAlerts(alerts []sysmetrics.ProcessAlert) []sysmetrics.ProcessAlert {
+	var active []sysmetrics.ProcessAlert
+	for _, alert := range alerts {
+		if alert.Status == "active" {
+			active = append(active, alert)
+		}
+	}
+	return active
+}
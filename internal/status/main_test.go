@@ -1,11 +1,16 @@
-package main
+package status
 
 import (
 	"errors"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/tw93/mole/pkg/sysmetrics"
 )
 
 func TestShouldUseJSONOutput_ForceFlag(t *testing.T) {
@@ -93,7 +98,7 @@ func TestValidateFlags(t *testing.T) {
 	}
 }
 
-func TestParseWatchInterval(t *testing.T) {
+func TestParseInterval(t *testing.T) {
 	tests := []struct {
 		name    string
 		raw     string
@@ -109,18 +114,18 @@ func TestParseWatchInterval(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := parseWatchInterval(tt.raw)
+			got, err := parseInterval(tt.raw)
 			if tt.wantErr {
 				if err == nil {
-					t.Fatalf("parseWatchInterval(%q) returned nil error", tt.raw)
+					t.Fatalf("parseInterval(%q) returned nil error", tt.raw)
 				}
 				return
 			}
 			if err != nil {
-				t.Fatalf("parseWatchInterval(%q) error = %v", tt.raw, err)
+				t.Fatalf("parseInterval(%q) error = %v", tt.raw, err)
 			}
 			if got != tt.want {
-				t.Fatalf("parseWatchInterval(%q) = %v, want %v", tt.raw, got, tt.want)
+				t.Fatalf("parseInterval(%q) = %v, want %v", tt.raw, got, tt.want)
 			}
 		})
 	}
@@ -193,7 +198,7 @@ func TestFullCollectionErrorDoesNotMarkFullFresh(t *testing.T) {
 	}
 
 	updated, _ := m.Update(metricsMsg{
-		data: MetricsSnapshot{
+		data: sysmetrics.MetricsSnapshot{
 			CollectedAt: now,
 		},
 		err:  errors.New("full collector failed"),
@@ -214,7 +219,7 @@ func TestProcessCollectionUpdatesProcessFreshness(t *testing.T) {
 	m := model{ready: true}
 
 	updated, _ := m.Update(metricsMsg{
-		data: MetricsSnapshot{CollectedAt: now},
+		data: sysmetrics.MetricsSnapshot{CollectedAt: now},
 		mode: collectionProcess,
 	})
 	got := updated.(model)
@@ -227,173 +232,6 @@ func TestProcessCollectionUpdatesProcessFreshness(t *testing.T) {
 	}
 }
 
-func TestCollectorAppliesCachedEnrichmentToFastSnapshot(t *testing.T) {
-	previous := MetricsSnapshot{
-		CPU:         CPUStatus{PCoreCount: 8, ECoreCount: 4},
-		Memory:      MemoryStatus{Cached: 512, Pressure: "warn"},
-		Hardware:    HardwareInfo{Model: "MacBook Pro", CPUModel: "M3", OSVersion: "macOS 15", RefreshRate: "120Hz"},
-		GPU:         []GPUStatus{{Name: "Apple GPU", Usage: 12}},
-		TrashSize:   42,
-		TrashApprox: true,
-		Proxy:       ProxyStatus{Enabled: true, Type: "HTTP", Host: "127.0.0.1:8080"},
-		Batteries:   []BatteryStatus{{Percent: 80, Capacity: 92}},
-		Thermal:     ThermalStatus{CPUTemp: 45},
-		Sensors:     []SensorReading{{Label: "Fan", Value: 1200, Unit: "rpm"}},
-		Bluetooth:   []BluetoothDevice{{Name: "Keyboard", Connected: true}},
-		TopProcesses: []ProcessInfo{
-			{PID: 42, Name: "Xcode", CPU: 82},
-		},
-		ProcessAlerts: []ProcessAlert{
-			{PID: 42, Name: "Xcode", CPU: 140, Status: "active"},
-		},
-	}
-
-	collector := NewCollector(ProcessWatchOptions{})
-	collector.cacheEnrichment(previous)
-	previous.GPU[0].Name = "mutated"
-
-	next := MetricsSnapshot{
-		UptimeSeconds: 60,
-		Hardware:      HardwareInfo{TotalRAM: "16G", DiskSize: "1T"},
-		CPU:           CPUStatus{Usage: 10},
-		Memory:        MemoryStatus{UsedPercent: 30, Pressure: "normal"},
-		Disks:         []DiskStatus{{Mount: "/", Total: 100, Used: 20, UsedPercent: 20}},
-		DiskIO:        DiskIOStatus{ReadRate: 1, WriteRate: 1},
-	}
-
-	collector.applyEnrichment(&next, false)
-
-	if next.Hardware.Model != "MacBook Pro" {
-		t.Fatalf("expected hardware details to be preserved, got %#v", next.Hardware)
-	}
-	if next.CPU.PCoreCount != 8 || next.CPU.ECoreCount != 4 {
-		t.Fatalf("expected CPU topology to be preserved, got %#v", next.CPU)
-	}
-	if next.Memory.Cached != 512 || next.Memory.Pressure != "warn" {
-		t.Fatalf("expected slow memory annotations to be preserved, got %#v", next.Memory)
-	}
-	if next.TrashSize != 42 || !next.TrashApprox {
-		t.Fatalf("expected trash metadata to be preserved, got size=%d approx=%v", next.TrashSize, next.TrashApprox)
-	}
-	if !next.Proxy.Enabled || next.Proxy.Host != "127.0.0.1:8080" {
-		t.Fatalf("expected proxy metadata to be preserved, got %#v", next.Proxy)
-	}
-	if len(next.GPU) != 1 || next.GPU[0].Name != "Apple GPU" {
-		t.Fatalf("expected GPU metadata to be preserved from cache, got %#v", next.GPU)
-	}
-	if len(next.Batteries) != 1 || next.Batteries[0].Capacity != 92 {
-		t.Fatalf("expected battery metadata to be preserved, got %#v", next.Batteries)
-	}
-	if next.Thermal.CPUTemp != 45 {
-		t.Fatalf("expected thermal metadata to be preserved, got %#v", next.Thermal)
-	}
-	if len(next.Bluetooth) != 1 || next.Bluetooth[0].Name != "Keyboard" {
-		t.Fatalf("expected Bluetooth metadata to be preserved, got %#v", next.Bluetooth)
-	}
-	if len(next.TopProcesses) != 1 || next.TopProcesses[0].Name != "Xcode" {
-		t.Fatalf("expected top processes to be preserved, got %#v", next.TopProcesses)
-	}
-	if len(next.ProcessAlerts) != 1 || next.ProcessAlerts[0].Status != "active" {
-		t.Fatalf("expected process alerts to be preserved, got %#v", next.ProcessAlerts)
-	}
-	if next.HealthScore == 0 || next.HealthScoreMsg == "" {
-		t.Fatalf("expected health score to be recalculated, got %d %q", next.HealthScore, next.HealthScoreMsg)
-	}
-}
-
-func TestCollectorAppliesZeroValueEnrichmentExactly(t *testing.T) {
-	collector := NewCollector(ProcessWatchOptions{})
-	collector.cacheEnrichment(MetricsSnapshot{
-		Memory: MemoryStatus{
-			Cached:   0,
-			Pressure: "",
-		},
-	})
-
-	next := MetricsSnapshot{
-		Memory: MemoryStatus{
-			Cached:   512,
-			Pressure: "critical",
-		},
-	}
-
-	collector.applyEnrichment(&next, false)
-
-	if next.Memory.Cached != 0 || next.Memory.Pressure != "" {
-		t.Fatalf("expected exact memory enrichment, got %#v", next.Memory)
-	}
-}
-
-func TestCollectorOverridesFastDisksWithCorrectedCache(t *testing.T) {
-	collector := NewCollector(ProcessWatchOptions{})
-	collector.cacheEnrichment(MetricsSnapshot{
-		Disks: []DiskStatus{
-			{Mount: "/", Total: 1000, Used: 600, UsedPercent: 60, External: false},
-		},
-	})
-
-	// Fast path produced raw statfs numbers that ignore APFS purgeable space.
-	next := MetricsSnapshot{
-		Disks: []DiskStatus{
-			{Mount: "/", Total: 1000, Used: 900, UsedPercent: 90, External: true},
-		},
-	}
-
-	collector.applyEnrichment(&next, false)
-
-	if len(next.Disks) != 1 {
-		t.Fatalf("expected one disk, got %#v", next.Disks)
-	}
-	if next.Disks[0].Used != 600 || next.Disks[0].UsedPercent != 60 || next.Disks[0].External {
-		t.Fatalf("expected corrected disk values from cache, got %#v", next.Disks[0])
-	}
-}
-
-func TestCollectorKeepsFastDisksWhenCacheHasNone(t *testing.T) {
-	collector := NewCollector(ProcessWatchOptions{})
-	// First full refresh failed to enumerate disks; the cache should not blank
-	// out the fast path's raw disks.
-	collector.cacheEnrichment(MetricsSnapshot{Disks: nil})
-
-	next := MetricsSnapshot{
-		Disks: []DiskStatus{
-			{Mount: "/", Total: 1000, Used: 900, UsedPercent: 90},
-		},
-	}
-
-	collector.applyEnrichment(&next, false)
-
-	if len(next.Disks) != 1 || next.Disks[0].Used != 900 {
-		t.Fatalf("expected raw fast disks to survive empty cache, got %#v", next.Disks)
-	}
-}
-
-func TestCollectorKeepsLiveProcessDataWhenApplyingEnrichment(t *testing.T) {
-	collector := NewCollector(ProcessWatchOptions{})
-	collector.cacheEnrichment(MetricsSnapshot{
-		TopProcesses: []ProcessInfo{{PID: 1, Name: "old", CPU: 10}},
-		ProcessAlerts: []ProcessAlert{
-			{PID: 1, Name: "old", Status: "active"},
-		},
-	})
-
-	next := MetricsSnapshot{
-		TopProcesses: []ProcessInfo{{PID: 2, Name: "new", CPU: 90}},
-		ProcessAlerts: []ProcessAlert{
-			{PID: 2, Name: "new", Status: "active"},
-		},
-	}
-
-	collector.applyEnrichment(&next, true)
-
-	if len(next.TopProcesses) != 1 || next.TopProcesses[0].Name != "new" {
-		t.Fatalf("expected live top process data, got %#v", next.TopProcesses)
-	}
-	if len(next.ProcessAlerts) != 1 || next.ProcessAlerts[0].Name != "new" {
-		t.Fatalf("expected live process alerts, got %#v", next.ProcessAlerts)
-	}
-}
-
 func TestMetricsSnapshotFieldsHaveCollectionClassifications(t *testing.T) {
 	classified := map[string]string{
 		"CollectedAt":    "fast",
@@ -401,6 +239,7 @@ func TestMetricsSnapshotFieldsHaveCollectionClassifications(t *testing.T) {
 		"Platform":       "fast",
 		"Uptime":         "fast",
 		"UptimeSeconds":  "fast",
+		"BootTime":       "fast",
 		"Procs":          "fast",
 		"Hardware":       "enrichment",
 		"HealthScore":    "recomputed",
@@ -412,19 +251,49 @@ func TestMetricsSnapshotFieldsHaveCollectionClassifications(t *testing.T) {
 		"TrashSize":      "enrichment",
 		"TrashApprox":    "enrichment",
 		"DiskIO":         "fast",
+		"DiskHealth":     "enrichment",
+		"Storage":        "enrichment",
 		"Network":        "fast",
 		"NetworkHistory": "fast",
+		"NetworkProcs":   "enrichment",
 		"Proxy":          "enrichment",
+		"VPN":            "enrichment",
+		"PublicIP":       "enrichment",
+		"Latency":        "enrichment",
+		"DNS":            "enrichment",
+		"Containers":     "enrichment",
+		"Kubernetes":     "enrichment",
+		"VMs":            "enrichment",
+		"Peripherals":    "enrichment",
+		"Displays":       "enrichment",
+		"LaunchItems":    "enrichment",
+		"Services":       "enrichment",
+		"TimeMachine":    "enrichment",
+		"CrashReports":   "enrichment",
+		"Spotlight":      "enrichment",
+		"ICloud":         "enrichment",
+		"Clock":          "enrichment",
+		"Session":        "enrichment",
+		"PowerEvents":    "enrichment",
+		"Limits":         "enrichment",
+		"Ports":          "enrichment",
 		"Batteries":      "enrichment",
 		"Thermal":        "enrichment",
 		"Sensors":        "enrichment",
+		"Power":          "enrichment",
+		"ANE":            "enrichment",
 		"Bluetooth":      "enrichment",
 		"TopProcesses":   "live-or-enrichment",
 		"ProcessWatch":   "config",
+		"ProcessSort":    "config",
 		"ProcessAlerts":  "live-or-enrichment",
+		"LeakSuspects":   "live-or-enrichment",
+		"Stuck":          "enrichment",
+		"Collectors":     "enrichment",
+		"SpeedTests":     "config",
 	}
 
-	typ := reflect.TypeFor[MetricsSnapshot]()
+	typ := reflect.TypeFor[sysmetrics.MetricsSnapshot]()
 	for i := 0; i < typ.NumField(); i++ {
 		name := typ.Field(i).Name
 		if _, ok := classified[name]; !ok {
@@ -435,3 +304,106 @@ func TestMetricsSnapshotFieldsHaveCollectionClassifications(t *testing.T) {
 		t.Fatalf("field classification count = %d, want %d", len(classified), typ.NumField())
 	}
 }
+
+func TestModelCycleProcessSortResortsImmediately(t *testing.T) {
+	m := model{metrics: sysmetrics.MetricsSnapshot{
+		ProcessSort: sysmetrics.ProcessSortCPU,
+		TopProcesses: []sysmetrics.ProcessInfo{
+			{PID: 1, CPU: 90, MemoryBytes: 100},
+			{PID: 2, CPU: 10, MemoryBytes: 900},
+		},
+	}}
+	m.cycleProcessSort()
+	if m.metrics.ProcessSort != sysmetrics.ProcessSortMemory || m.metrics.TopProcesses[0].PID != 2 {
+		t.Fatalf("after cycle: sort=%q top=%+v", m.metrics.ProcessSort, m.metrics.TopProcesses)
+	}
+}
+
+func TestPauseHoldsRefreshAndStepCollectsOnce(t *testing.T) {
+	key := func(k string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)} }
+	m := model{ready: true, collector: &sysmetrics.Collector{}}
+
+	updated, _ := m.Update(key("p"))
+	m = updated.(model)
+	if !m.paused {
+		t.Fatal("p should pause refresh")
+	}
+	if _, cmd := m.Update(tickMsg{}); cmd != nil {
+		t.Fatal("paused model should not collect on tick")
+	}
+
+	updated, cmd := m.Update(key("."))
+	m = updated.(model)
+	if cmd == nil || !m.collecting {
+		t.Fatal(". should start one collection while paused")
+	}
+	updated, cmd = m.Update(metricsMsg{data: sysmetrics.MetricsSnapshot{CollectedAt: time.Now()}, mode: collectionFull})
+	m = updated.(model)
+	if cmd != nil {
+		t.Fatal("a stepped collection should not schedule the next tick")
+	}
+
+	updated, cmd = m.Update(key("p"))
+	if updated.(model).paused || cmd == nil {
+		t.Fatal("p should resume and tick right away")
+	}
+}
+
+func TestBoostCyclesPanelsAndLapses(t *testing.T) {
+	now := time.Now()
+	var boost *refreshBoost
+	for i := range sysmetrics.BoostPanels() {
+		boost = nextBoost(boost, now)
+		if boost == nil || boost.panel != i {
+			t.Fatalf("press %d: boost = %+v", i+1, boost)
+		}
+	}
+	if nextBoost(boost, now) != nil {
+		t.Fatal("cycling past the last panel should turn the boost off")
+	}
+
+	m := model{ready: true, lastFullAt: now, lastProcessAt: now, boost: &refreshBoost{until: now.Add(boostDuration)}}
+	if got := m.nextCollectionMode(now); got != collectionBoost {
+		t.Fatalf("boosted mode = %v, want boost", got)
+	}
+	lapsed := now.Add(boostDuration)
+	m.lastFullAt, m.lastProcessAt = lapsed, lapsed
+	if got := m.nextCollectionMode(lapsed); got != collectionFast {
+		t.Fatalf("lapsed boost mode = %v, want fast", got)
+	}
+	m.lastFullAt = now.Add(-slowRefreshInterval)
+	if got := m.nextCollectionMode(now); got != collectionFull {
+		t.Fatalf("a due full refresh should win over the boost, got %v", got)
+	}
+}
+
+func TestRenderRefreshLine(t *testing.T) {
+	now := time.Now()
+	if line := renderRefreshLine(false, nil, time.Second, now, 80); line != "" {
+		t.Fatalf("idle refresh line = %q", line)
+	}
+	if line := stripANSI(renderRefreshLine(true, nil, time.Second, now, 80)); !strings.Contains(line, "PAUSED") {
+		t.Fatalf("paused line = %q", line)
+	}
+	line := stripANSI(renderRefreshLine(false, &refreshBoost{panel: 0, until: now.Add(12 * time.Second)}, 2*time.Second, now, 80))
+	if !strings.Contains(line, "BOOST GPU every 2s · 12s left") {
+		t.Fatalf("boost line = %q", line)
+	}
+}
+
+func TestSpeedTestResultIsRecordedOnSnapshots(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	m := model{collector: sysmetrics.NewCollector(sysmetrics.ProcessWatchOptions{}), speedTesting: true}
+	next, _ := m.Update(speedTestMsg{result: sysmetrics.SpeedTestResult{DownloadMbps: 250, UploadMbps: 30}})
+	got := next.(model)
+	if got.speedTesting || got.speedTestNote != "" {
+		t.Fatalf("speed test state not cleared: testing=%v note=%q", got.speedTesting, got.speedTestNote)
+	}
+	if len(got.metrics.SpeedTests) != 1 || got.metrics.SpeedTests[0].DownloadMbps != 250 {
+		t.Fatalf("metrics.SpeedTests = %+v", got.metrics.SpeedTests)
+	}
+	if len(loadSpeedTestHistory()) != 1 {
+		t.Fatal("speed test result should persist for later runs")
+	}
+}
@@ -0,0 +1,76 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/tw93/mole/internal/notify"
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+// notifier delivers alerts on the --notify channels; nil without them.
+var notifier *notify.Notifier
+
+// alertNotifier sends one notification per process alert, when it becomes
+// active, from the TUI and from --watch.
+type alertNotifier struct {
+	sent map[string]bool
+}
+
+func alertKey(alert sysmetrics.ProcessAlert) string {
+	return strconv.Itoa(alert.PID) + "@" + alert.TriggeredAt.String()
+}
+
+// messages returns the notifications for alerts that were not active last
+// time and forgets alerts that ended, so a process that trips again is
+// reported again.
+func (a *alertNotifier) messages(alerts []sysmetrics.ProcessAlert) []notify.Message {
+	active := activeAlerts(alerts)
+	next := make(map[string]bool, len(active))
+	var msgs []notify.Message
+	for _, alert := range active {
+		key := alertKey(alert)
+		next[key] = true
+		if a.sent[key] {
+			continue
+		}
+		msgs = append(msgs, notify.Message{
+			Title: "Mole: high CPU",
+			Body: fmt.Sprintf("%s at %.1f%% for %s (threshold %.1f%%)",
+				sysmetrics.FormatProcessLabel(sysmetrics.ProcessInfo{PID: alert.PID, Name: alert.Name}),
+				alert.CPU, alert.Window, alert.Threshold),
+		})
+	}
+	a.sent = next
+	return msgs
+}
+
+// send delivers the notifications for alerts in the background, so a slow
+// webhook does not hold up the next snapshot. Failures are in the debug
+// log; an alert is never worth interrupting status for.
+func (a *alertNotifier) send(alerts []sysmetrics.ProcessAlert) {
+	if msgs := a.messages(alerts); len(msgs) > 0 && notifier != nil {
+		go deliver(msgs)
+	}
+}
+
+// cmd is send for the TUI, which runs it off the update loop.
+func (a *alertNotifier) cmd(alerts []sysmetrics.ProcessAlert) tea.Cmd {
+	msgs := a.messages(alerts)
+	if len(msgs) == 0 || notifier == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		deliver(msgs)
+		return nil
+	}
+}
+
+func deliver(msgs []notify.Message) {
+	for _, msg := range msgs {
+		notifier.Send(context.Background(), msg)
+	}
+}
@@ -0,0 +1,31 @@
+package status
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+func TestAlertNotifierSendsOncePerAlert(t *testing.T) {
+	since := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	node := sysmetrics.ProcessAlert{PID: 7, Name: "node", CPU: 180.5, Threshold: 100, Window: "5m0s", TriggeredAt: since, Status: "active"}
+	resolved := sysmetrics.ProcessAlert{PID: 9, Name: "mds", TriggeredAt: since, Status: "resolved"}
+
+	var a alertNotifier
+	msgs := a.messages([]sysmetrics.ProcessAlert{node, resolved})
+	if len(msgs) != 1 || !strings.Contains(msgs[0].Body, "node") || !strings.Contains(msgs[0].Body, "180.5%") {
+		t.Fatalf("first refresh: %+v", msgs)
+	}
+	if msgs := a.messages([]sysmetrics.ProcessAlert{node}); len(msgs) != 0 {
+		t.Fatalf("an alert still active was sent again: %+v", msgs)
+	}
+
+	// The alert ends, then the same process trips it again.
+	a.messages(nil)
+	node.TriggeredAt = since.Add(10 * time.Minute)
+	if msgs := a.messages([]sysmetrics.ProcessAlert{node}); len(msgs) != 1 {
+		t.Fatalf("a new alert for the same process was not sent: %+v", msgs)
+	}
+}
@@ -0,0 +1,261 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/shirou/gopsutil/v4/process"
+
+	"github.com/tw93/mole/internal/debuglog"
+	"github.com/tw93/mole/internal/i18n"
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+const (
+	processInspectTimeout = 2 * time.Second
+	processParentDepth    = 6
+	// reniceStep lowers priority only; raising it needs root and is rarely
+	// what someone chasing a runaway process wants.
+	reniceStep = 5
+)
+
+// runCmd runs the external commands behind process and service actions.
+// Tests swap it to avoid touching real processes.
+var runCmd = func(ctx context.Context, name string, args ...string) (string, error) {
+	start := time.Now()
+	output, err := exec.CommandContext(ctx, name, args...).Output()
+	debuglog.Command(name, args, time.Since(start), err)
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// Process actions; term and kill wait for an explicit "y" before running.
+const (
+	processActionTerm   = "term"
+	processActionKill   = "kill"
+	processActionRenice = "renice"
+)
+
+// processDetail is the read-only inspect view for one process.
+type processDetail struct {
+	PID       int
+	Name      string
+	User      string
+	Threads   int32
+	OpenFiles int32 // -1 when the OS denies access
+	Nice      int32
+	StartedAt time.Time
+	Parents   []string // "name (pid)", nearest parent first
+}
+
+// processInspect is the model state while a process is open for inspection.
+type processInspect struct {
+	target  sysmetrics.ProcessInfo
+	detail  *processDetail
+	pending string
+	message string
+}
+
+type processDetailMsg struct {
+	pid    int
+	detail processDetail
+	err    error
+}
+
+type processActionMsg struct {
+	message string
+	err     error
+}
+
+var (
+	inspectProcessFunc = inspectProcess
+	signalProcessFunc  = signalProcess
+)
+
+func inspectProcess(pid int) (processDetail, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), processInspectTimeout)
+	defer cancel()
+
+	proc, err := process.NewProcessWithContext(ctx, int32(pid))
+	if err != nil {
+		return processDetail{}, fmt.Errorf("pid %d: %w", pid, err)
+	}
+	detail := processDetail{PID: pid, OpenFiles: -1}
+	detail.Name, _ = proc.NameWithContext(ctx)
+	detail.User, _ = proc.UsernameWithContext(ctx)
+	detail.Threads, _ = proc.NumThreadsWithContext(ctx)
+	detail.Nice, _ = proc.NiceWithContext(ctx)
+	if fds, err := proc.NumFDsWithContext(ctx); err == nil {
+		detail.OpenFiles = fds
+	}
+	if ms, err := proc.CreateTimeWithContext(ctx); err == nil && ms > 0 {
+		detail.StartedAt = time.UnixMilli(ms)
+	}
+
+	ppid, _ := proc.PpidWithContext(ctx)
+	for len(detail.Parents) < processParentDepth && ppid > 0 {
+		parent, err := process.NewProcessWithContext(ctx, ppid)
+		if err != nil {
+			break
+		}
+		name, _ := parent.NameWithContext(ctx)
+		detail.Parents = append(detail.Parents, sysmetrics.FormatProcessLabel(sysmetrics.ProcessInfo{PID: int(ppid), Name: name}))
+		if ppid == 1 {
+			break
+		}
+		ppid, _ = parent.PpidWithContext(ctx)
+	}
+	return detail, nil
+}
+
+func signalProcess(pid int, sig syscall.Signal) error {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return proc.Signal(sig)
+}
+
+func inspectProcessCmd(pid int) tea.Cmd {
+	return func() tea.Msg {
+		detail, err := inspectProcessFunc(pid)
+		return processDetailMsg{pid: pid, detail: detail, err: err}
+	}
+}
+
+func processActionCmd(action string, target sysmetrics.ProcessInfo) tea.Cmd {
+	label := sysmetrics.FormatProcessLabel(target)
+	return func() tea.Msg {
+		switch action {
+		case processActionTerm, processActionKill:
+			sig, name := syscall.SIGTERM, "SIGTERM"
+			if action == processActionKill {
+				sig, name = syscall.SIGKILL, "SIGKILL"
+			}
+			if err := signalProcessFunc(target.PID, sig); err != nil {
+				return processActionMsg{err: fmt.Errorf("%s %s: %w", name, label, err)}
+			}
+			return processActionMsg{message: fmt.Sprintf("Sent %s to %s", name, label)}
+		case processActionRenice:
+			ctx, cancel := context.WithTimeout(context.Background(), processInspectTimeout)
+			defer cancel()
+			if _, err := runCmd(ctx, "renice", "-n", "+"+strconv.Itoa(reniceStep), "-p", strconv.Itoa(target.PID)); err != nil {
+				return processActionMsg{err: fmt.Errorf("renice %s: %w", label, err)}
+			}
+			return processActionMsg{message: fmt.Sprintf("Lowered priority of %s by %d", label, reniceStep)}
+		}
+		return processActionMsg{err: fmt.Errorf("unknown action %q", action)}
+	}
+}
+
+// handleInspectKey routes keys while the inspect panel is open. Destructive
+// signals are staged first and only sent after "y".
+func (m model) handleInspectKey(key string) (tea.Model, tea.Cmd) {
+	in := m.inspect
+	if in.pending != "" {
+		action := in.pending
+		in.pending = ""
+		if key != "y" {
+			in.message = "Cancelled"
+			return m, nil
+		}
+		in.message = "Sending..."
+		return m, processActionCmd(action, in.target)
+	}
+
+	switch key {
+	case "esc":
+		m.inspect = nil
+	case "t":
+		in.pending = processActionTerm
+	case "x":
+		in.pending = processActionKill
+	case "r":
+		in.message = "Renicing..."
+		return m, processActionCmd(processActionRenice, in.target)
+	}
+	return m, nil
+}
+
+// openInspect selects the process shown at rank (1-based) on the card.
+func (m model) openInspect(rank int) (tea.Model, tea.Cmd) {
+	procs := m.metrics.TopProcesses
+	if m.replay != nil || rank < 1 || rank > min(len(procs), processCardRows) {
+		return m, nil
+	}
+	target := procs[rank-1]
+	m.inspect = &processInspect{target: target}
+	return m, inspectProcessCmd(target.PID)
+}
+
+// openFlagged jumps to the process behind the CPU alert, or else to the
+// first stuck process. Blocked processes come before zombies, whose fix is
+// usually their parent.
+func (m model) openFlagged() (tea.Model, tea.Cmd) {
+	if m.replay != nil {
+		return m, nil
+	}
+	var target sysmetrics.ProcessInfo
+	if active := activeAlerts(m.metrics.ProcessAlerts); len(active) > 0 {
+		a := active[0]
+		target = sysmetrics.ProcessInfo{PID: a.PID, Name: a.Name, Command: a.Command, CPU: a.CPU}
+	} else if len(m.metrics.Stuck) > 0 {
+		first := m.metrics.Stuck[0]
+		if i := slices.IndexFunc(m.metrics.Stuck, func(p sysmetrics.StuckProcess) bool { return p.State != "zombie" }); i >= 0 {
+			first = m.metrics.Stuck[i]
+		}
+		target = sysmetrics.ProcessInfo{PID: first.PID, PPID: first.PPID, Name: first.Name}
+	} else {
+		return m, nil
+	}
+	m.inspect = &processInspect{target: target}
+	return m, inspectProcessCmd(target.PID)
+}
+
+func renderInspectCard(in *processInspect) cardData {
+	title := i18n.Tf("Inspect %s", sysmetrics.FormatProcessLabel(in.target))
+	var lines []string
+	if d := in.detail; d != nil {
+		if d.User != "" {
+			lines = append(lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "User", d.User))
+		}
+		files := "n/a"
+		if d.OpenFiles >= 0 {
+			files = strconv.Itoa(int(d.OpenFiles))
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %d threads · %s files · nice %d", metricLabelWidth, "Load", d.Threads, files, d.Nice))
+		if !d.StartedAt.IsZero() {
+			lines = append(lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "Start", d.StartedAt.Format("Jan 2 15:04:05")))
+		}
+		if len(d.Parents) > 0 {
+			chain := slices.Clone(d.Parents)
+			slices.Reverse(chain)
+			lines = append(lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "Tree", strings.Join(chain, " › ")))
+		}
+	} else if in.message == "" {
+		lines = append(lines, subtleStyle.Render(i18n.T("Inspecting...")))
+	}
+
+	switch in.pending {
+	case processActionTerm:
+		lines = append(lines, warnStyle.Render(i18n.T("Send SIGTERM? y to confirm, any other key cancels")))
+	case processActionKill:
+		lines = append(lines, dangerStyle.Render(i18n.T("Send SIGKILL? y to confirm, any other key cancels")))
+	default:
+		if in.message != "" {
+			lines = append(lines, in.message)
+		}
+		lines = append(lines, subtleStyle.Render(i18n.T("t term · x kill · r renice +5 · esc close")))
+	}
+	return cardData{icon: iconProcs, title: title, lines: lines}
+}
@@ -0,0 +1,115 @@
+package status
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+func TestInspectKillRequiresConfirmation(t *testing.T) {
+	oldSignal := signalProcessFunc
+	defer func() { signalProcessFunc = oldSignal }()
+
+	var sent []syscall.Signal
+	signalProcessFunc = func(pid int, sig syscall.Signal) error {
+		if pid != 4242 {
+			t.Fatalf("signal sent to pid %d, want 4242", pid)
+		}
+		sent = append(sent, sig)
+		return nil
+	}
+
+	m := model{inspect: &processInspect{target: sysmetrics.ProcessInfo{PID: 4242, Name: "runaway"}}}
+	updated, cmd := m.handleInspectKey("x")
+	m = updated.(model)
+	if cmd != nil || m.inspect.pending != processActionKill {
+		t.Fatalf("x should stage SIGKILL without sending, pending=%q", m.inspect.pending)
+	}
+	if !strings.Contains(stripANSI(strings.Join(renderInspectCard(m.inspect).lines, "\n")), "Send SIGKILL? y to confirm") {
+		t.Fatal("expected a confirmation prompt")
+	}
+
+	updated, cmd = m.handleInspectKey("n")
+	m = updated.(model)
+	if cmd != nil || m.inspect.pending != "" || len(sent) != 0 {
+		t.Fatalf("non-y key should cancel, pending=%q sent=%v", m.inspect.pending, sent)
+	}
+
+	updated, _ = m.handleInspectKey("t")
+	m = updated.(model)
+	_, cmd = m.handleInspectKey("y")
+	msg := cmd().(processActionMsg)
+	if msg.err != nil || len(sent) != 1 || sent[0] != syscall.SIGTERM {
+		t.Fatalf("confirmed term: msg=%+v sent=%v", msg, sent)
+	}
+	if msg.message != "Sent SIGTERM to runaway (4242)" {
+		t.Fatalf("message = %q", msg.message)
+	}
+
+	signalProcessFunc = func(int, syscall.Signal) error { return errors.New("operation not permitted") }
+	m.inspect.pending = processActionKill
+	_, cmd = m.handleInspectKey("y")
+	if msg := cmd().(processActionMsg); msg.err == nil || !strings.Contains(msg.err.Error(), "not permitted") {
+		t.Fatalf("expected signal error to surface, got %+v", msg)
+	}
+}
+
+func TestOpenInspectSelectsDisplayedRank(t *testing.T) {
+	oldInspect := inspectProcessFunc
+	defer func() { inspectProcessFunc = oldInspect }()
+	inspectProcessFunc = func(pid int) (processDetail, error) {
+		return processDetail{PID: pid, User: "dev", Threads: 12, OpenFiles: 40, Parents: []string{"zsh (90)", "launchd (1)"}}, nil
+	}
+
+	m := model{metrics: sysmetrics.MetricsSnapshot{TopProcesses: []sysmetrics.ProcessInfo{{PID: 10, Name: "a"}, {PID: 20, Name: "b"}}}}
+	if _, cmd := m.openInspect(3); cmd != nil {
+		t.Fatal("rank beyond the list should be ignored")
+	}
+	updated, cmd := m.openInspect(2)
+	m = updated.(model)
+	if m.inspect == nil || m.inspect.target.PID != 20 {
+		t.Fatalf("inspect = %+v, want pid 20", m.inspect)
+	}
+
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	plain := stripANSI(strings.Join(renderInspectCard(m.inspect).lines, "\n"))
+	if !strings.Contains(plain, "12 threads · 40 files") || !strings.Contains(plain, "launchd (1) › zsh (90)") {
+		t.Fatalf("inspect card = %q", plain)
+	}
+
+	updated, _ = m.handleInspectKey("esc")
+	if updated.(model).inspect != nil {
+		t.Fatal("esc should close the inspect panel instead of quitting")
+	}
+
+	replaying := model{replay: &sessionReplay{}, metrics: m.metrics}
+	if updated, _ := replaying.openInspect(1); updated.(model).inspect != nil {
+		t.Fatal("recorded processes should not be actionable during replay")
+	}
+}
+
+func TestOpenFlaggedPrefersAlertThenBlocked(t *testing.T) {
+	stuck := []sysmetrics.StuckProcess{
+		{PID: 40, PPID: 1, Name: "defunct", State: "zombie"},
+		{PID: 41, PPID: 1, Name: "mount_nfs", State: "uninterruptible"},
+	}
+	m := model{metrics: sysmetrics.MetricsSnapshot{Stuck: stuck}}
+	updated, cmd := m.openFlagged()
+	if got := updated.(model).inspect; got == nil || got.target.PID != 41 || cmd == nil {
+		t.Fatalf("inspect = %+v, want blocked pid 41", got)
+	}
+
+	m.metrics.ProcessAlerts = []sysmetrics.ProcessAlert{{PID: 7, Name: "node", Status: "active"}}
+	updated, _ = m.openFlagged()
+	if got := updated.(model).inspect; got == nil || got.target.PID != 7 {
+		t.Fatalf("inspect = %+v, want alerting pid 7", got)
+	}
+
+	if updated, cmd := (model{}).openFlagged(); updated.(model).inspect != nil || cmd != nil {
+		t.Fatal("nothing flagged should leave the panel closed")
+	}
+}
@@ -0,0 +1,34 @@
+package status
+
+import (
+	"time"
+
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+// boostDuration is how long `b` keeps a panel on the fast cadence.
+const boostDuration = 30 * time.Second
+
+// refreshBoost is the panel `b` selected, an index into
+// sysmetrics.BoostPanels, and when the boost lapses.
+type refreshBoost struct {
+	panel int
+	until time.Time
+}
+
+// nextBoost cycles through the boost panels and then back to no boost.
+func nextBoost(current *refreshBoost, now time.Time) *refreshBoost {
+	next := 0
+	if current != nil {
+		next = current.panel + 1
+	}
+	if next >= len(sysmetrics.BoostPanels()) {
+		return nil
+	}
+	return &refreshBoost{panel: next, until: now.Add(boostDuration)}
+}
+
+// name is the boosted panel's name, as CollectBoosted takes it.
+func (b *refreshBoost) name() string {
+	return sysmetrics.BoostPanels()[b.panel]
+}
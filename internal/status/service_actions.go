@@ -0,0 +1,136 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/tw93/mole/internal/i18n"
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+// brew services restart can take a while to unload and reload a plist.
+const serviceActionTimeout = 15 * time.Second
+
+// Service actions; stop waits for an explicit "y" before running.
+const (
+	serviceActionRestart = "restart"
+	serviceActionStop    = "stop"
+)
+
+// serviceControl is the model state while the services panel is open.
+type serviceControl struct {
+	cursor  int
+	pending string
+	target  sysmetrics.ServiceStatus
+	message string
+}
+
+type serviceActionMsg struct {
+	message string
+	err     error
+}
+
+// serviceActionArgs builds the command for an action. launchd jobs are
+// addressed in the user's gui domain: kickstart -k restarts a running job,
+// bootout unloads it until next login.
+func serviceActionArgs(action string, svc sysmetrics.ServiceStatus) (string, []string) {
+	if svc.Manager == sysmetrics.ServiceManagerBrew {
+		return "brew", []string{"services", action, svc.Name}
+	}
+	target := "gui/" + strconv.Itoa(os.Getuid()) + "/" + svc.Name
+	if action == serviceActionRestart {
+		return "launchctl", []string{"kickstart", "-k", target}
+	}
+	return "launchctl", []string{"bootout", target}
+}
+
+func serviceActionCmd(action string, svc sysmetrics.ServiceStatus) tea.Cmd {
+	return func() tea.Msg {
+		name, args := serviceActionArgs(action, svc)
+		ctx, cancel := context.WithTimeout(context.Background(), serviceActionTimeout)
+		defer cancel()
+		_, err := runCmd(ctx, name, args...)
+		switch {
+		case err != nil && action == serviceActionStop:
+			return serviceActionMsg{err: fmt.Errorf("%s: %w", i18n.Tf("Could not stop %s", svc.Name), err)}
+		case err != nil:
+			return serviceActionMsg{err: fmt.Errorf("%s: %w", i18n.Tf("Could not restart %s", svc.Name), err)}
+		case action == serviceActionStop:
+			return serviceActionMsg{message: i18n.Tf("Stopped %s; the card updates on the next full refresh", svc.Name)}
+		}
+		return serviceActionMsg{message: i18n.Tf("Restarted %s; the card updates on the next full refresh", svc.Name)}
+	}
+}
+
+// openServices shows the services panel when there is something to act on.
+func (m model) openServices() (tea.Model, tea.Cmd) {
+	if m.replay != nil || len(m.metrics.Services) == 0 {
+		return m, nil
+	}
+	m.services = &serviceControl{}
+	return m, nil
+}
+
+// handleServicesKey routes keys while the services panel is open. Stopping
+// is staged first and only runs after "y".
+func (m model) handleServicesKey(key string) (tea.Model, tea.Cmd) {
+	sc := m.services
+	list := m.metrics.Services
+	if sc.pending != "" {
+		action := sc.pending
+		sc.pending = ""
+		if key != "y" {
+			sc.message = i18n.T("Cancelled")
+			return m, nil
+		}
+		sc.message = i18n.T("Stopping...")
+		return m, serviceActionCmd(action, sc.target)
+	}
+	if len(list) == 0 {
+		m.services = nil
+		return m, nil
+	}
+	sc.cursor = min(sc.cursor, len(list)-1)
+
+	switch key {
+	case "esc":
+		m.services = nil
+	case "up":
+		sc.cursor = max(sc.cursor-1, 0)
+	case "down":
+		sc.cursor = min(sc.cursor+1, len(list)-1)
+	case "r":
+		sc.message = i18n.T("Restarting...")
+		return m, serviceActionCmd(serviceActionRestart, list[sc.cursor])
+	case "x":
+		sc.pending = serviceActionStop
+		sc.target = list[sc.cursor]
+	}
+	return m, nil
+}
+
+func renderServicesPanel(sc *serviceControl, services []sysmetrics.ServiceStatus) cardData {
+	var lines []string
+	for i, svc := range services {
+		marker := "  "
+		if i == min(sc.cursor, len(services)-1) {
+			marker = "› "
+		}
+		lines = append(lines, marker+formatServiceLine(svc))
+	}
+
+	if sc.pending == serviceActionStop {
+		lines = append(lines, warnStyle.Render(i18n.Tf("Stop %s? y to confirm, any other key cancels", sc.target.Name)))
+	} else {
+		if sc.message != "" {
+			lines = append(lines, sc.message)
+		}
+		lines = append(lines, subtleStyle.Render(i18n.T("↑/↓ select · r restart · x stop · esc close")))
+	}
+	return cardData{icon: iconService, title: "Manage Services", lines: lines}
+}
@@ -0,0 +1,78 @@
+package status
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tw93/mole/internal/i18n"
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+func TestServicesStopRequiresConfirmation(t *testing.T) {
+	origRunCmd := runCmd
+	defer func() { runCmd = origRunCmd }()
+
+	var ran []string
+	runCmd = func(ctx context.Context, name string, args ...string) (string, error) {
+		ran = append(ran, name+" "+strings.Join(args, " "))
+		return "", nil
+	}
+
+	m := model{metrics: sysmetrics.MetricsSnapshot{Services: []sysmetrics.ServiceStatus{
+		{Name: "com.example.sync", Manager: sysmetrics.ServiceManagerLaunchd, State: "error", ExitCode: 78},
+		{Name: "postgresql@16", Manager: sysmetrics.ServiceManagerBrew, State: "error", ExitCode: 1},
+	}}}
+	updated, _ := m.openServices()
+	m = updated.(model)
+	updated, _ = m.handleServicesKey("down")
+	m = updated.(model)
+	updated, cmd := m.handleServicesKey("x")
+	m = updated.(model)
+	if cmd != nil || m.services.pending != serviceActionStop {
+		t.Fatalf("x should stage a stop without running it, pending=%q", m.services.pending)
+	}
+	panel := stripANSI(strings.Join(renderServicesPanel(m.services, m.metrics.Services).lines, "\n"))
+	if !strings.Contains(panel, "Stop postgresql@16? y to confirm") {
+		t.Fatalf("expected a confirmation prompt, got\n%s", panel)
+	}
+
+	updated, cmd = m.handleServicesKey("n")
+	m = updated.(model)
+	if cmd != nil || m.services.pending != "" || len(ran) != 0 {
+		t.Fatalf("non-y key should cancel, pending=%q ran=%v", m.services.pending, ran)
+	}
+
+	updated, _ = m.handleServicesKey("x")
+	m = updated.(model)
+	_, cmd = m.handleServicesKey("y")
+	if msg := cmd().(serviceActionMsg); msg.err != nil {
+		t.Fatalf("stop: %v", msg.err)
+	}
+	if len(ran) != 1 || ran[0] != "brew services stop postgresql@16" {
+		t.Fatalf("ran = %v", ran)
+	}
+
+	updated, _ = m.handleServicesKey("up")
+	m = updated.(model)
+	_, cmd = m.handleServicesKey("r")
+	cmd()
+	if len(ran) != 2 || !strings.HasPrefix(ran[1], "launchctl kickstart -k gui/") || !strings.HasSuffix(ran[1], "/com.example.sync") {
+		t.Fatalf("ran = %v", ran)
+	}
+}
+
+func TestServicesPanelTranslates(t *testing.T) {
+	i18n.Set("zh")
+	defer i18n.Set(i18n.English)
+
+	services := []sysmetrics.ServiceStatus{{Name: "postgresql@16", Manager: sysmetrics.ServiceManagerBrew}}
+	sc := &serviceControl{pending: serviceActionStop, target: services[0]}
+	if panel := stripANSI(strings.Join(renderServicesPanel(sc, services).lines, "\n")); !strings.Contains(panel, "停止 postgresql@16？") {
+		t.Fatalf("prompt not translated:\n%s", panel)
+	}
+	sc.pending = ""
+	if panel := stripANSI(strings.Join(renderServicesPanel(sc, services).lines, "\n")); !strings.Contains(panel, "r 重启") {
+		t.Fatalf("key hints not translated:\n%s", panel)
+	}
+}
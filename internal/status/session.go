@@ -0,0 +1,145 @@
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+const (
+	defaultRecordDuration = 10 * time.Minute
+	// Replay pacing follows the recorded gaps, clamped so a paused recording
+	// does not stall the replay and a burst of fast samples stays readable.
+	minReplayDelay = 200 * time.Millisecond
+	maxReplayDelay = 5 * time.Second
+)
+
+// sessionRecorder appends snapshots to a file as newline-delimited JSON, the
+// same format --watch emits, so either source can be replayed later.
+type sessionRecorder struct {
+	file   *os.File
+	enc    *json.Encoder
+	until  time.Time
+	frames int
+	closed bool
+}
+
+func newSessionRecorder(path string, duration time.Duration, now time.Time) (*sessionRecorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open session file: %w", err)
+	}
+	r := &sessionRecorder{file: file, enc: json.NewEncoder(file)}
+	if duration > 0 {
+		r.until = now.Add(duration)
+	}
+	return r, nil
+}
+
+// Record writes one snapshot. It closes the file once the recording window
+// has elapsed and reports whether the recorder is still accepting frames.
+func (r *sessionRecorder) Record(snap sysmetrics.MetricsSnapshot) (bool, error) {
+	if r == nil || r.closed {
+		return false, nil
+	}
+	if !r.until.IsZero() && snap.CollectedAt.After(r.until) {
+		return false, r.Close()
+	}
+	if err := r.enc.Encode(snap); err != nil {
+		_ = r.Close()
+		return false, fmt.Errorf("record session: %w", err)
+	}
+	r.frames++
+	return true, nil
+}
+
+func (r *sessionRecorder) Active() bool {
+	return r != nil && !r.closed
+}
+
+func (r *sessionRecorder) Close() error {
+	if r == nil || r.closed {
+		return nil
+	}
+	r.closed = true
+	return r.file.Close()
+}
+
+// sessionReplay serves recorded snapshots back to the TUI in order.
+type sessionReplay struct {
+	path   string
+	frames []sysmetrics.MetricsSnapshot
+	pos    int
+}
+
+func loadSessionReplay(path string) (*sessionReplay, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open session file: %w", err)
+	}
+	defer file.Close()
+
+	frames, err := decodeSessionFrames(file)
+	if err != nil {
+		return nil, fmt.Errorf("read session %s: %w", path, err)
+	}
+	return &sessionReplay{path: path, frames: frames}, nil
+}
+
+func decodeSessionFrames(r io.Reader) ([]sysmetrics.MetricsSnapshot, error) {
+	dec := json.NewDecoder(r)
+	var frames []sysmetrics.MetricsSnapshot
+	for {
+		var snap sysmetrics.MetricsSnapshot
+		err := dec.Decode(&snap)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// A recording cut short mid-write still has usable frames.
+			if len(frames) > 0 && errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return nil, fmt.Errorf("frame %d: %w", len(frames)+1, err)
+		}
+		frames = append(frames, snap)
+	}
+	if len(frames) == 0 {
+		return nil, errors.New("no snapshots recorded")
+	}
+	return frames, nil
+}
+
+// Next returns the next frame and the delay to wait before the one after it.
+func (r *sessionReplay) Next() (sysmetrics.MetricsSnapshot, time.Duration, bool) {
+	if r == nil || r.pos >= len(r.frames) {
+		return sysmetrics.MetricsSnapshot{}, 0, false
+	}
+	frame := r.frames[r.pos]
+	r.pos++
+
+	delay := refreshInterval
+	if r.pos < len(r.frames) {
+		next := r.frames[r.pos].CollectedAt
+		if !frame.CollectedAt.IsZero() && !next.IsZero() {
+			delay = min(max(next.Sub(frame.CollectedAt), minReplayDelay), maxReplayDelay)
+		}
+	}
+	return frame, delay, true
+}
+
+func (r *sessionReplay) Done() bool {
+	return r == nil || r.pos >= len(r.frames)
+}
+
+func (r *sessionReplay) Progress() (int, int) {
+	if r == nil {
+		return 0, 0
+	}
+	return r.pos, len(r.frames)
+}
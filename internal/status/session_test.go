@@ -0,0 +1,169 @@
+package status
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+func TestSessionRecordAndReplayRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.ndjson")
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	rec, err := newSessionRecorder(path, time.Minute, start)
+	if err != nil {
+		t.Fatalf("newSessionRecorder() error = %v", err)
+	}
+	for i := range 3 {
+		snap := sysmetrics.MetricsSnapshot{
+			CollectedAt: start.Add(time.Duration(i) * 2 * time.Second),
+			Host:        "support-mac",
+			CPU:         sysmetrics.CPUStatus{Usage: float64(10 * (i + 1))},
+		}
+		if ok, err := rec.Record(snap); err != nil || !ok {
+			t.Fatalf("Record(%d) = %v, %v; want true, nil", i, ok, err)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	replay, err := loadSessionReplay(path)
+	if err != nil {
+		t.Fatalf("loadSessionReplay() error = %v", err)
+	}
+	frame, delay, ok := replay.Next()
+	if !ok || frame.Host != "support-mac" || frame.CPU.Usage != 10 {
+		t.Fatalf("first frame = %+v, ok=%v", frame, ok)
+	}
+	if delay != 2*time.Second {
+		t.Fatalf("delay = %v, want recorded 2s gap", delay)
+	}
+	replay.Next()
+	if _, _, ok := replay.Next(); !ok || !replay.Done() {
+		t.Fatalf("expected third frame to finish the replay")
+	}
+	if _, _, ok := replay.Next(); ok {
+		t.Fatal("Next() after the last frame should report false")
+	}
+}
+
+func TestSessionRecorderStopsAfterDuration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.ndjson")
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	rec, err := newSessionRecorder(path, 5*time.Second, start)
+	if err != nil {
+		t.Fatalf("newSessionRecorder() error = %v", err)
+	}
+	if ok, _ := rec.Record(sysmetrics.MetricsSnapshot{CollectedAt: start.Add(time.Second)}); !ok {
+		t.Fatal("expected frame inside the window to be recorded")
+	}
+	if ok, _ := rec.Record(sysmetrics.MetricsSnapshot{CollectedAt: start.Add(10 * time.Second)}); ok {
+		t.Fatal("expected frame after the window to stop recording")
+	}
+	if rec.Active() {
+		t.Fatal("recorder should be closed after the window elapsed")
+	}
+	if rec.frames != 1 {
+		t.Fatalf("frames = %d, want 1", rec.frames)
+	}
+}
+
+func TestDecodeSessionFramesToleratesTruncatedTail(t *testing.T) {
+	raw := `{"host":"a","collected_at":"2026-10-16T09:00:00Z"}` + "\n" + `{"host":"b","coll`
+	frames, err := decodeSessionFrames(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("decodeSessionFrames() error = %v", err)
+	}
+	if len(frames) != 1 || frames[0].Host != "a" {
+		t.Fatalf("frames = %+v, want the complete first frame", frames)
+	}
+
+	if _, err := decodeSessionFrames(strings.NewReader("")); err == nil {
+		t.Fatal("expected an empty session to be rejected")
+	}
+	if _, err := decodeSessionFrames(strings.NewReader("not json")); err == nil {
+		t.Fatal("expected garbage input to be rejected")
+	}
+}
+
+func TestSessionReplayClampsDelays(t *testing.T) {
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	replay := &sessionReplay{frames: []sysmetrics.MetricsSnapshot{
+		{CollectedAt: start},
+		{CollectedAt: start.Add(time.Hour)},
+		{CollectedAt: start.Add(time.Hour + time.Millisecond)},
+	}}
+
+	if _, delay, _ := replay.Next(); delay != maxReplayDelay {
+		t.Fatalf("long gap delay = %v, want %v", delay, maxReplayDelay)
+	}
+	if _, delay, _ := replay.Next(); delay != minReplayDelay {
+		t.Fatalf("short gap delay = %v, want %v", delay, minReplayDelay)
+	}
+}
+
+func TestModelReplayFeedsFramesWithoutCollector(t *testing.T) {
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	m := model{replay: &sessionReplay{frames: []sysmetrics.MetricsSnapshot{
+		{CollectedAt: start, Host: "first"},
+		{CollectedAt: start.Add(time.Second), Host: "second"},
+	}}}
+
+	updated, cmd := m.Update(tickMsg{})
+	m = updated.(model)
+	if cmd == nil {
+		t.Fatal("expected tick to schedule the first replay frame")
+	}
+	updated, next := m.Update(cmd())
+	m = updated.(model)
+	if m.metrics.Host != "first" || !m.ready || next == nil {
+		t.Fatalf("after first frame: host=%q ready=%v next=%v", m.metrics.Host, m.ready, next != nil)
+	}
+
+	updated, cmd = m.Update(tickMsg{})
+	m = updated.(model)
+	updated, next = m.Update(cmd())
+	m = updated.(model)
+	if m.metrics.Host != "second" {
+		t.Fatalf("host = %q, want second", m.metrics.Host)
+	}
+	if next != nil {
+		t.Fatal("expected replay to stop ticking after the last frame")
+	}
+	if line := renderSessionLine(nil, m.replay, 120); !strings.Contains(line, "REPLAY 2/2") || !strings.Contains(line, "finished") {
+		t.Fatalf("session line = %q", line)
+	}
+}
+
+func TestValidateFlagsRejectsSessionConflicts(t *testing.T) {
+	oldRecord, oldReplay, oldWatch := *recordSession, *replaySession, *watchMode
+	defer func() {
+		*recordSession, *replaySession, *watchMode = oldRecord, oldReplay, oldWatch
+	}()
+
+	*recordSession, *replaySession = "a.ndjson", "b.ndjson"
+	if err := validateFlags(); err == nil {
+		t.Fatal("expected --record-session with --replay to fail")
+	}
+
+	*recordSession, *replaySession, *watchMode = "a.ndjson", "", true
+	if err := validateFlags(); err == nil {
+		t.Fatal("expected --record-session with --watch to fail")
+	}
+}
+
+func TestNewSessionRecorderReportsOpenError(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing", "session.ndjson")
+	if _, err := newSessionRecorder(dir, 0, time.Now()); err == nil {
+		t.Fatal("expected an error for an unwritable path")
+	}
+	if _, err := os.Stat(dir); err == nil {
+		t.Fatal("recorder should not create missing parent directories")
+	}
+}
@@ -0,0 +1,71 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+const speedTestTimeout = 30 * time.Second
+
+var runSpeedTestFunc = sysmetrics.RunSpeedTest
+
+type speedTestMsg struct {
+	result sysmetrics.SpeedTestResult
+	err    error
+}
+
+func speedTestCmd() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), speedTestTimeout)
+		defer cancel()
+		result, err := runSpeedTestFunc(ctx)
+		return speedTestMsg{result: result, err: err}
+	}
+}
+
+// speedTestHistoryPath sits next to status_prefs so results survive
+// restarts and later runs can be compared against earlier ones.
+func speedTestHistoryPath() string {
+	path := getConfigPath()
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), "speedtest_history.json")
+}
+
+func loadSpeedTestHistory() []sysmetrics.SpeedTestResult {
+	path := speedTestHistoryPath()
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var history []sysmetrics.SpeedTestResult
+	if json.Unmarshal(data, &history) != nil {
+		return nil
+	}
+	return history
+}
+
+func saveSpeedTestHistory(history []sysmetrics.SpeedTestResult) {
+	path := speedTestHistoryPath()
+	if path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	data, err := json.Marshal(history)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0644)
+}
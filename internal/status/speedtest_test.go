@@ -0,0 +1,23 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+func TestSpeedTestHistoryIsPersisted(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var history []sysmetrics.SpeedTestResult
+	for i := range 3 {
+		history = append(history, sysmetrics.SpeedTestResult{At: time.Unix(int64(i), 0), DownloadMbps: float64(i)})
+	}
+
+	saveSpeedTestHistory(history)
+	loaded := loadSpeedTestHistory()
+	if len(loaded) != len(history) || loaded[len(loaded)-1].DownloadMbps != 2 {
+		t.Fatalf("loaded = %+v", loaded)
+	}
+}
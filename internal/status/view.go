@@ -0,0 +1,2235 @@
+package status
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+
+	"github.com/tw93/mole/internal/hyperlink"
+	"github.com/tw93/mole/internal/i18n"
+	"github.com/tw93/mole/internal/theme"
+	"github.com/tw93/mole/internal/units"
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+var (
+	titleStyle, subtleStyle, warnStyle, dangerStyle, okStyle, lineStyle lipgloss.Style
+	primaryStyle, alertBarStyle                                         lipgloss.Style
+
+	// palette is the active theme. main applies --theme before the first
+	// frame; until then the styles are the dark theme.
+	palette = applyTheme(theme.Dark)
+)
+
+// applyTheme rebuilds every style from p and returns it.
+func applyTheme(p theme.Palette) theme.Palette {
+	titleStyle = lipgloss.NewStyle().Foreground(themeColor(p.Title)).Bold(true)
+	subtleStyle = lipgloss.NewStyle().Foreground(themeColor(p.Subtle))
+	warnStyle = lipgloss.NewStyle().Foreground(themeColor(p.Warn))
+	dangerStyle = lipgloss.NewStyle().Foreground(themeColor(p.Danger)).Bold(true)
+	okStyle = lipgloss.NewStyle().Foreground(themeColor(p.OK))
+	lineStyle = lipgloss.NewStyle().Foreground(themeColor(p.Line))
+	primaryStyle = lipgloss.NewStyle().Foreground(themeColor(p.Primary))
+	alertBarStyle = lipgloss.NewStyle().
+		Foreground(themeColor(p.AlertText)).
+		Background(themeColor(p.AlertBack)).
+		Bold(true).
+		Padding(0, 1)
+	return p
+}
+
+// themeColor hands lipgloss all three depths so it never has to guess the
+// 16-color fallback.
+func themeColor(c theme.Color) lipgloss.TerminalColor {
+	return lipgloss.CompleteColor{
+		TrueColor: c.Hex,
+		ANSI256:   strconv.Itoa(int(c.ANSI256())),
+		ANSI:      strconv.Itoa(int(c.ANSI)),
+	}
+}
+
+// colorProfiles maps the shared color depth detection onto lipgloss.
+var colorProfiles = map[theme.Profile]termenv.Profile{
+	theme.NoColor:   termenv.Ascii,
+	theme.ANSI:      termenv.ANSI,
+	theme.ANSI256:   termenv.ANSI256,
+	theme.TrueColor: termenv.TrueColor,
+}
+
+const (
+	colWidth    = 38
+	iconCPU     = "◉"
+	iconMemory  = "◫"
+	iconGPU     = "◧"
+	iconDisk    = "▥"
+	iconNetwork = "⇅"
+	iconBattery = "◪"
+	iconSensors = "◈"
+	iconProcs   = "❊"
+	iconPorts   = "⊙"
+	iconVPN     = "◬"
+	iconLatency = "◷"
+	iconDNS     = "◎"
+	iconBox     = "▣"
+	iconKube    = "☸"
+	iconVM      = "◰"
+	iconPlug    = "⌁"
+	iconDisplay = "▭"
+	iconLaunch  = "⏻"
+	iconService = "⚙"
+	iconBackup  = "◴"
+	iconCrash   = "✕"
+	iconSystem  = "◉"
+	iconSleep   = "☾"
+	iconLimits  = "⊘"
+	iconLayout  = "▦"
+
+	metricLabelWidth    = 6
+	processMemoryWidth  = 7
+	processWideMinWidth = 46
+	coreGridWidth       = 16
+	sensorGraphWidth    = 16
+	processCardRows     = 3
+	diskTrendWidth      = 10
+	storageCardRows     = 6
+	latencyLabelWidth   = 9
+	latencyGraphWidth   = 10
+	dnsLabelWidth       = 15
+	containerNameWidth  = 16
+)
+
+// Glyphs for bars, graphs and card titles. useASCIIGlyphs swaps them for
+// plain ASCII when color is off, so logs and screen readers get text.
+var (
+	sparkBlocks         = []rune{'▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+	barFull, barEmpty   = "█", "░"
+	cellFull, cellEmpty = "▮", "▯"
+	titleRule           = "╌"
+	cardIcons           = true
+)
+
+func useASCIIGlyphs() {
+	sparkBlocks = []rune{'_', '.', ':', '-', '=', '+', '*', '#'}
+	barFull, barEmpty = "#", "-"
+	cellFull, cellEmpty = "#", "-"
+	titleRule = "-"
+	cardIcons = false
+}
+
+// Mole body frames (facing right).
+var moleBody = [][]string{
+	{
+		`     /\_/\`,
+		` ___/ o o \`,
+		`/___   =-= /`,
+		`\____)-m-m)`,
+	},
+	{
+		`     /\_/\`,
+		` ___/ o o \`,
+		`/___   =-= /`,
+		`\____)mm__)`,
+	},
+	{
+		`     /\_/\`,
+		` ___/ · · \`,
+		`/___   =-= /`,
+		`\___)-m__m)`,
+	},
+	{
+		`     /\_/\`,
+		` ___/ o o \`,
+		`/___   =-= /`,
+		`\____)-mm-)`,
+	},
+}
+
+// Mirror mole body frames (facing left).
+var moleBodyMirror = [][]string{
+	{
+		`    /\_/\`,
+		`   / o o \___`,
+		`  \ =-=   ___\`,
+		`  (m-m-(____/`,
+	},
+	{
+		`    /\_/\`,
+		`   / o o \___`,
+		`  \ =-=   ___\`,
+		`  (__mm(____/`,
+	},
+	{
+		`    /\_/\`,
+		`   / · · \___`,
+		`  \ =-=   ___\`,
+		`  (m__m-(___/`,
+	},
+	{
+		`    /\_/\`,
+		`   / o o \___`,
+		`  \ =-=   ___\`,
+		`  (-mm-(____/`,
+	},
+}
+
+// getMoleFrame renders the animated mole.
+func getMoleFrame(animFrame int, termWidth int) string {
+	moleWidth := 15
+	maxPos := max(termWidth-moleWidth, 0)
+
+	cycleLength := maxPos * 2
+	if cycleLength == 0 {
+		cycleLength = 1
+	}
+	pos := animFrame % cycleLength
+	movingLeft := pos > maxPos
+	if movingLeft {
+		pos = cycleLength - pos
+	}
+
+	// Use mirror frames when moving left
+	var frames [][]string
+	if movingLeft {
+		frames = moleBodyMirror
+	} else {
+		frames = moleBody
+	}
+
+	bodyIdx := animFrame % len(frames)
+	body := frames[bodyIdx]
+
+	padding := strings.Repeat(" ", pos)
+	var lines []string
+
+	for _, line := range body {
+		lines = append(lines, padding+line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type cardData struct {
+	icon  string
+	title string
+	lines []string
+}
+
+func renderHeader(m sysmetrics.MetricsSnapshot, errMsg string, animFrame int, termWidth int, catHidden bool) (string, string) {
+	if termWidth <= 0 {
+		termWidth = 80
+	}
+	compactHeader := termWidth <= 80
+
+	title := titleStyle.Render(i18n.T("Status"))
+
+	scoreStyle := getScoreStyle(m.HealthScore)
+	scoreText := subtleStyle.Render(i18n.T("Health")+" ") + scoreStyle.Render(fmt.Sprintf("● %d", m.HealthScore))
+	if errMsg == "" {
+		diagnosis := statusDiagnosisLine(m)
+		scoreText += " " + subtleStyle.Render(diagnosis)
+	}
+
+	// Hardware info for a single line.
+	identityParts := []string{}
+	if m.Hardware.Model != "" {
+		identityParts = append(identityParts, primaryStyle.Render(m.Hardware.Model))
+	}
+	if m.Hardware.CPUModel != "" {
+		cpuInfo := m.Hardware.CPUModel
+		// Append GPU core count when available.
+		if len(m.GPU) > 0 && m.GPU[0].CoreCount > 0 {
+			cpuInfo += fmt.Sprintf(", %dGPU", m.GPU[0].CoreCount)
+		}
+		identityParts = append(identityParts, cpuInfo)
+	}
+	specParts := []string{}
+	if m.Hardware.TotalRAM != "" {
+		specParts = append(specParts, i18n.T("RAM")+" "+m.Hardware.TotalRAM)
+	} else if m.Memory.Total > 0 {
+		specParts = append(specParts, i18n.T("RAM")+" "+humanBytes(m.Memory.Total))
+	}
+	if m.Hardware.DiskSize != "" {
+		specParts = append(specParts, i18n.T("Disk")+" "+m.Hardware.DiskSize)
+	} else if disk, ok := rootDisk(m.Disks); ok && disk.Total > 0 {
+		specParts = append(specParts, i18n.T("Disk")+" "+humanBytes(disk.Total))
+	}
+	refreshParts := []string{}
+	if m.Hardware.RefreshRate != "" {
+		refreshParts = append(refreshParts, m.Hardware.RefreshRate)
+	}
+	optionalInfoParts := []string{}
+	if !compactHeader && m.Hardware.OSVersion != "" {
+		optionalInfoParts = append(optionalInfoParts, m.Hardware.OSVersion)
+	}
+	if !compactHeader && m.Uptime != "" {
+		uptimeText := i18n.Tf("up %s", m.Uptime)
+		switch sysmetrics.UptimeSeverity(m.UptimeSeconds) {
+		case "danger":
+			uptimeText = dangerStyle.Render(uptimeText + " ↻")
+		case "warn":
+			uptimeText = warnStyle.Render(uptimeText)
+		default:
+			uptimeText = subtleStyle.Render(uptimeText)
+		}
+		optionalInfoParts = append(optionalInfoParts, uptimeText)
+	}
+	joinInfoParts := func(groups ...[]string) []string {
+		parts := []string{}
+		for _, group := range groups {
+			parts = append(parts, group...)
+		}
+		return parts
+	}
+
+	headLeft := title + "  " + scoreText
+	headerLine := headLeft
+	if termWidth > 0 && lipgloss.Width(headerLine) > termWidth {
+		headerLine = wrapToWidth(headLeft, termWidth)[0]
+	}
+	if termWidth > 0 {
+		fitHeaderParts := func(parts []string) (string, bool) {
+			if len(parts) == 0 {
+				return "", false
+			}
+			combined := headLeft + "  " + strings.Join(parts, " · ")
+			if lipgloss.Width(combined) <= termWidth {
+				return combined, true
+			}
+			return "", false
+		}
+		candidates := [][]string{
+			joinInfoParts(identityParts, specParts, refreshParts, optionalInfoParts),
+			joinInfoParts(identityParts, specParts, refreshParts),
+			joinInfoParts(identityParts, specParts),
+		}
+		if len(identityParts) > 1 {
+			// Keep labeled RAM/Disk visible on narrow terminals before CPU details.
+			candidates = append(candidates, joinInfoParts(identityParts[:1], specParts))
+		}
+		candidates = append(candidates, specParts)
+		for _, parts := range candidates {
+			if line, ok := fitHeaderParts(parts); ok {
+				headerLine = line
+				break
+			}
+		}
+		if headerLine == headLeft {
+			// Last resort: preserve the existing tail-drop behavior for unusual
+			// hardware strings that still do not fit the priority candidates.
+			fitParts := joinInfoParts(identityParts, specParts, refreshParts)
+			for len(fitParts) > 0 {
+				if line, ok := fitHeaderParts(fitParts); ok {
+					headerLine = line
+					break
+				}
+				fitParts = fitParts[:len(fitParts)-1]
+			}
+		}
+	}
+
+	// Show cat unless hidden - render mole centered below header
+	var mole string
+	if !catHidden {
+		mole = getMoleFrame(animFrame, termWidth)
+	}
+
+	if errMsg != "" {
+		if mole == "" {
+			return lipgloss.JoinVertical(lipgloss.Left, headerLine, "", dangerStyle.Render("ERROR: "+errMsg)), ""
+		}
+		return lipgloss.JoinVertical(lipgloss.Left, headerLine, "", mole, dangerStyle.Render("ERROR: "+errMsg)), ""
+	}
+	if mole == "" {
+		return headerLine, ""
+	}
+	return headerLine, mole
+}
+
+func getScoreStyle(score int) lipgloss.Style {
+	switch {
+	case score >= sysmetrics.ScoreExcellentThreshold:
+		return lipgloss.NewStyle().Foreground(themeColor(palette.Excellent)).Bold(true)
+	case score >= sysmetrics.ScoreGoodThreshold:
+		return lipgloss.NewStyle().Foreground(themeColor(palette.Good)).Bold(true)
+	case score >= sysmetrics.ScoreFairThreshold:
+		return lipgloss.NewStyle().Foreground(themeColor(palette.Warn)).Bold(true)
+	default:
+		return lipgloss.NewStyle().Foreground(themeColor(palette.Danger)).Bold(true)
+	}
+}
+
+func renderProcessAlertBar(alerts []sysmetrics.ProcessAlert, width int) string {
+	active := activeAlerts(alerts)
+	if len(active) == 0 {
+		return ""
+	}
+
+	focus := active[0]
+
+	text := fmt.Sprintf(
+		i18n.T("ALERT %s at %.1f%% for %s (threshold %.1f%%)"),
+		sysmetrics.FormatProcessLabel(sysmetrics.ProcessInfo{PID: focus.PID, Name: focus.Name}),
+		focus.CPU,
+		focus.Window,
+		focus.Threshold,
+	)
+	if len(active) > 1 {
+		text += " · " + i18n.Tf("+%d more", len(active)-1)
+	}
+
+	return renderBanner(alertBarStyle, text, width)
+}
+
+func renderBanner(style lipgloss.Style, text string, width int) string {
+	if width > 0 {
+		style = style.MaxWidth(width)
+	}
+	return style.Render(text)
+}
+
+// renderSessionLine shows a one-line recording or replay indicator under the
+// header so a replayed frame is never mistaken for live data.
+func renderSessionLine(recorder *sessionRecorder, replay *sessionReplay, width int) string {
+	var text string
+	switch {
+	case replay != nil:
+		pos, total := replay.Progress()
+		text = i18n.Tf("REPLAY %d/%d", pos, total)
+		if pos > 0 {
+			if at := replay.frames[pos-1].CollectedAt; !at.IsZero() {
+				text += " · " + i18n.Tf("recorded %s", at.Format("2006-01-02 15:04:05"))
+			}
+		}
+		if replay.Done() {
+			text += " · " + i18n.T("finished, q to quit")
+		}
+	case recorder.Active():
+		text = i18n.Tf("REC %s · %d frames", recorder.file.Name(), recorder.frames)
+	default:
+		return ""
+	}
+	return renderBanner(warnStyle, text, width)
+}
+
+// renderRefreshLine shows a paused refresh or a boosted panel, since either
+// leaves cards updating at a different pace than usual.
+func renderRefreshLine(paused bool, boost *refreshBoost, interval time.Duration, now time.Time, width int) string {
+	var text string
+	switch {
+	case paused:
+		text = i18n.T("PAUSED · . step · p resume")
+	case boost != nil && now.Before(boost.until):
+		left := boost.until.Sub(now).Round(time.Second)
+		text = i18n.Tf("BOOST %s every %s · %s left · b next", boost.name(), interval, left)
+	default:
+		return ""
+	}
+	return renderBanner(warnStyle, text, width)
+}
+
+func renderCPUCard(cpu sysmetrics.CPUStatus, thermal sysmetrics.ThermalStatus, power sysmetrics.PowerStatus, ane sysmetrics.ANEStatus) cardData {
+	var lines []string
+
+	// Line 1: Usage + Temp (Format: 15% @ 30.4°C)
+	usageBar := progressBar(cpu.Usage)
+
+	headerText := fmt.Sprintf("%5.1f%%", cpu.Usage)
+	if thermal.CPUTemp > 0 {
+		headerText += fmt.Sprintf(" @ %s°C", colorizeTemp(thermal.CPUTemp))
+	}
+
+	lines = append(lines, fmt.Sprintf("Total  %s  %s", usageBar, headerText))
+
+	if cpu.PerCoreEstimated {
+		lines = append(lines, subtleStyle.Render(i18n.T("Per-core data unavailable, using averaged load")))
+	} else if len(cpu.PerCore) > 0 {
+		lines = append(lines, renderCoreGrid(cpu.PerCore)...)
+
+		hottest := 0
+		for i, v := range cpu.PerCore {
+			if v > cpu.PerCore[hottest] {
+				hottest = i
+			}
+		}
+		val := cpu.PerCore[hottest]
+		lines = append(lines, fmt.Sprintf("Core%-2d %s  %5.1f%%", hottest+1, progressBar(val), val))
+	}
+
+	if line := formatClusterLine(cpu.Clusters); line != "" {
+		lines = append(lines, line)
+	}
+	if line := formatPowerLine(power); line != "" {
+		lines = append(lines, line)
+	}
+	// Idle engines draw nothing; only show the line while something runs on it.
+	if ane.Watts >= 0.05 {
+		lines = append(lines, fmt.Sprintf("%-*s %s  %5.1f%% %s", metricLabelWidth, "ANE", progressBar(ane.Usage), ane.Usage,
+			subtleStyle.Render(fmt.Sprintf("%.1fW", ane.Watts))))
+	}
+
+	// Load line at the end
+	if cpu.PCoreCount > 0 && cpu.ECoreCount > 0 {
+		lines = append(lines, fmt.Sprintf("Load   %.2f / %.2f / %.2f, %dP+%dE",
+			cpu.Load1, cpu.Load5, cpu.Load15, cpu.PCoreCount, cpu.ECoreCount))
+	} else {
+		lines = append(lines, fmt.Sprintf("Load   %.2f / %.2f / %.2f, %d cores",
+			cpu.Load1, cpu.Load5, cpu.Load15, cpu.LogicalCPU))
+	}
+
+	return cardData{icon: iconCPU, title: "CPU", lines: lines}
+}
+
+// formatClusterLine shows P and E cluster usage side by side, since a
+// saturated P cluster hides behind a modest aggregate on Apple Silicon.
+func formatClusterLine(clusters []sysmetrics.CPUCluster) string {
+	if len(clusters) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		part := cluster.Name + " " + colorizePercent(cluster.Usage, fmt.Sprintf("%.0f%%", cluster.Usage))
+		if cluster.FrequencyMHz > 0 {
+			part += fmt.Sprintf(" %.1fGHz", cluster.FrequencyMHz/1000)
+		}
+		parts = append(parts, part)
+	}
+	return fmt.Sprintf("%-*s %s", metricLabelWidth, "P/E", strings.Join(parts, " · "))
+}
+
+// formatPowerLine shows package draw next to its recent average, then the
+// components that make it up, e.g. "Power  12.3W avg 9.8W  CPU 8.1 GPU 3.0".
+func formatPowerLine(power sysmetrics.PowerStatus) string {
+	if power.PackageWatts <= 0 {
+		return ""
+	}
+	line := fmt.Sprintf("%-*s %.1fW", metricLabelWidth, "Power", power.PackageWatts)
+	if power.AvgPackageWatts > 0 {
+		line += subtleStyle.Render(fmt.Sprintf(" avg %.1fW", power.AvgPackageWatts))
+	}
+	var parts []string
+	for _, c := range []struct {
+		name  string
+		watts float64
+	}{{"CPU", power.CPUWatts}, {"GPU", power.GPUWatts}, {"ANE", power.ANEWatts}} {
+		if c.watts >= 0.05 {
+			parts = append(parts, fmt.Sprintf("%s %.1f", c.name, c.watts))
+		}
+	}
+	if len(parts) > 0 {
+		line += "  " + strings.Join(parts, " ")
+	}
+	return line
+}
+
+// renderCoreGrid draws one block glyph per logical core, in core order, so
+// a single saturated core stands out even when the total looks idle. Rows
+// match the progress bar width and wrap for high core counts.
+func renderCoreGrid(perCore []float64) []string {
+	var rows []string
+	for start := 0; start < len(perCore); start += coreGridWidth {
+		end := min(start+coreGridWidth, len(perCore))
+		var builder strings.Builder
+		for _, v := range perCore[start:end] {
+			builder.WriteString(coreGlyph(v))
+		}
+		label := ""
+		if start == 0 {
+			label = "Cores"
+		}
+		rows = append(rows, fmt.Sprintf("%-*s %s", metricLabelWidth, label, builder.String()))
+	}
+	return rows
+}
+
+func coreGlyph(percent float64) string {
+	level := int(min(max(percent, 0), 100) / 100 * float64(len(sparkBlocks)-1))
+	return colorizePercent(percent, string(sparkBlocks[level]))
+}
+
+// withGPUMemory shows how much unified memory Apple Silicon GPUs hold
+// wired for Metal, which counts against the same RAM as everything else.
+func withGPUMemory(card cardData, gpus []sysmetrics.GPUStatus, total uint64, now time.Time) cardData {
+	var wired float64
+	for _, gpu := range gpus {
+		if gpu.SharedMemory {
+			wired += gpu.MemoryUsed
+		}
+	}
+	if wired <= 0 {
+		return card
+	}
+	bytes := uint64(wired * (1 << 20))
+	line := fmt.Sprintf("%-*s %s wired", metricLabelWidth, "GPU", humanBytes(bytes))
+	if total > 0 {
+		line += subtleStyle.Render(fmt.Sprintf(" · %.0f%% of RAM", float64(bytes)/float64(total)*100))
+	}
+	card.lines = append(card.lines, line+gpuAgeSuffix(gpus, now))
+	return card
+}
+
+func renderMemoryCard(mem sysmetrics.MemoryStatus, cardWidth int) cardData {
+	// Check if swap is being used (or at least allocated).
+	hasSwap := mem.SwapTotal > 0 || mem.SwapUsed > 0
+
+	var lines []string
+	// Line 1: Used
+	lines = append(lines, fmt.Sprintf("Used   %s  %5.1f%%", progressBar(mem.UsedPercent), mem.UsedPercent))
+
+	// Line 2: Free
+	var freePercent float64
+	if mem.Total > 0 {
+		freePercent = (float64(mem.Available) / float64(mem.Total)) * 100.0
+	}
+	lines = append(lines, fmt.Sprintf("Free   %s  %5.1f%%", progressBar(freePercent), freePercent))
+
+	if hasSwap {
+		// Layout with Swap:
+		// 3. Swap (progress bar + text)
+		// 4. Total + Avail
+		var swapPercent float64
+		if mem.SwapTotal > 0 {
+			swapPercent = (float64(mem.SwapUsed) / float64(mem.SwapTotal)) * 100.0
+		}
+		swapLine := fmt.Sprintf("Swap   %s  %5.1f%%", progressBar(swapPercent), swapPercent)
+		swapText := fmt.Sprintf("%s/%s", humanBytesCompact(mem.SwapUsed), humanBytesCompact(mem.SwapTotal))
+		swapLineWithText := swapLine + " " + swapText
+		if cardWidth > 0 && lipgloss.Width(swapLineWithText) <= cardWidth {
+			lines = append(lines, swapLineWithText)
+		} else if cardWidth <= 0 {
+			lines = append(lines, swapLineWithText)
+		} else {
+			lines = append(lines, swapLine)
+		}
+
+		lines = append(lines, formatMemoryDetailLine("Total", humanBytes(mem.Used)+" / "+humanBytes(mem.Total), mem.Available, cardWidth))
+	} else {
+		// Layout without Swap:
+		// 3. Total
+		// 4. Cache + Avail
+		lines = append(lines, fmt.Sprintf("Total  %s / %s", humanBytes(mem.Used), humanBytes(mem.Total)))
+
+		if mem.Cached > 0 {
+			lines = append(lines, formatMemoryDetailLine("Cache", humanBytes(mem.Cached), mem.Available, cardWidth))
+		} else {
+			lines = append(lines, fmt.Sprintf("Avail  %s", humanBytes(mem.Available)))
+		}
+	}
+	if mem.Compressed > 0 {
+		lines = append(lines, fmt.Sprintf("%-6s %s", "Comp", humanBytes(mem.Compressed)))
+	}
+	if line := formatPagingLine(mem); line != "" {
+		lines = append(lines, line)
+	}
+	// Memory pressure status.
+	if mem.Pressure != "" {
+		pressureStyle := okStyle
+		pressureText := "Status " + mem.Pressure
+		switch mem.Pressure {
+		case "warn":
+			pressureStyle = warnStyle
+		case "critical":
+			pressureStyle = dangerStyle
+		}
+		lines = append(lines, pressureStyle.Render(pressureText))
+	}
+	return cardData{icon: iconMemory, title: "Memory", lines: lines}
+}
+
+// formatPagingLine shows swap traffic and page faults, e.g.
+// "Paging ↓0.1 ↑2.4 MB/s · 1.2k flt/s". Sustained swap-out is the clearest
+// sign the machine is short on memory, so it is highlighted.
+func formatPagingLine(mem sysmetrics.MemoryStatus) string {
+	if mem.SwapInRate == 0 && mem.SwapOutRate == 0 && mem.PageFaultRate == 0 {
+		return ""
+	}
+	swapText := fmt.Sprintf("↓%.1f ↑%.1f MB/s", mem.SwapInRate, mem.SwapOutRate)
+	if mem.SwapOutRate >= 1 {
+		swapText = warnStyle.Render(swapText)
+	}
+	faults := fmt.Sprintf("%.0f", mem.PageFaultRate)
+	if mem.PageFaultRate >= 1000 {
+		faults = fmt.Sprintf("%.1fk", mem.PageFaultRate/1000)
+	}
+	return fmt.Sprintf("%-6s %s · %s flt/s", "Paging", swapText, faults)
+}
+
+func formatMemoryDetailLine(label string, value string, available uint64, cardWidth int) string {
+	line := fmt.Sprintf("%-6s %s · Avail %s", label, value, humanBytes(available))
+	if cardWidth <= 0 || lipgloss.Width(line) <= cardWidth {
+		return line
+	}
+	return fmt.Sprintf("%-6s %s · Avail %s", label, value, humanBytesCompact(available))
+}
+
+func renderDiskCard(disks []sysmetrics.DiskStatus, io sysmetrics.DiskIOStatus, _ uint64, _ bool) cardData {
+	var lines []string
+	if len(disks) == 0 {
+		lines = append(lines, subtleStyle.Render(i18n.T("Collecting...")))
+	} else {
+		internal, external := splitDisks(disks)
+		addGroup := func(prefix string, list []sysmetrics.DiskStatus) {
+			if len(list) == 0 {
+				return
+			}
+			for i, d := range list {
+				label := diskLabel(prefix, i, len(list))
+				lines = append(lines, formatDiskLine(label, d))
+			}
+		}
+		addGroup("INTR", internal)
+		addGroup("EXTR", external)
+		if len(lines) == 0 {
+			lines = append(lines, subtleStyle.Render(i18n.T("No disks detected")))
+		} else if len(disks) == 1 {
+			lines = append(lines, formatDiskMetaLine(disks[0]))
+		}
+	}
+	lines = append(lines, formatDiskIOLine(io))
+	lines = append(lines, formatDiskIODetailLines(io)...)
+	return cardData{icon: iconDisk, title: "Disk", lines: lines}
+}
+
+func splitDisks(disks []sysmetrics.DiskStatus) (internal, external []sysmetrics.DiskStatus) {
+	for _, d := range disks {
+		if d.External {
+			external = append(external, d)
+		} else {
+			internal = append(internal, d)
+		}
+	}
+	return internal, external
+}
+
+func diskLabel(prefix string, index int, total int) string {
+	if total <= 1 {
+		return prefix
+	}
+	return fmt.Sprintf("%s%d", prefix, index+1)
+}
+
+func formatDiskLine(label string, d sysmetrics.DiskStatus) string {
+	if label == "" {
+		label = "DISK"
+	}
+	bar := progressBar(d.UsedPercent)
+	used := humanBytesShort(d.Used)
+	free := uint64(0)
+	if d.Total > d.Used {
+		free = d.Total - d.Used
+	}
+	return fmt.Sprintf("%s %s  %s used, %s free", linkPadded(d.Mount, label, 6), bar, used, humanBytesShort(free))
+}
+
+func formatDiskMetaLine(d sysmetrics.DiskStatus) string {
+	parts := []string{humanBytesShort(d.Total)}
+	if d.Fstype != "" {
+		parts = append(parts, strings.ToUpper(d.Fstype))
+	}
+	return fmt.Sprintf("Total  %s", strings.Join(parts, " · "))
+}
+
+func formatDiskIOLine(io sysmetrics.DiskIOStatus) string {
+	text := fmt.Sprintf("%s R %s · %s W %s MB/s",
+		ioBar(io.ReadRate),
+		formatRateCompact(io.ReadRate),
+		ioBar(io.WriteRate),
+		formatRateCompact(io.WriteRate),
+	)
+	return fmt.Sprintf("%-*s %s", metricLabelWidth, "I/O", text)
+}
+
+// formatDiskIODetailLines adds IOPS, latency, and a throughput trend under
+// the I/O line, plus a row per disk when more than one is busy so a backup
+// drive or Spotlight indexing an external volume is attributable.
+func formatDiskIODetailLines(io sysmetrics.DiskIOStatus) []string {
+	var lines []string
+	if io.ReadIOPS+io.WriteIOPS > 0 {
+		lines = append(lines, fmt.Sprintf("%-*s R %.0f · W %.0f IOPS · %s",
+			metricLabelWidth, "Ops", io.ReadIOPS, io.WriteIOPS, formatLatency(io.LatencyMs)))
+	}
+	if len(io.ReadHistory) > 1 {
+		lines = append(lines, fmt.Sprintf("%-*s R %s  W %s", metricLabelWidth, "Trend",
+			okStyle.Render(sparkline(io.ReadHistory, io.ReadRate, diskTrendWidth)),
+			warnStyle.Render(sparkline(io.WriteHistory, io.WriteRate, diskTrendWidth))))
+	}
+	if len(io.Devices) > 1 {
+		for _, dev := range io.Devices[:min(len(io.Devices), 2)] {
+			lines = append(lines, fmt.Sprintf("%-*s R %s · W %s MB/s · %s", metricLabelWidth, shorten(dev.Name, metricLabelWidth),
+				formatRateCompact(dev.ReadRate), formatRateCompact(dev.WriteRate), formatLatency(dev.LatencyMs)))
+		}
+	}
+	return lines
+}
+
+func formatLatency(ms float64) string {
+	if ms < 10 {
+		return fmt.Sprintf("%.1fms", ms)
+	}
+	text := fmt.Sprintf("%.0fms", ms)
+	if ms >= 50 {
+		return dangerStyle.Render(text)
+	}
+	return warnStyle.Render(text)
+}
+
+func withDiskHealth(card cardData, health []sysmetrics.DiskHealth) cardData {
+	if line := formatDiskHealthLine(health); line != "" {
+		card.lines = append(card.lines, line)
+	}
+	return card
+}
+
+// formatDiskHealthLine summarizes SMART state in one row: the worst drive's
+// first reason when anything is off, otherwise NVMe wear and spare.
+func formatDiskHealthLine(health []sysmetrics.DiskHealth) string {
+	if len(health) == 0 {
+		return ""
+	}
+	worst := health[0]
+	for _, h := range health[1:] {
+		if diskHealthRank(h.Status) > diskHealthRank(worst.Status) {
+			worst = h
+		}
+	}
+
+	switch worst.Status {
+	case sysmetrics.DiskHealthFailing, sysmetrics.DiskHealthDegraded:
+		text := strings.ToUpper(worst.Status)
+		if len(health) > 1 {
+			text += " " + strings.TrimPrefix(worst.Device, "/dev/")
+		}
+		if len(worst.Reasons) > 0 {
+			text += ": " + worst.Reasons[0]
+		}
+		style := warnStyle
+		if worst.Status == sysmetrics.DiskHealthFailing {
+			style = dangerStyle
+		}
+		return fmt.Sprintf("%-*s %s", metricLabelWidth, "Health", style.Render(text))
+	}
+
+	parts := []string{okStyle.Render(i18n.T("OK"))}
+	if len(health) > 1 {
+		parts = append(parts, fmt.Sprintf("%d disks", len(health)))
+	} else if worst.SparePercent > 0 {
+		parts = append(parts, fmt.Sprintf("wear %d%%", worst.WearPercent), fmt.Sprintf("spare %d%%", worst.SparePercent))
+	}
+	return fmt.Sprintf("%-*s %s", metricLabelWidth, "Health", strings.Join(parts, " · "))
+}
+
+func diskHealthRank(status string) int {
+	switch status {
+	case sysmetrics.DiskHealthFailing:
+		return 2
+	case sysmetrics.DiskHealthDegraded:
+		return 1
+	}
+	return 0
+}
+
+func ioBar(rate float64) string {
+	filled := max(min(int(rate/10.0), 5), 0)
+	bar := strings.Repeat(cellFull, filled) + strings.Repeat(cellEmpty, 5-filled)
+	if rate > 80 {
+		return dangerStyle.Render(bar)
+	}
+	if rate > 30 {
+		return warnStyle.Render(bar)
+	}
+	return okStyle.Render(bar)
+}
+
+// renderProcessCard lists the top processes for sortKey. The bar follows
+// the sort key; CPU percent stays visible in every mode, and the last column
+// shows energy impact when sorting by energy, resident memory otherwise.
+func renderProcessCard(procs []sysmetrics.ProcessInfo, cardWidth int, sortKey string) cardData {
+	var lines []string
+	for i, p := range procs {
+		if i >= processCardRows {
+			break
+		}
+		rank := fmt.Sprintf("#%d", i+1)
+		barValue, detail := p.CPU, processMemoryText(p)
+		switch sortKey {
+		case sysmetrics.ProcessSortMemory:
+			barValue = p.Memory
+		case sysmetrics.ProcessSortEnergy:
+			barValue, detail = min(p.Energy, 100), fmt.Sprintf("E%.1f", p.Energy)
+		}
+		line := fmt.Sprintf(
+			"%-*s %s %5.1f%% %*s",
+			metricLabelWidth,
+			rank,
+			processBar(barValue, cardWidth),
+			p.CPU,
+			processMemoryWidth,
+			detail,
+		)
+		if nameWidth := remainingLineWidth(cardWidth, line); nameWidth > 0 {
+			line += " " + shorten(p.Name, nameWidth)
+		}
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, subtleStyle.Render(i18n.T("Collecting...")))
+	}
+	title := "Processes"
+	if sortKey != "" && sortKey != sysmetrics.ProcessSortCPU {
+		title += " by " + sortKey
+	}
+	return cardData{icon: iconProcs, title: title, lines: lines}
+}
+
+// withGPUProcesses adds the processes driving GPU load, across all GPUs.
+func withGPUProcesses(card cardData, gpus []sysmetrics.GPUStatus, now time.Time) cardData {
+	var procs []sysmetrics.GPUProcess
+	for _, gpu := range gpus {
+		procs = append(procs, gpu.Processes...)
+	}
+	if len(procs) == 0 {
+		return card
+	}
+	slices.SortStableFunc(procs, func(a, b sysmetrics.GPUProcess) int { return cmp.Compare(b.Usage, a.Usage) })
+	parts := make([]string, 0, len(procs))
+	for _, p := range procs {
+		parts = append(parts, fmt.Sprintf("%s %.0f%%", p.Name, p.Usage))
+	}
+	age := gpuAgeSuffix(gpus, now)
+	line := fmt.Sprintf("%-*s %s", metricLabelWidth, "GPU", joinFit(parts, colWidth-metricLabelWidth-1-lipgloss.Width(age)))
+	card.lines = append(card.lines, line+age)
+	return card
+}
+
+// staleAfter is how old a cached reading gets before the view says so.
+const staleAfter = 5 * time.Second
+
+// gpuAgeSuffix notes how old the GPU reading is once it trails the snapshot,
+// e.g. " (12s ago)" between full refreshes.
+func gpuAgeSuffix(gpus []sysmetrics.GPUStatus, now time.Time) string {
+	if len(gpus) == 0 || gpus[0].SampledAt.IsZero() || now.IsZero() {
+		return ""
+	}
+	age := now.Sub(gpus[0].SampledAt)
+	if age < staleAfter {
+		return ""
+	}
+	text := sysmetrics.FormatUptime(uint64(age.Seconds()))
+	if age < time.Minute {
+		text = fmt.Sprintf("%ds", int(age.Seconds()))
+	}
+	return subtleStyle.Render(" (" + text + " ago)")
+}
+
+// withStuckProcesses counts zombies and processes stuck in uninterruptible
+// wait; "a" opens the first one in the inspect panel.
+func withStuckProcesses(card cardData, stuck []sysmetrics.StuckProcess) cardData {
+	var zombies, blocked int
+	for _, p := range stuck {
+		if p.State == "zombie" {
+			zombies++
+		} else {
+			blocked++
+		}
+	}
+	var parts []string
+	if blocked > 0 {
+		parts = append(parts, dangerStyle.Render(fmt.Sprintf("%d blocked", blocked)))
+	}
+	if zombies > 0 {
+		parts = append(parts, warnStyle.Render(fmt.Sprintf("%d zombie", zombies)))
+	}
+	if len(parts) == 0 {
+		return card
+	}
+	parts = append(parts, subtleStyle.Render(i18n.T("a open")))
+	card.lines = append(card.lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "Stuck", joinFit(parts, colWidth-metricLabelWidth-1)))
+	return card
+}
+
+// withSpotlight notes Spotlight indexing on the Processes card, since it
+// often explains CPU and disk load after an OS update.
+func withSpotlight(card cardData, s sysmetrics.SpotlightStatus) cardData {
+	if !s.Indexing {
+		return card
+	}
+	line := fmt.Sprintf("%-*s %s", metricLabelWidth, "Index",
+		joinFit([]string{fmt.Sprintf("Spotlight %.0f%%", s.CPU), fmt.Sprintf("%d workers", s.Workers)}, colWidth-metricLabelWidth-1))
+	card.lines = append(card.lines, line)
+	return card
+}
+
+// withLeakSuspects names the process whose memory has grown the most
+// without letting up, and how long that has gone on.
+func withLeakSuspects(card cardData, suspects []sysmetrics.LeakSuspect, now time.Time) cardData {
+	if len(suspects) == 0 {
+		return card
+	}
+	top := suspects[0]
+	parts := []string{fmt.Sprintf("%s +%s in %s", top.Name, humanBytesShort(top.Growth()),
+		sysmetrics.FormatUptime(uint64(max(now.Sub(top.Since), 0).Seconds())))}
+	if len(suspects) > 1 {
+		parts = append(parts, fmt.Sprintf("+%d more", len(suspects)-1))
+	}
+	card.lines = append(card.lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "Leak?",
+		warnStyle.Render(joinFit(parts, colWidth-metricLabelWidth-1))))
+	return card
+}
+
+func processBar(percent float64, cardWidth int) string {
+	if cardWidth >= processWideMinWidth {
+		return progressBar(percent)
+	}
+	return miniBar(percent)
+}
+
+func processMemoryText(p sysmetrics.ProcessInfo) string {
+	if p.MemoryBytes > 0 {
+		return humanBytesCompact(p.MemoryBytes)
+	}
+	if p.Memory >= 10 {
+		return fmt.Sprintf("M%.0f%%", p.Memory)
+	}
+	return ""
+}
+
+func buildCards(m sysmetrics.MetricsSnapshot, width int) []cardData {
+	cards := []cardData{
+		renderCPUCard(m.CPU, m.Thermal, m.Power, m.ANE),
+		withLeakSuspects(withGPUMemory(renderMemoryCard(m.Memory, width), m.GPU, m.Memory.Total, m.CollectedAt), m.LeakSuspects, m.CollectedAt),
+		withDiskHealth(renderDiskCard(m.Disks, m.DiskIO, m.TrashSize, m.TrashApprox), m.DiskHealth),
+		renderBatteryCard(m.Batteries, m.Thermal),
+		withStuckProcesses(withSpotlight(withGPUProcesses(renderProcessCard(m.TopProcesses, width, m.ProcessSort), m.GPU, m.CollectedAt), m.Spotlight), m.Stuck),
+		withClock(withICloud(withSpeedTest(withPublicIP(renderNetworkCard(m.Network, m.NetworkHistory, m.NetworkProcs, m.Proxy, width), m.PublicIP), m.SpeedTests), m.ICloud), m.Clock),
+	}
+	if !m.BootTime.IsZero() {
+		cards = append(cards, renderSystemCard(m))
+	}
+	if m.Limits.Pressure() >= sysmetrics.LimitsShowRatio {
+		cards = append(cards, renderLimitsCard(m.Limits))
+	}
+	if len(m.PowerEvents) > 0 {
+		cards = append(cards, renderSleepCard(m.PowerEvents))
+	}
+	if len(m.Sensors) > 0 {
+		cards = append(cards, renderSensorsCard(m.Sensors))
+	}
+	if len(m.Latency) > 0 {
+		cards = append(cards, renderLatencyCard(m.Latency))
+	}
+	if m.Containers.Runtime != "" {
+		cards = append(cards, renderContainersCard(m.Containers))
+	}
+	if m.Kubernetes.Context != "" {
+		cards = append(cards, renderKubernetesCard(m.Kubernetes))
+	}
+	if len(m.VMs) > 0 {
+		cards = append(cards, renderVMCard(m.VMs))
+	}
+	if len(m.Peripherals) > 0 {
+		cards = append(cards, renderPeripheralsCard(m.Peripherals))
+	}
+	// A lone built-in panel is nothing to debug; show external setups.
+	if slices.ContainsFunc(m.Displays, func(d sysmetrics.Display) bool { return d.Connection != "Internal" }) {
+		cards = append(cards, renderDisplaysCard(m.Displays))
+	}
+	if len(m.Services) > 0 {
+		cards = append(cards, renderServicesCard(m.Services))
+	}
+	if r := m.CrashReports; r.Crashes+r.Hangs+r.Panics > 0 {
+		cards = append(cards, renderCrashReportsCard(r))
+	}
+	if m.TimeMachine.Destination != "" {
+		cards = append(cards, renderTimeMachineCard(m.TimeMachine, m.CollectedAt))
+	}
+	// Long-installed agents are background noise; only new ones need a look.
+	if slices.ContainsFunc(m.LaunchItems, func(item sysmetrics.LaunchItem) bool { return item.Recent }) {
+		cards = append(cards, renderLaunchItemsCard(m.LaunchItems))
+	}
+	if len(m.DNS.Servers) > 0 {
+		cards = append(cards, renderDNSCard(m.DNS))
+	}
+	if m.VPN.Active() {
+		cards = append(cards, renderVPNCard(m.VPN))
+	}
+	if len(m.Ports.Listeners) > 0 {
+		cards = append(cards, renderPortsCard(m.Ports))
+	}
+	// A single plain volume is already the Disk card; only show Storage
+	// when there are APFS pools or several volumes to tell apart.
+	if len(m.Storage.Containers) > 0 || len(m.Storage.Volumes) > 1 {
+		cards = append(cards, renderStorageCard(m.Storage))
+	}
+	return cards
+}
+
+// renderLatencyCard answers "is my connection bad right now": average round
+// trip with jitter, loss, and the trend across recent refreshes per target.
+func renderLatencyCard(probes []sysmetrics.LatencyProbe) cardData {
+	lines := make([]string, 0, len(probes))
+	for _, p := range probes {
+		label := shorten(p.Target, latencyLabelWidth)
+		if p.LossPercent >= 100 {
+			lines = append(lines, fmt.Sprintf("%-*s %s", latencyLabelWidth, label, dangerStyle.Render(i18n.T("unreachable"))))
+			continue
+		}
+		rtt := fmt.Sprintf("%.0fms ±%.1f", p.AvgMs, p.JitterMs)
+		switch {
+		case p.AvgMs >= 250:
+			rtt = dangerStyle.Render(rtt)
+		case p.AvgMs >= 100:
+			rtt = warnStyle.Render(rtt)
+		}
+		loss := fmt.Sprintf("%.0f%%", p.LossPercent)
+		switch {
+		case p.LossPercent >= 20:
+			loss = dangerStyle.Render(loss)
+		case p.LossPercent > 0:
+			loss = warnStyle.Render(loss)
+		}
+		line := fmt.Sprintf("%-*s %s %s", latencyLabelWidth, label, rtt, loss)
+		if len(p.History) > 1 {
+			line += " " + subtleStyle.Render(sparkline(p.History, p.AvgMs, latencyGraphWidth))
+		}
+		lines = append(lines, line)
+	}
+	return cardData{icon: iconLatency, title: "Latency", lines: lines}
+}
+
+// renderContainersCard shows the engine's VM allocation, then the busiest
+// containers by CPU.
+func renderContainersCard(status sysmetrics.ContainerStatus) cardData {
+	vm := []string{status.Runtime}
+	if status.VMCPUs > 0 {
+		vm = append(vm, fmt.Sprintf("%d CPU", status.VMCPUs))
+	}
+	if status.VMMemory > 0 {
+		vm = append(vm, humanBytesShort(status.VMMemory))
+	}
+	lines := []string{fmt.Sprintf("%-*s %s", metricLabelWidth, "VM", joinFit(vm, colWidth-metricLabelWidth-1))}
+	if len(status.Containers) == 0 {
+		lines = append(lines, subtleStyle.Render(i18n.T("No running containers")))
+	}
+	for _, c := range status.Containers[:min(len(status.Containers), processCardRows)] {
+		mem := humanBytesCompact(c.MemoryBytes)
+		lines = append(lines, fmt.Sprintf("%-*s %5.1f%% %7s", containerNameWidth, shorten(c.Name, containerNameWidth), c.CPU, mem))
+	}
+	if extra := len(status.Containers) - processCardRows; extra > 0 {
+		lines = append(lines, subtleStyle.Render(fmt.Sprintf("+%d more", extra)))
+	}
+	return cardData{icon: iconBox, title: "Containers", lines: lines}
+}
+
+// renderKubernetesCard summarizes pod health, then node usage and the
+// busiest pods when metrics-server is installed.
+func renderKubernetesCard(status sysmetrics.KubernetesStatus) cardData {
+	pods := []string{fmt.Sprintf("%d up", status.Pods.Running)}
+	if status.Pods.Pending > 0 {
+		pods = append(pods, warnStyle.Render(fmt.Sprintf("%d pending", status.Pods.Pending)))
+	}
+	if status.Pods.Failing > 0 {
+		pods = append(pods, dangerStyle.Render(fmt.Sprintf("%d failing", status.Pods.Failing)))
+	}
+	lines := []string{
+		fmt.Sprintf("%-*s %s", metricLabelWidth, "Ctx", shorten(status.Context, colWidth-metricLabelWidth-1)),
+		fmt.Sprintf("%-*s %s", metricLabelWidth, "Pods", strings.Join(pods, " · ")),
+	}
+	for _, node := range status.Nodes[:min(len(status.Nodes), processCardRows)] {
+		lines = append(lines, fmt.Sprintf("%-*s %-*s %3.0f%% %s", metricLabelWidth, "Node",
+			containerNameWidth, shorten(node.Name, containerNameWidth), node.CPUPercent, humanBytesShort(node.MemoryBytes)))
+	}
+	for _, pod := range status.TopPods {
+		lines = append(lines, fmt.Sprintf("%-*s %5dm %7s", containerNameWidth, shorten(pod.Name, containerNameWidth), pod.CPUMilli, humanBytesCompact(pod.MemoryBytes)))
+	}
+	return cardData{icon: iconKube, title: "Kubernetes", lines: lines}
+}
+
+// renderVMCard shows each guest's host CPU against its vCPU allocation and
+// resident memory against its configured size.
+func renderVMCard(vms []sysmetrics.VirtualMachine) cardData {
+	var lines []string
+	for _, vm := range vms[:min(len(vms), processCardRows)] {
+		cpu := fmt.Sprintf("%3.0f%%", vm.CPUPercent)
+		if vm.CPUs > 0 {
+			cpu = fmt.Sprintf("%3.0f%% of %d", vm.CPUPercent/float64(vm.CPUs), vm.CPUs)
+		}
+		mem := humanBytesShort(vm.MemoryUsed)
+		if vm.MemoryAllocated > 0 {
+			mem += "/" + humanBytesShort(vm.MemoryAllocated)
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %s · %s", containerNameWidth, shorten(vm.Name, containerNameWidth), cpu, mem))
+	}
+	if extra := len(vms) - processCardRows; extra > 0 {
+		lines = append(lines, subtleStyle.Render(fmt.Sprintf("+%d more", extra)))
+	}
+	return cardData{icon: iconVM, title: "Virtual Machines", lines: lines}
+}
+
+// renderPeripheralsCard lists USB and Thunderbolt devices with their link
+// speed, putting any that negotiated below what they support first.
+func renderPeripheralsCard(peripherals []sysmetrics.Peripheral) cardData {
+	sorted := slices.Clone(peripherals)
+	slices.SortStableFunc(sorted, func(a, b sysmetrics.Peripheral) int {
+		switch {
+		case a.SlowLink() == b.SlowLink():
+			return 0
+		case a.SlowLink():
+			return -1
+		}
+		return 1
+	})
+	var lines []string
+	for _, p := range sorted[:min(len(sorted), processCardRows)] {
+		link := formatLinkSpeed(p.SpeedMbps)
+		if p.SlowLink() {
+			link = warnStyle.Render(link + " of " + formatLinkSpeed(p.MaxSpeedMbps))
+		}
+		line := fmt.Sprintf("%-*s %s", containerNameWidth, shorten(p.Name, containerNameWidth), link)
+		if p.PowerMilli > 0 {
+			line += subtleStyle.Render(fmt.Sprintf(" %dmA", p.PowerMilli))
+		}
+		lines = append(lines, line)
+	}
+	if extra := len(sorted) - processCardRows; extra > 0 {
+		lines = append(lines, subtleStyle.Render(fmt.Sprintf("+%d more", extra)))
+	}
+	return cardData{icon: iconPlug, title: "Peripherals", lines: lines}
+}
+
+// renderDisplaysCard shows each display's mode and refresh rate, flagging
+// 30Hz or lower since that usually means a dock or cable fell back. A
+// second line has the connection, scaled size, and HDR.
+func renderDisplaysCard(displays []sysmetrics.Display) cardData {
+	var lines []string
+	for _, d := range displays[:min(len(displays), processCardRows)] {
+		mode := fmt.Sprintf("%dx%d", d.Width, d.Height)
+		if d.RefreshHz > 0 {
+			hz := fmt.Sprintf("%.0fHz", d.RefreshHz)
+			if d.RefreshHz <= 30.5 {
+				hz = warnStyle.Render(hz)
+			}
+			mode += " " + hz
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %s", containerNameWidth, shorten(d.Name, containerNameWidth), mode))
+
+		var detail []string
+		if d.Connection != "" {
+			detail = append(detail, d.Connection)
+		}
+		if d.LooksWidth > 0 {
+			detail = append(detail, fmt.Sprintf("looks %dx%d", d.LooksWidth, d.LooksHeight))
+		}
+		if d.HDR {
+			detail = append(detail, "HDR")
+		}
+		if len(detail) > 0 {
+			lines = append(lines, subtleStyle.Render("  "+joinFit(detail, colWidth-2)))
+		}
+	}
+	return cardData{icon: iconDisplay, title: "Displays", lines: lines}
+}
+
+// renderSystemCard shows uptime with the boot time, the last wake from
+// sleep, and who is logged in. Load averages stay on the CPU card.
+func renderSystemCard(m sysmetrics.MetricsSnapshot) cardData {
+	lines := []string{fmt.Sprintf("%-*s %s · since %s", metricLabelWidth, "Up",
+		sysmetrics.FormatUptime(m.UptimeSeconds), m.BootTime.Format("Jan 2 15:04"))}
+	if s := m.Session; !s.WakeTime.IsZero() && s.WakeTime.After(m.BootTime) {
+		wake := []string{sysmetrics.FormatUptime(uint64(max(m.CollectedAt.Sub(s.WakeTime), 0).Seconds())) + " ago"}
+		if s.WakeReason != "" {
+			wake = append([]string{s.WakeReason}, wake...)
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "Wake", joinFit(wake, colWidth-metricLabelWidth-1)))
+	}
+	if s := m.Session; len(s.Users) > 0 {
+		users := []string{strings.Join(s.Users, ", ")}
+		if s.Sessions > 1 {
+			users = append(users, fmt.Sprintf("%d sessions", s.Sessions))
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "Users", joinFit(users, colWidth-metricLabelWidth-1)))
+	}
+	return cardData{icon: iconSystem, title: "System", lines: lines}
+}
+
+// renderLimitsCard compares open files, processes, and threads with their
+// kernel caps, and lists the processes holding the most descriptors
+// against the per-process cap. It only appears once a limit gets close.
+func renderLimitsCard(l sysmetrics.LimitsStatus) cardData {
+	var lines []string
+	row := func(label string, used, limit int) {
+		if limit <= 0 {
+			return
+		}
+		percent := float64(used) / float64(limit) * 100
+		lines = append(lines, fmt.Sprintf("%-*s %s  %s", metricLabelWidth, label, progressBar(percent),
+			limitStyle(percent).Render(fmt.Sprintf("%d/%d", used, limit))))
+	}
+	row("Files", l.OpenFiles, l.MaxFiles)
+	row("Procs", l.Procs, l.MaxProcs)
+	row("Thrds", l.Threads, l.MaxThreads)
+	for _, p := range l.TopFDs {
+		text := fmt.Sprintf("%d fds", p.FDs)
+		if l.MaxFilesPerProc > 0 {
+			percent := float64(p.FDs) / float64(l.MaxFilesPerProc) * 100
+			text = limitStyle(percent).Render(fmt.Sprintf("%d/%d fds", p.FDs, l.MaxFilesPerProc))
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %s", containerNameWidth, shorten(sysmetrics.FormatProcessLabel(sysmetrics.ProcessInfo{PID: p.PID, Name: p.Name}), containerNameWidth), text))
+	}
+	return cardData{icon: iconLimits, title: "Limits", lines: lines}
+}
+
+func limitStyle(percent float64) lipgloss.Style {
+	switch {
+	case percent >= 95:
+		return dangerStyle
+	case percent >= sysmetrics.LimitsShowRatio*100:
+		return warnStyle
+	}
+	return subtleStyle
+}
+
+// renderSleepCard counts the last day's sleeps and dark wakes and lists the
+// latest events with battery charge, newest first, so overnight drain can
+// be matched to what woke the machine.
+func renderSleepCard(events []sysmetrics.PowerEvent) cardData {
+	var sleeps, dark int
+	for _, e := range events {
+		switch e.Kind {
+		case "sleep":
+			sleeps++
+		case "darkwake":
+			dark++
+		}
+	}
+	lines := []string{fmt.Sprintf("%-*s sleeps %d · dark wakes %d", metricLabelWidth, "24h", sleeps, dark)}
+	for _, e := range events[:min(len(events), processCardRows)] {
+		parts := []string{e.At.Format("15:04"), fmt.Sprintf("%-5s", powerEventLabels[e.Kind])}
+		if e.Charge >= 0 {
+			parts = append(parts, fmt.Sprintf("%3d%%", e.Charge))
+		}
+		line := strings.Join(parts, " ")
+		if e.Reason != "" {
+			line += " " + subtleStyle.Render(shorten(e.Reason, max(colWidth-lipgloss.Width(line)-1, 4)))
+		}
+		lines = append(lines, line)
+	}
+	return cardData{icon: iconSleep, title: "Sleep", lines: lines}
+}
+
+var powerEventLabels = map[string]string{"sleep": "Sleep", "wake": "Wake", "darkwake": "Dark"}
+
+// renderCrashReportsCard counts the week's crash, hang, and panic reports
+// and names the process that crashed most.
+func renderCrashReportsCard(r sysmetrics.CrashReports) cardData {
+	counts := []string{
+		fmt.Sprintf("crashes %d", r.Crashes),
+		fmt.Sprintf("hangs %d", r.Hangs),
+	}
+	if r.Panics > 0 {
+		counts = append(counts, dangerStyle.Render(fmt.Sprintf("panics %d", r.Panics)))
+	}
+	lines := []string{fmt.Sprintf("%-*s %s", metricLabelWidth, "7d", strings.Join(counts, " · "))}
+	if r.TopCount > 1 {
+		lines = append(lines, fmt.Sprintf("%-*s %s ×%d", metricLabelWidth, "Top", shorten(r.TopProcess, colWidth-metricLabelWidth-5), r.TopCount))
+	}
+	return cardData{icon: iconCrash, title: "Crash Reports", lines: lines}
+}
+
+// renderTimeMachineCard shows when the last backup finished and where, and
+// the phase and progress of one in flight.
+func renderTimeMachineCard(tm sysmetrics.TimeMachineStatus, now time.Time) cardData {
+	last := "never"
+	if !tm.LastBackup.IsZero() {
+		last = sysmetrics.FormatUptime(uint64(max(now.Sub(tm.LastBackup), 0).Seconds())) + " ago"
+	}
+	if tm.Overdue {
+		last = warnStyle.Render(last)
+	}
+	lines := []string{
+		fmt.Sprintf("%-*s %s", metricLabelWidth, "Last", last),
+		fmt.Sprintf("%-*s %s", metricLabelWidth, "Disk", tm.Destination),
+	}
+	if tm.Running {
+		progress := []string{cmp.Or(tm.Phase, "Running")}
+		if tm.Percent >= 0 {
+			progress = append(progress, fmt.Sprintf("%.0f%%", tm.Percent))
+		}
+		if tm.TimeRemaining > 0 {
+			progress = append(progress, sysmetrics.FormatUptime(uint64(tm.TimeRemaining))+" left")
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "Now", joinFit(progress, colWidth-metricLabelWidth-1)))
+	}
+	return cardData{icon: iconBackup, title: "Time Machine", lines: lines}
+}
+
+// renderServicesCard lists failed and busy services; "v" opens the panel
+// that restarts or stops them.
+func renderServicesCard(services []sysmetrics.ServiceStatus) cardData {
+	var lines []string
+	for _, svc := range services[:min(len(services), processCardRows)] {
+		lines = append(lines, formatServiceLine(svc))
+	}
+	if extra := len(services) - processCardRows; extra > 0 {
+		lines = append(lines, subtleStyle.Render(fmt.Sprintf("+%d more", extra)))
+	}
+	return cardData{icon: iconService, title: "Services", lines: lines}
+}
+
+func formatServiceLine(svc sysmetrics.ServiceStatus) string {
+	state := fmt.Sprintf("%.0f%% CPU", svc.CPU)
+	if svc.State == "error" {
+		state = dangerStyle.Render(fmt.Sprintf("exit %d", svc.ExitCode))
+	} else if svc.CPU >= sysmetrics.ServiceCPUThreshold*2 {
+		state = warnStyle.Render(state)
+	}
+	return fmt.Sprintf("%-*s %s %s", containerNameWidth, shorten(svc.Name, containerNameWidth), state, subtleStyle.Render(svc.Manager))
+}
+
+// renderLaunchItemsCard counts launchd jobs and lists the ones added in the
+// last week with whether they are running and what they execute.
+func renderLaunchItemsCard(items []sysmetrics.LaunchItem) cardData {
+	var running int
+	var recent []sysmetrics.LaunchItem
+	for _, item := range items {
+		if item.Running {
+			running++
+		}
+		if item.Recent {
+			recent = append(recent, item)
+		}
+	}
+	lines := []string{fmt.Sprintf("%d items · %d running · %s",
+		len(items), running, warnStyle.Render(fmt.Sprintf("%d new", len(recent))))}
+	for _, item := range recent[:min(len(recent), processCardRows)] {
+		state := subtleStyle.Render(i18n.T("idle"))
+		if item.Running {
+			state = okStyle.Render(i18n.T("running"))
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %s", containerNameWidth, shorten(item.Label, containerNameWidth), state))
+		if item.Program != "" {
+			lines = append(lines, subtleStyle.Render("  "+shorten(item.Program, colWidth-2)))
+		}
+	}
+	if extra := len(recent) - processCardRows; extra > 0 {
+		lines = append(lines, subtleStyle.Render(fmt.Sprintf("+%d more", extra)))
+	}
+	return cardData{icon: iconLaunch, title: "Login Items", lines: lines}
+}
+
+func formatLinkSpeed(mbps float64) string {
+	switch {
+	case mbps <= 0:
+		return "?"
+	case mbps >= 1000:
+		return fmt.Sprintf("%.0fG", mbps/1000)
+	}
+	return strconv.FormatFloat(mbps, 'f', -1, 64) + "M"
+}
+
+// renderDNSCard times each configured resolver so a slow or dead one is
+// visible next to the latency probes.
+func renderDNSCard(dns sysmetrics.DNSStatus) cardData {
+	var lines []string
+	for _, server := range dns.Servers {
+		result := fmt.Sprintf("%.0fms", server.LatencyMs)
+		switch {
+		case server.Error != "":
+			result = dangerStyle.Render(server.Error)
+		case server.Slow:
+			result = warnStyle.Render(result + " slow")
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %s", dnsLabelWidth, shorten(server.Address, dnsLabelWidth), result))
+	}
+	if len(dns.SearchDomains) > 0 {
+		lines = append(lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "Search",
+			subtleStyle.Render(joinFit(dns.SearchDomains, colWidth-metricLabelWidth-1))))
+	}
+	return cardData{icon: iconDNS, title: "DNS", lines: lines}
+}
+
+// renderVPNCard lists named VPN services and Tailscale; raw tunnel
+// interfaces only show when nothing named explains them.
+func renderVPNCard(vpn sysmetrics.VPNStatus) cardData {
+	var lines []string
+	for _, conn := range vpn.Connections[:min(len(vpn.Connections), 2)] {
+		lines = append(lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "VPN", okStyle.Render(conn.Name)))
+	}
+	if ts := vpn.Tailscale; ts != nil && ts.State == "Running" {
+		name := ts.Tailnet
+		if name == "" {
+			name = ts.IP
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %s · %d/%d peers", metricLabelWidth, "Tail",
+			okStyle.Render(shorten(name, 20)), ts.PeersOnline, ts.Peers))
+		if ts.ExitNode != "" {
+			lines = append(lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "Exit", ts.ExitNode))
+		}
+	}
+	if len(lines) == 0 && len(vpn.Tunnels) > 0 {
+		lines = append(lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "Tunnel", vpn.Tunnels[0]))
+	}
+	return cardData{icon: iconVPN, title: "VPN", lines: lines}
+}
+
+// renderPortsCard summarizes listeners and connections. Newly appeared
+// listeners get their own highlighted rows since they are the signal worth
+// noticing; the steady set collapses into one TCP row and a UDP count.
+func renderPortsCard(ports sysmetrics.PortsStatus) cardData {
+	var tcp, udp int
+	var newRows, steady []string
+	for _, l := range ports.Listeners {
+		if l.Proto == "udp" {
+			udp++
+		} else {
+			tcp++
+		}
+		label := fmt.Sprintf(":%d %s", l.Port, l.Process)
+		switch {
+		case l.New && len(newRows) < 2:
+			newRows = append(newRows, warnStyle.Render(fmt.Sprintf("%-*s %s %s (%d)", metricLabelWidth, "New", l.Proto, label, l.PID)))
+		case l.Proto == "tcp" && !slices.Contains(steady, label):
+			steady = append(steady, label)
+		}
+	}
+
+	lines := []string{fmt.Sprintf("%-*s %d TCP · %d UDP · %d est", metricLabelWidth, "Listen", tcp, udp, ports.Established)}
+	lines = append(lines, newRows...)
+	if len(steady) > 0 {
+		lines = append(lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "TCP",
+			joinFit(steady, colWidth-metricLabelWidth-1)))
+	}
+	if len(ports.Connections) > 0 {
+		parts := make([]string, 0, len(ports.Connections))
+		for _, c := range ports.Connections {
+			parts = append(parts, fmt.Sprintf("%s %d", c.Process, c.Established))
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "Conns",
+			joinFit(parts, colWidth-metricLabelWidth-1)))
+	}
+	return cardData{icon: iconPorts, title: "Ports", lines: lines}
+}
+
+// renderStorageCard shows each APFS container's shared free space with the
+// volumes drawing from it, then any standalone volumes.
+func renderStorageCard(storage sysmetrics.StorageStatus) cardData {
+	var lines []string
+	for _, c := range storage.Containers {
+		line := fmt.Sprintf("%-*s %s free of %s", metricLabelWidth, shorten(c.Device, metricLabelWidth),
+			humanBytesShort(c.Free), humanBytesShort(c.Total))
+		if c.Purgeable >= sysmetrics.StorageMinVolumeBytes {
+			line += " · " + humanBytesShort(c.Purgeable) + " purgeable"
+		}
+		lines = append(lines, line)
+
+		var parts []string
+		for _, v := range storage.Volumes {
+			if v.Container == c.Device {
+				parts = append(parts, v.Name+" "+humanBytesShort(v.Used))
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "",
+			subtleStyle.Render(joinFit(parts, colWidth-metricLabelWidth-1))))
+	}
+	for _, v := range storage.Volumes {
+		if v.Container != "" {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s %s used · %s free", linkPadded(v.Mount, shorten(v.Name, metricLabelWidth), metricLabelWidth),
+			humanBytesShort(v.Used), humanBytesShort(v.Free)))
+	}
+	if len(lines) > storageCardRows {
+		lines = lines[:storageCardRows]
+	}
+	return cardData{icon: iconDisk, title: "Storage", lines: lines}
+}
+
+// renderSensorsCard shows one row per component with a short history graph.
+// The graph spans half the warning threshold up to the danger threshold, so
+// an idle machine stays low and only heat near throttling fills the blocks.
+func renderSensorsCard(readings []sysmetrics.SensorReading) cardData {
+	lines := make([]string, 0, len(readings))
+	for _, r := range readings {
+		line := fmt.Sprintf("%-*s %s°C", metricLabelWidth, r.Label, colorizeTemp(r.Value))
+		if len(r.History) > 1 {
+			line += "  " + colorizeTempGraph(temperatureGraph(r.History, sensorGraphWidth), r.Value)
+		}
+		lines = append(lines, line)
+	}
+	return cardData{icon: iconSensors, title: "Sensors", lines: lines}
+}
+
+func temperatureGraph(history []float64, width int) string {
+	if len(history) > width {
+		history = history[len(history)-width:]
+	}
+	floor := *tempWarn / 2
+	span := *tempDanger - floor
+	var b strings.Builder
+	for _, v := range history {
+		level := int((v - floor) / span * float64(len(sparkBlocks)-1))
+		b.WriteRune(sparkBlocks[max(min(level, len(sparkBlocks)-1), 0)])
+	}
+	return b.String()
+}
+
+func colorizeTempGraph(graph string, current float64) string {
+	switch {
+	case current >= *tempDanger:
+		return dangerStyle.Render(graph)
+	case current >= *tempWarn:
+		return warnStyle.Render(graph)
+	default:
+		return subtleStyle.Render(graph)
+	}
+}
+
+func miniBar(percent float64) string {
+	filled := max(min(int(percent/20), 5), 0)
+	return colorizePercent(percent, strings.Repeat(cellFull, filled)+strings.Repeat(cellEmpty, 5-filled))
+}
+
+func renderNetworkCard(netStats []sysmetrics.NetworkStatus, history sysmetrics.NetworkHistory, procs []sysmetrics.ProcessNetwork, proxy sysmetrics.ProxyStatus, cardWidth int) cardData {
+	var lines []string
+	var totalRx, totalTx float64
+	var primaryIP string
+
+	for _, n := range netStats {
+		totalRx += n.RxRateMBs
+		totalTx += n.TxRateMBs
+		if primaryIP == "" && n.IP != "" && n.Name == "en0" {
+			primaryIP = n.IP
+		}
+	}
+
+	if len(netStats) == 0 {
+		lines = append(lines, subtleStyle.Render(i18n.T("Collecting...")))
+	} else {
+		// Calculate dynamic width
+		// Layout: "Down   " (7) + graph + "  " (2) + rate (approx 10-12)
+		// Safe margin: 22 chars.
+		// We target 16 chars to match progressBar implementation for visual consistency.
+		graphWidth := min(max(cardWidth-22, 5), 16)
+
+		// sparkline graphs
+		rxSparkline := sparkline(history.RxHistory, totalRx, graphWidth)
+		txSparkline := sparkline(history.TxHistory, totalTx, graphWidth)
+		lines = append(lines, fmt.Sprintf("Down   %s  %s", rxSparkline, formatRate(totalRx)))
+		lines = append(lines, fmt.Sprintf("Up     %s  %s", txSparkline, formatRate(totalTx)))
+		if line := formatNetworkProcessLine(procs); line != "" {
+			lines = append(lines, line)
+		}
+		// Show proxy and IP on one line.
+		var infoParts []string
+		if proxy.Enabled {
+			infoParts = append(infoParts, "Proxy "+proxy.Type)
+		}
+		if primaryIP != "" {
+			infoParts = append(infoParts, primaryIP)
+		}
+		if len(infoParts) > 0 {
+			lines = append(lines, strings.Join(infoParts, " · "))
+		}
+	}
+	return cardData{icon: iconNetwork, title: "Network", lines: lines}
+}
+
+func withPublicIP(card cardData, ip sysmetrics.PublicIPStatus) cardData {
+	if ip.IPv4 == "" && ip.IPv6 == "" {
+		return card
+	}
+	var parts []string
+	for _, part := range []string{cmp.Or(ip.IPv4, ip.IPv6), formatLocation(ip.City, ip.Country), ip.ASN} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	card.lines = append(card.lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "Public", joinFit(parts, colWidth-metricLabelWidth-1)))
+	return card
+}
+
+// withClock warns on the Network card when the clock has drifted from NTP
+// or the OS reports it unsynchronized; a healthy clock adds nothing.
+func withClock(card cardData, clock sysmetrics.ClockStatus) cardData {
+	var text string
+	switch {
+	case clock.Drifting:
+		offset := fmt.Sprintf("%+.0fms", clock.OffsetMs)
+		if math.Abs(clock.OffsetMs) >= 1000 {
+			offset = fmt.Sprintf("%+.1fs", clock.OffsetMs/1000)
+		}
+		text = offset + " off"
+		if clock.Server != "" {
+			text += " " + clock.Server
+		}
+	case clock.Sync == "unsynced":
+		text = "not synced"
+	default:
+		return card
+	}
+	card.lines = append(card.lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "Clock", warnStyle.Render(shorten(text, colWidth-metricLabelWidth-1))))
+	return card
+}
+
+// withICloud notes iCloud Drive syncing on the Network card, with the
+// items still waiting to transfer.
+func withICloud(card cardData, ic sysmetrics.ICloudStatus) cardData {
+	if !ic.Syncing {
+		return card
+	}
+	parts := []string{"syncing"}
+	if ic.PendingUploads > 0 {
+		parts = append(parts, fmt.Sprintf("%d up", ic.PendingUploads))
+	}
+	if ic.PendingDownloads > 0 {
+		parts = append(parts, fmt.Sprintf("%d down", ic.PendingDownloads))
+	}
+	parts = append(parts, fmt.Sprintf("%.0f%% CPU", ic.CPU))
+	card.lines = append(card.lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "iCloud", joinFit(parts, colWidth-metricLabelWidth-1)))
+	return card
+}
+
+// withSpeedTest shows the latest speed test, with the average download of
+// earlier runs for comparison. A result under half that average is flagged.
+func withSpeedTest(card cardData, history []sysmetrics.SpeedTestResult) cardData {
+	if len(history) == 0 {
+		return card
+	}
+	last := history[len(history)-1]
+	down := fmt.Sprintf("↓%.0f ↑%.0f Mbps", last.DownloadMbps, last.UploadMbps)
+	parts := []string{down, fmt.Sprintf("%.0fms", last.LatencyMs)}
+	if earlier := history[:len(history)-1]; len(earlier) > 0 {
+		var sum float64
+		for _, r := range earlier {
+			sum += r.DownloadMbps
+		}
+		avg := sum / float64(len(earlier))
+		if last.DownloadMbps < avg/2 {
+			parts[0] = warnStyle.Render(down)
+		}
+		parts = append(parts, fmt.Sprintf("avg ↓%.0f", avg))
+	}
+	card.lines = append(card.lines, fmt.Sprintf("%-*s %s", metricLabelWidth, "Speed", joinFit(parts, colWidth-metricLabelWidth-1)))
+	return card
+}
+
+func formatLocation(city, country string) string {
+	if city == "" || country == "" {
+		return city + country
+	}
+	return city + ", " + country
+}
+
+// formatNetworkProcessLine names the process behind most of the traffic, so
+// a spike in the graph above has an owner.
+func formatNetworkProcessLine(procs []sysmetrics.ProcessNetwork) string {
+	if len(procs) == 0 || procs[0].RxRateMBs+procs[0].TxRateMBs < 0.01 {
+		return ""
+	}
+	p := procs[0]
+	return fmt.Sprintf("%-*s %s ↓%s ↑%s MB/s", metricLabelWidth, "Top",
+		shorten(p.Name, 16), formatRateCompact(p.RxRateMBs), formatRateCompact(p.TxRateMBs))
+}
+
+// 8 levels: ▁▂▃▄▅▆▇█
+func sparkline(history []float64, current float64, width int) string {
+
+	data := make([]float64, 0, width)
+	if len(history) > 0 {
+		// Take the most recent points.
+		start := 0
+		if len(history) > width {
+			start = len(history) - width
+		}
+		data = append(data, history[start:]...)
+	}
+	// padding with zeros at the start
+	for len(data) < width {
+		data = append([]float64{0}, data...)
+	}
+	if len(data) > width {
+		data = data[len(data)-width:]
+	}
+
+	maxVal := 0.1
+	for _, v := range data {
+		if v > maxVal {
+			maxVal = v
+		}
+	}
+
+	var builder strings.Builder
+	for _, v := range data {
+		level := max(int((v/maxVal)*float64(len(sparkBlocks)-1)), 0)
+		if level >= len(sparkBlocks) {
+			level = len(sparkBlocks) - 1
+		}
+		builder.WriteRune(sparkBlocks[level])
+	}
+
+	result := builder.String()
+	if current > 8 {
+		return dangerStyle.Render(result)
+	}
+	if current > 3 {
+		return warnStyle.Render(result)
+	}
+	return okStyle.Render(result)
+}
+
+func renderBatteryCard(batts []sysmetrics.BatteryStatus, thermal sysmetrics.ThermalStatus) cardData {
+	var lines []string
+	if len(batts) == 0 {
+		lines = append(lines, subtleStyle.Render(i18n.T("No battery")))
+	} else {
+		b := batts[0]
+		statusLower := strings.ToLower(b.Status)
+		percentText := fmt.Sprintf("%5.1f%%", b.Percent)
+		if b.Percent < 20 && statusLower != "charging" && statusLower != "charged" {
+			percentText = dangerStyle.Render(percentText)
+		}
+		lines = append(lines, fmt.Sprintf("Level  %s  %s", batteryProgressBar(b.Percent), percentText))
+
+		// Add capacity line if available.
+		if b.Capacity > 0 {
+			capacityText := fmt.Sprintf("%5d%%", b.Capacity)
+			if b.Capacity < 70 {
+				capacityText = dangerStyle.Render(capacityText)
+			} else if b.Capacity < 85 {
+				capacityText = warnStyle.Render(capacityText)
+			}
+			lines = append(lines, fmt.Sprintf("Health %s  %s", batteryProgressBar(float64(b.Capacity)), capacityText))
+		}
+		if line := formatBatteryDetailLine(b); line != "" {
+			lines = append(lines, line)
+		}
+
+		if thermal.AdapterPower > 0 && isPoweredByAC(statusLower) {
+			lines = append(lines, fmt.Sprintf("%-6s %s  %6s",
+				"Input",
+				okStyle.Render(plainProgressBar(100)),
+				fmt.Sprintf("%.0fW max", thermal.AdapterPower),
+			))
+		}
+
+		statusStyle := subtleStyle
+		if isPoweredByAC(statusLower) {
+			statusStyle = okStyle
+		} else if b.Percent < 20 {
+			statusStyle = dangerStyle
+		}
+		statusText := formatBatteryStatus(b.Status)
+		if b.TimeLeft != "" && b.TimeLeft != "0:00" {
+			statusText += " · " + b.TimeLeft
+		}
+
+		healthParts := []string{}
+
+		// Battery health assessment label.
+		if b.CycleCount > 0 || b.Capacity > 0 {
+			label, severity := sysmetrics.BatteryHealthLabel(b.CycleCount, b.Capacity)
+			switch severity {
+			case "danger":
+				healthParts = append(healthParts, dangerStyle.Render(label))
+			case "warn":
+				healthParts = append(healthParts, warnStyle.Render(label))
+			default:
+				healthParts = append(healthParts, okStyle.Render(label))
+			}
+		} else if b.Health != "" {
+			healthParts = append(healthParts, b.Health)
+		}
+
+		if b.CycleCount > 0 {
+			cycleText := fmt.Sprintf("%d cycles", b.CycleCount)
+			if b.CycleCount > sysmetrics.BatteryCycleDanger {
+				cycleText = dangerStyle.Render(cycleText)
+			} else if b.CycleCount > sysmetrics.BatteryCycleWarn {
+				cycleText = warnStyle.Render(cycleText)
+			}
+			healthParts = append(healthParts, cycleText)
+		}
+
+		if thermal.BatteryTemp > 0 {
+			tempText := colorizeTemp(thermal.BatteryTemp) + "°C"
+			healthParts = append(healthParts, tempText)
+		}
+
+		if thermal.FanSpeed > 0 {
+			healthParts = append(healthParts, fmt.Sprintf("%d RPM", thermal.FanSpeed))
+		}
+
+		summaryParts := append([]string{statusStyle.Render(statusText)}, healthParts...)
+		lines = append(lines, strings.Join(summaryParts, " · "))
+	}
+
+	return cardData{icon: iconBattery, title: "Power", lines: lines}
+}
+
+// formatBatteryDetailLine shows remaining vs design capacity and what the
+// charger is doing, e.g. "Cell   4382/5103 mAh · +38.2W · full in 1:05".
+func formatBatteryDetailLine(b sysmetrics.BatteryStatus) string {
+	var parts []string
+	if b.MaxCapacity > 0 && b.DesignCapacity > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d %s", b.MaxCapacity, b.DesignCapacity, b.CapacityUnit))
+	}
+	if b.ChargeWatts > 0 {
+		parts = append(parts, okStyle.Render(fmt.Sprintf("+%.1fW", b.ChargeWatts)))
+	}
+	// pmset already puts its estimate in the status line.
+	if b.TimeLeft == "" {
+		switch {
+		case b.TimeToFull > 0:
+			parts = append(parts, "full in "+formatMinutes(b.TimeToFull))
+		case b.TimeToEmpty > 0:
+			parts = append(parts, "empty in "+formatMinutes(b.TimeToEmpty))
+		}
+	}
+	if b.OptimizedCharging {
+		parts = append(parts, subtleStyle.Render(i18n.T("charging on hold")))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%-*s %s", metricLabelWidth, "Cell", strings.Join(parts, " · "))
+}
+
+func formatMinutes(minutes int) string {
+	return fmt.Sprintf("%d:%02d", minutes/60, minutes%60)
+}
+
+func isPoweredByAC(statusLower string) bool {
+	return statusLower == "charging" ||
+		statusLower == "charged" ||
+		statusLower == "ac" ||
+		strings.Contains(statusLower, "ac attached")
+}
+
+func formatBatteryStatus(status string) string {
+	status = strings.TrimSpace(status)
+	if status == "" {
+		return "Unknown"
+	}
+	lower := strings.ToLower(status)
+	switch lower {
+	case "ac":
+		return "AC"
+	case "charged":
+		return "Charged"
+	case "charging":
+		return "Charging"
+	case "discharging":
+		return "Discharging"
+	}
+	return strings.ToUpper(status[:1]) + strings.ToLower(status[1:])
+}
+
+func renderCard(data cardData, width int, height int) string {
+	if width <= 0 {
+		width = colWidth
+	}
+
+	titleText := i18n.T(data.title)
+	if cardIcons && data.icon != "" {
+		titleText = data.icon + " " + titleText
+	}
+	lineLen := max(width-lipgloss.Width(titleText)-2, 0)
+
+	header := titleStyle.Render(titleText)
+	if lineLen > 0 {
+		header += "  " + lineStyle.Render(strings.Repeat(titleRule, lineLen))
+	}
+
+	lines := wrapToWidth(header, width)
+	for _, line := range data.lines {
+		lines = append(lines, wrapToWidth(line, width)...)
+	}
+
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func wrapToWidth(text string, width int) []string {
+	if width <= 0 {
+		return []string{text}
+	}
+	wrapped := lipgloss.NewStyle().MaxWidth(width).Render(text)
+	return strings.Split(wrapped, "\n")
+}
+
+func progressBar(percent float64) string {
+	return colorizePercent(percent, plainProgressBar(percent))
+}
+
+func plainProgressBar(percent float64) string {
+	total := 16
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	filled := int(percent / 100 * float64(total))
+
+	var builder strings.Builder
+	for i := range total {
+		if i < filled {
+			builder.WriteString(barFull)
+		} else {
+			builder.WriteString(barEmpty)
+		}
+	}
+	return builder.String()
+}
+
+func batteryProgressBar(percent float64) string {
+	total := 16
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	filled := int(percent / 100 * float64(total))
+
+	var builder strings.Builder
+	for i := range total {
+		if i < filled {
+			builder.WriteString(barFull)
+		} else {
+			builder.WriteString(barEmpty)
+		}
+	}
+	return colorizeBattery(percent, builder.String())
+}
+
+func colorizePercent(percent float64, s string) string {
+	switch {
+	case percent >= 85:
+		return dangerStyle.Render(s)
+	case percent >= 60:
+		return warnStyle.Render(s)
+	default:
+		return okStyle.Render(s)
+	}
+}
+
+func colorizeBattery(percent float64, s string) string {
+	switch {
+	case percent < 20:
+		return dangerStyle.Render(s)
+	case percent < 50:
+		return warnStyle.Render(s)
+	default:
+		return okStyle.Render(s)
+	}
+}
+
+func colorizeTemp(t float64) string {
+	switch {
+	case t >= *tempDanger:
+		return dangerStyle.Render(fmt.Sprintf("%.1f", t))
+	case t >= *tempWarn:
+		return warnStyle.Render(fmt.Sprintf("%.1f", t))
+	default:
+		return okStyle.Render(fmt.Sprintf("%.1f", t))
+	}
+}
+
+func formatRate(mb float64) string {
+	if mb < 0.01 {
+		return "0 MB/s"
+	}
+	if mb < 1 {
+		return fmt.Sprintf("%.2f MB/s", mb)
+	}
+	if mb < 10 {
+		return fmt.Sprintf("%.1f MB/s", mb)
+	}
+	return fmt.Sprintf("%.0f MB/s", mb)
+}
+
+func formatRateCompact(mb float64) string {
+	if mb < 0.01 {
+		return "0"
+	}
+	if mb < 10 {
+		return fmt.Sprintf("%.1f", mb)
+	}
+	return fmt.Sprintf("%.0f", mb)
+}
+
+// unitSystem is --units; Auto keeps binary sizes with short labels, as
+// Activity Monitor shows them.
+var unitSystem = units.Auto
+
+func humanBytes(v uint64) string {
+	switch unitSystem {
+	case units.SI:
+		return units.BytesSI(int64(min(v, math.MaxInt64)))
+	case units.Binary:
+		return units.BytesIEC(v)
+	default:
+		return units.BytesBin(v)
+	}
+}
+
+func humanBytesShort(v uint64) string {
+	switch unitSystem {
+	case units.SI:
+		return units.BytesSIShort(v)
+	case units.Binary:
+		return units.BytesIECShort(v)
+	default:
+		return units.BytesBinShort(v)
+	}
+}
+
+func humanBytesCompact(v uint64) string {
+	switch unitSystem {
+	case units.SI:
+		return units.BytesSICompact(v)
+	case units.Binary:
+		return units.BytesIECCompact(v)
+	default:
+		return units.BytesBinCompact(v)
+	}
+}
+
+func shorten(s string, maxLen int) string {
+	if lipgloss.Width(s) <= maxLen {
+		return s
+	}
+	return ansi.Truncate(s, maxLen, "…")
+}
+
+// linkPadded left-aligns text in width columns like %-*s and links it to
+// path when the terminal shows hyperlinks, leaving the padding unlinked.
+func linkPadded(path, text string, width int) string {
+	return hyperlink.File(path, text) + strings.Repeat(" ", max(width-lipgloss.Width(text), 0))
+}
+
+// joinFit joins parts with " · ", keeping only whole parts that fit width.
+func joinFit(parts []string, width int) string {
+	out := ""
+	for _, part := range parts {
+		next := part
+		if out != "" {
+			next = out + " · " + part
+		}
+		if lipgloss.Width(next) > width {
+			break
+		}
+		out = next
+	}
+	if out == "" && len(parts) > 0 {
+		return shorten(parts[0], width)
+	}
+	return out
+}
+
+func remainingLineWidth(width int, prefix string) int {
+	if width <= 0 {
+		width = colWidth
+	}
+	return max(width-lipgloss.Width(prefix)-1, 0)
+}
+
+// Values of --compact.
+const (
+	compactAuto = "auto"
+	compactOn   = "on"
+	compactOff  = "off"
+)
+
+// compactWidth is where auto compact mode starts: below it two columns
+// clip every card and one column pushes most cards off screen.
+const compactWidth = 100
+
+func useCompactLayout(mode string, termWidth int) bool {
+	switch mode {
+	case compactOn:
+		return true
+	case compactOff:
+		return false
+	default:
+		return termWidth < compactWidth
+	}
+}
+
+// renderCompactCards stacks one line per card: its title, then its first
+// line, which every card keeps for the headline reading. Lines are clipped
+// rather than wrapped so the list stays one row per panel.
+func renderCompactCards(cards []cardData, width int) string {
+	labelWidth := 0
+	for _, c := range cards {
+		labelWidth = max(labelWidth, lipgloss.Width(i18n.T(c.title)))
+	}
+	labelWidth = min(labelWidth, 12)
+
+	clip := lipgloss.NewStyle().MaxWidth(max(width, colWidth))
+	var rows []string
+	for _, c := range cards {
+		label := lipgloss.NewStyle().Width(labelWidth).Render(shorten(i18n.T(c.title), labelWidth))
+		if cardIcons {
+			label = c.icon + " " + label
+		}
+		label = titleStyle.Render(label)
+		summary := ""
+		if len(c.lines) > 0 {
+			summary = strings.TrimSpace(c.lines[0])
+		}
+		rows = append(rows, clip.Render(strings.TrimRight(label+" "+summary, " ")))
+	}
+	return strings.Join(rows, "\n")
+}
+
+func renderTwoColumns(cards []cardData, width int) string {
+	return renderColumns(cards, width, 2)
+}
+
+// renderColumns lays cards out left to right in rows of n, each row as tall
+// as its tallest card.
+func renderColumns(cards []cardData, width int, n int) string {
+	if len(cards) == 0 {
+		return ""
+	}
+	n = max(n, 1)
+	cw := colWidth
+	if width > 0 && width/n-2 > cw {
+		cw = width/n - 2
+	}
+	var rows []string
+	for i := 0; i < len(cards); i += n {
+		row := cards[i:min(i+n, len(cards))]
+		targetHeight := 0
+		for _, c := range row {
+			targetHeight = max(targetHeight, lipgloss.Height(renderCard(c, cw, 0)))
+		}
+		var rendered []string
+		for j, c := range row {
+			if j > 0 {
+				rendered = append(rendered, "  ")
+			}
+			rendered = append(rendered, renderCard(c, cw, targetHeight))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, rendered...))
+	}
+
+	var spacedRows []string
+	for i, r := range rows {
+		if i > 0 {
+			spacedRows = append(spacedRows, "")
+		}
+		spacedRows = append(spacedRows, r)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, spacedRows...)
+}
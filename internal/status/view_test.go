@@ -0,0 +1,2121 @@
+package status
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/tw93/mole/internal/hyperlink"
+	"github.com/tw93/mole/internal/i18n"
+	"github.com/tw93/mole/internal/units"
+	"github.com/tw93/mole/pkg/sysmetrics"
+)
+
+func TestFormatRate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input float64
+		want  string
+	}{
+		// Below threshold (< 0.01).
+		{"zero", 0, "0 MB/s"},
+		{"tiny", 0.001, "0 MB/s"},
+		{"just under threshold", 0.009, "0 MB/s"},
+
+		// Small rates (0.01 to < 1) — 2 decimal places.
+		{"at threshold", 0.01, "0.01 MB/s"},
+		{"small rate", 0.5, "0.50 MB/s"},
+		{"just under 1", 0.99, "0.99 MB/s"},
+
+		// Medium rates (1 to < 10) — 1 decimal place.
+		{"exactly 1", 1.0, "1.0 MB/s"},
+		{"medium rate", 5.5, "5.5 MB/s"},
+		{"just under 10", 9.9, "9.9 MB/s"},
+
+		// Large rates (>= 10) — no decimal places.
+		{"exactly 10", 10.0, "10 MB/s"},
+		{"large rate", 100.5, "100 MB/s"},
+		{"very large", 1000.0, "1000 MB/s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatRate(tt.input)
+			if got != tt.want {
+				t.Errorf("formatRate(%v) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorizePercent(t *testing.T) {
+	tests := []struct {
+		name         string
+		percent      float64
+		input        string
+		expectDanger bool
+		expectWarn   bool
+		expectOk     bool
+	}{
+		{"low usage", 30.0, "30%", false, false, true},
+		{"just below warn", 59.9, "59.9%", false, false, true},
+		{"at warn threshold", 60.0, "60%", false, true, false},
+		{"mid range", 70.0, "70%", false, true, false},
+		{"just below danger", 84.9, "84.9%", false, true, false},
+		{"at danger threshold", 85.0, "85%", true, false, false},
+		{"high usage", 95.0, "95%", true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := colorizePercent(tt.percent, tt.input)
+
+			if got == "" {
+				t.Errorf("colorizePercent(%v, %q) returned empty string", tt.percent, tt.input)
+				return
+			}
+
+			expected := ""
+			if tt.expectDanger {
+				expected = dangerStyle.Render(tt.input)
+			} else if tt.expectWarn {
+				expected = warnStyle.Render(tt.input)
+			} else if tt.expectOk {
+				expected = okStyle.Render(tt.input)
+			}
+
+			if got != expected {
+				t.Errorf("colorizePercent(%v, %q) = %q, want %q (danger=%v warn=%v ok=%v)",
+					tt.percent, tt.input, got, expected, tt.expectDanger, tt.expectWarn, tt.expectOk)
+			}
+		})
+	}
+}
+
+func TestColorizeBattery(t *testing.T) {
+	tests := []struct {
+		name         string
+		percent      float64
+		input        string
+		expectDanger bool
+		expectWarn   bool
+		expectOk     bool
+	}{
+		{"critical low", 10.0, "10%", true, false, false},
+		{"just below low", 19.9, "19.9%", true, false, false},
+		{"at low threshold", 20.0, "20%", false, true, false},
+		{"mid range", 35.0, "35%", false, true, false},
+		{"just below ok", 49.9, "49.9%", false, true, false},
+		{"at ok threshold", 50.0, "50%", false, false, true},
+		{"healthy", 80.0, "80%", false, false, true},
+		{"full", 100.0, "100%", false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := colorizeBattery(tt.percent, tt.input)
+
+			if got == "" {
+				t.Errorf("colorizeBattery(%v, %q) returned empty string", tt.percent, tt.input)
+				return
+			}
+
+			expected := ""
+			if tt.expectDanger {
+				expected = dangerStyle.Render(tt.input)
+			} else if tt.expectWarn {
+				expected = warnStyle.Render(tt.input)
+			} else if tt.expectOk {
+				expected = okStyle.Render(tt.input)
+			}
+
+			if got != expected {
+				t.Errorf("colorizeBattery(%v, %q) = %q, want %q (danger=%v warn=%v ok=%v)",
+					tt.percent, tt.input, got, expected, tt.expectDanger, tt.expectWarn, tt.expectOk)
+			}
+		})
+	}
+}
+
+func TestShorten(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		maxLen int
+		want   string
+	}{
+		// No truncation needed.
+		{"empty string", "", 10, ""},
+		{"shorter than max", "hello", 10, "hello"},
+		{"exactly at max", "hello", 5, "hello"},
+
+		// Truncation needed.
+		{"one over max", "hello!", 5, "hell…"},
+		{"much longer", "hello world", 5, "hell…"},
+
+		// Edge cases.
+		{"maxLen 1", "hello", 1, "…"},
+		{"maxLen 2", "hello", 2, "h…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shorten(tt.input, tt.maxLen)
+			if got != tt.want {
+				t.Errorf("shorten(%q, %d) = %q, want %q", tt.input, tt.maxLen, got, tt.want)
+			}
+		})
+	}
+}
+
+// Core byte-format coverage lives in internal/units; these are wiring sanity
+// checks to ensure the status helpers still delegate to that package.
+func TestHumanBytesShort(t *testing.T) {
+	if got := humanBytesShort(100 << 30); got != "100G" {
+		t.Errorf("humanBytesShort(100<<30) = %q, want %q", got, "100G")
+	}
+}
+
+func TestHumanBytes(t *testing.T) {
+	if got := humanBytes((1 << 20) + 1); got != "1.0 MB" {
+		t.Errorf("humanBytes(1MB+1) = %q, want %q", got, "1.0 MB")
+	}
+}
+
+func TestHumanBytesCompact(t *testing.T) {
+	if got := humanBytesCompact(1536); got != "1.5K" {
+		t.Errorf("humanBytesCompact(1536) = %q, want %q", got, "1.5K")
+	}
+}
+
+func TestSplitDisks(t *testing.T) {
+	tests := []struct {
+		name         string
+		disks        []sysmetrics.DiskStatus
+		wantInternal int
+		wantExternal int
+	}{
+		{
+			name:         "empty slice",
+			disks:        []sysmetrics.DiskStatus{},
+			wantInternal: 0,
+			wantExternal: 0,
+		},
+		{
+			name: "all internal",
+			disks: []sysmetrics.DiskStatus{
+				{Mount: "/", External: false},
+				{Mount: "/System", External: false},
+			},
+			wantInternal: 2,
+			wantExternal: 0,
+		},
+		{
+			name: "all external",
+			disks: []sysmetrics.DiskStatus{
+				{Mount: "/Volumes/USB", External: true},
+				{Mount: "/Volumes/Backup", External: true},
+			},
+			wantInternal: 0,
+			wantExternal: 2,
+		},
+		{
+			name: "mixed",
+			disks: []sysmetrics.DiskStatus{
+				{Mount: "/", External: false},
+				{Mount: "/Volumes/USB", External: true},
+				{Mount: "/System", External: false},
+			},
+			wantInternal: 2,
+			wantExternal: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			internal, external := splitDisks(tt.disks)
+			if len(internal) != tt.wantInternal {
+				t.Errorf("splitDisks() internal count = %d, want %d", len(internal), tt.wantInternal)
+			}
+			if len(external) != tt.wantExternal {
+				t.Errorf("splitDisks() external count = %d, want %d", len(external), tt.wantExternal)
+			}
+		})
+	}
+}
+
+func TestDiskLabel(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		index  int
+		total  int
+		want   string
+	}{
+		// Single disk — no numbering.
+		{"single disk", "INTR", 0, 1, "INTR"},
+		{"single external", "EXTR", 0, 1, "EXTR"},
+
+		// Multiple disks — numbered (1-indexed).
+		{"first of two", "INTR", 0, 2, "INTR1"},
+		{"second of two", "INTR", 1, 2, "INTR2"},
+		{"third of three", "EXTR", 2, 3, "EXTR3"},
+
+		// Edge case: total 0 treated as single.
+		{"total zero", "DISK", 0, 0, "DISK"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diskLabel(tt.prefix, tt.index, tt.total)
+			if got != tt.want {
+				t.Errorf("diskLabel(%q, %d, %d) = %q, want %q", tt.prefix, tt.index, tt.total, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		name     string
+		percent  float64
+		wantRune int
+	}{
+		{"zero percent", 0, 16},
+		{"negative clamped", -10, 16},
+		{"low percent", 25, 16},
+		{"half", 50, 16},
+		{"high percent", 75, 16},
+		{"full", 100, 16},
+		{"over 100 clamped", 150, 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := progressBar(tt.percent)
+			if len(got) == 0 {
+				t.Errorf("progressBar(%v) returned empty string", tt.percent)
+				return
+			}
+			gotClean := stripANSI(got)
+			gotRuneCount := len([]rune(gotClean))
+			if gotRuneCount != tt.wantRune {
+				t.Errorf("progressBar(%v) rune count = %d, want %d", tt.percent, gotRuneCount, tt.wantRune)
+			}
+		})
+	}
+}
+
+func TestBatteryProgressBar(t *testing.T) {
+	tests := []struct {
+		name     string
+		percent  float64
+		wantRune int
+	}{
+		{"zero percent", 0, 16},
+		{"negative clamped", -10, 16},
+		{"critical low", 15, 16},
+		{"low", 25, 16},
+		{"medium", 50, 16},
+		{"high", 75, 16},
+		{"full", 100, 16},
+		{"over 100 clamped", 120, 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := batteryProgressBar(tt.percent)
+			if len(got) == 0 {
+				t.Errorf("batteryProgressBar(%v) returned empty string", tt.percent)
+				return
+			}
+			gotClean := stripANSI(got)
+			gotRuneCount := len([]rune(gotClean))
+			if gotRuneCount != tt.wantRune {
+				t.Errorf("batteryProgressBar(%v) rune count = %d, want %d", tt.percent, gotRuneCount, tt.wantRune)
+			}
+		})
+	}
+}
+
+func TestRenderBatteryCardShowsAdapterInputOnly(t *testing.T) {
+	card := renderBatteryCard([]sysmetrics.BatteryStatus{{
+		Percent:    80,
+		Status:     "AC",
+		Capacity:   100,
+		CycleCount: 4,
+	}}, sysmetrics.ThermalStatus{
+		BatteryTemp:  30.7,
+		AdapterPower: 94,
+	})
+
+	var joined []string
+	for _, line := range card.lines {
+		joined = append(joined, stripANSI(line))
+	}
+	got := strings.Join(joined, "\n")
+
+	if len(card.lines) != 4 {
+		t.Fatalf("expected compact 4-line power card, got %d lines:\n%s", len(card.lines), got)
+	}
+	if !strings.Contains(got, "Input") || !strings.Contains(got, "94W max") {
+		t.Fatalf("expected input line with adapter max watts, got:\n%s", got)
+	}
+	if strings.Contains(got, "Draw") || strings.Contains(got, "Charge") {
+		t.Fatalf("expected no live draw or charge watt row, got:\n%s", got)
+	}
+	if strings.Contains(got, "94W adapter") {
+		t.Fatalf("expected adapter watts only on the Input line, got:\n%s", got)
+	}
+	if !strings.Contains(got, "AC · Healthy · 4 cycles · 30.7°C") {
+		t.Fatalf("expected compact AC health summary, got:\n%s", got)
+	}
+	if strings.Contains(got, "Battery 30.7°C") {
+		t.Fatalf("expected compact temperature without Battery prefix, got:\n%s", got)
+	}
+	if strings.Contains(got, "Ac") {
+		t.Fatalf("expected AC to stay uppercase, got:\n%s", got)
+	}
+	if strings.Contains(got, "⚡") {
+		t.Fatalf("expected no charging glyph, got:\n%s", got)
+	}
+}
+
+func TestColorizeTemp(t *testing.T) {
+	tests := []struct {
+		name string
+		temp float64
+	}{
+		{"very low", 20.0},
+		{"low", 40.0},
+		{"normal range", 55.0},
+		{"at warn threshold", 65.0},
+		{"warn range", 75.0},
+		{"just below danger", 84.9},
+		{"at danger threshold", 85.0},
+		{"high", 92.0},
+		{"very high", 95.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := colorizeTemp(tt.temp)
+			if got == "" {
+				t.Errorf("colorizeTemp(%v) returned empty string", tt.temp)
+			}
+		})
+	}
+}
+
+func TestMiniBar(t *testing.T) {
+	tests := []struct {
+		name    string
+		percent float64
+	}{
+		{"zero", 0},
+		{"negative", -5},
+		{"low", 15},
+		{"at first step", 20},
+		{"mid", 50},
+		{"high", 75},
+		{"full", 100},
+		{"over 100", 120},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := miniBar(tt.percent)
+			if got == "" {
+				t.Errorf("miniBar(%v) returned empty string", tt.percent)
+				return
+			}
+			gotClean := stripANSI(got)
+			gotRuneCount := len([]rune(gotClean))
+			if gotRuneCount != 5 {
+				t.Errorf("miniBar(%v) rune count = %d, want 5", tt.percent, gotRuneCount)
+			}
+		})
+	}
+}
+
+func TestIoBar(t *testing.T) {
+	tests := []struct {
+		name string
+		rate float64
+	}{
+		{"zero", 0},
+		{"very low", 5},
+		{"low normal", 20},
+		{"at warn threshold", 30},
+		{"warn range", 50},
+		{"just below danger", 79},
+		{"at danger threshold", 80},
+		{"high", 100},
+		{"very high", 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ioBar(tt.rate)
+			if got == "" {
+				t.Errorf("ioBar(%v) returned empty string", tt.rate)
+				return
+			}
+			gotClean := stripANSI(got)
+			gotRuneCount := len([]rune(gotClean))
+			if gotRuneCount != 5 {
+				t.Errorf("ioBar(%v) rune count = %d, want 5", tt.rate, gotRuneCount)
+			}
+		})
+	}
+}
+
+func TestFormatDiskLine(t *testing.T) {
+	tests := []struct {
+		name         string
+		label        string
+		disk         sysmetrics.DiskStatus
+		wantUsed     string
+		wantFree     string
+		wantNoSubstr string
+	}{
+		{
+			name:         "empty label defaults to DISK",
+			label:        "",
+			disk:         sysmetrics.DiskStatus{UsedPercent: 50.5, Used: 100 << 30, Total: 200 << 30},
+			wantUsed:     "100G used",
+			wantFree:     "100G free",
+			wantNoSubstr: "%",
+		},
+		{
+			name:         "internal disk",
+			label:        "INTR",
+			disk:         sysmetrics.DiskStatus{UsedPercent: 67.2, Used: 336 << 30, Total: 500 << 30},
+			wantUsed:     "336G used",
+			wantFree:     "164G free",
+			wantNoSubstr: "%",
+		},
+		{
+			name:         "external disk",
+			label:        "EXTR1",
+			disk:         sysmetrics.DiskStatus{UsedPercent: 85.0, Used: 850 << 30, Total: 1000 << 30},
+			wantUsed:     "850G used",
+			wantFree:     "150G free",
+			wantNoSubstr: "%",
+		},
+		{
+			name:         "low usage",
+			label:        "INTR",
+			disk:         sysmetrics.DiskStatus{UsedPercent: 15.3, Used: 15 << 30, Total: 100 << 30},
+			wantUsed:     "15G used",
+			wantFree:     "85G free",
+			wantNoSubstr: "%",
+		},
+		{
+			name:         "used exceeds total clamps free to zero",
+			label:        "INTR",
+			disk:         sysmetrics.DiskStatus{UsedPercent: 110.0, Used: 110 << 30, Total: 100 << 30},
+			wantUsed:     "110G used",
+			wantFree:     "0 free",
+			wantNoSubstr: "%",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatDiskLine(tt.label, tt.disk)
+			if got == "" {
+				t.Errorf("formatDiskLine(%q, ...) returned empty string", tt.label)
+				return
+			}
+			expectedLabel := tt.label
+			if expectedLabel == "" {
+				expectedLabel = "DISK"
+			}
+			if !strings.Contains(got, expectedLabel) {
+				t.Errorf("formatDiskLine(%q, ...) = %q, should contain label %q", tt.label, got, expectedLabel)
+			}
+			if !strings.Contains(got, tt.wantUsed) {
+				t.Errorf("formatDiskLine(%q, ...) = %q, should contain used value %q", tt.label, got, tt.wantUsed)
+			}
+			if !strings.Contains(got, tt.wantFree) {
+				t.Errorf("formatDiskLine(%q, ...) = %q, should contain free value %q", tt.label, got, tt.wantFree)
+			}
+			if tt.wantNoSubstr != "" && strings.Contains(got, tt.wantNoSubstr) {
+				t.Errorf("formatDiskLine(%q, ...) = %q, should not contain %q", tt.label, got, tt.wantNoSubstr)
+			}
+		})
+	}
+}
+
+func TestFormatDiskLineLinksMountWithoutShiftingColumns(t *testing.T) {
+	disk := sysmetrics.DiskStatus{Mount: "/Volumes/Backup", Used: 100 << 30, Total: 500 << 30, UsedPercent: 20}
+	plain := formatDiskLine("EXTR", disk)
+
+	hyperlink.Enable(true)
+	defer hyperlink.Enable(false)
+	linked := formatDiskLine("EXTR", disk)
+
+	if !strings.Contains(linked, "\033]8;;file://") || !strings.Contains(linked, "/Volumes/Backup") {
+		t.Fatalf("disk line should link the mount point, got %q", linked)
+	}
+	if lipgloss.Width(linked) != lipgloss.Width(plain) {
+		t.Fatalf("link changed the line width: %d, want %d", lipgloss.Width(linked), lipgloss.Width(plain))
+	}
+}
+
+func TestRenderDiskCardAddsMetaLineForSingleDisk(t *testing.T) {
+	card := renderDiskCard([]sysmetrics.DiskStatus{{
+		UsedPercent: 28.4,
+		Used:        263 << 30,
+		Total:       926 << 30,
+		Fstype:      "apfs",
+	}}, sysmetrics.DiskIOStatus{ReadRate: 0, WriteRate: 0.1}, 0, false)
+
+	if len(card.lines) != 3 {
+		t.Fatalf("renderDiskCard() single disk expected 3 lines, got %d", len(card.lines))
+	}
+
+	meta := stripANSI(card.lines[1])
+	if meta != "Total  926G · APFS" {
+		t.Fatalf("renderDiskCard() single disk meta line = %q, want %q", meta, "Total  926G · APFS")
+	}
+}
+
+func TestRenderDiskCardDoesNotAddMetaLineForMultipleDisks(t *testing.T) {
+	card := renderDiskCard([]sysmetrics.DiskStatus{
+		{UsedPercent: 28.4, Used: 263 << 30, Total: 926 << 30, Fstype: "apfs"},
+		{UsedPercent: 50.0, Used: 500 << 30, Total: 1000 << 30, Fstype: "apfs"},
+	}, sysmetrics.DiskIOStatus{}, 0, false)
+
+	if len(card.lines) != 3 {
+		t.Fatalf("renderDiskCard() multiple disks expected 3 lines, got %d", len(card.lines))
+	}
+
+	for _, line := range card.lines {
+		if stripANSI(line) == "Total  926G · APFS" || stripANSI(line) == "Total  1000G · APFS" {
+			t.Fatalf("renderDiskCard() multiple disks should not add meta line, got %q", line)
+		}
+	}
+}
+
+func TestRenderDiskCardOmitsTrashFromMainView(t *testing.T) {
+	disk := sysmetrics.DiskStatus{UsedPercent: 50, Used: 500 << 30, Total: 1000 << 30, Fstype: "apfs"}
+	tests := []struct {
+		name      string
+		trashSize uint64
+		approx    bool
+	}{
+		{"no trash", 0, false},
+		{"1.5 GB exact", 1536 << 20, false},
+		{"approx 12 GB", 12 << 30, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := renderDiskCard([]sysmetrics.DiskStatus{disk}, sysmetrics.DiskIOStatus{}, tt.trashSize, tt.approx)
+			ioLine := ""
+			trashLine := ""
+			for _, line := range card.lines {
+				s := stripANSI(line)
+				if strings.HasPrefix(s, "I/O") {
+					ioLine = s
+				}
+				if strings.HasPrefix(s, "Trash") {
+					trashLine = s
+				}
+			}
+			if ioLine == "" {
+				t.Fatal("expected I/O line")
+			}
+			if trashLine != "" {
+				t.Fatalf("expected no trash line in main view, got %q", trashLine)
+			}
+		})
+	}
+}
+
+func TestRenderDiskCardUsesGraphicIOLine(t *testing.T) {
+	card := renderDiskCard([]sysmetrics.DiskStatus{
+		{UsedPercent: 50.0, Used: 500 << 30, Total: 1000 << 30},
+		{UsedPercent: 95.0, Used: 18 << 30, Total: 18<<30 + 472<<20, External: true},
+		{UsedPercent: 95.0, Used: 16 << 30, Total: 16<<30 + 444<<20, External: true},
+	}, sysmetrics.DiskIOStatus{ReadRate: 0, WriteRate: 24.6}, 101<<20, false)
+
+	if len(card.lines) != 4 {
+		t.Fatalf("renderDiskCard() expected 4 lines without trash, got %d", len(card.lines))
+	}
+	if got := stripANSI(card.lines[3]); got != "I/O    ▯▯▯▯▯ R 0 · ▮▮▯▯▯ W 25 MB/s" {
+		t.Fatalf("I/O line = %q", got)
+	}
+}
+
+func TestGetScoreStyle(t *testing.T) {
+	tests := []struct {
+		name  string
+		score int
+	}{
+		{"critical low", 10},
+		{"poor low", 25},
+		{"just below fair", 39},
+		{"at fair threshold", 40},
+		{"fair range", 50},
+		{"just below good", 59},
+		{"at good threshold", 60},
+		{"good range", 70},
+		{"just below excellent", 74},
+		{"at excellent threshold", 75},
+		{"excellent range", 85},
+		{"just below perfect", 89},
+		{"perfect", 90},
+		{"max", 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			style := getScoreStyle(tt.score)
+			if style.GetForeground() == nil {
+				t.Errorf("getScoreStyle(%d) returned style with no foreground color", tt.score)
+			}
+		})
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []float64
+		current float64
+		width   int
+		wantLen int
+	}{
+		{
+			name:    "empty history",
+			history: []float64{},
+			current: 1.5,
+			width:   10,
+			wantLen: 10,
+		},
+		{
+			name:    "short history padded",
+			history: []float64{1.0, 2.0, 3.0},
+			current: 3.0,
+			width:   10,
+			wantLen: 10,
+		},
+		{
+			name:    "exact width",
+			history: []float64{1.0, 2.0, 3.0, 4.0, 5.0},
+			current: 5.0,
+			width:   5,
+			wantLen: 5,
+		},
+		{
+			name:    "history longer than width",
+			history: []float64{1.0, 2.0, 3.0, 4.0, 5.0, 6.0, 7.0, 8.0, 9.0, 10.0},
+			current: 10.0,
+			width:   5,
+			wantLen: 5,
+		},
+		{
+			name:    "low current value ok style",
+			history: []float64{1.0, 1.5, 2.0},
+			current: 2.0,
+			width:   5,
+			wantLen: 5,
+		},
+		{
+			name:    "medium current value warn style",
+			history: []float64{3.0, 4.0, 5.0},
+			current: 5.0,
+			width:   5,
+			wantLen: 5,
+		},
+		{
+			name:    "high current value danger style",
+			history: []float64{8.0, 9.0, 10.0},
+			current: 10.0,
+			width:   5,
+			wantLen: 5,
+		},
+		{
+			name:    "all identical values flatline",
+			history: []float64{5.0, 5.0, 5.0, 5.0, 5.0},
+			current: 5.0,
+			width:   5,
+			wantLen: 5,
+		},
+		{
+			name:    "zero width edge case",
+			history: []float64{1.0, 2.0, 3.0},
+			current: 2.0,
+			width:   0,
+			wantLen: 0,
+		},
+		{
+			name:    "width of 1",
+			history: []float64{1.0, 2.0, 3.0},
+			current: 2.0,
+			width:   1,
+			wantLen: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sparkline(tt.history, tt.current, tt.width)
+			if tt.width == 0 {
+				return
+			}
+			if got == "" {
+				t.Errorf("sparkline() returned empty string")
+				return
+			}
+			gotClean := stripANSI(got)
+			if len([]rune(gotClean)) != tt.wantLen {
+				t.Errorf("sparkline() rune length = %d, want %d", len([]rune(gotClean)), tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestRenderHeaderErrorReturnsMoleOnce(t *testing.T) {
+	header, mole := renderHeader(sysmetrics.MetricsSnapshot{}, "boom", 0, 120, false)
+
+	if mole != "" {
+		t.Fatalf("renderHeader() mole return should be empty on error to avoid duplicate render, got %q", mole)
+	}
+	if !strings.Contains(header, "ERROR: boom") {
+		t.Fatalf("renderHeader() missing error text, got %q", header)
+	}
+	if strings.Count(header, "/\\_/\\") != 1 {
+		t.Fatalf("renderHeader() should contain one mole frame in error state, got %d", strings.Count(header, "/\\_/\\"))
+	}
+}
+
+func TestStatusDiagnosisLineUsesTopCPUProcess(t *testing.T) {
+	m := sysmetrics.MetricsSnapshot{
+		CPU: sysmetrics.CPUStatus{Usage: 95},
+		TopProcesses: []sysmetrics.ProcessInfo{
+			{Name: "Safari", CPU: 12},
+			{Name: "Xcode", CPU: 82},
+		},
+	}
+
+	got := statusDiagnosisLine(m)
+	if got != "Xcode high CPU" {
+		t.Fatalf("statusDiagnosisLine() = %q, want top CPU process", got)
+	}
+}
+
+func TestStatusDiagnosisLineUsesMemoryContributorWhenCPUIsCalm(t *testing.T) {
+	m := sysmetrics.MetricsSnapshot{
+		CPU: sysmetrics.CPUStatus{Usage: 20},
+		Memory: sysmetrics.MemoryStatus{
+			UsedPercent: 86,
+			Pressure:    "warn",
+		},
+		TopProcesses: []sysmetrics.ProcessInfo{
+			{Name: "Chrome", Memory: 31},
+			{Name: "Finder", Memory: 2},
+		},
+	}
+
+	got := statusDiagnosisLine(m)
+	if got != "Chrome memory pressure" {
+		t.Fatalf("statusDiagnosisLine() = %q, want memory contributor", got)
+	}
+}
+
+func TestStatusDiagnosisLineFallsBackToAllClear(t *testing.T) {
+	m := sysmetrics.MetricsSnapshot{
+		CPU:            sysmetrics.CPUStatus{Usage: 10},
+		Memory:         sysmetrics.MemoryStatus{UsedPercent: 20, Pressure: "normal"},
+		HealthScoreMsg: "Excellent",
+	}
+
+	got := statusDiagnosisLine(m)
+	if got != "All clear" {
+		t.Fatalf("statusDiagnosisLine() = %q, want All clear", got)
+	}
+}
+
+func TestRenderProcessCardAddsInlineMemoryWithoutExtraRows(t *testing.T) {
+	card := renderProcessCard([]sysmetrics.ProcessInfo{
+		{Name: "Chrome", CPU: 12, Memory: 22, MemoryBytes: 2 * 1024 * 1024 * 1024},
+		{Name: "Xcode", CPU: 95, Memory: 8, MemoryBytes: 512 * 1024 * 1024},
+	}, colWidth, sysmetrics.ProcessSortCPU)
+
+	if len(card.lines) != 2 {
+		t.Fatalf("renderProcessCard() lines = %d, want 2", len(card.lines))
+	}
+	plain := stripANSI(strings.Join(card.lines, "\n"))
+	if !strings.Contains(plain, "2.0G") {
+		t.Fatalf("renderProcessCard() missing resident memory hint, got %q", plain)
+	}
+	if !strings.Contains(plain, "95.0%") {
+		t.Fatalf("renderProcessCard() missing cpu value, got %q", plain)
+	}
+}
+
+func TestRenderProcessCardShowsCollectingWhenEmpty(t *testing.T) {
+	card := renderProcessCard(nil, colWidth, sysmetrics.ProcessSortCPU)
+
+	if len(card.lines) != 1 {
+		t.Fatalf("renderProcessCard() empty lines = %d, want 1", len(card.lines))
+	}
+	if got := stripANSI(card.lines[0]); got != "Collecting..." {
+		t.Fatalf("renderProcessCard() empty line = %q", got)
+	}
+}
+
+func TestRenderProcessCardAlignsMetricColumns(t *testing.T) {
+	const wideCardWidth = 56
+	card := renderProcessCard([]sysmetrics.ProcessInfo{
+		{Name: "duetexpertd", CPU: 97.3, MemoryBytes: 75 << 20},
+		{Name: "WindowServer", CPU: 46.8, MemoryBytes: 352 << 20},
+		{Name: "Xcode", CPU: 24.3, MemoryBytes: 1018 << 20},
+	}, wideCardWidth, sysmetrics.ProcessSortCPU)
+
+	if len(card.lines) != 3 {
+		t.Fatalf("renderProcessCard() lines = %d, want 3", len(card.lines))
+	}
+	lines := make([]string, 0, len(card.lines))
+	for _, line := range card.lines {
+		lines = append(lines, stripANSI(line))
+	}
+
+	barText := []string{
+		"███████████████░",
+		"███████░░░░░░░░░",
+		"███░░░░░░░░░░░░░",
+	}
+	memoryText := []string{"75.0M", "352.0M", "1018.0M"}
+	barCol := strings.Index(lines[0], barText[0])
+	if barCol != metricLabelWidth+1 {
+		t.Fatalf("process bar column = %d, want %d in %q", barCol, metricLabelWidth+1, lines[0])
+	}
+	afterBar := lines[0][barCol+len(barText[0]):]
+	if !strings.HasPrefix(afterBar, "  97.3%") {
+		t.Fatalf("process percent should sit one column closer to the bar, got %q", lines[0])
+	}
+	percentCol := strings.Index(lines[0], "%")
+	memoryEndCol := strings.Index(lines[0], memoryText[0]) + len(memoryText[0])
+	for i, line := range lines {
+		if got := strings.Index(line, barText[i]); got != barCol {
+			t.Fatalf("process bar column line %d = %d, want %d in %q", i, got, barCol, line)
+		}
+		if got := strings.Index(line, "%"); got != percentCol {
+			t.Fatalf("process percent column line %d = %d, want %d in %q", i, got, percentCol, line)
+		}
+		gotMemoryEnd := strings.Index(line, memoryText[i]) + len(memoryText[i])
+		if gotMemoryEnd != memoryEndCol {
+			t.Fatalf("process memory column line %d ends at %d, want %d in %q", i, gotMemoryEnd, memoryEndCol, line)
+		}
+	}
+	if lipgloss.Width(lines[0]) > wideCardWidth {
+		t.Fatalf("renderProcessCard() line exceeds card width: %q", lines[0])
+	}
+	if !strings.Contains(lines[0], "duet") {
+		t.Fatalf("renderProcessCard() should keep process name after metrics, got %q", lines[0])
+	}
+}
+
+func TestRenderProcessCardFallsBackToMemoryPercent(t *testing.T) {
+	card := renderProcessCard([]sysmetrics.ProcessInfo{
+		{Name: "Chrome", CPU: 12, Memory: 22},
+	}, colWidth, sysmetrics.ProcessSortCPU)
+
+	plain := stripANSI(strings.Join(card.lines, "\n"))
+	if !strings.Contains(plain, "M22%") {
+		t.Fatalf("renderProcessCard() missing memory percent fallback, got %q", plain)
+	}
+}
+
+func TestRenderHeaderUsesFastMetricSpecFallbacks(t *testing.T) {
+	const ram = uint64(16 * 1024 * 1024 * 1024)
+	const diskSize = uint64(512 * 1024 * 1024 * 1024)
+	m := sysmetrics.MetricsSnapshot{
+		HealthScore: 90,
+		Memory:      sysmetrics.MemoryStatus{Total: ram},
+		Disks:       []sysmetrics.DiskStatus{{Mount: "/", Total: diskSize}},
+	}
+
+	header, _ := renderHeader(m, "", 0, 120, true)
+	plain := stripANSI(header)
+	wantRAM := "RAM " + humanBytes(ram)
+	wantDisk := "Disk " + humanBytes(diskSize)
+	if !strings.Contains(plain, wantRAM) || !strings.Contains(plain, wantDisk) {
+		t.Fatalf("renderHeader() should label fast metric specs %q and %q, got %q", wantRAM, wantDisk, plain)
+	}
+	if strings.Contains(plain, humanBytes(ram)+"/"+humanBytes(diskSize)) {
+		t.Fatalf("renderHeader() should not render RAM and disk as a slash pair, got %q", plain)
+	}
+}
+
+func TestRenderHeaderWrapsOnNarrowWidth(t *testing.T) {
+	m := sysmetrics.MetricsSnapshot{
+		HealthScore: 91,
+		Hardware: sysmetrics.HardwareInfo{
+			Model:       "MacBook Pro",
+			CPUModel:    "Apple M3 Max",
+			TotalRAM:    "128GB",
+			DiskSize:    "4TB",
+			RefreshRate: "120Hz",
+			OSVersion:   "macOS 15.0",
+		},
+		Uptime: "10d 3h",
+	}
+
+	header, _ := renderHeader(m, "", 0, 38, true)
+	for line := range strings.Lines(header) {
+		if lipgloss.Width(stripANSI(line)) > 38 {
+			t.Fatalf("renderHeader() line exceeds width: %q", line)
+		}
+	}
+}
+
+func TestRenderHeaderHidesOSAndUptimeOnNarrowWidth(t *testing.T) {
+	m := sysmetrics.MetricsSnapshot{
+		HealthScore: 91,
+		Hardware: sysmetrics.HardwareInfo{
+			Model:       "MacBook Pro",
+			CPUModel:    "Apple M3 Max",
+			TotalRAM:    "128GB",
+			DiskSize:    "4TB",
+			RefreshRate: "120Hz",
+			OSVersion:   "macOS 15.0",
+		},
+		Uptime: "10d 3h",
+	}
+
+	header, _ := renderHeader(m, "", 0, 80, true)
+	plain := stripANSI(header)
+	if strings.Contains(plain, "macOS 15.0") {
+		t.Fatalf("renderHeader() narrow width should hide os version, got %q", plain)
+	}
+	if strings.Contains(plain, "up 10d 3h") {
+		t.Fatalf("renderHeader() narrow width should hide uptime, got %q", plain)
+	}
+}
+
+func TestRenderHeaderKeepsLabeledSpecsOnCompactWidth(t *testing.T) {
+	m := sysmetrics.MetricsSnapshot{
+		HealthScore: 91,
+		Hardware: sysmetrics.HardwareInfo{
+			Model:       "MacBook Pro",
+			CPUModel:    "Apple M4 Pro",
+			TotalRAM:    "48G",
+			DiskSize:    "926GB",
+			RefreshRate: "120Hz",
+		},
+		GPU: []sysmetrics.GPUStatus{{CoreCount: 20}},
+	}
+
+	header, _ := renderHeader(m, "", 0, 80, true)
+	plain := stripANSI(header)
+	if !strings.Contains(plain, "RAM 48G") || !strings.Contains(plain, "Disk 926GB") {
+		t.Fatalf("renderHeader() compact width should keep labeled specs, got %q", plain)
+	}
+	if strings.Contains(plain, "48G/926GB") {
+		t.Fatalf("renderHeader() compact width should not use slash specs, got %q", plain)
+	}
+	for line := range strings.Lines(header) {
+		if lipgloss.Width(stripANSI(line)) > 80 {
+			t.Fatalf("renderHeader() compact line exceeds width: %q", line)
+		}
+	}
+}
+
+func TestRenderHeaderDropsLowPriorityInfoToStaySingleLine(t *testing.T) {
+	m := sysmetrics.MetricsSnapshot{
+		HealthScore: 90,
+		Hardware: sysmetrics.HardwareInfo{
+			Model:       "MacBook Pro",
+			CPUModel:    "Apple M2 Pro",
+			TotalRAM:    "32.0 GB",
+			DiskSize:    "460.4 GB",
+			RefreshRate: "60Hz",
+			OSVersion:   "macOS 26.3",
+		},
+		GPU:    []sysmetrics.GPUStatus{{CoreCount: 19}},
+		Uptime: "9d 13h",
+	}
+
+	header, _ := renderHeader(m, "", 0, 100, true)
+	plain := stripANSI(header)
+	if strings.Contains(plain, "\n") {
+		t.Fatalf("renderHeader() should stay single line when trimming low-priority fields, got %q", plain)
+	}
+	if strings.Contains(plain, "macOS 26.3") {
+		t.Fatalf("renderHeader() should drop os version when width is tight, got %q", plain)
+	}
+	if strings.Contains(plain, "up 9d 13h") {
+		t.Fatalf("renderHeader() should drop uptime when width is tight, got %q", plain)
+	}
+}
+
+func TestRenderCardWrapsOnNarrowWidth(t *testing.T) {
+	card := cardData{
+		icon:  iconCPU,
+		title: "CPU",
+		lines: []string{
+			"Total  ████████████████  100.0% @ 85.0°C",
+			"Load   12.34 / 8.90 / 7.65, 4P+4E",
+		},
+	}
+
+	rendered := renderCard(card, 26, 0)
+	for line := range strings.Lines(rendered) {
+		if lipgloss.Width(stripANSI(line)) > 26 {
+			t.Fatalf("renderCard() line exceeds width: %q", line)
+		}
+	}
+}
+
+func TestRenderCPUCardShowsCoreGridAndHottestCore(t *testing.T) {
+	card := renderCPUCard(sysmetrics.CPUStatus{
+		Usage:      6.1,
+		PerCore:    []float64{8.0, 27.9, 18.9, 16.8},
+		Load1:      2.30,
+		Load5:      2.27,
+		Load15:     2.16,
+		LogicalCPU: 4,
+	}, sysmetrics.ThermalStatus{}, sysmetrics.PowerStatus{}, sysmetrics.ANEStatus{})
+
+	plain := stripANSI(strings.Join(card.lines, "\n"))
+	if len(card.lines) != 4 {
+		t.Fatalf("renderCPUCard() lines = %d, want 4", len(card.lines))
+	}
+	if !strings.Contains(plain, "Cores  ▁▂▂▂") {
+		t.Fatalf("renderCPUCard() should render one glyph per core, got %q", plain)
+	}
+	if strings.Count(plain, "Core2 ") != 1 || strings.Contains(plain, "Core3 ") {
+		t.Fatalf("renderCPUCard() should keep only the hottest core row, got %q", plain)
+	}
+}
+
+func TestRenderCoreGridWrapsHighCoreCounts(t *testing.T) {
+	perCore := make([]float64, 20)
+	perCore[19] = 100
+
+	rows := renderCoreGrid(perCore)
+	if len(rows) != 2 {
+		t.Fatalf("renderCoreGrid() rows = %d, want 2", len(rows))
+	}
+	first, second := stripANSI(rows[0]), stripANSI(rows[1])
+	if first != "Cores  "+strings.Repeat("▁", coreGridWidth) {
+		t.Fatalf("first row = %q", first)
+	}
+	if second != "       ▁▁▁█" {
+		t.Fatalf("second row = %q", second)
+	}
+}
+
+func TestRenderTwoColumnsInsertsRowGap(t *testing.T) {
+	cards := []cardData{
+		{icon: iconCPU, title: "CPU", lines: []string{"Total  ok"}},
+		{icon: iconMemory, title: "Memory", lines: []string{"Used   ok"}},
+		{icon: iconDisk, title: "Disk", lines: []string{"INTR   ok"}},
+		{icon: iconNetwork, title: "Network", lines: []string{"Down   ok"}},
+	}
+
+	rendered := stripANSI(renderTwoColumns(cards, 120))
+	hasBlankRow := false
+	for line := range strings.Lines(rendered) {
+		if strings.TrimSpace(line) == "" {
+			hasBlankRow = true
+			break
+		}
+	}
+	if !hasBlankRow {
+		t.Fatalf("renderTwoColumns() should insert one blank row between card rows, got %q", rendered)
+	}
+}
+
+func TestCardTitlesTranslateButPanelIDsStayEnglish(t *testing.T) {
+	i18n.Set("zh")
+	defer i18n.Set(i18n.English)
+
+	cards := buildCards(sysmetrics.MetricsSnapshot{
+		Disks: []sysmetrics.DiskStatus{{Mount: "/", Used: 100 << 30, Total: 500 << 30, UsedPercent: 20}},
+	}, 78)
+	var disk cardData
+	for _, c := range cards {
+		if panelID(c.title) == "disk" {
+			disk = c
+		}
+	}
+	if disk.title == "" {
+		t.Fatal("no disk card; panel IDs should not follow the language")
+	}
+	if got := stripANSI(renderCard(disk, 40, 0)); !strings.Contains(got, "磁盘") {
+		t.Fatalf("card title not translated:\n%s", got)
+	}
+
+	columns := map[int]bool{}
+	for i, line := range strings.Split(stripANSI(renderCompactCards(cards, 200)), "\n") {
+		summary := strings.TrimSpace(stripANSI(cards[i].lines[0]))
+		if at := strings.Index(line, summary); at >= 0 {
+			columns[lipgloss.Width(line[:at])] = true
+		}
+	}
+	if len(columns) != 1 {
+		t.Fatalf("compact summaries should start in one column in any language, got %v", columns)
+	}
+}
+
+func TestRenderCompactCardsOneLinePerPanel(t *testing.T) {
+	cards := buildCards(sysmetrics.MetricsSnapshot{
+		CPU:    sysmetrics.CPUStatus{Usage: 42, LogicalCPU: 8, PerCore: []float64{40, 44}},
+		Memory: sysmetrics.MemoryStatus{Used: 8 << 30, Total: 16 << 30, UsedPercent: 50},
+		TopProcesses: []sysmetrics.ProcessInfo{
+			{Name: "a-process-with-a-very-long-name-that-would-wrap", CPU: 12, Memory: 3},
+		},
+	}, 78)
+	rendered := stripANSI(renderCompactCards(cards, 60))
+
+	lines := strings.Split(rendered, "\n")
+	if len(lines) != len(cards) {
+		t.Fatalf("renderCompactCards() = %d lines for %d cards:\n%s", len(lines), len(cards), rendered)
+	}
+	for _, line := range lines {
+		if lipgloss.Width(line) > 60 {
+			t.Fatalf("line wider than the terminal: %q", line)
+		}
+	}
+	if !strings.HasPrefix(lines[0], iconCPU+" CPU") || !strings.Contains(lines[0], "42.0%") {
+		t.Fatalf("CPU summary = %q", lines[0])
+	}
+
+	for _, tc := range []struct {
+		mode  string
+		width int
+		want  bool
+	}{
+		{compactAuto, 99, true},
+		{compactAuto, 100, false},
+		{compactOn, 200, true},
+		{compactOff, 60, false},
+	} {
+		if got := useCompactLayout(tc.mode, tc.width); got != tc.want {
+			t.Errorf("useCompactLayout(%q, %d) = %v, want %v", tc.mode, tc.width, got, tc.want)
+		}
+	}
+}
+
+func TestASCIIGlyphsWhenColorIsOff(t *testing.T) {
+	spark, full, empty, cell, emptyCell, rule, icons := sparkBlocks, barFull, barEmpty, cellFull, cellEmpty, titleRule, cardIcons
+	defer func() {
+		sparkBlocks, barFull, barEmpty, cellFull, cellEmpty, titleRule, cardIcons = spark, full, empty, cell, emptyCell, rule, icons
+	}()
+	useASCIIGlyphs()
+
+	card := renderCPUCard(sysmetrics.CPUStatus{Usage: 50, PerCore: []float64{10, 90}, LogicalCPU: 2}, sysmetrics.ThermalStatus{}, sysmetrics.PowerStatus{}, sysmetrics.ANEStatus{})
+	rendered := renderCard(card, 40, 0)
+	for _, r := range rendered {
+		if r > 127 {
+			t.Fatalf("non-ASCII %q in the CPU card with color off:\n%s", r, rendered)
+		}
+	}
+	if !strings.HasPrefix(rendered, "CPU  ---") || !strings.Contains(rendered, "########--------") {
+		t.Fatalf("expected an ASCII title rule and bar, got:\n%s", rendered)
+	}
+}
+
+func TestHumanBytesFollowsUnitSystem(t *testing.T) {
+	defer func(orig units.System) { unitSystem = orig }(unitSystem)
+
+	for _, tc := range []struct {
+		system               units.System
+		long, short, compact string
+	}{
+		{units.Auto, "2.0 GB", "2G", "2.0G"},
+		{units.SI, "2.1 GB", "2G", "2.1G"},
+		{units.Binary, "2.0 GiB", "2Gi", "2.0Gi"},
+	} {
+		unitSystem = tc.system
+		const v = 2<<30 + 1
+		if got := humanBytes(v); got != tc.long {
+			t.Errorf("units %d: humanBytes = %q, want %q", tc.system, got, tc.long)
+		}
+		if got := humanBytesShort(v); got != tc.short {
+			t.Errorf("units %d: humanBytesShort = %q, want %q", tc.system, got, tc.short)
+		}
+		if got := humanBytesCompact(v); got != tc.compact {
+			t.Errorf("units %d: humanBytesCompact = %q, want %q", tc.system, got, tc.compact)
+		}
+	}
+}
+
+func TestRenderMemoryCardHidesSwapSizeOnNarrowWidth(t *testing.T) {
+	card := renderMemoryCard(sysmetrics.MemoryStatus{
+		Used:        8 << 30,
+		Total:       16 << 30,
+		Available:   8 << 30,
+		UsedPercent: 50.0,
+		SwapUsed:    482,
+		SwapTotal:   1000,
+	}, 38)
+
+	if len(card.lines) < 3 {
+		t.Fatalf("renderMemoryCard() expected at least 3 lines, got %d", len(card.lines))
+	}
+
+	swapLine := stripANSI(card.lines[2])
+	if strings.Contains(swapLine, "/") {
+		t.Fatalf("renderMemoryCard() narrow width should hide swap size, got %q", swapLine)
+	}
+}
+
+func TestRenderMemoryCardShowsSwapSizeOnWideWidth(t *testing.T) {
+	card := renderMemoryCard(sysmetrics.MemoryStatus{
+		Used:        8 << 30,
+		Total:       16 << 30,
+		Available:   8 << 30,
+		UsedPercent: 50.0,
+		SwapUsed:    482,
+		SwapTotal:   1000,
+	}, 60)
+
+	if len(card.lines) < 3 {
+		t.Fatalf("renderMemoryCard() expected at least 3 lines, got %d", len(card.lines))
+	}
+
+	swapLine := stripANSI(card.lines[2])
+	if !strings.Contains(swapLine, "/") {
+		t.Fatalf("renderMemoryCard() wide width should include swap size, got %q", swapLine)
+	}
+}
+
+func TestRenderMemoryCardUsesCollectedAvailableMemory(t *testing.T) {
+	card := renderMemoryCard(sysmetrics.MemoryStatus{
+		Used:        12 << 30,
+		Total:       16 << 30,
+		Available:   9 << 30,
+		UsedPercent: 75.0,
+	}, 60)
+
+	plain := stripANSI(strings.Join(card.lines, "\n"))
+	if !strings.Contains(plain, "Free") || !strings.Contains(plain, "56.2%") {
+		t.Fatalf("renderMemoryCard() should derive free percent from Available, got %q", plain)
+	}
+	if !strings.Contains(plain, "Avail  9.0 GB") {
+		t.Fatalf("renderMemoryCard() should render collected Available memory, got %q", plain)
+	}
+}
+
+func TestRenderMemoryCardCombinesCacheAndAvailable(t *testing.T) {
+	card := renderMemoryCard(sysmetrics.MemoryStatus{
+		Used:        12 << 30,
+		Total:       16 << 30,
+		Available:   9 << 30,
+		Cached:      2 << 30,
+		UsedPercent: 75.0,
+	}, 60)
+
+	plain := stripANSI(strings.Join(card.lines, "\n"))
+	if len(card.lines) != 4 {
+		t.Fatalf("renderMemoryCard() lines = %d, want 4", len(card.lines))
+	}
+	if !strings.Contains(plain, "Cache  2.0 GB · Avail 9.0 GB") {
+		t.Fatalf("renderMemoryCard() should combine cache and available memory, got %q", plain)
+	}
+}
+
+func TestModelViewPadsToTerminalHeight(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  int
+		height int
+	}{
+		{"narrow terminal", 60, 40},
+		{"wide terminal", 120, 40},
+		{"tall terminal", 120, 80},
+		{"short terminal", 120, 10},
+		{"zero height", 120, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := model{
+				width:   tt.width,
+				height:  tt.height,
+				ready:   true,
+				metrics: sysmetrics.MetricsSnapshot{},
+			}
+
+			view := m.View()
+			got := lipgloss.Height(view)
+			if got < tt.height {
+				t.Errorf("View() height = %d, want >= %d (terminal height)", got, tt.height)
+			}
+		})
+	}
+}
+
+func TestModelViewErrorRendersSingleMole(t *testing.T) {
+	m := model{
+		width:      120,
+		height:     40,
+		ready:      true,
+		metrics:    sysmetrics.MetricsSnapshot{},
+		errMessage: "boom",
+		animFrame:  0,
+		catHidden:  false,
+	}
+
+	view := m.View()
+	if strings.Count(view, "/\\_/\\") != 1 {
+		t.Fatalf("model.View() should render one mole frame in error state, got %d", strings.Count(view, "/\\_/\\"))
+	}
+}
+
+func stripANSI(s string) string {
+	var result strings.Builder
+	i := 0
+	for i < len(s) {
+		if i < len(s)-1 && s[i] == '\x1b' && s[i+1] == '[' {
+			i += 2
+			for i < len(s) && (s[i] < 'A' || s[i] > 'Z') && (s[i] < 'a' || s[i] > 'z') {
+				i++
+			}
+			if i < len(s) {
+				i++
+			}
+		} else {
+			result.WriteByte(s[i])
+			i++
+		}
+	}
+	return result.String()
+}
+
+func TestFormatClusterLine(t *testing.T) {
+	line := stripANSI(formatClusterLine([]sysmetrics.CPUCluster{
+		{Name: "P", Cores: 4, Usage: 92, FrequencyMHz: 3228},
+		{Name: "E", Cores: 4, Usage: 8},
+	}))
+	if line != "P/E    P 92% 3.2GHz · E 8%" {
+		t.Fatalf("formatClusterLine() = %q", line)
+	}
+	if formatClusterLine(nil) != "" {
+		t.Fatal("expected no line without cluster data")
+	}
+}
+
+func TestRenderSensorsCardUsesTemperatureThresholds(t *testing.T) {
+	card := renderSensorsCard([]sysmetrics.SensorReading{
+		{Label: "CPU", Value: 72, Unit: "°C", History: []float64{30, 50, 72, 90}},
+		{Label: "SSD", Value: 38, Unit: "°C"},
+	})
+	if card.title != "Sensors" || len(card.lines) != 2 {
+		t.Fatalf("card = %+v", card)
+	}
+	if got := stripANSI(card.lines[0]); got != "CPU    72.0°C  ▁▃▆█" {
+		t.Fatalf("cpu line = %q", got)
+	}
+	if got := stripANSI(card.lines[1]); got != "SSD    38.0°C" {
+		t.Fatalf("ssd line = %q", got)
+	}
+}
+
+func TestValidateFlagsRejectsInvertedTemperatureThresholds(t *testing.T) {
+	oldWarn, oldDanger := *tempWarn, *tempDanger
+	defer func() { *tempWarn, *tempDanger = oldWarn, oldDanger }()
+
+	*tempWarn, *tempDanger = 90, 80
+	if err := validateFlags(); err == nil {
+		t.Fatal("expected --temp-warn above --temp-danger to fail")
+	}
+}
+
+func TestFormatBatteryDetailLine(t *testing.T) {
+	line := stripANSI(formatBatteryDetailLine(sysmetrics.BatteryStatus{
+		DesignCapacity: 5103,
+		MaxCapacity:    4382,
+		CapacityUnit:   "mAh",
+		ChargeWatts:    38.24,
+		TimeToFull:     65,
+	}))
+	if line != "Cell   4382/5103 mAh · +38.2W · full in 1:05" {
+		t.Fatalf("formatBatteryDetailLine() = %q", line)
+	}
+
+	held := stripANSI(formatBatteryDetailLine(sysmetrics.BatteryStatus{TimeLeft: "2:10", TimeToEmpty: 130, OptimizedCharging: true}))
+	if held != "Cell   charging on hold" {
+		t.Fatalf("formatBatteryDetailLine() = %q, want pmset estimate left to the status line", held)
+	}
+	if formatBatteryDetailLine(sysmetrics.BatteryStatus{}) != "" {
+		t.Fatal("expected no line without detail")
+	}
+}
+
+func TestRenderProcessCardEnergySort(t *testing.T) {
+	card := renderProcessCard([]sysmetrics.ProcessInfo{
+		{PID: 3, Name: "mds_stores", CPU: 40, Energy: 95.4},
+	}, 56, sysmetrics.ProcessSortEnergy)
+	if card.title != "Processes by energy" {
+		t.Fatalf("title = %q", card.title)
+	}
+	if plain := stripANSI(card.lines[0]); !strings.Contains(plain, "40.0%") || !strings.Contains(plain, "E95.4") {
+		t.Fatalf("energy line = %q", plain)
+	}
+}
+
+func TestFormatDiskIODetailLines(t *testing.T) {
+	lines := formatDiskIODetailLines(sysmetrics.DiskIOStatus{
+		ReadRate:     40,
+		WriteRate:    2,
+		ReadIOPS:     150,
+		WriteIOPS:    50,
+		LatencyMs:    3.75,
+		ReadHistory:  []float64{0, 40},
+		WriteHistory: []float64{0, 2},
+		Devices: []sysmetrics.DiskDeviceIO{
+			{Name: "disk4", ReadRate: 30, ReadIOPS: 50, LatencyMs: 9},
+			{Name: "disk0", ReadRate: 10, WriteRate: 2, ReadIOPS: 100, WriteIOPS: 50, LatencyMs: 2},
+		},
+	})
+	plain := make([]string, len(lines))
+	for i, line := range lines {
+		plain[i] = stripANSI(line)
+	}
+	want := []string{
+		"Ops    R 150 · W 50 IOPS · 3.8ms",
+		"Trend  R ▁▁▁▁▁▁▁▁▁█  W ▁▁▁▁▁▁▁▁▁█",
+		"disk4  R 30 · W 0 MB/s · 9.0ms",
+		"disk0  R 10 · W 2.0 MB/s · 2.0ms",
+	}
+	if strings.Join(plain, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines =\n%s\nwant\n%s", strings.Join(plain, "\n"), strings.Join(want, "\n"))
+	}
+	if got := formatDiskIODetailLines(sysmetrics.DiskIOStatus{}); len(got) != 0 {
+		t.Fatalf("idle disk should add no lines, got %q", got)
+	}
+}
+
+func TestFormatDiskHealthLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		health []sysmetrics.DiskHealth
+		want   string
+	}{
+		{"none", nil, ""},
+		{"nvme ok", []sysmetrics.DiskHealth{{Device: "/dev/disk0", Status: sysmetrics.DiskHealthOK, WearPercent: 3, SparePercent: 100}}, "Health OK · wear 3% · spare 100%"},
+		{"diskutil ok", []sysmetrics.DiskHealth{{Device: "/dev/disk0", Status: sysmetrics.DiskHealthOK}}, "Health OK"},
+		{"worst wins", []sysmetrics.DiskHealth{
+			{Device: "/dev/disk0", Status: sysmetrics.DiskHealthOK},
+			{Device: "/dev/disk4", Status: sysmetrics.DiskHealthDegraded, Reasons: []string{"7 media errors"}},
+		}, "Health DEGRADED disk4: 7 media errors"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripANSI(formatDiskHealthLine(tt.health)); got != tt.want {
+				t.Fatalf("formatDiskHealthLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderStorageCard(t *testing.T) {
+	card := renderStorageCard(sysmetrics.StorageStatus{
+		Containers: []sysmetrics.APFSContainer{{Device: "disk3", Total: 460 << 30, Free: 120 << 30, Purgeable: 18 << 30}},
+		Volumes: []sysmetrics.StorageVolume{
+			{Name: "Macintosh HD", Used: 11 << 30, Container: "disk3"},
+			{Name: "Data", Used: 320 << 30, Container: "disk3"},
+			{Name: "Backup", Used: 1 << 40, Free: 800 << 30},
+		},
+	})
+	plain := make([]string, len(card.lines))
+	for i, line := range card.lines {
+		plain[i] = stripANSI(line)
+	}
+	want := []string{
+		"disk3  120G free of 460G · 18G purgeable",
+		"       Macintosh HD 11G · Data 320G",
+		"Backup 1T used · 800G free",
+	}
+	if strings.Join(plain, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines =\n%s\nwant\n%s", strings.Join(plain, "\n"), strings.Join(want, "\n"))
+	}
+
+	single := sysmetrics.MetricsSnapshot{Storage: sysmetrics.StorageStatus{Volumes: []sysmetrics.StorageVolume{{Name: "root"}}}}
+	for _, c := range buildCards(single, 80) {
+		if c.title == "Storage" {
+			t.Fatal("a single plain volume should not add a Storage card")
+		}
+	}
+}
+
+func TestRenderPortsCard(t *testing.T) {
+	card := renderPortsCard(sysmetrics.PortsStatus{
+		Listeners: []sysmetrics.ListenPort{
+			{Proto: "tcp", Port: 3000, PID: 4321, Process: "node", New: true},
+			{Proto: "tcp", Port: 5000, Process: "ControlCenter"},
+			{Proto: "tcp", Port: 7000, Process: "ControlCenter"},
+			{Proto: "udp", Port: 5353, Process: "mDNSResponder"},
+		},
+		Connections: []sysmetrics.ConnectionSummary{{Process: "Chrome", Established: 24}, {Process: "ssh", Established: 1}},
+		Established: 25,
+	})
+	plain := make([]string, len(card.lines))
+	for i, line := range card.lines {
+		plain[i] = stripANSI(line)
+	}
+	want := []string{
+		"Listen 3 TCP · 1 UDP · 25 est",
+		"New    tcp :3000 node (4321)",
+		"TCP    :5000 ControlCenter",
+		"Conns  Chrome 24 · ssh 1",
+	}
+	if strings.Join(plain, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines =\n%s\nwant\n%s", strings.Join(plain, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestFormatNetworkProcessLine(t *testing.T) {
+	got := stripANSI(formatNetworkProcessLine([]sysmetrics.ProcessNetwork{{Name: "Dropbox", RxRateMBs: 4, TxRateMBs: 0.25}}))
+	if got != "Top    Dropbox ↓4.0 ↑0.2 MB/s" {
+		t.Fatalf("formatNetworkProcessLine() = %q", got)
+	}
+	if got := formatNetworkProcessLine([]sysmetrics.ProcessNetwork{{Name: "idle"}}); got != "" {
+		t.Fatalf("idle traffic should be hidden, got %q", got)
+	}
+}
+
+func TestRenderVPNCard(t *testing.T) {
+	card := renderVPNCard(sysmetrics.VPNStatus{
+		Connections: []sysmetrics.VPNConnection{{Name: "Home WG"}},
+		Tunnels:     []string{"utun4 10.8.0.2"},
+		Tailscale:   &sysmetrics.TailscaleStatus{State: "Running", Tailnet: "example.ts.net", ExitNode: "nyc-exit", Peers: 3, PeersOnline: 2},
+	})
+	plain := make([]string, len(card.lines))
+	for i, line := range card.lines {
+		plain[i] = stripANSI(line)
+	}
+	want := "VPN    Home WG\nTail   example.ts.net · 2/3 peers\nExit   nyc-exit"
+	if got := strings.Join(plain, "\n"); got != want {
+		t.Fatalf("lines =\n%s\nwant\n%s", got, want)
+	}
+
+	tunnelOnly := renderVPNCard(sysmetrics.VPNStatus{Tunnels: []string{"utun4 10.8.0.2"}})
+	if len(tunnelOnly.lines) != 1 || stripANSI(tunnelOnly.lines[0]) != "Tunnel utun4 10.8.0.2" {
+		t.Fatalf("tunnel-only lines = %q", tunnelOnly.lines)
+	}
+}
+
+func TestWithPublicIP(t *testing.T) {
+	card := withPublicIP(cardData{}, sysmetrics.PublicIPStatus{IPv4: "203.0.113.7", City: "Berlin", Country: "DE", ASN: "AS3320"})
+	if len(card.lines) != 1 || stripANSI(card.lines[0]) != "Public 203.0.113.7 · Berlin, DE" {
+		t.Fatalf("lines = %q", card.lines)
+	}
+	if got := withPublicIP(cardData{}, sysmetrics.PublicIPStatus{}); len(got.lines) != 0 {
+		t.Fatalf("disabled public IP should add nothing, got %q", got.lines)
+	}
+}
+
+func TestRenderLatencyCard(t *testing.T) {
+	card := renderLatencyCard([]sysmetrics.LatencyProbe{
+		{Target: "gateway", AvgMs: 2.4, JitterMs: 1.4},
+		{Target: "1.1.1.1", AvgMs: 120, JitterMs: 30.2, LossPercent: 20, History: []float64{12, 120}},
+		{Target: "vpn.example.com", LossPercent: 100},
+	})
+	plain := make([]string, len(card.lines))
+	for i, line := range card.lines {
+		plain[i] = stripANSI(line)
+	}
+	want := []string{
+		"gateway   2ms ±1.4 0%",
+		"1.1.1.1   120ms ±30.2 20% ▁▁▁▁▁▁▁▁▁█",
+		"vpn.exam… unreachable",
+	}
+	if strings.Join(plain, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines =\n%s\nwant\n%s", strings.Join(plain, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRenderDNSCard(t *testing.T) {
+	card := renderDNSCard(sysmetrics.DNSStatus{
+		Servers: []sysmetrics.DNSServer{
+			{Address: "192.168.1.1", LatencyMs: 14.2},
+			{Address: "1.1.1.1", LatencyMs: 340, Slow: true},
+			{Address: "10.0.0.2", LatencyMs: 2000, Error: "timeout"},
+		},
+		SearchDomains: []string{"lan"},
+	})
+	plain := make([]string, len(card.lines))
+	for i, line := range card.lines {
+		plain[i] = stripANSI(line)
+	}
+	want := []string{
+		"192.168.1.1     14ms",
+		"1.1.1.1         340ms slow",
+		"10.0.0.2        timeout",
+		"Search lan",
+	}
+	if strings.Join(plain, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines =\n%s\nwant\n%s", strings.Join(plain, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestWithSpeedTestComparesToEarlierRuns(t *testing.T) {
+	card := withSpeedTest(cardData{}, []sysmetrics.SpeedTestResult{
+		{DownloadMbps: 300, UploadMbps: 40, LatencyMs: 12},
+		{DownloadMbps: 100, UploadMbps: 20, LatencyMs: 30},
+	})
+	if len(card.lines) != 1 || stripANSI(card.lines[0]) != "Speed  ↓100 ↑20 Mbps · 30ms · avg ↓300" {
+		t.Fatalf("lines = %q", card.lines)
+	}
+	if got := withSpeedTest(cardData{}, nil); len(got.lines) != 0 {
+		t.Fatalf("no history should add nothing, got %q", got.lines)
+	}
+}
+
+func TestRenderContainersCard(t *testing.T) {
+	card := renderContainersCard(sysmetrics.ContainerStatus{
+		Runtime:  "OrbStack",
+		VMCPUs:   8,
+		VMMemory: 16 << 30,
+		Containers: []sysmetrics.ContainerInfo{
+			{Name: "postgres", CPU: 112.4, MemoryBytes: 478 << 20},
+			{Name: "redis", CPU: 0.1, MemoryBytes: 24 << 20},
+			{Name: "web", CPU: 0, MemoryBytes: 80 << 20},
+			{Name: "worker", CPU: 0, MemoryBytes: 60 << 20},
+		},
+	})
+	plain := make([]string, len(card.lines))
+	for i, line := range card.lines {
+		plain[i] = stripANSI(line)
+	}
+	want := []string{
+		"VM     OrbStack · 8 CPU · 16G",
+		"postgres         112.4%  478.0M",
+		"redis              0.1%   24.0M",
+		"web                0.0%   80.0M",
+		"+1 more",
+	}
+	if strings.Join(plain, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines =\n%s\nwant\n%s", strings.Join(plain, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRenderKubernetesCard(t *testing.T) {
+	card := renderKubernetesCard(sysmetrics.KubernetesStatus{
+		Context: "kind-dev",
+		Distro:  "kind",
+		Pods:    sysmetrics.KubePodCounts{Total: 9, Running: 7, Pending: 1, Failing: 1},
+		Nodes:   []sysmetrics.KubeNode{{Name: "kind-control-plane", CPUPercent: 3, MemoryBytes: 1 << 30}},
+		TopPods: []sysmetrics.KubePod{{Namespace: "default", Name: "api-7f9c6b5d4-xyz12", CPUMilli: 1000, MemoryBytes: 512 << 20}},
+	})
+	plain := make([]string, len(card.lines))
+	for i, line := range card.lines {
+		plain[i] = stripANSI(line)
+	}
+	want := []string{
+		"Ctx    kind-dev",
+		"Pods   7 up · 1 pending · 1 failing",
+		"Node   kind-control-pl…   3% 1G",
+		"api-7f9c6b5d4-x…  1000m  512.0M",
+	}
+	if strings.Join(plain, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines =\n%s\nwant\n%s", strings.Join(plain, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRenderVMCard(t *testing.T) {
+	card := renderVMCard([]sysmetrics.VirtualMachine{
+		{Name: "Windows 11", CPUs: 4, MemoryAllocated: 8 << 30, CPUPercent: 120, MemoryUsed: 6 << 30},
+		{Name: "Virtualization", CPUPercent: 3, MemoryUsed: 512 << 20},
+	})
+	plain := make([]string, len(card.lines))
+	for i, line := range card.lines {
+		plain[i] = stripANSI(line)
+	}
+	want := []string{
+		"Windows 11        30% of 4 · 6G/8G",
+		"Virtualization     3% · 512M",
+	}
+	if strings.Join(plain, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines =\n%s\nwant\n%s", strings.Join(plain, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestWithGPUProcessesMergesDevices(t *testing.T) {
+	card := withGPUProcesses(cardData{}, []sysmetrics.GPUStatus{
+		{Processes: []sysmetrics.GPUProcess{{Name: "python", Usage: 12}}},
+		{Processes: []sysmetrics.GPUProcess{{GPU: 1, Name: "blender", Usage: 64}}},
+	}, time.Time{})
+	if len(card.lines) != 1 || stripANSI(card.lines[0]) != "GPU    blender 64% · python 12%" {
+		t.Fatalf("lines = %q", card.lines)
+	}
+
+	// Between full refreshes the reading trails the snapshot.
+	sampled := time.Date(2026, 3, 12, 9, 0, 0, 0, time.UTC)
+	stale := []sysmetrics.GPUStatus{{SampledAt: sampled, Processes: []sysmetrics.GPUProcess{{Name: "blender", Usage: 64}}}}
+	if got := withGPUProcesses(cardData{}, stale, sampled.Add(12*time.Second)); stripANSI(got.lines[0]) != "GPU    blender 64% (12s ago)" {
+		t.Fatalf("stale lines = %q", got.lines)
+	}
+	if got := withGPUProcesses(cardData{}, stale, sampled.Add(2*time.Second)); stripANSI(got.lines[0]) != "GPU    blender 64%" {
+		t.Fatalf("fresh lines = %q", got.lines)
+	}
+	if got := withGPUProcesses(cardData{}, []sysmetrics.GPUStatus{{Name: "Apple M3"}}, time.Time{}); len(got.lines) != 0 {
+		t.Fatalf("expected no GPU line without processes, got %q", got.lines)
+	}
+}
+
+func TestRenderCPUCardShowsBusyANE(t *testing.T) {
+	cpu := sysmetrics.CPUStatus{Usage: 10, LogicalCPU: 8}
+	if plain := stripANSI(strings.Join(renderCPUCard(cpu, sysmetrics.ThermalStatus{}, sysmetrics.PowerStatus{}, sysmetrics.ANEStatus{}).lines, "\n")); strings.Contains(plain, "ANE") {
+		t.Fatalf("idle ANE should stay hidden, got %q", plain)
+	}
+	plain := stripANSI(strings.Join(renderCPUCard(cpu, sysmetrics.ThermalStatus{}, sysmetrics.PowerStatus{}, sysmetrics.ANEStatus{Watts: 2, Usage: 25}).lines, "\n"))
+	if !strings.Contains(plain, "ANE") || !strings.Contains(plain, "25.0% 2.0W") {
+		t.Fatalf("CPU card = %q, want ANE line at 25%% 2.0W", plain)
+	}
+}
+
+func TestWithGPUMemoryShowsWiredUnifiedMemory(t *testing.T) {
+	card := withGPUMemory(cardData{}, []sysmetrics.GPUStatus{{Name: "Apple M3 Max", MemoryUsed: 4096, SharedMemory: true}}, 32<<30, time.Time{})
+	if len(card.lines) != 1 || stripANSI(card.lines[0]) != "GPU    "+humanBytes(4<<30)+" wired · 12% of RAM" {
+		t.Fatalf("lines = %q", card.lines)
+	}
+	// Dedicated VRAM is not system memory.
+	if got := withGPUMemory(cardData{}, []sysmetrics.GPUStatus{{Name: "RTX 4090", MemoryUsed: 8000, MemoryTotal: 24000}}, 32<<30, time.Time{}); len(got.lines) != 0 {
+		t.Fatalf("expected no line for dedicated VRAM, got %q", got.lines)
+	}
+}
+
+func TestRenderPeripheralsCardPutsSlowLinksFirst(t *testing.T) {
+	card := renderPeripheralsCard([]sysmetrics.Peripheral{
+		{Name: "Magic Keyboard", Bus: "USB", SpeedMbps: 12, MaxSpeedMbps: 12},
+		{Name: "Samsung T7", Bus: "USB", SpeedMbps: 480, MaxSpeedMbps: 10000, PowerMilli: 896},
+		{Name: "TS3 Plus", Bus: "Thunderbolt", SpeedMbps: 40000, MaxSpeedMbps: 40000},
+	})
+	plain := make([]string, len(card.lines))
+	for i, line := range card.lines {
+		plain[i] = stripANSI(line)
+	}
+	want := []string{
+		"Samsung T7       480M of 10G 896mA",
+		"Magic Keyboard   12M",
+		"TS3 Plus         40G",
+	}
+	if strings.Join(plain, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines =\n%s\nwant\n%s", strings.Join(plain, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRenderDisplaysCard(t *testing.T) {
+	card := renderDisplaysCard([]sysmetrics.Display{
+		{Name: "Color LCD", Connection: "Internal", Width: 3024, Height: 1964, LooksWidth: 1512, LooksHeight: 982, RefreshHz: 120, HDR: true},
+		{Name: "LG HDR 4K", Connection: "DisplayPort", Width: 3840, Height: 2160, RefreshHz: 30},
+	})
+	plain := make([]string, len(card.lines))
+	for i, line := range card.lines {
+		plain[i] = stripANSI(line)
+	}
+	want := []string{
+		"Color LCD        3024x1964 120Hz",
+		"  Internal · looks 1512x982 · HDR",
+		"LG HDR 4K        3840x2160 30Hz",
+		"  DisplayPort",
+	}
+	if strings.Join(plain, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines =\n%s\nwant\n%s", strings.Join(plain, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRenderLaunchItemsCard(t *testing.T) {
+	card := renderLaunchItemsCard([]sysmetrics.LaunchItem{
+		{Label: "com.acme.updater", Program: "/Library/Acme/updater", Running: true, Recent: true},
+		{Label: "com.google.keystone.agent", Running: true},
+		{Label: "org.example.sync", Recent: true},
+	})
+	plain := make([]string, len(card.lines))
+	for i, line := range card.lines {
+		plain[i] = stripANSI(line)
+	}
+	want := []string{
+		"3 items · 2 running · 2 new",
+		"com.acme.updater running",
+		"  /Library/Acme/updater",
+		"org.example.sync idle",
+	}
+	if strings.Join(plain, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines =\n%s\nwant\n%s", strings.Join(plain, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRenderServicesCard(t *testing.T) {
+	card := renderServicesCard([]sysmetrics.ServiceStatus{
+		{Name: "postgresql@16", Manager: sysmetrics.ServiceManagerBrew, State: "error", ExitCode: 1},
+		{Name: "com.example.indexer", Manager: sysmetrics.ServiceManagerLaunchd, State: "running", PID: 812, CPU: 64},
+	})
+	plain := make([]string, len(card.lines))
+	for i, line := range card.lines {
+		plain[i] = stripANSI(line)
+	}
+	want := []string{
+		"postgresql@16    exit 1 brew",
+		"com.example.ind… 64% CPU launchd",
+	}
+	if strings.Join(plain, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines =\n%s\nwant\n%s", strings.Join(plain, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRenderTimeMachineCard(t *testing.T) {
+	now := time.Date(2026, 3, 12, 8, 0, 0, 0, time.UTC)
+	card := renderTimeMachineCard(sysmetrics.TimeMachineStatus{
+		Destination:   "Backup",
+		LastBackup:    now.Add(-9*24*time.Hour - 3*time.Hour),
+		Running:       true,
+		Phase:         "Copying",
+		Percent:       45.2,
+		TimeRemaining: 754,
+		Overdue:       true,
+	}, now)
+	plain := make([]string, len(card.lines))
+	for i, line := range card.lines {
+		plain[i] = stripANSI(line)
+	}
+	want := []string{
+		"Last   9d 3h ago",
+		"Disk   Backup",
+		"Now    Copying · 45% · 12m left",
+	}
+	if strings.Join(plain, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines =\n%s\nwant\n%s", strings.Join(plain, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRenderCrashReportsCard(t *testing.T) {
+	card := renderCrashReportsCard(sysmetrics.CrashReports{Crashes: 4, Hangs: 1, Panics: 1, TopProcess: "Safari", TopCount: 3})
+	plain := make([]string, len(card.lines))
+	for i, line := range card.lines {
+		plain[i] = stripANSI(line)
+	}
+	want := []string{
+		"7d     crashes 4 · hangs 1 · panics 1",
+		"Top    Safari ×3",
+	}
+	if strings.Join(plain, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines =\n%s\nwant\n%s", strings.Join(plain, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestWithSpotlightAddsIndexLine(t *testing.T) {
+	card := withSpotlight(cardData{}, sysmetrics.SpotlightStatus{Indexing: true, CPU: 93.6, Workers: 2})
+	if len(card.lines) != 1 || stripANSI(card.lines[0]) != "Index  Spotlight 94% · 2 workers" {
+		t.Fatalf("lines = %q", card.lines)
+	}
+	if card := withSpotlight(cardData{}, sysmetrics.SpotlightStatus{CPU: 3}); len(card.lines) != 0 {
+		t.Fatalf("idle Spotlight should add nothing, got %q", card.lines)
+	}
+}
+
+func TestWithICloudAddsSyncLine(t *testing.T) {
+	card := withICloud(cardData{}, sysmetrics.ICloudStatus{Syncing: true, CPU: 15.5, PendingUploads: 12})
+	if len(card.lines) != 1 || stripANSI(card.lines[0]) != "iCloud syncing · 12 up · 16% CPU" {
+		t.Fatalf("lines = %q", card.lines)
+	}
+}
+
+func TestWithClockWarnsOnDrift(t *testing.T) {
+	card := withClock(cardData{}, sysmetrics.ClockStatus{Server: "time.apple.com", OffsetMs: 2345.7, Measured: true, Drifting: true})
+	if len(card.lines) != 1 || stripANSI(card.lines[0]) != "Clock  +2.3s off time.apple.com" {
+		t.Fatalf("lines = %q", card.lines)
+	}
+	if card := withClock(cardData{}, sysmetrics.ClockStatus{Sync: "synced", OffsetMs: 3, Measured: true}); len(card.lines) != 0 {
+		t.Fatalf("healthy clock should add nothing, got %q", card.lines)
+	}
+}
+
+func TestRenderSystemCard(t *testing.T) {
+	boot := time.Date(2026, 3, 9, 8, 12, 0, 0, time.UTC)
+	now := boot.Add(3*24*time.Hour + 4*time.Hour)
+	card := renderSystemCard(sysmetrics.MetricsSnapshot{
+		CollectedAt:   now,
+		UptimeSeconds: uint64(now.Sub(boot).Seconds()),
+		BootTime:      boot,
+		Session: sysmetrics.SessionInfo{
+			Users:      []string{"tw93"},
+			Sessions:   2,
+			WakeReason: "EC.LidOpen (User)",
+			WakeTime:   now.Add(-2*time.Hour - 5*time.Minute),
+		},
+	})
+	plain := make([]string, len(card.lines))
+	for i, line := range card.lines {
+		plain[i] = stripANSI(line)
+	}
+	want := []string{
+		"Up     3d 4h · since Mar 9 08:12",
+		"Wake   EC.LidOpen (User) · 2h 5m ago",
+		"Users  tw93 · 2 sessions",
+	}
+	if strings.Join(plain, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines =\n%s\nwant\n%s", strings.Join(plain, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRenderSleepCard(t *testing.T) {
+	day := time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)
+	card := renderSleepCard([]sysmetrics.PowerEvent{
+		{At: day.Add(8 * time.Hour), Kind: "wake", Reason: "EC.LidOpen/Lid Open", Charge: 79},
+		{At: day.Add(3 * time.Hour), Kind: "darkwake", Reason: "RTC/Maintenance", Charge: 84},
+		{At: day.Add(-50 * time.Minute), Kind: "sleep", Reason: "Clamshell Sleep", Charge: -1},
+	})
+	plain := make([]string, len(card.lines))
+	for i, line := range card.lines {
+		plain[i] = stripANSI(line)
+	}
+	want := []string{
+		"24h    sleeps 1 · dark wakes 1",
+		"08:00 Wake   79% EC.LidOpen/Lid Open",
+		"03:00 Dark   84% RTC/Maintenance",
+		"23:10 Sleep Clamshell Sleep",
+	}
+	if strings.Join(plain, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("lines =\n%s\nwant\n%s", strings.Join(plain, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestWithLeakSuspectsAddsMemoryLine(t *testing.T) {
+	now := time.Date(2026, 3, 12, 9, 0, 0, 0, time.UTC)
+	card := withLeakSuspects(cardData{}, []sysmetrics.LeakSuspect{
+		{PID: 10, Name: "Electron", StartRSS: 400 << 20, RSS: 812 << 20, Since: now.Add(-38 * time.Minute)},
+		{PID: 20, Name: "node", StartRSS: 200 << 20, RSS: 320 << 20, Since: now.Add(-12 * time.Minute)},
+	}, now)
+	if len(card.lines) != 1 || stripANSI(card.lines[0]) != "Leak?  Electron +412M in 38m · +1 more" {
+		t.Fatalf("lines = %q", card.lines)
+	}
+}
+
+func TestRenderLimitsCard(t *testing.T) {
+	card := renderLimitsCard(sysmetrics.LimitsStatus{
+		OpenFiles: 11520, MaxFiles: 12288, MaxFilesPerProc: 10240,
+		Procs: 612, MaxProcs: 4000,
+		TopFDs: []sysmetrics.ProcessFDs{{PID: 901, Name: "node", FDs: 9800}},
+	})
+	if len(card.lines) != 3 {
+		t.Fatalf("lines = %q", card.lines)
+	}
+	plain := stripANSI(card.lines[0])
+	if !strings.HasPrefix(plain, "Files ") || !strings.HasSuffix(plain, "11520/12288") {
+		t.Fatalf("files line = %q", plain)
+	}
+	if got := stripANSI(card.lines[2]); got != "node (901)       9800/10240 fds" {
+		t.Fatalf("holder line = %q", got)
+	}
+}
+
+func TestWithStuckProcessesCountsStates(t *testing.T) {
+	card := withStuckProcesses(cardData{}, []sysmetrics.StuckProcess{
+		{PID: 10, Name: "defunct", State: "zombie"},
+		{PID: 11, Name: "mount_nfs", State: "uninterruptible"},
+		{PID: 12, Name: "defunct", State: "zombie"},
+	})
+	if len(card.lines) != 1 || stripANSI(card.lines[0]) != "Stuck  1 blocked · 2 zombie · a open" {
+		t.Fatalf("lines = %q", card.lines)
+	}
+	if card := withStuckProcesses(cardData{}, nil); len(card.lines) != 0 {
+		t.Fatalf("no stuck processes should add nothing, got %q", card.lines)
+	}
+}
+
+func TestColorizeTempThresholds(t *testing.T) {
+	tests := []struct {
+		temp     float64
+		expected string
+	}{
+		{temp: 30.0, expected: "30.0"}, // Normal - should use okStyle (green)
+		{temp: 64.9, expected: "64.9"}, // Just below warning threshold
+		{temp: 65.0, expected: "65.0"}, // Warning threshold - should use warnStyle (yellow)
+		{temp: 78.0, expected: "78.0"}, // Mid warning range
+		{temp: 84.9, expected: "84.9"}, // Just below danger threshold
+		{temp: 85.0, expected: "85.0"}, // Danger threshold - should use dangerStyle (red)
+		{temp: 90.0, expected: "90.0"}, // High temperature
+		{temp: 0.0, expected: "0.0"},   // Edge case: zero
+	}
+
+	for _, tt := range tests {
+		result := colorizeTemp(tt.temp)
+		// Check that result contains the formatted temperature value
+		if !strings.Contains(result, tt.expected) {
+			t.Errorf("colorizeTemp(%.1f) = %q, should contain %q", tt.temp, result, tt.expected)
+		}
+		// Verify output is not empty and contains the temperature
+		if result == "" {
+			t.Errorf("colorizeTemp(%.1f) returned empty string", tt.temp)
+		}
+	}
+}
+
+func TestColorizeTempStyleRanges(t *testing.T) {
+	normalTemp := colorizeTemp(40.0)
+	warningTemp := colorizeTemp(72.0)
+	dangerTemp := colorizeTemp(90.0)
+
+	if normalTemp == "" || warningTemp == "" || dangerTemp == "" {
+		t.Fatal("colorizeTemp should not return empty strings")
+	}
+
+	if !strings.Contains(normalTemp, "40.0") {
+		t.Errorf("normal temp should contain '40.0', got: %s", normalTemp)
+	}
+	if !strings.Contains(warningTemp, "72.0") {
+		t.Errorf("warning temp should contain '72.0', got: %s", warningTemp)
+	}
+	if !strings.Contains(dangerTemp, "90.0") {
+		t.Errorf("danger temp should contain '90.0', got: %s", dangerTemp)
+	}
+}
+
+func TestFormatPagingLine(t *testing.T) {
+	line := stripANSI(formatPagingLine(sysmetrics.MemoryStatus{SwapInRate: 0.12, SwapOutRate: 2.4, PageFaultRate: 1234}))
+	if line != "Paging ↓0.1 ↑2.4 MB/s · 1.2k flt/s" {
+		t.Fatalf("formatPagingLine() = %q", line)
+	}
+	if formatPagingLine(sysmetrics.MemoryStatus{}) != "" {
+		t.Fatal("expected no line without paging activity")
+	}
+
+	card := renderMemoryCard(sysmetrics.MemoryStatus{Total: 16 << 30, Used: 8 << 30, Compressed: 3 << 30}, 0)
+	if !strings.Contains(stripANSI(strings.Join(card.lines, "\n")), "Comp   3.0 GB") {
+		t.Fatalf("memory card should show compressed memory, got %q", card.lines)
+	}
+}
+
+func TestFormatPowerLine(t *testing.T) {
+	line := stripANSI(formatPowerLine(sysmetrics.PowerStatus{PackageWatts: 12.34, CPUWatts: 8.1, GPUWatts: 3, ANEWatts: 0.01, AvgPackageWatts: 9.8}))
+	if line != "Power  12.3W avg 9.8W  CPU 8.1 GPU 3.0" {
+		t.Fatalf("formatPowerLine() = %q", line)
+	}
+	if formatPowerLine(sysmetrics.PowerStatus{}) != "" {
+		t.Fatal("expected no line without power data")
+	}
+}
+
+func TestRenderProcessAlertBar(t *testing.T) {
+	alerts := []sysmetrics.ProcessAlert{
+		{PID: 10, Name: "node", CPU: 150, Threshold: 100, Window: "5m0s", Status: "active"},
+		{PID: 11, Name: "java", CPU: 130, Threshold: 100, Window: "5m0s", Status: "active"},
+	}
+
+	bar := renderProcessAlertBar(alerts, 120)
+	if !strings.Contains(bar, "ALERT") {
+		t.Fatalf("missing alert prefix: %q", bar)
+	}
+	if !strings.Contains(bar, "node (10)") {
+		t.Fatalf("missing lead process label: %q", bar)
+	}
+	if !strings.Contains(bar, "+1 more") {
+		t.Fatalf("missing additional alert count: %q", bar)
+	}
+	if strings.Contains(bar, "terminate") || strings.Contains(bar, "ignore") {
+		t.Fatalf("unexpected action text in read-only alert bar: %q", bar)
+	}
+}
@@ -1,20 +1,36 @@
-package main
+package status
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
+
+	"github.com/tw93/mole/pkg/sysmetrics"
 )
 
 // runWatchMode streams metrics continuously as newline-delimited JSON (one full
 // MetricsSnapshot per line) using a single warm Collector, so rate metrics
 // (network, disk IO) stay accurate across ticks.
 func runWatchMode(interval time.Duration) {
-	runWatchStdout(interval)
+	out := openOutput("status")
+	if out.Stdout() {
+		runWatch(context.Background(), out, interval)
+		return
+	}
+	// --output only renames the stream into place on Close, so Ctrl-C and
+	// SIGTERM end the watch instead of the process.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	runWatch(ctx, out, interval)
+	closeOutput("status", out)
 }
 
-// watchState mirrors the TUI's collection cadence (cmd/status/main.go): a full
+// watchState mirrors the TUI's collection cadence (main.go): a full
 // collect priming the enrichment cache, then mostly fast collects that inherit
 // the cached slow-changing fields, with periodic process/full refreshes.
 type watchState struct {
@@ -27,21 +43,21 @@ func (s *watchState) nextMode(now time.Time) collectionMode {
 	return nextCollectionMode(s.ready, s.lastFullAt, s.lastProcessAt, now)
 }
 
-func (s *watchState) collect(c *Collector) (MetricsSnapshot, error) {
+func (s *watchState) collect(c *sysmetrics.Collector) (sysmetrics.MetricsSnapshot, error) {
 	now := time.Now()
 	mode := s.nextMode(now)
 
 	var (
-		snap MetricsSnapshot
+		snap sysmetrics.MetricsSnapshot
 		err  error
 	)
 	switch mode {
 	case collectionFull:
-		snap, err = c.Collect()
+		snap, err = c.Collect(context.Background())
 	case collectionProcess:
-		snap, err = c.CollectProcesses()
+		snap, err = c.CollectProcesses(context.Background())
 	default:
-		snap, err = c.CollectFast()
+		snap, err = c.CollectFast(context.Background())
 	}
 
 	if err == nil {
@@ -51,31 +67,46 @@ func (s *watchState) collect(c *Collector) (MetricsSnapshot, error) {
 	return snap, err
 }
 
-// runWatchStdout emits the first snapshot immediately (so the consumer paints
+// runWatch emits the first snapshot immediately (so the consumer paints
 // without waiting a full interval), then mirrors the TUI cadence: the first
 // successful fast snapshot is followed by an immediate full snapshot, and later
 // ticks wait for the configured interval after each collection finishes. Exits
-// cleanly when stdout closes (parent process gone).
-func runWatchStdout(interval time.Duration) {
-	collector := NewCollector(processWatchOptionsFromFlags())
-	enc := json.NewEncoder(os.Stdout)
+// cleanly when w closes (parent process gone) or ctx is done.
+func runWatch(ctx context.Context, w io.Writer, interval time.Duration) {
+	collector := newCollectorFromFlags()
+	defer collector.Close()
+	enc := json.NewEncoder(w)
 	var st watchState
+	var alerts alertNotifier
 
-	for {
+	for ctx.Err() == nil {
 		wasReady := st.ready
 		snap, err := st.collect(collector)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "status: collect failed: %v\n", err)
 			if snap.CollectedAt.IsZero() {
-				time.Sleep(interval)
+				sleepCtx(ctx, interval)
 				continue
 			}
 		}
 		if err := enc.Encode(snap); err != nil {
 			return // stdout closed; parent died, nothing left to feed.
 		}
+		if err == nil {
+			alerts.send(snap.ProcessAlerts)
+		}
 		if wasReady {
-			time.Sleep(interval)
+			sleepCtx(ctx, interval)
 		}
 	}
 }
+
+// sleepCtx sleeps for d, or until ctx is done.
+func sleepCtx(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
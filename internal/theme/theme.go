@@ -0,0 +1,238 @@
+// Package theme holds the named color palettes shared by the analyze and
+// status commands, and the terminal color depth they are drawn at.
+//
+// Every color carries a truecolor value and a basic ANSI fallback. The 256
+// color value is derived from the truecolor one; the ANSI index is picked by
+// hand because the nearest of the 16 terminal colors is often the wrong hue.
+// The dark palette's fallbacks are the codes analyze used before themes, so
+// a 16-color terminal looks the same as it always did.
+package theme
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Color is one palette entry: "#RRGGBB" and an ANSI color index, 0-15.
+type Color struct {
+	Hex  string
+	ANSI uint8
+}
+
+func (c Color) rgb() (r, g, b uint8) {
+	v, err := strconv.ParseUint(strings.TrimPrefix(c.Hex, "#"), 16, 32)
+	if err != nil {
+		return 0, 0, 0
+	}
+	return uint8(v >> 16), uint8(v >> 8), uint8(v)
+}
+
+// ANSI256 maps the color onto the xterm 6x6x6 cube or the gray ramp,
+// whichever is closer.
+func (c Color) ANSI256() uint8 {
+	r, g, b := c.rgb()
+	levels := []int{0, 95, 135, 175, 215, 255}
+	nearest := func(v uint8) int {
+		best := 0
+		for i, l := range levels {
+			if abs(int(v)-l) < abs(int(v)-levels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	ri, gi, bi := nearest(r), nearest(g), nearest(b)
+	cube := 16 + 36*ri + 6*gi + bi
+	cubeDist := sq(int(r)-levels[ri]) + sq(int(g)-levels[gi]) + sq(int(b)-levels[bi])
+
+	avg := (int(r) + int(g) + int(b)) / 3
+	step := min(max((avg-8+5)/10, 0), 23)
+	grayLevel := 8 + 10*step
+	grayDist := sq(int(r)-grayLevel) + sq(int(g)-grayLevel) + sq(int(b)-grayLevel)
+	if grayDist < cubeDist {
+		return uint8(232 + step)
+	}
+	return uint8(cube)
+}
+
+func abs(v int) int { return max(v, -v) }
+func sq(v int) int  { return v * v }
+
+// params is the SGR foreground parameter list for the color at a profile.
+func (c Color) params(p Profile) string {
+	switch p {
+	case TrueColor:
+		r, g, b := c.rgb()
+		return fmt.Sprintf("38;2;%d;%d;%d", r, g, b)
+	case ANSI256:
+		return fmt.Sprintf("38;5;%d", c.ANSI256())
+	default:
+		if c.ANSI < 8 {
+			return strconv.Itoa(30 + int(c.ANSI))
+		}
+		return strconv.Itoa(90 + int(c.ANSI) - 8)
+	}
+}
+
+// Sequence is the escape that resets attributes and switches to the color,
+// bold when asked, e.g. "\033[0;35m" or "\033[1;38;2;189;147;249m".
+func (c Color) Sequence(p Profile, bold bool) string {
+	if p == NoColor {
+		return ""
+	}
+	attr := "0"
+	if bold {
+		attr = "1"
+	}
+	return "\033[" + attr + ";" + c.params(p) + "m"
+}
+
+// Palette assigns a color to every role the commands draw with.
+type Palette struct {
+	Name      string
+	Primary   Color // Brand accent: headers, selection, the mole
+	Title     Color // Card and section titles
+	Subtle    Color // Secondary text and hints
+	Line      Color // Separators and empty bar cells
+	OK        Color
+	Good      Color // Health score, second tier
+	Excellent Color // Health score, top tier
+	Warn      Color
+	Danger    Color
+	Info      Color
+	Accent    Color
+	AlertText Color // Text on the alert bar
+	AlertBack Color // Alert bar background
+}
+
+// Dark is the default and matches the colors used before themes existed.
+var Dark = Palette{
+	Name:      "dark",
+	Primary:   Color{"#BD93F9", 5},
+	Title:     Color{"#C79FD7", 5},
+	Subtle:    Color{"#737373", 8},
+	Line:      Color{"#404040", 8},
+	OK:        Color{"#A5D6A7", 2},
+	Good:      Color{"#87D787", 2},
+	Excellent: Color{"#87FF87", 10},
+	Warn:      Color{"#FFD75F", 3},
+	Danger:    Color{"#FF5F5F", 1},
+	Info:      Color{"#5FAFFF", 4},
+	Accent:    Color{"#5FD7D7", 6},
+	AlertText: Color{"#2B1200", 0},
+	AlertBack: Color{"#FFD75F", 3},
+}
+
+// Light keeps text readable on white and pale backgrounds.
+var Light = Palette{
+	Name:      "light",
+	Primary:   Color{"#7C3AED", 5},
+	Title:     Color{"#8E44AD", 5},
+	Subtle:    Color{"#6B6B6B", 8},
+	Line:      Color{"#C8C8C8", 7},
+	OK:        Color{"#2E7D32", 2},
+	Good:      Color{"#388E3C", 2},
+	Excellent: Color{"#1B5E20", 2},
+	Warn:      Color{"#B26A00", 3},
+	Danger:    Color{"#C62828", 1},
+	Info:      Color{"#1565C0", 4},
+	Accent:    Color{"#00838F", 6},
+	AlertText: Color{"#FFFFFF", 15},
+	AlertBack: Color{"#B26A00", 3},
+}
+
+// Solarized uses Ethan Schoonover's accent colors, which read on both the
+// dark and light Solarized backgrounds.
+var Solarized = Palette{
+	Name:      "solarized",
+	Primary:   Color{"#6C71C4", 5},
+	Title:     Color{"#D33682", 5},
+	Subtle:    Color{"#586E75", 8},
+	Line:      Color{"#073642", 8},
+	OK:        Color{"#859900", 2},
+	Good:      Color{"#859900", 2},
+	Excellent: Color{"#2AA198", 6},
+	Warn:      Color{"#B58900", 3},
+	Danger:    Color{"#DC322F", 1},
+	Info:      Color{"#268BD2", 4},
+	Accent:    Color{"#2AA198", 6},
+	AlertText: Color{"#002B36", 0},
+	AlertBack: Color{"#B58900", 3},
+}
+
+// HighContrast uses only fully saturated colors and white for low vision
+// and washed-out projectors.
+var HighContrast = Palette{
+	Name:      "high-contrast",
+	Primary:   Color{"#FF00FF", 13},
+	Title:     Color{"#FFFFFF", 15},
+	Subtle:    Color{"#C0C0C0", 7},
+	Line:      Color{"#808080", 7},
+	OK:        Color{"#00FF00", 10},
+	Good:      Color{"#00FF00", 10},
+	Excellent: Color{"#00FF00", 10},
+	Warn:      Color{"#FFFF00", 11},
+	Danger:    Color{"#FF0000", 9},
+	Info:      Color{"#00BFFF", 12},
+	Accent:    Color{"#00FFFF", 14},
+	AlertText: Color{"#000000", 0},
+	AlertBack: Color{"#FFFF00", 11},
+}
+
+var palettes = []Palette{Dark, Light, Solarized, HighContrast}
+
+// Names lists the themes --theme accepts.
+func Names() []string {
+	names := make([]string, 0, len(palettes))
+	for _, p := range palettes {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// Resolve picks the palette named by --theme, then MO_THEME, then Dark.
+func Resolve(name string) (Palette, error) {
+	if name == "" {
+		name = os.Getenv("MO_THEME")
+	}
+	if name == "" {
+		return Dark, nil
+	}
+	i := slices.IndexFunc(palettes, func(p Palette) bool { return p.Name == strings.ToLower(name) })
+	if i < 0 {
+		return Palette{}, fmt.Errorf("unknown theme %q; choose one of %s", name, strings.Join(Names(), ", "))
+	}
+	return palettes[i], nil
+}
+
+// Profile is how many colors the terminal can show. NoColor also means
+// plain ASCII bars and graphs, for logs and screen readers.
+type Profile int
+
+const (
+	NoColor Profile = iota
+	ANSI
+	ANSI256
+	TrueColor
+)
+
+// Detect reads the color depth from the environment the way most terminal
+// libraries do: NO_COLOR (https://no-color.org) or a dumb terminal turns
+// color off, COLORTERM announces truecolor, and TERM names 256-color
+// support.
+func Detect(getenv func(string) string) Profile {
+	term := getenv("TERM")
+	switch colorterm := strings.ToLower(getenv("COLORTERM")); {
+	case getenv("NO_COLOR") != "" || term == "dumb":
+		return NoColor
+	case colorterm == "truecolor" || colorterm == "24bit":
+		return TrueColor
+	case strings.Contains(term, "256color"), getenv("TERM_PROGRAM") == "Apple_Terminal":
+		return ANSI256
+	default:
+		return ANSI
+	}
+}
@@ -0,0 +1,78 @@
+package theme
+
+import (
+	"testing"
+)
+
+func TestDarkMatchesLegacyANSICodes(t *testing.T) {
+	for _, tc := range []struct {
+		color Color
+		bold  bool
+		want  string
+	}{
+		{Dark.Primary, false, "\033[0;35m"},
+		{Dark.Primary, true, "\033[1;35m"},
+		{Dark.Subtle, false, "\033[0;90m"},
+		{Dark.Danger, false, "\033[0;31m"},
+		{Dark.Warn, false, "\033[0;33m"},
+		{Dark.OK, false, "\033[0;32m"},
+		{Dark.Info, false, "\033[0;34m"},
+		{Dark.Accent, false, "\033[0;36m"},
+	} {
+		if got := tc.color.Sequence(ANSI, tc.bold); got != tc.want {
+			t.Errorf("%s.Sequence(ANSI) = %q, want %q", tc.color.Hex, got, tc.want)
+		}
+	}
+
+	if got := Dark.Primary.Sequence(TrueColor, false); got != "\033[0;38;2;189;147;249m" {
+		t.Errorf("truecolor sequence = %q", got)
+	}
+	if got := Dark.Primary.Sequence(NoColor, true); got != "" {
+		t.Errorf("NoColor sequence = %q, want empty", got)
+	}
+}
+
+func TestANSI256PicksCubeOrGray(t *testing.T) {
+	for hex, want := range map[string]uint8{
+		"#FF0000": 196,
+		"#FFD75F": 221,
+		"#737373": 243,
+		"#000000": 16,
+	} {
+		if got := (Color{Hex: hex}).ANSI256(); got != want {
+			t.Errorf("ANSI256(%s) = %d, want %d", hex, got, want)
+		}
+	}
+}
+
+func TestDetectAndResolve(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+	for _, tc := range []struct {
+		vars map[string]string
+		want Profile
+	}{
+		{map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor"}, TrueColor},
+		{map[string]string{"TERM": "xterm-256color"}, ANSI256},
+		{map[string]string{"TERM": "xterm"}, ANSI},
+		{map[string]string{"TERM": "dumb", "COLORTERM": "truecolor"}, NoColor},
+		{map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor", "NO_COLOR": "1"}, NoColor},
+		{map[string]string{"TERM": "xterm", "NO_COLOR": ""}, ANSI},
+	} {
+		if got := Detect(env(tc.vars)); got != tc.want {
+			t.Errorf("Detect(%v) = %d, want %d", tc.vars, got, tc.want)
+		}
+	}
+
+	t.Setenv("MO_THEME", "solarized")
+	if p, err := Resolve(""); err != nil || p.Name != "solarized" {
+		t.Fatalf("Resolve(\"\") with MO_THEME = %q, %v", p.Name, err)
+	}
+	if p, err := Resolve("High-Contrast"); err != nil || p.Name != "high-contrast" {
+		t.Fatalf("--theme should win over MO_THEME, got %q, %v", p.Name, err)
+	}
+	if _, err := Resolve("neon"); err == nil {
+		t.Fatal("unknown theme accepted")
+	}
+}
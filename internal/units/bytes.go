@@ -6,6 +6,8 @@
 // while status reports memory and live counters with binary (1024-based)
 // units to match macOS Activity Monitor and gopsutil. Both styles live here so
 // that any future tweak (precision, rounding, label set) stays in one place.
+// --units overrides both with a System: SI everywhere, or binary with IEC
+// labels everywhere.
 package units
 
 import (
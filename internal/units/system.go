@@ -0,0 +1,120 @@
+package units
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// System is the unit convention chosen with --units. Auto keeps each
+// command's own default: SI in analyze, binary with short labels in status.
+type System int
+
+const (
+	Auto   System = iota
+	SI            // 1000-based, "1.5 GB", as Finder and disk vendors count
+	Binary        // 1024-based with IEC labels, "1.4 GiB"
+)
+
+var systemNames = map[string]System{"auto": Auto, "si": SI, "binary": Binary}
+
+// ParseSystem reads a --units value.
+func ParseSystem(name string) (System, error) {
+	system, ok := systemNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Auto, fmt.Errorf("unknown units %q; choose si, binary, or auto", name)
+	}
+	return system, nil
+}
+
+// ConfigPath is the file holding the default for --units, one word.
+func ConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "mole", "units")
+}
+
+// ResolveSystem picks --units, then MO_UNITS, then the config file, then
+// Auto. A bad flag or environment value is an error; a config file that
+// cannot be read is ignored.
+func ResolveSystem(flagValue string) (System, error) {
+	if flagValue != "" {
+		return ParseSystem(flagValue)
+	}
+	if env := os.Getenv("MO_UNITS"); env != "" {
+		system, err := ParseSystem(env)
+		if err != nil {
+			return Auto, fmt.Errorf("MO_UNITS: %w", err)
+		}
+		return system, nil
+	}
+	if path := ConfigPath(); path != "" {
+		if raw, err := os.ReadFile(path); err == nil {
+			if system, err := ParseSystem(string(raw)); err == nil {
+				return system, nil
+			}
+		}
+	}
+	return Auto, nil
+}
+
+// scaled divides v by base until it drops below base, returning the value
+// and how many times it divided.
+func scaled(v uint64, base float64) (float64, int) {
+	value, exp := float64(v), 0
+	for value >= base && exp < 6 {
+		value /= base
+		exp++
+	}
+	return value, exp
+}
+
+// BytesIEC formats a byte count in binary units with IEC labels
+// (e.g. "1.5 GiB").
+func BytesIEC(v uint64) string {
+	value, exp := scaled(v, 1024)
+	if exp == 0 {
+		return strconv.FormatUint(v, 10) + " B"
+	}
+	return fmt.Sprintf("%.1f %ciB", value, "KMGTPE"[exp-1])
+}
+
+// BytesIECShort is BytesBinShort with IEC suffixes (e.g. "100Gi").
+func BytesIECShort(v uint64) string {
+	value, exp := scaled(v, 1024)
+	if exp == 0 {
+		return strconv.FormatUint(v, 10)
+	}
+	return fmt.Sprintf("%.0f%ci", value, "KMGTPE"[exp-1])
+}
+
+// BytesIECCompact is BytesBinCompact with IEC suffixes (e.g. "1.5Gi").
+func BytesIECCompact(v uint64) string {
+	value, exp := scaled(v, 1024)
+	if exp == 0 {
+		return strconv.FormatUint(v, 10)
+	}
+	return fmt.Sprintf("%.1f%ci", value, "KMGTPE"[exp-1])
+}
+
+// BytesSIShort is BytesBinShort in 1000-based units (e.g. "107G").
+func BytesSIShort(v uint64) string {
+	value, exp := scaled(v, 1000)
+	if exp == 0 {
+		return strconv.FormatUint(v, 10)
+	}
+	return fmt.Sprintf("%.0f%c", value, "kMGTPE"[exp-1])
+}
+
+// BytesSICompact is BytesBinCompact in 1000-based units (e.g. "1.6G").
+func BytesSICompact(v uint64) string {
+	value, exp := scaled(v, 1000)
+	if exp == 0 {
+		return strconv.FormatUint(v, 10)
+	}
+	return fmt.Sprintf("%.1f%c", value, "kMGTPE"[exp-1])
+}
@@ -0,0 +1,61 @@
+package units
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSystemFormatters(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(uint64) string
+		in   uint64
+		want string
+	}{
+		{"IEC bytes", BytesIEC, 512, "512 B"},
+		{"IEC kibibytes", BytesIEC, 1536, "1.5 KiB"},
+		{"IEC gibibytes", BytesIEC, 3 << 30, "3.0 GiB"},
+		{"IEC short", BytesIECShort, 100 << 30, "100Gi"},
+		{"IEC compact", BytesIECCompact, 1536 << 20, "1.5Gi"},
+		{"SI short", BytesSIShort, 100 << 30, "107G"},
+		{"SI compact", BytesSICompact, 1536 << 20, "1.6G"},
+		{"SI short below a kilobyte", BytesSIShort, 999, "999"},
+	}
+	for _, tt := range tests {
+		if got := tt.fn(tt.in); got != tt.want {
+			t.Errorf("%s(%d) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestResolveSystem(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("MO_UNITS", "")
+
+	if got, err := ResolveSystem(""); err != nil || got != Auto {
+		t.Fatalf("no flag, env or config = %v, %v; want Auto", got, err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(home, ".config", "mole"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ConfigPath(), []byte("binary\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ResolveSystem(""); got != Binary {
+		t.Fatalf("config file = %v, want Binary", got)
+	}
+
+	t.Setenv("MO_UNITS", "SI")
+	if got, _ := ResolveSystem(""); got != SI {
+		t.Fatalf("MO_UNITS should override the config file, got %v", got)
+	}
+	if got, _ := ResolveSystem("auto"); got != Auto {
+		t.Fatalf("--units should override MO_UNITS, got %v", got)
+	}
+	if _, err := ResolveSystem("decimal"); err == nil {
+		t.Fatal("unknown --units accepted")
+	}
+}
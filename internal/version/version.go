@@ -0,0 +1,8 @@
+// Package version is the release version every mole command reports.
+package version
+
+// Version is stamped at build time from the VERSION line of the mole
+// entrypoint:
+//
+//	go build -ldflags "-X github.com/tw93/mole/internal/version.Version=1.47.1"
+var Version = "dev"
@@ -7,9 +7,18 @@ MOLE_COMMANDS=(
     "optimize:Refresh caches and services"
     "analyze:Explore disk usage"
     "status:Monitor system health"
+    "config:Show or change settings"
+    "doctor:Check permissions, tools, and settings"
+    "setup:Walk through first-run setup"
+    "cache:List or clear the analyze scan cache"
+    "serve:Automation interface over JSON-RPC"
+    "mcp:Disk and status tools for AI assistants"
+    "helper:Privileged helper for password-free cleanup"
     "history:Review cleanup activity"
     "purge:Remove old project artifacts"
     "installer:Find and remove installer files"
+    "orphans:Find data left by uninstalled apps"
+    "screenshots:Tidy up old screenshots and recordings"
     "touchid:Configure Touch ID for sudo"
     "completion:Setup shell tab completion"
     "update:Update to latest version"
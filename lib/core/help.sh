@@ -9,6 +9,7 @@ show_clean_help() {
     echo "  --dry-run, -n     Preview cleanup without making changes"
     echo "  --external PATH   Clean OS metadata from a mounted external volume"
     echo "  --whitelist       Manage protected paths"
+    echo "  --rules [ARGS]    Run the YAML cleanup rules instead, see mo clean --rules --help"
     echo "  --debug           Show detailed operation logs"
     echo "  -h, --help        Show this help message"
 }
@@ -24,6 +25,41 @@ show_installer_help() {
     echo "  -h, --help        Show this help message"
 }
 
+show_orphans_help() {
+    echo "Usage: mo orphans [OPTIONS]"
+    echo ""
+    echo "List ~/Library data that belongs to apps no longer installed, with the"
+    echo "total reclaimable size, then pick which apps' data to remove."
+    echo ""
+    echo "Options:"
+    echo "  --list            List orphaned data without prompting"
+    echo "  --dry-run, -n     Preview removal without making changes"
+    echo "  --permanent       Bypass macOS Trash and rm -rf immediately"
+    echo "  --debug           Show detailed operation logs"
+    echo "  -h, --help        Show this help message"
+    echo ""
+    echo "Data is only listed when it has been untouched for 30 days and no"
+    echo "installed or running app claims its bundle ID."
+}
+
+show_screenshots_help() {
+    echo "Usage: mo screenshots [OPTIONS]"
+    echo ""
+    echo "List screenshots and screen recordings on the Desktop and in the"
+    echo "screencapture folder, grouped by age, then archive or trash the"
+    echo "groups you pick."
+    echo ""
+    echo "Options:"
+    echo "  --list             List captures without prompting"
+    echo "  --archive-dir DIR  Archive into DIR, default ~/Pictures/Screenshot Archive"
+    echo "  --dry-run, -n      Preview the move without making changes"
+    echo "  --permanent        Bypass macOS Trash and rm -rf immediately"
+    echo "  --debug            Show detailed operation logs"
+    echo "  -h, --help         Show this help message"
+    echo ""
+    echo "Archived captures go into a folder per month they were taken."
+}
+
 show_optimize_help() {
     echo "Usage: mo optimize [OPTIONS]"
     echo ""
@@ -54,7 +90,7 @@ show_touchid_help() {
 }
 
 show_uninstall_help() {
-    echo "Usage: mo uninstall [OPTIONS] [APP_NAME ...]"
+    echo "Usage: mo uninstall [OPTIONS] [APP_NAME|BUNDLE_ID ...]"
     echo ""
     echo "Interactively remove applications and their leftover files."
     echo "Optionally specify one or more app names to uninstall directly."
@@ -64,6 +100,7 @@ show_uninstall_help() {
     echo "  mo uninstall                   Open interactive app selector"
     echo "  mo uninstall slack             Uninstall Slack"
     echo "  mo uninstall slack zoom        Uninstall Slack and Zoom"
+    echo "  mo uninstall com.tinyspeck.slackmacgap  Uninstall by bundle ID"
     echo "  mo uninstall --dry-run slack   Preview Slack uninstallation"
     echo "  mo uninstall --list            Show installed apps and the names mo uninstall accepts"
     echo ""
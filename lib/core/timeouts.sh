@@ -24,6 +24,9 @@
 #                     plist lint). Per-listing finds are already capped; this is
 #                     the cumulative wall-clock ceiling for the whole walk so it
 #                     can never appear hung. ~15s.
+#   SPOTLIGHT_QUERY   An mdfind query over a whole folder rather than one
+#                     bundle ID (screenshot discovery); a cold or rebuilding
+#                     Spotlight index answers slowly. ~10s.
 #
 # Migration: new code should use these constants. Existing call sites can
 # be migrated incrementally; the script `grep 'run_with_timeout [0-9]'` lists
@@ -65,3 +68,4 @@ readonly MOLE_TIMEOUT_PKG_LIST_SEC="${MOLE_TIMEOUT_PKG_LIST_SEC:-10}"
 readonly MOLE_TIMEOUT_PKG_CLEANUP_SEC="${MOLE_TIMEOUT_PKG_CLEANUP_SEC:-20}"
 readonly MOLE_TIMEOUT_DISK_VERIFY_SEC="${MOLE_TIMEOUT_DISK_VERIFY_SEC:-30}"
 readonly MOLE_TIMEOUT_HINT_SCAN_SEC="${MOLE_TIMEOUT_HINT_SCAN_SEC:-15}"
+readonly MOLE_TIMEOUT_SPOTLIGHT_QUERY_SEC="${MOLE_TIMEOUT_SPOTLIGHT_QUERY_SEC:-10}"
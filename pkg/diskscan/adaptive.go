@@ -0,0 +1,138 @@
+//go:build darwin || linux
+
+package diskscan
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tw93/mole/internal/debuglog"
+)
+
+// tuner scales the entry and directory worker pools to the storage under
+// the scan. The CPU-based defaults suit an internal SSD but stall a USB
+// disk or network share, where every extra walker only lengthens the
+// queue, and leave a fast NVMe drive idle.
+//
+// Each pool's channel is allocated at its ceiling; the tuner lowers the
+// limit by holding tokens itself and raises it by handing them back, so the
+// walkers keep their plain channel sends. Every tuneInterval it looks at
+// the mean ReadDir time since the last look: under fastReadDir it frees one
+// token per pool, over slowReadDir it halves each pool's limit.
+type tuner struct {
+	pools []*tunedPool
+
+	// calls and nanos add up the ReadDir calls timed since the last look.
+	calls atomic.Int64
+	nanos atomic.Int64
+}
+
+type tunedPool struct {
+	name   string
+	sem    chan struct{}
+	min    int
+	parked int
+}
+
+func (p *tunedPool) limit() int { return cap(p.sem) - p.parked }
+
+// tryPark takes one free token out of use.
+func (p *tunedPool) tryPark() bool {
+	select {
+	case p.sem <- struct{}{}:
+		p.parked++
+		return true
+	default:
+		return false
+	}
+}
+
+// newTuner replaces the entry pool, when tuneEntry is set, and the
+// directory pool, when tuneDir is, with ones that can grow to twice their
+// default, up to the adaptive ceilings. Both start at the default.
+func newTuner(l *scanLimiter, tuneEntry, tuneDir bool) *tuner {
+	t := &tuner{}
+	add := func(name string, sem *chan struct{}, ceiling int) {
+		start := cap(*sem)
+		p := &tunedPool{name: name, sem: make(chan struct{}, max(min(2*start, ceiling), start)), min: 1}
+		for p.limit() > start && p.tryPark() {
+		}
+		*sem = p.sem
+		t.pools = append(t.pools, p)
+	}
+	if tuneEntry {
+		add("entry", &l.entrySem, adaptiveMaxWorkers)
+	}
+	if tuneDir {
+		add("dir", &l.dirSem, adaptiveMaxDirWorkers)
+	}
+	if len(t.pools) == 0 {
+		return nil
+	}
+	return t
+}
+
+// observe records one ReadDir call.
+func (t *tuner) observe(took time.Duration) {
+	t.calls.Add(1)
+	t.nanos.Add(int64(took))
+}
+
+// start tunes the pools until the returned func runs. A nil tuner does
+// nothing.
+func (t *tuner) start() (stop func()) {
+	if t == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ticker := time.NewTicker(tuneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				t.tune()
+			}
+		}
+	})
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// tune adjusts the pools once from the ReadDir times since the last call.
+func (t *tuner) tune() {
+	calls, nanos := t.calls.Swap(0), t.nanos.Swap(0)
+	if calls < tuneMinSamples {
+		return
+	}
+	mean := time.Duration(nanos / calls)
+	changed := false
+	for _, p := range t.pools {
+		before := p.limit()
+		switch {
+		case mean < fastReadDir && p.parked > 0:
+			<-p.sem
+			p.parked--
+		case mean > slowReadDir:
+			// Parking never blocks: a token in use now is parked on a
+			// later tick if the storage is still slow.
+			target := max(before/2, p.min)
+			for p.limit() > target && p.tryPark() {
+			}
+		}
+		changed = changed || p.limit() != before
+	}
+	if changed && debuglog.Enabled() {
+		attrs := make([]any, 0, 2*len(t.pools))
+		for _, p := range t.pools {
+			attrs = append(attrs, p.name, p.limit())
+		}
+		debuglog.Phase("scan workers", mean, attrs...)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build darwin || linux
+
+package diskscan
+
+import "time"
+
+const day = 24 * time.Hour
+
+// AgeBuckets bound the buckets of Ages: a file modified under
+// AgeBuckets[0] ago goes in the first, one modified over AgeBuckets[3] ago
+// in the last.
+var AgeBuckets = [4]time.Duration{30 * day, 90 * day, 365 * day, 3 * 365 * day}
+
+// Ages adds up the bytes of a scan's files by how long ago each was
+// modified: under 30 days, 30 to 90 days, 90 days to a year, 1 to 3 years,
+// and over 3 years. Folders sized by du rather than walked, such as
+// node_modules, are left out, so Total can fall short of TotalSize.
+type Ages [5]int64
+
+// Total returns the bytes in all the buckets.
+func (a Ages) Total() int64 {
+	var total int64
+	for _, n := range a {
+		total += n
+	}
+	return total
+}
+
+// add counts size bytes modified at mod, as of now.
+func (a *Ages) add(mod, now time.Time, size int64) {
+	age := now.Sub(mod)
+	i := 0
+	for i < len(AgeBuckets) && age >= AgeBuckets[i] {
+		i++
+	}
+	a[i] += size
+}
+
+func (a *Ages) merge(b Ages) {
+	for i, n := range b {
+		a[i] += n
+	}
+}
@@ -0,0 +1,67 @@
+//go:build darwin || linux
+
+package diskscan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScanAddsUpBytesByAge(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	now := time.Now()
+	var want Ages
+	for i, f := range []struct {
+		path string
+		age  time.Duration
+	}{
+		{"new.txt", time.Hour},
+		{"docs/month.txt", 45 * day},
+		{"docs/old/year.txt", 200 * day},
+		{"docs/old/two.txt", 2 * 365 * day},
+		{"archive/ancient.txt", 10 * 365 * day},
+		{"archive/also.txt", 4 * 365 * day},
+	} {
+		path := filepath.Join(root, f.path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, 4096*(i+1)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mod := now.Add(-f.age)
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+		info, _ := os.Stat(path)
+		want.add(mod, now, getActualFileSize(path, info))
+	}
+	if want[0] == 0 || want[1] == 0 || want[2] == 0 || want[3] == 0 || want[4] == 0 {
+		t.Fatalf("fixture leaves a bucket empty: %v", want)
+	}
+
+	result, err := New(Options{MaxEntries: DefaultMaxEntries, Cache: true}).Scan(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Ages != want || result.Ages.Total() != result.TotalSize {
+		t.Fatalf("Ages = %v (total %d), want %v (total %d)", result.Ages, result.Ages.Total(), want, result.TotalSize)
+	}
+
+	// Live reuses the subtrees Scan cached.
+	live, err := New(Options{Cache: true}).Live(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last Event
+	for ev := range live.Events {
+		last = ev
+	}
+	if last.Result.Ages != want {
+		t.Fatalf("Live Ages = %v, want %v", last.Result.Ages, want)
+	}
+}
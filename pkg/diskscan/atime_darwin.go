@@ -0,0 +1,15 @@
+package diskscan
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+func getLastAccessTimeFromInfo(info fs.FileInfo) time.Time {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}
+	}
+	return time.Unix(stat.Atimespec.Sec, stat.Atimespec.Nsec)
+}
@@ -0,0 +1,550 @@
+//go:build darwin || linux
+
+package diskscan
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/tw93/mole/internal/debuglog"
+)
+
+// cacheSchemaVersion is bumped whenever directory-size semantics change so
+// stale on-disk cache entries are rejected instead of silently reused.
+// v2: analyze deduplicates hardlinked files to match `du`.
+// v3: entries record the directory they describe.
+// v4: entries list sparse files.
+// v5: entries add up bytes by age.
+const cacheSchemaVersion = 5
+
+// CacheEntry is a cached scan of one directory.
+type CacheEntry struct {
+	// Path is the directory scanned. The file is named for its hash, so a
+	// load checks it rather than trusting the name.
+	Path         string
+	Entries      []Entry
+	LargeFiles   []File
+	SparseFiles  []SparseFile
+	Ages         Ages
+	TotalSize    int64
+	TotalFiles   int64
+	ModTime      time.Time
+	ScanTime     time.Time
+	NeedsRefresh bool
+	// SchemaVersion guards against reusing cache written by an older binary
+	// with different sizing semantics. Entries not at cacheSchemaVersion are
+	// rejected on load. Old caches decode this as 0.
+	SchemaVersion int
+}
+
+type overviewSizeSnapshot struct {
+	Size    int64     `json:"size"`
+	Updated time.Time `json:"updated"`
+}
+
+// The snapshot file is read once and kept in memory. overviewSnapshotFrom
+// names the file it came from, so a changed HOME (tests, mostly) reloads.
+var (
+	overviewSnapshotMu    sync.Mutex
+	overviewSnapshotCache map[string]overviewSizeSnapshot
+	overviewSnapshotFrom  string
+)
+
+func ensureOverviewSnapshotCacheLocked() error {
+	storePath, err := getOverviewSizeStorePath()
+	if err != nil {
+		return err
+	}
+	if overviewSnapshotFrom == storePath {
+		return nil
+	}
+	data, err := os.ReadFile(storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			overviewSnapshotCache = make(map[string]overviewSizeSnapshot)
+			overviewSnapshotFrom = storePath
+			return nil
+		}
+		return err
+	}
+	if len(data) == 0 {
+		overviewSnapshotCache = make(map[string]overviewSizeSnapshot)
+		overviewSnapshotFrom = storePath
+		return nil
+	}
+	var snapshots map[string]overviewSizeSnapshot
+	if err := json.Unmarshal(data, &snapshots); err != nil || snapshots == nil {
+		backupPath := storePath + ".corrupt"
+		_ = os.Rename(storePath, backupPath)
+		overviewSnapshotCache = make(map[string]overviewSizeSnapshot)
+		overviewSnapshotFrom = storePath
+		return nil
+	}
+	overviewSnapshotCache = snapshots
+	overviewSnapshotFrom = storePath
+	return nil
+}
+
+func getOverviewSizeStorePath() (string, error) {
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, overviewCacheFile), nil
+}
+
+// StoredSize returns the size Measure or StoreSize last recorded for path,
+// if it is less than a week old.
+func StoredSize(path string) (int64, error) {
+	if path == "" {
+		return 0, fmt.Errorf("empty path")
+	}
+	overviewSnapshotMu.Lock()
+	defer overviewSnapshotMu.Unlock()
+	if err := ensureOverviewSnapshotCacheLocked(); err != nil {
+		return 0, err
+	}
+	if overviewSnapshotCache == nil {
+		return 0, fmt.Errorf("snapshot cache unavailable")
+	}
+	if snapshot, ok := overviewSnapshotCache[path]; ok && snapshot.Size > 0 {
+		if time.Since(snapshot.Updated) < overviewCacheTTL {
+			return snapshot.Size, nil
+		}
+		return 0, fmt.Errorf("snapshot expired")
+	}
+	return 0, fmt.Errorf("snapshot not found")
+}
+
+// StoreSize records size for path, for later StoredSize calls.
+func StoreSize(path string, size int64) error {
+	if path == "" || size <= 0 {
+		return fmt.Errorf("invalid overview size")
+	}
+	overviewSnapshotMu.Lock()
+	defer overviewSnapshotMu.Unlock()
+	if err := ensureOverviewSnapshotCacheLocked(); err != nil {
+		return err
+	}
+	if overviewSnapshotCache == nil {
+		overviewSnapshotCache = make(map[string]overviewSizeSnapshot)
+	}
+	overviewSnapshotCache[path] = overviewSizeSnapshot{
+		Size:    size,
+		Updated: time.Now(),
+	}
+	return persistOverviewSnapshotLocked()
+}
+
+func persistOverviewSnapshotLocked() error {
+	storePath, err := getOverviewSizeStorePath()
+	if err != nil {
+		return err
+	}
+	tmpPath := storePath + ".tmp"
+	data, err := json.MarshalIndent(overviewSnapshotCache, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, storePath)
+}
+
+// CachedSize returns path's stored size, or failing that the total of its
+// cached scan.
+func CachedSize(path string) (int64, error) {
+	if path == "" {
+		return 0, fmt.Errorf("empty path")
+	}
+	if snapshot, err := StoredSize(path); err == nil {
+		return snapshot, nil
+	}
+	CacheEntry, err := LoadCache(path)
+	if err != nil {
+		return 0, err
+	}
+	_ = StoreSize(path, CacheEntry.TotalSize)
+	return CacheEntry.TotalSize, nil
+}
+
+func getCacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	cacheDir := filepath.Join(home, ".cache", "mole")
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", err
+	}
+	return cacheDir, nil
+}
+
+func getCachePath(path string) (string, error) {
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return "", err
+	}
+	hash := xxhash.Sum64String(path)
+	filename := fmt.Sprintf("%x.cache", hash)
+	return filepath.Join(cacheDir, filename), nil
+}
+
+// PruneCache deletes cache files older than a week, then the oldest of
+// the rest until they fit in CacheBudget.
+func PruneCache() {
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return
+	}
+	// Pruning is best-effort; errors are intentionally ignored to avoid blocking startup.
+	_ = pruneAnalyzerCacheDir(cacheDir, time.Now())
+	_ = trimCacheDir(cacheDir, CacheBudget)
+}
+
+// trimCacheDir deletes the least recently written cache files in cacheDir
+// until the rest add up to budget bytes or less.
+func trimCacheDir(cacheDir string, budget int64) error {
+	files, err := cacheFiles(cacheDir)
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, f := range files {
+		total += f.Size()
+	}
+	slices.SortFunc(files, func(a, b os.FileInfo) int { return a.ModTime().Compare(b.ModTime()) })
+	for _, f := range files {
+		if total <= budget {
+			break
+		}
+		if os.Remove(filepath.Join(cacheDir, f.Name())) == nil {
+			total -= f.Size()
+		}
+	}
+	return nil
+}
+
+// cacheFiles lists the regular .cache files in cacheDir.
+func cacheFiles(cacheDir string) ([]os.FileInfo, error) {
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var files []os.FileInfo
+	for _, entry := range entries {
+		if entry.Type()&os.ModeSymlink != 0 || filepath.Ext(entry.Name()) != ".cache" {
+			continue
+		}
+		if info, err := entry.Info(); err == nil && info.Mode().IsRegular() {
+			files = append(files, info)
+		}
+	}
+	return files, nil
+}
+
+func pruneAnalyzerCacheDir(cacheDir string, now time.Time) error {
+	if cacheDir == "" || analyzerCacheTTL <= 0 {
+		return nil
+	}
+
+	files, err := cacheFiles(cacheDir)
+	if err != nil {
+		return err
+	}
+
+	cutoff := now.Add(-analyzerCacheTTL)
+	for _, info := range files {
+		if info.ModTime().Before(cutoff) {
+			_ = os.Remove(filepath.Join(cacheDir, info.Name()))
+		}
+	}
+
+	return nil
+}
+
+func loadRawCacheFromDisk(path string) (*CacheEntry, error) {
+	cachePath, err := getCachePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := decodeCacheFile(cachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.SchemaVersion != cacheSchemaVersion {
+		return nil, fmt.Errorf("cache schema mismatch: got %d, want %d", entry.SchemaVersion, cacheSchemaVersion)
+	}
+	if entry.Path != path {
+		return nil, fmt.Errorf("cache belongs to %s", entry.Path)
+	}
+
+	return entry, nil
+}
+
+// LoadCache returns the cached scan of path unless it has expired: it is
+// over a week old, or path changed since and the scan is over a day old.
+func LoadCache(path string) (entry *CacheEntry, err error) {
+	defer func() {
+		if err != nil {
+			debuglog.Cache("scan "+path, false, 0)
+		} else {
+			debuglog.Cache("scan "+path, true, time.Since(entry.ScanTime))
+		}
+	}()
+
+	entry, err = loadRawCacheFromDisk(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	scanAge := time.Since(entry.ScanTime)
+	if scanAge > analyzerCacheTTL {
+		return nil, fmt.Errorf("cache expired: too old")
+	}
+
+	if info.ModTime().After(entry.ModTime) {
+		// Allow grace window.
+		if cacheModTimeGrace <= 0 || info.ModTime().Sub(entry.ModTime) > cacheModTimeGrace {
+			// Directory mod time is noisy on macOS; reuse recent cache to avoid
+			// frequent full rescans while still forcing refresh for older entries.
+			if cacheReuseWindow <= 0 || scanAge > cacheReuseWindow {
+				return nil, fmt.Errorf("cache expired: directory modified")
+			}
+		}
+	}
+
+	return entry, nil
+}
+
+// LoadStaleCache loads cache without strict freshness checks.
+// It is used for fast first paint before triggering a background refresh.
+func LoadStaleCache(path string) (*CacheEntry, error) {
+	entry, err := loadRawCacheFromDisk(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	if time.Since(entry.ScanTime) > staleCacheTTL {
+		return nil, fmt.Errorf("stale cache expired")
+	}
+
+	return entry, nil
+}
+
+// SaveCache caches result as the scan of path.
+func SaveCache(path string, result Result) error {
+	return saveCacheToDiskWithOptions(path, result, false)
+}
+
+// SaveWarmedCache caches result as a provisional scan of path, the kind
+// Scan writes for subdirectories. Its CacheEntry has NeedsRefresh set, so
+// callers can show it at once and rescan behind it.
+func SaveWarmedCache(path string, result Result) error {
+	return saveCacheToDiskWithOptions(path, result, true)
+}
+
+func saveCacheToDiskWithOptions(path string, result Result, needsRefresh bool) error {
+	cachePath, err := getCachePath(path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	entry := CacheEntry{
+		Path:          path,
+		Entries:       result.Entries,
+		LargeFiles:    result.LargeFiles,
+		SparseFiles:   result.SparseFiles,
+		Ages:          result.Ages,
+		TotalSize:     result.TotalSize,
+		TotalFiles:    result.TotalFiles,
+		ModTime:       info.ModTime(),
+		ScanTime:      time.Now(),
+		NeedsRefresh:  needsRefresh,
+		SchemaVersion: cacheSchemaVersion,
+	}
+
+	file, err := os.Create(cachePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close() //nolint:errcheck
+
+	encoder := gob.NewEncoder(file)
+	return encoder.Encode(entry)
+}
+
+// CachedFileCount reads the file count of path's cached scan, ignoring
+// expiry, to estimate the progress of a new scan.
+func CachedFileCount(path string) (int64, error) {
+	cachePath, err := getCachePath(path)
+	if err != nil {
+		return 0, err
+	}
+	entry, err := decodeCacheFile(cachePath)
+	if err != nil {
+		return 0, err
+	}
+	return entry.TotalFiles, nil
+}
+
+// InvalidateCache forgets the cached scan and stored size of path.
+func InvalidateCache(path string) {
+	cachePath, err := getCachePath(path)
+	if err == nil {
+		_ = os.Remove(cachePath)
+	}
+	removeOverviewSnapshot(path)
+}
+
+// InvalidateCacheTree invalidates the cache for path and all its direct
+// child directories so that a rescan does not reuse stale subdirectory
+// sizes. See #812.
+func InvalidateCacheTree(path string) {
+	InvalidateCache(path)
+	children, err := os.ReadDir(path)
+	if err != nil {
+		return
+	}
+	for _, child := range children {
+		if child.IsDir() {
+			InvalidateCache(filepath.Join(path, child.Name()))
+		}
+	}
+}
+
+func removeOverviewSnapshot(path string) {
+	if path == "" {
+		return
+	}
+	overviewSnapshotMu.Lock()
+	defer overviewSnapshotMu.Unlock()
+	if err := ensureOverviewSnapshotCacheLocked(); err != nil {
+		return
+	}
+	if overviewSnapshotCache == nil {
+		return
+	}
+	if _, ok := overviewSnapshotCache[path]; ok {
+		delete(overviewSnapshotCache, path)
+		_ = persistOverviewSnapshotLocked()
+	}
+}
+
+// CachedScan describes one cache file, for `mole cache ls`.
+type CachedScan struct {
+	// Path is the directory scanned; "" when the file is from an older
+	// Mole and is never read, only pruned.
+	Path string
+	// File is the cache file, and Bytes its size on disk.
+	File  string
+	Bytes int64
+	// TotalSize and ScanTime come from the scan.
+	TotalSize int64
+	ScanTime  time.Time
+	// Expired is set once the scan is past CacheTTL. The next
+	// PruneCache deletes it.
+	Expired bool
+}
+
+// CacheDir returns the directory the scan cache lives in, creating it.
+func CacheDir() (string, error) { return getCacheDir() }
+
+// ListCache describes every cache file, sorted by path.
+func ListCache() ([]CachedScan, error) {
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := cacheFiles(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	scans := make([]CachedScan, 0, len(files))
+	for _, f := range files {
+		scan := CachedScan{File: filepath.Join(cacheDir, f.Name()), Bytes: f.Size(), ScanTime: f.ModTime()}
+		if entry, err := decodeCacheFile(scan.File); err == nil && entry.SchemaVersion == cacheSchemaVersion {
+			scan.Path, scan.TotalSize, scan.ScanTime = entry.Path, entry.TotalSize, entry.ScanTime
+		}
+		scan.Expired = scan.Path == "" || now.Sub(scan.ScanTime) > CacheTTL
+		scans = append(scans, scan)
+	}
+	slices.SortFunc(scans, func(a, b CachedScan) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return scans, nil
+}
+
+func decodeCacheFile(name string) (*CacheEntry, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() //nolint:errcheck
+	var entry CacheEntry
+	if err := gob.NewDecoder(file).Decode(&entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// ClearCache deletes every cached scan and stored size. It returns how
+// many files it removed and the bytes they took.
+func ClearCache() (files int, bytes int64, err error) {
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return 0, 0, err
+	}
+	infos, err := cacheFiles(cacheDir)
+	if err != nil {
+		return 0, 0, err
+	}
+	overviewSnapshotMu.Lock()
+	defer overviewSnapshotMu.Unlock()
+	for _, name := range []string{overviewCacheFile, overviewCacheFile + ".corrupt"} {
+		if info, statErr := os.Stat(filepath.Join(cacheDir, name)); statErr == nil && info.Mode().IsRegular() {
+			infos = append(infos, info)
+		}
+	}
+	var errs []error
+	for _, info := range infos {
+		if removeErr := os.Remove(filepath.Join(cacheDir, info.Name())); removeErr != nil {
+			errs = append(errs, removeErr)
+			continue
+		}
+		files++
+		bytes += info.Size()
+	}
+	overviewSnapshotCache, overviewSnapshotFrom = nil, ""
+	return files, bytes, errors.Join(errs...)
+}
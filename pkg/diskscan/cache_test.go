@@ -0,0 +1,530 @@
+//go:build darwin || linux
+
+package diskscan
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// forgetOverviewSnapshot drops the in-memory snapshot so the next read
+// reloads it from disk.
+func forgetOverviewSnapshot() {
+	overviewSnapshotMu.Lock()
+	overviewSnapshotCache = nil
+	overviewSnapshotFrom = ""
+	overviewSnapshotMu.Unlock()
+}
+
+func TestStoreSizeAndStoredSize(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path := filepath.Join(home, "project")
+	want := int64(123456)
+
+	if err := StoreSize(path, want); err != nil {
+		t.Fatalf("StoreSize: %v", err)
+	}
+
+	got, err := StoredSize(path)
+	if err != nil {
+		t.Fatalf("StoredSize: %v", err)
+	}
+	if got != want {
+		t.Fatalf("snapshot mismatch: want %d, got %d", want, got)
+	}
+
+	// Reload from disk and ensure value persists.
+	forgetOverviewSnapshot()
+	got, err = StoredSize(path)
+	if err != nil {
+		t.Fatalf("StoredSize after reset: %v", err)
+	}
+	if got != want {
+		t.Fatalf("snapshot mismatch after reset: want %d, got %d", want, got)
+	}
+}
+
+func TestCacheSaveLoadRoundTrip(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	target := filepath.Join(home, "cache-target")
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		t.Fatalf("create target dir: %v", err)
+	}
+
+	result := Result{
+		Entries: []Entry{
+			{Name: "alpha", Path: filepath.Join(target, "alpha"), Size: 10, IsDir: true},
+		},
+		LargeFiles: []File{
+			{Name: "big.bin", Path: filepath.Join(target, "big.bin"), Size: 2048},
+		},
+		TotalSize: 42,
+	}
+
+	if err := SaveCache(target, result); err != nil {
+		t.Fatalf("SaveCache: %v", err)
+	}
+
+	cache, err := LoadCache(target)
+	if err != nil {
+		t.Fatalf("LoadCache: %v", err)
+	}
+	if cache.TotalSize != result.TotalSize {
+		t.Fatalf("total size mismatch: want %d, got %d", result.TotalSize, cache.TotalSize)
+	}
+	if len(cache.Entries) != len(result.Entries) {
+		t.Fatalf("entry count mismatch: want %d, got %d", len(result.Entries), len(cache.Entries))
+	}
+	if len(cache.LargeFiles) != len(result.LargeFiles) {
+		t.Fatalf("large file count mismatch: want %d, got %d", len(result.LargeFiles), len(cache.LargeFiles))
+	}
+}
+
+func TestPruneCacheDirRemovesOnlyExpiredCacheFiles(t *testing.T) {
+	cacheDir := t.TempDir()
+	now := time.Now()
+	oldTime := now.Add(-analyzerCacheTTL - time.Hour)
+	freshTime := now.Add(-time.Hour)
+
+	oldCache := filepath.Join(cacheDir, "old.cache")
+	freshCache := filepath.Join(cacheDir, "fresh.cache")
+	namedState := filepath.Join(cacheDir, overviewCacheFile)
+	cacheDirEntry := filepath.Join(cacheDir, "directory.cache")
+	symlinkTarget := filepath.Join(cacheDir, "target")
+	symlinkCache := filepath.Join(cacheDir, "link.cache")
+
+	for _, path := range []string{oldCache, freshCache, namedState, symlinkTarget} {
+		if err := os.WriteFile(path, []byte("cache"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	if err := os.Mkdir(cacheDirEntry, 0o755); err != nil {
+		t.Fatalf("mkdir cache dir entry: %v", err)
+	}
+	if err := os.Symlink(symlinkTarget, symlinkCache); err != nil {
+		t.Fatalf("symlink cache entry: %v", err)
+	}
+
+	for _, path := range []string{oldCache, namedState, cacheDirEntry, symlinkCache} {
+		if err := os.Chtimes(path, oldTime, oldTime); err != nil {
+			t.Fatalf("chtimes %s: %v", path, err)
+		}
+	}
+	if err := os.Chtimes(freshCache, freshTime, freshTime); err != nil {
+		t.Fatalf("chtimes fresh cache: %v", err)
+	}
+
+	if err := pruneAnalyzerCacheDir(cacheDir, now); err != nil {
+		t.Fatalf("pruneAnalyzerCacheDir: %v", err)
+	}
+
+	if _, err := os.Stat(oldCache); !os.IsNotExist(err) {
+		t.Fatalf("expected expired cache file to be removed, stat err: %v", err)
+	}
+	for _, path := range []string{freshCache, namedState, cacheDirEntry, symlinkCache} {
+		if _, err := os.Lstat(path); err != nil {
+			t.Fatalf("expected %s to be preserved: %v", path, err)
+		}
+	}
+}
+
+func TestPruneCacheDirMissingDirectory(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	if err := pruneAnalyzerCacheDir(missing, time.Now()); err != nil {
+		t.Fatalf("expected missing cache dir to be ignored, got: %v", err)
+	}
+}
+
+func TestPruneCacheDirIgnoresRemoveFailures(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can remove files from read-only directories")
+	}
+
+	cacheDir := t.TempDir()
+	oldCache := filepath.Join(cacheDir, "old.cache")
+	if err := os.WriteFile(oldCache, []byte("cache"), 0o644); err != nil {
+		t.Fatalf("write old cache: %v", err)
+	}
+	oldTime := time.Now().Add(-analyzerCacheTTL - time.Hour)
+	if err := os.Chtimes(oldCache, oldTime, oldTime); err != nil {
+		t.Fatalf("chtimes old cache: %v", err)
+	}
+
+	if err := os.Chmod(cacheDir, 0o555); err != nil {
+		t.Fatalf("chmod cache dir read-only: %v", err)
+	}
+	defer func() {
+		_ = os.Chmod(cacheDir, 0o755)
+	}()
+
+	if err := pruneAnalyzerCacheDir(cacheDir, time.Now()); err != nil {
+		t.Fatalf("expected remove failure to be ignored, got: %v", err)
+	}
+	if _, err := os.Stat(oldCache); err != nil {
+		t.Fatalf("expected failed removal to leave cache file in place: %v", err)
+	}
+}
+
+func TestLoadCacheExpiresWhenDirectoryChanges(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	target := filepath.Join(home, "change-target")
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		t.Fatalf("create target: %v", err)
+	}
+
+	result := Result{TotalSize: 5}
+	if err := SaveCache(target, result); err != nil {
+		t.Fatalf("SaveCache: %v", err)
+	}
+
+	// Advance mtime beyond grace period.
+	time.Sleep(time.Millisecond * 10)
+	if err := os.Chtimes(target, time.Now(), time.Now()); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	// Simulate older cache entry to exceed grace window.
+	cachePath, err := getCachePath(target)
+	if err != nil {
+		t.Fatalf("getCachePath: %v", err)
+	}
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Fatalf("stat cache: %v", err)
+	}
+	oldTime := time.Now().Add(-cacheModTimeGrace - time.Minute)
+	if err := os.Chtimes(cachePath, oldTime, oldTime); err != nil {
+		t.Fatalf("chtimes cache: %v", err)
+	}
+
+	file, err := os.Open(cachePath)
+	if err != nil {
+		t.Fatalf("open cache: %v", err)
+	}
+	var entry CacheEntry
+	if err := gob.NewDecoder(file).Decode(&entry); err != nil {
+		t.Fatalf("decode cache: %v", err)
+	}
+	_ = file.Close()
+
+	entry.ScanTime = time.Now().Add(-8 * 24 * time.Hour)
+
+	tmp := cachePath + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		t.Fatalf("create tmp cache: %v", err)
+	}
+	if err := gob.NewEncoder(f).Encode(&entry); err != nil {
+		t.Fatalf("encode tmp cache: %v", err)
+	}
+	_ = f.Close()
+	if err := os.Rename(tmp, cachePath); err != nil {
+		t.Fatalf("rename tmp cache: %v", err)
+	}
+
+	if _, err := LoadCache(target); err == nil {
+		t.Fatalf("expected cache load to fail after stale scan time")
+	}
+}
+
+func TestLoadCacheReusesRecentEntryAfterDirectoryChanges(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	target := filepath.Join(home, "recent-change-target")
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		t.Fatalf("create target: %v", err)
+	}
+
+	result := Result{TotalSize: 5, TotalFiles: 1}
+	if err := SaveCache(target, result); err != nil {
+		t.Fatalf("SaveCache: %v", err)
+	}
+
+	cachePath, err := getCachePath(target)
+	if err != nil {
+		t.Fatalf("getCachePath: %v", err)
+	}
+
+	file, err := os.Open(cachePath)
+	if err != nil {
+		t.Fatalf("open cache: %v", err)
+	}
+	var entry CacheEntry
+	if err := gob.NewDecoder(file).Decode(&entry); err != nil {
+		t.Fatalf("decode cache: %v", err)
+	}
+	_ = file.Close()
+
+	// Make cache entry look recently scanned, but older than mod time grace.
+	entry.ModTime = time.Now().Add(-2 * time.Hour)
+	entry.ScanTime = time.Now().Add(-1 * time.Hour)
+
+	tmp := cachePath + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		t.Fatalf("create tmp cache: %v", err)
+	}
+	if err := gob.NewEncoder(f).Encode(&entry); err != nil {
+		t.Fatalf("encode tmp cache: %v", err)
+	}
+	_ = f.Close()
+	if err := os.Rename(tmp, cachePath); err != nil {
+		t.Fatalf("rename tmp cache: %v", err)
+	}
+
+	if err := os.Chtimes(target, time.Now(), time.Now()); err != nil {
+		t.Fatalf("chtimes target: %v", err)
+	}
+
+	if _, err := LoadCache(target); err != nil {
+		t.Fatalf("expected recent cache to be reused, got error: %v", err)
+	}
+}
+
+func TestLoadCacheExpiresWhenModifiedAndReuseWindowPassed(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	target := filepath.Join(home, "reuse-window-target")
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		t.Fatalf("create target: %v", err)
+	}
+
+	result := Result{TotalSize: 5, TotalFiles: 1}
+	if err := SaveCache(target, result); err != nil {
+		t.Fatalf("SaveCache: %v", err)
+	}
+
+	cachePath, err := getCachePath(target)
+	if err != nil {
+		t.Fatalf("getCachePath: %v", err)
+	}
+
+	file, err := os.Open(cachePath)
+	if err != nil {
+		t.Fatalf("open cache: %v", err)
+	}
+	var entry CacheEntry
+	if err := gob.NewDecoder(file).Decode(&entry); err != nil {
+		t.Fatalf("decode cache: %v", err)
+	}
+	_ = file.Close()
+
+	// Within overall 7-day TTL but beyond reuse window.
+	entry.ModTime = time.Now().Add(-48 * time.Hour)
+	entry.ScanTime = time.Now().Add(-(cacheReuseWindow + time.Hour))
+
+	tmp := cachePath + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		t.Fatalf("create tmp cache: %v", err)
+	}
+	if err := gob.NewEncoder(f).Encode(&entry); err != nil {
+		t.Fatalf("encode tmp cache: %v", err)
+	}
+	_ = f.Close()
+	if err := os.Rename(tmp, cachePath); err != nil {
+		t.Fatalf("rename tmp cache: %v", err)
+	}
+
+	if err := os.Chtimes(target, time.Now(), time.Now()); err != nil {
+		t.Fatalf("chtimes target: %v", err)
+	}
+
+	if _, err := LoadCache(target); err == nil {
+		t.Fatalf("expected cache load to fail after reuse window passes")
+	}
+}
+
+func TestLoadStaleCacheAllowsRecentExpiredCache(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	target := filepath.Join(home, "stale-cache-target")
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		t.Fatalf("create target: %v", err)
+	}
+
+	result := Result{TotalSize: 7, TotalFiles: 2}
+	if err := SaveCache(target, result); err != nil {
+		t.Fatalf("SaveCache: %v", err)
+	}
+
+	cachePath, err := getCachePath(target)
+	if err != nil {
+		t.Fatalf("getCachePath: %v", err)
+	}
+	file, err := os.Open(cachePath)
+	if err != nil {
+		t.Fatalf("open cache: %v", err)
+	}
+	var entry CacheEntry
+	if err := gob.NewDecoder(file).Decode(&entry); err != nil {
+		t.Fatalf("decode cache: %v", err)
+	}
+	_ = file.Close()
+
+	// Expired for normal cache validation but still inside stale fallback window.
+	entry.ModTime = time.Now().Add(-48 * time.Hour)
+	entry.ScanTime = time.Now().Add(-48 * time.Hour)
+
+	tmp := cachePath + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		t.Fatalf("create tmp cache: %v", err)
+	}
+	if err := gob.NewEncoder(f).Encode(&entry); err != nil {
+		t.Fatalf("encode tmp cache: %v", err)
+	}
+	_ = f.Close()
+	if err := os.Rename(tmp, cachePath); err != nil {
+		t.Fatalf("rename tmp cache: %v", err)
+	}
+
+	if err := os.Chtimes(target, time.Now(), time.Now()); err != nil {
+		t.Fatalf("chtimes target: %v", err)
+	}
+
+	if _, err := LoadCache(target); err == nil {
+		t.Fatalf("expected normal cache load to fail")
+	}
+	if _, err := LoadStaleCache(target); err != nil {
+		t.Fatalf("expected stale cache load to succeed, got error: %v", err)
+	}
+}
+
+func TestLoadStaleCacheExpiresByStaleTTL(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	target := filepath.Join(home, "stale-cache-expired-target")
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		t.Fatalf("create target: %v", err)
+	}
+
+	result := Result{TotalSize: 9, TotalFiles: 3}
+	if err := SaveCache(target, result); err != nil {
+		t.Fatalf("SaveCache: %v", err)
+	}
+
+	cachePath, err := getCachePath(target)
+	if err != nil {
+		t.Fatalf("getCachePath: %v", err)
+	}
+	file, err := os.Open(cachePath)
+	if err != nil {
+		t.Fatalf("open cache: %v", err)
+	}
+	var entry CacheEntry
+	if err := gob.NewDecoder(file).Decode(&entry); err != nil {
+		t.Fatalf("decode cache: %v", err)
+	}
+	_ = file.Close()
+
+	entry.ScanTime = time.Now().Add(-(staleCacheTTL + time.Hour))
+
+	tmp := cachePath + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		t.Fatalf("create tmp cache: %v", err)
+	}
+	if err := gob.NewEncoder(f).Encode(&entry); err != nil {
+		t.Fatalf("encode tmp cache: %v", err)
+	}
+	_ = f.Close()
+	if err := os.Rename(tmp, cachePath); err != nil {
+		t.Fatalf("rename tmp cache: %v", err)
+	}
+
+	if _, err := LoadStaleCache(target); err == nil {
+		t.Fatalf("expected stale cache load to fail after stale TTL")
+	}
+}
+
+func TestTrimCacheDirDropsOldestOverBudget(t *testing.T) {
+	cacheDir := t.TempDir()
+	now := time.Now()
+	for i, name := range []string{"oldest.cache", "middle.cache", "newest.cache"} {
+		path := filepath.Join(cacheDir, name)
+		if err := os.WriteFile(path, make([]byte, 100), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		at := now.Add(time.Duration(i-3) * time.Hour)
+		os.Chtimes(path, at, at)
+	}
+	os.WriteFile(filepath.Join(cacheDir, overviewCacheFile), make([]byte, 500), 0o644)
+
+	if err := trimCacheDir(cacheDir, 250); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]bool{"oldest.cache": false, "middle.cache": true, "newest.cache": true, overviewCacheFile: true} {
+		if _, err := os.Stat(filepath.Join(cacheDir, name)); (err == nil) != want {
+			t.Errorf("%s kept = %v, want %v", name, err == nil, want)
+		}
+	}
+}
+
+func TestListAndClearCache(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	forgetOverviewSnapshot()
+	defer forgetOverviewSnapshot()
+
+	target := filepath.Join(home, "Projects")
+	os.MkdirAll(target, 0o755)
+	if err := SaveCache(target, Result{TotalSize: 4096}); err != nil {
+		t.Fatal(err)
+	}
+	StoreSize(target, 4096)
+	cacheDir, _ := CacheDir()
+	os.WriteFile(filepath.Join(cacheDir, "0ld.cache"), []byte("v1"), 0o644)
+
+	scans, err := ListCache()
+	if err != nil || len(scans) != 2 {
+		t.Fatalf("ListCache = %+v, %v", scans, err)
+	}
+	if old := scans[0]; old.Path != "" || !old.Expired {
+		t.Errorf("unreadable file = %+v, want it listed as expired with no path", old)
+	}
+	if got := scans[1]; got.Path != target || got.TotalSize != 4096 || got.Expired || got.Bytes == 0 {
+		t.Errorf("scan = %+v", got)
+	}
+
+	files, _, err := ClearCache()
+	if err != nil || files != 3 {
+		t.Fatalf("ClearCache = %d files, %v; want both scans and the size store", files, err)
+	}
+	if _, err := StoredSize(target); err == nil {
+		t.Error("StoredSize still answers after ClearCache")
+	}
+	if scans, _ := ListCache(); len(scans) != 0 {
+		t.Errorf("left after clear: %+v", scans)
+	}
+}
+
+func TestLoadCacheRejectsAnotherPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	a, b := filepath.Join(home, "a"), filepath.Join(home, "b")
+	os.MkdirAll(a, 0o755)
+	os.MkdirAll(b, 0o755)
+	if err := SaveCache(a, Result{TotalSize: 1}); err != nil {
+		t.Fatal(err)
+	}
+	// Stand in for a hash collision: b's file holds a's scan.
+	fromA, _ := getCachePath(a)
+	toB, _ := getCachePath(b)
+	os.Rename(fromA, toB)
+	if _, err := LoadCache(b); err == nil {
+		t.Fatal("LoadCache returned another directory's scan")
+	}
+}
@@ -1,27 +1,31 @@
-//go:build darwin
+//go:build darwin || linux
 
-package main
+package diskscan
 
 import "time"
 
 const (
-	maxEntries             = 30
 	maxLargeFiles          = 20
-	barWidth               = 24
+	maxSparseFiles         = 20
+	sparseMinHoles         = 100 << 20
 	spotlightMinFileSize   = 100 << 20
 	largeFileWarmupMinSize = 1 << 20
-	defaultViewport        = 12
 	analyzerCacheTTL       = 7 * 24 * time.Hour
 	overviewCacheTTL       = 7 * 24 * time.Hour
 	overviewCacheFile      = "overview_sizes.json"
 	duTimeout              = 30 * time.Second
 	mdlsTimeout            = 5 * time.Second
-	maxConcurrentOverview  = 8
 	batchUpdateSize        = 100
 	cacheModTimeGrace      = 30 * time.Minute
 	cacheReuseWindow       = 24 * time.Hour
 	staleCacheTTL          = 3 * 24 * time.Hour
 
+	// CacheTTL is how long a cached scan is trusted, and CacheBudget the
+	// disk space the cache files may take before PruneCache deletes the
+	// oldest.
+	CacheTTL    = analyzerCacheTTL
+	CacheBudget = 250_000_000
+
 	// Worker pool limits. Deliberately conservative: the User Library scan
 	// blocks many goroutines in syscalls on high-fan-out trees (Steam
 	// workshop/temp, browser caches), and each blocked goroutine holds an
@@ -29,13 +33,31 @@ const (
 	// fatal "runtime: failed to create new OS thread" with no recovery.
 	// Further reduced after #765: System Library (184GB, 261k files) with
 	// deep permission checks can still exhaust threads at previous limits.
-	minWorkers         = 2
-	maxWorkers         = 12
-	cpuMultiplier      = 1
-	maxDirWorkers      = 6
-	openCommandTimeout = 10 * time.Second
-	scanSendTimeout    = 100 * time.Millisecond
-	uiTickInterval     = 100 * time.Millisecond
+	minWorkers      = 2
+	maxWorkers      = 12
+	cpuMultiplier   = 1
+	maxDirWorkers   = 6
+	scanSendTimeout = 100 * time.Millisecond
+
+	// MaxWorkers caps each of Options.Workers, DirWorkers, and FoldWorkers,
+	// well short of the thread limit above even with all three at the cap.
+	MaxWorkers = 64
+
+	// The tuner grows the entry and directory pools up to these ceilings
+	// while ReadDir stays under fastReadDir, and halves them when it goes
+	// over slowReadDir, judged every tuneInterval from at least
+	// tuneMinSamples calls.
+	adaptiveMaxWorkers    = 24
+	adaptiveMaxDirWorkers = 12
+	fastReadDir           = time.Millisecond
+	slowReadDir           = 10 * time.Millisecond
+	tuneInterval          = 200 * time.Millisecond
+	tuneMinSamples        = 8
+
+	// liveProgressInterval paces ChildProgress events during Live.
+	liveProgressInterval = 200 * time.Millisecond
+	// defaultProgressInterval paces Options.OnProgress.
+	defaultProgressInterval = 100 * time.Millisecond
 )
 
 var overviewDuIgnoreNames = map[string]bool{
@@ -263,18 +285,3 @@ var skipExtensions = map[string]bool{
 	".nim":    true,
 	".hx":     true,
 }
-
-var spinnerFrames = []string{"|", "/", "-", "\\", "|", "/", "-", "\\"}
-
-const (
-	colorPurple     = "\033[0;35m"
-	colorPurpleBold = "\033[1;35m"
-	colorGray       = "\033[0;90m"
-	colorRed        = "\033[0;31m"
-	colorYellow     = "\033[0;33m"
-	colorGreen      = "\033[0;32m"
-	colorBlue       = "\033[0;34m"
-	colorCyan       = "\033[0;36m"
-	colorReset      = "\033[0m"
-	colorBold       = "\033[1m"
-)
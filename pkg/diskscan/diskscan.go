@@ -0,0 +1,158 @@
+//go:build darwin || linux
+
+package diskscan
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries is how many entries analyze lists per directory, and
+// how many a cached subdirectory keeps.
+const DefaultMaxEntries = 30
+
+// Options configure a Scanner. The zero value scans with no entry limit,
+// no Spotlight query, and no cache.
+type Options struct {
+	// MaxEntries keeps only the largest entries of the scanned directory;
+	// 0 keeps them all.
+	MaxEntries int
+
+	// Spotlight also asks mdfind for files over 100 MB under the root, which
+	// finds large files in folded directories the walk only sizes.
+	Spotlight bool
+
+	// Cache reads and refreshes the per-directory cache in ~/.cache/mole
+	// for subdirectories, the cache mole analyze shares.
+	Cache bool
+
+	// Exclude names directories to skip wherever they appear, on top of
+	// the built-in list of VM and network mounts.
+	Exclude []string
+
+	// Workers, DirWorkers, and FoldWorkers pin the worker pools: Workers
+	// sizes that many children of the root at once, DirWorkers caps the
+	// directory walkers inside them, and FoldWorkers caps the du processes
+	// sizing folded directories. Each is capped at MaxWorkers. 0 starts the
+	// pool from the CPU count; a disk scan then grows the entry and
+	// directory pools while directory reads are fast and shrinks them when
+	// the storage is slow, such as a USB disk or network share.
+	Workers, DirWorkers, FoldWorkers int
+
+	// FollowSymlinks counts what symlinks point to instead of the links
+	// themselves, for data kept behind a link such as ~/Music ->
+	// /Volumes/Media. Every directory is walked once however many links
+	// reach it, which also ends link loops. It only applies to the disk,
+	// turns the cache off, and folded directories are still sized by du
+	// without following their links.
+	FollowSymlinks bool
+
+	// FS is the tree to scan; nil scans the disk. Any other FS is sized by
+	// walking it, without du, Spotlight, or the cache.
+	FS FS
+
+	// Progress receives the running counts. New allocates one when nil.
+	Progress *Progress
+
+	// OnProgress, when set, is called from a separate goroutine every
+	// ProgressInterval (100ms by default) while Scan runs, and once more
+	// when it returns.
+	OnProgress       func(*Progress)
+	ProgressInterval time.Duration
+}
+
+// Scanner scans directories with fixed Options. One Scanner may run
+// several scans in turn; they share its Progress.
+type Scanner struct {
+	opts Options
+	skip map[string]bool
+}
+
+// New returns a Scanner for opts.
+func New(opts Options) *Scanner {
+	if opts.Progress == nil {
+		opts.Progress = &Progress{}
+	}
+	skip := make(map[string]bool, len(opts.Exclude))
+	for _, name := range opts.Exclude {
+		if name != "" {
+			skip[name] = true
+		}
+	}
+	return &Scanner{opts: opts, skip: skip}
+}
+
+// Progress returns the counters the scanner's scans add to.
+func (s *Scanner) Progress() *Progress { return s.opts.Progress }
+
+func (s *Scanner) limiter(ctx context.Context, root string) *scanLimiter {
+	l := newScanLimiter(ctx, 0)
+	if s.opts.FS != nil && s.opts.FS != OS {
+		l.fsys, l.native = s.opts.FS, false
+	}
+	l.follow = s.opts.FollowSymlinks && l.native
+	if l.follow {
+		l.realRoot = root
+		if real, err := filepath.EvalSymlinks(root); err == nil {
+			l.realRoot = real
+		}
+	}
+	l.skip, l.cache = s.skip, s.opts.Cache && l.native && !l.follow
+	l.resize(s.opts.Workers, s.opts.DirWorkers, s.opts.FoldWorkers)
+	if l.native {
+		l.tuner = newTuner(l, s.opts.Workers == 0, s.opts.DirWorkers == 0)
+	}
+	return l
+}
+
+// Scan sizes every child of root and collects the largest files beneath
+// it. Unreadable subdirectories count as empty and are counted in
+// Result.Unreadable; only an unreadable root or a canceled ctx is an error.
+func (s *Scanner) Scan(ctx context.Context, root string) (Result, error) {
+	if s.opts.OnProgress != nil {
+		stop := s.reportProgress()
+		defer stop()
+	}
+	l := s.limiter(ctx, root)
+	defer l.tuner.start()()
+	result, err := scanPathConcurrentWithLimiter(root, s.opts.Progress, s.opts.Spotlight && l.native, s.opts.MaxEntries, l)
+	if err == nil {
+		result.Unreadable = l.denied.Load()
+		// The walk cached every subdirectory it scanned; cache the root
+		// too, as the TUI does, unless hardlink dedup made it order
+		// dependent.
+		if l.cache && !result.DedupedHardlink {
+			_ = SaveCache(root, result)
+		}
+	}
+	return result, err
+}
+
+// reportProgress calls OnProgress on a ticker until the returned func runs.
+func (s *Scanner) reportProgress() (stop func()) {
+	interval := s.opts.ProgressInterval
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				s.opts.OnProgress(s.opts.Progress)
+			}
+		}
+	})
+	return func() {
+		close(done)
+		wg.Wait()
+		s.opts.OnProgress(s.opts.Progress)
+	}
+}
@@ -0,0 +1,29 @@
+// Package diskscan is the directory scanner behind `mole analyze`: it sizes
+// a directory's children concurrently, keeps the largest entries and files
+// in bounded heaps, folds dependency and cache trees (node_modules, .git,
+// DerivedData, ...) into a single du call, and reuses a per-directory cache
+// under ~/.cache/mole between runs.
+//
+// A one-shot scan:
+//
+//	s := diskscan.New(diskscan.Options{MaxEntries: 30, Cache: true})
+//	result, err := s.Scan(ctx, "/Users/me/Projects")
+//	for _, e := range result.Entries {
+//		fmt.Println(e.Size, e.Path)
+//	}
+//
+// Options.OnProgress reports the running file, directory, and byte counts
+// while Scan works; a UI can also poll Scanner.Progress directly. Live starts
+// a scan whose top-level listing is available at once and whose directories
+// are sized in the background, delivered as Events.
+//
+// Options.FS scans some other tree through the FS interface: FromFS mounts
+// any io/fs filesystem, such as an archive or a testing/fstest.MapFS, at an
+// absolute path. Such a scan walks every directory itself; du, Spotlight,
+// and the cache only apply to the disk.
+//
+// Sizes are allocated bytes, as du reports them, with hardlinked files
+// counted once per scan. The scanner is built for macOS (it asks Spotlight
+// for large files and skips the system's virtual mounts) and also builds on
+// Linux.
+package diskscan
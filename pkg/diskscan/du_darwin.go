@@ -0,0 +1,6 @@
+package diskscan
+
+// duIgnoreArgs skips directories called name; BSD du spells it -I.
+func duIgnoreArgs(name string) []string {
+	return []string{"-I", name}
+}
@@ -0,0 +1,6 @@
+package diskscan
+
+// duIgnoreArgs skips directories called name; GNU du has no -I.
+func duIgnoreArgs(name string) []string {
+	return []string{"--exclude=" + name}
+}
@@ -1,6 +1,6 @@
-//go:build darwin
+//go:build darwin || linux
 
-package main
+package diskscan
 
 import (
 	"fmt"
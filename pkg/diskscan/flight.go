@@ -0,0 +1,65 @@
+//go:build darwin || linux
+
+package diskscan
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tw93/mole/internal/debuglog"
+)
+
+// scanGroup lets concurrent requests to size the same directory share one
+// walk. The analyze overview, its insights, and the background prefetch
+// can all ask for ~/Library at once; without it each runs its own du over
+// the same tree.
+var scanGroup flightGroup
+
+// flightGroup runs one call per key at a time: callers that ask for a key
+// already in flight wait for that call and get its result, as
+// golang.org/x/sync/singleflight does, without the dependency.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done chan struct{}
+	size int64
+	err  error
+
+	// waiters counts the callers sharing this call.
+	waiters int
+}
+
+// do returns fn's result for key, running fn only if no call for key is in
+// flight.
+func (g *flightGroup) do(key string, fn func() (int64, error)) (int64, error) {
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		c.waiters++
+		g.mu.Unlock()
+		<-c.done
+		return c.size, c.err
+	}
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	c := &flightCall{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	start := time.Now()
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		waiters := c.waiters
+		g.mu.Unlock()
+		close(c.done)
+		if waiters > 0 {
+			debuglog.Phase("shared scan", time.Since(start), "key", key, "waiters", waiters)
+		}
+	}()
+	c.size, c.err = fn()
+	return c.size, c.err
+}
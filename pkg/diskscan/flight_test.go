@@ -0,0 +1,60 @@
+//go:build darwin || linux
+
+package diskscan
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlightGroupSharesCall(t *testing.T) {
+	var g flightGroup
+	var runs atomic.Int32
+	release := make(chan struct{})
+	fn := func() (int64, error) {
+		runs.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	sizes := make([]int64, 5)
+	for i := range sizes {
+		wg.Go(func() { sizes[i], _ = g.do("/Users/me/Library", fn) })
+	}
+	// Let every caller join the first call before it finishes.
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		g.mu.Lock()
+		c := g.calls["/Users/me/Library"]
+		joined := c != nil && c.waiters == len(sizes)-1
+		g.mu.Unlock()
+		if joined {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("callers never joined the call in flight")
+		}
+	}
+	close(release)
+	wg.Wait()
+
+	if runs.Load() != 1 {
+		t.Errorf("fn ran %d times, want once", runs.Load())
+	}
+	for i, size := range sizes {
+		if size != 42 {
+			t.Errorf("caller %d got %d", i, size)
+		}
+	}
+
+	// A finished call is not cached: the next one walks again.
+	g.do("/Users/me/Library", fn)
+	if runs.Load() != 2 {
+		t.Errorf("fn ran %d times after the flight landed, want 2", runs.Load())
+	}
+	if len(g.calls) != 0 {
+		t.Errorf("calls left in flight: %v", g.calls)
+	}
+}
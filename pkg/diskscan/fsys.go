@@ -0,0 +1,85 @@
+//go:build darwin || linux
+
+package diskscan
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FS is the filesystem a Scanner walks. Names are absolute OS paths, the
+// same paths Result reports, rather than io/fs's relative slash-separated
+// names. Lstat must not follow a final symlink; symlinks are listed and
+// sized as the link itself.
+//
+// Sizes come from each fs.FileInfo: allocated blocks when Sys is a
+// *syscall.Stat_t, which also lets hardlinks count once, and Size
+// otherwise.
+type FS interface {
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Stat(name string) (fs.FileInfo, error)
+	Lstat(name string) (fs.FileInfo, error)
+}
+
+// OS is the real filesystem, what a Scanner uses when Options.FS is nil.
+// Only a scan of OS runs du and Spotlight and reads or writes the cache.
+var OS FS = osFS{}
+
+type osFS struct{}
+
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (osFS) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (osFS) Lstat(name string) (fs.FileInfo, error)     { return os.Lstat(name) }
+
+// FromFS serves fsys as an FS mounted at the absolute path root, so an
+// archive or an in-memory tree such as fstest.MapFS can be scanned like a
+// directory on disk. Lstat needs fsys to implement fs.ReadLinkFS; without
+// it, symlinks are followed.
+func FromFS(fsys fs.FS, root string) FS {
+	return mountedFS{fsys: fsys, root: filepath.Clean(root)}
+}
+
+type mountedFS struct {
+	fsys fs.FS
+	root string
+}
+
+// rel turns an absolute name under the mount point into an io/fs name.
+func (m mountedFS) rel(op, name string) (string, error) {
+	rel, err := filepath.Rel(m.root, filepath.Clean(name))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	rel = path.Clean(filepath.ToSlash(rel))
+	if !fs.ValidPath(rel) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return rel, nil
+}
+
+func (m mountedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	rel, err := m.rel("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadDir(m.fsys, rel)
+}
+
+func (m mountedFS) Stat(name string) (fs.FileInfo, error) {
+	rel, err := m.rel("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.Stat(m.fsys, rel)
+}
+
+func (m mountedFS) Lstat(name string) (fs.FileInfo, error) {
+	rel, err := m.rel("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.Lstat(m.fsys, rel)
+}
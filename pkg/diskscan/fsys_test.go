@@ -0,0 +1,123 @@
+//go:build darwin || linux
+
+package diskscan
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func memTree() fstest.MapFS {
+	return fstest.MapFS{
+		"notes.txt":                         {Data: []byte("hello")},
+		"photos/big.raw":                    {Data: make([]byte, 2<<20)},
+		"photos/small.jpg":                  {Data: make([]byte, 100)},
+		"app/node_modules/pkg/index.js":     {Data: make([]byte, 300)},
+		"app/node_modules/pkg/package.json": {Data: make([]byte, 50)},
+		"latest":                            {Data: []byte("photos"), Mode: fs.ModeSymlink},
+	}
+}
+
+func TestScanInMemoryFS(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // the cache must stay untouched
+	s := New(Options{FS: FromFS(memTree(), "/mem"), Cache: true, Spotlight: true})
+	result, err := s.Scan(context.Background(), "/mem")
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	sizes := map[string]int64{}
+	dirs := map[string]bool{}
+	for _, e := range result.Entries {
+		sizes[e.Path] = e.Size
+		dirs[e.Path] = e.IsDir
+	}
+	want := map[string]int64{
+		"/mem/photos":    2<<20 + 100,
+		"/mem/app":       350, // node_modules is folded and walked, not du'd
+		"/mem/notes.txt": 5,
+		"/mem/latest":    6, // the link itself, not photos
+	}
+	for path, size := range want {
+		if sizes[path] != size {
+			t.Errorf("size of %s = %d, want %d (entries %+v)", path, sizes[path], size, result.Entries)
+		}
+	}
+	if !dirs["/mem/latest"] {
+		t.Errorf("symlink to a directory should be listed as one")
+	}
+	if result.TotalSize != 2<<20+100+350+5+6 {
+		t.Errorf("TotalSize = %d", result.TotalSize)
+	}
+	if len(result.LargeFiles) == 0 || result.LargeFiles[0].Path != "/mem/photos/big.raw" {
+		t.Errorf("LargeFiles = %+v, want /mem/photos/big.raw first", result.LargeFiles)
+	}
+	if _, err := LoadCache("/mem/photos"); err == nil {
+		t.Errorf("an in-memory scan wrote the disk cache")
+	}
+}
+
+func TestLiveInMemoryFS(t *testing.T) {
+	s := New(Options{FS: FromFS(memTree(), "/mem")})
+	live, err := s.Live(context.Background(), "/mem")
+	if err != nil {
+		t.Fatalf("Live: %v", err)
+	}
+	if len(live.Pending) != 2 {
+		t.Fatalf("Pending = %v, want photos and app", live.Pending)
+	}
+	var last Event
+	for ev := range live.Events {
+		last = ev
+	}
+	if last.Kind != Complete || last.Result.TotalSize != 2<<20+100+350+5+6 {
+		t.Fatalf("last event = %+v", last)
+	}
+}
+
+func TestFromFSOutsideMount(t *testing.T) {
+	fsys := FromFS(memTree(), "/mem")
+	if _, err := fsys.ReadDir("/elsewhere"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadDir outside the mount: err = %v, want ErrNotExist", err)
+	}
+	if _, err := fsys.Stat("/mem/../etc"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat escaping the mount: err = %v, want ErrNotExist", err)
+	}
+	info, err := fsys.Lstat("/mem/latest")
+	if err != nil || info.Mode()&fs.ModeSymlink == 0 {
+		t.Errorf("Lstat(latest) = %v, %v; want the symlink", info, err)
+	}
+}
+
+// deniedFS refuses to list one directory, as the OS does for a folder
+// behind Full Disk Access.
+type deniedFS struct {
+	fstest.MapFS
+	denied string
+}
+
+func (d deniedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name == d.denied {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrPermission}
+	}
+	return d.MapFS.ReadDir(name)
+}
+
+func TestScanCountsUnreadable(t *testing.T) {
+	fsys := FromFS(deniedFS{memTree(), "app/node_modules"}, "/mem")
+	result, err := New(Options{FS: fsys}).Scan(context.Background(), "/mem")
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if result.Unreadable != 1 {
+		t.Errorf("Unreadable = %d, want 1", result.Unreadable)
+	}
+
+	result, err = New(Options{FS: FromFS(memTree(), "/mem")}).Scan(context.Background(), "/mem")
+	if err != nil || result.Unreadable != 0 {
+		t.Errorf("readable tree: Unreadable = %d, err = %v", result.Unreadable, err)
+	}
+}
@@ -1,16 +1,16 @@
-//go:build darwin
+//go:build darwin || linux
 
-package main
+package diskscan
 
-// entryHeap is a min-heap of dirEntry used to keep Top N largest entries.
-type entryHeap []dirEntry
+// entryHeap is a min-heap of Entry used to keep Top N largest entries.
+type entryHeap []Entry
 
 func (h entryHeap) Len() int           { return len(h) }
 func (h entryHeap) Less(i, j int) bool { return h[i].Size < h[j].Size }
 func (h entryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
 
 func (h *entryHeap) Push(x any) {
-	*h = append(*h, x.(dirEntry))
+	*h = append(*h, x.(Entry))
 }
 
 func (h *entryHeap) Pop() any {
@@ -21,15 +21,15 @@ func (h *entryHeap) Pop() any {
 	return x
 }
 
-// largeFileHeap is a min-heap for fileEntry.
-type largeFileHeap []fileEntry
+// largeFileHeap is a min-heap for File.
+type largeFileHeap []File
 
 func (h largeFileHeap) Len() int           { return len(h) }
 func (h largeFileHeap) Less(i, j int) bool { return h[i].Size < h[j].Size }
 func (h largeFileHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
 
 func (h *largeFileHeap) Push(x any) {
-	*h = append(*h, x.(fileEntry))
+	*h = append(*h, x.(File))
 }
 
 func (h *largeFileHeap) Pop() any {
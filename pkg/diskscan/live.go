@@ -0,0 +1,523 @@
+//go:build darwin || linux
+
+package diskscan
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventKind says what a Live Event reports.
+type EventKind int
+
+const (
+	// ChildProgress carries a directory's size so far in Entry. These
+	// events are dropped rather than queued when the reader falls behind.
+	ChildProgress EventKind = iota + 1
+	// ChildDone carries a finished directory in Entry and its scan in Result.
+	ChildDone
+	// Complete carries the whole scan in Result. It is the last event.
+	Complete
+	// Failed reports in Err that the directory in Entry could not be
+	// scanned. The scan goes on.
+	Failed
+	// Canceled reports that ctx was canceled. It is the last event.
+	Canceled
+)
+
+// Event is one update from a Live scan.
+type Event struct {
+	Kind   EventKind
+	Entry  Entry
+	Result Result
+	Err    error
+}
+
+// Live is a scan in progress. Its fields describe the immediate listing of
+// the root, known before any directory is sized.
+type Live struct {
+	// Entries are the children of the root. Directories still being sized
+	// have Size -1.
+	Entries []Entry
+	// Pending lists the paths of those directories.
+	Pending []string
+	// TotalSize and TotalFiles count the files directly in the root.
+	TotalSize  int64
+	TotalFiles int64
+	// LargeFiles are the largest of those files, and SparseFiles the
+	// sparse ones.
+	LargeFiles  []File
+	SparseFiles []SparseFile
+	// Events delivers the rest of the scan and is closed after Complete or
+	// Canceled.
+	Events <-chan Event
+}
+
+type liveScanTargetKind int
+
+const (
+	liveScanTargetDirectory liveScanTargetKind = iota + 1
+	liveScanTargetFoldedDirectory
+	liveScanTargetHomeLibrary
+)
+
+type liveScanTarget struct {
+	name string
+	path string
+	kind liveScanTargetKind
+}
+
+// Live lists root at once and sizes its directories in the background,
+// reporting each as it finishes. Cancel ctx to stop the scan; Events still
+// closes.
+func (s *Scanner) Live(ctx context.Context, root string) (*Live, error) {
+	limiter := s.limiter(ctx, root)
+	entries, targets, totalSize, totalFiles, largeFiles, sparseFiles, ages, err := readLiveScanInitialEntries(root, limiter)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := s.opts.Progress
+	if totalFiles > 0 {
+		progress.Files.Add(totalFiles)
+	}
+	if totalSize > 0 {
+		progress.Bytes.Add(totalSize)
+	}
+
+	events := make(chan Event, max(len(targets)*4, 1))
+	go runLiveScan(ctx, root, entries, targets, totalSize, totalFiles, largeFiles, sparseFiles, ages, limiter, progress, s.opts.MaxEntries, events)
+
+	pending := make([]string, 0, len(targets))
+	for _, target := range targets {
+		pending = append(pending, target.path)
+	}
+
+	return &Live{
+		Entries:     entries,
+		Pending:     pending,
+		TotalSize:   totalSize,
+		TotalFiles:  totalFiles,
+		LargeFiles:  largeFiles,
+		SparseFiles: sparseFiles,
+		Events:      events,
+	}, nil
+}
+
+func readLiveScanInitialEntries(root string, limiter *scanLimiter) ([]Entry, []liveScanTarget, int64, int64, []File, []SparseFile, Ages, error) {
+	children, err := limiter.fsys.ReadDir(root)
+	if err != nil {
+		return nil, nil, 0, 0, nil, nil, Ages{}, err
+	}
+	// A link back to the root is a loop like any other.
+	limiter.enterDir(root)
+
+	isRootDir := root == "/" && limiter.native
+	home := os.Getenv("HOME")
+	isHomeDir := home != "" && root == home && limiter.native
+
+	entries := make([]Entry, 0, len(children))
+	targets := make([]liveScanTarget, 0, len(children))
+	largeFiles := make([]File, 0)
+	var sparseFiles []SparseFile
+	var ages Ages
+	var totalSize int64
+	var totalFiles int64
+
+	for _, child := range children {
+		fullPath := filepath.Join(root, child.Name())
+
+		linkedDir := false
+		if child.Type()&fs.ModeSymlink != 0 {
+			if target, ok := limiter.followLink(fullPath); ok && target.IsDir() {
+				linkedDir = true
+			} else if ok {
+				size := getActualFileSize(fullPath, target)
+				totalSize += size
+				totalFiles++
+				entries = append(entries, Entry{
+					Name:       child.Name() + " →",
+					Path:       fullPath,
+					Size:       size,
+					LastAccess: getLastAccessTimeFromInfo(target),
+				})
+				continue
+			}
+		}
+		if child.Type()&fs.ModeSymlink != 0 && !linkedDir {
+			targetInfo, err := limiter.fsys.Stat(fullPath)
+			isDir := false
+			if err == nil && targetInfo.IsDir() {
+				isDir = true
+			}
+			info, err := child.Info()
+			if err != nil {
+				continue
+			}
+			size := getActualFileSize(fullPath, info)
+			totalSize += size
+			entries = append(entries, Entry{
+				Name:       child.Name() + " →",
+				Path:       fullPath,
+				Size:       size,
+				IsDir:      isDir,
+				LastAccess: getLastAccessTimeFromInfo(info),
+			})
+			continue
+		}
+
+		if child.IsDir() || linkedDir {
+			if limiter.skips(child.Name()) {
+				continue
+			}
+			if isRootDir && skipSystemDirs[child.Name()] {
+				continue
+			}
+
+			name, targetKind := child.Name(), liveScanTargetDirectory
+			switch {
+			case linkedDir:
+				name += " →"
+			case isHomeDir && child.Name() == "Library":
+				targetKind = liveScanTargetHomeLibrary
+			case shouldFoldDirWithPath(child.Name(), fullPath):
+				targetKind = liveScanTargetFoldedDirectory
+			}
+
+			entries = append(entries, Entry{
+				Name:  name,
+				Path:  fullPath,
+				Size:  -1,
+				IsDir: true,
+			})
+			targets = append(targets, liveScanTarget{
+				name: name,
+				path: fullPath,
+				kind: targetKind,
+			})
+			continue
+		}
+
+		info, err := child.Info()
+		if err != nil {
+			continue
+		}
+		size, _ := countableFileSize(info, &limiter.seen)
+		totalSize += size
+		totalFiles++
+		entries = append(entries, Entry{
+			Name:       child.Name(),
+			Path:       fullPath,
+			Size:       size,
+			IsDir:      false,
+			LastAccess: getLastAccessTimeFromInfo(info),
+		})
+		if !shouldSkipFileForLargeTracking(fullPath) && size >= largeFileWarmupMinSize {
+			largeFiles = append(largeFiles, File{Name: child.Name(), Path: fullPath, Size: size})
+		}
+		if f, ok := sparseFile(child.Name(), fullPath, info); ok {
+			sparseFiles = append(sparseFiles, f)
+		}
+		ages.add(info.ModTime(), limiter.now, size)
+	}
+
+	SortEntries(entries)
+	largeFiles = topLargeFiles(largeFiles)
+	return entries, targets, totalSize, totalFiles, largeFiles, topSparseFiles(sparseFiles), ages, nil
+}
+
+func runLiveScan(
+	ctx context.Context,
+	root string,
+	initialEntries []Entry,
+	targets []liveScanTarget,
+	initialTotalSize int64,
+	initialTotalFiles int64,
+	initialLargeFiles []File,
+	sparseFiles []SparseFile,
+	ages Ages,
+	limiter *scanLimiter,
+	progress *Progress,
+	maxEntries int,
+	events chan<- Event,
+) {
+	defer close(events)
+	defer limiter.tuner.start()()
+
+	entriesByPath := make(map[string]Entry, len(initialEntries))
+	for _, entry := range initialEntries {
+		entriesByPath[entry.Path] = entry
+	}
+
+	var totalSize atomic.Int64
+	var totalFiles atomic.Int64
+	totalSize.Store(initialTotalSize)
+	totalFiles.Store(initialTotalFiles)
+
+	largeFileChan := make(chan File, maxLargeFiles*2)
+	largeFileMinSize := int64(largeFileWarmupMinSize)
+	largeFilesDone := make(chan []File, 1)
+	go collectLiveLargeFiles(initialLargeFiles, largeFileChan, &largeFileMinSize, largeFilesDone)
+
+	var dedupedHardlink atomic.Bool
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, target := range targets {
+		if ctx.Err() != nil {
+			break
+		}
+		scanTarget := func() {
+			defer wg.Done()
+			result, err := scanLiveTargetWithProgress(ctx, target, largeFileChan, &largeFileMinSize, limiter, progress, events)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				sendLiveScanEvent(ctx, events, Event{Kind: Failed, Entry: Entry{Name: target.name, Path: target.path, IsDir: true}, Err: err})
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+
+			entry := Entry{
+				Name:  target.name,
+				Path:  target.path,
+				Size:  result.TotalSize,
+				IsDir: true,
+			}
+			mu.Lock()
+			entriesByPath[target.path] = entry
+			sparseFiles = append(sparseFiles, result.SparseFiles...)
+			ages.merge(result.Ages)
+			mu.Unlock()
+
+			totalSize.Add(result.TotalSize)
+			if result.TotalFiles > 0 {
+				totalFiles.Add(result.TotalFiles)
+			}
+			if result.DedupedHardlink {
+				dedupedHardlink.Store(true)
+			}
+			progress.Dirs.Add(1)
+			if result.TotalFiles > 0 {
+				progress.Files.Add(result.TotalFiles)
+			}
+			if result.TotalSize > 0 {
+				progress.Bytes.Add(result.TotalSize)
+			}
+
+			sendLiveScanEvent(ctx, events, Event{Kind: ChildDone, Entry: entry, Result: result})
+		}
+
+		wg.Add(1)
+		if limiter.tryAcquireEntry() {
+			go func() {
+				defer limiter.releaseEntry()
+				scanTarget()
+			}()
+		} else {
+			scanTarget()
+		}
+	}
+
+	wg.Wait()
+	close(largeFileChan)
+	largeFiles := <-largeFilesDone
+
+	if ctx.Err() != nil {
+		sendLiveScanEvent(context.Background(), events, Event{Kind: Canceled, Err: ctx.Err()})
+		return
+	}
+
+	mu.Lock()
+	finalEntries := make([]Entry, 0, len(entriesByPath))
+	for _, entry := range entriesByPath {
+		finalEntries = append(finalEntries, entry)
+	}
+	sparseFiles = topSparseFiles(sparseFiles)
+	mu.Unlock()
+	SortEntries(finalEntries)
+	if maxEntries > 0 && len(finalEntries) > maxEntries {
+		finalEntries = finalEntries[:maxEntries]
+	}
+
+	result := Result{
+		Entries:         finalEntries,
+		LargeFiles:      largeFiles,
+		SparseFiles:     sparseFiles,
+		Ages:            ages,
+		TotalSize:       totalSize.Load(),
+		TotalFiles:      totalFiles.Load(),
+		DedupedHardlink: dedupedHardlink.Load(),
+		Unreadable:      limiter.denied.Load(),
+	}
+
+	sendLiveScanEvent(ctx, events, Event{Kind: Complete, Result: result})
+}
+
+func scanLiveTargetWithProgress(ctx context.Context, target liveScanTarget, largeFileChan chan<- File, largeFileMinSize *int64, limiter *scanLimiter, progress *Progress, events chan<- Event) (Result, error) {
+	// The target counts into its own Progress so each ChildProgress event
+	// carries that directory's size; the shared one is updated when the
+	// target finishes.
+	local := &Progress{}
+	done := make(chan struct{})
+	progressDone := make(chan struct{})
+
+	go func() {
+		defer close(progressDone)
+		ticker := time.NewTicker(liveProgressInterval)
+		defer ticker.Stop()
+
+		var lastSize int64
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				size := local.Bytes.Load()
+				if size <= 0 || size == lastSize {
+					continue
+				}
+				lastSize = size
+				if path := local.Path(); path != "" {
+					progress.setPath(path)
+				}
+				sendLiveScanProgress(ctx, events, Event{
+					Kind: ChildProgress,
+					Entry: Entry{
+						Name:  target.name,
+						Path:  target.path,
+						Size:  size,
+						IsDir: true,
+					},
+				})
+			}
+		}
+	}()
+
+	result, err := scanLiveTarget(ctx, target, largeFileChan, largeFileMinSize, limiter, local)
+	close(done)
+	<-progressDone
+	if result.TotalFiles == 0 {
+		result.TotalFiles = local.Files.Load()
+	}
+	if result.TotalSize == 0 {
+		result.TotalSize = local.Bytes.Load()
+	}
+	return result, err
+}
+
+func scanLiveTarget(ctx context.Context, target liveScanTarget, largeFileChan chan<- File, largeFileMinSize *int64, limiter *scanLimiter, progress *Progress) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	switch target.kind {
+	case liveScanTargetHomeLibrary:
+		if cached, err := StoredSize(target.path); err == nil && cached > 0 {
+			return Result{TotalSize: cached}, nil
+		}
+	case liveScanTargetFoldedDirectory:
+		if !limiter.enterDir(target.path) {
+			return Result{}, nil
+		}
+		size, err := limiter.duSize(target.path)
+		if err != nil || size <= 0 {
+			size = calculateDirSizeFastWithLimiter(target.path, limiter, progress)
+		} else {
+			progress.Bytes.Add(size)
+		}
+		return Result{TotalSize: size}, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	result := scanSubdirWithCache(target.path, largeFileChan, largeFileMinSize, limiter, limiter.dirSem, limiter.duSem, limiter.duQueueSem, progress)
+	return result, ctx.Err()
+}
+
+func collectLiveLargeFiles(initial []File, largeFileChan <-chan File, largeFileMinSize *int64, done chan<- []File) {
+	h := &largeFileHeap{}
+	heap.Init(h)
+	for _, file := range initial {
+		pushLiveLargeFile(h, file, largeFileMinSize)
+	}
+	for file := range largeFileChan {
+		pushLiveLargeFile(h, file, largeFileMinSize)
+	}
+	files := make([]File, h.Len())
+	for i := range slices.Backward(files) {
+		files[i] = heap.Pop(h).(File)
+	}
+	done <- files
+}
+
+func pushLiveLargeFile(h *largeFileHeap, file File, largeFileMinSize *int64) {
+	if h.Len() < maxLargeFiles {
+		heap.Push(h, file)
+		if h.Len() == maxLargeFiles {
+			atomic.StoreInt64(largeFileMinSize, (*h)[0].Size)
+		}
+		return
+	}
+	if file.Size > (*h)[0].Size {
+		heap.Pop(h)
+		heap.Push(h, file)
+		atomic.StoreInt64(largeFileMinSize, (*h)[0].Size)
+	}
+}
+
+func sendLiveScanEvent(ctx context.Context, events chan<- Event, event Event) {
+	select {
+	case <-ctx.Done():
+	case events <- event:
+	}
+}
+
+func sendLiveScanProgress(ctx context.Context, events chan<- Event, event Event) {
+	select {
+	case <-ctx.Done():
+	case events <- event:
+	default:
+	}
+}
+
+// SortEntries sorts entries by size, largest first, keeping the order of
+// equal sizes.
+func SortEntries(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Size > entries[j].Size
+	})
+}
+
+func topLargeFiles(files []File) []File {
+	if len(files) <= maxLargeFiles {
+		sort.SliceStable(files, func(i, j int) bool {
+			return files[i].Size > files[j].Size
+		})
+		return files
+	}
+	h := &largeFileHeap{}
+	heap.Init(h)
+	var minSize int64 = largeFileWarmupMinSize
+	for _, file := range files {
+		pushLiveLargeFile(h, file, &minSize)
+	}
+	top := make([]File, h.Len())
+	for i := range slices.Backward(top) {
+		top[i] = heap.Pop(h).(File)
+	}
+	return top
+}
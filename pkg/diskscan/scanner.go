@@ -1,11 +1,12 @@
-//go:build darwin
+//go:build darwin || linux
 
-package main
+package diskscan
 
 import (
 	"bytes"
 	"container/heap"
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -20,10 +21,20 @@ import (
 	"sync/atomic"
 	"syscall"
 	"time"
+
+	"github.com/tw93/mole/internal/debuglog"
 )
 
+// PrivilegedSize, when set, sizes a directory this user cannot list, such
+// as through a privileged helper. Measure uses it before falling back to a
+// walk that would count the directory as empty.
+var PrivilegedSize func(ctx context.Context, path string) (int64, error)
+
 var spotlightQueryRunner = func(ctx context.Context, root, query string) ([]byte, error) {
-	return exec.CommandContext(ctx, "mdfind", "-onlyin", root, query).Output()
+	start := time.Now()
+	out, err := exec.CommandContext(ctx, "mdfind", "-onlyin", root, query).Output()
+	debuglog.Command("mdfind", []string{"-onlyin", root, query}, time.Since(start), err)
+	return out, err
 }
 
 // scanLimiter bundles the concurrency budgets used by a single scan pass.
@@ -31,7 +42,24 @@ var spotlightQueryRunner = func(ctx context.Context, root, query string) ([]byte
 // There are five separate semaphores on purpose: each protects a different
 // scarce resource. Collapsing two of them changes scaling behavior in ways
 // that are easy to get wrong; see the per-field notes before adjusting.
+//
+// The limiter also carries the rest of the per-scan state the walkers
+// share: cancellation, the filesystem, the caller's excludes, and whether
+// to use the cache.
 type scanLimiter struct {
+	ctx   context.Context
+	skip  map[string]bool
+	cache bool
+
+	// fsys is the tree being walked. native is true when it is the disk,
+	// the only tree du can size.
+	fsys   FS
+	native bool
+
+	// denied counts the directories the walk was refused, for
+	// Result.Unreadable.
+	denied atomic.Int64
+
 	// entrySem caps the number of in-flight top-level entry workers (one per
 	// child of the root being scanned). Acquired with tryAcquireEntry so the
 	// caller can fall back to inline scanning when the budget is saturated.
@@ -60,17 +88,35 @@ type scanLimiter struct {
 	// the fast path replaces a single du subprocess with one walker.
 	fastSem chan struct{}
 
+	// tuner scales entrySem and dirSem with ReadDir latency; nil when
+	// Options pins both or the tree is not the disk.
+	tuner *tuner
+
+	// now is when the scan started, which file ages count from.
+	now time.Time
+
 	// seen tracks (dev, ino) of hardlinked files counted so far in this
 	// scan so a file with multiple links is counted once, matching `du`.
 	seen sync.Map
+
+	// follow counts what symlinks point to; visited then holds the
+	// (dev, ino) of every directory walked, so each is walked once, and
+	// realRoot is the scanned root with its own links resolved.
+	follow   bool
+	visited  sync.Map
+	realRoot string
 }
 
-func newScanLimiter(childCount int) *scanLimiter {
+func newScanLimiter(ctx context.Context, childCount int) *scanLimiter {
 	if childCount <= 0 {
 		childCount = maxWorkers
 	}
 	numWorkers := max(min(max(runtime.NumCPU()*cpuMultiplier, minWorkers), maxWorkers, childCount), 1)
 	return &scanLimiter{
+		ctx:        ctx,
+		now:        time.Now(),
+		fsys:       OS,
+		native:     true,
 		entrySem:   make(chan struct{}, numWorkers),
 		dirSem:     make(chan struct{}, min(runtime.NumCPU()*2, maxDirWorkers)),
 		duSem:      make(chan struct{}, min(4, runtime.NumCPU())),
@@ -79,6 +125,24 @@ func newScanLimiter(childCount int) *scanLimiter {
 	}
 }
 
+// resize replaces the pools Options overrides; 0 leaves a pool as it is.
+// It must run before the scan starts.
+func (l *scanLimiter) resize(workers, dirWorkers, foldWorkers int) {
+	if workers > 0 {
+		workers = min(workers, MaxWorkers)
+		l.entrySem = make(chan struct{}, workers)
+		l.fastSem = make(chan struct{}, workers)
+	}
+	if dirWorkers > 0 {
+		l.dirSem = make(chan struct{}, min(dirWorkers, MaxWorkers))
+	}
+	if foldWorkers > 0 {
+		foldWorkers = min(foldWorkers, MaxWorkers)
+		l.duSem = make(chan struct{}, foldWorkers)
+		l.duQueueSem = make(chan struct{}, foldWorkers*2)
+	}
+}
+
 func (l *scanLimiter) tryAcquireEntry() bool {
 	if l == nil || l.entrySem == nil {
 		return false
@@ -97,6 +161,34 @@ func (l *scanLimiter) releaseEntry() {
 	}
 }
 
+// duSize sizes a folded directory with du, which only the disk supports.
+func (l *scanLimiter) duSize(path string) (int64, error) {
+	if !l.native {
+		return 0, errors.ErrUnsupported
+	}
+	path = filepath.Clean(path)
+	return scanGroup.do("du:"+path, func() (int64, error) { return getDirectorySizeFromDu(path) })
+}
+
+// readDir lists a directory below the root, counting it in denied when
+// this user may not open it.
+func (l *scanLimiter) readDir(path string) ([]fs.DirEntry, error) {
+	start := time.Now()
+	entries, err := l.fsys.ReadDir(path)
+	if l.tuner != nil {
+		l.tuner.observe(time.Since(start))
+	}
+	if errors.Is(err, fs.ErrPermission) {
+		l.denied.Add(1)
+	}
+	return entries, err
+}
+
+// skips reports whether a directory with this name is left out of the scan.
+func (l *scanLimiter) skips(name string) bool {
+	return defaultSkipDirs[name] || l.skip[name]
+}
+
 // trySend attempts to send an item to a channel with a timeout.
 // Returns true if the item was sent, false if the timeout was reached.
 func trySend[T any](ch chan<- T, item T, timeout time.Duration) bool {
@@ -133,25 +225,22 @@ func trySend[T any](ch chan<- T, item T, timeout time.Duration) bool {
 	}
 }
 
-func scanPathConcurrent(root string, filesScanned, dirsScanned, bytesScanned *int64, currentPath *atomic.Value) (scanResult, error) {
-	return scanPathConcurrentWithOptions(root, filesScanned, dirsScanned, bytesScanned, currentPath, true, maxEntries)
-}
-
-func scanPathConcurrentAllEntries(root string, filesScanned, dirsScanned, bytesScanned *int64, currentPath *atomic.Value) (scanResult, error) {
-	return scanPathConcurrentWithOptions(root, filesScanned, dirsScanned, bytesScanned, currentPath, true, 0)
-}
-
-func scanPathConcurrentWithOptions(root string, filesScanned, dirsScanned, bytesScanned *int64, currentPath *atomic.Value, useSpotlight bool, entryLimit int) (scanResult, error) {
-	return scanPathConcurrentWithLimiter(root, filesScanned, dirsScanned, bytesScanned, currentPath, useSpotlight, entryLimit, nil)
-}
-
-func scanPathConcurrentWithLimiter(root string, filesScanned, dirsScanned, bytesScanned *int64, currentPath *atomic.Value, useSpotlight bool, entryLimit int, limiter *scanLimiter) (scanResult, error) {
-	children, err := os.ReadDir(root)
+func scanPathConcurrentWithLimiter(root string, progress *Progress, useSpotlight bool, entryLimit int, limiter *scanLimiter) (Result, error) {
+	start := time.Now()
+	defer func() { debuglog.Phase("scan", time.Since(start), "path", root) }()
+	fsys := OS
+	if limiter != nil {
+		fsys = limiter.fsys
+	}
+	children, err := fsys.ReadDir(root)
 	if err != nil {
-		return scanResult{}, err
+		return Result{}, err
 	}
 	if limiter == nil {
-		limiter = newScanLimiter(len(children))
+		limiter = newScanLimiter(context.Background(), len(children))
+	}
+	if !limiter.enterDir(root) {
+		return Result{}, nil
 	}
 
 	var total int64
@@ -160,8 +249,20 @@ func scanPathConcurrentWithLimiter(root string, filesScanned, dirsScanned, bytes
 	var subtreeFilesScanned atomic.Int64
 	var dedupedHardlink atomic.Bool
 
+	// Sparse files and ages, from the files here and each subtree as it
+	// finishes.
+	var statsMu sync.Mutex
+	var sparseFiles []SparseFile
+	var ages Ages
+	addSubtree := func(result Result) {
+		statsMu.Lock()
+		sparseFiles = append(sparseFiles, result.SparseFiles...)
+		ages.merge(result.Ages)
+		statsMu.Unlock()
+	}
+
 	collectAllEntries := entryLimit <= 0
-	var collectedEntries []dirEntry
+	var collectedEntries []Entry
 
 	// Keep Top N heaps when a limit is requested.
 	entriesHeap := &entryHeap{}
@@ -181,8 +282,8 @@ func scanPathConcurrentWithLimiter(root string, filesScanned, dirsScanned, bytes
 	// Collect results via channels.
 	// Cap buffer size to prevent memory spikes with huge directories.
 	entryBufSize := max(min(len(children), 4096), 1)
-	entryChan := make(chan dirEntry, entryBufSize)
-	largeFileChan := make(chan fileEntry, maxLargeFiles*2)
+	entryChan := make(chan Entry, entryBufSize)
+	largeFileChan := make(chan File, maxLargeFiles*2)
 
 	var collectorWg sync.WaitGroup
 	collectorWg.Go(func() {
@@ -215,16 +316,38 @@ func scanPathConcurrentWithLimiter(root string, filesScanned, dirsScanned, bytes
 		}
 	})
 
-	isRootDir := root == "/"
+	isRootDir := root == "/" && limiter.native
 	home := os.Getenv("HOME")
-	isHomeDir := home != "" && root == home
+	isHomeDir := home != "" && root == home && limiter.native
 
 	for _, child := range children {
+		if limiter.ctx.Err() != nil {
+			break
+		}
 		fullPath := filepath.Join(root, child.Name())
 
-		// Skip symlinks to avoid following unexpected targets.
+		// A symlink counts as itself unless the scan follows links, so an
+		// unexpected target is not walked.
+		linkedDir := false
 		if child.Type()&fs.ModeSymlink != 0 {
-			targetInfo, err := os.Stat(fullPath)
+			if target, ok := limiter.followLink(fullPath); ok && target.IsDir() {
+				linkedDir = true
+			} else if ok {
+				size := getActualFileSize(fullPath, target)
+				atomic.AddInt64(&total, size)
+				localFilesScanned++
+				localBytesScanned += size
+				trySend(entryChan, Entry{
+					Name:       child.Name() + " →",
+					Path:       fullPath,
+					Size:       size,
+					LastAccess: getLastAccessTimeFromInfo(target),
+				}, scanSendTimeout)
+				continue
+			}
+		}
+		if child.Type()&fs.ModeSymlink != 0 && !linkedDir {
+			targetInfo, err := limiter.fsys.Stat(fullPath)
 			isDir := false
 			if err == nil && targetInfo.IsDir() {
 				isDir = true
@@ -238,7 +361,7 @@ func scanPathConcurrentWithLimiter(root string, filesScanned, dirsScanned, bytes
 			size := getActualFileSize(fullPath, info)
 			atomic.AddInt64(&total, size)
 
-			trySend(entryChan, dirEntry{
+			trySend(entryChan, Entry{
 				Name:       child.Name() + " →",
 				Path:       fullPath,
 				Size:       size,
@@ -249,10 +372,14 @@ func scanPathConcurrentWithLimiter(root string, filesScanned, dirsScanned, bytes
 
 		}
 
-		if child.IsDir() {
-			if defaultSkipDirs[child.Name()] {
+		if child.IsDir() || linkedDir {
+			if limiter.skips(child.Name()) {
 				continue
 			}
+			name := child.Name()
+			if linkedDir {
+				name += " →"
+			}
 
 			// Skip system dirs at root.
 			if isRootDir && skipSystemDirs[child.Name()] {
@@ -260,24 +387,25 @@ func scanPathConcurrentWithLimiter(root string, filesScanned, dirsScanned, bytes
 			}
 
 			// ~/Library is scanned separately; reuse cache when possible.
-			if isHomeDir && child.Name() == "Library" {
+			if isHomeDir && child.Name() == "Library" && !linkedDir {
 				processDir := func(name, path string) {
-					result := scanResult{}
-					if cached, err := loadStoredOverviewSize(path); err == nil && cached > 0 {
+					result := Result{}
+					if cached, err := StoredSize(path); err == nil && cached > 0 {
 						result.TotalSize = cached
 					} else {
-						result = scanSubdirWithCache(path, largeFileChan, &largeFileMinSize, limiter, dirSem, duSem, duQueueSem, filesScanned, dirsScanned, bytesScanned, currentPath)
+						result = scanSubdirWithCache(path, largeFileChan, &largeFileMinSize, limiter, dirSem, duSem, duQueueSem, progress)
 					}
 					atomic.AddInt64(&total, result.TotalSize)
+					addSubtree(result)
 					if result.TotalFiles > 0 {
 						subtreeFilesScanned.Add(result.TotalFiles)
 					}
-					if result.dedupedHardlink {
+					if result.DedupedHardlink {
 						dedupedHardlink.Store(true)
 					}
-					atomic.AddInt64(dirsScanned, 1)
+					progress.Dirs.Add(1)
 
-					trySend(entryChan, dirEntry{
+					trySend(entryChan, Entry{
 						Name:       name,
 						Path:       path,
 						Size:       result.TotalSize,
@@ -296,8 +424,12 @@ func scanPathConcurrentWithLimiter(root string, filesScanned, dirsScanned, bytes
 				continue
 			}
 
-			// Folded dirs: fast size without expanding.
-			if shouldFoldDirWithPath(child.Name(), fullPath) {
+			// Folded dirs: fast size without expanding. du does not follow
+			// links, so one reached through a link is walked instead.
+			if !linkedDir && shouldFoldDirWithPath(child.Name(), fullPath) {
+				if !limiter.enterDir(fullPath) {
+					continue
+				}
 				duQueueSem <- struct{}{}
 				wg.Go(func() {
 					defer func() { <-duQueueSem }()
@@ -305,15 +437,15 @@ func scanPathConcurrentWithLimiter(root string, filesScanned, dirsScanned, bytes
 					size, err := func() (int64, error) {
 						duSem <- struct{}{}
 						defer func() { <-duSem }()
-						return getDirectorySizeFromDu(fullPath)
+						return limiter.duSize(fullPath)
 					}()
 					if err != nil || size <= 0 {
-						size = calculateDirSizeFastWithLimiter(fullPath, limiter, filesScanned, dirsScanned, bytesScanned, currentPath)
+						size = calculateDirSizeFastWithLimiter(fullPath, limiter, progress)
 					}
 					atomic.AddInt64(&total, size)
-					atomic.AddInt64(dirsScanned, 1)
+					progress.Dirs.Add(1)
 
-					trySend(entryChan, dirEntry{
+					trySend(entryChan, Entry{
 						Name:       child.Name(),
 						Path:       fullPath,
 						Size:       size,
@@ -325,17 +457,18 @@ func scanPathConcurrentWithLimiter(root string, filesScanned, dirsScanned, bytes
 			}
 
 			processDir := func(name, path string) {
-				result := scanSubdirWithCache(path, largeFileChan, &largeFileMinSize, limiter, dirSem, duSem, duQueueSem, filesScanned, dirsScanned, bytesScanned, currentPath)
+				result := scanSubdirWithCache(path, largeFileChan, &largeFileMinSize, limiter, dirSem, duSem, duQueueSem, progress)
 				atomic.AddInt64(&total, result.TotalSize)
+				addSubtree(result)
 				if result.TotalFiles > 0 {
 					subtreeFilesScanned.Add(result.TotalFiles)
 				}
-				if result.dedupedHardlink {
+				if result.DedupedHardlink {
 					dedupedHardlink.Store(true)
 				}
-				atomic.AddInt64(dirsScanned, 1)
+				progress.Dirs.Add(1)
 
-				trySend(entryChan, dirEntry{
+				trySend(entryChan, Entry{
 					Name:       name,
 					Path:       path,
 					Size:       result.TotalSize,
@@ -346,10 +479,10 @@ func scanPathConcurrentWithLimiter(root string, filesScanned, dirsScanned, bytes
 			if limiter.tryAcquireEntry() {
 				wg.Go(func() {
 					defer limiter.releaseEntry()
-					processDir(child.Name(), fullPath)
+					processDir(name, fullPath)
 				})
 			} else {
-				processDir(child.Name(), fullPath)
+				processDir(name, fullPath)
 			}
 			continue
 		}
@@ -366,8 +499,14 @@ func scanPathConcurrentWithLimiter(root string, filesScanned, dirsScanned, bytes
 		atomic.AddInt64(&total, size)
 		localFilesScanned++
 		localBytesScanned += size
+		statsMu.Lock()
+		if f, ok := sparseFile(child.Name(), fullPath, info); ok {
+			sparseFiles = append(sparseFiles, f)
+		}
+		ages.add(info.ModTime(), limiter.now, size)
+		statsMu.Unlock()
 
-		trySend(entryChan, dirEntry{
+		trySend(entryChan, Entry{
 			Name:       child.Name(),
 			Path:       fullPath,
 			Size:       size,
@@ -379,16 +518,16 @@ func scanPathConcurrentWithLimiter(root string, filesScanned, dirsScanned, bytes
 		if !shouldSkipFileForLargeTracking(fullPath) {
 			minSize := atomic.LoadInt64(&largeFileMinSize)
 			if size >= minSize {
-				trySend(largeFileChan, fileEntry{Name: child.Name(), Path: fullPath, Size: size}, scanSendTimeout)
+				trySend(largeFileChan, File{Name: child.Name(), Path: fullPath, Size: size}, scanSendTimeout)
 			}
 		}
 	}
 
 	if localFilesScanned > 0 {
-		atomic.AddInt64(filesScanned, localFilesScanned)
+		progress.Files.Add(localFilesScanned)
 	}
 	if localBytesScanned > 0 {
-		atomic.AddInt64(bytesScanned, localBytesScanned)
+		progress.Bytes.Add(localBytesScanned)
 	}
 
 	wg.Wait()
@@ -397,24 +536,27 @@ func scanPathConcurrentWithLimiter(root string, filesScanned, dirsScanned, bytes
 	close(entryChan)
 	close(largeFileChan)
 	collectorWg.Wait()
+	if err := limiter.ctx.Err(); err != nil {
+		return Result{}, err
+	}
 
 	// Convert heaps to sorted slices (descending).
-	var entries []dirEntry
+	var entries []Entry
 	if collectAllEntries {
 		entries = append(entries, collectedEntries...)
 		sort.SliceStable(entries, func(i, j int) bool {
 			return entries[i].Size > entries[j].Size
 		})
 	} else {
-		entries = make([]dirEntry, entriesHeap.Len())
+		entries = make([]Entry, entriesHeap.Len())
 		for i := range slices.Backward(entries) {
-			entries[i] = heap.Pop(entriesHeap).(dirEntry)
+			entries[i] = heap.Pop(entriesHeap).(Entry)
 		}
 	}
 
-	largeFiles := make([]fileEntry, largeFilesHeap.Len())
+	largeFiles := make([]File, largeFilesHeap.Len())
 	for i := range slices.Backward(largeFiles) {
-		largeFiles[i] = heap.Pop(largeFilesHeap).(fileEntry)
+		largeFiles[i] = heap.Pop(largeFilesHeap).(File)
 	}
 
 	// Use Spotlight for large files when it expands the list.
@@ -424,60 +566,66 @@ func scanPathConcurrentWithLimiter(root string, filesScanned, dirsScanned, bytes
 		}
 	}
 
-	return scanResult{
+	return Result{
 		Entries:         entries,
 		LargeFiles:      largeFiles,
+		SparseFiles:     topSparseFiles(sparseFiles),
+		Ages:            ages,
 		TotalSize:       total,
 		TotalFiles:      localFilesScanned + subtreeFilesScanned.Load(),
-		dedupedHardlink: dedupedHardlink.Load(),
+		DedupedHardlink: dedupedHardlink.Load(),
 	}, nil
 }
 
-func publishLargeFiles(files []fileEntry, largeFileChan chan<- fileEntry) {
+func publishLargeFiles(files []File, largeFileChan chan<- File) {
 	for _, file := range files {
 		trySend(largeFileChan, file, scanSendTimeout)
 	}
 }
 
-func loadCachedSubdirResult(path string, largeFileChan chan<- fileEntry) (scanResult, bool) {
-	cached, err := loadCacheFromDisk(path)
+func loadCachedSubdirResult(path string, largeFileChan chan<- File) (Result, bool) {
+	cached, err := LoadCache(path)
 	if err != nil {
-		return scanResult{}, false
+		return Result{}, false
 	}
 
-	result := scanResult{
-		Entries:    cached.Entries,
-		LargeFiles: cached.LargeFiles,
-		TotalSize:  cached.TotalSize,
-		TotalFiles: cached.TotalFiles,
+	result := Result{
+		Entries:     cached.Entries,
+		LargeFiles:  cached.LargeFiles,
+		SparseFiles: cached.SparseFiles,
+		Ages:        cached.Ages,
+		TotalSize:   cached.TotalSize,
+		TotalFiles:  cached.TotalFiles,
 	}
 	publishLargeFiles(result.LargeFiles, largeFileChan)
 	return result, true
 }
 
-func scanSubdirWithCache(root string, largeFileChan chan<- fileEntry, largeFileMinSize *int64, limiter *scanLimiter, dirSem, duSem, duQueueSem chan struct{}, filesScanned, dirsScanned, bytesScanned *int64, currentPath *atomic.Value) scanResult {
-	if cached, ok := loadCachedSubdirResult(root, largeFileChan); ok {
-		if cached.TotalFiles > 0 {
-			atomic.AddInt64(filesScanned, cached.TotalFiles)
-		}
-		if cached.TotalSize > 0 {
-			atomic.AddInt64(bytesScanned, cached.TotalSize)
+func scanSubdirWithCache(root string, largeFileChan chan<- File, largeFileMinSize *int64, limiter *scanLimiter, dirSem, duSem, duQueueSem chan struct{}, progress *Progress) Result {
+	if limiter.cache {
+		if cached, ok := loadCachedSubdirResult(root, largeFileChan); ok {
+			if cached.TotalFiles > 0 {
+				progress.Files.Add(cached.TotalFiles)
+			}
+			if cached.TotalSize > 0 {
+				progress.Bytes.Add(cached.TotalSize)
+			}
+			return cached
 		}
-		return cached
 	}
 
-	result, err := scanPathConcurrentWithLimiter(root, filesScanned, dirsScanned, bytesScanned, currentPath, false, maxEntries, limiter)
+	result, err := scanPathConcurrentWithLimiter(root, progress, false, DefaultMaxEntries, limiter)
 	if err == nil {
 		publishLargeFiles(result.LargeFiles, largeFileChan)
 		// A subtree whose size depended on hardlink dedup is scan-order
 		// dependent; caching it would poison standalone re-scans.
-		if !result.dedupedHardlink {
+		if limiter.cache && !result.DedupedHardlink {
 			_ = saveCacheToDiskWithOptions(root, result, true)
 		}
 		return result
 	}
 
-	return scanResult{TotalSize: calculateDirSizeConcurrent(root, largeFileChan, largeFileMinSize, limiter, dirSem, duSem, duQueueSem, filesScanned, dirsScanned, bytesScanned, currentPath)}
+	return Result{TotalSize: calculateDirSizeConcurrent(root, largeFileChan, largeFileMinSize, limiter, dirSem, duSem, duQueueSem, progress)}
 }
 
 func shouldFoldDirWithPath(name, path string) bool {
@@ -504,16 +652,20 @@ func shouldSkipFileForLargeTracking(path string) bool {
 	return skipExtensions[ext]
 }
 
-// calculateDirSizeFast performs concurrent dir sizing using os.ReadDir.
-func calculateDirSizeFast(root string, filesScanned, dirsScanned, bytesScanned *int64, currentPath *atomic.Value) int64 {
-	return calculateDirSizeFastWithLimiter(root, newScanLimiter(0), filesScanned, dirsScanned, bytesScanned, currentPath)
+// calculateDirSizeFast performs concurrent dir sizing by listing directories.
+func calculateDirSizeFast(root string, progress *Progress) int64 {
+	return calculateDirSizeFastWithLimiter(root, newScanLimiter(context.Background(), 0), progress)
 }
 
-func calculateDirSizeFastWithLimiter(root string, limiter *scanLimiter, filesScanned, dirsScanned, bytesScanned *int64, currentPath *atomic.Value) int64 {
+func calculateDirSizeFastWithLimiter(root string, limiter *scanLimiter, progress *Progress) int64 {
 	var total atomic.Int64
 	var wg sync.WaitGroup
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	parent := context.Background()
+	if limiter != nil {
+		parent = limiter.ctx
+	}
+	ctx, cancel := context.WithTimeout(parent, 5*time.Minute)
 	defer cancel()
 
 	concurrency := min(runtime.NumCPU()*cpuMultiplier, maxWorkers)
@@ -521,6 +673,9 @@ func calculateDirSizeFastWithLimiter(root string, limiter *scanLimiter, filesSca
 	if limiter != nil && limiter.fastSem != nil {
 		sem = limiter.fastSem
 	}
+	if limiter == nil {
+		limiter = newScanLimiter(parent, 0)
+	}
 
 	var walk func(string)
 	walk = func(dirPath string) {
@@ -530,11 +685,14 @@ func calculateDirSizeFastWithLimiter(root string, limiter *scanLimiter, filesSca
 		default:
 		}
 
-		if currentPath != nil && atomic.LoadInt64(filesScanned)%int64(batchUpdateSize) == 0 {
-			currentPath.Store(dirPath)
+		if progress.Files.Load()%int64(batchUpdateSize) == 0 {
+			progress.setPath(dirPath)
 		}
 
-		entries, err := os.ReadDir(dirPath)
+		if !limiter.enterDir(dirPath) {
+			return
+		}
+		entries, err := limiter.readDir(dirPath)
 		if err != nil {
 			return
 		}
@@ -542,9 +700,13 @@ func calculateDirSizeFastWithLimiter(root string, limiter *scanLimiter, filesSca
 		var localBytes, localFiles int64
 
 		for _, entry := range entries {
-			if entry.IsDir() {
+			var target fs.FileInfo
+			if entry.Type()&fs.ModeSymlink != 0 {
+				target, _ = limiter.followLink(filepath.Join(dirPath, entry.Name()))
+			}
+			if entry.IsDir() || (target != nil && target.IsDir()) {
 				subDir := filepath.Join(dirPath, entry.Name())
-				atomic.AddInt64(dirsScanned, 1)
+				progress.Dirs.Add(1)
 
 				select {
 				case sem <- struct{}{}:
@@ -556,6 +718,10 @@ func calculateDirSizeFastWithLimiter(root string, limiter *scanLimiter, filesSca
 					// Fallback to synchronous traversal to avoid semaphore deadlock under high fan-out.
 					walk(subDir)
 				}
+			} else if target != nil {
+				size := getActualFileSize("", target)
+				localBytes += size
+				localFiles++
 			} else {
 				info, err := entry.Info()
 				if err == nil {
@@ -568,10 +734,10 @@ func calculateDirSizeFastWithLimiter(root string, limiter *scanLimiter, filesSca
 
 		if localBytes > 0 {
 			total.Add(localBytes)
-			atomic.AddInt64(bytesScanned, localBytes)
+			progress.Bytes.Add(localBytes)
 		}
 		if localFiles > 0 {
-			atomic.AddInt64(filesScanned, localFiles)
+			progress.Files.Add(localFiles)
 		}
 	}
 
@@ -582,7 +748,7 @@ func calculateDirSizeFastWithLimiter(root string, limiter *scanLimiter, filesSca
 }
 
 // Use Spotlight (mdfind) to quickly find large files.
-func findLargeFilesWithSpotlight(root string, minSize int64) []fileEntry {
+func findLargeFilesWithSpotlight(root string, minSize int64) []File {
 	// Validate root path.
 	if err := validatePath(root); err != nil {
 		return nil
@@ -632,7 +798,7 @@ func findLargeFilesWithSpotlight(root string, minSize int64) []fileEntry {
 
 		// Actual disk usage for sparse/cloud files.
 		actualSize := getActualFileSize(line, info)
-		candidate := fileEntry{
+		candidate := File{
 			Name: filepath.Base(line),
 			Path: line,
 			Size: actualSize,
@@ -646,9 +812,9 @@ func findLargeFilesWithSpotlight(root string, minSize int64) []fileEntry {
 		}
 	}
 
-	files := make([]fileEntry, h.Len())
+	files := make([]File, h.Len())
 	for i := range slices.Backward(files) {
-		files[i] = heap.Pop(h).(fileEntry)
+		files[i] = heap.Pop(h).(File)
 	}
 
 	return files
@@ -665,8 +831,11 @@ func isInFoldedDir(path string) bool {
 	return false
 }
 
-func calculateDirSizeConcurrent(root string, largeFileChan chan<- fileEntry, largeFileMinSize *int64, limiter *scanLimiter, dirSem, duSem, duQueueSem chan struct{}, filesScanned, dirsScanned, bytesScanned *int64, currentPath *atomic.Value) int64 {
-	children, err := os.ReadDir(root)
+func calculateDirSizeConcurrent(root string, largeFileChan chan<- File, largeFileMinSize *int64, limiter *scanLimiter, dirSem, duSem, duQueueSem chan struct{}, progress *Progress) int64 {
+	if !limiter.enterDir(root) {
+		return 0
+	}
+	children, err := limiter.readDir(root)
 	if err != nil {
 		return 0
 	}
@@ -679,24 +848,39 @@ func calculateDirSizeConcurrent(root string, largeFileChan chan<- fileEntry, lar
 	var wg sync.WaitGroup
 
 	for _, child := range children {
+		if limiter.ctx.Err() != nil {
+			break
+		}
 		fullPath := filepath.Join(root, child.Name())
 
+		linkedDir := false
 		if child.Type()&fs.ModeSymlink != 0 {
-			info, err := child.Info()
-			if err != nil {
+			info, ok := limiter.followLink(fullPath)
+			if ok && info.IsDir() {
+				linkedDir = true
+			} else {
+				var size int64
+				if ok {
+					size = getActualFileSize(fullPath, info)
+				} else if info, err = child.Info(); err == nil {
+					size = getActualFileSize(fullPath, info)
+				} else {
+					continue
+				}
+				localTotal += size
+				localFilesScanned++
+				localBytesScanned += size
 				continue
 			}
-			size := getActualFileSize(fullPath, info)
-			localTotal += size
-			localFilesScanned++
-			localBytesScanned += size
-			continue
 		}
 
-		if child.IsDir() {
+		if child.IsDir() || linkedDir {
 			localDirsScanned++
 
-			if shouldFoldDirWithPath(child.Name(), fullPath) {
+			if !linkedDir && shouldFoldDirWithPath(child.Name(), fullPath) {
+				if !limiter.enterDir(fullPath) {
+					continue
+				}
 				duQueueSem <- struct{}{}
 				wg.Go(func() {
 					defer func() { <-duQueueSem }()
@@ -704,12 +888,12 @@ func calculateDirSizeConcurrent(root string, largeFileChan chan<- fileEntry, lar
 					size, err := func() (int64, error) {
 						duSem <- struct{}{}
 						defer func() { <-duSem }()
-						return getDirectorySizeFromDu(fullPath)
+						return limiter.duSize(fullPath)
 					}()
 					if err != nil || size <= 0 {
-						size = calculateDirSizeFastWithLimiter(fullPath, limiter, filesScanned, dirsScanned, bytesScanned, currentPath)
+						size = calculateDirSizeFastWithLimiter(fullPath, limiter, progress)
 					} else {
-						atomic.AddInt64(bytesScanned, size)
+						progress.Bytes.Add(size)
 					}
 					total.Add(size)
 				})
@@ -721,11 +905,11 @@ func calculateDirSizeConcurrent(root string, largeFileChan chan<- fileEntry, lar
 				wg.Go(func() {
 					defer func() { <-dirSem }()
 
-					size := calculateDirSizeConcurrent(fullPath, largeFileChan, largeFileMinSize, limiter, dirSem, duSem, duQueueSem, filesScanned, dirsScanned, bytesScanned, currentPath)
+					size := calculateDirSizeConcurrent(fullPath, largeFileChan, largeFileMinSize, limiter, dirSem, duSem, duQueueSem, progress)
 					total.Add(size)
 				})
 			default:
-				size := calculateDirSizeConcurrent(fullPath, largeFileChan, largeFileMinSize, limiter, dirSem, duSem, duQueueSem, filesScanned, dirsScanned, bytesScanned, currentPath)
+				size := calculateDirSizeConcurrent(fullPath, largeFileChan, largeFileMinSize, limiter, dirSem, duSem, duQueueSem, progress)
 				localTotal += size
 			}
 			continue
@@ -744,13 +928,13 @@ func calculateDirSizeConcurrent(root string, largeFileChan chan<- fileEntry, lar
 		if !shouldSkipFileForLargeTracking(fullPath) && largeFileMinSize != nil {
 			minSize := atomic.LoadInt64(largeFileMinSize)
 			if size >= minSize {
-				trySend(largeFileChan, fileEntry{Name: child.Name(), Path: fullPath, Size: size}, scanSendTimeout)
+				trySend(largeFileChan, File{Name: child.Name(), Path: fullPath, Size: size}, scanSendTimeout)
 			}
 		}
 
 		// Update current path occasionally to prevent UI jitter.
-		if currentPath != nil && localFilesScanned%int64(batchUpdateSize) == 0 {
-			currentPath.Store(fullPath)
+		if localFilesScanned%int64(batchUpdateSize) == 0 {
+			progress.setPath(fullPath)
 		}
 	}
 
@@ -761,21 +945,23 @@ func calculateDirSizeConcurrent(root string, largeFileChan chan<- fileEntry, lar
 	wg.Wait()
 
 	if localFilesScanned > 0 {
-		atomic.AddInt64(filesScanned, localFilesScanned)
+		progress.Files.Add(localFilesScanned)
 	}
 	if localBytesScanned > 0 {
-		atomic.AddInt64(bytesScanned, localBytesScanned)
+		progress.Bytes.Add(localBytesScanned)
 	}
 	if localDirsScanned > 0 {
-		atomic.AddInt64(dirsScanned, localDirsScanned)
+		progress.Dirs.Add(localDirsScanned)
 	}
 
 	return total.Load()
 }
 
-// measureOverviewSize calculates the size of a directory using multiple strategies.
-// When scanning Home, it excludes ~/Library to avoid duplicate counting.
-func measureOverviewSize(path string) (int64, error) {
+// Measure sizes the directory at path with du, falling back to a logical
+// walk and then to the scan cache, and remembers the result for
+// StoredSize. Measuring the home directory leaves out ~/Library, which the
+// analyze overview lists on its own.
+func Measure(path string) (int64, error) {
 	if path == "" {
 		return 0, fmt.Errorf("empty path")
 	}
@@ -785,6 +971,11 @@ func measureOverviewSize(path string) (int64, error) {
 		return 0, fmt.Errorf("path must be absolute: %s", path)
 	}
 
+	return scanGroup.do("measure:"+path, func() (int64, error) { return measure(path) })
+}
+
+// measure is Measure for a clean absolute path, once per concurrent call.
+func measure(path string) (int64, error) {
 	if _, err := os.Stat(path); err != nil {
 		return 0, fmt.Errorf("cannot access path: %v", err)
 	}
@@ -797,23 +988,41 @@ func measureOverviewSize(path string) (int64, error) {
 	}
 
 	if duSize, err := getDirectorySizeFromDuWithExcludeAndIgnores(path, excludePath, overviewIgnoreNamesForPath(path)); err == nil {
-		_ = storeOverviewSize(path, duSize)
+		_ = StoreSize(path, duSize)
 		return duSize, nil
 	}
 
+	// The logical walk counts a directory this user cannot open as empty.
+	if PrivilegedSize != nil && unreadable(path) {
+		if size, err := PrivilegedSize(context.Background(), path); err == nil {
+			_ = StoreSize(path, size)
+			return size, nil
+		}
+	}
+
 	if logicalSize, err := getDirectoryLogicalSizeWithExclude(path, excludePath); err == nil {
-		_ = storeOverviewSize(path, logicalSize)
+		_ = StoreSize(path, logicalSize)
 		return logicalSize, nil
 	}
 
-	if cached, err := loadCacheFromDisk(path); err == nil {
-		_ = storeOverviewSize(path, cached.TotalSize)
+	if cached, err := LoadCache(path); err == nil {
+		_ = StoreSize(path, cached.TotalSize)
 		return cached.TotalSize, nil
 	}
 
 	return 0, fmt.Errorf("unable to measure directory size with fast methods")
 }
 
+// unreadable reports whether this user is denied listing the directory.
+func unreadable(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return os.IsPermission(err)
+	}
+	f.Close()
+	return false
+}
+
 func getDirectorySizeFromDu(path string) (int64, error) {
 	return getDirectorySizeFromDuWithExclude(path, "")
 }
@@ -848,7 +1057,7 @@ func getDirectorySizeFromDuWithExcludeAndIgnores(path string, excludePath string
 
 		args := []string{"-skPx"}
 		for _, ignoreName := range ignoreNames {
-			args = append(args, "-I", ignoreName)
+			args = append(args, duIgnoreArgs(ignoreName)...)
 		}
 		args = append(args, target)
 		cmd := exec.CommandContext(ctx, "du", args...)
@@ -856,7 +1065,9 @@ func getDirectorySizeFromDuWithExcludeAndIgnores(path string, excludePath string
 		cmd.Stdout = &stdout
 		cmd.Stderr = &stderr
 
+		duStart := time.Now()
 		runErr := cmd.Run()
+		debuglog.Command("du", args, time.Since(duStart), runErr)
 		fields := strings.Fields(stdout.String())
 		if runErr != nil {
 			if ctx.Err() == context.DeadlineExceeded {
@@ -1084,10 +1295,19 @@ func getActualFileSize(_ string, info fs.FileInfo) int64 {
 	return info.Size()
 }
 
-func getLastAccessTimeFromInfo(info fs.FileInfo) time.Time {
-	stat, ok := info.Sys().(*syscall.Stat_t)
-	if !ok {
-		return time.Time{}
+// validatePath checks a path before it is handed to du or mdfind.
+func validatePath(path string) error {
+	if path == "" {
+		return fmt.Errorf("path is empty")
 	}
-	return time.Unix(stat.Atimespec.Sec, stat.Atimespec.Nsec)
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("path must be absolute: %s", path)
+	}
+	if strings.Contains(path, "\x00") {
+		return fmt.Errorf("path contains null bytes")
+	}
+	if slices.Contains(strings.Split(path, string(filepath.Separator)), "..") {
+		return fmt.Errorf("path contains traversal components: %s", path)
+	}
+	return nil
 }
@@ -0,0 +1,620 @@
+//go:build darwin || linux
+
+package diskscan
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScanBasic(t *testing.T) {
+	root := t.TempDir()
+
+	rootFile := filepath.Join(root, "root.txt")
+	if err := os.WriteFile(rootFile, []byte("root-data"), 0o644); err != nil {
+		t.Fatalf("write root file: %v", err)
+	}
+
+	nested := filepath.Join(root, "nested")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("create nested dir: %v", err)
+	}
+
+	fileOne := filepath.Join(nested, "a.bin")
+	if err := os.WriteFile(fileOne, []byte("alpha"), 0o644); err != nil {
+		t.Fatalf("write file one: %v", err)
+	}
+	fileTwo := filepath.Join(nested, "b.bin")
+	if err := os.WriteFile(fileTwo, []byte(strings.Repeat("b", 32)), 0o644); err != nil {
+		t.Fatalf("write file two: %v", err)
+	}
+
+	linkPath := filepath.Join(root, "link-to-a")
+	if err := os.Symlink(fileOne, linkPath); err != nil {
+		t.Fatalf("create symlink: %v", err)
+	}
+
+	scanner := New(Options{MaxEntries: DefaultMaxEntries, Spotlight: true})
+
+	result, err := scanner.Scan(context.Background(), root)
+	if err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+
+	linkInfo, err := os.Lstat(linkPath)
+	if err != nil {
+		t.Fatalf("stat symlink: %v", err)
+	}
+
+	expectedDirSize := int64(len("alpha") + len(strings.Repeat("b", 32)))
+	expectedRootFileSize := int64(len("root-data"))
+	expectedLinkSize := getActualFileSize(linkPath, linkInfo)
+	expectedTotal := expectedDirSize + expectedRootFileSize + expectedLinkSize
+
+	if result.TotalSize != expectedTotal {
+		t.Fatalf("expected total size %d, got %d", expectedTotal, result.TotalSize)
+	}
+
+	if got := scanner.Progress().Files.Load(); got != 3 {
+		t.Fatalf("expected 3 files scanned, got %d", got)
+	}
+	if dirs := scanner.Progress().Dirs.Load(); dirs == 0 {
+		t.Fatalf("expected directory scan count to increase")
+	}
+	if bytes := scanner.Progress().Bytes.Load(); bytes == 0 {
+		t.Fatalf("expected byte counter to increase")
+	}
+	foundSymlink := false
+	for _, entry := range result.Entries {
+		if strings.HasSuffix(entry.Name, " →") {
+			foundSymlink = true
+			if entry.IsDir {
+				t.Fatalf("symlink entry should not be marked as directory")
+			}
+		}
+	}
+	if !foundSymlink {
+		t.Fatalf("expected symlink entry to be present in scan result")
+	}
+}
+
+// TestScanDedupsHardlinks guards #906: a file with multiple
+// hardlinks (e.g. Final Cut Pro managed media) must be counted once, the way
+// `du` does, instead of once per link.
+func TestScanDedupsHardlinks(t *testing.T) {
+	root := t.TempDir()
+
+	nested := filepath.Join(root, "nested")
+	other := filepath.Join(root, "other")
+	for _, d := range []string{nested, other} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", d, err)
+		}
+	}
+
+	original := filepath.Join(nested, "media.bin")
+	if err := os.WriteFile(original, []byte(strings.Repeat("x", 4096)), 0o644); err != nil {
+		t.Fatalf("write original: %v", err)
+	}
+	// Two more hardlinks to the same inode, one in this dir and one in a
+	// sibling dir, so the shared scan-wide dedup set is exercised.
+	for _, link := range []string{
+		filepath.Join(nested, "media-copy.bin"),
+		filepath.Join(other, "media-link.bin"),
+	} {
+		if err := os.Link(original, link); err != nil {
+			t.Fatalf("hardlink %s: %v", link, err)
+		}
+	}
+	// An unrelated plain file that must still be counted in full.
+	plain := filepath.Join(other, "plain.bin")
+	if err := os.WriteFile(plain, []byte("plaindata"), 0o644); err != nil {
+		t.Fatalf("write plain: %v", err)
+	}
+
+	scanner := New(Options{MaxEntries: DefaultMaxEntries, Spotlight: true})
+
+	result, err := scanner.Scan(context.Background(), root)
+	if err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+
+	mediaInfo, err := os.Lstat(original)
+	if err != nil {
+		t.Fatalf("stat original: %v", err)
+	}
+	plainInfo, err := os.Lstat(plain)
+	if err != nil {
+		t.Fatalf("stat plain: %v", err)
+	}
+	want := getActualFileSize(original, mediaInfo) + getActualFileSize(plain, plainInfo)
+	if result.TotalSize != want {
+		t.Fatalf("expected hardlinked media counted once (total %d), got %d", want, result.TotalSize)
+	}
+	if !result.DedupedHardlink {
+		t.Fatalf("expected DedupedHardlink to be set when a hardlink is deduped")
+	}
+}
+
+func TestScanWarmsChildDirectoryCache(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	root := filepath.Join(home, "root")
+	child := filepath.Join(root, "child")
+	if err := os.MkdirAll(child, 0o755); err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "root.txt"), []byte("root-data"), 0o644); err != nil {
+		t.Fatalf("write root data: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(child, "data.bin"), []byte(strings.Repeat("x", 4096)), 0o644); err != nil {
+		t.Fatalf("write child data: %v", err)
+	}
+
+	scanner := New(Options{MaxEntries: DefaultMaxEntries, Spotlight: true, Cache: true})
+
+	if _, err := scanner.Scan(context.Background(), root); err != nil {
+		t.Fatalf("Scan(root): %v", err)
+	}
+
+	cached, err := LoadCache(child)
+	if err != nil {
+		t.Fatalf("expected warmed child cache, got error: %v", err)
+	}
+	if cached.TotalSize <= 0 {
+		t.Fatalf("expected positive cached child size, got %d", cached.TotalSize)
+	}
+	if len(cached.Entries) == 0 {
+		t.Fatalf("expected cached child entries to be populated")
+	}
+	if cached.TotalFiles != 1 {
+		t.Fatalf("expected warmed child cache to track local file count 1, got %d", cached.TotalFiles)
+	}
+	if !cached.NeedsRefresh {
+		t.Fatalf("expected warmed child cache to be marked for refresh")
+	}
+}
+
+func TestScanUsesChildCacheLargeFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	root := filepath.Join(home, "root")
+	child := filepath.Join(root, "child")
+	if err := os.MkdirAll(child, 0o755); err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+
+	largeFile := filepath.Join(child, "large.bin")
+	if err := os.WriteFile(largeFile, []byte(strings.Repeat("x", 2<<20)), 0o644); err != nil {
+		t.Fatalf("write large file: %v", err)
+	}
+
+	childScanner := New(Options{MaxEntries: DefaultMaxEntries, Spotlight: true})
+	childResult, err := childScanner.Scan(context.Background(), child)
+	if err != nil {
+		t.Fatalf("Scan(child): %v", err)
+	}
+	if err := SaveCache(child, childResult); err != nil {
+		t.Fatalf("SaveCache(child): %v", err)
+	}
+
+	if err := os.Chmod(child, 0o000); err != nil {
+		t.Fatalf("chmod child unreadable: %v", err)
+	}
+	defer func() {
+		_ = os.Chmod(child, 0o755)
+	}()
+
+	scanner := New(Options{MaxEntries: DefaultMaxEntries, Spotlight: true, Cache: true})
+
+	result, err := scanner.Scan(context.Background(), root)
+	if err != nil {
+		t.Fatalf("Scan(root): %v", err)
+	}
+
+	foundChild := false
+	for _, entry := range result.Entries {
+		if entry.Path == child {
+			foundChild = true
+			if entry.Size != childResult.TotalSize {
+				t.Fatalf("cached child size mismatch: want %d, got %d", childResult.TotalSize, entry.Size)
+			}
+			break
+		}
+	}
+	if !foundChild {
+		t.Fatalf("expected cached child directory in root entries")
+	}
+
+	foundLargeFile := false
+	for _, file := range result.LargeFiles {
+		if file.Path == largeFile {
+			foundLargeFile = true
+			break
+		}
+	}
+	if !foundLargeFile {
+		t.Fatalf("expected root large files to include cached child large file")
+	}
+}
+
+func TestScanWarmsChildCachesWithoutRecursiveSpotlight(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	root := filepath.Join(home, "root")
+	childOne := filepath.Join(root, "child-one")
+	childTwo := filepath.Join(root, "child-two")
+	for _, dir := range []string{childOne, childTwo} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("create dir %s: %v", dir, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "data.bin"), []byte(strings.Repeat("x", 4096)), 0o644); err != nil {
+			t.Fatalf("write data in %s: %v", dir, err)
+		}
+	}
+
+	originalRunner := spotlightQueryRunner
+	spotlightRoots := []string{}
+	spotlightQueryRunner = func(_ context.Context, queryRoot, _ string) ([]byte, error) {
+		spotlightRoots = append(spotlightRoots, queryRoot)
+		return nil, nil
+	}
+	t.Cleanup(func() {
+		spotlightQueryRunner = originalRunner
+	})
+
+	scanner := New(Options{MaxEntries: DefaultMaxEntries, Spotlight: true, Cache: true})
+
+	if _, err := scanner.Scan(context.Background(), root); err != nil {
+		t.Fatalf("Scan(root): %v", err)
+	}
+
+	if len(spotlightRoots) != 1 || spotlightRoots[0] != root {
+		t.Fatalf("expected only root spotlight invocation, got %q", spotlightRoots)
+	}
+}
+
+func TestScanWarmsChildCacheWithLiveProgress(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	root := filepath.Join(home, "root")
+	child := filepath.Join(root, "child")
+	if err := os.MkdirAll(child, 0o755); err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+
+	const dirCount = 32
+	const filesPerDir = 256
+	for i := range dirCount {
+		dir := filepath.Join(child, fmt.Sprintf("dir-%02d", i))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("create nested dir %s: %v", dir, err)
+		}
+		for j := range filesPerDir {
+			file := filepath.Join(dir, fmt.Sprintf("file-%03d.bin", j))
+			if err := os.WriteFile(file, []byte("x"), 0o644); err != nil {
+				t.Fatalf("write %s: %v", file, err)
+			}
+		}
+	}
+
+	scanner := New(Options{MaxEntries: DefaultMaxEntries, Spotlight: true, Cache: true})
+
+	done := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := scanner.Scan(context.Background(), root)
+		errCh <- err
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	sawLiveProgress := false
+	for time.Now().Before(deadline) {
+		if scanner.Progress().Files.Load() > 0 {
+			select {
+			case <-done:
+			default:
+				sawLiveProgress = true
+			}
+			if sawLiveProgress {
+				break
+			}
+		}
+		select {
+		case <-done:
+			if !sawLiveProgress {
+				t.Fatalf("expected live progress before child warm scan completed, final files=%d", scanner.Progress().Files.Load())
+			}
+		default:
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	if !sawLiveProgress {
+		t.Fatalf("expected filesScanned to advance before warm child scan finished")
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("Scan(root): %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("scan did not complete")
+	}
+}
+
+func TestScanPermissionError(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can read directories without permission bits")
+	}
+
+	root := t.TempDir()
+	lockedDir := filepath.Join(root, "locked")
+	if err := os.Mkdir(lockedDir, 0o755); err != nil {
+		t.Fatalf("create locked dir: %v", err)
+	}
+
+	// Create a file before locking.
+	if err := os.WriteFile(filepath.Join(lockedDir, "secret.txt"), []byte("shh"), 0o644); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+
+	// Remove permissions.
+	if err := os.Chmod(lockedDir, 0o000); err != nil {
+		t.Fatalf("chmod 000: %v", err)
+	}
+	defer func() {
+		// Restore permissions for cleanup.
+		_ = os.Chmod(lockedDir, 0o755)
+	}()
+
+	scanner := New(Options{})
+
+	// Scanning the locked dir itself should fail.
+	_, err := scanner.Scan(context.Background(), lockedDir)
+	if err == nil {
+		t.Fatalf("expected error scanning locked directory, got nil")
+	}
+	if !os.IsPermission(err) {
+		t.Logf("unexpected error type: %v", err)
+	}
+}
+
+func TestCalculateDirSizeFastHighFanoutCompletes(t *testing.T) {
+	root := t.TempDir()
+
+	// Reproduce high fan-out nested directory pattern that previously risked semaphore deadlock.
+	const fanout = 256
+	for i := range fanout {
+		nested := filepath.Join(root, fmt.Sprintf("dir-%03d", i), "nested")
+		if err := os.MkdirAll(nested, 0o755); err != nil {
+			t.Fatalf("create nested dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(nested, "data.bin"), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write nested file: %v", err)
+		}
+	}
+
+	progress := &Progress{}
+
+	done := make(chan int64, 1)
+	go func() {
+		done <- calculateDirSizeFast(root, progress)
+	}()
+
+	select {
+	case total := <-done:
+		if total <= 0 {
+			t.Fatalf("expected positive total size, got %d", total)
+		}
+		if got := progress.Files.Load(); got < fanout {
+			t.Fatalf("expected at least %d files scanned, got %d", fanout, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("calculateDirSizeFast did not complete under high fan-out")
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	target := filepath.Join(home, "measure")
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		t.Fatalf("create target: %v", err)
+	}
+	content := []byte(strings.Repeat("x", 4096))
+	if err := os.WriteFile(filepath.Join(target, "data.bin"), content, 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	size, err := Measure(target)
+	if err != nil {
+		t.Fatalf("Measure: %v", err)
+	}
+	if size <= 0 {
+		t.Fatalf("expected positive size, got %d", size)
+	}
+
+	// Ensure snapshot stored.
+	cached, err := StoredSize(target)
+	if err != nil {
+		t.Fatalf("StoredSize: %v", err)
+	}
+	if cached != size {
+		t.Fatalf("snapshot mismatch: want %d, got %d", size, cached)
+	}
+
+	// Ensure Measure does not use cache
+	// APFS block size is 4KB, 4097 bytes should use more blocks
+	content = []byte(strings.Repeat("x", 4097))
+	if err := os.WriteFile(filepath.Join(target, "data2.bin"), content, 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	size2, err := Measure(target)
+	if err != nil {
+		t.Fatalf("Measure: %v", err)
+	}
+	if size2 == size {
+		t.Fatalf("Measure used cache")
+	}
+}
+
+func TestScannerWorkerOverrides(t *testing.T) {
+	l := New(Options{Workers: 3, FoldWorkers: 1000}).limiter(context.Background(), "")
+	if cap(l.entrySem) != 3 || cap(l.fastSem) != 3 {
+		t.Errorf("Workers: entry %d, fast %d, want 3", cap(l.entrySem), cap(l.fastSem))
+	}
+	if cap(l.duSem) != MaxWorkers || cap(l.duQueueSem) != 2*MaxWorkers {
+		t.Errorf("FoldWorkers: du %d, queue %d, want capped at %d", cap(l.duSem), cap(l.duQueueSem), MaxWorkers)
+	}
+	// An unpinned pool starts at the default, with room for the tuner.
+	if def, free := newScanLimiter(context.Background(), 0), cap(l.dirSem)-len(l.dirSem); free != cap(def.dirSem) {
+		t.Errorf("DirWorkers 0: dir %d, want the default %d", free, cap(def.dirSem))
+	}
+	if len(l.tuner.pools) != 1 || l.tuner.pools[0].name != "dir" {
+		t.Errorf("want only the unpinned dir pool tuned")
+	}
+
+	root := t.TempDir()
+	for _, dir := range []string{"a/x", "b", "c"} {
+		os.MkdirAll(filepath.Join(root, dir), 0o755)
+		os.WriteFile(filepath.Join(root, dir, "f"), make([]byte, 4096), 0o644)
+	}
+	result, err := New(Options{Workers: 1, DirWorkers: 1, FoldWorkers: 1}).Scan(context.Background(), root)
+	if err != nil || len(result.Entries) != 3 || result.TotalSize == 0 {
+		t.Fatalf("single-worker scan = %d entries, %d bytes, %v", len(result.Entries), result.TotalSize, err)
+	}
+}
+
+func TestTunerFollowsReadDirLatency(t *testing.T) {
+	l := newScanLimiter(context.Background(), 0)
+	l.dirSem = make(chan struct{}, 4)
+	tu := newTuner(l, false, true)
+	pool := tu.pools[0]
+	if cap(l.dirSem) != 8 || pool.limit() != 4 {
+		t.Fatalf("new pool: cap %d, limit %d; want room to double from 4", cap(l.dirSem), pool.limit())
+	}
+
+	feed := func(took time.Duration) {
+		for range tuneMinSamples {
+			tu.observe(took)
+		}
+		tu.tune()
+	}
+	for range 10 {
+		feed(100 * time.Microsecond)
+	}
+	if pool.limit() != 8 {
+		t.Errorf("fast storage: limit %d, want the ceiling 8", pool.limit())
+	}
+	feed(50 * time.Millisecond)
+	if pool.limit() != 4 {
+		t.Errorf("slow storage: limit %d, want it halved to 4", pool.limit())
+	}
+
+	// With every free token taken, slowing down parks nothing yet.
+	for range 4 {
+		l.dirSem <- struct{}{}
+	}
+	feed(50 * time.Millisecond)
+	if pool.limit() != 4 {
+		t.Errorf("busy pool: limit %d, want 4 until tokens come back", pool.limit())
+	}
+	tu.observe(time.Second)
+	tu.tune()
+	if pool.limit() != 4 {
+		t.Errorf("one sample moved the limit to %d", pool.limit())
+	}
+
+	if newTuner(l, false, false) != nil {
+		t.Error("a tuner with no pools to tune")
+	}
+}
+
+func TestScanFollowSymlinks(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "root")
+	write := func(path string, size int) int64 {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		info, _ := os.Lstat(path)
+		return getActualFileSize(path, info)
+	}
+	data := write(filepath.Join(root, "data", "a.bin"), 8192)
+	ext := write(filepath.Join(base, "ext", "e.bin"), 16384)
+	big := write(filepath.Join(base, "big.bin"), 32768)
+
+	var linkBytes int64
+	for link, target := range map[string]string{
+		"data/back": "../data",
+		"data/up":   root,
+		"inner":     "data/a.bin",
+		"ext1":      "../ext",
+		"ext2":      "../ext",
+		"big":       "../big.bin",
+		"big2":      "../big.bin",
+	} {
+		path := filepath.Join(root, link)
+		if err := os.Symlink(target, path); err != nil {
+			t.Fatal(err)
+		}
+		info, _ := os.Lstat(path)
+		linkBytes += getActualFileSize(path, info)
+	}
+
+	// Each directory and file counts once: the loops end, the second link
+	// to ext and big.bin adds nothing, and the link to a file inside the
+	// tree counts as a link.
+	want := data + ext + big
+	result, err := New(Options{MaxEntries: DefaultMaxEntries, FollowSymlinks: true}).Scan(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TotalSize < want || result.TotalSize > want+linkBytes {
+		t.Fatalf("TotalSize = %d, want %d plus at most %d of links", result.TotalSize, want, linkBytes)
+	}
+	var extSeen bool
+	for _, e := range result.Entries {
+		if (e.Name == "ext1 →" || e.Name == "ext2 →") && e.Size >= ext {
+			extSeen = extSeen || e.IsDir
+		}
+	}
+	if !extSeen {
+		t.Errorf("no entry for the linked ext folder: %+v", result.Entries)
+	}
+
+	live, err := New(Options{FollowSymlinks: true}).Live(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last Event
+	for ev := range live.Events {
+		last = ev
+	}
+	if last.Kind != Complete || last.Result.TotalSize < want || last.Result.TotalSize > want+linkBytes {
+		t.Fatalf("Live ended with %+v, want %d plus at most %d of links", last, want, linkBytes)
+	}
+
+	// Without the option, links count as themselves.
+	plain, err := New(Options{MaxEntries: DefaultMaxEntries}).Scan(context.Background(), root)
+	if err != nil || plain.TotalSize > data+linkBytes {
+		t.Fatalf("plain TotalSize = %d, %v; want at most %d", plain.TotalSize, err, data+linkBytes)
+	}
+}
@@ -0,0 +1,38 @@
+//go:build darwin || linux
+
+package diskscan
+
+import (
+	"cmp"
+	"io/fs"
+	"slices"
+	"syscall"
+)
+
+// sparseFile returns the file as a SparseFile when its blocks cover under
+// half its length and the holes come to at least sparseMinHoles. Files the
+// OS shrinks another way, by compression or by leaving the data in the
+// cloud, are not sparse.
+func sparseFile(name, path string, info fs.FileInfo) (SparseFile, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || !info.Mode().IsRegular() || notSparse(stat) {
+		return SparseFile{}, false
+	}
+	f := SparseFile{Name: name, Path: path, Size: stat.Blocks * 512, Logical: info.Size()}
+	if f.Holes() < sparseMinHoles || 2*f.Size > f.Logical {
+		return SparseFile{}, false
+	}
+	return f, true
+}
+
+// topSparseFiles sorts files by their holes, most first, and keeps the
+// first maxSparseFiles.
+func topSparseFiles(files []SparseFile) []SparseFile {
+	slices.SortFunc(files, func(a, b SparseFile) int {
+		return cmp.Compare(b.Holes(), a.Holes())
+	})
+	if len(files) > maxSparseFiles {
+		files = files[:maxSparseFiles]
+	}
+	return files
+}
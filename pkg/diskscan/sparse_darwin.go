@@ -0,0 +1,14 @@
+package diskscan
+
+import "syscall"
+
+// Flags from sys/stat.h: APFS compressed the file, or its data is in the
+// cloud and only downloaded on open.
+const (
+	ufCompressed = 0x00000020
+	sfDataless   = 0x40000000
+)
+
+func notSparse(stat *syscall.Stat_t) bool {
+	return stat.Flags&(ufCompressed|sfDataless) != 0
+}
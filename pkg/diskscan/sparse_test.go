@@ -0,0 +1,73 @@
+//go:build darwin || linux
+
+package diskscan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// makeSparse creates a file of length logical with only its first block
+// written, and skips the test when the filesystem allocates the holes.
+func makeSparse(t *testing.T, path string, logical int64) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, 4096), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, logical); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if getActualFileSize(path, info) > logical/2 {
+		t.Skip("the filesystem under TMPDIR has no sparse files")
+	}
+}
+
+func TestScanReportsSparseFiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	makeSparse(t, filepath.Join(root, "vm", "disk.img"), 1<<30)
+	makeSparse(t, filepath.Join(root, "core"), 300<<20)
+	// Too few holes to matter, and a file with none.
+	makeSparse(t, filepath.Join(root, "vm", "small.img"), 50<<20)
+	if err := os.WriteFile(filepath.Join(root, "dense.bin"), make([]byte, 64<<10), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(name string, files []SparseFile) {
+		t.Helper()
+		if len(files) != 2 || files[0].Name != "disk.img" || files[1].Name != "core" {
+			t.Fatalf("%s: SparseFiles = %+v, want disk.img then core", name, files)
+		}
+		if f := files[0]; f.Logical != 1<<30 || f.Size <= 0 || f.Size > 1<<20 {
+			t.Errorf("%s: disk.img = %d of %d bytes", name, f.Size, f.Logical)
+		}
+	}
+
+	result, err := New(Options{MaxEntries: DefaultMaxEntries, Cache: true}).Scan(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("Scan", result.SparseFiles)
+	if cached, err := LoadCache(filepath.Join(root, "vm")); err != nil || len(cached.SparseFiles) != 1 {
+		t.Errorf("cached vm = %+v, %v; want its sparse file kept", cached, err)
+	}
+
+	live, err := New(Options{Cache: true}).Live(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last Event
+	for ev := range live.Events {
+		last = ev
+	}
+	check("Live", last.Result.SparseFiles)
+}
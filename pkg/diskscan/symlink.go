@@ -0,0 +1,82 @@
+//go:build darwin || linux
+
+package diskscan
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// dirKey identifies a file or directory by device and inode, the way the
+// hardlink set does.
+func dirKey(info fs.FileInfo) ([2]uint64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return [2]uint64{}, false
+	}
+	return [2]uint64{uint64(uint32(stat.Dev)), stat.Ino}, true
+}
+
+// followLink resolves the symlink at path when the scan follows links. It
+// returns the target and true when the scan should count the target in
+// place of the link. It does not for a broken link, for a directory the
+// scan has already walked, which is how a loop ends, or for a file inside
+// the scanned tree, which the walk counts where it is. A file outside it
+// counts once however many links reach it.
+func (l *scanLimiter) followLink(path string) (fs.FileInfo, bool) {
+	if !l.follow {
+		return nil, false
+	}
+	target, err := l.fsys.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	key, ok := dirKey(target)
+	if !ok {
+		return nil, false
+	}
+	if target.IsDir() {
+		if _, walked := l.visited.Load(key); walked {
+			return nil, false
+		}
+		return target, true
+	}
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil || within(real, l.realRoot) {
+		return nil, false
+	}
+	if _, counted := l.seen.LoadOrStore(key, struct{}{}); counted {
+		return nil, false
+	}
+	return target, true
+}
+
+// within reports whether path is dir or below it.
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+// enterDir marks the directory at path visited and reports whether this
+// walker is the first to get there. A scan that follows links calls it on
+// every directory, so one reached both directly and through links, or
+// around a loop, is walked once. Without FollowSymlinks it always says
+// yes.
+func (l *scanLimiter) enterDir(path string) bool {
+	if !l.follow {
+		return true
+	}
+	info, err := l.fsys.Stat(path)
+	if err != nil {
+		// ReadDir reports it.
+		return true
+	}
+	key, ok := dirKey(info)
+	if !ok {
+		return true
+	}
+	_, walked := l.visited.LoadOrStore(key, struct{}{})
+	return !walked
+}
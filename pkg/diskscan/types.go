@@ -0,0 +1,87 @@
+//go:build darwin || linux
+
+package diskscan
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Entry is one child of a scanned directory. Symlinks are listed with a
+// trailing " →" and sized as the link itself, never the target.
+type Entry struct {
+	Name       string
+	Path       string
+	Size       int64
+	IsDir      bool
+	LastAccess time.Time
+}
+
+// File is one of the largest files found anywhere under the scanned root.
+type File struct {
+	Name string
+	Path string
+	Size int64
+}
+
+// SparseFile is a file whose length runs far past the blocks it has on
+// disk, such as a VM disk image, a core dump, or a download in progress.
+// The holes were never written and take no space, so the scan counts Size,
+// while ls and Finder show Logical.
+type SparseFile struct {
+	Name    string
+	Path    string
+	Size    int64
+	Logical int64
+}
+
+// Holes returns the bytes of f's length that take no space on disk.
+func (f SparseFile) Holes() int64 { return f.Logical - f.Size }
+
+// Result is a scanned directory: its entries and largest files, both
+// sorted by size, largest first, its sparse files, those with the most
+// holes first, and its bytes by age.
+type Result struct {
+	Entries     []Entry
+	LargeFiles  []File
+	SparseFiles []SparseFile
+	Ages        Ages
+	TotalSize   int64
+	TotalFiles  int64
+	// DedupedHardlink is true when a hardlinked file in this subtree was
+	// counted as zero because another link was seen earlier in the same
+	// scan. Such a result is scan-order dependent and is never written to
+	// the on-disk cache.
+	DedupedHardlink bool `json:"-"`
+	// Unreadable counts the directories the scan was not allowed to open
+	// and counted as empty, so TotalSize falls short when it is nonzero.
+	// Scanner.Scan and Live set it; subtrees served from the cache and
+	// sized by du are not counted.
+	Unreadable int64 `json:"-"`
+}
+
+// Progress counts the work of a running scan. The counters are atomic, so
+// another goroutine may read them while the scan updates them.
+type Progress struct {
+	Files atomic.Int64
+	Dirs  atomic.Int64
+	Bytes atomic.Int64
+	path  atomic.Value
+}
+
+// Path is a recently visited path, for display; it is "" before the scan
+// descends into anything.
+func (p *Progress) Path() string {
+	path, _ := p.path.Load().(string)
+	return path
+}
+
+func (p *Progress) setPath(path string) { p.path.Store(path) }
+
+// Reset zeroes the counters so the Progress can serve another scan.
+func (p *Progress) Reset() {
+	p.Files.Store(0)
+	p.Dirs.Store(0)
+	p.Bytes.Store(0)
+	p.path.Store("")
+}
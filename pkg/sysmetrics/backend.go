@@ -0,0 +1,93 @@
+package sysmetrics
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Backend is a pluggable collector. It reads whatever it measures and
+// returns a func that writes those readings onto the snapshot; the func runs
+// after the built-in collectors, so it may also override their fields.
+// Backends run on every full refresh, held to CollectorBudget like the
+// built-in collectors, and show up in MetricsSnapshot.Collectors by name.
+type Backend func(ctx context.Context) (func(*MetricsSnapshot), error)
+
+type backendResult struct {
+	name  string
+	apply func(*MetricsSnapshot)
+}
+
+// SetBackend installs b as the collector called name. A name matching a
+// built-in collector (see MetricsSnapshot.Collectors) replaces it, so a
+// menubar app can read sensors from its own helper, say; other names add a
+// collector. A nil b removes the backend and restores any built-in.
+func (c *Collector) SetBackend(name string, b Backend) {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	if b == nil {
+		delete(c.backends, name)
+		return
+	}
+	if c.backends == nil {
+		c.backends = make(map[string]Backend)
+	}
+	c.backends[name] = b
+}
+
+func (c *Collector) hasBackend(name string) bool {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	return c.backends[name] != nil
+}
+
+// withBackends swaps the built-in tasks a backend replaces and appends the
+// rest of the backends in name order.
+func (c *Collector) withBackends(ctx context.Context, tasks []collectorTask) []collectorTask {
+	c.watchMu.Lock()
+	backends := make(map[string]Backend, len(c.backends))
+	for name, b := range c.backends {
+		backends[name] = b
+	}
+	c.watchMu.Unlock()
+	if len(backends) == 0 {
+		return tasks
+	}
+
+	for i, task := range tasks {
+		if b, ok := backends[task.name]; ok {
+			tasks[i] = backendTask(ctx, task.name, task.budget, b)
+			delete(backends, task.name)
+		}
+	}
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tasks = append(tasks, backendTask(ctx, name, CollectorBudget, backends[name]))
+	}
+	return tasks
+}
+
+func backendTask(ctx context.Context, name string, budget time.Duration, b Backend) collectorTask {
+	return collectorTask{name: name, budget: budget, run: func() (collectedSet, error) {
+		apply, err := b(ctx)
+		if apply == nil {
+			return nil, err
+		}
+		return func(m *collectedMetrics) {
+			m.backends = append(m.backends, backendResult{name: name, apply: apply})
+		}, err
+	}}
+}
+
+// applyBackends writes backend readings onto snapshot in name order, so
+// overlapping backends resolve the same way every refresh.
+func applyBackends(snapshot *MetricsSnapshot, results []backendResult) {
+	sort.SliceStable(results, func(i, j int) bool { return results[i].name < results[j].name })
+	for _, r := range results {
+		r.apply(snapshot)
+	}
+}
@@ -0,0 +1,44 @@
+package sysmetrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSetBackendReplacesBuiltInAndAddsNew(t *testing.T) {
+	c := &Collector{scheduler: newCollectorScheduler()}
+	c.SetBackend("sensors", func(context.Context) (func(*MetricsSnapshot), error) {
+		return func(s *MetricsSnapshot) { s.Sensors = []SensorReading{{Label: "Helper", Value: 42}} }, nil
+	})
+	c.SetBackend("fans", func(context.Context) (func(*MetricsSnapshot), error) {
+		return nil, errors.New("no fans")
+	})
+
+	tasks := c.withBackends(context.Background(), []collectorTask{
+		bounded("sensors", func() (collectedSet, error) {
+			t.Fatal("built-in sensors collector should be replaced")
+			return nil, nil
+		}),
+	})
+	if len(tasks) != 2 || tasks[0].name != "sensors" || tasks[1].name != "fans" {
+		t.Fatalf("tasks = %+v, want sensors then fans", tasks)
+	}
+
+	var collected collectedMetrics
+	err := c.scheduler.Run(context.Background(), tasks, &collected)
+	if err == nil {
+		t.Fatal("expected the failing backend's error")
+	}
+	var snapshot MetricsSnapshot
+	applyBackends(&snapshot, collected.backends)
+	if len(snapshot.Sensors) != 1 || snapshot.Sensors[0].Label != "Helper" {
+		t.Fatalf("Sensors = %+v, want the backend's reading", snapshot.Sensors)
+	}
+
+	c.SetBackend("sensors", nil)
+	c.SetBackend("fans", nil)
+	if c.hasBackend("sensors") || len(c.withBackends(context.Background(), nil)) != 0 {
+		t.Fatal("nil backend should remove it")
+	}
+}
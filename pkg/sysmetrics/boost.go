@@ -0,0 +1,73 @@
+package sysmetrics
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// boostPanel is a card fed by full-refresh collectors that CollectBoosted
+// can rerun on every tick instead of waiting for the next full refresh.
+type boostPanel struct {
+	name       string
+	collectors []string
+	// store writes the fresh readings into the enrichment cache, which every
+	// fast refresh copies onto its snapshot.
+	store func(e *snapshotEnrichment, m collectedMetrics)
+}
+
+var boostPanels = []boostPanel{
+	{name: "GPU", collectors: []string{"gpu"}, store: func(e *snapshotEnrichment, m collectedMetrics) {
+		e.gpu = m.gpuStats
+	}},
+	{name: "Sensors", collectors: []string{"thermal", "sensors"}, store: func(e *snapshotEnrichment, m collectedMetrics) {
+		applySensorTemps(&m.thermalStats, m.sensorStats)
+		e.thermal, e.sensors = m.thermalStats, m.sensorStats
+	}},
+	{name: "Battery", collectors: []string{"batteries"}, store: func(e *snapshotEnrichment, m collectedMetrics) {
+		e.batteries = m.batteryStats
+	}},
+	{name: "Latency", collectors: []string{"latency"}, store: func(e *snapshotEnrichment, m collectedMetrics) {
+		e.latency = m.latency
+	}},
+	{name: "Ports", collectors: []string{"ports"}, store: func(e *snapshotEnrichment, m collectedMetrics) {
+		e.ports = m.portStats
+	}},
+	{name: "Containers", collectors: []string{"containers"}, store: func(e *snapshotEnrichment, m collectedMetrics) {
+		e.containers = m.containers
+	}},
+	{name: "Bluetooth", collectors: []string{"bluetooth"}, store: func(e *snapshotEnrichment, m collectedMetrics) {
+		e.bluetooth = m.btStats
+	}},
+}
+
+// BoostPanels names the panels CollectBoosted accepts, in the order the
+// status dashboard cycles through them.
+func BoostPanels() []string {
+	names := make([]string, len(boostPanels))
+	for i, p := range boostPanels {
+		names[i] = p.name
+	}
+	return names
+}
+
+// CollectBoosted reruns one panel's full-refresh collectors, then does a
+// fast refresh with processes, which picks the new readings up from the
+// enrichment cache. Before the first full refresh, for an unknown panel,
+// or for a panel fed by a Backend, it is a plain fast refresh.
+func (c *Collector) CollectBoosted(ctx context.Context, panel string) (MetricsSnapshot, error) {
+	var runErr error
+	i := slices.IndexFunc(boostPanels, func(p boostPanel) bool { return p.name == panel })
+	if c.hasEnrichment && i >= 0 && !slices.ContainsFunc(boostPanels[i].collectors, c.hasBackend) {
+		p := boostPanels[i]
+		tasks := slices.DeleteFunc(c.fullTasks(ctx, time.Now(), nil), func(t collectorTask) bool {
+			return !slices.Contains(p.collectors, t.name)
+		})
+		var collected collectedMetrics
+		runErr = c.scheduler.Run(ctx, tasks, &collected)
+		p.store(&c.enrichment, collected)
+	}
+	snapshot, err := c.collectFast(ctx, true)
+	return snapshot, errors.Join(runErr, err)
+}
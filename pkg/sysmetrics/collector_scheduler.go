@@ -0,0 +1,227 @@
+package sysmetrics
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tw93/mole/internal/debuglog"
+)
+
+// CollectorBudget is how long a full refresh waits for one collector. A
+// collector that runs over keeps going in the background and its last good
+// result stands in, so one slow tool (powermetrics, system_profiler, a hung
+// mount) cannot hold back the rest of the refresh.
+const CollectorBudget = 3 * time.Second
+
+// collectedSet stores one collector's result.
+type collectedSet func(*collectedMetrics)
+
+// collectorTask is one collector in a full refresh. run does the work on its
+// own goroutine and returns a setter that stores the result; setters only
+// run on the refreshing goroutine, so a collector that finishes late never
+// writes into a snapshot that is already being built.
+type collectorTask struct {
+	name   string
+	budget time.Duration // 0 waits however long the collector takes
+	run    func() (collectedSet, error)
+}
+
+// bounded is a collector held to CollectorBudget.
+func bounded(name string, run func() (collectedSet, error)) collectorTask {
+	return collectorTask{name: name, budget: CollectorBudget, run: run}
+}
+
+// waited is a collector the refresh always waits for. The fast path runs
+// these too, so letting one linger would race the next fast refresh.
+func waited(name string, run func() (collectedSet, error)) collectorTask {
+	return collectorTask{name: name, run: run}
+}
+
+// CollectorStat is one collector's timing and failure record since start.
+type CollectorStat struct {
+	Name     string  `json:"name"`
+	Runs     int     `json:"runs"`
+	Failures int     `json:"failures"`
+	Timeouts int     `json:"timeouts"` // Refreshes that went on without it
+	LastMs   float64 `json:"last_ms"`
+	MaxMs    float64 `json:"max_ms"`
+	// LastError is the newest failure, kept after later runs succeed.
+	LastError string `json:"last_error,omitempty"`
+}
+
+type collectorScheduler struct {
+	mu       sync.Mutex
+	inflight map[string]bool
+	last     map[string]collectedSet
+	stats    map[string]*CollectorStat
+}
+
+func newCollectorScheduler() *collectorScheduler {
+	return &collectorScheduler{
+		inflight: make(map[string]bool),
+		last:     make(map[string]collectedSet),
+		stats:    make(map[string]*CollectorStat),
+	}
+}
+
+type collectorResult struct {
+	index int
+	set   collectedSet
+	err   error
+}
+
+// Run starts every task at once and fills into as results arrive. A task
+// still running from an earlier refresh is not started again; like one that
+// misses its budget, it contributes its last good result. When ctx ends,
+// every task still pending is written off the same way and Run returns
+// ctx's error along with any collector errors.
+func (s *collectorScheduler) Run(ctx context.Context, tasks []collectorTask, into *collectedMetrics) error {
+	start := time.Now()
+	results := make(chan collectorResult, len(tasks))
+	pending := make(map[int]time.Time, len(tasks)) // index -> deadline, zero if waited
+	for i, task := range tasks {
+		s.mu.Lock()
+		busy := s.inflight[task.name]
+		s.inflight[task.name] = true
+		s.mu.Unlock()
+		if busy {
+			s.useLast(task.name, into)
+			continue
+		}
+		var deadline time.Time
+		if task.budget > 0 {
+			deadline = start.Add(task.budget)
+		}
+		pending[i] = deadline
+		go func() {
+			set, err := s.runTask(task)
+			results <- collectorResult{index: i, set: set, err: err}
+		}()
+	}
+
+	var merged error
+	for len(pending) > 0 {
+		var timer <-chan time.Time
+		if next, ok := nextDeadline(pending); ok {
+			timer = time.After(time.Until(next))
+		}
+		select {
+		case <-ctx.Done():
+			for i := range pending {
+				delete(pending, i)
+				s.useLast(tasks[i].name, into)
+			}
+			if merged == nil {
+				merged = ctx.Err()
+			} else {
+				merged = fmt.Errorf("%v; %w", merged, ctx.Err())
+			}
+		case r := <-results:
+			if _, ok := pending[r.index]; !ok {
+				continue // Already written off; runTask kept its result.
+			}
+			delete(pending, r.index)
+			if r.set != nil {
+				r.set(into)
+			}
+			if r.err != nil {
+				if merged == nil {
+					merged = r.err
+				} else {
+					merged = fmt.Errorf("%v; %w", merged, r.err)
+				}
+			}
+		case <-timer:
+			now := time.Now()
+			for i, deadline := range pending {
+				if !deadline.IsZero() && !now.Before(deadline) {
+					delete(pending, i)
+					debuglog.Timeout(tasks[i].name, tasks[i].budget)
+					s.timedOut(tasks[i].name, into)
+				}
+			}
+		}
+	}
+	return merged
+}
+
+func nextDeadline(pending map[int]time.Time) (time.Time, bool) {
+	var next time.Time
+	for _, deadline := range pending {
+		if !deadline.IsZero() && (next.IsZero() || deadline.Before(next)) {
+			next = deadline
+		}
+	}
+	return next, !next.IsZero()
+}
+
+func (s *collectorScheduler) runTask(task collectorTask) (set collectedSet, err error) {
+	began := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			set, err = nil, fmt.Errorf("collector panic: %v", r)
+		}
+		elapsed := time.Since(began)
+		debuglog.Collector(task.name, elapsed, err)
+		took := float64(elapsed.Microseconds()) / 1000
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.inflight[task.name] = false
+		stat := s.stat(task.name)
+		stat.Runs++
+		stat.LastMs = took
+		stat.MaxMs = max(stat.MaxMs, took)
+		if err != nil {
+			stat.Failures++
+			stat.LastError = err.Error()
+		} else if set != nil {
+			s.last[task.name] = set
+		}
+	}()
+	return task.run()
+}
+
+func (s *collectorScheduler) timedOut(name string, into *collectedMetrics) {
+	s.mu.Lock()
+	s.stat(name).Timeouts++
+	s.mu.Unlock()
+	s.useLast(name, into)
+}
+
+func (s *collectorScheduler) useLast(name string, into *collectedMetrics) {
+	s.mu.Lock()
+	set := s.last[name]
+	s.mu.Unlock()
+	if set != nil {
+		set(into)
+	}
+}
+
+// stat must be called with s.mu held.
+func (s *collectorScheduler) stat(name string) *CollectorStat {
+	stat, ok := s.stats[name]
+	if !ok {
+		stat = &CollectorStat{Name: name}
+		s.stats[name] = stat
+	}
+	return stat
+}
+
+// Stats lists every collector, slowest last run first.
+func (s *collectorScheduler) Stats() []CollectorStat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := make([]CollectorStat, 0, len(s.stats))
+	for _, stat := range s.stats {
+		stats = append(stats, *stat)
+	}
+	slices.SortFunc(stats, func(a, b CollectorStat) int {
+		return cmp.Or(cmp.Compare(b.LastMs, a.LastMs), cmp.Compare(a.Name, b.Name))
+	})
+	return stats
+}
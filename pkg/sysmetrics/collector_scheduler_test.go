@@ -0,0 +1,67 @@
+package sysmetrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCollectorSchedulerDoesNotWaitForSlowCollector(t *testing.T) {
+	s := newCollectorScheduler()
+	release := make(chan struct{})
+	defer close(release)
+
+	slow := func(spotlight SpotlightStatus, block bool) collectorTask {
+		return collectorTask{name: "spotlight", budget: 20 * time.Millisecond, run: func() (collectedSet, error) {
+			if block {
+				<-release
+			}
+			return func(m *collectedMetrics) { m.spotlight = spotlight }, nil
+		}}
+	}
+	fast := waited("clock", func() (collectedSet, error) {
+		return func(m *collectedMetrics) { m.clock = ClockStatus{Server: "time.apple.com"} }, nil
+	})
+	failing := bounded("services", func() (collectedSet, error) { return nil, errors.New("brew missing") })
+
+	// First run finishes in time and becomes the fallback.
+	var first collectedMetrics
+	if err := s.Run(context.Background(), []collectorTask{slow(SpotlightStatus{CPU: 10}, false), fast}, &first); err != nil {
+		t.Fatal(err)
+	}
+
+	var second collectedMetrics
+	began := time.Now()
+	err := s.Run(context.Background(), []collectorTask{slow(SpotlightStatus{CPU: 99}, true), fast, failing}, &second)
+	if took := time.Since(began); took > time.Second {
+		t.Fatalf("Run waited %v for a collector past its budget", took)
+	}
+	if err == nil || err.Error() != "brew missing" {
+		t.Fatalf("err = %v, want the failing collector's error", err)
+	}
+	if second.spotlight.CPU != 10 || second.clock.Server != "time.apple.com" {
+		t.Fatalf("second = spotlight %+v clock %+v, want last good spotlight and fresh clock", second.spotlight, second.clock)
+	}
+
+	// The blocked collector is still running, so the next refresh reuses
+	// its last result instead of starting another copy.
+	var third collectedMetrics
+	if err := s.Run(context.Background(), []collectorTask{slow(SpotlightStatus{CPU: 50}, false)}, &third); err != nil {
+		t.Fatal(err)
+	}
+	if third.spotlight.CPU != 10 {
+		t.Fatalf("third spotlight = %+v, want the last good result", third.spotlight)
+	}
+
+	stats := make(map[string]CollectorStat)
+	for _, stat := range s.Stats() {
+		stats[stat.Name] = stat
+	}
+	if got := stats["spotlight"]; got.Runs != 1 || got.Timeouts != 1 {
+		t.Fatalf("spotlight stats = %+v, want 1 run and 1 timeout", got)
+	}
+	if got := stats["services"]; got.Failures != 1 {
+		t.Fatalf("services stats = %+v, want 1 failure", got)
+	}
+}
@@ -0,0 +1,22 @@
+// Package sysmetrics is the collector behind `mole status`: CPU, memory,
+// disks, network, battery, sensors, GPU, processes, and the slower panels
+// (containers, services, Time Machine, ...) gathered into one
+// MetricsSnapshot, the same struct `mole status --json` prints.
+//
+// A Collector keeps the state rates and histories need between calls, so
+// create one and reuse it:
+//
+//	c := sysmetrics.NewCollector(sysmetrics.ProcessWatchOptions{})
+//	snap, err := c.Collect(ctx) // every collector, each held to CollectorBudget
+//	for range time.Tick(time.Second) {
+//		snap, err = c.CollectFast(ctx) // per-second metrics, rest from the last Collect
+//	}
+//
+// Collect returns a usable snapshot alongside an error when some collectors
+// failed; MetricsSnapshot.Collectors records which, and how long each took.
+// SetBackend replaces a built-in collector, or adds one, with the caller's
+// own reading, for hosts where the defaults need tools the caller lacks.
+//
+// The collectors are written for macOS and Linux; elsewhere the snapshot
+// carries what gopsutil can read.
+package sysmetrics
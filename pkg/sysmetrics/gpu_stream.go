@@ -0,0 +1,176 @@
+package sysmetrics
+
+import (
+	"bufio"
+	"io"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	nvidiaQueryGPU = "--query-gpu=index,utilization.gpu,memory.used,memory.total,name"
+	// A stream that has not printed for this long is treated as gone and the
+	// next refresh falls back to a one-shot query.
+	nvidiaStreamStale = 3 * time.Second
+)
+
+// nvidiaStream keeps one `nvidia-smi --query-gpu=... -l 1` child running and
+// holds the newest reading for each GPU, so refreshes read memory instead of
+// forking nvidia-smi every second. nvidia-smi prints every GPU's line each
+// interval; lines are keyed by index, so a partial batch never mixes GPUs.
+type nvidiaStream struct {
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	gen    int // Bumped per child so a stopped one's reader cannot clobber state
+	gpus   map[int]GPUStatus
+	lastAt time.Time
+}
+
+// Start launches the child unless one is already running.
+func (s *nvidiaStream) Start() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cmd != nil {
+		return
+	}
+	cmd := exec.Command("nvidia-smi", nvidiaQueryGPU, "--format=csv,noheader,nounits", "-l", "1")
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		return
+	}
+	s.cmd = cmd
+	s.gen++
+	gen := s.gen
+	go func() {
+		s.consume(out, gen, time.Now)
+		_ = cmd.Wait()
+	}()
+}
+
+// Stop kills the child. A later Start launches a new one.
+func (s *nvidiaStream) Stop() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cmd != nil {
+		_ = s.cmd.Process.Kill()
+		s.cmd = nil
+		s.gen++
+	}
+}
+
+func (s *nvidiaStream) consume(r io.Reader, gen int, clock func() time.Time) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		index, gpu, ok := parseNvidiaGPULine(scanner.Text())
+		if !ok {
+			continue
+		}
+		s.mu.Lock()
+		if s.gen != gen {
+			s.mu.Unlock()
+			return
+		}
+		if s.gpus == nil {
+			s.gpus = make(map[int]GPUStatus)
+		}
+		s.gpus[index] = gpu
+		s.lastAt = clock()
+		s.mu.Unlock()
+	}
+	// The child exited (driver reset, GPU removed) or was stopped; the next
+	// full refresh polls once and starts a fresh stream.
+	s.mu.Lock()
+	if s.gen == gen {
+		s.cmd = nil
+	}
+	s.mu.Unlock()
+}
+
+// Latest returns the newest reading of every GPU in index order, or nil once
+// the stream has gone quiet.
+func (s *nvidiaStream) Latest(now time.Time) []GPUStatus {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.gpus) == 0 || now.Sub(s.lastAt) > nvidiaStreamStale {
+		return nil
+	}
+	indexes := make([]int, 0, len(s.gpus))
+	for index := range s.gpus {
+		indexes = append(indexes, index)
+	}
+	slices.Sort(indexes)
+	gpus := make([]GPUStatus, 0, len(indexes))
+	for _, index := range indexes {
+		gpus = append(gpus, s.gpus[index])
+	}
+	return gpus
+}
+
+// SampledAt is when the newest line arrived.
+func (s *nvidiaStream) SampledAt() time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastAt
+}
+
+// parseNvidiaGPULine reads one line of nvidiaQueryGPU's CSV output:
+//
+//	0, 37, 2048, 24576, NVIDIA GeForce RTX 4090
+func parseNvidiaGPULine(line string) (int, GPUStatus, bool) {
+	fields := strings.Split(line, ",")
+	if len(fields) < 5 {
+		return 0, GPUStatus{}, false
+	}
+	index, err := strconv.Atoi(strings.TrimSpace(fields[0]))
+	if err != nil {
+		return 0, GPUStatus{}, false
+	}
+	util, _ := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+	memUsed, _ := strconv.ParseFloat(strings.TrimSpace(fields[2]), 64)
+	memTotal, _ := strconv.ParseFloat(strings.TrimSpace(fields[3]), 64)
+	return index, GPUStatus{
+		// GPU names do not contain commas, but keep any that do.
+		Name:        strings.TrimSpace(strings.Join(fields[4:], ",")),
+		Usage:       util,
+		MemoryUsed:  memUsed,
+		MemoryTotal: memTotal,
+	}, true
+}
+
+// withNvidiaSamples refreshes the cached GPUs' utilization and memory from
+// the stream between full refreshes. Processes stay from the last full
+// refresh. If the GPU set changed, the stream's list wins.
+func withNvidiaSamples(cached, live []GPUStatus, sampledAt time.Time) []GPUStatus {
+	gpus := live
+	if len(cached) == len(live) {
+		gpus = slices.Clone(cached)
+		for i, sample := range live {
+			gpus[i].Usage = sample.Usage
+			gpus[i].MemoryUsed = sample.MemoryUsed
+			gpus[i].MemoryTotal = sample.MemoryTotal
+		}
+	}
+	for i := range gpus {
+		gpus[i].SampledAt = sampledAt
+	}
+	return gpus
+}
@@ -0,0 +1,44 @@
+package sysmetrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNvidiaStreamKeepsNewestPerGPU(t *testing.T) {
+	at := time.Date(2026, 3, 12, 9, 0, 0, 0, time.UTC)
+	s := &nvidiaStream{gen: 1}
+	out := `0, 10, 1024, 24576, NVIDIA GeForce RTX 4090
+1, 5, 512, 8192, NVIDIA RTX A2000
+not a sample
+0, 97, 20480, 24576, NVIDIA GeForce RTX 4090
+`
+	s.consume(strings.NewReader(out), 1, func() time.Time { return at })
+
+	got := s.Latest(at.Add(time.Second))
+	if len(got) != 2 || got[0].Usage != 97 || got[0].MemoryUsed != 20480 || got[1].Name != "NVIDIA RTX A2000" {
+		t.Fatalf("Latest() = %+v", got)
+	}
+	if got := s.Latest(at.Add(nvidiaStreamStale + time.Second)); got != nil {
+		t.Fatalf("quiet stream should report nothing, got %+v", got)
+	}
+
+	// A stopped child's reader must not overwrite a newer one's samples.
+	s.consume(strings.NewReader("0, 1, 1, 1, stale\n"), 0, func() time.Time { return at })
+	if got := s.Latest(at); got[0].Name != "NVIDIA GeForce RTX 4090" {
+		t.Fatalf("old generation leaked in: %+v", got)
+	}
+}
+
+func TestWithNvidiaSamplesKeepsProcesses(t *testing.T) {
+	cached := []GPUStatus{{Name: "RTX 4090", Usage: 10, Processes: []GPUProcess{{PID: 42}}}}
+	at := time.Date(2026, 3, 12, 9, 0, 0, 0, time.UTC)
+	got := withNvidiaSamples(cached, []GPUStatus{{Name: "RTX 4090", Usage: 88, MemoryUsed: 4096, MemoryTotal: 24576}}, at)
+	if got[0].Usage != 88 || got[0].MemoryUsed != 4096 || len(got[0].Processes) != 1 || !got[0].SampledAt.Equal(at) {
+		t.Fatalf("withNvidiaSamples() = %+v", got)
+	}
+	if cached[0].Usage != 10 {
+		t.Fatal("cached GPUs should not be modified")
+	}
+}
@@ -0,0 +1,15 @@
+package sysmetrics
+
+import "errors"
+
+var errIORegistryUnavailable = errors.New("IORegistry unavailable")
+
+// readIORegistryFunc is swapped out in tests.
+var readIORegistryFunc = readIORegistry
+
+// ioRegistryEntry is one registry service's properties and those of its
+// provider in the IOService plane, where a PCI GPU keeps its model and VRAM.
+type ioRegistryEntry struct {
+	props  map[string]any
+	parent map[string]any
+}
@@ -0,0 +1,206 @@
+//go:build darwin
+
+package sysmetrics
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"github.com/ebitengine/purego"
+)
+
+// IOKit and CoreFoundation are called through purego, so the release build
+// keeps CGO_ENABLED=0 and still reads the registry without spawning
+// system_profiler or ioreg.
+const (
+	ioKitPath          = "/System/Library/Frameworks/IOKit.framework/IOKit"
+	coreFoundationPath = "/System/Library/Frameworks/CoreFoundation.framework/CoreFoundation"
+
+	kCFStringEncodingUTF8 = 0x08000100
+	kCFNumberSInt64Type   = 4
+	kCFNumberFloat64Type  = 6
+)
+
+var ioKit struct {
+	IOServiceMatching                 func(name string) uintptr
+	IOServiceGetMatchingServices      func(mainPort uint32, matching uintptr, iterator *uint32) int32
+	IOIteratorNext                    func(iterator uint32) uint32
+	IORegistryEntryCreateCFProperties func(entry uint32, properties *uintptr, allocator uintptr, options uint32) int32
+	IORegistryEntryGetParentEntry     func(entry uint32, plane string, parent *uint32) int32
+	IOObjectRelease                   func(object uint32) int32
+
+	CFGetTypeID                       func(cf uintptr) uint64
+	CFDictionaryGetTypeID             func() uint64
+	CFArrayGetTypeID                  func() uint64
+	CFStringGetTypeID                 func() uint64
+	CFNumberGetTypeID                 func() uint64
+	CFBooleanGetTypeID                func() uint64
+	CFDataGetTypeID                   func() uint64
+	CFDictionaryGetCount              func(dict uintptr) int64
+	CFDictionaryGetKeysAndValues      func(dict uintptr, keys, values *uintptr)
+	CFArrayGetCount                   func(array uintptr) int64
+	CFArrayGetValueAtIndex            func(array uintptr, index int64) uintptr
+	CFStringGetLength                 func(str uintptr) int64
+	CFStringGetMaximumSizeForEncoding func(length int64, encoding uint32) int64
+	CFStringGetCString                func(str uintptr, buffer *byte, size int64, encoding uint32) bool
+	CFNumberIsFloatType               func(num uintptr) bool
+	CFNumberGetValue                  func(num uintptr, numberType int64, value unsafe.Pointer) bool
+	CFBooleanGetValue                 func(boolean uintptr) bool
+	CFDataGetLength                   func(data uintptr) int64
+	CFDataGetBytePtr                  func(data uintptr) unsafe.Pointer
+	CFRelease                         func(cf uintptr)
+
+	err error
+}
+
+var loadIOKitOnce sync.Once
+
+func loadIOKit() error {
+	loadIOKitOnce.Do(func() {
+		iokit, err := purego.Dlopen(ioKitPath, purego.RTLD_LAZY|purego.RTLD_GLOBAL)
+		if err != nil {
+			ioKit.err = err
+			return
+		}
+		cf, err := purego.Dlopen(coreFoundationPath, purego.RTLD_LAZY|purego.RTLD_GLOBAL)
+		if err != nil {
+			ioKit.err = err
+			return
+		}
+		purego.RegisterLibFunc(&ioKit.IOServiceMatching, iokit, "IOServiceMatching")
+		purego.RegisterLibFunc(&ioKit.IOServiceGetMatchingServices, iokit, "IOServiceGetMatchingServices")
+		purego.RegisterLibFunc(&ioKit.IOIteratorNext, iokit, "IOIteratorNext")
+		purego.RegisterLibFunc(&ioKit.IORegistryEntryCreateCFProperties, iokit, "IORegistryEntryCreateCFProperties")
+		purego.RegisterLibFunc(&ioKit.IORegistryEntryGetParentEntry, iokit, "IORegistryEntryGetParentEntry")
+		purego.RegisterLibFunc(&ioKit.IOObjectRelease, iokit, "IOObjectRelease")
+
+		purego.RegisterLibFunc(&ioKit.CFGetTypeID, cf, "CFGetTypeID")
+		purego.RegisterLibFunc(&ioKit.CFDictionaryGetTypeID, cf, "CFDictionaryGetTypeID")
+		purego.RegisterLibFunc(&ioKit.CFArrayGetTypeID, cf, "CFArrayGetTypeID")
+		purego.RegisterLibFunc(&ioKit.CFStringGetTypeID, cf, "CFStringGetTypeID")
+		purego.RegisterLibFunc(&ioKit.CFNumberGetTypeID, cf, "CFNumberGetTypeID")
+		purego.RegisterLibFunc(&ioKit.CFBooleanGetTypeID, cf, "CFBooleanGetTypeID")
+		purego.RegisterLibFunc(&ioKit.CFDataGetTypeID, cf, "CFDataGetTypeID")
+		purego.RegisterLibFunc(&ioKit.CFDictionaryGetCount, cf, "CFDictionaryGetCount")
+		purego.RegisterLibFunc(&ioKit.CFDictionaryGetKeysAndValues, cf, "CFDictionaryGetKeysAndValues")
+		purego.RegisterLibFunc(&ioKit.CFArrayGetCount, cf, "CFArrayGetCount")
+		purego.RegisterLibFunc(&ioKit.CFArrayGetValueAtIndex, cf, "CFArrayGetValueAtIndex")
+		purego.RegisterLibFunc(&ioKit.CFStringGetLength, cf, "CFStringGetLength")
+		purego.RegisterLibFunc(&ioKit.CFStringGetMaximumSizeForEncoding, cf, "CFStringGetMaximumSizeForEncoding")
+		purego.RegisterLibFunc(&ioKit.CFStringGetCString, cf, "CFStringGetCString")
+		purego.RegisterLibFunc(&ioKit.CFNumberIsFloatType, cf, "CFNumberIsFloatType")
+		purego.RegisterLibFunc(&ioKit.CFNumberGetValue, cf, "CFNumberGetValue")
+		purego.RegisterLibFunc(&ioKit.CFBooleanGetValue, cf, "CFBooleanGetValue")
+		purego.RegisterLibFunc(&ioKit.CFDataGetLength, cf, "CFDataGetLength")
+		purego.RegisterLibFunc(&ioKit.CFDataGetBytePtr, cf, "CFDataGetBytePtr")
+		purego.RegisterLibFunc(&ioKit.CFRelease, cf, "CFRelease")
+	})
+	return ioKit.err
+}
+
+// readIORegistry returns the properties of every service of the given
+// class, like `ioreg -a -r -d 1 -c class`, with its provider's properties
+// alongside.
+func readIORegistry(class string) ([]ioRegistryEntry, error) {
+	if err := loadIOKit(); err != nil {
+		return nil, err
+	}
+	var iterator uint32
+	// IOServiceGetMatchingServices consumes the matching dictionary.
+	if kr := ioKit.IOServiceGetMatchingServices(0, ioKit.IOServiceMatching(class), &iterator); kr != 0 {
+		return nil, fmt.Errorf("IOServiceGetMatchingServices(%s): %#x", class, kr)
+	}
+	defer ioKit.IOObjectRelease(iterator)
+
+	var entries []ioRegistryEntry
+	for service := ioKit.IOIteratorNext(iterator); service != 0; service = ioKit.IOIteratorNext(iterator) {
+		entry := ioRegistryEntry{props: ioRegistryProperties(service)}
+		var parent uint32
+		if ioKit.IORegistryEntryGetParentEntry(service, "IOService", &parent) == 0 {
+			entry.parent = ioRegistryProperties(parent)
+			ioKit.IOObjectRelease(parent)
+		}
+		ioKit.IOObjectRelease(service)
+		if entry.props != nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func ioRegistryProperties(entry uint32) map[string]any {
+	var props uintptr
+	if ioKit.IORegistryEntryCreateCFProperties(entry, &props, 0, 0) != 0 || props == 0 {
+		return nil
+	}
+	defer ioKit.CFRelease(props)
+	dict, _ := cfValue(props).(map[string]any)
+	return dict
+}
+
+// cfValue converts a CoreFoundation property into the shapes decodePlist
+// produces, so registry reads and ioreg output share their parsers.
+// Non-negative integers are uint64; CFData becomes []byte.
+func cfValue(ref uintptr) any {
+	if ref == 0 {
+		return nil
+	}
+	switch ioKit.CFGetTypeID(ref) {
+	case ioKit.CFDictionaryGetTypeID():
+		n := ioKit.CFDictionaryGetCount(ref)
+		dict := make(map[string]any, n)
+		if n == 0 {
+			return dict
+		}
+		keys := make([]uintptr, n)
+		values := make([]uintptr, n)
+		ioKit.CFDictionaryGetKeysAndValues(ref, &keys[0], &values[0])
+		for i := range keys {
+			if key, ok := cfValue(keys[i]).(string); ok {
+				dict[key] = cfValue(values[i])
+			}
+		}
+		return dict
+	case ioKit.CFArrayGetTypeID():
+		n := ioKit.CFArrayGetCount(ref)
+		list := make([]any, 0, n)
+		for i := range n {
+			list = append(list, cfValue(ioKit.CFArrayGetValueAtIndex(ref, i)))
+		}
+		return list
+	case ioKit.CFStringGetTypeID():
+		size := ioKit.CFStringGetMaximumSizeForEncoding(ioKit.CFStringGetLength(ref), kCFStringEncodingUTF8) + 1
+		buf := make([]byte, size)
+		if !ioKit.CFStringGetCString(ref, &buf[0], size, kCFStringEncodingUTF8) {
+			return ""
+		}
+		for i, b := range buf {
+			if b == 0 {
+				return string(buf[:i])
+			}
+		}
+		return string(buf)
+	case ioKit.CFNumberGetTypeID():
+		if ioKit.CFNumberIsFloatType(ref) {
+			var f float64
+			ioKit.CFNumberGetValue(ref, kCFNumberFloat64Type, unsafe.Pointer(&f))
+			return f
+		}
+		var n int64
+		ioKit.CFNumberGetValue(ref, kCFNumberSInt64Type, unsafe.Pointer(&n))
+		if n >= 0 {
+			return uint64(n)
+		}
+		return n
+	case ioKit.CFBooleanGetTypeID():
+		return ioKit.CFBooleanGetValue(ref)
+	case ioKit.CFDataGetTypeID():
+		n := ioKit.CFDataGetLength(ref)
+		if n == 0 {
+			return []byte{}
+		}
+		return append([]byte(nil), unsafe.Slice((*byte)(ioKit.CFDataGetBytePtr(ref)), n)...)
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+//go:build !darwin
+
+package sysmetrics
+
+func readIORegistry(string) ([]ioRegistryEntry, error) {
+	return nil, errIORegistryUnavailable
+}
@@ -0,0 +1,124 @@
+package sysmetrics
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// Leak detection samples each process's resident memory once a minute and
+// flags one whose RSS has only grown across enough samples to rule out a
+// warm-up spike. Dips under leakDipTolerance are treated as allocator noise;
+// a larger drop restarts the process's run.
+const (
+	leakSampleInterval = time.Minute
+	leakMinSamples     = 10
+	leakMinGrowth      = 100 << 20 // bytes
+	leakMinGrowthRatio = 0.25
+	leakDipTolerance   = 0.02
+	leakSuspectLimit   = 5
+)
+
+// LeakSuspect is a process whose memory has grown steadily since Since.
+type LeakSuspect struct {
+	PID      int       `json:"pid"`
+	Name     string    `json:"name"`
+	StartRSS uint64    `json:"start_rss"`
+	RSS      uint64    `json:"rss"`
+	Since    time.Time `json:"since"`
+	Samples  int       `json:"samples"`
+}
+
+// Growth is how much resident memory the process gained since Since.
+func (s LeakSuspect) Growth() uint64 {
+	return s.RSS - s.StartRSS
+}
+
+type leakTrack struct {
+	name       string
+	startRSS   uint64
+	lastRSS    uint64
+	since      time.Time
+	lastSample time.Time
+	samples    int
+}
+
+type LeakWatcher struct {
+	tracks map[processIdentity]*leakTrack
+}
+
+func NewLeakWatcher() *LeakWatcher {
+	return &LeakWatcher{tracks: make(map[processIdentity]*leakTrack)}
+}
+
+func (w *LeakWatcher) Update(now time.Time, processes []ProcessInfo) []LeakSuspect {
+	if w == nil {
+		return nil
+	}
+	seen := make(map[processIdentity]bool, len(processes))
+	for _, proc := range processes {
+		if proc.PID <= 0 || proc.MemoryBytes == 0 {
+			continue
+		}
+		key := processIdentity{pid: proc.PID, ppid: proc.PPID, command: proc.Command}
+		seen[key] = true
+
+		track, ok := w.tracks[key]
+		if !ok {
+			w.tracks[key] = &leakTrack{
+				name:       proc.Name,
+				startRSS:   proc.MemoryBytes,
+				lastRSS:    proc.MemoryBytes,
+				since:      now,
+				lastSample: now,
+				samples:    1,
+			}
+			continue
+		}
+		if now.Sub(track.lastSample) < leakSampleInterval {
+			continue
+		}
+		track.lastSample = now
+		rss := proc.MemoryBytes
+		if float64(rss) < float64(track.lastRSS)*(1-leakDipTolerance) {
+			track.startRSS, track.lastRSS, track.since, track.samples = rss, rss, now, 1
+			continue
+		}
+		// lastRSS is the run's high-water mark, so a small dip is ignored.
+		track.lastRSS = max(track.lastRSS, rss)
+		track.samples++
+	}
+
+	for key := range w.tracks {
+		if !seen[key] {
+			delete(w.tracks, key)
+		}
+	}
+	return w.Snapshot()
+}
+
+func (w *LeakWatcher) Snapshot() []LeakSuspect {
+	if w == nil {
+		return nil
+	}
+	var suspects []LeakSuspect
+	for key, track := range w.tracks {
+		growth := track.lastRSS - track.startRSS
+		if track.samples < leakMinSamples || growth < leakMinGrowth ||
+			float64(growth) < float64(track.startRSS)*leakMinGrowthRatio {
+			continue
+		}
+		suspects = append(suspects, LeakSuspect{
+			PID:      key.pid,
+			Name:     track.name,
+			StartRSS: track.startRSS,
+			RSS:      track.lastRSS,
+			Since:    track.since,
+			Samples:  track.samples,
+		})
+	}
+	slices.SortFunc(suspects, func(a, b LeakSuspect) int {
+		return cmp.Or(cmp.Compare(b.Growth(), a.Growth()), cmp.Compare(a.PID, b.PID))
+	})
+	return suspects[:min(len(suspects), leakSuspectLimit)]
+}
@@ -0,0 +1,44 @@
+package sysmetrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeakWatcherFlagsSteadyGrowth(t *testing.T) {
+	w := NewLeakWatcher()
+	start := time.Date(2026, 3, 12, 8, 0, 0, 0, time.UTC)
+	const mib = 1 << 20
+
+	var suspects []LeakSuspect
+	for i := range leakMinSamples {
+		now := start.Add(time.Duration(i) * leakSampleInterval)
+		suspects = w.Update(now, []ProcessInfo{
+			{PID: 10, PPID: 1, Name: "Electron", Command: "/Applications/App.app/Electron", MemoryBytes: uint64(400+40*i) * mib},
+			// Sawtooth: grows, then the GC gives most of it back.
+			{PID: 20, PPID: 1, Name: "node", Command: "node server.js", MemoryBytes: uint64(400+100*(i%3)) * mib},
+			{PID: 30, PPID: 1, Name: "idle", Command: "idle", MemoryBytes: 50 * mib},
+		})
+		if i < leakMinSamples-1 && len(suspects) != 0 {
+			t.Fatalf("sample %d: flagged too early: %+v", i, suspects)
+		}
+	}
+	if len(suspects) != 1 || suspects[0].PID != 10 {
+		t.Fatalf("suspects = %+v", suspects)
+	}
+	if got := suspects[0].Growth(); got != 360*mib || !suspects[0].Since.Equal(start) {
+		t.Fatalf("growth = %d since %v", got, suspects[0].Since)
+	}
+
+	// Refreshes faster than the sample interval do not count as samples.
+	later := start.Add(time.Duration(leakMinSamples-1)*leakSampleInterval + time.Second)
+	w.Update(later, []ProcessInfo{{PID: 10, PPID: 1, Name: "Electron", Command: "/Applications/App.app/Electron", MemoryBytes: 100 * mib}})
+	if got := w.Snapshot(); len(got) != 1 || got[0].Samples != leakMinSamples {
+		t.Fatalf("sub-interval refresh changed the run: %+v", got)
+	}
+
+	// A process that exits is forgotten.
+	if got := w.Update(later.Add(leakSampleInterval), nil); len(got) != 0 {
+		t.Fatalf("exited process still flagged: %+v", got)
+	}
+}
@@ -0,0 +1,1542 @@
+package sysmetrics
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/host"
+	"github.com/shirou/gopsutil/v4/net"
+	"github.com/tw93/mole/internal/debuglog"
+)
+
+// RingBuffer is a fixed-size circular buffer for float64 values.
+type RingBuffer struct {
+	data  []float64
+	index int // Current insert position (oldest value)
+	size  int // Number of valid elements
+	cap   int // Total capacity
+}
+
+func NewRingBuffer(capacity int) *RingBuffer {
+	return &RingBuffer{
+		data: make([]float64, capacity),
+		cap:  capacity,
+	}
+}
+
+func (rb *RingBuffer) Add(val float64) {
+	rb.data[rb.index] = val
+	rb.index = (rb.index + 1) % rb.cap
+	if rb.size < rb.cap {
+		rb.size++
+	}
+}
+
+// Slice returns the data in chronological order (oldest to newest).
+func (rb *RingBuffer) Slice() []float64 {
+	if rb.size == 0 {
+		return nil
+	}
+	res := make([]float64, rb.size)
+	if rb.size < rb.cap {
+		// Not full yet: data is at [0 : size]
+		copy(res, rb.data[:rb.size])
+	} else {
+		// Full: oldest is at index, then wrapped
+		// data: [4, 5, 1, 2, 3] (cap=5, index=2, oldest=1)
+		// want: [1, 2, 3, 4, 5]
+		// part1: [index:] -> [1, 2, 3]
+		// part2: [:index] -> [4, 5]
+		copy(res, rb.data[rb.index:])
+		copy(res[rb.cap-rb.index:], rb.data[:rb.index])
+	}
+	return res
+}
+
+type MetricsSnapshot struct {
+	CollectedAt    time.Time    `json:"collected_at"`
+	Host           string       `json:"host"`
+	Platform       string       `json:"platform"`
+	Uptime         string       `json:"uptime"`
+	UptimeSeconds  uint64       `json:"uptime_seconds"`
+	BootTime       time.Time    `json:"boot_time,omitzero"`
+	Procs          uint64       `json:"procs"`
+	Hardware       HardwareInfo `json:"hardware"`
+	HealthScore    int          `json:"health_score"`     // 0-100 system health score
+	HealthScoreMsg string       `json:"health_score_msg"` // Brief explanation
+
+	CPU            CPUStatus          `json:"cpu"`
+	GPU            []GPUStatus        `json:"gpu"`
+	Memory         MemoryStatus       `json:"memory"`
+	Disks          []DiskStatus       `json:"disks"`
+	TrashSize      uint64             `json:"trash_size"`
+	TrashApprox    bool               `json:"trash_approx"`
+	DiskIO         DiskIOStatus       `json:"disk_io"`
+	DiskHealth     []DiskHealth       `json:"disk_health"`
+	Storage        StorageStatus      `json:"storage"`
+	Network        []NetworkStatus    `json:"network"`
+	NetworkHistory NetworkHistory     `json:"network_history"`
+	NetworkProcs   []ProcessNetwork   `json:"network_processes"`
+	Proxy          ProxyStatus        `json:"proxy"`
+	VPN            VPNStatus          `json:"vpn"`
+	PublicIP       PublicIPStatus     `json:"public_ip"`
+	Latency        []LatencyProbe     `json:"latency"`
+	DNS            DNSStatus          `json:"dns"`
+	Containers     ContainerStatus    `json:"containers"`
+	Kubernetes     KubernetesStatus   `json:"kubernetes"`
+	VMs            []VirtualMachine   `json:"virtual_machines,omitempty"`
+	Peripherals    []Peripheral       `json:"peripherals,omitempty"`
+	Displays       []Display          `json:"displays,omitempty"`
+	LaunchItems    []LaunchItem       `json:"launch_items,omitempty"`
+	Services       []ServiceStatus    `json:"services,omitempty"`
+	TimeMachine    TimeMachineStatus  `json:"time_machine"`
+	CrashReports   CrashReports       `json:"crash_reports"`
+	Spotlight      SpotlightStatus    `json:"spotlight"`
+	ICloud         ICloudStatus       `json:"icloud"`
+	Clock          ClockStatus        `json:"clock"`
+	Session        SessionInfo        `json:"session"`
+	PowerEvents    []PowerEvent       `json:"power_events,omitempty"`
+	Limits         LimitsStatus       `json:"limits"`
+	Ports          PortsStatus        `json:"ports"`
+	Batteries      []BatteryStatus    `json:"batteries"`
+	Thermal        ThermalStatus      `json:"thermal"`
+	Sensors        []SensorReading    `json:"sensors"`
+	Power          PowerStatus        `json:"power"`
+	ANE            ANEStatus          `json:"ane"`
+	Bluetooth      []BluetoothDevice  `json:"bluetooth"`
+	TopProcesses   []ProcessInfo      `json:"top_processes"`
+	ProcessWatch   ProcessWatchConfig `json:"process_watch"`
+	ProcessSort    string             `json:"process_sort"`
+	ProcessAlerts  []ProcessAlert     `json:"process_alerts"`
+	LeakSuspects   []LeakSuspect      `json:"leak_suspects,omitempty"`
+	Stuck          []StuckProcess     `json:"stuck_processes,omitempty"`
+	Collectors     []CollectorStat    `json:"collectors,omitempty"`
+	SpeedTests     []SpeedTestResult  `json:"speed_tests"`
+}
+
+type HardwareInfo struct {
+	Model       string `json:"model"`        // MacBook Pro 14-inch, 2021
+	CPUModel    string `json:"cpu_model"`    // Apple M1 Pro / Intel Core i7
+	TotalRAM    string `json:"total_ram"`    // 16GB
+	DiskSize    string `json:"disk_size"`    // 512GB
+	OSVersion   string `json:"os_version"`   // macOS Sonoma 14.5
+	RefreshRate string `json:"refresh_rate"` // 120Hz / 60Hz
+}
+
+type DiskIOStatus struct {
+	ReadRate     float64        `json:"read_rate"`  // MB/s
+	WriteRate    float64        `json:"write_rate"` // MB/s
+	ReadIOPS     float64        `json:"read_iops"`
+	WriteIOPS    float64        `json:"write_iops"`
+	LatencyMs    float64        `json:"latency_ms"` // Mean service time per operation
+	Devices      []DiskDeviceIO `json:"devices,omitempty"`
+	ReadHistory  []float64      `json:"read_history,omitempty"`
+	WriteHistory []float64      `json:"write_history,omitempty"`
+}
+
+// DiskDeviceIO is one physical disk's share of DiskIOStatus, busiest first.
+type DiskDeviceIO struct {
+	Name      string  `json:"name"`
+	ReadRate  float64 `json:"read_rate"`
+	WriteRate float64 `json:"write_rate"`
+	ReadIOPS  float64 `json:"read_iops"`
+	WriteIOPS float64 `json:"write_iops"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// StorageStatus lists mounted volumes for the Storage card. APFS volumes in
+// one container share its free space, so Containers carries that pool.
+type StorageStatus struct {
+	Volumes    []StorageVolume `json:"volumes"`
+	Containers []APFSContainer `json:"containers,omitempty"`
+}
+
+type StorageVolume struct {
+	Name      string `json:"name"`
+	Mount     string `json:"mount,omitempty"`
+	Device    string `json:"device"`
+	Fstype    string `json:"fstype"`
+	Role      string `json:"role,omitempty"` // APFS role: System, Data, VM, ...
+	Used      uint64 `json:"used"`
+	Free      uint64 `json:"free"`
+	Total     uint64 `json:"total"`
+	Container string `json:"container,omitempty"` // APFS container device, e.g. disk3
+	External  bool   `json:"external"`
+}
+
+type APFSContainer struct {
+	Device    string   `json:"device"`
+	Total     uint64   `json:"total"`
+	Free      uint64   `json:"free"`
+	Purgeable uint64   `json:"purgeable"` // Startup container only
+	Volumes   []string `json:"volumes"`
+}
+
+// DiskHealth is one drive's SMART verdict. Wear and spare are NVMe only;
+// the diskutil fallback on macOS carries just the pass/fail bit.
+type DiskHealth struct {
+	Device         string   `json:"device"`
+	Model          string   `json:"model,omitempty"`
+	Status         string   `json:"status"`                    // ok, degraded, failing
+	WearPercent    int      `json:"wear_percent,omitempty"`    // NVMe percentage used
+	SparePercent   int      `json:"spare_percent,omitempty"`   // NVMe available spare
+	SpareThreshold int      `json:"spare_threshold,omitempty"` // NVMe spare warning threshold
+	MediaErrors    uint64   `json:"media_errors"`
+	Temperature    float64  `json:"temperature,omitempty"`
+	Source         string   `json:"source"` // smartctl or diskutil
+	Reasons        []string `json:"reasons,omitempty"`
+}
+
+type ProcessInfo struct {
+	PID         int     `json:"pid"`
+	PPID        int     `json:"ppid"`
+	Name        string  `json:"name"`
+	Command     string  `json:"command"`
+	CPU         float64 `json:"cpu"`
+	Memory      float64 `json:"memory"` // Percent of physical memory, kept for compatibility.
+	MemoryBytes uint64  `json:"memory_bytes,omitempty"`
+	Energy      float64 `json:"energy,omitempty"` // macOS energy impact, as in Activity Monitor
+}
+
+// StuckProcess is a zombie, or a process blocked in uninterruptible wait.
+// A zombie lingers until its parent reaps it, so PPID is the one to fix.
+type StuckProcess struct {
+	PID   int    `json:"pid"`
+	PPID  int    `json:"ppid"`
+	Name  string `json:"name"`
+	State string `json:"state"` // "zombie" or "uninterruptible"
+}
+
+type CPUStatus struct {
+	Usage            float64      `json:"usage"`
+	PerCore          []float64    `json:"per_core"`
+	PerCoreEstimated bool         `json:"per_core_estimated"`
+	Load1            float64      `json:"load1"`
+	Load5            float64      `json:"load5"`
+	Load15           float64      `json:"load15"`
+	CoreCount        int          `json:"core_count"`
+	LogicalCPU       int          `json:"logical_cpu"`
+	PCoreCount       int          `json:"p_core_count"` // Performance cores (Apple Silicon)
+	ECoreCount       int          `json:"e_core_count"` // Efficiency cores (Apple Silicon)
+	Clusters         []CPUCluster `json:"clusters,omitempty"`
+}
+
+// CPUCluster is the usage of one Apple Silicon core cluster kind.
+type CPUCluster struct {
+	Name         string  `json:"name"` // "P" or "E"
+	Cores        int     `json:"cores"`
+	Usage        float64 `json:"usage"`
+	FrequencyMHz float64 `json:"frequency_mhz,omitempty"` // Needs powermetrics (root)
+}
+
+type GPUStatus struct {
+	Name        string  `json:"name"`
+	Usage       float64 `json:"usage"`
+	MemoryUsed  float64 `json:"memory_used"`
+	MemoryTotal float64 `json:"memory_total"`
+	CoreCount   int     `json:"core_count"`
+	Note        string  `json:"note"`
+	// SharedMemory marks an Apple Silicon GPU, whose MemoryUsed is unified
+	// memory wired for Metal rather than dedicated VRAM.
+	SharedMemory bool `json:"shared_memory,omitempty"`
+	// SampledAt is when Usage was measured. Between full refreshes the
+	// snapshot carries the last reading, so it can trail CollectedAt.
+	SampledAt time.Time `json:"sampled_at,omitzero"`
+
+	Processes []GPUProcess `json:"processes,omitempty"` // Busiest GPU clients, highest usage first
+}
+
+// GPUProcess is one process's share of a GPU's time, in percent.
+type GPUProcess struct {
+	GPU   int     `json:"gpu"`
+	PID   int     `json:"pid"`
+	Name  string  `json:"name"`
+	Usage float64 `json:"usage"`
+}
+
+type MemoryStatus struct {
+	Used        uint64  `json:"used"`
+	Total       uint64  `json:"total"`
+	Available   uint64  `json:"available"`
+	UsedPercent float64 `json:"used_percent"`
+	SwapUsed    uint64  `json:"swap_used"`
+	SwapTotal   uint64  `json:"swap_total"`
+	Cached      uint64  `json:"cached"`   // File cache that can be freed if needed
+	Pressure    string  `json:"pressure"` // macOS memory pressure: normal/warn/critical
+
+	Compressed    uint64  `json:"compressed"`      // macOS pages held by the memory compressor
+	SwapInRate    float64 `json:"swap_in_rate"`    // MB/s
+	SwapOutRate   float64 `json:"swap_out_rate"`   // MB/s
+	PageFaultRate float64 `json:"page_fault_rate"` // faults/s
+}
+
+type DiskStatus struct {
+	Mount       string  `json:"mount"`
+	Device      string  `json:"device"`
+	Used        uint64  `json:"used"`
+	Total       uint64  `json:"total"`
+	UsedPercent float64 `json:"used_percent"`
+	Fstype      string  `json:"fstype"`
+	External    bool    `json:"external"`
+}
+
+type NetworkStatus struct {
+	Name      string  `json:"name"`
+	RxRateMBs float64 `json:"rx_rate_mbs"`
+	TxRateMBs float64 `json:"tx_rate_mbs"`
+	IP        string  `json:"ip"`
+}
+
+// ProcessNetwork is one process's share of network traffic (macOS nettop).
+type ProcessNetwork struct {
+	PID       int     `json:"pid"`
+	Name      string  `json:"name"`
+	RxRateMBs float64 `json:"rx_rate_mbs"`
+	TxRateMBs float64 `json:"tx_rate_mbs"`
+}
+
+// NetworkHistory holds the global network usage history.
+type NetworkHistory struct {
+	RxHistory []float64 `json:"rx_history"`
+	TxHistory []float64 `json:"tx_history"`
+}
+
+const NetworkHistorySize = 120 // Increased history size for wider graph
+
+type ProxyStatus struct {
+	Enabled bool   `json:"enabled"`
+	Type    string `json:"type"` // HTTP, HTTPS, SOCKS, PAC, WPAD, TUN
+	Host    string `json:"host"`
+}
+
+// ContainerStatus is the Docker-compatible engine (Docker Desktop, OrbStack,
+// Colima) and its running containers.
+type ContainerStatus struct {
+	Runtime    string          `json:"runtime,omitempty"`
+	VMCPUs     int             `json:"vm_cpus,omitempty"`
+	VMMemory   uint64          `json:"vm_memory,omitempty"` // Bytes allocated to the engine's VM
+	Containers []ContainerInfo `json:"containers"`
+}
+
+type ContainerInfo struct {
+	Name        string  `json:"name"`
+	CPU         float64 `json:"cpu"` // Percent of one core, as docker stats reports it
+	MemoryBytes uint64  `json:"memory_bytes"`
+	MemoryLimit uint64  `json:"memory_limit"`
+	NetRx       uint64  `json:"net_rx"` // Cumulative bytes since the container started
+	NetTx       uint64  `json:"net_tx"`
+}
+
+// KubernetesStatus is the local cluster the current kubectl context points
+// at (kind, minikube, Docker Desktop, ...). Nodes and TopPods need
+// metrics-server and stay empty without it.
+type KubernetesStatus struct {
+	Context string        `json:"context,omitempty"`
+	Distro  string        `json:"distro,omitempty"`
+	Pods    KubePodCounts `json:"pods"`
+	Nodes   []KubeNode    `json:"nodes,omitempty"`
+	TopPods []KubePod     `json:"top_pods,omitempty"`
+}
+
+type KubePodCounts struct {
+	Total   int `json:"total"`
+	Running int `json:"running"` // Includes completed jobs
+	Pending int `json:"pending"`
+	Failing int `json:"failing"`
+}
+
+type KubeNode struct {
+	Name          string  `json:"name"`
+	CPUMilli      int     `json:"cpu_millicores"`
+	CPUPercent    float64 `json:"cpu_percent"`
+	MemoryBytes   uint64  `json:"memory_bytes"`
+	MemoryPercent float64 `json:"memory_percent"`
+}
+
+type KubePod struct {
+	Namespace   string `json:"namespace"`
+	Name        string `json:"name"`
+	CPUMilli    int    `json:"cpu_millicores"`
+	MemoryBytes uint64 `json:"memory_bytes"`
+}
+
+// VirtualMachine is a running guest and the host process backing it.
+// CPUPercent is that process's host CPU, 100 per busy core; CPUs and
+// MemoryAllocated are the guest's configuration when it can be read.
+type VirtualMachine struct {
+	Name            string  `json:"name"`
+	Hypervisor      string  `json:"hypervisor"`
+	PID             int     `json:"pid"`
+	CPUs            int     `json:"cpus,omitempty"`
+	MemoryAllocated uint64  `json:"memory_allocated,omitempty"`
+	CPUPercent      float64 `json:"cpu_percent"`
+	MemoryUsed      uint64  `json:"memory_used"` // Resident size of the host process
+}
+
+// Peripheral is an attached USB or Thunderbolt device. MaxSpeedMbps is the
+// fastest link the device (USB) or host port (Thunderbolt) supports, so a
+// dock or SSD stuck on a slower link shows SpeedMbps below it.
+type Peripheral struct {
+	Name         string  `json:"name"`
+	Vendor       string  `json:"vendor,omitempty"`
+	Bus          string  `json:"bus"` // "USB" or "Thunderbolt"
+	SpeedMbps    float64 `json:"speed_mbps,omitempty"`
+	MaxSpeedMbps float64 `json:"max_speed_mbps,omitempty"`
+	PowerMilli   int     `json:"power_ma,omitempty"` // Bus power used (macOS) or configured max (Linux)
+}
+
+// Display is a connected screen. Width and Height are the native mode;
+// LooksWidth and LooksHeight are the scaled size macOS renders at, zero when
+// unscaled.
+type Display struct {
+	Name        string  `json:"name"`
+	Connection  string  `json:"connection,omitempty"` // "Internal", "DisplayPort", "HDMI", ...
+	Width       int     `json:"width"`
+	Height      int     `json:"height"`
+	LooksWidth  int     `json:"looks_like_width,omitempty"`
+	LooksHeight int     `json:"looks_like_height,omitempty"`
+	RefreshHz   float64 `json:"refresh_hz,omitempty"`
+	HDR         bool    `json:"hdr,omitempty"`
+	Main        bool    `json:"main,omitempty"`
+}
+
+// LaunchItem is a launchd agent or daemon installed outside /System.
+// AddedAt is the plist's modification time; Recent marks the ones that
+// appeared in the last week.
+type LaunchItem struct {
+	Label   string    `json:"label"`
+	Scope   string    `json:"scope"` // "user agent", "agent", or "daemon"
+	Plist   string    `json:"plist"`
+	Program string    `json:"program,omitempty"`
+	Running bool      `json:"running"`
+	PID     int       `json:"pid,omitempty"`
+	AddedAt time.Time `json:"added_at"`
+	Recent  bool      `json:"recent,omitempty"`
+}
+
+// ServiceStatus is a Homebrew service or user launchd job that failed or is
+// using notable CPU. ExitCode is the last exit status; launchd reports a
+// job killed by a signal as the negated signal number.
+type ServiceStatus struct {
+	Name     string  `json:"name"`
+	Manager  string  `json:"manager"` // "brew" or "launchd"
+	State    string  `json:"state"`   // "running", "stopped", or "error"
+	PID      int     `json:"pid,omitempty"`
+	ExitCode int     `json:"exit_code,omitempty"`
+	CPU      float64 `json:"cpu_percent,omitempty"`
+}
+
+// TimeMachineStatus is the backup destination that last completed, plus
+// the running session's phase and progress. Percent is -1 when idle or
+// when the phase has no estimate. Overdue means no backup has completed
+// within --backup-warn-days.
+type TimeMachineStatus struct {
+	Destination   string    `json:"destination,omitempty"`
+	LastBackup    time.Time `json:"last_backup,omitzero"`
+	Running       bool      `json:"running"`
+	Phase         string    `json:"phase,omitempty"`
+	Percent       float64   `json:"percent"`
+	TimeRemaining int       `json:"time_remaining_seconds,omitempty"`
+	Overdue       bool      `json:"overdue"`
+}
+
+// CrashReports counts diagnostic reports from the last 7 days. TopProcess
+// is the process with the most crash reports in that window.
+type CrashReports struct {
+	Crashes    int       `json:"crashes"`
+	Hangs      int       `json:"hangs"`
+	Panics     int       `json:"panics"`
+	TopProcess string    `json:"top_process,omitempty"`
+	TopCount   int       `json:"top_count,omitempty"`
+	LastPanic  time.Time `json:"last_panic,omitzero"`
+}
+
+// SpotlightStatus is Spotlight's indexing activity. CPU is the combined
+// percent of mds, mds_stores, and the mdworkers, 100 per busy core.
+type SpotlightStatus struct {
+	Indexing bool              `json:"indexing"`
+	CPU      float64           `json:"cpu_percent"`
+	Workers  int               `json:"workers"`
+	Volumes  []SpotlightVolume `json:"volumes,omitempty"`
+}
+
+// SpotlightVolume is one volume's indexing state from mdutil.
+type SpotlightVolume struct {
+	Path  string `json:"path"`
+	State string `json:"state"` // "enabled", "disabled", or "unknown"
+}
+
+// ICloudStatus is iCloud Drive sync activity. CPU is bird plus cloudd; the
+// pending counts are items brctl lists as waiting to transfer.
+type ICloudStatus struct {
+	Syncing          bool    `json:"syncing"`
+	CPU              float64 `json:"cpu_percent"`
+	PendingUploads   int     `json:"pending_uploads"`
+	PendingDownloads int     `json:"pending_downloads"`
+}
+
+// ClockStatus is time sync against the configured NTP server. OffsetMs
+// follows NTP's sign: positive when the local clock is behind. Sync comes
+// from timedatectl and is "unknown" on macOS, where timed's state needs
+// root. Drifting means the offset exceeds --clock-drift-warn.
+type ClockStatus struct {
+	Server   string  `json:"server,omitempty"`
+	Sync     string  `json:"sync,omitempty"` // "synced", "unsynced", or "unknown"
+	OffsetMs float64 `json:"offset_ms"`
+	Measured bool    `json:"measured"`
+	Drifting bool    `json:"drifting"`
+}
+
+// SessionInfo is who is logged in and the last wake from sleep. WakeReason
+// is the kernel's raw string, such as "EC.LidOpen (User)"; macOS only.
+type SessionInfo struct {
+	Users      []string  `json:"users"`
+	Sessions   int       `json:"sessions"`
+	WakeReason string    `json:"wake_reason,omitempty"`
+	WakeTime   time.Time `json:"wake_time,omitzero"`
+}
+
+// PowerEvent is a sleep or wake from the pmset log. Dark wakes are the
+// screen-off maintenance wakes that drain a sleeping laptop. Charge is the
+// battery percent at the time, or -1 on desktops.
+type PowerEvent struct {
+	At     time.Time `json:"at"`
+	Kind   string    `json:"kind"` // "sleep", "wake", or "darkwake"
+	Reason string    `json:"reason,omitempty"`
+	Charge int       `json:"charge"`
+}
+
+// LimitsStatus compares open files, processes, and threads with the
+// kernel's caps. MaxThreads is Linux only. TopFDs are the processes holding
+// the most descriptors, among those this user can inspect.
+type LimitsStatus struct {
+	OpenFiles       int          `json:"open_files"`
+	MaxFiles        int          `json:"max_files"`
+	MaxFilesPerProc int          `json:"max_files_per_proc"`
+	Procs           int          `json:"procs"`
+	MaxProcs        int          `json:"max_procs"`
+	Threads         int          `json:"threads"`
+	MaxThreads      int          `json:"max_threads,omitempty"`
+	TopFDs          []ProcessFDs `json:"top_fds,omitempty"`
+}
+
+// ProcessFDs is one process's open descriptor count.
+type ProcessFDs struct {
+	PID  int    `json:"pid"`
+	Name string `json:"name"`
+	FDs  int    `json:"fds"`
+}
+
+// SpeedTestResult is one on-demand speed test run with the "n" key.
+type SpeedTestResult struct {
+	At           time.Time `json:"at"`
+	Server       string    `json:"server"`
+	DownloadMbps float64   `json:"download_mbps"`
+	UploadMbps   float64   `json:"upload_mbps"`
+	LatencyMs    float64   `json:"latency_ms"`
+}
+
+// DNSStatus is the system resolver configuration with a timed lookup
+// against each nameserver.
+type DNSStatus struct {
+	Servers       []DNSServer `json:"servers"`
+	SearchDomains []string    `json:"search_domains,omitempty"`
+}
+
+type DNSServer struct {
+	Address   string  `json:"address"`
+	LatencyMs float64 `json:"latency_ms"`
+	Slow      bool    `json:"slow"`
+	Error     string  `json:"error,omitempty"` // timeout or failed
+}
+
+// LatencyProbe is one ping burst to a --ping-targets host.
+type LatencyProbe struct {
+	Target      string    `json:"target"` // As configured, e.g. "gateway"
+	Host        string    `json:"host"`   // Address actually pinged
+	Sent        int       `json:"sent"`
+	LossPercent float64   `json:"loss_percent"`
+	MinMs       float64   `json:"min_ms"`
+	AvgMs       float64   `json:"avg_ms"`
+	MaxMs       float64   `json:"max_ms"`
+	JitterMs    float64   `json:"jitter_ms"` // Standard deviation of the round trips
+	History     []float64 `json:"history,omitempty"`
+}
+
+// PublicIPStatus is the address the internet sees, resolved only when the
+// user opts in with --public-ip.
+type PublicIPStatus struct {
+	IPv4      string    `json:"ipv4,omitempty"`
+	IPv6      string    `json:"ipv6,omitempty"`
+	City      string    `json:"city,omitempty"`
+	Region    string    `json:"region,omitempty"`
+	Country   string    `json:"country,omitempty"`
+	ASN       string    `json:"asn,omitempty"`
+	Org       string    `json:"org,omitempty"`
+	CheckedAt time.Time `json:"checked_at,omitzero"`
+}
+
+// VPNStatus covers system VPN services, tunnel interfaces, and Tailscale.
+type VPNStatus struct {
+	Connections []VPNConnection  `json:"connections"`
+	Tunnels     []string         `json:"tunnels"` // "utun4 10.8.0.2"
+	Tailscale   *TailscaleStatus `json:"tailscale,omitempty"`
+}
+
+type VPNConnection struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // e.g. VPN:com.wireguard.macos, PPP:L2TP
+}
+
+type TailscaleStatus struct {
+	State       string `json:"state"` // Running, Stopped, NeedsLogin
+	Tailnet     string `json:"tailnet,omitempty"`
+	HostName    string `json:"host_name,omitempty"`
+	IP          string `json:"ip,omitempty"`
+	ExitNode    string `json:"exit_node,omitempty"`
+	Peers       int    `json:"peers"`
+	PeersOnline int    `json:"peers_online"`
+}
+
+// PortsStatus is the listening sockets and established TCP connections on
+// this machine, per owning process.
+type PortsStatus struct {
+	Listeners   []ListenPort        `json:"listeners"`
+	Connections []ConnectionSummary `json:"connections"` // Top processes by established count
+	Established int                 `json:"established"`
+}
+
+type ListenPort struct {
+	Proto   string `json:"proto"` // tcp or udp
+	Address string `json:"address"`
+	Port    int    `json:"port"`
+	PID     int    `json:"pid"`
+	Process string `json:"process"`
+	New     bool   `json:"new"` // Appeared after status started, within the last 10m
+}
+
+type ConnectionSummary struct {
+	PID         int    `json:"pid"`
+	Process     string `json:"process"`
+	Established int    `json:"established"`
+}
+
+type BatteryStatus struct {
+	Percent    float64 `json:"percent"`
+	Status     string  `json:"status"`
+	TimeLeft   string  `json:"time_left"`
+	Health     string  `json:"health"`
+	CycleCount int     `json:"cycle_count"`
+	Capacity   int     `json:"capacity"` // Maximum capacity percentage (e.g., 85 means 85% of original)
+
+	DesignCapacity    int     `json:"design_capacity,omitempty"` // Factory capacity in CapacityUnit
+	MaxCapacity       int     `json:"max_capacity,omitempty"`    // Current full-charge capacity in CapacityUnit
+	CapacityUnit      string  `json:"capacity_unit,omitempty"`   // "mAh" (macOS, most Linux) or "mWh"
+	ChargeWatts       float64 `json:"charge_watts,omitempty"`    // Power flowing into the battery while charging
+	TimeToFull        int     `json:"time_to_full_min,omitempty"`
+	TimeToEmpty       int     `json:"time_to_empty_min,omitempty"`
+	OptimizedCharging bool    `json:"optimized_charging"` // On AC but holding below full (optimized charging or a charge limit)
+}
+
+type ThermalStatus struct {
+	CPUTemp      float64 `json:"cpu_temp"`
+	GPUTemp      float64 `json:"gpu_temp"`
+	BatteryTemp  float64 `json:"battery_temp"` // Battery temperature in Celsius when exposed by AppleSmartBattery
+	FanSpeed     int     `json:"fan_speed"`
+	FanCount     int     `json:"fan_count"`
+	SystemPower  float64 `json:"system_power"`  // System power consumption in Watts
+	AdapterPower float64 `json:"adapter_power"` // AC adapter max power in Watts
+	BatteryPower float64 `json:"battery_power"` // Battery charge/discharge power in Watts (positive = discharging)
+}
+
+type SensorReading struct {
+	Label string  `json:"label"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+	Note  string  `json:"note"`
+	// History holds recent values, oldest first, for the Sensors card graph.
+	History []float64 `json:"history,omitempty"`
+}
+
+// PowerStatus is the SoC/package power draw. The average covers the last
+// few full refreshes so a spike can be told apart from sustained load.
+type PowerStatus struct {
+	PackageWatts    float64 `json:"package_watts"`
+	CPUWatts        float64 `json:"cpu_watts"`
+	GPUWatts        float64 `json:"gpu_watts"`
+	ANEWatts        float64 `json:"ane_watts"`
+	AvgPackageWatts float64 `json:"avg_package_watts"`
+	Source          string  `json:"source,omitempty"` // "powermetrics" or "rapl"
+}
+
+// ANEStatus is the Apple Neural Engine's draw from powermetrics. The
+// sampler reports no ANE residency, so Usage is estimated from the draw
+// against the engine's peak (aneMaxWatts).
+type ANEStatus struct {
+	Watts float64 `json:"watts"`
+	Usage float64 `json:"usage"` // Percent, estimated
+}
+
+type BluetoothDevice struct {
+	Name      string `json:"name"`
+	Address   string `json:"address,omitempty"`
+	Connected bool   `json:"connected"`
+	Battery   string `json:"battery"` // For earbuds, the lower of the two buds
+
+	// Earbuds such as AirPods report each bud and the case separately.
+	BatteryLeft  string `json:"battery_left,omitempty"`
+	BatteryRight string `json:"battery_right,omitempty"`
+	BatteryCase  string `json:"battery_case,omitempty"`
+
+	// Link quality for connected devices, where the platform reports it.
+	RSSI      int     `json:"rssi,omitempty"`       // dBm; closer to 0 is stronger
+	Codec     string  `json:"codec,omitempty"`      // Active audio codec (SBC, AAC, aptX, LDAC)
+	LatencyMs float64 `json:"latency_ms,omitempty"` // Audio output latency
+
+	SampledAt time.Time `json:"sampled_at,omitzero"` // When this state was read
+}
+
+type Collector struct {
+	// Static cache.
+	cachedHW  HardwareInfo
+	lastHWAt  time.Time
+	hasStatic bool
+
+	// Slow cache (30s-1m).
+	bluetooth    staleCache[bluetoothReading]
+	btProfilerAt time.Time
+	btProfiler   []BluetoothDevice
+
+	// Fast metrics (1s).
+	prevNet             map[string]net.IOCountersStat
+	lastNetAt           time.Time
+	rxHistoryBuf        *RingBuffer
+	txHistoryBuf        *RingBuffer
+	lastNetIPAt         time.Time
+	cachedNetIPs        map[string]string
+	gpuInfo             staleCache[macGPUInfo]
+	nvidia              *nvidiaStream
+	nvidiaPolled        bool
+	scheduler           *collectorScheduler
+	lastGPUTimeAt       time.Time
+	lastPeripheralsAt   time.Time
+	lastDisplaysAt      time.Time
+	cachedDisplays      []Display
+	lastLaunchItemsAt   time.Time
+	cachedLaunchItems   []LaunchItem
+	backupWarnAge       time.Duration
+	lastCrashReportsAt  time.Time
+	cachedCrashReports  CrashReports
+	lastSpotlightAt     time.Time
+	spotlightVolumes    []SpotlightVolume
+	clockDriftWarn      time.Duration
+	lastClockAt         time.Time
+	cachedClock         ClockStatus
+	lastPowerEventsAt   time.Time
+	cachedPowerEvents   []PowerEvent
+	lastFDHoldersAt     time.Time
+	cachedFDHolders     []ProcessFDs
+	peripheralsKey      string
+	cachedPeripherals   []Peripheral
+	prevGPUTime         map[int]gpuClient
+	lastPowermetricsAt  time.Time
+	cachedPowermetrics  powermetricsSample
+	powermetricsRunner  func(context.Context) (string, error)
+	prevDiskIO          map[string]disk.IOCountersStat
+	diskReadHistoryBuf  *RingBuffer
+	diskWriteHistoryBuf *RingBuffer
+	lastDiskAt          time.Time
+	memMu               sync.Mutex
+	prevVM              vmCounters
+	prevVMAt            time.Time
+	sensorHistory       map[string]*RingBuffer
+	powerHistoryBuf     *RingBuffer
+	prevRAPL            map[string]uint64
+	prevRAPLAt          time.Time
+	lastSmartAt         time.Time
+	cachedSmart         []DiskHealth
+	lastStorageAt       time.Time
+	cachedStorage       StorageStatus
+	listenerSeen        map[string]time.Time
+	publicIPEnabled     bool
+	lastPublicIPAt      time.Time
+	publicIPVPNKey      string
+	cachedPublicIP      PublicIPStatus
+	pingTargets         []string
+	latencyHistory      map[string]*RingBuffer
+
+	watchMu        sync.Mutex
+	processWatch   ProcessWatchConfig
+	processSort    string
+	processEnergy  map[int]float64
+	processWatcher *ProcessWatcher
+	leakWatcher    *LeakWatcher
+	speedTests     []SpeedTestResult
+	enrichment     snapshotEnrichment
+	hasEnrichment  bool
+	backends       map[string]Backend
+}
+
+type collectedMetrics struct {
+	cpuStats     CPUStatus
+	memStats     MemoryStatus
+	diskStats    []DiskStatus
+	trashSize    uint64
+	trashApprox  bool
+	diskIO       DiskIOStatus
+	diskHealth   []DiskHealth
+	storage      StorageStatus
+	netStats     []NetworkStatus
+	netProcs     []ProcessNetwork
+	proxyStats   ProxyStatus
+	vpnStats     VPNStatus
+	publicIP     PublicIPStatus
+	latency      []LatencyProbe
+	dnsStats     DNSStatus
+	containers   ContainerStatus
+	kubernetes   KubernetesStatus
+	vms          []VirtualMachine
+	peripherals  []Peripheral
+	displays     []Display
+	launchItems  []LaunchItem
+	services     []ServiceStatus
+	timeMachine  TimeMachineStatus
+	crashReports CrashReports
+	spotlight    SpotlightStatus
+	icloud       ICloudStatus
+	clock        ClockStatus
+	session      SessionInfo
+	powerEvents  []PowerEvent
+	limits       LimitsStatus
+	stuck        []StuckProcess
+	collectors   []CollectorStat
+	portStats    PortsStatus
+	batteryStats []BatteryStatus
+	thermalStats ThermalStatus
+	sensorStats  []SensorReading
+	powerStats   PowerStatus
+	aneStats     ANEStatus
+	gpuStats     []GPUStatus
+	btStats      []BluetoothDevice
+	allProcs     []ProcessInfo
+	hasProcesses bool
+	backends     []backendResult
+}
+
+type snapshotEnrichment struct {
+	// When adding MetricsSnapshot fields, update
+	// TestMetricsSnapshotFieldsHaveCollectionClassifications.
+	hardware       HardwareInfo
+	cpuPCores      int
+	cpuECores      int
+	cpuClusterFreq map[string]float64
+	memoryCached   uint64
+	memoryPressure string
+	memoryCompress uint64
+	memorySwapIn   float64
+	memorySwapOut  float64
+	memoryFaults   float64
+	disks          []DiskStatus
+	hasDisks       bool
+	diskHealth     []DiskHealth
+	storage        StorageStatus
+	gpu            []GPUStatus
+	trashSize      uint64
+	trashApprox    bool
+	netProcs       []ProcessNetwork
+	proxy          ProxyStatus
+	vpn            VPNStatus
+	publicIP       PublicIPStatus
+	latency        []LatencyProbe
+	dns            DNSStatus
+	containers     ContainerStatus
+	kubernetes     KubernetesStatus
+	vms            []VirtualMachine
+	peripherals    []Peripheral
+	displays       []Display
+	launchItems    []LaunchItem
+	services       []ServiceStatus
+	timeMachine    TimeMachineStatus
+	crashReports   CrashReports
+	spotlight      SpotlightStatus
+	icloud         ICloudStatus
+	clock          ClockStatus
+	session        SessionInfo
+	powerEvents    []PowerEvent
+	limits         LimitsStatus
+	stuck          []StuckProcess
+	collectors     []CollectorStat
+	ports          PortsStatus
+	batteries      []BatteryStatus
+	thermal        ThermalStatus
+	sensors        []SensorReading
+	power          PowerStatus
+	ane            ANEStatus
+	bluetooth      []BluetoothDevice
+	topProcesses   []ProcessInfo
+	processAlerts  []ProcessAlert
+	leakSuspects   []LeakSuspect
+}
+
+func NewCollector(options ProcessWatchOptions) *Collector {
+	c := &Collector{
+		prevNet:             make(map[string]net.IOCountersStat),
+		rxHistoryBuf:        NewRingBuffer(NetworkHistorySize),
+		txHistoryBuf:        NewRingBuffer(NetworkHistorySize),
+		diskReadHistoryBuf:  NewRingBuffer(diskIOHistorySize),
+		diskWriteHistoryBuf: NewRingBuffer(diskIOHistorySize),
+		cachedNetIPs:        make(map[string]string),
+		processWatch:        options.SnapshotConfig(),
+		processSort:         ProcessSortCPU,
+		processWatcher:      NewProcessWatcher(options),
+		leakWatcher:         NewLeakWatcher(),
+		nvidia:              &nvidiaStream{},
+		gpuInfo:             staleCache[macGPUInfo]{name: "gpu_info"},
+		bluetooth:           staleCache[bluetoothReading]{name: "bluetooth"},
+		scheduler:           newCollectorScheduler(),
+		backupWarnAge:       DefaultBackupWarnDays * 24 * time.Hour,
+		clockDriftWarn:      DefaultClockDriftWarn,
+	}
+	c.primeNetworkCounters(time.Now())
+	return c
+}
+
+func collectHostInfo() *host.InfoStat {
+	hostInfo, _ := host.Info()
+	if hostInfo == nil {
+		hostInfo = &host.InfoStat{}
+	}
+	return hostInfo
+}
+
+func collectConcurrently(tasks ...func() error) error {
+	var (
+		wg     sync.WaitGroup
+		errMu  sync.Mutex
+		merged error
+	)
+
+	for _, task := range tasks {
+		wg.Go(func() {
+			defer func() {
+				if r := recover(); r != nil {
+					errMu.Lock()
+					panicErr := fmt.Errorf("collector panic: %v", r)
+					if merged == nil {
+						merged = panicErr
+					} else {
+						merged = fmt.Errorf("%v; %w", merged, panicErr)
+					}
+					errMu.Unlock()
+				}
+			}()
+			if err := task(); err != nil {
+				errMu.Lock()
+				if merged == nil {
+					merged = err
+				} else {
+					merged = fmt.Errorf("%v; %w", merged, err)
+				}
+				errMu.Unlock()
+			}
+		})
+	}
+
+	wg.Wait()
+	return merged
+}
+
+// CollectFast reads the per-second metrics (CPU, memory, disks, disk IO,
+// network) and fills the rest of the snapshot from the last full refresh.
+// The readings take well under a second, so ctx is only checked before they
+// start.
+func (c *Collector) CollectFast(ctx context.Context) (MetricsSnapshot, error) {
+	start := time.Now()
+	defer func() { debuglog.Phase("refresh", time.Since(start), "kind", "fast") }()
+	return c.collectFast(ctx, false)
+}
+
+// SetProcessSort changes the key top processes are ranked by on the next
+// collection. Unknown keys are ignored.
+func (c *Collector) SetProcessSort(key string) {
+	if !ValidProcessSort(key) {
+		return
+	}
+	c.watchMu.Lock()
+	c.processSort = key
+	c.watchMu.Unlock()
+}
+
+// CollectProcesses is CollectFast plus a fresh process list.
+func (c *Collector) CollectProcesses(ctx context.Context) (MetricsSnapshot, error) {
+	return c.collectFast(ctx, true)
+}
+
+func (c *Collector) collectFast(ctx context.Context, includeProcesses bool) (MetricsSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return MetricsSnapshot{}, err
+	}
+	now := time.Now()
+	hostInfo := collectHostInfo()
+	var collected collectedMetrics
+
+	tasks := []func() error{
+		func() (err error) { collected.cpuStats, err = collectCPUFast(); return },
+		func() (err error) { collected.memStats, err = c.collectMemoryFast(now); return },
+		func() (err error) { collected.diskStats, err = collectDisksFast(); return },
+		func() (err error) { collected.diskIO = c.collectDiskIO(now); return nil },
+		func() (err error) { collected.netStats = c.collectNetwork(now); return nil },
+	}
+	if includeProcesses {
+		tasks = append(tasks, func() error { return collectProcessesInto(&collected) })
+	}
+
+	mergeErr := collectConcurrently(tasks...)
+
+	snapshot := c.snapshotFromMetrics(now, hostInfo, collected, false)
+	c.applyEnrichment(&snapshot, collected.hasProcesses)
+	if live := c.nvidia.Latest(now); len(live) > 0 {
+		snapshot.GPU = withNvidiaSamples(snapshot.GPU, live, c.nvidia.SampledAt())
+	}
+	return snapshot, mergeErr
+}
+
+// Close stops background helpers such as the nvidia-smi stream.
+func (c *Collector) Close() {
+	c.nvidia.Stop()
+}
+
+// Collect runs every collector, each held to CollectorBudget, and returns a
+// full snapshot. When ctx ends first, collectors still running contribute
+// their last good reading and the error includes ctx's.
+func (c *Collector) Collect(ctx context.Context) (MetricsSnapshot, error) {
+	start := time.Now()
+	defer func() { debuglog.Phase("refresh", time.Since(start), "kind", "full") }()
+	return c.collectFull(ctx)
+}
+
+func (c *Collector) collectFull(ctx context.Context) (MetricsSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return MetricsSnapshot{}, err
+	}
+	now := time.Now()
+	hostInfo := collectHostInfo()
+	var collected collectedMetrics
+
+	// Sample CPU first, before the concurrent collectors below spawn their
+	// subprocesses (system_profiler, df, ps, ...). The usage window is only
+	// 100ms, so measuring while our own collection burst runs inflates the
+	// reading with Mole's own load (#1237).
+	var cpuErr error
+	collected.cpuStats, cpuErr = collectCPU()
+
+	tasks := c.fullTasks(ctx, now, cpuErr)
+	mergeErr := c.scheduler.Run(ctx, tasks, &collected)
+	collected.collectors = c.scheduler.Stats()
+	applySensorTemps(&collected.thermalStats, collected.sensorStats)
+	collected.powerStats = c.collectPower(now)
+	collected.aneStats = c.collectANE()
+	collected.publicIP = c.collectPublicIP(now, collected.vpnStats)
+	if !collected.cpuStats.PerCoreEstimated {
+		collected.cpuStats.Clusters = cpuClusters(
+			collected.cpuStats.PerCore,
+			collected.cpuStats.PCoreCount,
+			collected.cpuStats.ECoreCount,
+			c.cachedPowermetrics.clusterFreqMHz,
+		)
+	}
+
+	snapshot := c.snapshotFromMetrics(now, hostInfo, collected, true)
+	applyBackends(&snapshot, collected.backends)
+	if mergeErr == nil {
+		c.cacheEnrichment(snapshot)
+	}
+	return snapshot, mergeErr
+}
+
+// fullTasks lists every full-refresh collector. Collectors the fast path
+// shares are waited for; the rest are held to CollectorBudget. cpuErr is the
+// result of the CPU sample collectFull takes before starting the others.
+// Backends replace the built-in collectors they are named after.
+func (c *Collector) fullTasks(ctx context.Context, now time.Time, cpuErr error) []collectorTask {
+	return c.withBackends(ctx, []collectorTask{
+		waited("cpu", func() (collectedSet, error) { return nil, cpuErr }),
+		waited("memory", func() (collectedSet, error) {
+			v, err := c.collectMemory(now)
+			return func(m *collectedMetrics) { m.memStats = v }, err
+		}),
+		waited("disks", func() (collectedSet, error) {
+			v, err := collectDisks()
+			return func(m *collectedMetrics) { m.diskStats = v }, err
+		}),
+		bounded("trash", func() (collectedSet, error) {
+			size, approx := collectTrashSize()
+			return func(m *collectedMetrics) { m.trashSize, m.trashApprox = size, approx }, nil
+		}),
+		waited("disk_io", func() (collectedSet, error) {
+			v := c.collectDiskIO(now)
+			return func(m *collectedMetrics) { m.diskIO = v }, nil
+		}),
+		bounded("disk_health", func() (collectedSet, error) {
+			v := c.collectDiskHealth(now)
+			return func(m *collectedMetrics) { m.diskHealth = v }, nil
+		}),
+		bounded("storage", func() (collectedSet, error) {
+			v := c.collectStorage(now)
+			return func(m *collectedMetrics) { m.storage = v }, nil
+		}),
+		waited("network", func() (collectedSet, error) {
+			v := c.collectNetwork(now)
+			return func(m *collectedMetrics) { m.netStats = v }, nil
+		}),
+		bounded("proxy", func() (collectedSet, error) {
+			v := collectProxy()
+			return func(m *collectedMetrics) { m.proxyStats = v }, nil
+		}),
+		bounded("vpn", func() (collectedSet, error) {
+			v := collectVPN()
+			return func(m *collectedMetrics) { m.vpnStats = v }, nil
+		}),
+		bounded("latency", func() (collectedSet, error) {
+			v := c.collectLatency()
+			return func(m *collectedMetrics) { m.latency = v }, nil
+		}),
+		bounded("dns", func() (collectedSet, error) {
+			v := collectDNS()
+			return func(m *collectedMetrics) { m.dnsStats = v }, nil
+		}),
+		bounded("containers", func() (collectedSet, error) {
+			v := collectContainers()
+			return func(m *collectedMetrics) { m.containers = v }, nil
+		}),
+		bounded("kubernetes", func() (collectedSet, error) {
+			v := collectKubernetes()
+			return func(m *collectedMetrics) { m.kubernetes = v }, nil
+		}),
+		bounded("vms", func() (collectedSet, error) { v := collectVMs(); return func(m *collectedMetrics) { m.vms = v }, nil }),
+		bounded("peripherals", func() (collectedSet, error) {
+			v := c.collectPeripherals(now)
+			return func(m *collectedMetrics) { m.peripherals = v }, nil
+		}),
+		bounded("displays", func() (collectedSet, error) {
+			v := c.collectDisplays(now)
+			return func(m *collectedMetrics) { m.displays = v }, nil
+		}),
+		bounded("launch_items", func() (collectedSet, error) {
+			v := c.collectLaunchItems(now)
+			return func(m *collectedMetrics) { m.launchItems = v }, nil
+		}),
+		bounded("services", func() (collectedSet, error) {
+			v := collectServices()
+			return func(m *collectedMetrics) { m.services = v }, nil
+		}),
+		bounded("time_machine", func() (collectedSet, error) {
+			v := c.collectTimeMachine(now)
+			return func(m *collectedMetrics) { m.timeMachine = v }, nil
+		}),
+		bounded("crash_reports", func() (collectedSet, error) {
+			v := c.collectCrashReports(now)
+			return func(m *collectedMetrics) { m.crashReports = v }, nil
+		}),
+		bounded("spotlight", func() (collectedSet, error) {
+			v := c.collectSpotlight(now)
+			return func(m *collectedMetrics) { m.spotlight = v }, nil
+		}),
+		bounded("icloud", func() (collectedSet, error) {
+			v := collectICloud()
+			return func(m *collectedMetrics) { m.icloud = v }, nil
+		}),
+		bounded("clock", func() (collectedSet, error) {
+			v := c.collectClock(now)
+			return func(m *collectedMetrics) { m.clock = v }, nil
+		}),
+		bounded("session", func() (collectedSet, error) {
+			v := collectSession()
+			return func(m *collectedMetrics) { m.session = v }, nil
+		}),
+		bounded("power_events", func() (collectedSet, error) {
+			v := c.collectPowerEvents(now)
+			return func(m *collectedMetrics) { m.powerEvents = v }, nil
+		}),
+		bounded("limits", func() (collectedSet, error) {
+			v := c.collectLimits(now)
+			return func(m *collectedMetrics) { m.limits = v }, nil
+		}),
+		bounded("stuck", func() (collectedSet, error) {
+			v := collectStuckProcesses()
+			return func(m *collectedMetrics) { m.stuck = v }, nil
+		}),
+		bounded("ports", func() (collectedSet, error) {
+			v := c.collectPorts(now)
+			return func(m *collectedMetrics) { m.portStats = v }, nil
+		}),
+		bounded("network_procs", func() (collectedSet, error) {
+			v := collectProcessNetworkFunc()
+			return func(m *collectedMetrics) { m.netProcs = v }, nil
+		}),
+		bounded("batteries", func() (collectedSet, error) {
+			v, _ := collectBatteries()
+			return func(m *collectedMetrics) { m.batteryStats = v }, nil
+		}),
+		bounded("thermal", func() (collectedSet, error) {
+			v := collectThermal()
+			return func(m *collectedMetrics) { m.thermalStats = v }, nil
+		}),
+		bounded("sensors", func() (collectedSet, error) {
+			v, _ := c.collectSensors()
+			return func(m *collectedMetrics) { m.sensorStats = v }, nil
+		}),
+		bounded("gpu", func() (collectedSet, error) {
+			v, err := c.collectGPU(now)
+			return func(m *collectedMetrics) { m.gpuStats = v }, err
+		}),
+		bounded("bluetooth", func() (collectedSet, error) {
+			v := c.collectBluetooth(now)
+			return func(m *collectedMetrics) { m.btStats = v }, nil
+		}),
+		waited("processes", func() (collectedSet, error) {
+			var procs collectedMetrics
+			err := collectProcessesInto(&procs)
+			return func(m *collectedMetrics) { m.allProcs, m.hasProcesses = procs.allProcs, procs.hasProcesses }, err
+		}),
+		bounded("process_energy", func() (collectedSet, error) {
+			energy := collectProcessEnergyFunc()
+			c.watchMu.Lock()
+			c.processEnergy = energy
+			c.watchMu.Unlock()
+			return nil, nil
+		}),
+	})
+}
+
+func collectProcessesInto(collected *collectedMetrics) error {
+	procs, err := collectProcessesFunc()
+	if err != nil {
+		return err
+	}
+	collected.allProcs = procs
+	collected.hasProcesses = true
+	return nil
+}
+
+func (c *Collector) snapshotFromMetrics(now time.Time, hostInfo *host.InfoStat, collected collectedMetrics, refreshHardware bool) MetricsSnapshot {
+	// Dependent tasks (post-collect).
+	// Cache hardware info as it's expensive and rarely changes.
+	if refreshHardware && (!c.hasStatic || now.Sub(c.lastHWAt) > 10*time.Minute) {
+		c.cachedHW = collectHardware(collected.memStats.Total, collected.diskStats)
+		c.lastHWAt = now
+		c.hasStatic = true
+	}
+	hwInfo := c.hardwareForSnapshot()
+
+	score, scoreMsg := calculateHealthScore(
+		collected.cpuStats,
+		collected.memStats,
+		collected.diskStats,
+		collected.diskIO,
+		collected.thermalStats,
+		collected.batteryStats,
+		hostInfo.Uptime,
+	)
+	var topProcs []ProcessInfo
+	var processAlerts []ProcessAlert
+	var leakSuspects []LeakSuspect
+	c.watchMu.Lock()
+	processSort := c.processSort
+	speedTests := slices.Clone(c.speedTests)
+	if collected.hasProcesses {
+		applyProcessEnergy(collected.allProcs, c.processEnergy)
+		topProcs = topProcesses(collected.allProcs, topProcessLimit, processSort)
+	}
+	if c.processWatcher != nil {
+		if collected.hasProcesses {
+			processAlerts = c.processWatcher.Update(now, collected.allProcs)
+		} else {
+			processAlerts = c.processWatcher.Snapshot()
+		}
+	}
+	if collected.hasProcesses {
+		leakSuspects = c.leakWatcher.Update(now, collected.allProcs)
+	} else {
+		leakSuspects = c.leakWatcher.Snapshot()
+	}
+	c.watchMu.Unlock()
+
+	return MetricsSnapshot{
+		CollectedAt:    now,
+		Host:           hostInfo.Hostname,
+		Platform:       fmt.Sprintf("%s %s", hostInfo.Platform, hostInfo.PlatformVersion),
+		Uptime:         FormatUptime(hostInfo.Uptime),
+		UptimeSeconds:  hostInfo.Uptime,
+		BootTime:       bootTime(hostInfo.BootTime),
+		Procs:          hostInfo.Procs,
+		Hardware:       hwInfo,
+		HealthScore:    score,
+		HealthScoreMsg: scoreMsg,
+		CPU:            collected.cpuStats,
+		GPU:            collected.gpuStats,
+		Memory:         collected.memStats,
+		Disks:          collected.diskStats,
+		TrashSize:      collected.trashSize,
+		TrashApprox:    collected.trashApprox,
+		DiskIO:         collected.diskIO,
+		DiskHealth:     collected.diskHealth,
+		Storage:        collected.storage,
+		Network:        collected.netStats,
+		NetworkHistory: NetworkHistory{
+			RxHistory: c.rxHistoryBuf.Slice(),
+			TxHistory: c.txHistoryBuf.Slice(),
+		},
+		NetworkProcs:  collected.netProcs,
+		Proxy:         collected.proxyStats,
+		VPN:           collected.vpnStats,
+		PublicIP:      collected.publicIP,
+		Latency:       collected.latency,
+		DNS:           collected.dnsStats,
+		Containers:    collected.containers,
+		Kubernetes:    collected.kubernetes,
+		VMs:           collected.vms,
+		Peripherals:   collected.peripherals,
+		Displays:      collected.displays,
+		LaunchItems:   collected.launchItems,
+		Services:      collected.services,
+		TimeMachine:   collected.timeMachine,
+		CrashReports:  collected.crashReports,
+		Spotlight:     collected.spotlight,
+		ICloud:        collected.icloud,
+		Clock:         collected.clock,
+		Session:       collected.session,
+		PowerEvents:   collected.powerEvents,
+		Limits:        collected.limits,
+		Stuck:         collected.stuck,
+		Collectors:    collected.collectors,
+		Ports:         collected.portStats,
+		Batteries:     collected.batteryStats,
+		Thermal:       collected.thermalStats,
+		Sensors:       collected.sensorStats,
+		Power:         collected.powerStats,
+		ANE:           collected.aneStats,
+		Bluetooth:     collected.btStats,
+		TopProcesses:  topProcs,
+		ProcessWatch:  c.processWatch,
+		ProcessSort:   processSort,
+		ProcessAlerts: processAlerts,
+		LeakSuspects:  leakSuspects,
+		SpeedTests:    speedTests,
+	}
+}
+
+func (c *Collector) hardwareForSnapshot() HardwareInfo {
+	if c.hasStatic {
+		return c.cachedHW
+	}
+	return HardwareInfo{}
+}
+
+func (c *Collector) cacheEnrichment(snapshot MetricsSnapshot) {
+	c.enrichment = snapshotEnrichment{
+		hardware:       snapshot.Hardware,
+		cpuPCores:      snapshot.CPU.PCoreCount,
+		cpuECores:      snapshot.CPU.ECoreCount,
+		cpuClusterFreq: clusterFrequencies(snapshot.CPU.Clusters),
+		memoryCached:   snapshot.Memory.Cached,
+		memoryPressure: snapshot.Memory.Pressure,
+		memoryCompress: snapshot.Memory.Compressed,
+		memorySwapIn:   snapshot.Memory.SwapInRate,
+		memorySwapOut:  snapshot.Memory.SwapOutRate,
+		memoryFaults:   snapshot.Memory.PageFaultRate,
+		disks:          slices.Clone(snapshot.Disks),
+		hasDisks:       true,
+		diskHealth:     slices.Clone(snapshot.DiskHealth),
+		storage:        snapshot.Storage,
+		gpu:            slices.Clone(snapshot.GPU),
+		trashSize:      snapshot.TrashSize,
+		trashApprox:    snapshot.TrashApprox,
+		netProcs:       slices.Clone(snapshot.NetworkProcs),
+		proxy:          snapshot.Proxy,
+		vpn:            snapshot.VPN,
+		publicIP:       snapshot.PublicIP,
+		latency:        slices.Clone(snapshot.Latency),
+		dns:            snapshot.DNS,
+		containers:     snapshot.Containers,
+		kubernetes:     snapshot.Kubernetes,
+		vms:            slices.Clone(snapshot.VMs),
+		peripherals:    slices.Clone(snapshot.Peripherals),
+		displays:       slices.Clone(snapshot.Displays),
+		launchItems:    slices.Clone(snapshot.LaunchItems),
+		services:       slices.Clone(snapshot.Services),
+		timeMachine:    snapshot.TimeMachine,
+		crashReports:   snapshot.CrashReports,
+		spotlight:      snapshot.Spotlight,
+		icloud:         snapshot.ICloud,
+		clock:          snapshot.Clock,
+		session:        snapshot.Session,
+		powerEvents:    slices.Clone(snapshot.PowerEvents),
+		limits:         snapshot.Limits,
+		stuck:          slices.Clone(snapshot.Stuck),
+		collectors:     slices.Clone(snapshot.Collectors),
+		ports:          snapshot.Ports,
+		batteries:      slices.Clone(snapshot.Batteries),
+		thermal:        snapshot.Thermal,
+		sensors:        slices.Clone(snapshot.Sensors),
+		power:          snapshot.Power,
+		ane:            snapshot.ANE,
+		bluetooth:      slices.Clone(snapshot.Bluetooth),
+		topProcesses:   slices.Clone(snapshot.TopProcesses),
+		processAlerts:  slices.Clone(snapshot.ProcessAlerts),
+		leakSuspects:   slices.Clone(snapshot.LeakSuspects),
+	}
+	c.hasEnrichment = true
+}
+
+func (c *Collector) applyEnrichment(snapshot *MetricsSnapshot, preserveLiveProcesses bool) {
+	if snapshot == nil || !c.hasEnrichment {
+		return
+	}
+	c.enrichment.apply(snapshot, preserveLiveProcesses)
+	snapshot.HealthScore, snapshot.HealthScoreMsg = calculateHealthScore(
+		snapshot.CPU,
+		snapshot.Memory,
+		snapshot.Disks,
+		snapshot.DiskIO,
+		snapshot.Thermal,
+		snapshot.Batteries,
+		snapshot.UptimeSeconds,
+	)
+}
+
+func (e snapshotEnrichment) apply(snapshot *MetricsSnapshot, preserveLiveProcesses bool) {
+	snapshot.Hardware = e.hardware
+	snapshot.CPU.PCoreCount = e.cpuPCores
+	snapshot.CPU.ECoreCount = e.cpuECores
+	if !snapshot.CPU.PerCoreEstimated {
+		snapshot.CPU.Clusters = cpuClusters(snapshot.CPU.PerCore, e.cpuPCores, e.cpuECores, e.cpuClusterFreq)
+	}
+	snapshot.Memory.Cached = e.memoryCached
+	snapshot.Memory.Pressure = e.memoryPressure
+	snapshot.Memory.Compressed = e.memoryCompress
+	// Linux fills rates on every fast tick; macOS only on full refreshes.
+	if snapshot.Memory.SwapInRate == 0 && snapshot.Memory.SwapOutRate == 0 && snapshot.Memory.PageFaultRate == 0 {
+		snapshot.Memory.SwapInRate = e.memorySwapIn
+		snapshot.Memory.SwapOutRate = e.memorySwapOut
+		snapshot.Memory.PageFaultRate = e.memoryFaults
+	}
+	// Disk capacity is slow-changing and the corrections (APFS purgeable,
+	// diskutil, Finder) are expensive, so the fast path collects raw statfs
+	// values and we overwrite them with the last full-refresh corrected
+	// snapshot. DiskIO stays live. Skip when the cache is empty so the first
+	// fast paint still shows raw disks instead of a blank card.
+	if e.hasDisks && len(e.disks) > 0 {
+		snapshot.Disks = slices.Clone(e.disks)
+	}
+	snapshot.DiskHealth = slices.Clone(e.diskHealth)
+	snapshot.Storage = e.storage
+	snapshot.GPU = slices.Clone(e.gpu)
+	snapshot.TrashSize = e.trashSize
+	snapshot.TrashApprox = e.trashApprox
+	snapshot.NetworkProcs = slices.Clone(e.netProcs)
+	snapshot.Proxy = e.proxy
+	snapshot.VPN = e.vpn
+	snapshot.PublicIP = e.publicIP
+	snapshot.Latency = slices.Clone(e.latency)
+	snapshot.DNS = e.dns
+	snapshot.Containers = e.containers
+	snapshot.Kubernetes = e.kubernetes
+	snapshot.VMs = slices.Clone(e.vms)
+	snapshot.Peripherals = slices.Clone(e.peripherals)
+	snapshot.Displays = slices.Clone(e.displays)
+	snapshot.LaunchItems = slices.Clone(e.launchItems)
+	snapshot.Services = slices.Clone(e.services)
+	snapshot.TimeMachine = e.timeMachine
+	snapshot.CrashReports = e.crashReports
+	snapshot.Spotlight = e.spotlight
+	snapshot.ICloud = e.icloud
+	snapshot.Clock = e.clock
+	snapshot.Session = e.session
+	snapshot.PowerEvents = slices.Clone(e.powerEvents)
+	snapshot.Limits = e.limits
+	snapshot.Stuck = slices.Clone(e.stuck)
+	snapshot.Collectors = slices.Clone(e.collectors)
+	snapshot.Ports = e.ports
+	snapshot.Batteries = slices.Clone(e.batteries)
+	snapshot.Thermal = e.thermal
+	snapshot.Sensors = slices.Clone(e.sensors)
+	snapshot.Power = e.power
+	snapshot.ANE = e.ane
+	snapshot.Bluetooth = slices.Clone(e.bluetooth)
+	if !preserveLiveProcesses {
+		snapshot.TopProcesses = slices.Clone(e.topProcesses)
+		snapshot.ProcessAlerts = slices.Clone(e.processAlerts)
+		snapshot.LeakSuspects = slices.Clone(e.leakSuspects)
+	}
+}
+
+var runCmd = func(ctx context.Context, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	start := time.Now()
+	output, err := cmd.Output()
+	debuglog.Command(name, args, time.Since(start), err)
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// CommandExists reports whether name is on PATH. Results are cached, so
+// the collectors can ask on every refresh.
+func CommandExists(name string) bool {
+	return commandExists(name)
+}
+
+// SourcePaths lists the files and directories the collectors read on goos
+// that need more than default permissions, so a caller can check access up
+// front (status's doctor mode does).
+func SourcePaths(goos string) []string {
+	switch goos {
+	case "darwin":
+		return append([]string{timeMachinePrefs}, crashReportDirs()...)
+	case "linux":
+		return []string{procRoot}
+	}
+	return nil
+}
+
+var commandExists = func(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	commandExistsCacheMu.Lock()
+	if exists, ok := commandExistsCache[name]; ok {
+		commandExistsCacheMu.Unlock()
+		return exists
+	}
+	commandExistsCacheMu.Unlock()
+
+	exists := lookPathExists(name)
+
+	commandExistsCacheMu.Lock()
+	commandExistsCache[name] = exists
+	commandExistsCacheMu.Unlock()
+	return exists
+}
+
+var (
+	commandExistsCacheMu sync.Mutex
+	commandExistsCache   = make(map[string]bool)
+)
+
+func lookPathExists(name string) (exists bool) {
+	defer func() {
+		if recover() != nil {
+			exists = false
+		}
+	}()
+	_, err := exec.LookPath(name)
+	return err == nil
+}
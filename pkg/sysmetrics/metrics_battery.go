@@ -1,4 +1,4 @@
-package main
+package sysmetrics
 
 import (
 	"context"
@@ -13,6 +13,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/tw93/mole/internal/debuglog"
 )
 
 var (
@@ -25,6 +27,8 @@ var (
 	powerCacheTTL   = 30 * time.Second
 )
 
+var powerSupplyRoot = "/sys/class/power_supply"
+
 func collectBatteries() (batts []BatteryStatus, err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -37,15 +41,16 @@ func collectBatteries() (batts []BatteryStatus, err error) {
 	if runtime.GOOS == "darwin" && commandExists("pmset") {
 		if out, err := runCmd(context.Background(), "pmset", "-g", "batt"); err == nil {
 			// Health/cycles/capacity from AppleSmartBattery and cached system_profiler.
-			health, cycles, capacity := getCachedPowerData()
+			health, cycles, capacity, detail := getCachedPowerData()
 			if batts := parsePMSet(out, health, cycles, capacity); len(batts) > 0 {
+				applyBatteryDetail(&batts[0], detail)
 				return batts, nil
 			}
 		}
 	}
 
 	// Linux: /sys/class/power_supply.
-	matches, _ := filepath.Glob("/sys/class/power_supply/BAT*/capacity")
+	matches, _ := filepath.Glob(filepath.Join(powerSupplyRoot, "BAT*", "capacity"))
 	for _, capFile := range matches {
 		statusFile := filepath.Join(filepath.Dir(capFile), "status")
 		capData, err := os.ReadFile(capFile)
@@ -59,10 +64,12 @@ func collectBatteries() (batts []BatteryStatus, err error) {
 		if status == "" {
 			status = "Unknown"
 		}
-		batts = append(batts, BatteryStatus{
+		batt := BatteryStatus{
 			Percent: percent,
 			Status:  status,
-		})
+		}
+		applyBatteryDetail(&batt, readSysfsBatteryDetail(filepath.Dir(capFile), status))
+		batts = append(batts, batt)
 	}
 	if len(batts) > 0 {
 		return batts, nil
@@ -124,11 +131,13 @@ func parsePMSet(raw string, health string, cycles int, capacity int) []BatterySt
 	return out
 }
 
-// getCachedPowerData returns condition, cycles, and capacity from macOS power sources.
-func getCachedPowerData() (health string, cycles int, capacity int) {
+// getCachedPowerData returns condition, cycles, capacity, and charging detail
+// from macOS power sources.
+func getCachedPowerData() (health string, cycles int, capacity int, detail batteryDetail) {
 	health, cycles, capacity = getCachedSystemPowerData()
-	ioregCycles, ioregCapacity := getAppleSmartBatteryHealthData()
-	return mergeBatteryHealthData(health, cycles, capacity, ioregCycles, ioregCapacity)
+	ioregCycles, ioregCapacity, detail := getAppleSmartBatteryHealthData()
+	health, cycles, capacity = mergeBatteryHealthData(health, cycles, capacity, ioregCycles, ioregCapacity)
+	return health, cycles, capacity, detail
 }
 
 func getCachedSystemPowerData() (health string, cycles int, capacity int) {
@@ -214,9 +223,9 @@ func parsePercentInt(raw string) int {
 	return value
 }
 
-func getAppleSmartBatteryHealthData() (cycles int, capacity int) {
+func getAppleSmartBatteryHealthData() (cycles int, capacity int, detail batteryDetail) {
 	if runtime.GOOS != "darwin" || !commandExists("ioreg") {
-		return 0, 0
+		return 0, 0, batteryDetail{}
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
@@ -224,9 +233,10 @@ func getAppleSmartBatteryHealthData() (cycles int, capacity int) {
 
 	out, err := runCmd(ctx, "ioreg", "-rn", "AppleSmartBattery")
 	if err != nil {
-		return 0, 0
+		return 0, 0, batteryDetail{}
 	}
-	return parseAppleSmartBatteryHealth(out)
+	cycles, capacity = parseAppleSmartBatteryHealth(out)
+	return cycles, capacity, parseAppleSmartBatteryDetail(out)
 }
 
 func parseAppleSmartBatteryHealth(out string) (cycles int, capacity int) {
@@ -300,8 +310,10 @@ func getSystemPowerJSONOutput() string {
 
 	now := time.Now()
 	if cachedPowerJSON != "" && now.Sub(lastPowerJSONAt) < powerCacheTTL {
+		debuglog.Cache("power_profile_json", true, now.Sub(lastPowerJSONAt))
 		return cachedPowerJSON
 	}
+	debuglog.Cache("power_profile_json", false, 0)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -324,8 +336,10 @@ func getSystemPowerOutput() string {
 
 	now := time.Now()
 	if cachedPower != "" && now.Sub(lastPowerAt) < powerCacheTTL {
+		debuglog.Cache("power_profile", true, now.Sub(lastPowerAt))
 		return cachedPower
 	}
+	debuglog.Cache("power_profile", false, 0)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -340,7 +354,7 @@ func getSystemPowerOutput() string {
 
 func collectThermal() ThermalStatus {
 	if runtime.GOOS != "darwin" {
-		return ThermalStatus{}
+		return nativeThermal()
 	}
 
 	var thermal ThermalStatus
@@ -0,0 +1,135 @@
+package sysmetrics
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// AppleSmartBattery reports 65535 for time estimates it has not settled yet.
+const ioregTimeUnknown = 65535
+
+// batteryDetail carries capacity and charging fields that come from a
+// different source than pmset's percentage line.
+type batteryDetail struct {
+	cycleCount        int
+	designCapacity    int
+	maxCapacity       int
+	capacityUnit      string
+	chargeWatts       float64
+	timeToFull        int
+	timeToEmpty       int
+	optimizedCharging bool
+}
+
+func applyBatteryDetail(b *BatteryStatus, d batteryDetail) {
+	b.DesignCapacity = d.designCapacity
+	b.MaxCapacity = d.maxCapacity
+	b.CapacityUnit = d.capacityUnit
+	b.ChargeWatts = d.chargeWatts
+	b.TimeToFull = d.timeToFull
+	b.TimeToEmpty = d.timeToEmpty
+	b.OptimizedCharging = d.optimizedCharging
+	if b.CycleCount == 0 {
+		b.CycleCount = d.cycleCount
+	}
+	if b.Capacity == 0 {
+		b.Capacity = batteryHealthPercent(d.designCapacity, d.maxCapacity, 0)
+	}
+}
+
+func parseAppleSmartBatteryDetail(out string) batteryDetail {
+	var (
+		d                                batteryDetail
+		nominal, rawMax                  int
+		external, charging, fullyCharged bool
+		avgToFull, avgToEmpty            = -1, -1
+	)
+	for line := range strings.Lines(out) {
+		line = strings.TrimSpace(line)
+		if v, found := parseIORegFloatValue(line, "DesignCapacity"); found && d.designCapacity == 0 {
+			d.designCapacity = int(v)
+		}
+		if v, found := parseIORegFloatValue(line, "NominalChargeCapacity"); found && nominal == 0 {
+			nominal = int(v)
+		}
+		if v, found := parseIORegFloatValue(line, "AppleRawMaxCapacity"); found && rawMax == 0 {
+			rawMax = int(v)
+		}
+		if v, found := parseIORegFloatValue(line, "AvgTimeToFull"); found && avgToFull < 0 {
+			avgToFull = int(v)
+		}
+		if v, found := parseIORegFloatValue(line, "AvgTimeToEmpty"); found && avgToEmpty < 0 {
+			avgToEmpty = int(v)
+		}
+		if v, found := ioRegValueForKey(line, "ExternalConnected"); found {
+			external = v == "Yes"
+		}
+		if v, found := ioRegValueForKey(line, "IsCharging"); found {
+			charging = v == "Yes"
+		}
+		if v, found := ioRegValueForKey(line, "FullyCharged"); found {
+			fullyCharged = v == "Yes"
+		}
+	}
+
+	d.maxCapacity = nominal
+	if d.maxCapacity == 0 {
+		d.maxCapacity = rawMax
+	}
+	if d.designCapacity > 0 || d.maxCapacity > 0 {
+		d.capacityUnit = "mAh"
+	}
+	if charging && avgToFull > 0 && avgToFull < ioregTimeUnknown {
+		d.timeToFull = avgToFull
+	}
+	if !external && avgToEmpty > 0 && avgToEmpty < ioregTimeUnknown {
+		d.timeToEmpty = avgToEmpty
+	}
+	// BatteryPower is negative while charging.
+	if power := parseAppleSmartBatteryThermal(out).BatteryPower; charging && power < 0 {
+		d.chargeWatts = -power
+	}
+	// macOS pauses charging around 80% for optimized charging and charge
+	// limits while still reporting the adapter as connected.
+	d.optimizedCharging = external && !charging && !fullyCharged
+	return d
+}
+
+// readSysfsBatteryDetail reads the same details from a Linux power_supply
+// directory. Drivers expose either charge_* (µAh) or energy_* (µWh) files.
+func readSysfsBatteryDetail(dir, status string) batteryDetail {
+	read := func(name string) int64 {
+		raw, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return 0
+		}
+		v, _ := strconv.ParseInt(strings.TrimSpace(string(raw)), 10, 64)
+		return v
+	}
+
+	d := batteryDetail{cycleCount: int(read("cycle_count"))}
+	if design, full := read("charge_full_design"), read("charge_full"); design > 0 || full > 0 {
+		d.designCapacity, d.maxCapacity, d.capacityUnit = int(design/1000), int(full/1000), "mAh"
+	} else if design, full := read("energy_full_design"), read("energy_full"); design > 0 || full > 0 {
+		d.designCapacity, d.maxCapacity, d.capacityUnit = int(design/1000), int(full/1000), "mWh"
+	}
+
+	charging := strings.EqualFold(status, "Charging")
+	watts := float64(read("power_now")) / 1e6
+	if watts == 0 {
+		watts = float64(read("current_now")) * float64(read("voltage_now")) / 1e12
+	}
+	if charging && watts > 0 {
+		d.chargeWatts = math.Round(watts*10) / 10
+	}
+	if charging {
+		d.timeToFull = int(read("time_to_full_now") / 60)
+	} else if strings.EqualFold(status, "Discharging") {
+		d.timeToEmpty = int(read("time_to_empty_now") / 60)
+	}
+	d.optimizedCharging = strings.EqualFold(status, "Not charging")
+	return d
+}
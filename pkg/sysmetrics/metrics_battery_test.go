@@ -1,7 +1,9 @@
-package main
+package sysmetrics
 
 import (
 	"math"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -193,3 +195,169 @@ func TestParseAppleSmartBatteryThermalIgnoresRawAdapterWatts(t *testing.T) {
 		t.Fatalf("expected normalized adapter power 96W, got %v", thermal.AdapterPower)
 	}
 }
+
+func TestParseAppleSmartBatteryDetailCharging(t *testing.T) {
+	out := `
+  | |   "ExternalConnected" = Yes
+  | |   "IsCharging" = Yes
+  | |   "FullyCharged" = No
+  | |   "DesignCapacity" = 5103
+  | |   "NominalChargeCapacity" = 4382
+  | |   "AvgTimeToFull" = 65
+  | |   "AvgTimeToEmpty" = 65535
+  | |   "Voltage" = 12600
+  | |   "InstantAmperage" = 3000
+`
+	d := parseAppleSmartBatteryDetail(out)
+	if d.designCapacity != 5103 || d.maxCapacity != 4382 || d.capacityUnit != "mAh" {
+		t.Fatalf("capacity = %d/%d %s", d.maxCapacity, d.designCapacity, d.capacityUnit)
+	}
+	if d.timeToFull != 65 || d.timeToEmpty != 0 {
+		t.Fatalf("times = full %d empty %d, want 65 and unset", d.timeToFull, d.timeToEmpty)
+	}
+	if d.chargeWatts < 37.7 || d.chargeWatts > 37.9 {
+		t.Fatalf("chargeWatts = %v, want ~37.8", d.chargeWatts)
+	}
+	if d.optimizedCharging {
+		t.Fatal("actively charging battery should not be reported as on hold")
+	}
+}
+
+func TestParseAppleSmartBatteryDetailOptimizedHold(t *testing.T) {
+	out := `
+  | |   "ExternalConnected" = Yes
+  | |   "IsCharging" = No
+  | |   "FullyCharged" = No
+`
+	if d := parseAppleSmartBatteryDetail(out); !d.optimizedCharging || d.chargeWatts != 0 {
+		t.Fatalf("detail = %+v, want optimized hold without charge power", d)
+	}
+}
+
+func TestReadSysfsBatteryDetail(t *testing.T) {
+	dir := t.TempDir()
+	for name, value := range map[string]string{
+		"energy_full_design": "57000000",
+		"energy_full":        "51300000",
+		"power_now":          "24500000",
+		"time_to_full_now":   "3900",
+		"cycle_count":        "312",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := readSysfsBatteryDetail(dir, "Charging")
+	if d.designCapacity != 57000 || d.maxCapacity != 51300 || d.capacityUnit != "mWh" {
+		t.Fatalf("capacity = %d/%d %s", d.maxCapacity, d.designCapacity, d.capacityUnit)
+	}
+	if d.chargeWatts != 24.5 || d.timeToFull != 65 || d.cycleCount != 312 {
+		t.Fatalf("detail = %+v", d)
+	}
+
+	var b BatteryStatus
+	applyBatteryDetail(&b, d)
+	if b.Capacity != 90 || b.CycleCount != 312 {
+		t.Fatalf("battery = %+v, want 90%% health and 312 cycles", b)
+	}
+}
+
+func TestParsePMSet(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		health   string
+		cycles   int
+		capacity int
+		wantLen  int
+		wantPct  float64
+		wantStat string
+		wantTime string
+	}{
+		{
+			name: "charging with time",
+			raw: `Now drawing from 'AC Power'
+ -InternalBattery-0 (id=1234)	85%; charging; 0:45 remaining present: true`,
+			health:   "Good",
+			cycles:   150,
+			capacity: 92,
+			wantLen:  1,
+			wantPct:  85,
+			wantStat: "charging",
+			wantTime: "0:45",
+		},
+		{
+			name: "discharging",
+			raw: `Now drawing from 'Battery Power'
+ -InternalBattery-0 (id=1234)	45%; discharging; 2:30 remaining present: true`,
+			health:   "Normal",
+			cycles:   200,
+			capacity: 88,
+			wantLen:  1,
+			wantPct:  45,
+			wantStat: "discharging",
+			wantTime: "2:30",
+		},
+		{
+			name: "fully charged",
+			raw: `Now drawing from 'AC Power'
+ -InternalBattery-0 (id=1234)	100%; charged; present: true`,
+			health:   "Good",
+			cycles:   50,
+			capacity: 100,
+			wantLen:  1,
+			wantPct:  100,
+			wantStat: "charged",
+			wantTime: "",
+		},
+		{
+			name:     "empty output",
+			raw:      "",
+			health:   "",
+			cycles:   0,
+			capacity: 0,
+			wantLen:  0,
+		},
+		{
+			name:     "no battery line",
+			raw:      "Now drawing from 'AC Power'\nNo batteries found.",
+			health:   "",
+			cycles:   0,
+			capacity: 0,
+			wantLen:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parsePMSet(tt.raw, tt.health, tt.cycles, tt.capacity)
+			if len(got) != tt.wantLen {
+				t.Errorf("parsePMSet() returned %d batteries, want %d", len(got), tt.wantLen)
+				return
+			}
+			if tt.wantLen == 0 {
+				return
+			}
+			b := got[0]
+			if b.Percent != tt.wantPct {
+				t.Errorf("Percent = %v, want %v", b.Percent, tt.wantPct)
+			}
+			if b.Status != tt.wantStat {
+				t.Errorf("Status = %q, want %q", b.Status, tt.wantStat)
+			}
+			if b.TimeLeft != tt.wantTime {
+				t.Errorf("TimeLeft = %q, want %q", b.TimeLeft, tt.wantTime)
+			}
+			if b.Health != tt.health {
+				t.Errorf("Health = %q, want %q", b.Health, tt.health)
+			}
+			if b.CycleCount != tt.cycles {
+				t.Errorf("CycleCount = %d, want %d", b.CycleCount, tt.cycles)
+			}
+			if b.Capacity != tt.capacity {
+				t.Errorf("Capacity = %d, want %d", b.Capacity, tt.capacity)
+			}
+		})
+	}
+}
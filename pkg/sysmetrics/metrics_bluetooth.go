@@ -0,0 +1,351 @@
+package sysmetrics
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	bluetoothCacheTTL   = 30 * time.Second
+	bluetoothctlTimeout = 1500 * time.Millisecond
+	// Keyboards, mice, and trackpads are read from the registry in
+	// milliseconds. Audio devices are not HID and only system_profiler lists
+	// them, so that listing is refreshed less often once the registry works.
+	bluetoothRegistryTTL = 5 * time.Second
+	bluetoothProfilerTTL = 2 * time.Minute
+)
+
+// bluetoothReading is the device list and whether the registry answered,
+// which sets how soon it is read again.
+type bluetoothReading struct {
+	devices      []BluetoothDevice
+	fromRegistry bool
+}
+
+func (c *Collector) collectBluetooth(now time.Time) []BluetoothDevice {
+	ttl := bluetoothCacheTTL
+	if reading, _ := c.bluetooth.Peek(); reading.fromRegistry {
+		ttl = bluetoothRegistryTTL
+	}
+	reading, at := c.bluetooth.Get(now, ttl, c.readBluetooth)
+	devices := slices.Clone(reading.devices)
+	for i := range devices {
+		devices[i].SampledAt = at
+	}
+	return devices
+}
+
+func (c *Collector) readBluetooth(now time.Time) (bluetoothReading, bool) {
+	if runtime.GOOS == "darwin" {
+		if reading := c.readMacBluetooth(now); len(reading.devices) > 0 {
+			return reading, true
+		}
+	}
+
+	if devs, err := readBluetoothCTLDevices(); err == nil && len(devs) > 0 {
+		applyPactlBluetooth(devs)
+		return bluetoothReading{devices: devs}, true
+	}
+	return bluetoothReading{devices: []BluetoothDevice{{Name: "No Bluetooth info", Connected: false}}}, false
+}
+
+func (c *Collector) readMacBluetooth(now time.Time) bluetoothReading {
+	hid, err := readRegistryBluetooth()
+	profilerTTL := bluetoothCacheTTL
+	if err == nil {
+		profilerTTL = bluetoothProfilerTTL
+	}
+	if len(c.btProfiler) == 0 || now.Sub(c.btProfilerAt) >= profilerTTL {
+		if devs, err := readSystemProfilerBluetooth(); err == nil && len(devs) > 0 {
+			c.btProfiler = devs
+		}
+		c.btProfilerAt = now
+	}
+	return bluetoothReading{devices: mergeBluetoothHID(c.btProfiler, hid), fromRegistry: err == nil}
+}
+
+// readRegistryBluetooth lists connected Bluetooth HID devices. Apple's
+// peripherals publish BatteryPercent on the same service.
+func readRegistryBluetooth() ([]BluetoothDevice, error) {
+	entries, err := readIORegistryFunc("IOHIDDevice")
+	if err != nil {
+		return nil, err
+	}
+	return bluetoothFromHID(entries), nil
+}
+
+func bluetoothFromHID(entries []ioRegistryEntry) []BluetoothDevice {
+	var devices []BluetoothDevice
+	seen := make(map[string]int)
+	for _, e := range entries {
+		// "Bluetooth" or "Bluetooth Low Energy".
+		if !strings.HasPrefix(plistString(e.props, "Transport"), "Bluetooth") {
+			continue
+		}
+		name := plistString(e.props, "Product")
+		if name == "" {
+			continue
+		}
+		dev := BluetoothDevice{
+			Name:      name,
+			Address:   strings.ToUpper(strings.ReplaceAll(plistString(e.props, "DeviceAddress"), "-", ":")),
+			Connected: true,
+		}
+		if pct, ok := e.props["BatteryPercent"].(uint64); ok {
+			dev.Battery = strconv.FormatUint(pct, 10) + "%"
+		}
+		// A device exposes one HID service per interface.
+		key := dev.Name + "|" + dev.Address
+		if i, ok := seen[key]; ok {
+			devices[i].Battery = cmp.Or(devices[i].Battery, dev.Battery)
+			continue
+		}
+		seen[key] = len(devices)
+		devices = append(devices, dev)
+	}
+	return devices
+}
+
+// mergeBluetoothHID overlays the registry's fresher state on the cached
+// system_profiler list, matching by address and then by name, and appends
+// HID devices paired since that list was read.
+func mergeBluetoothHID(profiler, hid []BluetoothDevice) []BluetoothDevice {
+	devices := slices.Clone(profiler)
+	if len(hid) > 0 && len(devices) == 1 && devices[0].Name == "No devices" {
+		devices = nil
+	}
+	for _, h := range hid {
+		i := slices.IndexFunc(devices, func(d BluetoothDevice) bool {
+			if h.Address != "" && d.Address != "" {
+				return strings.EqualFold(h.Address, d.Address)
+			}
+			return d.Name == h.Name
+		})
+		if i < 0 {
+			devices = append(devices, h)
+			continue
+		}
+		devices[i].Connected = true
+		devices[i].Battery = cmp.Or(h.Battery, devices[i].Battery)
+	}
+	return devices
+}
+
+func readSystemProfilerBluetooth() ([]BluetoothDevice, error) {
+	if runtime.GOOS != "darwin" || !commandExists("system_profiler") {
+		return nil, errors.New("system_profiler unavailable")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), systemProfilerTimeout)
+	defer cancel()
+
+	out, err := runCmd(ctx, "system_profiler", "SPBluetoothDataType")
+	if err != nil {
+		return nil, err
+	}
+	return parseSPBluetooth(out), nil
+}
+
+func readBluetoothCTLDevices() ([]BluetoothDevice, error) {
+	if !commandExists("bluetoothctl") {
+		return nil, errors.New("bluetoothctl unavailable")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), bluetoothctlTimeout)
+	defer cancel()
+
+	out, err := runCmd(ctx, "bluetoothctl", "info")
+	if err != nil {
+		return nil, err
+	}
+	return parseBluetoothctl(out), nil
+}
+
+// parseSPBluetooth reads system_profiler's text report. macOS publishes
+// RSSI for connected devices there but not the negotiated audio codec.
+func parseSPBluetooth(raw string) []BluetoothDevice {
+	var devices []BluetoothDevice
+	var current BluetoothDevice
+
+	for line := range strings.Lines(raw) {
+		trim := strings.TrimSpace(line)
+		if len(trim) == 0 {
+			continue
+		}
+		if !strings.HasPrefix(line, "    ") && strings.HasSuffix(trim, ":") {
+			// Reset at top-level sections.
+			current = BluetoothDevice{}
+			continue
+		}
+		if strings.HasPrefix(line, "        ") && strings.HasSuffix(trim, ":") {
+			if current.Name != "" {
+				devices = append(devices, current.withBudBattery())
+			}
+			current = BluetoothDevice{Name: strings.TrimSuffix(trim, ":")}
+			continue
+		}
+		if strings.Contains(trim, "Connected:") {
+			current.Connected = strings.Contains(trim, "Yes")
+		}
+		if key, value, ok := strings.Cut(trim, "Battery Level:"); ok {
+			value = strings.TrimSpace(value)
+			switch strings.TrimSpace(key) {
+			case "":
+				current.Battery = value
+			case "Left":
+				current.BatteryLeft = value
+			case "Right":
+				current.BatteryRight = value
+			case "Case":
+				current.BatteryCase = value
+			}
+		}
+		if value, ok := strings.CutPrefix(trim, "Address:"); ok {
+			current.Address = strings.TrimSpace(value)
+		}
+		if value, ok := strings.CutPrefix(trim, "RSSI:"); ok {
+			current.RSSI = parseRSSI(value)
+		}
+	}
+	if current.Name != "" {
+		devices = append(devices, current.withBudBattery())
+	}
+	if len(devices) == 0 {
+		return []BluetoothDevice{{Name: "No devices", Connected: false}}
+	}
+	return devices
+}
+
+// withBudBattery fills Battery for earbuds that only report per-bud levels,
+// using the lower bud since that one runs out first. A bud in the case
+// reports no level, so either alone is used.
+func (d BluetoothDevice) withBudBattery() BluetoothDevice {
+	if d.Battery != "" {
+		return d
+	}
+	switch {
+	case d.BatteryLeft != "" && d.BatteryRight != "":
+		d.Battery = d.BatteryLeft
+		if parsePercentInt(d.BatteryRight) < parsePercentInt(d.BatteryLeft) {
+			d.Battery = d.BatteryRight
+		}
+	case d.BatteryLeft != "":
+		d.Battery = d.BatteryLeft
+	default:
+		d.Battery = d.BatteryRight
+	}
+	return d
+}
+
+func parseBluetoothctl(raw string) []BluetoothDevice {
+	var devices []BluetoothDevice
+	current := BluetoothDevice{}
+	for line := range strings.Lines(raw) {
+		trim := strings.TrimSpace(line)
+		if after, ok := strings.CutPrefix(trim, "Device "); ok {
+			if current.Name != "" {
+				devices = append(devices, current)
+			}
+			address, _, _ := strings.Cut(after, " ")
+			current = BluetoothDevice{Name: after, Address: address, Connected: false}
+		}
+		if after, ok := strings.CutPrefix(trim, "Name:"); ok {
+			current.Name = strings.TrimSpace(after)
+		}
+		if strings.HasPrefix(trim, "Connected:") {
+			current.Connected = strings.Contains(trim, "yes")
+		}
+		if after, ok := strings.CutPrefix(trim, "RSSI:"); ok {
+			current.RSSI = parseRSSI(after)
+		}
+	}
+	if current.Name != "" {
+		devices = append(devices, current)
+	}
+	if len(devices) == 0 {
+		return []BluetoothDevice{{Name: "No devices", Connected: false}}
+	}
+	return devices
+}
+
+// parseRSSI accepts "-52", "-52 dBm", and bluetoothctl's "0xffffffc4 (-60)".
+func parseRSSI(raw string) int {
+	raw = strings.TrimSpace(raw)
+	if open := strings.LastIndex(raw, "("); open >= 0 {
+		raw = strings.TrimSuffix(raw[open+1:], ")")
+	}
+	raw = strings.TrimSpace(strings.TrimSuffix(raw, "dBm"))
+	n, _ := strconv.Atoi(raw)
+	return n
+}
+
+// applyPactlBluetooth adds the codec and output latency of Bluetooth audio
+// sinks, which BlueZ leaves to the sound server, matched by address.
+func applyPactlBluetooth(devs []BluetoothDevice) {
+	if !commandExists("pactl") {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), bluetoothctlTimeout)
+	defer cancel()
+	out, err := runCmd(ctx, "pactl", "list", "sinks")
+	if err != nil {
+		return
+	}
+	sinks := parsePactlBluetoothSinks(out)
+	for i := range devs {
+		if sink, ok := sinks[strings.ToUpper(devs[i].Address)]; ok {
+			devs[i].Codec = sink.Codec
+			devs[i].LatencyMs = sink.LatencyMs
+		}
+	}
+}
+
+// parsePactlBluetoothSinks reads `pactl list sinks` and keys BlueZ sinks by
+// device address. PipeWire names the codec api.bluez5.codec, PulseAudio
+// bluetooth.codec.
+func parsePactlBluetoothSinks(out string) map[string]BluetoothDevice {
+	sinks := make(map[string]BluetoothDevice)
+	var address string
+	var sink BluetoothDevice
+	flush := func() {
+		if address != "" {
+			sinks[strings.ToUpper(address)] = sink
+		}
+		address, sink = "", BluetoothDevice{}
+	}
+	for line := range strings.Lines(out) {
+		trim := strings.TrimSpace(line)
+		if strings.HasPrefix(trim, "Sink #") {
+			flush()
+			continue
+		}
+		if value, ok := strings.CutPrefix(trim, "Latency:"); ok {
+			usec, _, _ := strings.Cut(strings.TrimSpace(value), " ")
+			if n, err := strconv.ParseFloat(usec, 64); err == nil {
+				sink.LatencyMs = n / 1000
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(trim, " = ")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		switch key {
+		case "api.bluez5.address", "device.string":
+			if strings.Count(value, ":") == 5 {
+				address = value
+			}
+		case "api.bluez5.codec", "bluetooth.codec":
+			sink.Codec = strings.ToUpper(value)
+		}
+	}
+	flush()
+	return sinks
+}
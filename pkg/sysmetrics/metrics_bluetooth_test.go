@@ -0,0 +1,141 @@
+package sysmetrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSPBluetoothReadsRSSI(t *testing.T) {
+	out := `Bluetooth:
+
+      Bluetooth Controller:
+          State: On
+      Devices (Paired, Configured, etc.):
+          MX Master 3:
+              Address: D4:12:8A:00:11:22
+              Connected: Yes
+              RSSI: -48
+          WH-1000XM4:
+              Address: 38:18:4C:AA:BB:CC
+              Connected: No
+`
+	devs := parseSPBluetooth(out)
+	if len(devs) != 2 {
+		t.Fatalf("got %d devices, want 2: %+v", len(devs), devs)
+	}
+	if devs[0].Name != "MX Master 3" || !devs[0].Connected || devs[0].RSSI != -48 || devs[0].Address != "D4:12:8A:00:11:22" {
+		t.Fatalf("mouse = %+v", devs[0])
+	}
+	if devs[1].Connected || devs[1].RSSI != 0 {
+		t.Fatalf("headphones = %+v", devs[1])
+	}
+}
+
+func TestParseBluetoothctlAndPactlSinks(t *testing.T) {
+	devs := parseBluetoothctl(`Device 38:18:4C:AA:BB:CC (public)
+	Name: WH-1000XM4
+	Connected: yes
+	RSSI: 0xffffffc4 (-60)
+`)
+	if len(devs) != 1 || devs[0].Address != "38:18:4C:AA:BB:CC" || devs[0].RSSI != -60 {
+		t.Fatalf("parseBluetoothctl() = %+v", devs)
+	}
+
+	sinks := parsePactlBluetoothSinks(`Sink #52
+	State: RUNNING
+	Name: bluez_output.38_18_4C_AA_BB_CC.1
+	Latency: 41530 usec, configured 40000 usec
+	Properties:
+		api.bluez5.address = "38:18:4C:AA:BB:CC"
+		api.bluez5.codec = "ldac"
+		api.bluez5.profile = "a2dp-sink"
+
+Sink #53
+	Name: alsa_output.pci-0000_00_1f.3.analog-stereo
+	Latency: 0 usec, configured 0 usec
+	Properties:
+		device.string = "front:0"
+`)
+	if len(sinks) != 1 {
+		t.Fatalf("got %d Bluetooth sinks, want 1: %+v", len(sinks), sinks)
+	}
+	sink := sinks["38:18:4C:AA:BB:CC"]
+	if sink.Codec != "LDAC" || sink.LatencyMs != 41.53 {
+		t.Fatalf("sink = %+v, want LDAC at 41.53ms", sink)
+	}
+}
+
+func TestParseRSSI(t *testing.T) {
+	for raw, want := range map[string]int{" -52": -52, "-71 dBm": -71, "0xffffffc4 (-60)": -60, "n/a": 0} {
+		if got := parseRSSI(raw); got != want {
+			t.Errorf("parseRSSI(%q) = %d, want %d", raw, got, want)
+		}
+	}
+}
+
+func TestParseSPBluetoothSplitsEarbudBatteries(t *testing.T) {
+	out := `Bluetooth:
+
+      Devices (Paired, Configured, etc.):
+          AirPods Pro:
+              Address: 60:93:16:AA:BB:CC
+              Connected: Yes
+              Case Battery Level: 41%
+              Left Battery Level: 88%
+              Right Battery Level: 72%
+          AirPods:
+              Connected: Yes
+              Left Battery Level: 50%
+          Magic Keyboard:
+              Connected: Yes
+              Battery Level: 93%
+`
+	devs := parseSPBluetooth(out)
+	if len(devs) != 3 {
+		t.Fatalf("got %d devices, want 3: %+v", len(devs), devs)
+	}
+	pro := devs[0]
+	if pro.BatteryLeft != "88%" || pro.BatteryRight != "72%" || pro.BatteryCase != "41%" || pro.Battery != "72%" {
+		t.Fatalf("AirPods Pro = %+v, want per-bud levels and the lower bud as Battery", pro)
+	}
+	if devs[1].Battery != "50%" || devs[1].BatteryRight != "" {
+		t.Fatalf("one bud out of the case = %+v", devs[1])
+	}
+	if devs[2].Battery != "93%" || devs[2].BatteryCase != "" {
+		t.Fatalf("keyboard = %+v", devs[2])
+	}
+}
+
+func TestRegistryBluetoothMergesIntoProfilerList(t *testing.T) {
+	old := readIORegistryFunc
+	defer func() { readIORegistryFunc = old }()
+	readIORegistryFunc = func(string) ([]ioRegistryEntry, error) {
+		return []ioRegistryEntry{
+			{props: map[string]any{"Transport": "Bluetooth Low Energy", "Product": "Magic Keyboard", "DeviceAddress": "a4-83-e7-00-11-22"}},
+			{props: map[string]any{"Transport": "Bluetooth Low Energy", "Product": "Magic Keyboard", "DeviceAddress": "a4-83-e7-00-11-22", "BatteryPercent": uint64(64)}},
+			{props: map[string]any{"Transport": "Bluetooth", "Product": "Xbox Wireless Controller"}},
+			{props: map[string]any{"Transport": "USB", "Product": "Keychron K2"}},
+		}, nil
+	}
+	hid, err := readRegistryBluetooth()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profiler := []BluetoothDevice{
+		{Name: "AirPods Pro", Address: "F0:1B:00:00:00:01", Connected: true, Battery: "80%"},
+		{Name: "Magic Keyboard", Address: "A4:83:E7:00:11:22", Battery: "70%"},
+	}
+	got := mergeBluetoothHID(profiler, hid)
+	want := []BluetoothDevice{
+		{Name: "AirPods Pro", Address: "F0:1B:00:00:00:01", Connected: true, Battery: "80%"},
+		{Name: "Magic Keyboard", Address: "A4:83:E7:00:11:22", Connected: true, Battery: "64%"},
+		{Name: "Xbox Wireless Controller", Connected: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("mergeBluetoothHID() = %+v, want %+v", got, want)
+	}
+	if got := mergeBluetoothHID([]BluetoothDevice{{Name: "No devices"}}, hid[:1]); len(got) != 1 || got[0].Name != "Magic Keyboard" {
+		t.Fatalf("placeholder should give way to registry devices, got %+v", got)
+	}
+}
@@ -0,0 +1,184 @@
+package sysmetrics
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// An NTP query goes off the machine, so the offset is only re-measured
+	// every few minutes.
+	clockTTL              = 10 * time.Minute
+	DefaultClockDriftWarn = time.Second
+	defaultMacTimeServer  = "time.apple.com"
+)
+
+var ntpConfPath = "/etc/ntp.conf"
+
+// SetClockDriftWarn sets the clock offset beyond which the clock is
+// reported as drifting.
+func (c *Collector) SetClockDriftWarn(limit time.Duration) {
+	c.clockDriftWarn = limit
+}
+
+func (c *Collector) collectClock(now time.Time) ClockStatus {
+	if !c.lastClockAt.IsZero() && now.Sub(c.lastClockAt) < clockTTL {
+		return c.cachedClock
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var status ClockStatus
+	switch runtime.GOOS {
+	case "darwin":
+		// timed's own state needs root; sntp measures against the
+		// configured server directly.
+		status = ClockStatus{Server: readNTPServer(ntpConfPath), Sync: "unknown"}
+		if commandExists("sntp") {
+			if out, err := runCmd(ctx, "sntp", "-t", "3", status.Server); err == nil {
+				status.OffsetMs, status.Measured = parseSntpOffset(out)
+			}
+		}
+	case "linux":
+		status = collectLinuxClock(ctx)
+	default:
+		return ClockStatus{}
+	}
+	status.Drifting = status.Measured && c.clockDriftWarn > 0 &&
+		math.Abs(status.OffsetMs) > float64(c.clockDriftWarn.Milliseconds())
+
+	c.cachedClock = status
+	c.lastClockAt = now
+	return status
+}
+
+func collectLinuxClock(ctx context.Context) ClockStatus {
+	status := ClockStatus{Sync: "unknown"}
+	if commandExists("timedatectl") {
+		if out, err := runCmd(ctx, "timedatectl", "show", "-p", "NTPSynchronized", "--value"); err == nil {
+			status.Sync = "unsynced"
+			if strings.TrimSpace(out) == "yes" {
+				status.Sync = "synced"
+			}
+		}
+	}
+	switch {
+	case commandExists("chronyc"):
+		if out, err := runCmd(ctx, "chronyc", "tracking"); err == nil {
+			status.Server, status.OffsetMs, status.Measured = parseChronyTracking(out)
+		}
+	case commandExists("timedatectl"):
+		if out, err := runCmd(ctx, "timedatectl", "timesync-status"); err == nil {
+			status.Server, status.OffsetMs, status.Measured = parseTimesyncStatus(out)
+		}
+	}
+	return status
+}
+
+// readNTPServer returns the first "server" line of ntp.conf, which the
+// Date & Time settings write on macOS.
+func readNTPServer(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return defaultMacTimeServer
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "server" {
+			return fields[1]
+		}
+	}
+	return defaultMacTimeServer
+}
+
+// parseSntpOffset reads the offset in seconds that leads sntp's result:
+//
+//	+0.012345 +/- 0.023456 time.apple.com 17.253.4.125
+func parseSntpOffset(out string) (float64, bool) {
+	for line := range strings.Lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[1] != "+/-" {
+			continue
+		}
+		if secs, err := strconv.ParseFloat(fields[0], 64); err == nil {
+			return secs * 1000, true
+		}
+	}
+	return 0, false
+}
+
+// parseChronyTracking reads `chronyc tracking`:
+//
+//	Reference ID    : A29FC87B (time.cloudflare.com)
+//	System time     : 0.000123456 seconds slow of NTP time
+//
+// A clock running slow of NTP time has a positive offset.
+func parseChronyTracking(out string) (string, float64, bool) {
+	var server string
+	var offset float64
+	var measured bool
+	for line := range strings.Lines(out) {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "Reference ID":
+			if _, name, ok := strings.Cut(value, "("); ok {
+				server = strings.TrimSuffix(name, ")")
+			}
+		case "System time":
+			fields := strings.Fields(value)
+			if len(fields) < 3 {
+				continue
+			}
+			secs, err := strconv.ParseFloat(fields[0], 64)
+			if err != nil {
+				continue
+			}
+			if fields[2] == "fast" {
+				secs = -secs
+			}
+			offset, measured = secs*1000, true
+		}
+	}
+	return server, offset, measured
+}
+
+// parseTimesyncStatus reads `timedatectl timesync-status` from
+// systemd-timesyncd:
+//
+//	Server: 185.125.190.56 (ntp.ubuntu.com)
+//	Offset: -1.234ms
+func parseTimesyncStatus(out string) (string, float64, bool) {
+	var server string
+	var offset float64
+	var measured bool
+	for line := range strings.Lines(out) {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "Server":
+			addr, name, _ := strings.Cut(value, " (")
+			server = cmp.Or(strings.TrimSuffix(name, ")"), addr)
+		case "Offset":
+			if d, err := time.ParseDuration(strings.ReplaceAll(value, "+", "")); err == nil {
+				offset, measured = float64(d.Microseconds())/1000, true
+			}
+		}
+	}
+	return server, offset, measured
+}
@@ -0,0 +1,53 @@
+package sysmetrics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSntpOffset(t *testing.T) {
+	got, ok := parseSntpOffset("+2.345678 +/- 0.023456 time.apple.com 17.253.4.125\n")
+	if !ok || got < 2345.6 || got > 2345.7 {
+		t.Fatalf("offset = %v ok=%v", got, ok)
+	}
+	if _, ok := parseSntpOffset("sntp: lookup error\n"); ok {
+		t.Fatal("error output should not parse")
+	}
+}
+
+func TestParseChronyTracking(t *testing.T) {
+	out := `Reference ID    : A29FC87B (time.cloudflare.com)
+Stratum         : 4
+System time     : 0.001500000 seconds fast of NTP time
+Last offset     : +0.000012 seconds
+`
+	server, offset, ok := parseChronyTracking(out)
+	if !ok || server != "time.cloudflare.com" || offset != -1.5 {
+		t.Fatalf("server=%q offset=%v ok=%v", server, offset, ok)
+	}
+}
+
+func TestParseTimesyncStatus(t *testing.T) {
+	out := `       Server: 185.125.190.56 (ntp.ubuntu.com)
+Poll interval: 34min 8s (min: 32s; max 34min 8s)
+       Offset: -1.234ms
+`
+	server, offset, ok := parseTimesyncStatus(out)
+	if !ok || server != "ntp.ubuntu.com" || offset != -1.234 {
+		t.Fatalf("server=%q offset=%v ok=%v", server, offset, ok)
+	}
+}
+
+func TestReadNTPServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ntp.conf")
+	if err := os.WriteFile(path, []byte("# managed\nserver time.euro.apple.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := readNTPServer(path); got != "time.euro.apple.com" {
+		t.Fatalf("server = %q", got)
+	}
+	if got := readNTPServer(filepath.Join(t.TempDir(), "missing")); got != defaultMacTimeServer {
+		t.Fatalf("fallback = %q", got)
+	}
+}
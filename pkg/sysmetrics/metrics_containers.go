@@ -0,0 +1,124 @@
+package sysmetrics
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// docker stats samples CPU over about a second before printing.
+	dockerStatsTimeout = 5 * time.Second
+	dockerInfoTimeout  = 2 * time.Second
+)
+
+type dockerInfoJSON struct {
+	Name            string `json:"Name"`
+	OperatingSystem string `json:"OperatingSystem"`
+	NCPU            int    `json:"NCPU"`
+	MemTotal        uint64 `json:"MemTotal"`
+}
+
+type dockerStatsJSON struct {
+	Name     string `json:"Name"`
+	CPUPerc  string `json:"CPUPerc"`
+	MemUsage string `json:"MemUsage"` // "12.3MiB / 7.6GiB"
+	NetIO    string `json:"NetIO"`    // "1.2kB / 648B"
+}
+
+var dockerSizeUnits = map[string]float64{
+	"B":   1,
+	"kB":  1e3,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+	"TiB": 1 << 40,
+}
+
+// collectContainers reads the Docker-compatible engine behind the docker
+// CLI, which is also how OrbStack and Colima expose their containers.
+func collectContainers() ContainerStatus {
+	if !commandExists("docker") {
+		return ContainerStatus{}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dockerInfoTimeout)
+	out, err := runCmd(ctx, "docker", "info", "--format", "{{json .}}")
+	cancel()
+	if err != nil {
+		// Daemon not running.
+		return ContainerStatus{}
+	}
+	status := parseDockerInfo(out)
+
+	ctx, cancel = context.WithTimeout(context.Background(), dockerStatsTimeout)
+	defer cancel()
+	if out, err := runCmd(ctx, "docker", "stats", "--no-stream", "--format", "{{json .}}"); err == nil {
+		status.Containers = parseDockerStats(out)
+	}
+	return status
+}
+
+func parseDockerInfo(out string) ContainerStatus {
+	var info dockerInfoJSON
+	if json.Unmarshal([]byte(strings.TrimSpace(out)), &info) != nil {
+		return ContainerStatus{}
+	}
+	status := ContainerStatus{Runtime: info.OperatingSystem, VMCPUs: info.NCPU, VMMemory: info.MemTotal}
+	switch {
+	case strings.Contains(info.OperatingSystem, "Docker Desktop"):
+		status.Runtime = "Docker Desktop"
+	case strings.Contains(info.OperatingSystem, "OrbStack"):
+		status.Runtime = "OrbStack"
+	case strings.Contains(strings.ToLower(info.Name), "colima"):
+		status.Runtime = "Colima"
+	}
+	return status
+}
+
+// parseDockerStats reads one JSON object per line and orders containers by
+// CPU, busiest first.
+func parseDockerStats(out string) []ContainerInfo {
+	var containers []ContainerInfo
+	for line := range strings.Lines(out) {
+		var raw dockerStatsJSON
+		if json.Unmarshal([]byte(strings.TrimSpace(line)), &raw) != nil || raw.Name == "" {
+			continue
+		}
+		info := ContainerInfo{Name: raw.Name}
+		info.CPU, _ = strconv.ParseFloat(strings.TrimSuffix(raw.CPUPerc, "%"), 64)
+		info.MemoryBytes, info.MemoryLimit = parseDockerPair(raw.MemUsage)
+		info.NetRx, info.NetTx = parseDockerPair(raw.NetIO)
+		containers = append(containers, info)
+	}
+	slices.SortStableFunc(containers, func(a, b ContainerInfo) int {
+		return cmp.Compare(b.CPU, a.CPU)
+	})
+	return containers
+}
+
+func parseDockerPair(raw string) (uint64, uint64) {
+	left, right, _ := strings.Cut(raw, "/")
+	return parseDockerSize(left), parseDockerSize(right)
+}
+
+func parseDockerSize(raw string) uint64 {
+	raw = strings.TrimSpace(raw)
+	idx := strings.IndexFunc(raw, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if idx <= 0 {
+		return 0
+	}
+	value, err := strconv.ParseFloat(raw[:idx], 64)
+	scale, ok := dockerSizeUnits[strings.TrimSpace(raw[idx:])]
+	if err != nil || !ok {
+		return 0
+	}
+	return uint64(value * scale)
+}
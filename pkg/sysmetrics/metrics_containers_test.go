@@ -0,0 +1,63 @@
+package sysmetrics
+
+import "testing"
+
+func TestParseDockerInfoDetectsRuntime(t *testing.T) {
+	tests := []struct {
+		out  string
+		want string
+	}{
+		{`{"Name":"docker-desktop","OperatingSystem":"Docker Desktop","NCPU":8,"MemTotal":8217473024}`, "Docker Desktop"},
+		{`{"Name":"orbstack","OperatingSystem":"OrbStack","NCPU":10,"MemTotal":17179869184}`, "OrbStack"},
+		{`{"Name":"colima","OperatingSystem":"Ubuntu 24.04 LTS","NCPU":2,"MemTotal":2061381632}`, "Colima"},
+		{`{"Name":"build","OperatingSystem":"Debian GNU/Linux 12","NCPU":4}`, "Debian GNU/Linux 12"},
+	}
+	for _, tt := range tests {
+		got := parseDockerInfo(tt.out)
+		if got.Runtime != tt.want {
+			t.Errorf("parseDockerInfo(%s).Runtime = %q, want %q", tt.out, got.Runtime, tt.want)
+		}
+	}
+	if got := parseDockerInfo(tests[0].out); got.VMCPUs != 8 || got.VMMemory != 8217473024 {
+		t.Fatalf("allocation = %d CPU %d bytes", got.VMCPUs, got.VMMemory)
+	}
+	if got := parseDockerInfo("Cannot connect to the Docker daemon"); got.Runtime != "" {
+		t.Fatalf("unexpected runtime %q for error output", got.Runtime)
+	}
+}
+
+func TestParseDockerStats(t *testing.T) {
+	out := `{"BlockIO":"0B / 0B","CPUPerc":"0.05%","Container":"a1","ID":"a1","MemPerc":"0.31%","MemUsage":"24.5MiB / 7.653GiB","Name":"redis","NetIO":"1.2kB / 648B","PIDs":"6"}
+{"BlockIO":"0B / 0B","CPUPerc":"112.40%","Container":"b2","ID":"b2","MemPerc":"6.10%","MemUsage":"478MiB / 7.653GiB","Name":"postgres","NetIO":"3.1MB / 2.4MB","PIDs":"12"}
+not json
+`
+	got := parseDockerStats(out)
+	if len(got) != 2 {
+		t.Fatalf("got %d containers, want 2", len(got))
+	}
+	if got[0].Name != "postgres" || got[0].CPU != 112.4 {
+		t.Fatalf("busiest = %+v, want postgres at 112.4%%", got[0])
+	}
+	if got[0].MemoryBytes != 478<<20 || got[0].NetRx != 3_100_000 || got[0].NetTx != 2_400_000 {
+		t.Fatalf("postgres = %+v", got[0])
+	}
+	if got[1].NetRx != 1200 || got[1].NetTx != 648 {
+		t.Fatalf("redis net = %d/%d, want 1200/648", got[1].NetRx, got[1].NetTx)
+	}
+}
+
+func TestParseDockerSize(t *testing.T) {
+	tests := map[string]uint64{
+		"648B":       648,
+		"1.5kB":      1500,
+		"2GiB":       2 << 30,
+		" 24MiB ":    24 << 20,
+		"--":         0,
+		"12 parsecs": 0,
+	}
+	for in, want := range tests {
+		if got := parseDockerSize(in); got != want {
+			t.Errorf("parseDockerSize(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
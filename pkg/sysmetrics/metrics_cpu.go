@@ -1,4 +1,4 @@
-package main
+package sysmetrics
 
 import (
 	"bufio"
@@ -89,9 +89,13 @@ func collectCPUWithOptions(includeSlowFallbacks bool) (CPUStatus, error) {
 	if loadStats != nil {
 		loadAvg = *loadStats
 	}
-	if includeSlowFallbacks && (loadErr != nil || isZeroLoad(loadAvg)) {
-		if fallback, err := fallbackLoadAvgFromUptime(); err == nil {
-			loadAvg = fallback
+	if loadErr != nil || isZeroLoad(loadAvg) {
+		if native, ok := nativeLoadAvg(); ok {
+			loadAvg = native
+		} else if includeSlowFallbacks {
+			if fallback, err := fallbackLoadAvgFromUptime(); err == nil {
+				loadAvg = fallback
+			}
 		}
 	}
 
@@ -182,6 +186,40 @@ func getCoreTopology() (pCores, eCores int) {
 	return pCores, eCores
 }
 
+// cpuClusters splits per-core usage into Apple Silicon performance and
+// efficiency clusters. XNU numbers efficiency cores first, so the leading
+// eCores entries of the per-core slice belong to the E cluster.
+func cpuClusters(perCore []float64, pCores, eCores int, freqMHz map[string]float64) []CPUCluster {
+	if pCores <= 0 || eCores <= 0 || len(perCore) != pCores+eCores {
+		return nil
+	}
+	mean := func(values []float64) float64 {
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values))
+	}
+	return []CPUCluster{
+		{Name: "P", Cores: pCores, Usage: mean(perCore[eCores:]), FrequencyMHz: freqMHz["P"]},
+		{Name: "E", Cores: eCores, Usage: mean(perCore[:eCores]), FrequencyMHz: freqMHz["E"]},
+	}
+}
+
+func clusterFrequencies(clusters []CPUCluster) map[string]float64 {
+	var freqs map[string]float64
+	for _, cluster := range clusters {
+		if cluster.FrequencyMHz <= 0 {
+			continue
+		}
+		if freqs == nil {
+			freqs = make(map[string]float64, len(clusters))
+		}
+		freqs[cluster.Name] = cluster.FrequencyMHz
+	}
+	return freqs
+}
+
 func fallbackLoadAvgFromUptime() (load.AvgStat, error) {
 	if !commandExists("uptime") {
 		return load.AvgStat{}, errors.New("uptime command unavailable")
@@ -241,6 +279,9 @@ func fallbackCPUUtilization(logical int) (float64, []float64, error) {
 	if logical <= 0 {
 		logical = 1
 	}
+	if total, perCore, err := nativeCPUUtilization(cpuSampleInterval); err == nil && len(perCore) > 0 {
+		return total, perCore, nil
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
 	defer cancel()
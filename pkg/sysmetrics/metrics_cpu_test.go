@@ -1,4 +1,4 @@
-package main
+package sysmetrics
 
 import (
 	"math"
@@ -109,3 +109,48 @@ func TestPerCoreUsageClampsAndErrors(t *testing.T) {
 		t.Fatalf("negative busy delta should clamp to 0, got %.2f", percents[0])
 	}
 }
+
+func TestCPUClustersSplitsEfficiencyCoresFirst(t *testing.T) {
+	perCore := []float64{10, 20, 90, 100, 80, 70}
+	clusters := cpuClusters(perCore, 4, 2, map[string]float64{"P": 3228, "E": 1020})
+	if len(clusters) != 2 {
+		t.Fatalf("len(clusters) = %d, want 2", len(clusters))
+	}
+	p, e := clusters[0], clusters[1]
+	if p.Name != "P" || p.Cores != 4 || !almostEqual(p.Usage, 85) || p.FrequencyMHz != 3228 {
+		t.Fatalf("P cluster = %+v", p)
+	}
+	if e.Name != "E" || e.Cores != 2 || !almostEqual(e.Usage, 15) || e.FrequencyMHz != 1020 {
+		t.Fatalf("E cluster = %+v", e)
+	}
+
+	if got := cpuClusters(perCore, 4, 4, nil); got != nil {
+		t.Fatalf("mismatched topology should skip clusters, got %+v", got)
+	}
+	if got := cpuClusters(perCore, 0, 0, nil); got != nil {
+		t.Fatalf("Intel topology should skip clusters, got %+v", got)
+	}
+}
+
+func TestParsePowermetricsClusterFrequencies(t *testing.T) {
+	out := `E-Cluster HW active frequency: 1000 MHz
+E-Cluster HW active residency:  40.00%
+P0-Cluster HW active frequency: 3000 MHz
+P1-Cluster HW active frequency: 2000 MHz
+GPU HW active residency:  12.50%
+`
+	sample := parsePowermetrics(out)
+	if sample.gpuActive != 12.5 {
+		t.Fatalf("gpuActive = %v, want 12.5", sample.gpuActive)
+	}
+	if sample.clusterFreqMHz["E"] != 1000 || sample.clusterFreqMHz["P"] != 2500 {
+		t.Fatalf("clusterFreqMHz = %v", sample.clusterFreqMHz)
+	}
+
+	if idle := parsePowermetrics("GPU idle residency: 70.00%"); idle.gpuActive != 30 {
+		t.Fatalf("idle fallback gpuActive = %v, want 30", idle.gpuActive)
+	}
+	if empty := parsePowermetrics(""); empty.gpuActive != -1 || empty.clusterFreqMHz != nil {
+		t.Fatalf("empty sample = %+v", empty)
+	}
+}
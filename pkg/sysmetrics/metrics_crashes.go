@@ -0,0 +1,140 @@
+package sysmetrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	crashReportsTTL    = 5 * time.Minute
+	crashReportsWindow = 7 * 24 * time.Hour
+)
+
+// crashReportDirs are where ReportCrash, spindump, and the panic reporter
+// write; the system directory holds panics and crashes of root processes.
+var crashReportDirs = func() []string {
+	dirs := []string{"/Library/Logs/DiagnosticReports"}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, "Library", "Logs", "DiagnosticReports"))
+	}
+	return dirs
+}
+
+// Report file names are "<process>-<date>" or "<process>_<date>_<host>".
+var crashReportNameRe = regexp.MustCompile(`^(.+?)[-_]\d{4}-\d{2}-\d{2}`)
+
+// bug_type values in the JSON header of .ips reports.
+var ipsBugTypes = map[string]string{
+	"109": "crash", // Legacy crash
+	"309": "crash",
+	"210": "panic",
+}
+
+func (c *Collector) collectCrashReports(now time.Time) CrashReports {
+	if runtime.GOOS != "darwin" {
+		return CrashReports{}
+	}
+	if !c.lastCrashReportsAt.IsZero() && now.Sub(c.lastCrashReportsAt) < crashReportsTTL {
+		return c.cachedCrashReports
+	}
+	var reports CrashReports
+	counts := make(map[string]int)
+	for _, dir := range crashReportDirs() {
+		scanCrashReports(dir, now, &reports, counts)
+	}
+	for _, name := range slices.Sorted(maps.Keys(counts)) {
+		if counts[name] > reports.TopCount {
+			reports.TopProcess, reports.TopCount = name, counts[name]
+		}
+	}
+	c.cachedCrashReports = reports
+	c.lastCrashReportsAt = now
+	return reports
+}
+
+// scanCrashReports tallies reports modified within crashReportsWindow and
+// counts crashes per process. Diagnostics that are not failures, such as
+// CPU and disk-write resource reports, are skipped.
+func scanCrashReports(dir string, now time.Time, reports *CrashReports, counts map[string]int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || now.Sub(info.ModTime()) > crashReportsWindow {
+			continue
+		}
+		name := entry.Name()
+		kind, process := "", ""
+		switch filepath.Ext(name) {
+		case ".crash":
+			kind = "crash"
+		case ".hang", ".spin":
+			kind = "hang"
+		case ".panic":
+			kind = "panic"
+		case ".ips":
+			kind, process = readIPSHeader(filepath.Join(dir, name))
+		}
+		switch kind {
+		case "crash":
+			reports.Crashes++
+			if process == "" {
+				process = crashReportProcess(name)
+			}
+			if process != "" {
+				counts[process]++
+			}
+		case "hang":
+			reports.Hangs++
+		case "panic":
+			reports.Panics++
+			if info.ModTime().After(reports.LastPanic) {
+				reports.LastPanic = info.ModTime()
+			}
+		}
+	}
+}
+
+// readIPSHeader classifies an .ips report from its first line, a JSON
+// header such as {"app_name":"Safari","bug_type":"309",...}.
+func readIPSHeader(path string) (string, string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", ""
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return "", ""
+	}
+	var header struct {
+		AppName string `json:"app_name"`
+		Name    string `json:"name"`
+		BugType string `json:"bug_type"`
+	}
+	if json.Unmarshal([]byte(line), &header) != nil {
+		return "", ""
+	}
+	process := header.AppName
+	if process == "" {
+		process = header.Name
+	}
+	return ipsBugTypes[header.BugType], process
+}
+
+func crashReportProcess(name string) string {
+	if m := crashReportNameRe.FindStringSubmatch(name); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	return ""
+}
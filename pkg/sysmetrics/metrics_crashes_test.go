@@ -0,0 +1,40 @@
+package sysmetrics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScanCrashReports(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string, age time.Duration) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(-age)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("Safari-2026-03-10-101010.ips", `{"app_name":"Safari","bug_type":"309"}`+"\n{}", time.Hour)
+	write("Safari-2026-03-11-101010.ips", `{"app_name":"Safari","bug_type":"309"}`+"\n{}", 2*time.Hour)
+	write("mds_stores-2026-03-11-090000.ips", `{"name":"mds_stores","bug_type":"145"}`+"\n{}", time.Hour)
+	write("Xcode_2026-03-11-101010_MacBook-Pro.hang", "", time.Hour)
+	write("panic-full-2026-03-11-101010.0002.panic", "", time.Hour)
+	write("node-2026-03-11-101010.crash", "", time.Hour)
+	write("Mail-2026-02-01-101010.ips", `{"app_name":"Mail","bug_type":"309"}`+"\n{}", 30*24*time.Hour)
+
+	var reports CrashReports
+	counts := make(map[string]int)
+	scanCrashReports(dir, time.Now(), &reports, counts)
+	if reports.Crashes != 3 || reports.Hangs != 1 || reports.Panics != 1 || reports.LastPanic.IsZero() {
+		t.Fatalf("reports = %+v", reports)
+	}
+	if counts["Safari"] != 2 || counts["node"] != 1 || len(counts) != 2 {
+		t.Fatalf("counts = %v", counts)
+	}
+}
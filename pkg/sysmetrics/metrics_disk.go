@@ -1,6 +1,7 @@
-package main
+package sysmetrics
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -17,6 +19,13 @@ import (
 	"github.com/shirou/gopsutil/v4/disk"
 )
 
+const diskIOHistorySize = 60
+
+var (
+	diskIOCountersFunc = func() (map[string]disk.IOCountersStat, error) { return disk.IOCounters() }
+	sysBlockRoot       = "/sys/block"
+)
+
 var skipDiskMounts = map[string]bool{
 	"/System/Volumes/VM":       true,
 	"/System/Volumes/Preboot":  true,
@@ -420,42 +429,94 @@ func uint64AbsDiff(a, b uint64) uint64 {
 }
 
 func (c *Collector) collectDiskIO(now time.Time) DiskIOStatus {
-	counters, err := disk.IOCounters()
+	counters, err := diskIOCountersFunc()
 	if err != nil || len(counters) == 0 {
 		return DiskIOStatus{}
 	}
-
-	var total disk.IOCountersStat
-	for _, v := range counters {
-		total.ReadBytes += v.ReadBytes
-		total.WriteBytes += v.WriteBytes
+	current := make(map[string]disk.IOCountersStat, len(counters))
+	for name, v := range counters {
+		if isPhysicalDiskName(name) {
+			current[name] = v
+		}
 	}
 
-	if c.lastDiskAt.IsZero() {
-		c.prevDiskIO = total
-		c.lastDiskAt = now
+	prev, lastAt := c.prevDiskIO, c.lastDiskAt
+	c.prevDiskIO = current
+	c.lastDiskAt = now
+	if lastAt.IsZero() {
 		return DiskIOStatus{}
 	}
 
-	elapsed := now.Sub(c.lastDiskAt).Seconds()
+	elapsed := now.Sub(lastAt).Seconds()
 	if elapsed <= 0 {
 		elapsed = 1
 	}
 
-	readRate := float64(counterDelta(total.ReadBytes, c.prevDiskIO.ReadBytes)) / 1024 / 1024 / elapsed
-	writeRate := float64(counterDelta(total.WriteBytes, c.prevDiskIO.WriteBytes)) / 1024 / 1024 / elapsed
+	var status DiskIOStatus
+	var totalOps, totalTimeMs uint64
+	for name, cur := range current {
+		before, ok := prev[name]
+		if !ok {
+			continue
+		}
+		dev := diskDeviceRates(name, cur, before, elapsed)
+		status.ReadRate += dev.ReadRate
+		status.WriteRate += dev.WriteRate
+		status.ReadIOPS += dev.ReadIOPS
+		status.WriteIOPS += dev.WriteIOPS
+		totalOps += counterDelta(cur.ReadCount, before.ReadCount) + counterDelta(cur.WriteCount, before.WriteCount)
+		totalTimeMs += counterDelta(cur.ReadTime, before.ReadTime) + counterDelta(cur.WriteTime, before.WriteTime)
+		if dev.ReadIOPS+dev.WriteIOPS > 0 {
+			status.Devices = append(status.Devices, dev)
+		}
+	}
+	if totalOps > 0 {
+		status.LatencyMs = float64(totalTimeMs) / float64(totalOps)
+	}
+	slices.SortFunc(status.Devices, func(a, b DiskDeviceIO) int {
+		return cmp.Compare(b.ReadRate+b.WriteRate, a.ReadRate+a.WriteRate)
+	})
+
+	c.diskReadHistoryBuf.Add(status.ReadRate)
+	c.diskWriteHistoryBuf.Add(status.WriteRate)
+	status.ReadHistory = c.diskReadHistoryBuf.Slice()
+	status.WriteHistory = c.diskWriteHistoryBuf.Slice()
+	return status
+}
 
-	c.prevDiskIO = total
-	c.lastDiskAt = now
+// diskDeviceRates converts one device's counter deltas into rates. Latency is
+// the mean service time per completed operation in the interval.
+func diskDeviceRates(name string, cur, before disk.IOCountersStat, elapsed float64) DiskDeviceIO {
+	reads := counterDelta(cur.ReadCount, before.ReadCount)
+	writes := counterDelta(cur.WriteCount, before.WriteCount)
+	dev := DiskDeviceIO{
+		Name:      name,
+		ReadRate:  float64(counterDelta(cur.ReadBytes, before.ReadBytes)) / 1024 / 1024 / elapsed,
+		WriteRate: float64(counterDelta(cur.WriteBytes, before.WriteBytes)) / 1024 / 1024 / elapsed,
+		ReadIOPS:  float64(reads) / elapsed,
+		WriteIOPS: float64(writes) / elapsed,
+	}
+	if ops := reads + writes; ops > 0 {
+		busyMs := counterDelta(cur.ReadTime, before.ReadTime) + counterDelta(cur.WriteTime, before.WriteTime)
+		dev.LatencyMs = float64(busyMs) / float64(ops)
+	}
+	return dev
+}
 
-	if readRate < 0 {
-		readRate = 0
+// isPhysicalDiskName drops partitions and virtual devices so Linux totals
+// are not double counted (sda and sda1 both appear in /proc/diskstats).
+// macOS IOKit only reports whole disks.
+func isPhysicalDiskName(name string) bool {
+	if runtime.GOOS != "linux" {
+		return true
 	}
-	if writeRate < 0 {
-		writeRate = 0
+	for _, prefix := range []string{"loop", "ram", "zram", "dm-", "md"} {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
 	}
-
-	return DiskIOStatus{ReadRate: readRate, WriteRate: writeRate}
+	_, err := os.Stat(filepath.Join(sysBlockRoot, name))
+	return err == nil
 }
 
 func counterDelta(current, previous uint64) uint64 {
@@ -1,9 +1,12 @@
-package main
+package sysmetrics
 
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/shirou/gopsutil/v4/disk"
 )
@@ -212,3 +215,51 @@ func TestCounterDeltaClampsCounterReset(t *testing.T) {
 		t.Fatalf("counterDelta reset = %d, want 0", got)
 	}
 }
+
+func TestCollectDiskIOPerDeviceRatesAndLatency(t *testing.T) {
+	oldCounters, oldBlockRoot := diskIOCountersFunc, sysBlockRoot
+	defer func() { diskIOCountersFunc, sysBlockRoot = oldCounters, oldBlockRoot }()
+
+	// On Linux only whole disks listed in /sys/block count toward totals.
+	sysBlockRoot = t.TempDir()
+	for _, name := range []string{"disk0", "disk4"} {
+		if err := os.Mkdir(filepath.Join(sysBlockRoot, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sample := map[string]disk.IOCountersStat{
+		"disk0": {ReadBytes: 0, WriteBytes: 0, ReadCount: 0, WriteCount: 0},
+		"disk4": {ReadBytes: 0, ReadCount: 0},
+	}
+	diskIOCountersFunc = func() (map[string]disk.IOCountersStat, error) { return sample, nil }
+
+	c := NewCollector(ProcessWatchOptions{})
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	if io := c.collectDiskIO(start); io.ReadRate != 0 || len(io.Devices) != 0 {
+		t.Fatalf("first sample should only prime counters, got %+v", io)
+	}
+
+	sample = map[string]disk.IOCountersStat{
+		"disk0":   {ReadBytes: 20 << 20, WriteBytes: 4 << 20, ReadCount: 200, WriteCount: 100, ReadTime: 300, WriteTime: 300},
+		"disk4":   {ReadBytes: 60 << 20, ReadCount: 100, ReadTime: 900},
+		"disk0s1": {ReadBytes: 20 << 20, ReadCount: 200},
+		"loop0":   {ReadBytes: 1 << 30, ReadCount: 5},
+	}
+	io := c.collectDiskIO(start.Add(2 * time.Second))
+	if io.ReadRate != 40 || io.WriteRate != 2 {
+		t.Fatalf("rates = R %v W %v, want 40 and 2 MB/s", io.ReadRate, io.WriteRate)
+	}
+	if io.ReadIOPS != 150 || io.WriteIOPS != 50 {
+		t.Fatalf("iops = R %v W %v, want 150 and 50", io.ReadIOPS, io.WriteIOPS)
+	}
+	if io.LatencyMs != 3.75 {
+		t.Fatalf("latency = %v, want 1500ms over 400 ops", io.LatencyMs)
+	}
+	if len(io.Devices) != 2 || io.Devices[0].Name != "disk4" || io.Devices[0].LatencyMs != 9 {
+		t.Fatalf("devices = %+v, want disk4 first with 9ms latency", io.Devices)
+	}
+	if len(io.ReadHistory) != 1 || io.ReadHistory[0] != 40 {
+		t.Fatalf("read history = %v", io.ReadHistory)
+	}
+}
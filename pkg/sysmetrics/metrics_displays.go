@@ -0,0 +1,162 @@
+package sysmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Displays rarely change, but a dock renegotiating after sleep should show
+// up within a minute.
+const displaysTTL = time.Minute
+
+func (c *Collector) collectDisplays(now time.Time) []Display {
+	if !c.lastDisplaysAt.IsZero() && now.Sub(c.lastDisplaysAt) < displaysTTL {
+		return c.cachedDisplays
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), systemProfilerTimeout)
+	defer cancel()
+
+	var displays []Display
+	switch {
+	case runtime.GOOS == "darwin" && commandExists("system_profiler"):
+		if out, err := runCmd(ctx, "system_profiler", "-json", "SPDisplaysDataType"); err == nil {
+			displays = parseSPDisplays(out)
+		}
+	case runtime.GOOS == "linux" && commandExists("xrandr"):
+		// X11 only; Wayland compositors have no common query tool.
+		if out, err := runCmd(ctx, "xrandr", "--current"); err == nil {
+			displays = parseXrandr(out)
+		}
+	}
+	c.cachedDisplays = displays
+	c.lastDisplaysAt = now
+	return displays
+}
+
+// parseSPDisplays reads the displays attached to each GPU in
+// `system_profiler -json SPDisplaysDataType`. _spdisplays_pixels is the
+// panel's native mode; _spdisplays_resolution is the "looks like" size
+// macOS scales to, with the refresh rate.
+func parseSPDisplays(out string) []Display {
+	var data struct {
+		GPUs []struct {
+			Displays []map[string]any `json:"spdisplays_ndrvs"`
+		} `json:"SPDisplaysDataType"`
+	}
+	if json.Unmarshal([]byte(out), &data) != nil {
+		return nil
+	}
+	var displays []Display
+	for _, gpu := range data.GPUs {
+		for _, raw := range gpu.Displays {
+			str := func(key string) string { s, _ := raw[key].(string); return s }
+			d := Display{Name: str("_name")}
+			d.Width, d.Height = parseDisplaySize(str("_spdisplays_pixels"))
+			looks, hz, _ := strings.Cut(str("_spdisplays_resolution"), "@")
+			d.LooksWidth, d.LooksHeight = parseDisplaySize(looks)
+			d.RefreshHz, _ = strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(hz), "Hz"), 64)
+			d.Connection = spDisplayValue(str("spdisplays_connection_type"))
+			d.Main = str("spdisplays_main") == "spdisplays_yes"
+			displayType := strings.ToLower(str("spdisplays_display_type"))
+			d.HDR = strings.Contains(displayType, "xdr") || strings.Contains(displayType, "hdr") ||
+				str("spdisplays_hdr") == "spdisplays_yes"
+			if d.Connection == "" && strings.Contains(displayType, "built-in") {
+				d.Connection = "Internal"
+			}
+			if d.Width == d.LooksWidth && d.Height == d.LooksHeight {
+				d.LooksWidth, d.LooksHeight = 0, 0
+			}
+			displays = append(displays, d)
+		}
+	}
+	return displays
+}
+
+// spDisplayValue turns "spdisplays_displayport" into "DisplayPort".
+func spDisplayValue(raw string) string {
+	raw = strings.TrimPrefix(raw, "spdisplays_")
+	switch raw {
+	case "":
+		return ""
+	case "internal":
+		return "Internal"
+	case "displayport":
+		return "DisplayPort"
+	case "hdmi":
+		return "HDMI"
+	case "airplay":
+		return "AirPlay"
+	}
+	return raw
+}
+
+// parseDisplaySize reads "3840 x 2160" or "3840x2160".
+func parseDisplaySize(raw string) (int, int) {
+	w, h, ok := strings.Cut(strings.ReplaceAll(raw, " ", ""), "x")
+	if !ok {
+		return 0, 0
+	}
+	width, _ := strconv.Atoi(w)
+	height, _ := strconv.Atoi(strings.TrimRightFunc(h, func(r rune) bool { return r < '0' || r > '9' }))
+	return width, height
+}
+
+// parseXrandr reads connected outputs from `xrandr --current`. The active
+// mode is the one whose rate carries a '*'; the connector name gives the
+// connection type.
+func parseXrandr(out string) []Display {
+	var displays []Display
+	current := -1
+	for line := range strings.Lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			current = -1
+			if len(fields) < 2 || fields[1] != "connected" {
+				continue
+			}
+			displays = append(displays, Display{
+				Name:       fields[0],
+				Connection: xrandrConnection(fields[0]),
+				Main:       len(fields) > 2 && fields[2] == "primary",
+			})
+			current = len(displays) - 1
+			continue
+		}
+		if current < 0 {
+			continue
+		}
+		for _, rate := range fields[1:] {
+			if !strings.Contains(rate, "*") {
+				continue
+			}
+			d := &displays[current]
+			d.Width, d.Height = parseDisplaySize(fields[0])
+			d.RefreshHz, _ = strconv.ParseFloat(strings.TrimRight(rate, "*+"), 64)
+		}
+	}
+	return displays
+}
+
+func xrandrConnection(output string) string {
+	upper := strings.ToUpper(output)
+	switch {
+	case strings.HasPrefix(upper, "EDP"), strings.HasPrefix(upper, "LVDS"), strings.HasPrefix(upper, "DSI"):
+		return "Internal"
+	case strings.HasPrefix(upper, "HDMI"):
+		return "HDMI"
+	case strings.HasPrefix(upper, "DP"), strings.HasPrefix(upper, "DISPLAYPORT"):
+		return "DisplayPort"
+	case strings.HasPrefix(upper, "DVI"):
+		return "DVI"
+	case strings.HasPrefix(upper, "VGA"):
+		return "VGA"
+	}
+	return ""
+}
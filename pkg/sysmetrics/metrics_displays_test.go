@@ -0,0 +1,45 @@
+package sysmetrics
+
+import "testing"
+
+func TestParseSPDisplays(t *testing.T) {
+	out := `{"SPDisplaysDataType":[{"_name":"Apple M2 Pro","spdisplays_ndrvs":[
+  {"_name":"Color LCD","_spdisplays_pixels":"3024 x 1964","_spdisplays_resolution":"1512 x 982 @ 120.00Hz",
+   "spdisplays_connection_type":"spdisplays_internal","spdisplays_display_type":"spdisplays_built-in-liquid-retina-xdr"},
+  {"_name":"LG HDR 4K","_spdisplays_pixels":"3840 x 2160","_spdisplays_resolution":"3840 x 2160 @ 30.00Hz",
+   "spdisplays_main":"spdisplays_yes"}
+]}]}`
+	got := parseSPDisplays(out)
+	if len(got) != 2 {
+		t.Fatalf("got %d displays, want 2: %+v", len(got), got)
+	}
+	builtin := got[0]
+	if builtin.Connection != "Internal" || builtin.Width != 3024 || builtin.LooksWidth != 1512 || builtin.RefreshHz != 120 || !builtin.HDR {
+		t.Fatalf("built-in = %+v", builtin)
+	}
+	lg := got[1]
+	if lg.Width != 3840 || lg.LooksWidth != 0 || lg.RefreshHz != 30 || !lg.Main || lg.Connection != "" {
+		t.Fatalf("external = %+v", lg)
+	}
+}
+
+func TestParseXrandr(t *testing.T) {
+	out := `Screen 0: minimum 320 x 200, current 4480 x 1440, maximum 16384 x 16384
+eDP-1 connected 1920x1080+2560+0 (normal left inverted right x axis y axis) 344mm x 194mm
+   1920x1080     60.02*+  48.00
+HDMI-1 connected primary 2560x1440+0+0 (normal left inverted right x axis y axis) 597mm x 336mm
+   2560x1440     59.95 +  30.00*
+   1920x1080     60.00
+DP-1 disconnected (normal left inverted right x axis y axis)
+`
+	got := parseXrandr(out)
+	if len(got) != 2 {
+		t.Fatalf("got %d displays, want 2: %+v", len(got), got)
+	}
+	if got[0].Connection != "Internal" || got[0].RefreshHz != 60.02 || got[0].Width != 1920 {
+		t.Fatalf("eDP = %+v", got[0])
+	}
+	if got[1].Connection != "HDMI" || !got[1].Main || got[1].Width != 2560 || got[1].RefreshHz != 30 {
+		t.Fatalf("HDMI = %+v", got[1])
+	}
+}
@@ -0,0 +1,145 @@
+package sysmetrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	dnsProbeHost    = "www.apple.com"
+	dnsProbeTimeout = 2 * time.Second
+	dnsMaxServers   = 3
+	// Resolvers slower than this are flagged; a healthy one answers a cached
+	// popular name in tens of milliseconds.
+	dnsSlowMs = 200
+)
+
+var (
+	resolvConfPath = "/etc/resolv.conf"
+	dnsLookupFunc  = lookupViaServer
+)
+
+func collectDNS() DNSStatus {
+	var status DNSStatus
+	if runtime.GOOS == "darwin" && commandExists("scutil") {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		out, err := runCmd(ctx, "scutil", "--dns")
+		cancel()
+		if err == nil {
+			status = parseScutilDNS(out)
+		}
+	}
+	if len(status.Servers) == 0 {
+		if data, err := os.ReadFile(resolvConfPath); err == nil {
+			status = parseResolvConf(string(data))
+		}
+	}
+	if len(status.Servers) > dnsMaxServers {
+		status.Servers = status.Servers[:dnsMaxServers]
+	}
+
+	var wg sync.WaitGroup
+	for i := range status.Servers {
+		wg.Go(func() { probeDNSServer(&status.Servers[i]) })
+	}
+	wg.Wait()
+	return status
+}
+
+func probeDNSServer(server *DNSServer) {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsProbeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := dnsLookupFunc(ctx, server.Address, dnsProbeHost)
+	server.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		server.Error = "timeout"
+	case err != nil:
+		server.Error = "failed"
+	}
+	server.Slow = err == nil && server.LatencyMs >= dnsSlowMs
+}
+
+// lookupViaServer resolves host against one nameserver, bypassing the
+// system's resolver order so each server is timed on its own.
+func lookupViaServer(ctx context.Context, server, host string) error {
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, net.JoinHostPort(server, "53"))
+		},
+	}
+	_, err := resolver.LookupHost(ctx, host)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// parseScutilDNS reads the first resolver in `scutil --dns`, which is the
+// one macOS uses for unscoped queries.
+func parseScutilDNS(out string) DNSStatus {
+	var status DNSStatus
+	inFirst := false
+	for line := range strings.Lines(out) {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "resolver #") {
+			if inFirst {
+				break
+			}
+			inFirst = line == "resolver #1"
+			continue
+		}
+		if !inFirst {
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(key, "nameserver["):
+			status.addServer(value)
+		case strings.HasPrefix(key, "search domain["):
+			status.SearchDomains = append(status.SearchDomains, value)
+		}
+	}
+	return status
+}
+
+func parseResolvConf(data string) DNSStatus {
+	var status DNSStatus
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			status.addServer(fields[1])
+		case "search", "domain":
+			status.SearchDomains = fields[1:]
+		}
+	}
+	return status
+}
+
+func (s *DNSStatus) addServer(addr string) {
+	if slices.ContainsFunc(s.Servers, func(d DNSServer) bool { return d.Address == addr }) {
+		return
+	}
+	s.Servers = append(s.Servers, DNSServer{Address: addr})
+}
@@ -0,0 +1,84 @@
+package sysmetrics
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseScutilDNSUsesFirstResolver(t *testing.T) {
+	out := `DNS configuration
+
+resolver #1
+  search domain[0] : lan
+  search domain[1] : corp.example.com
+  nameserver[0] : 192.168.1.1
+  nameserver[1] : 1.1.1.1
+  if_index : 15 (en0)
+  flags    : Request A records
+  reach    : 0x00020002 (Reachable,Directly Reachable Address)
+
+resolver #2
+  domain   : local
+  options  : mdns
+  nameserver[0] : 224.0.0.251
+`
+	status := parseScutilDNS(out)
+	if len(status.Servers) != 2 || status.Servers[0].Address != "192.168.1.1" || status.Servers[1].Address != "1.1.1.1" {
+		t.Fatalf("servers = %+v", status.Servers)
+	}
+	if len(status.SearchDomains) != 2 || status.SearchDomains[1] != "corp.example.com" {
+		t.Fatalf("search domains = %v", status.SearchDomains)
+	}
+}
+
+func TestParseResolvConf(t *testing.T) {
+	status := parseResolvConf(`# Generated by NetworkManager
+search home.arpa example.com
+nameserver 127.0.0.53
+nameserver 127.0.0.53
+options edns0 trust-ad
+`)
+	if len(status.Servers) != 1 || status.Servers[0].Address != "127.0.0.53" {
+		t.Fatalf("servers = %+v", status.Servers)
+	}
+	if len(status.SearchDomains) != 2 || status.SearchDomains[0] != "home.arpa" {
+		t.Fatalf("search domains = %v", status.SearchDomains)
+	}
+}
+
+func TestCollectDNSFlagsFailingResolvers(t *testing.T) {
+	origLookup := dnsLookupFunc
+	origPath := resolvConfPath
+	origCommandExists := commandExists
+	t.Cleanup(func() {
+		dnsLookupFunc = origLookup
+		resolvConfPath = origPath
+		commandExists = origCommandExists
+	})
+
+	resolvConfPath = filepath.Join(t.TempDir(), "resolv.conf")
+	if err := os.WriteFile(resolvConfPath, []byte("nameserver 10.0.0.1\nnameserver 10.0.0.2\nnameserver 10.0.0.3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	commandExists = func(string) bool { return false }
+	dnsLookupFunc = func(ctx context.Context, server, host string) error {
+		switch server {
+		case "10.0.0.2":
+			return context.DeadlineExceeded
+		case "10.0.0.3":
+			return errors.New("no such host")
+		}
+		return nil
+	}
+
+	status := collectDNS()
+	if len(status.Servers) != 3 {
+		t.Fatalf("servers = %+v", status.Servers)
+	}
+	if status.Servers[0].Error != "" || status.Servers[1].Error != "timeout" || status.Servers[2].Error != "failed" {
+		t.Fatalf("errors = %+v", status.Servers)
+	}
+}
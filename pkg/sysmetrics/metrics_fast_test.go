@@ -1,4 +1,4 @@
-package main
+package sysmetrics
 
 import (
 	"context"
@@ -54,7 +54,7 @@ func TestCollectFastAvoidsExternalCommands(t *testing.T) {
 	}
 
 	collector := NewCollector(ProcessWatchOptions{})
-	if _, err := collector.CollectFast(); err != nil {
+	if _, err := collector.CollectFast(context.Background()); err != nil {
 		t.Fatalf("CollectFast() error = %v", err)
 	}
 	if externalCalls.Load() != 0 {
@@ -109,7 +109,7 @@ func TestCollectProcessesKeepsLiveProcessesWithCachedEnrichment(t *testing.T) {
 		},
 	})
 
-	snapshot, err := collector.CollectProcesses()
+	snapshot, err := collector.CollectProcesses(context.Background())
 	if err != nil {
 		t.Fatalf("CollectProcesses() error = %v", err)
 	}
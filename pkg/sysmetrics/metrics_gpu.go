@@ -0,0 +1,350 @@
+package sysmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	systemProfilerTimeout = 4 * time.Second
+	macGPUInfoTTL         = 10 * time.Minute
+	macGPUUsageTTL        = 5 * time.Second
+	powermetricsTimeout   = 2 * time.Second
+
+	// A registry read takes milliseconds, so a hot-plugged eGPU shows up
+	// within a minute; the system_profiler fallback keeps the long cache.
+	macGPURegistryTTL = time.Minute
+)
+
+func (c *Collector) collectGPU(now time.Time) ([]GPUStatus, error) {
+	if runtime.GOOS == "darwin" {
+		// Static GPU info.
+		ttl := macGPUInfoTTL
+		if info, _ := c.gpuInfo.Peek(); info.fromRegistry {
+			ttl = macGPURegistryTTL
+		}
+		info, _ := c.gpuInfo.Get(now, ttl, readMacGPUStatic)
+
+		// Real-time GPU usage.
+		if len(info.gpus) > 0 {
+			result := slices.Clone(info.gpus)
+			c.applyMacGPUUsage(result, now)
+			for i := range result {
+				result[i].SampledAt = now
+			}
+			attachGPUProcesses(result, c.collectGPUProcesses(now))
+			return result, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Millisecond)
+	defer cancel()
+
+	if !commandExists("nvidia-smi") {
+		return []GPUStatus{{
+			Name: "No GPU metrics available",
+			Note: "Install nvidia-smi or use platform-specific metrics",
+		}}, nil
+	}
+
+	gpus, sampledAt := c.nvidia.Latest(now), c.nvidia.SampledAt()
+	if len(gpus) == 0 {
+		sampledAt = now
+		out, err := runCmd(ctx, "nvidia-smi", nvidiaQueryGPU, "--format=csv,noheader,nounits")
+		if err != nil {
+			return nil, err
+		}
+		for line := range strings.Lines(strings.TrimSpace(out)) {
+			if _, gpu, ok := parseNvidiaGPULine(line); ok {
+				gpus = append(gpus, gpu)
+			}
+		}
+		// Stream from the second refresh on, so a one-shot --json run never
+		// leaves nvidia-smi behind.
+		if c.nvidiaPolled {
+			c.nvidia.Start()
+		}
+		c.nvidiaPolled = true
+	}
+	for i := range gpus {
+		gpus[i].SampledAt = sampledAt
+	}
+
+	if len(gpus) == 0 {
+		return []GPUStatus{{
+			Name: "GPU read failed",
+			Note: "Verify nvidia-smi availability",
+		}}, nil
+	}
+	attachGPUProcesses(gpus, c.collectGPUProcesses(now))
+
+	return gpus, nil
+}
+
+// macGPUInfo is the static part of the GPU list and where it came from,
+// which sets how long it is trusted.
+type macGPUInfo struct {
+	gpus         []GPUStatus
+	fromRegistry bool
+}
+
+func readMacGPUStatic(time.Time) (macGPUInfo, bool) {
+	if gpus := readRegistryGPUInfo(); len(gpus) > 0 {
+		return macGPUInfo{gpus: gpus, fromRegistry: true}, true
+	}
+	gpus, err := readMacGPUInfo()
+	return macGPUInfo{gpus: gpus}, err == nil && len(gpus) > 0
+}
+
+// readRegistryGPUInfo names each IOAccelerator from the registry. Apple
+// Silicon's AGX driver publishes the chip name and core count itself; a PCI
+// GPU keeps its model and VRAM on the provider. The Metal family is only in
+// system_profiler and is left out.
+func readRegistryGPUInfo() []GPUStatus {
+	entries, err := readIORegistryFunc("IOAccelerator")
+	if err != nil {
+		return nil
+	}
+	return gpusFromRegistry(entries)
+}
+
+func gpusFromRegistry(entries []ioRegistryEntry) []GPUStatus {
+	var gpus []GPUStatus
+	for _, e := range entries {
+		name := registryText(e.props, "model")
+		if name == "" {
+			name = registryText(e.parent, "model")
+		}
+		if name == "" {
+			continue
+		}
+		var noteParts []string
+		if mb := max(plistUint(e.props, "VRAM,totalMB"), plistUint(e.parent, "VRAM,totalMB")); mb > 0 {
+			noteParts = append(noteParts, "VRAM "+formatVRAM(mb))
+		}
+		if vendor := acceleratorVendor(plistString(e.props, "IOClass")); vendor != "" {
+			noteParts = append(noteParts, vendor)
+		}
+		gpus = append(gpus, GPUStatus{
+			Name:      name,
+			Usage:     -1, // Will be updated with real-time data
+			CoreCount: int(plistUint(e.props, "gpu-core-count")),
+			Note:      strings.Join(noteParts, " · "),
+		})
+	}
+	return gpus
+}
+
+// registryText reads a property that drivers store either as a string or as
+// NUL-terminated data, as PCI devices do for "model".
+func registryText(dict map[string]any, key string) string {
+	switch v := dict[key].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case []byte:
+		text, _, _ := strings.Cut(string(v), "\x00")
+		return strings.TrimSpace(text)
+	}
+	return ""
+}
+
+// formatVRAM matches system_profiler's "8 GB" and "1536 MB".
+func formatVRAM(mb uint64) string {
+	if mb%1024 == 0 {
+		return strconv.FormatUint(mb/1024, 10) + " GB"
+	}
+	return strconv.FormatUint(mb, 10) + " MB"
+}
+
+func readMacGPUInfo() ([]GPUStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), systemProfilerTimeout)
+	defer cancel()
+
+	if !commandExists("system_profiler") {
+		return nil, errors.New("system_profiler unavailable")
+	}
+
+	out, err := runCmd(ctx, "system_profiler", "-json", "SPDisplaysDataType")
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		Displays []struct {
+			Name   string `json:"_name"`
+			VRAM   string `json:"spdisplays_vram"`
+			Vendor string `json:"spdisplays_vendor"`
+			Metal  string `json:"spdisplays_metal"`
+			Cores  string `json:"sppci_cores"`
+		} `json:"SPDisplaysDataType"`
+	}
+	if err := json.Unmarshal([]byte(out), &data); err != nil {
+		return nil, err
+	}
+
+	var gpus []GPUStatus
+	for _, d := range data.Displays {
+		if d.Name == "" {
+			continue
+		}
+		noteParts := []string{}
+		if d.VRAM != "" {
+			noteParts = append(noteParts, "VRAM "+d.VRAM)
+		}
+		if d.Metal != "" {
+			noteParts = append(noteParts, d.Metal)
+		}
+		if d.Vendor != "" {
+			noteParts = append(noteParts, d.Vendor)
+		}
+		note := strings.Join(noteParts, " · ")
+		coreCount, _ := strconv.Atoi(d.Cores)
+		gpus = append(gpus, GPUStatus{
+			Name:      d.Name,
+			Usage:     -1, // Will be updated with real-time data
+			CoreCount: coreCount,
+			Note:      note,
+		})
+	}
+
+	if len(gpus) == 0 {
+		return []GPUStatus{{
+			Name: "GPU info unavailable",
+			Note: "Unable to parse system_profiler output",
+		}}, nil
+	}
+
+	return gpus, nil
+}
+
+// applyMacGPUUsage gives each GPU its own accelerator's utilization, which
+// any user can read. powermetrics (root) only measures the built-in GPU, so
+// it fills in for the first GPU when its driver publishes no counter.
+func (c *Collector) applyMacGPUUsage(gpus []GPUStatus, now time.Time) {
+	// Power and cluster frequencies reuse this sample, so take it either way.
+	sample := c.samplePowermetrics(now)
+	matchAcceleratorUsage(gpus, readAcceleratorUsage())
+	if len(gpus) > 0 && gpus[0].Usage < 0 {
+		gpus[0].Usage = sample.gpuActive
+	}
+}
+
+// acceleratorUsage is one IOAccelerator's utilization and the vendor its
+// driver class names.
+type acceleratorUsage struct {
+	vendor     string
+	usage      float64
+	wiredBytes uint64 // System memory the driver has allocated, Apple only
+}
+
+// Driver class prefixes and the vendor name system_profiler uses for the
+// same GPU, e.g. AGXAcceleratorG14X -> "Apple M1 Pro".
+var acceleratorVendors = []struct{ prefix, vendor string }{
+	{"AGX", "Apple"},
+	{"Intel", "Intel"},
+	{"AMD", "AMD"},
+	{"NVDA", "NVIDIA"},
+	{"GeForce", "NVIDIA"},
+}
+
+func acceleratorVendor(class string) string {
+	for _, v := range acceleratorVendors {
+		if strings.HasPrefix(class, v.prefix) {
+			return v.vendor
+		}
+	}
+	return ""
+}
+
+func readAcceleratorUsage() []acceleratorUsage {
+	if entries, err := readIORegistryFunc("IOAccelerator"); err == nil {
+		dicts := make([]map[string]any, 0, len(entries))
+		for _, e := range entries {
+			dicts = append(dicts, e.props)
+		}
+		return acceleratorUsageFrom(dicts)
+	}
+	if !commandExists("ioreg") {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	out, err := runCmd(ctx, "ioreg", "-a", "-r", "-d", "1", "-c", "IOAccelerator")
+	if err != nil {
+		return nil
+	}
+	return parseAcceleratorUsage(out)
+}
+
+// parseAcceleratorUsage reads "Device Utilization %" from each
+// IOAccelerator's PerformanceStatistics, in registry order. Apple Silicon,
+// Intel, and AMD drivers all publish it; -1 marks one that does not. Apple
+// GPUs also report "Alloc system memory", the unified memory wired for
+// Metal.
+func parseAcceleratorUsage(out string) []acceleratorUsage {
+	root, err := decodePlist(out)
+	if err != nil {
+		return nil
+	}
+	list, _ := root.([]any)
+	dicts := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		dict, _ := item.(map[string]any)
+		dicts = append(dicts, dict)
+	}
+	return acceleratorUsageFrom(dicts)
+}
+
+func acceleratorUsageFrom(dicts []map[string]any) []acceleratorUsage {
+	var accels []acceleratorUsage
+	for _, dict := range dicts {
+		accel := acceleratorUsage{usage: -1, vendor: acceleratorVendor(plistString(dict, "IOClass"))}
+		stats, _ := dict["PerformanceStatistics"].(map[string]any)
+		if value, ok := stats["Device Utilization %"].(uint64); ok {
+			accel.usage = float64(min(value, 100))
+		}
+		if accel.vendor == "Apple" {
+			accel.wiredBytes = plistUint(stats, "Alloc system memory")
+		}
+		accels = append(accels, accel)
+	}
+	return accels
+}
+
+// matchAcceleratorUsage pairs accelerators with system_profiler's GPUs by
+// vendor, so an eGPU or a MacBook Pro's discrete GPU gets its own reading
+// rather than the integrated GPU's. Leftovers pair up in order.
+func matchAcceleratorUsage(gpus []GPUStatus, accels []acceleratorUsage) {
+	used := make([]bool, len(accels))
+	take := func(i int, match func(acceleratorUsage) bool) bool {
+		for j, accel := range accels {
+			if !used[j] && match(accel) {
+				used[j] = true
+				gpus[i].Usage = accel.usage
+				if accel.wiredBytes > 0 {
+					// MiB, matching nvidia-smi's memory columns.
+					gpus[i].MemoryUsed = float64(accel.wiredBytes) / (1 << 20)
+					gpus[i].SharedMemory = true
+				}
+				return true
+			}
+		}
+		return false
+	}
+	var unmatched []int
+	for i := range gpus {
+		gpus[i].Usage = -1
+		if !take(i, func(a acceleratorUsage) bool { return a.vendor != "" && strings.Contains(gpus[i].Name, a.vendor) }) {
+			unmatched = append(unmatched, i)
+		}
+	}
+	for _, i := range unmatched {
+		take(i, func(acceleratorUsage) bool { return true })
+	}
+}
@@ -0,0 +1,142 @@
+package sysmetrics
+
+import (
+	"cmp"
+	"context"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const gpuProcessLimit = 5
+
+// collectGPUProcesses attributes GPU time to processes. macOS exposes a
+// cumulative GPU time per Metal client, so usage is the delta since the
+// previous full refresh and the first call returns nothing; nvidia-smi pmon
+// reports utilization directly.
+func (c *Collector) collectGPUProcesses(now time.Time) []GPUProcess {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if runtime.GOOS == "darwin" {
+		if !commandExists("ioreg") {
+			return nil
+		}
+		out, err := runCmd(ctx, "ioreg", "-a", "-r", "-d", "1", "-c", "AGXDeviceUserClient")
+		if err != nil {
+			return nil
+		}
+		clients := parseAGXClients(out)
+		procs := gpuTimeDeltas(c.prevGPUTime, clients, now.Sub(c.lastGPUTimeAt))
+		c.prevGPUTime = clients
+		c.lastGPUTimeAt = now
+		return topGPUProcesses(procs)
+	}
+
+	if !commandExists("nvidia-smi") {
+		return nil
+	}
+	out, err := runCmd(ctx, "nvidia-smi", "pmon", "-c", "1", "-s", "u")
+	if err != nil {
+		return nil
+	}
+	return topGPUProcesses(parseNvidiaPmon(out))
+}
+
+// gpuClient is one process's cumulative GPU time across its Metal clients.
+type gpuClient struct {
+	name string
+	ns   uint64
+}
+
+// parseAGXClients reads `ioreg -a -c AGXDeviceUserClient`, one dict per
+// Metal client with "IOUserClientCreator" = "pid 409, WindowServer" and an
+// AppUsage list carrying accumulatedGPUTime in nanoseconds.
+func parseAGXClients(out string) map[int]gpuClient {
+	root, err := decodePlist(out)
+	if err != nil {
+		return nil
+	}
+	list, _ := root.([]any)
+	clients := make(map[int]gpuClient)
+	for _, item := range list {
+		dict, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		pidText, name, ok := strings.Cut(strings.TrimPrefix(plistString(dict, "IOUserClientCreator"), "pid "), ", ")
+		if !ok {
+			continue
+		}
+		pid, err := strconv.Atoi(pidText)
+		if err != nil {
+			continue
+		}
+		client := clients[pid]
+		client.name = name
+		for _, usage := range plistDicts(dict, "AppUsage") {
+			client.ns += plistUint(usage, "accumulatedGPUTime")
+		}
+		clients[pid] = client
+	}
+	return clients
+}
+
+func gpuTimeDeltas(prev, cur map[int]gpuClient, elapsed time.Duration) []GPUProcess {
+	if prev == nil || elapsed <= 0 {
+		return nil
+	}
+	var procs []GPUProcess
+	for pid, client := range cur {
+		before, ok := prev[pid]
+		// A pid reused by a new process restarts its counter.
+		if !ok || client.ns < before.ns {
+			continue
+		}
+		usage := float64(client.ns-before.ns) / float64(elapsed.Nanoseconds()) * 100
+		if usage > 0 {
+			procs = append(procs, GPUProcess{PID: pid, Name: client.name, Usage: min(usage, 100)})
+		}
+	}
+	return procs
+}
+
+// parseNvidiaPmon reads `nvidia-smi pmon -s u`:
+//
+//	# gpu        pid  type    sm   mem   enc   dec   command
+//	    0       1234     C    45    12     -     -   python
+func parseNvidiaPmon(out string) []GPUProcess {
+	var procs []GPUProcess
+	for line := range strings.Lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) < 8 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		gpu, err1 := strconv.Atoi(fields[0])
+		pid, err2 := strconv.Atoi(fields[1])
+		sm, err3 := strconv.ParseFloat(fields[3], 64)
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+		procs = append(procs, GPUProcess{GPU: gpu, PID: pid, Name: fields[7], Usage: sm})
+	}
+	return procs
+}
+
+func topGPUProcesses(procs []GPUProcess) []GPUProcess {
+	slices.SortFunc(procs, func(a, b GPUProcess) int {
+		return cmp.Or(cmp.Compare(b.Usage, a.Usage), cmp.Compare(a.PID, b.PID))
+	})
+	return procs[:min(len(procs), gpuProcessLimit)]
+}
+
+// attachGPUProcesses hands each process to the device it ran on.
+func attachGPUProcesses(gpus []GPUStatus, procs []GPUProcess) {
+	for _, p := range procs {
+		if p.GPU >= 0 && p.GPU < len(gpus) {
+			gpus[p.GPU].Processes = append(gpus[p.GPU].Processes, p)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package sysmetrics
+
+import (
+	"testing"
+	"time"
+)
+
+const agxClientsPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<array>
+	<dict>
+		<key>IOUserClientCreator</key>
+		<string>pid 409, WindowServer</string>
+		<key>AppUsage</key>
+		<array>
+			<dict>
+				<key>API</key>
+				<string>Metal</string>
+				<key>accumulatedGPUTime</key>
+				<integer>4000000000</integer>
+			</dict>
+		</array>
+	</dict>
+	<dict>
+		<key>IOUserClientCreator</key>
+		<string>pid 812, Safari</string>
+		<key>AppUsage</key>
+		<array>
+			<dict>
+				<key>accumulatedGPUTime</key>
+				<integer>1000000000</integer>
+			</dict>
+		</array>
+	</dict>
+	<dict>
+		<key>IOUserClientCreator</key>
+		<string>pid 812, Safari</string>
+	</dict>
+</array>
+</plist>`
+
+func TestParseAGXClientsAndDeltas(t *testing.T) {
+	cur := parseAGXClients(agxClientsPlist)
+	if len(cur) != 2 || cur[409].name != "WindowServer" || cur[812].ns != 1_000_000_000 {
+		t.Fatalf("parseAGXClients() = %+v", cur)
+	}
+
+	if got := gpuTimeDeltas(nil, cur, 10*time.Second); got != nil {
+		t.Fatalf("first sample should report nothing, got %+v", got)
+	}
+	prev := map[int]gpuClient{
+		409: {name: "WindowServer", ns: 1_000_000_000},
+		812: {name: "Safari", ns: 2_000_000_000}, // pid reused, counter restarted
+	}
+	got := topGPUProcesses(gpuTimeDeltas(prev, cur, 10*time.Second))
+	if len(got) != 1 || got[0].PID != 409 || got[0].Usage != 30 {
+		t.Fatalf("deltas = %+v, want WindowServer at 30%%", got)
+	}
+}
+
+func TestParseNvidiaPmon(t *testing.T) {
+	out := `# gpu        pid  type    sm   mem   enc   dec   command
+# Idx          #   C/G     %     %     %     %   name
+    0       1234     C    45    12     -     -   python
+    1       2345     G     7     3     -     -   Xorg
+    1          -     -     -     -     -     -   -
+`
+	procs := parseNvidiaPmon(out)
+	if len(procs) != 2 {
+		t.Fatalf("got %d processes, want 2", len(procs))
+	}
+	gpus := make([]GPUStatus, 2)
+	attachGPUProcesses(gpus, procs)
+	if len(gpus[0].Processes) != 1 || gpus[0].Processes[0].Name != "python" || gpus[0].Processes[0].Usage != 45 {
+		t.Fatalf("gpu0 = %+v", gpus[0].Processes)
+	}
+	if len(gpus[1].Processes) != 1 || gpus[1].Processes[0].PID != 2345 {
+		t.Fatalf("gpu1 = %+v", gpus[1].Processes)
+	}
+}
@@ -0,0 +1,113 @@
+package sysmetrics
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseAcceleratorUsage(t *testing.T) {
+	out := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<array>
+	<dict>
+		<key>IOClass</key>
+		<string>AGXAcceleratorG14X</string>
+		<key>PerformanceStatistics</key>
+		<dict>
+			<key>Device Utilization %</key>
+			<integer>37</integer>
+			<key>Renderer Utilization %</key>
+			<integer>35</integer>
+			<key>Tiler Utilization %</key>
+			<integer>12</integer>
+			<key>Alloc system memory</key>
+			<integer>3221225472</integer>
+			<key>In use system memory</key>
+			<integer>1073741824</integer>
+		</dict>
+	</dict>
+	<dict>
+		<key>IOClass</key>
+		<string>IntelAccelerator</string>
+	</dict>
+</array>
+</plist>`
+	got := parseAcceleratorUsage(out)
+	want := []acceleratorUsage{{vendor: "Apple", usage: 37, wiredBytes: 3 << 30}, {vendor: "Intel", usage: -1}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("parseAcceleratorUsage() = %v, want %v", got, want)
+	}
+	if got := parseAcceleratorUsage("not a plist"); got != nil {
+		t.Fatalf("expected nil for bad input, got %v", got)
+	}
+}
+
+func TestMatchAcceleratorUsageByVendor(t *testing.T) {
+	// MacBook Pro with a discrete GPU plus an eGPU; ioreg lists the
+	// accelerators in a different order than system_profiler.
+	gpus := []GPUStatus{
+		{Name: "Intel UHD Graphics 630"},
+		{Name: "AMD Radeon Pro 5500M"},
+		{Name: "AMD Radeon RX 6800 XT"},
+		{Name: "Unknown Display Adapter"},
+	}
+	matchAcceleratorUsage(gpus, []acceleratorUsage{
+		{vendor: "AMD", usage: 80},
+		{vendor: "Intel", usage: 5},
+		{vendor: "AMD", usage: 12},
+		{usage: 40},
+	})
+	want := []float64{5, 80, 12, 40}
+	for i, w := range want {
+		if gpus[i].Usage != w {
+			t.Errorf("%s usage = %v, want %v", gpus[i].Name, gpus[i].Usage, w)
+		}
+	}
+
+	if gpus[0].SharedMemory || gpus[0].MemoryUsed != 0 {
+		t.Errorf("Intel GPU should not report unified memory: %+v", gpus[0])
+	}
+
+	single := []GPUStatus{{Name: "Apple M3 Max"}}
+	matchAcceleratorUsage(single, nil)
+	if single[0].Usage != -1 {
+		t.Fatalf("usage without accelerators = %v, want -1", single[0].Usage)
+	}
+	matchAcceleratorUsage(single, []acceleratorUsage{{vendor: "Apple", usage: 20, wiredBytes: 3 << 30}})
+	if !single[0].SharedMemory || single[0].MemoryUsed != 3072 {
+		t.Fatalf("Apple GPU = %+v, want 3072 MiB shared", single[0])
+	}
+}
+
+func TestGPUsFromRegistry(t *testing.T) {
+	old := readIORegistryFunc
+	defer func() { readIORegistryFunc = old }()
+	readIORegistryFunc = func(class string) ([]ioRegistryEntry, error) {
+		if class != "IOAccelerator" {
+			t.Fatalf("class = %q", class)
+		}
+		return []ioRegistryEntry{
+			{props: map[string]any{"IOClass": "AGXAcceleratorG13X", "model": "Apple M1 Pro", "gpu-core-count": uint64(16)}},
+			{
+				props:  map[string]any{"IOClass": "AMDRadeonX6000_AMDNavi14GraphicsAccelerator"},
+				parent: map[string]any{"model": []byte("AMD Radeon Pro 5500M\x00"), "VRAM,totalMB": uint64(8192)},
+			},
+			{props: map[string]any{"IOClass": "IOAccelerator"}},
+		}, nil
+	}
+
+	got := readRegistryGPUInfo()
+	want := []GPUStatus{
+		{Name: "Apple M1 Pro", Usage: -1, CoreCount: 16, Note: "Apple"},
+		{Name: "AMD Radeon Pro 5500M", Usage: -1, Note: "VRAM 8 GB · AMD"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("readRegistryGPUInfo() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || got[i].Usage != want[i].Usage || got[i].CoreCount != want[i].CoreCount || got[i].Note != want[i].Note {
+			t.Fatalf("gpu %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package sysmetrics
 
 import (
 	"context"
@@ -6,6 +6,8 @@ import (
 	"runtime"
 	"strings"
 	"time"
+
+	"github.com/tw93/mole/internal/units"
 )
 
 func collectHardware(totalRAM uint64, disks []DiskStatus) HardwareInfo {
@@ -13,7 +15,7 @@ func collectHardware(totalRAM uint64, disks []DiskStatus) HardwareInfo {
 		return HardwareInfo{
 			Model:       "Unknown",
 			CPUModel:    runtime.GOARCH,
-			TotalRAM:    humanBytes(totalRAM),
+			TotalRAM:    units.BytesBin(totalRAM),
 			DiskSize:    "Unknown",
 			OSVersion:   runtime.GOOS,
 			RefreshRate: "",
@@ -69,13 +71,13 @@ func collectHardware(totalRAM uint64, disks []DiskStatus) HardwareInfo {
 
 	diskSize := "Unknown"
 	if len(disks) > 0 {
-		diskSize = humanBytes(disks[0].Total)
+		diskSize = units.BytesBin(disks[0].Total)
 	}
 
 	return HardwareInfo{
 		Model:       model,
 		CPUModel:    cpuModel,
-		TotalRAM:    humanBytes(totalRAM),
+		TotalRAM:    units.BytesBin(totalRAM),
 		DiskSize:    diskSize,
 		OSVersion:   osVersion,
 		RefreshRate: refreshRate,
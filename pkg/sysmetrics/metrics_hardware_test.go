@@ -0,0 +1,84 @@
+package sysmetrics
+
+import "testing"
+
+func TestParseInt(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  int
+	}{
+		// Basic integers.
+		{"simple number", "123", 123},
+		{"zero", "0", 0},
+		{"single digit", "5", 5},
+
+		// With whitespace.
+		{"leading space", "  42", 42},
+		{"trailing space", "42  ", 42},
+		{"both spaces", "  42  ", 42},
+
+		// With non-numeric padding.
+		{"leading @", "@60", 60},
+		{"trailing Hz", "120Hz", 120},
+		{"both padding", "@60Hz", 60},
+
+		// Decimals (truncated to int).
+		{"decimal", "60.00", 60},
+		{"decimal with suffix", "119.88hz", 119},
+
+		// Edge cases.
+		{"empty string", "", 0},
+		{"only spaces", "   ", 0},
+		{"no digits", "abc", 0},
+		{"negative strips sign", "-5", 5}, // Strips non-numeric prefix.
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseInt(tt.input)
+			if got != tt.want {
+				t.Errorf("parseInt(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRefreshRate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		// Standard formats.
+		{"60Hz format", "Resolution: 1920x1080 @ 60Hz", "60Hz"},
+		{"120Hz format", "Resolution: 2560x1600 @ 120Hz", "120Hz"},
+		{"separated Hz", "Refresh Rate: 60 Hz", "60Hz"},
+
+		// Decimal refresh rates.
+		{"decimal Hz", "Resolution: 3840x2160 @ 59.94Hz", "59Hz"},
+		{"ProMotion", "Resolution: 3456x2234 @ 120.00Hz", "120Hz"},
+
+		// Multiple lines — picks highest valid.
+		{"multiple rates", "Display 1: 60Hz\nDisplay 2: 120Hz", "120Hz"},
+
+		// Edge cases.
+		{"empty string", "", ""},
+		{"no Hz found", "Resolution: 1920x1080", ""},
+		{"invalid Hz value", "Rate: abcHz", ""},
+		{"Hz too high filtered", "Rate: 600Hz", ""},
+
+		// Case insensitivity.
+		{"lowercase hz", "60hz", "60Hz"},
+		{"uppercase HZ", "60HZ", "60Hz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseRefreshRate(tt.input)
+			if got != tt.want {
+				t.Errorf("parseRefreshRate(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package sysmetrics
 
 import (
 	"fmt"
@@ -15,43 +15,43 @@ const (
 	healthIOWeight      = 10.0
 
 	// CPU.
-	cpuNormalThreshold = 50.0
-	cpuHighThreshold   = 85.0
+	CPUNormalThreshold = 50.0
+	CPUHighThreshold   = 85.0
 
 	// Memory.
-	memNormalThreshold     = 70.0
-	memHighThreshold       = 88.0
+	MemNormalThreshold     = 70.0
+	MemHighThreshold       = 88.0
 	memPressureWarnPenalty = 5.0
 	memPressureCritPenalty = 15.0
 
 	// Disk.
-	diskWarnThreshold = 80.0
-	diskCritThreshold = 93.0
+	DiskWarnThreshold = 80.0
+	DiskCritThreshold = 93.0
 
 	// Thermal.
-	thermalNormalThreshold = 65.0
-	thermalHighThreshold   = 85.0
+	ThermalNormalThreshold = 65.0
+	ThermalHighThreshold   = 85.0
 
 	// Disk IO (MB/s).
-	ioNormalThreshold = 50.0
-	ioHighThreshold   = 150.0
+	IONormalThreshold = 50.0
+	IOHighThreshold   = 150.0
 
 	// Battery.
-	batteryCycleWarn   = 800
-	batteryCycleDanger = 900
-	batteryCapWarn     = 80
-	batteryCapDanger   = 60
+	BatteryCycleWarn   = 800
+	BatteryCycleDanger = 900
+	BatteryCapWarn     = 80
+	BatteryCapDanger   = 60
 
 	// Uptime (seconds).
-	uptimeWarnDays   = 7
-	uptimeDangerDays = 14
-	uptimeWarnSecs   = uptimeWarnDays * 86400
-	uptimeDangerSecs = uptimeDangerDays * 86400
+	UptimeWarnDays   = 7
+	UptimeDangerDays = 14
+	uptimeWarnSecs   = UptimeWarnDays * 86400
+	uptimeDangerSecs = UptimeDangerDays * 86400
 
 	// Score display bands (shared with view.go score styling).
-	scoreExcellentThreshold = 85
-	scoreGoodThreshold      = 65
-	scoreFairThreshold      = 45
+	ScoreExcellentThreshold = 85
+	ScoreGoodThreshold      = 65
+	ScoreFairThreshold      = 45
 )
 
 func calculateHealthScore(cpu CPUStatus, mem MemoryStatus, disks []DiskStatus, diskIO DiskIOStatus, thermal ThermalStatus, batteries []BatteryStatus, uptimeSecs uint64) (int, string) {
@@ -60,37 +60,37 @@ func calculateHealthScore(cpu CPUStatus, mem MemoryStatus, disks []DiskStatus, d
 
 	// CPU penalty.
 	cpuPenalty := 0.0
-	if cpu.Usage > cpuNormalThreshold {
-		if cpu.Usage > cpuHighThreshold {
+	if cpu.Usage > CPUNormalThreshold {
+		if cpu.Usage > CPUHighThreshold {
 			// Scale across the remaining range up to 100% so the penalty keeps
 			// growing with usage (matches the disk branch). Dividing by the raw
 			// high threshold instead made the penalty drop past 85%, letting the
 			// score rise as CPU load got worse.
-			cpuPenalty = healthCPUWeight * (cpu.Usage - cpuNormalThreshold) / (100 - cpuNormalThreshold)
+			cpuPenalty = healthCPUWeight * (cpu.Usage - CPUNormalThreshold) / (100 - CPUNormalThreshold)
 		} else {
-			cpuPenalty = (healthCPUWeight / 2) * (cpu.Usage - cpuNormalThreshold) / (cpuHighThreshold - cpuNormalThreshold)
+			cpuPenalty = (healthCPUWeight / 2) * (cpu.Usage - CPUNormalThreshold) / (CPUHighThreshold - CPUNormalThreshold)
 		}
 	}
 	score -= cpuPenalty
-	if cpu.Usage > cpuHighThreshold {
+	if cpu.Usage > CPUHighThreshold {
 		issues = append(issues, "High CPU")
 	}
 
 	// Memory penalty.
 	memPenalty := 0.0
-	if mem.UsedPercent > memNormalThreshold {
-		if mem.UsedPercent > memHighThreshold {
+	if mem.UsedPercent > MemNormalThreshold {
+		if mem.UsedPercent > MemHighThreshold {
 			// Scale across the remaining range up to 100% so the penalty keeps
 			// growing with usage (matches the disk branch). Dividing by the raw
 			// normal threshold instead made the penalty drop past 88%, letting
 			// the score rise as memory pressure got worse.
-			memPenalty = healthMemWeight * (mem.UsedPercent - memNormalThreshold) / (100 - memNormalThreshold)
+			memPenalty = healthMemWeight * (mem.UsedPercent - MemNormalThreshold) / (100 - MemNormalThreshold)
 		} else {
-			memPenalty = (healthMemWeight / 2) * (mem.UsedPercent - memNormalThreshold) / (memHighThreshold - memNormalThreshold)
+			memPenalty = (healthMemWeight / 2) * (mem.UsedPercent - MemNormalThreshold) / (MemHighThreshold - MemNormalThreshold)
 		}
 	}
 	score -= memPenalty
-	if mem.UsedPercent > memHighThreshold {
+	if mem.UsedPercent > MemHighThreshold {
 		issues = append(issues, "High Memory")
 	}
 
@@ -108,15 +108,15 @@ func calculateHealthScore(cpu CPUStatus, mem MemoryStatus, disks []DiskStatus, d
 	diskPenalty := 0.0
 	if len(disks) > 0 {
 		diskUsage := disks[0].UsedPercent
-		if diskUsage > diskWarnThreshold {
-			if diskUsage > diskCritThreshold {
-				diskPenalty = healthDiskWeight * (diskUsage - diskWarnThreshold) / (100 - diskWarnThreshold)
+		if diskUsage > DiskWarnThreshold {
+			if diskUsage > DiskCritThreshold {
+				diskPenalty = healthDiskWeight * (diskUsage - DiskWarnThreshold) / (100 - DiskWarnThreshold)
 			} else {
-				diskPenalty = (healthDiskWeight / 2) * (diskUsage - diskWarnThreshold) / (diskCritThreshold - diskWarnThreshold)
+				diskPenalty = (healthDiskWeight / 2) * (diskUsage - DiskWarnThreshold) / (DiskCritThreshold - DiskWarnThreshold)
 			}
 		}
 		score -= diskPenalty
-		if diskUsage > diskCritThreshold {
+		if diskUsage > DiskCritThreshold {
 			issues = append(issues, "Disk Almost Full")
 		}
 	}
@@ -124,12 +124,12 @@ func calculateHealthScore(cpu CPUStatus, mem MemoryStatus, disks []DiskStatus, d
 	// Thermal penalty.
 	thermalPenalty := 0.0
 	if thermal.CPUTemp > 0 {
-		if thermal.CPUTemp > thermalNormalThreshold {
-			if thermal.CPUTemp > thermalHighThreshold {
+		if thermal.CPUTemp > ThermalNormalThreshold {
+			if thermal.CPUTemp > ThermalHighThreshold {
 				thermalPenalty = healthThermalWeight
 				issues = append(issues, "Overheating")
 			} else {
-				thermalPenalty = healthThermalWeight * (thermal.CPUTemp - thermalNormalThreshold) / (thermalHighThreshold - thermalNormalThreshold)
+				thermalPenalty = healthThermalWeight * (thermal.CPUTemp - ThermalNormalThreshold) / (ThermalHighThreshold - ThermalNormalThreshold)
 			}
 		}
 		score -= thermalPenalty
@@ -138,12 +138,12 @@ func calculateHealthScore(cpu CPUStatus, mem MemoryStatus, disks []DiskStatus, d
 	// Disk IO penalty.
 	ioPenalty := 0.0
 	totalIO := diskIO.ReadRate + diskIO.WriteRate
-	if totalIO > ioNormalThreshold {
-		if totalIO > ioHighThreshold {
+	if totalIO > IONormalThreshold {
+		if totalIO > IOHighThreshold {
 			ioPenalty = healthIOWeight
 			issues = append(issues, "Heavy Disk IO")
 		} else {
-			ioPenalty = healthIOWeight * (totalIO - ioNormalThreshold) / (ioHighThreshold - ioNormalThreshold)
+			ioPenalty = healthIOWeight * (totalIO - IONormalThreshold) / (IOHighThreshold - IONormalThreshold)
 		}
 	}
 	score -= ioPenalty
@@ -151,7 +151,7 @@ func calculateHealthScore(cpu CPUStatus, mem MemoryStatus, disks []DiskStatus, d
 	// Battery health penalty (only when battery present).
 	if len(batteries) > 0 {
 		b := batteries[0]
-		_, sev := batteryHealthLabel(b.CycleCount, b.Capacity)
+		_, sev := BatteryHealthLabel(b.CycleCount, b.Capacity)
 		switch sev {
 		case "danger":
 			score -= 5
@@ -180,11 +180,11 @@ func calculateHealthScore(cpu CPUStatus, mem MemoryStatus, disks []DiskStatus, d
 	// Build message.
 	var msg string
 	switch {
-	case score >= scoreExcellentThreshold:
+	case score >= ScoreExcellentThreshold:
 		msg = "Excellent"
-	case score >= scoreGoodThreshold:
+	case score >= ScoreGoodThreshold:
 		msg = "Good"
-	case score >= scoreFairThreshold:
+	case score >= ScoreFairThreshold:
 		msg = "Fair"
 	default:
 		msg = "Needs Attention"
@@ -197,20 +197,20 @@ func calculateHealthScore(cpu CPUStatus, mem MemoryStatus, disks []DiskStatus, d
 	return int(score), msg
 }
 
-// batteryHealthLabel returns a human-readable health label and severity based on cycle count and capacity.
+// BatteryHealthLabel returns a human-readable health label and severity based on cycle count and capacity.
 // Severity is "ok", "warn", or "danger".
-func batteryHealthLabel(cycles int, capacity int) (string, string) {
-	if cycles > batteryCycleDanger || (capacity > 0 && capacity < batteryCapDanger) {
+func BatteryHealthLabel(cycles int, capacity int) (string, string) {
+	if cycles > BatteryCycleDanger || (capacity > 0 && capacity < BatteryCapDanger) {
 		return "Service Soon", "danger"
 	}
-	if cycles > batteryCycleWarn || (capacity > 0 && capacity < batteryCapWarn) {
+	if cycles > BatteryCycleWarn || (capacity > 0 && capacity < BatteryCapWarn) {
 		return "Fair", "warn"
 	}
 	return "Healthy", "ok"
 }
 
-// uptimeSeverity returns "ok", "warn", or "danger" based on uptime seconds.
-func uptimeSeverity(secs uint64) string {
+// UptimeSeverity returns "ok", "warn", or "danger" based on uptime seconds.
+func UptimeSeverity(secs uint64) string {
 	if secs > uptimeDangerSecs {
 		return "danger"
 	}
@@ -220,7 +220,7 @@ func uptimeSeverity(secs uint64) string {
 	return "ok"
 }
 
-func formatUptime(secs uint64) string {
+func FormatUptime(secs uint64) string {
 	days := secs / 86400
 	hours := (secs % 86400) / 3600
 	mins := (secs % 3600) / 60
@@ -1,4 +1,4 @@
-package main
+package sysmetrics
 
 import (
 	"strings"
@@ -88,65 +88,17 @@ func TestCalculateHealthScoreMonotonicInMemory(t *testing.T) {
 }
 
 func TestFormatUptime(t *testing.T) {
-	if got := formatUptime(65); got != "1m" {
+	if got := FormatUptime(65); got != "1m" {
 		t.Fatalf("expected 1m, got %s", got)
 	}
-	if got := formatUptime(3600 + 120); got != "1h 2m" {
+	if got := FormatUptime(3600 + 120); got != "1h 2m" {
 		t.Fatalf("expected \"1h 2m\", got %s", got)
 	}
-	if got := formatUptime(86400*2 + 3600*3 + 60*5); got != "2d 3h" {
+	if got := FormatUptime(86400*2 + 3600*3 + 60*5); got != "2d 3h" {
 		t.Fatalf("expected \"2d 3h\", got %s", got)
 	}
 }
 
-func TestColorizeTempThresholds(t *testing.T) {
-	tests := []struct {
-		temp     float64
-		expected string
-	}{
-		{temp: 30.0, expected: "30.0"}, // Normal - should use okStyle (green)
-		{temp: 64.9, expected: "64.9"}, // Just below warning threshold
-		{temp: 65.0, expected: "65.0"}, // Warning threshold - should use warnStyle (yellow)
-		{temp: 78.0, expected: "78.0"}, // Mid warning range
-		{temp: 84.9, expected: "84.9"}, // Just below danger threshold
-		{temp: 85.0, expected: "85.0"}, // Danger threshold - should use dangerStyle (red)
-		{temp: 90.0, expected: "90.0"}, // High temperature
-		{temp: 0.0, expected: "0.0"},   // Edge case: zero
-	}
-
-	for _, tt := range tests {
-		result := colorizeTemp(tt.temp)
-		// Check that result contains the formatted temperature value
-		if !strings.Contains(result, tt.expected) {
-			t.Errorf("colorizeTemp(%.1f) = %q, should contain %q", tt.temp, result, tt.expected)
-		}
-		// Verify output is not empty and contains the temperature
-		if result == "" {
-			t.Errorf("colorizeTemp(%.1f) returned empty string", tt.temp)
-		}
-	}
-}
-
-func TestColorizeTempStyleRanges(t *testing.T) {
-	normalTemp := colorizeTemp(40.0)
-	warningTemp := colorizeTemp(72.0)
-	dangerTemp := colorizeTemp(90.0)
-
-	if normalTemp == "" || warningTemp == "" || dangerTemp == "" {
-		t.Fatal("colorizeTemp should not return empty strings")
-	}
-
-	if !strings.Contains(normalTemp, "40.0") {
-		t.Errorf("normal temp should contain '40.0', got: %s", normalTemp)
-	}
-	if !strings.Contains(warningTemp, "72.0") {
-		t.Errorf("warning temp should contain '72.0', got: %s", warningTemp)
-	}
-	if !strings.Contains(dangerTemp, "90.0") {
-		t.Errorf("danger temp should contain '90.0', got: %s", dangerTemp)
-	}
-}
-
 func TestCalculateHealthScoreEdgeCases(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -227,12 +179,12 @@ func TestBatteryHealthLabel(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			label, severity := batteryHealthLabel(tt.cycles, tt.capacity)
+			label, severity := BatteryHealthLabel(tt.cycles, tt.capacity)
 			if label != tt.label {
-				t.Errorf("batteryHealthLabel(%d, %d) label = %q, want %q", tt.cycles, tt.capacity, label, tt.label)
+				t.Errorf("BatteryHealthLabel(%d, %d) label = %q, want %q", tt.cycles, tt.capacity, label, tt.label)
 			}
 			if severity != tt.severity {
-				t.Errorf("batteryHealthLabel(%d, %d) severity = %q, want %q", tt.cycles, tt.capacity, severity, tt.severity)
+				t.Errorf("BatteryHealthLabel(%d, %d) severity = %q, want %q", tt.cycles, tt.capacity, severity, tt.severity)
 			}
 		})
 	}
@@ -251,9 +203,9 @@ func TestUptimeSeverity(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := uptimeSeverity(tt.secs)
+			got := UptimeSeverity(tt.secs)
 			if got != tt.want {
-				t.Errorf("uptimeSeverity(%d) = %q, want %q", tt.secs, got, tt.want)
+				t.Errorf("UptimeSeverity(%d) = %q, want %q", tt.secs, got, tt.want)
 			}
 		})
 	}
@@ -300,9 +252,9 @@ func TestFormatUptimeEdgeCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := formatUptime(tt.secs)
+			got := FormatUptime(tt.secs)
 			if got != tt.want {
-				t.Errorf("formatUptime(%d) = %q, want %q", tt.secs, got, tt.want)
+				t.Errorf("FormatUptime(%d) = %q, want %q", tt.secs, got, tt.want)
 			}
 		})
 	}
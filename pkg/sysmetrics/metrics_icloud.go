@@ -0,0 +1,74 @@
+package sysmetrics
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Combined bird/cloudd CPU at or above this counts as syncing even when
+// brctl lists nothing pending.
+const icloudSyncCPU = 10.0
+
+// icloudProcesses are the iCloud Drive daemon and CloudKit's sync daemon.
+var icloudProcesses = map[string]bool{
+	"bird":   true,
+	"cloudd": true,
+}
+
+// collectICloud reports iCloud Drive sync activity from bird and cloudd
+// CPU and the items `brctl status` shows waiting to transfer.
+func collectICloud() ICloudStatus {
+	if runtime.GOOS != "darwin" || !commandExists("ps") {
+		return ICloudStatus{}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var status ICloudStatus
+	if out, err := runCmd(ctx, "ps", "-Aeo", "pcpu=,comm="); err == nil {
+		status.CPU = parseICloudCPU(out)
+	}
+	if commandExists("brctl") {
+		if out, err := runCmd(ctx, "brctl", "status"); err == nil {
+			status.PendingUploads, status.PendingDownloads = parseBrctlStatus(out)
+		}
+	}
+	status.Syncing = status.CPU >= icloudSyncCPU || status.PendingUploads+status.PendingDownloads > 0
+	return status
+}
+
+// parseICloudCPU sums CPU over bird and cloudd in `ps -Aeo pcpu=,comm=`.
+func parseICloudCPU(out string) float64 {
+	var total float64
+	for line := range strings.Lines(out) {
+		cpuText, comm, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok || !icloudProcesses[filepath.Base(strings.TrimSpace(comm))] {
+			continue
+		}
+		if cpu, err := strconv.ParseFloat(cpuText, 64); err == nil {
+			total += cpu
+		}
+	}
+	return total
+}
+
+// parseBrctlStatus counts items brctl lists as waiting to upload or
+// download. The output format is undocumented and changes between macOS
+// releases, so this matches the transfer states rather than full lines.
+func parseBrctlStatus(out string) (int, int) {
+	var uploads, downloads int
+	for line := range strings.Lines(out) {
+		text := strings.ToLower(line)
+		switch {
+		case strings.Contains(text, "needs-upload"), strings.Contains(text, "uploading"):
+			uploads++
+		case strings.Contains(text, "needs-download"), strings.Contains(text, "downloading"):
+			downloads++
+		}
+	}
+	return uploads, downloads
+}
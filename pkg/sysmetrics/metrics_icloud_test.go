@@ -0,0 +1,25 @@
+package sysmetrics
+
+import "testing"
+
+func TestParseICloudCPU(t *testing.T) {
+	out := " 12.5 /System/Library/PrivateFrameworks/CloudDocsDaemon.framework/Versions/A/Support/bird\n" +
+		"  3.0 /System/Library/PrivateFrameworks/CloudKitDaemon.framework/Support/cloudd\n" +
+		" 50.0 /usr/sbin/birdwatcher\n"
+	if got := parseICloudCPU(out); got != 15.5 {
+		t.Fatalf("cpu = %v, want 15.5", got)
+	}
+}
+
+func TestParseBrctlStatus(t *testing.T) {
+	out := `<com.apple.CloudDocs[1] foreground {client:idle server:full-sync|fetched-recents}>
+ - o Documents/report.pdf  needs-upload
+ - o Documents/photo.heic  uploading 40%
+ - o Desktop/notes.txt  needs-download
+ - o Desktop/done.txt  synced
+`
+	up, down := parseBrctlStatus(out)
+	if up != 2 || down != 1 {
+		t.Fatalf("up=%d down=%d, want 2 and 1", up, down)
+	}
+}
@@ -0,0 +1,164 @@
+package sysmetrics
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	kubectlTimeout = 3 * time.Second
+	kubeTopPodRows = 3
+)
+
+// Context name prefixes the local distributions create. Remote clusters are
+// left alone: querying them is slow and says nothing about this machine.
+var localKubeContexts = []struct{ prefix, distro string }{
+	{"kind-", "kind"},
+	{"k3d-", "k3d"},
+	{"minikube", "minikube"},
+	{"docker-desktop", "Docker Desktop"},
+	{"orbstack", "OrbStack"},
+	{"colima", "Colima"},
+	{"rancher-desktop", "Rancher Desktop"},
+}
+
+func collectKubernetes() KubernetesStatus {
+	if !commandExists("kubectl") {
+		return KubernetesStatus{}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	out, err := runCmd(ctx, "kubectl", "config", "current-context")
+	cancel()
+	if err != nil {
+		return KubernetesStatus{}
+	}
+	status := KubernetesStatus{Context: strings.TrimSpace(out)}
+	if status.Distro = localKubeDistro(status.Context); status.Distro == "" {
+		return KubernetesStatus{}
+	}
+
+	kubectl := func(args ...string) (string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), kubectlTimeout)
+		defer cancel()
+		return runCmd(ctx, "kubectl", append([]string{"--request-timeout=2s"}, args...)...)
+	}
+	out, err = kubectl("get", "pods", "-A", "--no-headers")
+	if err != nil {
+		// Context configured but the cluster is stopped.
+		return KubernetesStatus{}
+	}
+	status.Pods = parseKubePodPhases(out)
+
+	// `kubectl top` needs metrics-server; without it only pod counts show.
+	if out, err := kubectl("top", "nodes", "--no-headers"); err == nil {
+		status.Nodes = parseKubeTopNodes(out)
+	}
+	if out, err := kubectl("top", "pods", "-A", "--no-headers", "--sort-by=cpu"); err == nil {
+		status.TopPods = parseKubeTopPods(out, kubeTopPodRows)
+	}
+	return status
+}
+
+func localKubeDistro(name string) string {
+	for _, c := range localKubeContexts {
+		if strings.HasPrefix(name, c.prefix) {
+			return c.distro
+		}
+	}
+	return ""
+}
+
+// parseKubePodPhases counts the STATUS column of `kubectl get pods -A`,
+// whose rows read "NAMESPACE NAME READY STATUS RESTARTS AGE".
+func parseKubePodPhases(out string) KubePodCounts {
+	var counts KubePodCounts
+	for line := range strings.Lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		counts.Total++
+		switch fields[3] {
+		case "Running", "Completed", "Succeeded":
+			counts.Running++
+		case "Pending", "ContainerCreating", "PodInitializing":
+			counts.Pending++
+		default:
+			// CrashLoopBackOff, Error, ImagePullBackOff, and friends.
+			counts.Failing++
+		}
+	}
+	return counts
+}
+
+// parseKubeTopNodes reads "NAME CPU(cores) CPU% MEMORY(bytes) MEMORY%".
+func parseKubeTopNodes(out string) []KubeNode {
+	var nodes []KubeNode
+	for line := range strings.Lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		nodes = append(nodes, KubeNode{
+			Name:          fields[0],
+			CPUMilli:      parseKubeCPU(fields[1]),
+			CPUPercent:    parseKubePercent(fields[2]),
+			MemoryBytes:   parseKubeMemory(fields[3]),
+			MemoryPercent: parseKubePercent(fields[4]),
+		})
+	}
+	return nodes
+}
+
+// parseKubeTopPods reads "NAMESPACE NAME CPU(cores) MEMORY(bytes)", already
+// sorted by CPU, and keeps the first limit rows.
+func parseKubeTopPods(out string, limit int) []KubePod {
+	var pods []KubePod
+	for line := range strings.Lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		pods = append(pods, KubePod{
+			Namespace:   fields[0],
+			Name:        fields[1],
+			CPUMilli:    parseKubeCPU(fields[2]),
+			MemoryBytes: parseKubeMemory(fields[3]),
+		})
+		if len(pods) == limit {
+			break
+		}
+	}
+	return pods
+}
+
+// parseKubeCPU converts "250m" or "2" (cores) to millicores.
+func parseKubeCPU(raw string) int {
+	if milli, ok := strings.CutSuffix(raw, "m"); ok {
+		n, _ := strconv.Atoi(milli)
+		return n
+	}
+	cores, _ := strconv.ParseFloat(raw, 64)
+	return int(cores * 1000)
+}
+
+func parseKubeMemory(raw string) uint64 {
+	for _, u := range []struct {
+		suffix string
+		scale  uint64
+	}{{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40}} {
+		if n, ok := strings.CutSuffix(raw, u.suffix); ok {
+			v, _ := strconv.ParseUint(n, 10, 64)
+			return v * u.scale
+		}
+	}
+	v, _ := strconv.ParseUint(raw, 10, 64)
+	return v
+}
+
+func parseKubePercent(raw string) float64 {
+	v, _ := strconv.ParseFloat(strings.TrimSuffix(raw, "%"), 64)
+	return v
+}
@@ -0,0 +1,51 @@
+package sysmetrics
+
+import "testing"
+
+func TestLocalKubeDistro(t *testing.T) {
+	tests := map[string]string{
+		"kind-dev":         "kind",
+		"minikube":         "minikube",
+		"docker-desktop":   "Docker Desktop",
+		"orbstack":         "OrbStack",
+		"prod-eks-us-east": "",
+	}
+	for name, want := range tests {
+		if got := localKubeDistro(name); got != want {
+			t.Errorf("localKubeDistro(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestParseKubePodPhases(t *testing.T) {
+	out := `kube-system   coredns-5d78c9869d-abcde          1/1   Running            0             3d
+kube-system   etcd-kind-control-plane           1/1   Running            0             3d
+default       api-7f9c6b5d4-xyz12               0/1   CrashLoopBackOff   12 (1m ago)   40m
+default       migrate-8kq2p                     0/1   Completed          0             40m
+default       worker-6d8f7b-qq1                 0/1   Pending            0             2m
+`
+	got := parseKubePodPhases(out)
+	want := KubePodCounts{Total: 5, Running: 3, Pending: 1, Failing: 1}
+	if got != want {
+		t.Fatalf("parseKubePodPhases() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseKubeTop(t *testing.T) {
+	nodes := parseKubeTopNodes("kind-control-plane   250m   3%   1024Mi   13%\n")
+	if len(nodes) != 1 || nodes[0].CPUMilli != 250 || nodes[0].CPUPercent != 3 || nodes[0].MemoryBytes != 1<<30 || nodes[0].MemoryPercent != 13 {
+		t.Fatalf("nodes = %+v", nodes)
+	}
+
+	pods := parseKubeTopPods(`default       api-7f9c6b5d4-xyz12    1         512Mi
+kube-system   etcd-kind-control-plane 24m      48Mi
+kube-system   coredns-5d78c9869d-abcde 3m      12Mi
+kube-system   kindnet-x2x9q           1m       9Mi
+`, 3)
+	if len(pods) != 3 {
+		t.Fatalf("got %d pods, want 3", len(pods))
+	}
+	if pods[0].CPUMilli != 1000 || pods[0].MemoryBytes != 512<<20 || pods[1].Namespace != "kube-system" {
+		t.Fatalf("pods = %+v", pods)
+	}
+}
@@ -0,0 +1,177 @@
+package sysmetrics
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	pingGatewayTarget = "gateway"
+	pingCount         = 5
+	pingTimeout       = 4 * time.Second
+	latencyHistory    = 20
+)
+
+var (
+	// macOS: "round-trip min/avg/max/stddev = 9.8/12.3/15.0/1.7 ms"
+	// Linux: "rtt min/avg/max/mdev = 9.8/12.3/15.0/1.7 ms"
+	pingRTTRe  = regexp.MustCompile(`= ([\d.]+)/([\d.]+)/([\d.]+)/([\d.]+) ms`)
+	pingLossRe = regexp.MustCompile(`([\d.]+)% packet loss`)
+
+	procNetRoute     = "/proc/net/route"
+	defaultGatewayFn = defaultGateway
+)
+
+// ParsePingTargets splits the --ping-targets flag. "none" or an empty value
+// turns the probe off.
+func ParsePingTargets(raw string) []string {
+	if strings.EqualFold(strings.TrimSpace(raw), "none") {
+		return nil
+	}
+	var targets []string
+	for part := range strings.SplitSeq(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			targets = append(targets, part)
+		}
+	}
+	return targets
+}
+
+// SetPingTargets sets the hosts probed on each full refresh.
+func (c *Collector) SetPingTargets(targets []string) {
+	c.pingTargets = targets
+}
+
+func (c *Collector) collectLatency() []LatencyProbe {
+	if len(c.pingTargets) == 0 || !commandExists("ping") {
+		return nil
+	}
+
+	probes := make([]LatencyProbe, len(c.pingTargets))
+	var wg sync.WaitGroup
+	for i, target := range c.pingTargets {
+		probes[i] = LatencyProbe{Target: target, Host: target}
+		if target == pingGatewayTarget {
+			probes[i].Host = defaultGatewayFn()
+			if probes[i].Host == "" {
+				continue
+			}
+		}
+		wg.Go(func() { pingHost(&probes[i]) })
+	}
+	wg.Wait()
+
+	if c.latencyHistory == nil {
+		c.latencyHistory = make(map[string]*RingBuffer)
+	}
+	out := probes[:0]
+	for _, p := range probes {
+		if p.Sent == 0 {
+			continue
+		}
+		buf, ok := c.latencyHistory[p.Target]
+		if !ok {
+			buf = NewRingBuffer(latencyHistory)
+			c.latencyHistory[p.Target] = buf
+		}
+		buf.Add(p.AvgMs)
+		p.History = buf.Slice()
+		out = append(out, p)
+	}
+	return out
+}
+
+func pingHost(p *LatencyProbe) {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	// Bound the wait without turning partial loss into a failure: macOS -t is
+	// an overall timeout, Linux -W a per-reply one (its -w deadline exits
+	// non-zero as soon as one reply is missing).
+	timeout := []string{"-W", "1"}
+	if runtime.GOOS == "darwin" {
+		timeout = []string{"-t", "3"}
+	}
+	args := append([]string{"-n", "-q", "-c", strconv.Itoa(pingCount), "-i", "0.2"}, timeout...)
+	// ping exits non-zero only when nothing came back.
+	out, err := runCmd(ctx, "ping", append(args, p.Host)...)
+	p.Sent = pingCount
+	if err != nil {
+		p.LossPercent = 100
+		return
+	}
+	parsePingSummary(out, p)
+}
+
+func parsePingSummary(out string, p *LatencyProbe) {
+	if m := pingLossRe.FindStringSubmatch(out); m != nil {
+		p.LossPercent, _ = strconv.ParseFloat(m[1], 64)
+	}
+	if m := pingRTTRe.FindStringSubmatch(out); m != nil {
+		p.MinMs, _ = strconv.ParseFloat(m[1], 64)
+		p.AvgMs, _ = strconv.ParseFloat(m[2], 64)
+		p.MaxMs, _ = strconv.ParseFloat(m[3], 64)
+		p.JitterMs, _ = strconv.ParseFloat(m[4], 64)
+	} else {
+		p.LossPercent = 100
+	}
+}
+
+func defaultGateway() string {
+	if runtime.GOOS == "linux" {
+		return linuxDefaultGateway(procNetRoute)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	out, err := runCmd(ctx, "route", "-n", "get", "default")
+	if err != nil {
+		return ""
+	}
+	return parseRouteGateway(out)
+}
+
+// parseRouteGateway reads the "gateway:" line of `route -n get default`.
+func parseRouteGateway(out string) string {
+	for line := range strings.Lines(out) {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "gateway:"); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// linuxDefaultGateway reads the 0.0.0.0 destination row of /proc/net/route,
+// where addresses are little-endian hex.
+func linuxDefaultGateway(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[1] != "00000000" {
+			continue
+		}
+		raw, err := hex.DecodeString(fields[2])
+		if err != nil || len(raw) != 4 {
+			continue
+		}
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, binary.LittleEndian.Uint32(raw))
+		return ip.String()
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package sysmetrics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParsePingSummary(t *testing.T) {
+	darwin := `--- 1.1.1.1 ping statistics ---
+5 packets transmitted, 4 packets received, 20.0% packet loss
+round-trip min/avg/max/stddev = 9.812/12.304/15.020/1.734 ms
+`
+	var p LatencyProbe
+	parsePingSummary(darwin, &p)
+	if p.LossPercent != 20 || p.AvgMs != 12.304 || p.JitterMs != 1.734 || p.MinMs != 9.812 || p.MaxMs != 15.02 {
+		t.Fatalf("darwin summary = %+v", p)
+	}
+
+	linux := `--- 192.168.1.1 ping statistics ---
+5 packets transmitted, 5 received, 0% packet loss, time 812ms
+rtt min/avg/max/mdev = 1.021/2.410/4.998/1.402 ms
+`
+	p = LatencyProbe{}
+	parsePingSummary(linux, &p)
+	if p.LossPercent != 0 || p.AvgMs != 2.41 || p.JitterMs != 1.402 {
+		t.Fatalf("linux summary = %+v", p)
+	}
+
+	p = LatencyProbe{}
+	parsePingSummary("5 packets transmitted, 0 received, 100% packet loss", &p)
+	if p.LossPercent != 100 {
+		t.Fatalf("no replies should be total loss, got %+v", p)
+	}
+}
+
+func TestParsePingTargets(t *testing.T) {
+	if got := ParsePingTargets(" gateway, 1.1.1.1 ,,example.com"); !slices.Equal(got, []string{"gateway", "1.1.1.1", "example.com"}) {
+		t.Fatalf("ParsePingTargets() = %v", got)
+	}
+	if got := ParsePingTargets("none"); got != nil {
+		t.Fatalf("none should disable probing, got %v", got)
+	}
+}
+
+func TestDefaultGatewayParsers(t *testing.T) {
+	route := `   route to: default
+destination: default
+       mask: default
+    gateway: 192.168.1.1
+  interface: en0
+`
+	if got := parseRouteGateway(route); got != "192.168.1.1" {
+		t.Fatalf("parseRouteGateway() = %q", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "route")
+	table := "Iface\tDestination\tGateway \tFlags\n" +
+		"eth0\t0001A8C0\t00000000\t0001\n" +
+		"eth0\t00000000\t0101A8C0\t0003\n"
+	if err := os.WriteFile(path, []byte(table), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := linuxDefaultGateway(path); got != "192.168.1.1" {
+		t.Fatalf("linuxDefaultGateway() = %q", got)
+	}
+}
+
+func TestCollectLatencyKeepsHistoryPerTarget(t *testing.T) {
+	origRunCmd := runCmd
+	origCommandExists := commandExists
+	origGateway := defaultGatewayFn
+	t.Cleanup(func() {
+		runCmd = origRunCmd
+		commandExists = origCommandExists
+		defaultGatewayFn = origGateway
+	})
+
+	commandExists = func(name string) bool { return name == "ping" }
+	defaultGatewayFn = func() string { return "" }
+	runCmd = func(ctx context.Context, name string, args ...string) (string, error) {
+		host := args[len(args)-1]
+		if host != "1.1.1.1" {
+			t.Errorf("unexpected ping host %q", host)
+		}
+		return "0.0% packet loss\nround-trip min/avg/max/stddev = 10/12/14/1.5 ms\n", nil
+	}
+
+	c := NewCollector(ProcessWatchOptions{})
+	c.SetPingTargets([]string{"gateway", "1.1.1.1"})
+	c.collectLatency()
+	probes := c.collectLatency()
+	if len(probes) != 1 || probes[0].Target != "1.1.1.1" || probes[0].AvgMs != 12 {
+		t.Fatalf("collectLatency() = %+v (gateway without a route should be skipped)", probes)
+	}
+	if len(probes[0].History) != 2 {
+		t.Fatalf("history = %v, want 2 samples", probes[0].History)
+	}
+	if !strings.HasPrefix(probes[0].Host, "1.1.1.1") {
+		t.Fatalf("host = %q", probes[0].Host)
+	}
+}
@@ -0,0 +1,149 @@
+package sysmetrics
+
+import (
+	"cmp"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	launchItemsTTL = 5 * time.Minute
+	// Items whose plist appeared within this window are flagged as new.
+	launchItemRecentWindow = 7 * 24 * time.Hour
+)
+
+// launchItemDirs are the third-party launchd locations; /System is Apple's
+// own and sealed. SMAppService login items live in a root-only database
+// (sfltool dumpbtm) and are not listed.
+var launchItemDirs = func() []launchItemDir {
+	dirs := []launchItemDir{
+		{path: "/Library/LaunchAgents", scope: "agent"},
+		{path: "/Library/LaunchDaemons", scope: "daemon"},
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append([]launchItemDir{{path: filepath.Join(home, "Library", "LaunchAgents"), scope: "user agent"}}, dirs...)
+	}
+	return dirs
+}
+
+type launchItemDir struct {
+	path  string
+	scope string
+}
+
+func (c *Collector) collectLaunchItems(now time.Time) []LaunchItem {
+	if runtime.GOOS != "darwin" {
+		return nil
+	}
+	if !c.lastLaunchItemsAt.IsZero() && now.Sub(c.lastLaunchItemsAt) < launchItemsTTL {
+		return c.cachedLaunchItems
+	}
+
+	var items []LaunchItem
+	for _, dir := range launchItemDirs() {
+		items = append(items, readLaunchItems(dir, now)...)
+	}
+	if len(items) > 0 && commandExists("ps") {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		// Without -c, macOS ps prints the full executable path.
+		if out, err := runCmd(ctx, "ps", "-Aeo", "pid=,comm="); err == nil {
+			markRunningLaunchItems(items, out)
+		}
+		cancel()
+	}
+	slices.SortStableFunc(items, func(a, b LaunchItem) int {
+		return b.AddedAt.Compare(a.AddedAt)
+	})
+
+	c.cachedLaunchItems = items
+	c.lastLaunchItemsAt = now
+	return items
+}
+
+func readLaunchItems(dir launchItemDir, now time.Time) []LaunchItem {
+	entries, err := os.ReadDir(dir.path)
+	if err != nil {
+		return nil
+	}
+	var items []LaunchItem
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".plist") {
+			continue
+		}
+		path := filepath.Join(dir.path, entry.Name())
+		item := LaunchItem{
+			Label: strings.TrimSuffix(entry.Name(), ".plist"),
+			Scope: dir.scope,
+			Plist: path,
+		}
+		if info, err := entry.Info(); err == nil {
+			item.AddedAt = info.ModTime()
+			item.Recent = now.Sub(item.AddedAt) < launchItemRecentWindow
+		}
+		if label, program, ok := readLaunchPlist(path); ok {
+			item.Label = cmp.Or(label, item.Label)
+			item.Program = program
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// readLaunchPlist returns a job's Label and the executable it runs, from
+// Program or the first ProgramArguments entry. Binary plists go through
+// plutil.
+func readLaunchPlist(path string) (string, string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", false
+	}
+	raw := string(data)
+	if strings.HasPrefix(raw, "bplist") {
+		if !commandExists("plutil") {
+			return "", "", false
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		raw, err = runCmd(ctx, "plutil", "-convert", "xml1", "-o", "-", path)
+		cancel()
+		if err != nil {
+			return "", "", false
+		}
+	}
+	root, err := decodePlist(raw)
+	if err != nil {
+		return "", "", false
+	}
+	dict, _ := root.(map[string]any)
+	program := plistString(dict, "Program")
+	if args, _ := dict["ProgramArguments"].([]any); program == "" && len(args) > 0 {
+		program, _ = args[0].(string)
+	}
+	return plistString(dict, "Label"), program, true
+}
+
+// markRunningLaunchItems matches each job's executable against
+// `ps -Aeo pid=,comm=`.
+func markRunningLaunchItems(items []LaunchItem, out string) {
+	pids := make(map[string]int)
+	for line := range strings.Lines(out) {
+		pidText, path, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		if pid, err := strconv.Atoi(pidText); err == nil {
+			pids[strings.TrimSpace(path)] = pid
+		}
+	}
+	for i := range items {
+		if pid, ok := pids[items[i].Program]; ok && items[i].Program != "" {
+			items[i].Running = true
+			items[i].PID = pid
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package sysmetrics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadLaunchItems(t *testing.T) {
+	dir := t.TempDir()
+	plist := `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>com.acme.updater</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>/Library/Acme/updater</string>
+		<string>--background</string>
+	</array>
+</dict>
+</plist>`
+	if err := os.WriteFile(filepath.Join(dir, "com.acme.updater.plist"), []byte(plist), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	oldPath := filepath.Join(dir, "org.example.old.plist")
+	if err := os.WriteFile(oldPath, []byte("not a plist"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(oldPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	items := readLaunchItems(launchItemDir{path: dir, scope: "agent"}, time.Now())
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2: %+v", len(items), items)
+	}
+	acme, stale := items[0], items[1]
+	if acme.Label != "com.acme.updater" || acme.Program != "/Library/Acme/updater" || acme.Scope != "agent" || !acme.Recent {
+		t.Fatalf("acme = %+v", acme)
+	}
+	if stale.Label != "org.example.old" || stale.Program != "" || stale.Recent {
+		t.Fatalf("stale = %+v", stale)
+	}
+
+	markRunningLaunchItems(items, "  1 /sbin/launchd\n812 /Library/Acme/updater\n")
+	if !items[0].Running || items[0].PID != 812 || items[1].Running {
+		t.Fatalf("running = %+v", items)
+	}
+}
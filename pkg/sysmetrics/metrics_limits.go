@@ -0,0 +1,208 @@
+package sysmetrics
+
+import (
+	"cmp"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Counting every process's descriptors walks the whole file table, so
+	// the per-process list is refreshed less often than the totals.
+	fdHoldersTTL   = 2 * time.Minute
+	fdHoldersLimit = 3
+	// The Limits card appears once any count reaches this share of its cap.
+	LimitsShowRatio = 0.7
+)
+
+var procRoot = "/proc"
+
+func (c *Collector) collectLimits(now time.Time) LimitsStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var status LimitsStatus
+	switch runtime.GOOS {
+	case "darwin":
+		if commandExists("sysctl") {
+			if out, err := runCmd(ctx, "sysctl", "-n", "kern.num_files", "kern.maxfiles", "kern.maxfilesperproc", "kern.maxproc"); err == nil {
+				applyLimitsSysctl(&status, out)
+			}
+		}
+		if commandExists("ps") {
+			if out, err := runCmd(ctx, "ps", "-AM"); err == nil {
+				status.Procs, status.Threads = countPsThreads(out)
+			}
+		}
+	case "linux":
+		readLinuxLimits(&status, procRoot)
+	default:
+		return LimitsStatus{}
+	}
+
+	if c.lastFDHoldersAt.IsZero() || now.Sub(c.lastFDHoldersAt) >= fdHoldersTTL {
+		c.cachedFDHolders = collectFDHolders(ctx)
+		c.lastFDHoldersAt = now
+	}
+	status.TopFDs = c.cachedFDHolders
+	return status
+}
+
+// applyLimitsSysctl reads `sysctl -n kern.num_files kern.maxfiles
+// kern.maxfilesperproc kern.maxproc`, one value per line.
+func applyLimitsSysctl(status *LimitsStatus, out string) {
+	var values []int
+	for line := range strings.Lines(out) {
+		n, err := strconv.Atoi(strings.TrimSpace(line))
+		if err != nil {
+			return
+		}
+		values = append(values, n)
+	}
+	if len(values) != 4 {
+		return
+	}
+	status.OpenFiles, status.MaxFiles, status.MaxFilesPerProc, status.MaxProcs = values[0], values[1], values[2], values[3]
+}
+
+// countPsThreads counts processes and threads in `ps -AM`, which prints a
+// row per thread and leaves USER blank on all but each process's first.
+func countPsThreads(out string) (int, int) {
+	var procs, threads int
+	for line := range strings.Lines(out) {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "USER") {
+			continue
+		}
+		threads++
+		if line[0] != ' ' {
+			procs++
+		}
+	}
+	return procs, threads
+}
+
+// readLinuxLimits reads the kernel's file and task tables:
+// fs/file-nr is "allocated unused max", and the fourth field of loadavg is
+// "running/total" scheduling entities, which counts every thread.
+func readLinuxLimits(status *LimitsStatus, root string) {
+	readInt := func(path string) int {
+		n, _ := strconv.Atoi(readSysfsString(filepath.Join(root, path)))
+		return n
+	}
+	if fields := strings.Fields(readSysfsString(filepath.Join(root, "sys/fs/file-nr"))); len(fields) == 3 {
+		status.OpenFiles, _ = strconv.Atoi(fields[0])
+		status.MaxFiles, _ = strconv.Atoi(fields[2])
+	}
+	status.MaxFilesPerProc = readInt("sys/fs/nr_open")
+	status.MaxProcs = readInt("sys/kernel/pid_max")
+	status.MaxThreads = readInt("sys/kernel/threads-max")
+	if fields := strings.Fields(readSysfsString(filepath.Join(root, "loadavg"))); len(fields) >= 4 {
+		if _, total, ok := strings.Cut(fields[3], "/"); ok {
+			status.Threads, _ = strconv.Atoi(total)
+		}
+	}
+	if entries, err := os.ReadDir(root); err == nil {
+		for _, entry := range entries {
+			if _, err := strconv.Atoi(entry.Name()); err == nil && entry.IsDir() {
+				status.Procs++
+			}
+		}
+	}
+}
+
+// collectFDHolders finds the processes holding the most descriptors. Only
+// processes this user can inspect are counted.
+func collectFDHolders(ctx context.Context) []ProcessFDs {
+	var holders []ProcessFDs
+	switch runtime.GOOS {
+	case "darwin":
+		if !commandExists("lsof") {
+			return nil
+		}
+		// lsof exits 1 when some processes could not be read; keep what it printed.
+		out, _ := runCmd(ctx, "lsof", "-nP", "-F", "pcf")
+		holders = parseLsofFDs(out)
+	case "linux":
+		holders = readProcFDs(procRoot)
+	}
+	slices.SortFunc(holders, func(a, b ProcessFDs) int {
+		return cmp.Or(cmp.Compare(b.FDs, a.FDs), cmp.Compare(a.PID, b.PID))
+	})
+	return holders[:min(len(holders), fdHoldersLimit)]
+}
+
+// parseLsofFDs counts numbered descriptors per process in `lsof -F pcf`
+// output, where each process starts with p<pid> and c<command> and each
+// file has an f line. cwd, txt, and mapped files are not descriptors.
+func parseLsofFDs(out string) []ProcessFDs {
+	var holders []ProcessFDs
+	for line := range strings.Lines(out) {
+		line = strings.TrimRight(line, "\n")
+		if len(line) < 2 {
+			continue
+		}
+		value := line[1:]
+		switch line[0] {
+		case 'p':
+			pid, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+			holders = append(holders, ProcessFDs{PID: pid})
+		case 'c':
+			if len(holders) > 0 {
+				holders[len(holders)-1].Name = value
+			}
+		case 'f':
+			if _, err := strconv.Atoi(value); err == nil && len(holders) > 0 {
+				holders[len(holders)-1].FDs++
+			}
+		}
+	}
+	return holders
+}
+
+func readProcFDs(root string) []ProcessFDs {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var holders []ProcessFDs
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		fds, err := os.ReadDir(filepath.Join(root, entry.Name(), "fd"))
+		if err != nil {
+			continue
+		}
+		holders = append(holders, ProcessFDs{
+			PID:  pid,
+			Name: readSysfsString(filepath.Join(root, entry.Name(), "comm")),
+			FDs:  len(fds),
+		})
+	}
+	return holders
+}
+
+// Pressure is the highest share of any limit in use, from 0 to 1.
+func (l LimitsStatus) Pressure() float64 {
+	ratio := func(used, limit int) float64 {
+		if limit <= 0 {
+			return 0
+		}
+		return float64(used) / float64(limit)
+	}
+	pressure := max(ratio(l.OpenFiles, l.MaxFiles), ratio(l.Procs, l.MaxProcs), ratio(l.Threads, l.MaxThreads))
+	if len(l.TopFDs) > 0 {
+		pressure = max(pressure, ratio(l.TopFDs[0].FDs, l.MaxFilesPerProc))
+	}
+	return pressure
+}
@@ -0,0 +1,86 @@
+package sysmetrics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyLimitsSysctl(t *testing.T) {
+	var status LimitsStatus
+	applyLimitsSysctl(&status, "11520\n122880\n61440\n8000\n")
+	if status.OpenFiles != 11520 || status.MaxFiles != 122880 || status.MaxFilesPerProc != 61440 || status.MaxProcs != 8000 {
+		t.Fatalf("status = %+v", status)
+	}
+}
+
+func TestCountPsThreads(t *testing.T) {
+	out := `USER     PID   TT   %CPU STAT PRI     STIME     UTIME COMMAND
+root       1   ??    0.0 S    31T   0:05.95   0:13.61 /sbin/launchd
+           1         0.0 S    20T   0:00.04   0:00.01
+tw93     812   ??    2.1 S    46T   0:01.00   0:02.00 /Applications/Safari.app/Contents/MacOS/Safari
+           812       0.0 S    46T   0:00.00   0:00.00
+           812       0.0 S    46T   0:00.00   0:00.00
+`
+	procs, threads := countPsThreads(out)
+	if procs != 2 || threads != 5 {
+		t.Fatalf("procs=%d threads=%d", procs, threads)
+	}
+}
+
+func TestReadLinuxLimits(t *testing.T) {
+	root := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("sys/fs/file-nr", "9824\t0\t9223372036854775807\n")
+	write("sys/fs/nr_open", "1048576\n")
+	write("sys/kernel/pid_max", "4194304\n")
+	write("sys/kernel/threads-max", "126483\n")
+	write("loadavg", "0.52 0.58 0.59 3/1204 40321\n")
+	write("1/comm", "systemd\n")
+	write("1/fd/0", "")
+	write("1/fd/1", "")
+	write("4242/comm", "node\n")
+	for _, fd := range []string{"0", "1", "2", "3", "4"} {
+		write("4242/fd/"+fd, "")
+	}
+
+	var status LimitsStatus
+	readLinuxLimits(&status, root)
+	if status.OpenFiles != 9824 || status.MaxFilesPerProc != 1048576 || status.MaxProcs != 4194304 ||
+		status.MaxThreads != 126483 || status.Threads != 1204 || status.Procs != 2 {
+		t.Fatalf("status = %+v", status)
+	}
+	holders := readProcFDs(root)
+	if len(holders) != 2 {
+		t.Fatalf("holders = %+v", holders)
+	}
+	for _, h := range holders {
+		if h.PID == 4242 && (h.Name != "node" || h.FDs != 5) {
+			t.Fatalf("node = %+v", h)
+		}
+	}
+}
+
+func TestParseLsofFDs(t *testing.T) {
+	out := "p812\ncSafari\nfcwd\nftxt\nf0\nf1\nf2\np901\ncnode\nf0\nf12\n"
+	got := parseLsofFDs(out)
+	if len(got) != 2 || got[0] != (ProcessFDs{PID: 812, Name: "Safari", FDs: 3}) || got[1] != (ProcessFDs{PID: 901, Name: "node", FDs: 2}) {
+		t.Fatalf("holders = %+v", got)
+	}
+}
+
+func TestLimitsPressure(t *testing.T) {
+	l := LimitsStatus{OpenFiles: 100, MaxFiles: 1000, Procs: 10, MaxProcs: 100, TopFDs: []ProcessFDs{{FDs: 240}}, MaxFilesPerProc: 256}
+	if got := l.Pressure(); got < 0.93 || got > 0.94 {
+		t.Fatalf("pressure = %v, want the per-process share", got)
+	}
+}
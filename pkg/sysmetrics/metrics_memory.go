@@ -0,0 +1,193 @@
+package sysmetrics
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/mem"
+)
+
+// vmCounters are cumulative since boot; rates come from the delta between
+// two samples.
+type vmCounters struct {
+	swapIn  uint64 // bytes
+	swapOut uint64 // bytes
+	faults  uint64
+	ok      bool
+}
+
+// vmStat is the subset of macOS vm_stat output the memory card uses.
+type vmStat struct {
+	fileBacked uint64
+	compressed uint64
+	counters   vmCounters
+}
+
+func (c *Collector) collectMemory(now time.Time) (MemoryStatus, error) {
+	return c.collectMemoryWithOptions(now, true)
+}
+
+func (c *Collector) collectMemoryFast(now time.Time) (MemoryStatus, error) {
+	return c.collectMemoryWithOptions(now, false)
+}
+
+func (c *Collector) collectMemoryWithOptions(now time.Time, includeSlowAnnotations bool) (MemoryStatus, error) {
+	status, counters, err := collectMemoryWithOptions(includeSlowAnnotations)
+	if err != nil {
+		return status, err
+	}
+	c.applyMemoryRates(now, counters, &status)
+	return status, nil
+}
+
+// applyMemoryRates turns swap and fault counters into per-second rates.
+// Darwin only has counters on the full path, so the fast path leaves the
+// previous sample in place for the next full refresh to diff against.
+func (c *Collector) applyMemoryRates(now time.Time, counters vmCounters, status *MemoryStatus) {
+	if !counters.ok {
+		return
+	}
+	c.memMu.Lock()
+	defer c.memMu.Unlock()
+	prev, prevAt := c.prevVM, c.prevVMAt
+	c.prevVM, c.prevVMAt = counters, now
+	elapsed := now.Sub(prevAt).Seconds()
+	if !prev.ok || elapsed <= 0 {
+		return
+	}
+	rate := func(cur, before uint64) float64 {
+		if cur < before {
+			return 0
+		}
+		return float64(cur-before) / elapsed
+	}
+	status.SwapInRate = rate(counters.swapIn, prev.swapIn) / 1024 / 1024
+	status.SwapOutRate = rate(counters.swapOut, prev.swapOut) / 1024 / 1024
+	status.PageFaultRate = rate(counters.faults, prev.faults)
+}
+
+func collectMemoryWithOptions(includeSlowAnnotations bool) (MemoryStatus, vmCounters, error) {
+	vm, err := mem.VirtualMemory()
+	if err != nil {
+		return MemoryStatus{}, vmCounters{}, err
+	}
+
+	swap, _ := mem.SwapMemory()
+	if swap == nil {
+		swap = &mem.SwapMemoryStat{}
+	}
+	var pressure string
+	if includeSlowAnnotations {
+		pressure = getMemoryPressure()
+	}
+
+	// Linux exposes swap and fault counters through /proc/vmstat for free;
+	// macOS needs vm_stat, which only runs on the full path.
+	counters := vmCounters{swapIn: swap.Sin, swapOut: swap.Sout, faults: swap.PgFault, ok: runtime.GOOS == "linux"}
+	cached := vm.Cached
+	var compressed uint64
+	if includeSlowAnnotations && runtime.GOOS == "darwin" {
+		if stat, ok := getVMStat(); ok {
+			// On macOS, vm.Cached is 0, so we calculate from file-backed pages.
+			if cached == 0 {
+				cached = stat.fileBacked
+			}
+			compressed = stat.compressed
+			counters = stat.counters
+		}
+	}
+
+	return MemoryStatus{
+		Used:        vm.Used,
+		Total:       vm.Total,
+		Available:   vm.Available,
+		UsedPercent: vm.UsedPercent,
+		SwapUsed:    swap.Used,
+		SwapTotal:   swap.Total,
+		Cached:      cached,
+		Compressed:  compressed,
+		Pressure:    pressure,
+	}, counters, nil
+}
+
+func getVMStat() (vmStat, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	out, err := runCmd(ctx, "vm_stat")
+	if err != nil {
+		return vmStat{}, false
+	}
+	return parseVMStat(out), true
+}
+
+func parseVMStat(out string) vmStat {
+	// Parse page size from first line: "Mach Virtual Memory Statistics: (page size of 16384 bytes)"
+	var pageSize uint64 = 4096 // Default
+	var stat vmStat
+	firstLine := true
+	for line := range strings.Lines(out) {
+		if firstLine {
+			firstLine = false
+			if strings.Contains(line, "page size of") {
+				if _, after, found := strings.Cut(line, "page size of "); found {
+					if before, _, found := strings.Cut(after, " bytes"); found {
+						if size, err := strconv.ParseUint(strings.TrimSpace(before), 10, 64); err == nil {
+							pageSize = size
+						}
+					}
+				}
+			}
+			continue
+		}
+
+		// Lines look like "File-backed pages:   388975."
+		key, after, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(after), "."), 10, 64)
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "File-backed pages":
+			stat.fileBacked = value * pageSize
+		case "Pages occupied by compressor":
+			stat.compressed = value * pageSize
+		case "Swapins":
+			stat.counters.swapIn = value * pageSize
+			stat.counters.ok = true
+		case "Swapouts":
+			stat.counters.swapOut = value * pageSize
+		case "Translation faults":
+			stat.counters.faults = value
+		}
+	}
+	return stat
+}
+
+func getMemoryPressure() string {
+	if runtime.GOOS != "darwin" {
+		return nativeMemoryPressure()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	out, err := runCmd(ctx, "memory_pressure")
+	if err != nil {
+		return ""
+	}
+	lower := strings.ToLower(out)
+	if strings.Contains(lower, "critical") {
+		return "critical"
+	}
+	if strings.Contains(lower, "warn") {
+		return "warn"
+	}
+	if strings.Contains(lower, "normal") {
+		return "normal"
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package sysmetrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseVMStat(t *testing.T) {
+	out := `Mach Virtual Memory Statistics: (page size of 16384 bytes)
+Pages free:                               12345.
+File-backed pages:                       100000.
+Pages occupied by compressor:             65536.
+Translation faults:                   987654321.
+Swapins:                                     10.
+Swapouts:                                    20.
+`
+	stat := parseVMStat(out)
+	if stat.fileBacked != 100000*16384 {
+		t.Fatalf("fileBacked = %d", stat.fileBacked)
+	}
+	if stat.compressed != 65536*16384 {
+		t.Fatalf("compressed = %d, want 1 GiB", stat.compressed)
+	}
+	want := vmCounters{swapIn: 10 * 16384, swapOut: 20 * 16384, faults: 987654321, ok: true}
+	if stat.counters != want {
+		t.Fatalf("counters = %+v, want %+v", stat.counters, want)
+	}
+}
+
+func TestApplyMemoryRatesUsesCounterDeltas(t *testing.T) {
+	c := &Collector{}
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	var first MemoryStatus
+	c.applyMemoryRates(start, vmCounters{swapIn: 0, swapOut: 0, faults: 1000, ok: true}, &first)
+	if first.PageFaultRate != 0 {
+		t.Fatalf("first sample should only prime counters, got %+v", first)
+	}
+
+	// Fast darwin ticks carry no counters and must not reset the baseline.
+	var skipped MemoryStatus
+	c.applyMemoryRates(start.Add(time.Second), vmCounters{}, &skipped)
+
+	var next MemoryStatus
+	c.applyMemoryRates(start.Add(2*time.Second), vmCounters{swapIn: 2 << 20, swapOut: 4 << 20, faults: 5000, ok: true}, &next)
+	if next.SwapInRate != 1 || next.SwapOutRate != 2 || next.PageFaultRate != 2000 {
+		t.Fatalf("rates = %+v, want 1 MB/s in, 2 MB/s out, 2000 faults/s", next)
+	}
+}
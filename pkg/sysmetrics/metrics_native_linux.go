@@ -0,0 +1,220 @@
+//go:build linux
+
+package sysmetrics
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/load"
+)
+
+// Linux publishes everything the core cards need under /proc and /sys, so
+// these readers stand in for the uptime and ps fallbacks and fill the
+// thermal and memory pressure fields macOS gets from its own tools. gopsutil
+// already reads disks and network from /proc/mounts, /proc/diskstats and
+// /proc/net/dev without spawning anything.
+var (
+	sysThermalRoot = "/sys/class/thermal"
+	sysHwmonRoot   = "/sys/class/hwmon"
+)
+
+// Thermal zone types that track the CPU package, best first. acpitz is a
+// board sensor near the CPU on most laptops and only used when nothing
+// better exists.
+var cpuThermalZones = []string{"x86_pkg_temp", "cpu-thermal", "cpu_thermal", "soc_thermal", "acpitz"}
+
+func readSysInt(path string) (int64, bool) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(string(raw)), 10, 64)
+	return v, err == nil
+}
+
+// nativeLoadAvg reads /proc/loadavg: "0.52 0.58 0.59 2/1190 123456".
+func nativeLoadAvg() (load.AvgStat, bool) {
+	raw, err := os.ReadFile(filepath.Join(procRoot, "loadavg"))
+	if err != nil {
+		return load.AvgStat{}, false
+	}
+	fields := strings.Fields(string(raw))
+	if len(fields) < 3 {
+		return load.AvgStat{}, false
+	}
+	var values [3]float64
+	for i := range values {
+		if values[i], err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return load.AvgStat{}, false
+		}
+	}
+	return load.AvgStat{Load1: values[0], Load5: values[1], Load15: values[2]}, true
+}
+
+// procCPUTimes is one cpu line of /proc/stat in USER_HZ ticks.
+type procCPUTimes struct {
+	busy, total uint64
+}
+
+// readProcStat returns the aggregate "cpu" line and the per-core lines.
+func readProcStat() (procCPUTimes, []procCPUTimes, error) {
+	raw, err := os.ReadFile(filepath.Join(procRoot, "stat"))
+	if err != nil {
+		return procCPUTimes{}, nil, err
+	}
+	var all procCPUTimes
+	var cores []procCPUTimes
+	found := false
+	for line := range strings.Lines(string(raw)) {
+		fields := strings.Fields(line)
+		if len(fields) < 5 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+		var times procCPUTimes
+		// user nice system idle iowait irq softirq steal guest guest_nice;
+		// guest time is already counted in user, so stop before it.
+		for i, field := range fields[1:min(len(fields), 9)] {
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				break
+			}
+			times.total += v
+			if i != 3 && i != 4 { // idle, iowait
+				times.busy += v
+			}
+		}
+		if fields[0] == "cpu" {
+			all, found = times, true
+		} else {
+			cores = append(cores, times)
+		}
+	}
+	if !found {
+		return procCPUTimes{}, nil, errors.New("no cpu line in /proc/stat")
+	}
+	return all, cores, nil
+}
+
+func cpuTimesPercent(cur, before procCPUTimes) float64 {
+	if cur.total <= before.total || cur.busy < before.busy {
+		return 0
+	}
+	return float64(cur.busy-before.busy) / float64(cur.total-before.total) * 100
+}
+
+// nativeCPUUtilization samples /proc/stat twice, interval apart.
+func nativeCPUUtilization(interval time.Duration) (float64, []float64, error) {
+	all1, cores1, err := readProcStat()
+	if err != nil {
+		return 0, nil, err
+	}
+	time.Sleep(interval)
+	all2, cores2, err := readProcStat()
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(cores1) != len(cores2) {
+		return 0, nil, errors.New("cpu count changed while sampling")
+	}
+	perCore := make([]float64, len(cores2))
+	for i := range cores2 {
+		perCore[i] = cpuTimesPercent(cores2[i], cores1[i])
+	}
+	return cpuTimesPercent(all2, all1), perCore, nil
+}
+
+// nativeMemoryPressure maps pressure stall information to the macOS
+// memory_pressure levels. "full" means every task stalled on memory at once,
+// so a little of it is already critical.
+//
+//	some avg10=1.53 avg60=0.87 avg300=0.22 total=1234
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=567
+func nativeMemoryPressure() string {
+	raw, err := os.ReadFile(filepath.Join(procRoot, "pressure", "memory"))
+	if err != nil {
+		return ""
+	}
+	avg10 := map[string]float64{}
+	for line := range strings.Lines(string(raw)) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if v, ok := strings.CutPrefix(fields[1], "avg10="); ok {
+			avg10[fields[0]], _ = strconv.ParseFloat(v, 64)
+		}
+	}
+	switch {
+	case avg10["full"] >= 5 || avg10["some"] >= 40:
+		return "critical"
+	case avg10["some"] >= 10:
+		return "warn"
+	default:
+		return "normal"
+	}
+}
+
+// nativeThermal reads the CPU temperature from thermal zones, fans from
+// hwmon, and battery temperature and draw from the power supply class.
+func nativeThermal() ThermalStatus {
+	var thermal ThermalStatus
+
+	zones, _ := filepath.Glob(filepath.Join(sysThermalRoot, "thermal_zone*"))
+	best := len(cpuThermalZones)
+	for _, zone := range zones {
+		raw, err := os.ReadFile(filepath.Join(zone, "type"))
+		if err != nil {
+			continue
+		}
+		rank := slices.Index(cpuThermalZones, strings.TrimSpace(string(raw)))
+		if rank < 0 || rank >= best {
+			continue
+		}
+		if milli, ok := readSysInt(filepath.Join(zone, "temp")); ok && milli > 0 {
+			thermal.CPUTemp = float64(milli) / 1000
+			best = rank
+		}
+	}
+
+	fans, _ := filepath.Glob(filepath.Join(sysHwmonRoot, "hwmon*", "fan*_input"))
+	for _, fan := range fans {
+		if rpm, ok := readSysInt(fan); ok {
+			thermal.FanCount++
+			thermal.FanSpeed = max(thermal.FanSpeed, int(rpm))
+		}
+	}
+
+	batteries, _ := filepath.Glob(filepath.Join(powerSupplyRoot, "BAT*"))
+	for _, dir := range batteries {
+		if tenths, ok := readSysInt(filepath.Join(dir, "temp")); ok && tenths > 0 {
+			thermal.BatteryTemp = float64(tenths) / 10
+		}
+		watts := 0.0
+		if micro, ok := readSysInt(filepath.Join(dir, "power_now")); ok {
+			watts = float64(micro) / 1e6
+		} else {
+			current, _ := readSysInt(filepath.Join(dir, "current_now"))
+			voltage, _ := readSysInt(filepath.Join(dir, "voltage_now"))
+			watts = float64(current) * float64(voltage) / 1e12
+		}
+		// Some drivers sign the reading by direction; status says which way.
+		watts = math.Abs(watts)
+		raw, _ := os.ReadFile(filepath.Join(dir, "status"))
+		switch strings.TrimSpace(string(raw)) {
+		case "Discharging":
+			// On battery the pack feeds the whole system.
+			thermal.BatteryPower += watts
+			thermal.SystemPower += watts
+		case "Charging":
+			thermal.BatteryPower -= watts
+		}
+	}
+	return thermal
+}
@@ -0,0 +1,96 @@
+package sysmetrics
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeSysFiles creates each relative path under root with its contents.
+func writeSysFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNativeProcReaders(t *testing.T) {
+	root := t.TempDir()
+	oldRoot := procRoot
+	procRoot = root
+	defer func() { procRoot = oldRoot }()
+
+	writeSysFiles(t, root, map[string]string{
+		"loadavg": "0.52 0.58 0.59 2/1190 123456\n",
+		"stat": "cpu  300 0 100 500 100 0 0 0 50 0\n" +
+			"cpu0 200 0 50 200 50 0 0 0 50 0\n" +
+			"cpu1 100 0 50 300 50 0 0 0 0 0\n" +
+			"intr 12345\n",
+		"pressure/memory": "some avg10=12.50 avg60=3.00 avg300=1.00 total=1234\n" +
+			"full avg10=0.40 avg60=0.10 avg300=0.00 total=56\n",
+	})
+
+	avg, ok := nativeLoadAvg()
+	if !ok || avg.Load1 != 0.52 || avg.Load15 != 0.59 {
+		t.Fatalf("nativeLoadAvg() = %+v, %v", avg, ok)
+	}
+
+	all, cores, err := readProcStat()
+	if err != nil || len(cores) != 2 {
+		t.Fatalf("readProcStat() = %+v, %+v, %v", all, cores, err)
+	}
+	// Guest ticks are already inside user and must not be counted twice.
+	if all.total != 1000 || all.busy != 400 {
+		t.Fatalf("aggregate times = %+v, want 400 busy of 1000", all)
+	}
+	if got := cpuTimesPercent(procCPUTimes{busy: 500, total: 1200}, all); got != 50 {
+		t.Fatalf("cpuTimesPercent() = %v, want 50", got)
+	}
+
+	if got := nativeMemoryPressure(); got != "warn" {
+		t.Fatalf("nativeMemoryPressure() = %q, want warn", got)
+	}
+}
+
+func TestNativeThermalReadsZonesFansAndBattery(t *testing.T) {
+	oldThermal, oldHwmon, oldSupply := sysThermalRoot, sysHwmonRoot, powerSupplyRoot
+	defer func() { sysThermalRoot, sysHwmonRoot, powerSupplyRoot = oldThermal, oldHwmon, oldSupply }()
+	sysThermalRoot, sysHwmonRoot, powerSupplyRoot = t.TempDir(), t.TempDir(), t.TempDir()
+
+	writeSysFiles(t, sysThermalRoot, map[string]string{
+		"thermal_zone0/type": "acpitz\n",
+		"thermal_zone0/temp": "41000\n",
+		"thermal_zone1/type": "x86_pkg_temp\n",
+		"thermal_zone1/temp": "63500\n",
+		"thermal_zone2/type": "iwlwifi_1\n",
+		"thermal_zone2/temp": "90000\n",
+	})
+	writeSysFiles(t, sysHwmonRoot, map[string]string{
+		"hwmon3/fan1_input": "2100\n",
+		"hwmon3/fan2_input": "3400\n",
+	})
+	writeSysFiles(t, powerSupplyRoot, map[string]string{
+		"BAT0/status":      "Discharging\n",
+		"BAT0/temp":        "312\n",
+		"BAT0/current_now": "-1500000\n",
+		"BAT0/voltage_now": "12000000\n",
+	})
+
+	thermal := nativeThermal()
+	if thermal.CPUTemp != 63.5 {
+		t.Fatalf("CPUTemp = %v, want the package zone over acpitz", thermal.CPUTemp)
+	}
+	if thermal.FanCount != 2 || thermal.FanSpeed != 3400 {
+		t.Fatalf("fans = %d at %d rpm", thermal.FanCount, thermal.FanSpeed)
+	}
+	if math.Abs(thermal.BatteryTemp-31.2) > 1e-9 || thermal.BatteryPower != 18 || thermal.SystemPower != 18 {
+		t.Fatalf("battery thermal = %+v", thermal)
+	}
+}
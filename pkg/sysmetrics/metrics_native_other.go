@@ -0,0 +1,26 @@
+//go:build !linux
+
+package sysmetrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/load"
+)
+
+func nativeLoadAvg() (load.AvgStat, bool) {
+	return load.AvgStat{}, false
+}
+
+func nativeCPUUtilization(time.Duration) (float64, []float64, error) {
+	return 0, nil, errors.New("/proc/stat unavailable")
+}
+
+func nativeMemoryPressure() string {
+	return ""
+}
+
+func nativeThermal() ThermalStatus {
+	return ThermalStatus{}
+}
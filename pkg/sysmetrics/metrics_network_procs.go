@@ -0,0 +1,99 @@
+package sysmetrics
+
+import (
+	"cmp"
+	"context"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// nettop's delta mode needs two samples; the second covers this interval.
+	nettopSampleSeconds = 1
+	nettopTimeout       = 4 * time.Second
+	networkProcessTop   = 3
+)
+
+var collectProcessNetworkFunc = collectProcessNetwork
+
+// collectProcessNetwork attributes traffic to processes with nettop. Linux
+// has no unprivileged per-process byte counters, so it reports nothing there.
+func collectProcessNetwork() []ProcessNetwork {
+	if runtime.GOOS != "darwin" || !commandExists("nettop") {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), nettopTimeout)
+	defer cancel()
+
+	out, err := runCmd(ctx, "nettop", "-P", "-x", "-d", "-L", "2",
+		"-s", strconv.Itoa(nettopSampleSeconds), "-J", "bytes_in,bytes_out")
+	if err != nil {
+		return nil
+	}
+	return topProcessNetwork(parseNettop(out, nettopSampleSeconds), networkProcessTop)
+}
+
+// parseNettop reads nettop's CSV output and returns the last sample. Rows
+// name processes as "name.pid"; byte columns are located by header so the
+// leading time column is optional.
+func parseNettop(out string, seconds float64) []ProcessNetwork {
+	var (
+		procs          []ProcessNetwork
+		inIdx, outIdx  = -1, -1
+		nameIdx        int
+		perSecondScale = 1.0 / 1024.0 / 1024.0 / seconds
+	)
+	for line := range strings.Lines(out) {
+		fields := strings.Split(strings.TrimSpace(line), ",")
+		if idx := slices.Index(fields, "bytes_in"); idx >= 0 {
+			// A new header starts the next sample; only the last one is a
+			// full interval delta.
+			procs = procs[:0]
+			inIdx, outIdx = idx, slices.Index(fields, "bytes_out")
+			nameIdx = 0
+			if fields[0] == "time" {
+				nameIdx = 1
+			}
+			continue
+		}
+		if inIdx < 0 || outIdx < 0 || len(fields) <= max(inIdx, outIdx, nameIdx) {
+			continue
+		}
+		dot := strings.LastIndex(fields[nameIdx], ".")
+		if dot <= 0 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[nameIdx][dot+1:])
+		if err != nil {
+			continue
+		}
+		rx, _ := strconv.ParseFloat(fields[inIdx], 64)
+		tx, _ := strconv.ParseFloat(fields[outIdx], 64)
+		if rx+tx <= 0 {
+			continue
+		}
+		procs = append(procs, ProcessNetwork{
+			PID:       pid,
+			Name:      fields[nameIdx][:dot],
+			RxRateMBs: rx * perSecondScale,
+			TxRateMBs: tx * perSecondScale,
+		})
+	}
+	return procs
+}
+
+func topProcessNetwork(procs []ProcessNetwork, limit int) []ProcessNetwork {
+	slices.SortFunc(procs, func(a, b ProcessNetwork) int {
+		return cmp.Or(
+			cmp.Compare(b.RxRateMBs+b.TxRateMBs, a.RxRateMBs+a.TxRateMBs),
+			cmp.Compare(a.PID, b.PID),
+		)
+	})
+	if len(procs) > limit {
+		procs = procs[:limit]
+	}
+	return procs
+}
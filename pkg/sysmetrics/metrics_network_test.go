@@ -1,6 +1,8 @@
-package main
+package sysmetrics
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -182,3 +184,100 @@ func TestCollectNetworkClampsCounterReset(t *testing.T) {
 		t.Fatalf("expected reset counters to clamp to zero, got %+v", got[0])
 	}
 }
+
+func TestParseNettopUsesLastSample(t *testing.T) {
+	out := `time,,bytes_in,bytes_out,
+10:00:00.000000,Google Chrome H.901,900000000,12000000,
+10:00:00.000000,launchd.1,0,0,
+time,,bytes_in,bytes_out,
+10:00:01.000000,Google Chrome H.901,2097152,524288,
+10:00:01.000000,Dropbox.612,4194304,0,
+10:00:01.000000,launchd.1,0,0,
+10:00:01.000000,garbage,5,5,
+`
+	procs := topProcessNetwork(parseNettop(out, 1), 3)
+	if len(procs) != 2 {
+		t.Fatalf("parseNettop() = %+v, want 2 active processes", procs)
+	}
+	if procs[0].Name != "Dropbox" || procs[0].PID != 612 || procs[0].RxRateMBs != 4 {
+		t.Fatalf("top process = %+v", procs[0])
+	}
+	if procs[1].Name != "Google Chrome H" || procs[1].RxRateMBs != 2 || procs[1].TxRateMBs != 0.5 {
+		t.Fatalf("second process = %+v", procs[1])
+	}
+}
+
+func TestCollectPublicIPIsOptInAndCached(t *testing.T) {
+	origGet := httpGetFunc
+	t.Cleanup(func() { httpGetFunc = origGet })
+
+	calls := 0
+	httpGetFunc = func(ctx context.Context, url string) ([]byte, error) {
+		calls++
+		if url == publicIPv6Endpoint {
+			return nil, errors.New("no route")
+		}
+		return []byte(`{"ip":"203.0.113.7","city":"Berlin","region":"Berlin","country":"DE","org":"AS3320 Deutsche Telekom AG"}`), nil
+	}
+
+	c := NewCollector(ProcessWatchOptions{})
+	now := time.Now()
+	if got := c.collectPublicIP(now, VPNStatus{}); got.IPv4 != "" || calls != 0 {
+		t.Fatalf("public IP should stay off by default, got %+v after %d calls", got, calls)
+	}
+
+	c.EnablePublicIP()
+	got := c.collectPublicIP(now, VPNStatus{})
+	if got.IPv4 != "203.0.113.7" || got.ASN != "AS3320" || got.Org != "Deutsche Telekom AG" || got.IPv6 != "" {
+		t.Fatalf("collectPublicIP() = %+v", got)
+	}
+	c.collectPublicIP(now.Add(time.Minute), VPNStatus{})
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2 (cached within TTL)", calls)
+	}
+	c.collectPublicIP(now.Add(2*time.Minute), VPNStatus{Tunnels: []string{"utun4 10.8.0.2"}})
+	if calls != 4 {
+		t.Fatalf("calls = %d, want 4 (VPN change refreshes)", calls)
+	}
+}
+
+func TestIsNoiseInterface(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		// Noise interfaces (should return true).
+		{"loopback", "lo0", true},
+		{"awdl", "awdl0", true},
+		{"utun", "utun0", true},
+		{"llw", "llw0", true},
+		{"bridge", "bridge0", true},
+		{"gif", "gif0", true},
+		{"stf", "stf0", true},
+		{"xhc", "xhc0", true},
+		{"anpi", "anpi0", true},
+		{"ap", "ap1", true},
+
+		// Real interfaces (should return false).
+		{"ethernet", "en0", false},
+		{"wifi", "en1", false},
+		{"thunderbolt", "en5", false},
+
+		// Case insensitivity.
+		{"uppercase LO", "LO0", true},
+		{"mixed case Awdl", "Awdl0", true},
+
+		// Edge cases.
+		{"empty string", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := isNoiseInterface(tt.input)
+			if got != tt.want {
+				t.Errorf("isNoiseInterface(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package sysmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// system_profiler takes a second or more, so the inventory is only re-read
+// when the set of attached USB devices changes, or every peripheralsTTL.
+const peripheralsTTL = 10 * time.Minute
+
+var (
+	sysUSBDevices         = "/sys/bus/usb/devices"
+	sysThunderboltDevices = "/sys/bus/thunderbolt/devices"
+)
+
+// Negotiated speeds system_profiler reports for USB devices, in Mb/s.
+var spUSBSpeeds = map[string]float64{
+	"low_speed":        1.5,
+	"full_speed":       12,
+	"high_speed":       480,
+	"super_speed":      5000,
+	"super_speed_plus": 10000,
+}
+
+// SlowLink reports whether the device negotiated below what it supports.
+func (p Peripheral) SlowLink() bool {
+	return p.SpeedMbps > 0 && p.MaxSpeedMbps > p.SpeedMbps
+}
+
+func (c *Collector) collectPeripherals(now time.Time) []Peripheral {
+	switch runtime.GOOS {
+	case "darwin":
+		return c.collectMacPeripherals(now)
+	case "linux":
+		return append(readSysfsUSB(sysUSBDevices), readSysfsThunderbolt(sysThunderboltDevices)...)
+	}
+	return nil
+}
+
+func (c *Collector) collectMacPeripherals(now time.Time) []Peripheral {
+	if !commandExists("ioreg") || !commandExists("system_profiler") {
+		return nil
+	}
+	// ioreg is quick; its device list doubles as the hotplug check.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	out, err := runCmd(ctx, "ioreg", "-a", "-r", "-d", "1", "-c", "IOUSBHostDevice")
+	cancel()
+	if err != nil {
+		return c.cachedPeripherals
+	}
+	capable := parseIOUSBCapability(out)
+	key := strings.Join(slices.Sorted(maps.Keys(capable)), "\n")
+	if key == c.peripheralsKey && !c.lastPeripheralsAt.IsZero() && now.Sub(c.lastPeripheralsAt) < peripheralsTTL {
+		return c.cachedPeripherals
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), systemProfilerTimeout)
+	defer cancel()
+	out, err = runCmd(ctx, "system_profiler", "-json", "SPUSBDataType", "SPThunderboltDataType")
+	if err != nil {
+		return c.cachedPeripherals
+	}
+	peripherals := parseSPPeripherals(out)
+	for i, p := range peripherals {
+		if p.Bus == "USB" {
+			peripherals[i].MaxSpeedMbps = capable[p.Name]
+		}
+	}
+	c.cachedPeripherals = peripherals
+	c.peripheralsKey = key
+	c.lastPeripheralsAt = now
+	return peripherals
+}
+
+type spPeripheralItem struct {
+	Name         string             `json:"_name"`
+	Manufacturer string             `json:"manufacturer"`
+	Speed        string             `json:"device_speed"`
+	PowerUsed    string             `json:"bus_power_used"`
+	DeviceName   string             `json:"device_name_key"`
+	VendorName   string             `json:"vendor_name_key"`
+	Items        []spPeripheralItem `json:"_items"`
+}
+
+// parseSPPeripherals flattens `system_profiler -json SPUSBDataType
+// SPThunderboltDataType`. USB buses nest hubs and devices under _items;
+// Thunderbolt buses list the host port first and attached devices below.
+func parseSPPeripherals(out string) []Peripheral {
+	var data struct {
+		USB         []json.RawMessage `json:"SPUSBDataType"`
+		Thunderbolt []json.RawMessage `json:"SPThunderboltDataType"`
+	}
+	if json.Unmarshal([]byte(out), &data) != nil {
+		return nil
+	}
+	var peripherals []Peripheral
+	var walkUSB func(items []spPeripheralItem)
+	walkUSB = func(items []spPeripheralItem) {
+		for _, item := range items {
+			// Hubs carry children; list what is plugged into them instead.
+			if len(item.Items) > 0 {
+				walkUSB(item.Items)
+				continue
+			}
+			power, _ := strconv.Atoi(item.PowerUsed)
+			peripherals = append(peripherals, Peripheral{
+				Name:       item.Name,
+				Vendor:     item.Manufacturer,
+				Bus:        "USB",
+				SpeedMbps:  spUSBSpeeds[item.Speed],
+				PowerMilli: power,
+			})
+		}
+	}
+	for _, raw := range data.USB {
+		var bus spPeripheralItem
+		if json.Unmarshal(raw, &bus) == nil {
+			walkUSB(bus.Items)
+		}
+	}
+	for _, raw := range data.Thunderbolt {
+		hostSpeed := thunderboltSpeed(raw)
+		for _, rawItem := range thunderboltItems(raw) {
+			var item spPeripheralItem
+			if json.Unmarshal(rawItem, &item) != nil {
+				continue
+			}
+			name := item.DeviceName
+			if name == "" {
+				name = item.Name
+			}
+			peripherals = append(peripherals, Peripheral{
+				Name:         name,
+				Vendor:       item.VendorName,
+				Bus:          "Thunderbolt",
+				SpeedMbps:    thunderboltSpeed(rawItem),
+				MaxSpeedMbps: hostSpeed,
+			})
+		}
+	}
+	return peripherals
+}
+
+func thunderboltItems(raw json.RawMessage) []json.RawMessage {
+	var bus struct {
+		Items []json.RawMessage `json:"_items"`
+	}
+	_ = json.Unmarshal(raw, &bus)
+	return bus.Items
+}
+
+// thunderboltSpeed reads current_speed_key ("Up to 40 Gb/s") from the
+// first receptacle_*_tag of a Thunderbolt bus or device.
+func thunderboltSpeed(raw json.RawMessage) float64 {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(raw, &fields) != nil {
+		return 0
+	}
+	for key, value := range fields {
+		if !strings.HasPrefix(key, "receptacle_") {
+			continue
+		}
+		var tag struct {
+			Speed string `json:"current_speed_key"`
+		}
+		if json.Unmarshal(value, &tag) == nil && tag.Speed != "" {
+			return parseLinkSpeed(tag.Speed)
+		}
+	}
+	return 0
+}
+
+// parseLinkSpeed converts "Up to 40 Gb/s", "20.0 Gb/s", or "480 Mb/s" to
+// Mb/s.
+func parseLinkSpeed(raw string) float64 {
+	fields := strings.Fields(strings.TrimPrefix(raw, "Up to "))
+	if len(fields) < 2 {
+		return 0
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	if strings.HasPrefix(fields[1], "G") {
+		value *= 1000
+	}
+	return value
+}
+
+// parseIOUSBCapability maps each IOUSBHostDevice's product name to the top
+// speed its bcdUSB version allows. system_profiler only shows the speed
+// the device negotiated.
+func parseIOUSBCapability(out string) map[string]float64 {
+	root, err := decodePlist(out)
+	if err != nil {
+		return nil
+	}
+	list, _ := root.([]any)
+	capable := make(map[string]float64, len(list))
+	for _, item := range list {
+		dict, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if name := plistString(dict, "USB Product Name"); name != "" {
+			capable[name] = usbVersionSpeed(plistUint(dict, "bcdUSB"))
+		}
+	}
+	return capable
+}
+
+// usbVersionSpeed maps a BCD USB version (0x0310 for 3.1) to its top
+// signalling rate. 3.2 devices are counted as 10 Gb/s; few do 20.
+func usbVersionSpeed(bcd uint64) float64 {
+	switch {
+	case bcd >= 0x0310:
+		return 10000
+	case bcd >= 0x0300:
+		return 5000
+	case bcd >= 0x0200:
+		return 480
+	case bcd >= 0x0100:
+		return 12
+	}
+	return 0
+}
+
+// readSysfsUSB lists non-hub USB devices. sysfs has the negotiated speed in
+// Mb/s, the USB version the device supports, and its configured max power.
+func readSysfsUSB(root string) []Peripheral {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var peripherals []Peripheral
+	for _, entry := range entries {
+		name := entry.Name()
+		// Root hubs are usbN; interfaces contain ':'.
+		if strings.HasPrefix(name, "usb") || strings.Contains(name, ":") {
+			continue
+		}
+		dir := filepath.Join(root, name)
+		if readSysfsString(filepath.Join(dir, "bDeviceClass")) == "09" {
+			continue
+		}
+		product := readSysfsString(filepath.Join(dir, "product"))
+		if product == "" {
+			continue
+		}
+		speed, _ := strconv.ParseFloat(readSysfsString(filepath.Join(dir, "speed")), 64)
+		power, _ := strconv.Atoi(strings.TrimSuffix(readSysfsString(filepath.Join(dir, "bMaxPower")), "mA"))
+		peripherals = append(peripherals, Peripheral{
+			Name:         product,
+			Vendor:       readSysfsString(filepath.Join(dir, "manufacturer")),
+			Bus:          "USB",
+			SpeedMbps:    speed,
+			MaxSpeedMbps: usbVersionSpeed(parseUSBVersion(readSysfsString(filepath.Join(dir, "version")))),
+			PowerMilli:   power,
+		})
+	}
+	return peripherals
+}
+
+// parseUSBVersion turns sysfs's "3.10" into BCD 0x0310.
+func parseUSBVersion(raw string) uint64 {
+	major, minor, _ := strings.Cut(raw, ".")
+	m, err1 := strconv.ParseUint(major, 10, 64)
+	n, err2 := strconv.ParseUint(minor, 16, 64)
+	if err1 != nil || err2 != nil {
+		return 0
+	}
+	return m<<8 | n
+}
+
+// readSysfsThunderbolt lists attached Thunderbolt/USB4 devices, skipping
+// each domain's host router (N-0).
+func readSysfsThunderbolt(root string) []Peripheral {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var peripherals []Peripheral
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, "-0") {
+			continue
+		}
+		dir := filepath.Join(root, name)
+		device := readSysfsString(filepath.Join(dir, "device_name"))
+		if device == "" {
+			continue
+		}
+		speed := parseLinkSpeed(readSysfsString(filepath.Join(dir, "rx_speed")))
+		if lanes, err := strconv.Atoi(readSysfsString(filepath.Join(dir, "rx_lanes"))); err == nil && lanes > 1 {
+			speed *= float64(lanes)
+		}
+		peripherals = append(peripherals, Peripheral{
+			Name:      device,
+			Vendor:    readSysfsString(filepath.Join(dir, "vendor_name")),
+			Bus:       "Thunderbolt",
+			SpeedMbps: speed,
+		})
+	}
+	return peripherals
+}
+
+func readSysfsString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
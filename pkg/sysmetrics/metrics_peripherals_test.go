@@ -0,0 +1,112 @@
+package sysmetrics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSPPeripherals(t *testing.T) {
+	out := `{
+  "SPThunderboltDataType": [{
+    "_name": "thunderboltusb4_bus_0",
+    "device_name_key": "MacBook Pro",
+    "receptacle_1_tag": {"current_speed_key": "Up to 40 Gb/s", "receptacle_status_key": "receptacle_connected"},
+    "_items": [{
+      "_name": "TS3 Plus",
+      "device_name_key": "TS3 Plus",
+      "vendor_name_key": "CalDigit, Inc.",
+      "receptacle_upstream_ambiguous_tag": {"current_speed_key": "Up to 20 Gb/s"}
+    }]
+  }],
+  "SPUSBDataType": [{
+    "_name": "USB31Bus",
+    "_items": [{
+      "_name": "USB3.1 Hub",
+      "_items": [
+        {"_name": "Samsung T7", "manufacturer": "Samsung", "device_speed": "high_speed", "bus_power_used": "896"},
+        {"_name": "Magic Keyboard", "manufacturer": "Apple Inc.", "device_speed": "full_speed"}
+      ]
+    }]
+  }]
+}`
+	got := parseSPPeripherals(out)
+	if len(got) != 3 {
+		t.Fatalf("got %d peripherals, want 3: %+v", len(got), got)
+	}
+	ssd := got[0]
+	if ssd.Name != "Samsung T7" || ssd.Bus != "USB" || ssd.SpeedMbps != 480 || ssd.PowerMilli != 896 {
+		t.Fatalf("ssd = %+v", ssd)
+	}
+	dock := got[2]
+	if dock.Name != "TS3 Plus" || dock.Bus != "Thunderbolt" || dock.SpeedMbps != 20000 || dock.MaxSpeedMbps != 40000 || !dock.SlowLink() {
+		t.Fatalf("dock = %+v", dock)
+	}
+}
+
+func TestParseIOUSBCapability(t *testing.T) {
+	out := `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<array>
+	<dict>
+		<key>USB Product Name</key>
+		<string>Samsung T7</string>
+		<key>bcdUSB</key>
+		<integer>800</integer>
+	</dict>
+	<dict>
+		<key>USB Product Name</key>
+		<string>Magic Keyboard</string>
+		<key>bcdUSB</key>
+		<integer>512</integer>
+	</dict>
+</array>
+</plist>`
+	got := parseIOUSBCapability(out)
+	if got["Samsung T7"] != 10000 || got["Magic Keyboard"] != 480 {
+		t.Fatalf("parseIOUSBCapability() = %v", got)
+	}
+}
+
+func TestReadSysfsUSBAndThunderbolt(t *testing.T) {
+	usb := t.TempDir()
+	write := func(dir, name, value string) {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(usb, "usb2"), "product", "xHCI Host Controller")
+	write(filepath.Join(usb, "2-1"), "product", "USB3.0 Hub")
+	write(filepath.Join(usb, "2-1"), "bDeviceClass", "09")
+	write(filepath.Join(usb, "2-1:1.0"), "bInterfaceClass", "09")
+	ssd := filepath.Join(usb, "2-1.2")
+	write(ssd, "product", "Portable SSD T7")
+	write(ssd, "manufacturer", "Samsung")
+	write(ssd, "speed", "5000")
+	write(ssd, "version", " 3.20")
+	write(ssd, "bMaxPower", "896mA")
+
+	got := readSysfsUSB(usb)
+	if len(got) != 1 {
+		t.Fatalf("got %d USB devices, want 1: %+v", len(got), got)
+	}
+	if p := got[0]; p.Name != "Portable SSD T7" || p.SpeedMbps != 5000 || p.MaxSpeedMbps != 10000 || p.PowerMilli != 896 || !p.SlowLink() {
+		t.Fatalf("ssd = %+v", p)
+	}
+
+	tb := t.TempDir()
+	write(filepath.Join(tb, "0-0"), "device_name", "Host")
+	write(filepath.Join(tb, "0-1"), "device_name", "TS4")
+	write(filepath.Join(tb, "0-1"), "vendor_name", "CalDigit, Inc.")
+	write(filepath.Join(tb, "0-1"), "rx_speed", "20.0 Gb/s")
+	write(filepath.Join(tb, "0-1"), "rx_lanes", "2")
+	write(filepath.Join(tb, "domain0"), "security", "user")
+	got = readSysfsThunderbolt(tb)
+	if len(got) != 1 || got[0].Name != "TS4" || got[0].SpeedMbps != 40000 {
+		t.Fatalf("readSysfsThunderbolt() = %+v", got)
+	}
+}
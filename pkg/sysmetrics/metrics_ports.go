@@ -0,0 +1,178 @@
+package sysmetrics
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	portsCmdTimeout = 3 * time.Second
+	// Listeners that appeared after the first scan stay flagged this long.
+	listenerNewWindow  = 10 * time.Minute
+	portConnectionsTop = 3
+)
+
+type lsofSocket struct {
+	pid     int
+	command string
+	proto   string
+	name    string
+	state   string
+}
+
+func (c *Collector) collectPorts(now time.Time) PortsStatus {
+	if !commandExists("lsof") {
+		return PortsStatus{}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), portsCmdTimeout)
+	defer cancel()
+
+	// -F emits one field per line, which survives spaces in process names.
+	out, err := runCmd(ctx, "lsof", "-nP", "-iTCP", "-iUDP", "-FpcPnT")
+	if err != nil {
+		return PortsStatus{}
+	}
+	status := summarizeSockets(parseLsofSockets(out))
+	c.markNewListeners(now, status.Listeners)
+	slices.SortStableFunc(status.Listeners, func(a, b ListenPort) int {
+		if a.New != b.New {
+			if a.New {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.Port, b.Port)
+	})
+	return status
+}
+
+func parseLsofSockets(out string) []lsofSocket {
+	var (
+		sockets []lsofSocket
+		pid     int
+		command string
+		cur     *lsofSocket
+	)
+	for line := range strings.Lines(out) {
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			continue
+		}
+		field, value := line[0], line[1:]
+		switch field {
+		case 'p':
+			pid, _ = strconv.Atoi(value)
+			command = ""
+			cur = nil
+		case 'c':
+			command = value
+		case 'f':
+			sockets = append(sockets, lsofSocket{pid: pid, command: command})
+			cur = &sockets[len(sockets)-1]
+		case 'P':
+			if cur != nil {
+				cur.proto = strings.ToLower(value)
+			}
+		case 'n':
+			if cur != nil {
+				cur.name = value
+			}
+		case 'T':
+			if cur != nil && strings.HasPrefix(value, "ST=") {
+				cur.state = strings.TrimPrefix(value, "ST=")
+			}
+		}
+	}
+	return sockets
+}
+
+// summarizeSockets keeps TCP listeners and unconnected UDP sockets as
+// listeners, and counts established TCP connections per process.
+func summarizeSockets(sockets []lsofSocket) PortsStatus {
+	var status PortsStatus
+	seen := make(map[string]bool)
+	perProc := make(map[int]*ConnectionSummary)
+	for _, s := range sockets {
+		remote := strings.Contains(s.name, "->")
+		switch {
+		case s.proto == "tcp" && s.state == "LISTEN", s.proto == "udp" && !remote:
+			port := socketPort(s.name)
+			key := fmt.Sprintf("%s %s %d", s.proto, s.name, s.pid)
+			if port == 0 || seen[key] {
+				continue
+			}
+			seen[key] = true
+			status.Listeners = append(status.Listeners, ListenPort{
+				Proto:   s.proto,
+				Address: s.name,
+				Port:    port,
+				PID:     s.pid,
+				Process: s.command,
+			})
+		case s.proto == "tcp" && s.state == "ESTABLISHED":
+			status.Established++
+			summary, ok := perProc[s.pid]
+			if !ok {
+				summary = &ConnectionSummary{PID: s.pid, Process: s.command}
+				perProc[s.pid] = summary
+			}
+			summary.Established++
+		}
+	}
+
+	for _, summary := range perProc {
+		status.Connections = append(status.Connections, *summary)
+	}
+	slices.SortFunc(status.Connections, func(a, b ConnectionSummary) int {
+		if a.Established != b.Established {
+			return b.Established - a.Established
+		}
+		return cmp.Compare(a.PID, b.PID)
+	})
+	if len(status.Connections) > portConnectionsTop {
+		status.Connections = status.Connections[:portConnectionsTop]
+	}
+	return status
+}
+
+func socketPort(name string) int {
+	idx := strings.LastIndex(name, ":")
+	if idx < 0 {
+		return 0
+	}
+	port, _ := strconv.Atoi(name[idx+1:])
+	return port
+}
+
+// markNewListeners flags listeners first seen after the initial scan, so
+// whatever was already listening when status started is not reported as new.
+func (c *Collector) markNewListeners(now time.Time, listeners []ListenPort) {
+	baseline := c.listenerSeen == nil
+	if baseline {
+		c.listenerSeen = make(map[string]time.Time)
+	}
+	current := make(map[string]bool, len(listeners))
+	for i := range listeners {
+		l := &listeners[i]
+		key := fmt.Sprintf("%s %d %s", l.Proto, l.Port, l.Process)
+		current[key] = true
+		firstSeen, ok := c.listenerSeen[key]
+		if !ok {
+			if !baseline {
+				firstSeen = now
+			}
+			c.listenerSeen[key] = firstSeen
+		}
+		l.New = !firstSeen.IsZero() && now.Sub(firstSeen) < listenerNewWindow
+	}
+	for key := range c.listenerSeen {
+		if !current[key] {
+			delete(c.listenerSeen, key)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package sysmetrics
+
+import (
+	"testing"
+	"time"
+)
+
+const lsofPortsOutput = `p532
+cControlCenter
+f9
+PTCP
+n*:5000
+TST=LISTEN
+TQR=0
+f10
+PTCP
+n*:7000
+TST=LISTEN
+p901
+cGoogle Chrome He
+f31
+PTCP
+n192.168.1.20:52344->142.250.72.14:443
+TST=ESTABLISHED
+f32
+PTCP
+n192.168.1.20:52345->142.250.72.14:443
+TST=ESTABLISHED
+f40
+PUDP
+n192.168.1.20:61000->142.250.72.14:443
+p4321
+cnode
+f22
+PTCP
+n*:3000
+TST=LISTEN
+f23
+PTCP
+n*:3000
+TST=LISTEN
+f24
+PTCP
+n127.0.0.1:3000->127.0.0.1:52400
+TST=ESTABLISHED
+p88
+cmDNSResponder
+f5
+PUDP
+n*:5353
+f6
+PUDP
+n*:*
+`
+
+func TestSummarizeLsofSockets(t *testing.T) {
+	status := summarizeSockets(parseLsofSockets(lsofPortsOutput))
+
+	if len(status.Listeners) != 4 {
+		t.Fatalf("listeners = %+v, want 4 (dedup v4/v6, skip connected and wildcard-port UDP)", status.Listeners)
+	}
+	if status.Listeners[2].Process != "node" || status.Listeners[2].Port != 3000 || status.Listeners[2].PID != 4321 {
+		t.Fatalf("node listener = %+v", status.Listeners[2])
+	}
+	if status.Listeners[3].Proto != "udp" || status.Listeners[3].Port != 5353 {
+		t.Fatalf("udp listener = %+v", status.Listeners[3])
+	}
+	if status.Established != 3 {
+		t.Fatalf("established = %d, want 3", status.Established)
+	}
+	if len(status.Connections) != 2 || status.Connections[0].Process != "Google Chrome He" || status.Connections[0].Established != 2 {
+		t.Fatalf("connections = %+v", status.Connections)
+	}
+}
+
+func TestMarkNewListenersSkipsBaseline(t *testing.T) {
+	c := NewCollector(ProcessWatchOptions{})
+	base := time.Date(2026, 3, 19, 10, 0, 0, 0, time.UTC)
+
+	initial := []ListenPort{{Proto: "tcp", Port: 5000, Process: "ControlCenter"}}
+	c.markNewListeners(base, initial)
+	if initial[0].New {
+		t.Fatal("listeners present at startup should not be new")
+	}
+
+	later := []ListenPort{
+		{Proto: "tcp", Port: 5000, Process: "ControlCenter"},
+		{Proto: "tcp", Port: 3000, Process: "node"},
+	}
+	c.markNewListeners(base.Add(time.Minute), later)
+	if later[0].New || !later[1].New {
+		t.Fatalf("after first scan = %+v", later)
+	}
+
+	c.markNewListeners(base.Add(time.Minute+listenerNewWindow), later)
+	if later[1].New {
+		t.Fatal("listener should stop being new after the window")
+	}
+}
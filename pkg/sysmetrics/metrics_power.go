@@ -0,0 +1,125 @@
+package sysmetrics
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	powerHistorySize = 10
+	// aneMaxWatts is roughly the Neural Engine's draw when fully busy on
+	// M-series chips; it turns ANE power into an approximate utilization.
+	aneMaxWatts = 8.0
+)
+
+// raplRoot is the Linux powercap tree; Intel and recent AMD CPUs expose
+// cumulative energy counters here. energy_uj is root-only on most distros.
+var raplRoot = "/sys/class/powercap"
+
+// collectPower runs after the concurrent collectors so the darwin path can
+// reuse the powermetrics sample the GPU collector already paid for.
+func (c *Collector) collectPower(now time.Time) PowerStatus {
+	var power PowerStatus
+	switch runtime.GOOS {
+	case "darwin":
+		power = c.cachedPowermetrics.power
+	case "linux":
+		power = c.sampleRAPL(now)
+	}
+	if power.PackageWatts <= 0 {
+		return PowerStatus{}
+	}
+
+	if c.powerHistoryBuf == nil {
+		c.powerHistoryBuf = NewRingBuffer(powerHistorySize)
+	}
+	c.powerHistoryBuf.Add(power.PackageWatts)
+	var sum float64
+	history := c.powerHistoryBuf.Slice()
+	for _, w := range history {
+		sum += w
+	}
+	power.AvgPackageWatts = sum / float64(len(history))
+	return power
+}
+
+// collectANE reads the Neural Engine's share of the powermetrics sample the
+// GPU collector took. Only Apple Silicon Macs have one.
+func (c *Collector) collectANE() ANEStatus {
+	if runtime.GOOS != "darwin" {
+		return ANEStatus{}
+	}
+	return aneFromWatts(c.cachedPowermetrics.power.ANEWatts)
+}
+
+func aneFromWatts(watts float64) ANEStatus {
+	if watts <= 0 {
+		return ANEStatus{}
+	}
+	return ANEStatus{Watts: watts, Usage: min(watts/aneMaxWatts*100, 100)}
+}
+
+// sampleRAPL converts the energy consumed since the previous call into an
+// average wattage. The first call only primes the counters.
+func (c *Collector) sampleRAPL(now time.Time) PowerStatus {
+	energy := readRAPLEnergy(raplRoot)
+	prev, prevAt := c.prevRAPL, c.prevRAPLAt
+	c.prevRAPL, c.prevRAPLAt = energy, now
+	if len(energy) == 0 || len(prev) == 0 {
+		return PowerStatus{}
+	}
+	elapsed := now.Sub(prevAt).Seconds()
+	if elapsed <= 0 {
+		return PowerStatus{}
+	}
+
+	watts := func(domain string) float64 {
+		cur, ok := energy[domain]
+		before, hadBefore := prev[domain]
+		// Skip counter wraparound rather than report a bogus spike.
+		if !ok || !hadBefore || cur < before {
+			return 0
+		}
+		return float64(cur-before) / 1e6 / elapsed
+	}
+	power := PowerStatus{
+		PackageWatts: watts("package"),
+		CPUWatts:     watts("core"),
+		GPUWatts:     watts("uncore"),
+	}
+	if power.PackageWatts > 0 {
+		power.Source = "rapl"
+	}
+	return power
+}
+
+// readRAPLEnergy returns cumulative microjoules per domain ("package",
+// "core", "uncore"), summed across sockets.
+func readRAPLEnergy(root string) map[string]uint64 {
+	dirs, _ := filepath.Glob(filepath.Join(root, "intel-rapl:*"))
+	energy := make(map[string]uint64)
+	for _, dir := range dirs {
+		name, err := os.ReadFile(filepath.Join(dir, "name"))
+		if err != nil {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(dir, "energy_uj"))
+		if err != nil {
+			continue
+		}
+		uj, err := strconv.ParseUint(strings.TrimSpace(string(raw)), 10, 64)
+		if err != nil {
+			continue
+		}
+		domain := strings.TrimSpace(string(name))
+		if strings.HasPrefix(domain, "package") {
+			domain = "package"
+		}
+		energy[domain] += uj
+	}
+	return energy
+}
@@ -0,0 +1,84 @@
+package sysmetrics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParsePowermetricsPower(t *testing.T) {
+	out := `CPU Power: 4120 mW
+GPU Power: 860 mW
+ANE Power: 35 mW
+Combined Power (CPU + GPU + ANE): 5015 mW
+`
+	power := parsePowermetrics(out).power
+	want := PowerStatus{PackageWatts: 5.015, CPUWatts: 4.12, GPUWatts: 0.86, ANEWatts: 0.035, Source: "powermetrics"}
+	if power != want {
+		t.Fatalf("power = %+v, want %+v", power, want)
+	}
+
+	// Older macOS releases omit the combined line.
+	if got := parsePowermetrics("CPU Power: 1000 mW\nGPU Power: 500 mW\n").power.PackageWatts; got != 1.5 {
+		t.Fatalf("derived package watts = %v, want 1.5", got)
+	}
+	if got := parsePowermetrics("").power; got != (PowerStatus{}) {
+		t.Fatalf("empty power = %+v", got)
+	}
+}
+
+func writeRAPLDomain(t *testing.T, root, dir, name, energy string) {
+	t.Helper()
+	path := filepath.Join(root, dir)
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "name"), []byte(name+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "energy_uj"), []byte(energy+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSampleRAPLComputesWattsFromEnergyDelta(t *testing.T) {
+	root := t.TempDir()
+	oldRoot := raplRoot
+	raplRoot = root
+	defer func() { raplRoot = oldRoot }()
+
+	writeRAPLDomain(t, root, "intel-rapl:0", "package-0", "1000000")
+	writeRAPLDomain(t, root, "intel-rapl:0:0", "core", "500000")
+
+	c := &Collector{}
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	if power := c.sampleRAPL(start); power != (PowerStatus{}) {
+		t.Fatalf("first sample should only prime counters, got %+v", power)
+	}
+
+	writeRAPLDomain(t, root, "intel-rapl:0", "package-0", "21000000")
+	writeRAPLDomain(t, root, "intel-rapl:0:0", "core", "10500000")
+	power := c.sampleRAPL(start.Add(2 * time.Second))
+	if power.PackageWatts != 10 || power.CPUWatts != 5 || power.Source != "rapl" {
+		t.Fatalf("power = %+v, want 10W package, 5W core", power)
+	}
+
+	// Counter wraparound is skipped instead of producing a negative spike.
+	writeRAPLDomain(t, root, "intel-rapl:0", "package-0", "100")
+	if power := c.sampleRAPL(start.Add(4 * time.Second)); power.PackageWatts != 0 {
+		t.Fatalf("wrapped counter power = %+v, want 0", power)
+	}
+}
+
+func TestANEFromWatts(t *testing.T) {
+	if got := aneFromWatts(2); got.Watts != 2 || got.Usage != 25 {
+		t.Fatalf("aneFromWatts(2) = %+v, want 25%%", got)
+	}
+	if got := aneFromWatts(12); got.Usage != 100 {
+		t.Fatalf("aneFromWatts(12).Usage = %v, want capped at 100", got.Usage)
+	}
+	if got := aneFromWatts(0); got != (ANEStatus{}) {
+		t.Fatalf("idle ANE = %+v, want zero", got)
+	}
+}
@@ -0,0 +1,90 @@
+package sysmetrics
+
+import (
+	"context"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// pmset -g log holds weeks of history and takes seconds to print.
+	powerEventsTTL     = 10 * time.Minute
+	powerEventsWindow  = 24 * time.Hour
+	powerEventsLimit   = 50
+	powerEventsTimeout = 10 * time.Second
+)
+
+var (
+	pmsetChargeRe = regexp.MustCompile(`\(Charge:\s*(\d+)%\)`)
+	// Sleep reasons are quoted ("due to 'Clamshell Sleep'"); wake reasons
+	// run up to the power source ("due to EC.LidOpen/Lid Open Using BATT").
+	pmsetSleepReasonRe = regexp.MustCompile(`due to '([^']+)'`)
+	pmsetWakeReasonRe  = regexp.MustCompile(`due to (.+?)(?:\s+Using|\s*$)`)
+)
+
+// pmsetEventKinds maps the pmset log's event column to PowerEvent kinds.
+var pmsetEventKinds = map[string]string{
+	"Sleep":    "sleep",
+	"Wake":     "wake",
+	"DarkWake": "darkwake",
+}
+
+func (c *Collector) collectPowerEvents(now time.Time) []PowerEvent {
+	if runtime.GOOS != "darwin" || !commandExists("pmset") {
+		return nil
+	}
+	if !c.lastPowerEventsAt.IsZero() && now.Sub(c.lastPowerEventsAt) < powerEventsTTL {
+		return c.cachedPowerEvents
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), powerEventsTimeout)
+	defer cancel()
+	out, err := runCmd(ctx, "pmset", "-g", "log")
+	if err != nil {
+		return c.cachedPowerEvents
+	}
+	c.cachedPowerEvents = parsePmsetLog(out, now)
+	c.lastPowerEventsAt = now
+	return c.cachedPowerEvents
+}
+
+// parsePmsetLog keeps the sleep, wake, and dark wake entries of
+// `pmset -g log` from the last powerEventsWindow, newest first:
+//
+//	2026-03-11 23:10:02 +0000 Sleep    Entering Sleep state due to 'Clamshell Sleep': Using Batt (Charge:85%) 3600 secs
+//	2026-03-12 03:00:00 +0000 DarkWake DarkWake from Deep Idle [CDN] : due to RTC/Maintenance Using BATT (Charge:84%) 45 secs
+//	2026-03-12 08:00:00 +0000 Wake     Wake from Deep Idle [CDNVA] : due to EC.LidOpen/Lid Open Using BATT (Charge:79%)
+func parsePmsetLog(out string, now time.Time) []PowerEvent {
+	var events []PowerEvent
+	for line := range strings.Lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		kind, ok := pmsetEventKinds[fields[3]]
+		if !ok {
+			continue
+		}
+		at, err := time.Parse("2006-01-02 15:04:05 -0700", strings.Join(fields[:3], " "))
+		if err != nil || now.Sub(at) > powerEventsWindow {
+			continue
+		}
+		event := PowerEvent{At: at, Kind: kind, Charge: -1}
+		reasonRe := pmsetWakeReasonRe
+		if kind == "sleep" {
+			reasonRe = pmsetSleepReasonRe
+		}
+		if m := reasonRe.FindStringSubmatch(line); m != nil {
+			event.Reason = strings.TrimSpace(pmsetChargeRe.ReplaceAllString(m[1], ""))
+		}
+		if m := pmsetChargeRe.FindStringSubmatch(line); m != nil {
+			event.Charge, _ = strconv.Atoi(m[1])
+		}
+		events = append(events, event)
+	}
+	slices.Reverse(events)
+	return events[:min(len(events), powerEventsLimit)]
+}
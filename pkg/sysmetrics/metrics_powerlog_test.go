@@ -0,0 +1,31 @@
+package sysmetrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePmsetLog(t *testing.T) {
+	out := `Time stamp                Domain              Message                                                                 Duration  Delay
+2026-03-10 20:00:00 +0000 Sleep               	Entering Sleep state due to 'Idle Sleep': Using AC (Charge:100%)	600 secs
+2026-03-11 23:10:02 +0000 Sleep               	Entering Sleep state due to 'Clamshell Sleep':TCPKeepAlive=active Using Batt (Charge:85%)	3600 secs
+2026-03-11 23:10:05 +0000 Assertions          	PID 123(Safari) Released PreventUserIdleSystemSleep
+2026-03-12 03:00:00 +0000 DarkWake            	DarkWake from Deep Idle [CDN] : due to RTC/Maintenance Using BATT (Charge:84%) 	45 secs
+2026-03-12 08:00:00 +0000 Wake                	Wake from Deep Idle [CDNVA] : due to EC.LidOpen/Lid Open Using BATT (Charge:79%)
+`
+	now := time.Date(2026, 3, 12, 9, 0, 0, 0, time.UTC)
+	got := parsePmsetLog(out, now)
+	want := []PowerEvent{
+		{At: time.Date(2026, 3, 12, 8, 0, 0, 0, time.UTC), Kind: "wake", Reason: "EC.LidOpen/Lid Open", Charge: 79},
+		{At: time.Date(2026, 3, 12, 3, 0, 0, 0, time.UTC), Kind: "darkwake", Reason: "RTC/Maintenance", Charge: 84},
+		{At: time.Date(2026, 3, 11, 23, 10, 2, 0, time.UTC), Kind: "sleep", Reason: "Clamshell Sleep", Charge: 85},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].At.Equal(want[i].At) || got[i].Kind != want[i].Kind || got[i].Reason != want[i].Reason || got[i].Charge != want[i].Charge {
+			t.Fatalf("event %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
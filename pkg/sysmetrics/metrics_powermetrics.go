@@ -0,0 +1,128 @@
+package sysmetrics
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// powermetrics may require root; without it, the runner set with
+// SetPowermetricsRunner runs it. One invocation samples every sampler the
+// status view reads so GPU, cluster, and power readings share a single fork.
+var (
+	gpuActiveResidencyRe = regexp.MustCompile(`GPU HW active residency:\s+([\d.]+)%`)
+	gpuIdleResidencyRe   = regexp.MustCompile(`GPU idle residency:\s+([\d.]+)%`)
+	clusterFrequencyRe   = regexp.MustCompile(`(?m)^([EP])\d*-Cluster HW active frequency:\s+([\d.]+)\s*MHz`)
+	componentPowerRe     = regexp.MustCompile(`(?m)^(CPU|GPU|ANE) Power:\s+([\d.]+)\s*mW`)
+	combinedPowerRe      = regexp.MustCompile(`(?m)^Combined Power \(CPU \+ GPU \+ ANE\):\s+([\d.]+)\s*mW`)
+)
+
+// PowermetricsArgs is the one powermetrics invocation the collector reads:
+// every sampler the status view uses, so GPU, cluster, and power readings
+// share a single fork.
+var PowermetricsArgs = []string{"--samplers", "cpu_power,gpu_power,ane_power", "-i", "500", "-n", "1"}
+
+type powermetricsSample struct {
+	gpuActive      float64            // GPU active residency percent, -1 when unavailable.
+	clusterFreqMHz map[string]float64 // Mean active frequency keyed by cluster kind ("P", "E").
+	power          PowerStatus        // Instantaneous draw; zero when the power samplers are unavailable.
+}
+
+func (c *Collector) samplePowermetrics(now time.Time) powermetricsSample {
+	if !c.lastPowermetricsAt.IsZero() && now.Sub(c.lastPowermetricsAt) < macGPUUsageTTL {
+		return c.cachedPowermetrics
+	}
+
+	sample := readPowermetrics(c.powermetricsRunner)
+	c.cachedPowermetrics = sample
+	c.lastPowermetricsAt = now
+	return sample
+}
+
+// SetPowermetricsRunner sets how a collector that is not running as root
+// gets powermetrics output run with PowermetricsArgs, such as through a
+// privileged helper. Without one it runs powermetrics itself.
+func (c *Collector) SetPowermetricsRunner(run func(context.Context) (string, error)) {
+	c.powermetricsRunner = run
+}
+
+func readPowermetrics(run func(context.Context) (string, error)) powermetricsSample {
+	ctx, cancel := context.WithTimeout(context.Background(), powermetricsTimeout)
+	defer cancel()
+
+	var out string
+	var err error
+	if os.Geteuid() != 0 && run != nil {
+		out, err = run(ctx)
+	} else {
+		out, err = runCmd(ctx, "powermetrics", PowermetricsArgs...)
+	}
+	if err != nil {
+		return powermetricsSample{gpuActive: -1}
+	}
+	return parsePowermetrics(out)
+}
+
+func parsePowermetrics(out string) powermetricsSample {
+	sample := powermetricsSample{gpuActive: -1}
+
+	// Parse "GPU HW active residency: X.XX%".
+	if m := gpuActiveResidencyRe.FindStringSubmatch(out); len(m) >= 2 {
+		if usage, err := strconv.ParseFloat(m[1], 64); err == nil {
+			sample.gpuActive = usage
+		}
+	} else if m := gpuIdleResidencyRe.FindStringSubmatch(out); len(m) >= 2 {
+		// Fallback: parse idle residency and derive active.
+		if idle, err := strconv.ParseFloat(m[1], 64); err == nil {
+			sample.gpuActive = 100.0 - idle
+		}
+	}
+
+	// Pro/Max/Ultra chips report several clusters of one kind (P0, P1, ...);
+	// average them so the card shows one frequency per kind.
+	sums := map[string]float64{}
+	counts := map[string]int{}
+	for _, m := range clusterFrequencyRe.FindAllStringSubmatch(out, -1) {
+		freq, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			continue
+		}
+		sums[m[1]] += freq
+		counts[m[1]]++
+	}
+	if len(sums) > 0 {
+		sample.clusterFreqMHz = make(map[string]float64, len(sums))
+		for kind, sum := range sums {
+			sample.clusterFreqMHz[kind] = sum / float64(counts[kind])
+		}
+	}
+
+	for _, m := range componentPowerRe.FindAllStringSubmatch(out, -1) {
+		mw, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			continue
+		}
+		switch m[1] {
+		case "CPU":
+			sample.power.CPUWatts = mw / 1000
+		case "GPU":
+			sample.power.GPUWatts = mw / 1000
+		case "ANE":
+			sample.power.ANEWatts = mw / 1000
+		}
+	}
+	if m := combinedPowerRe.FindStringSubmatch(out); len(m) >= 2 {
+		if mw, err := strconv.ParseFloat(m[1], 64); err == nil {
+			sample.power.PackageWatts = mw / 1000
+		}
+	}
+	if sample.power.PackageWatts == 0 {
+		sample.power.PackageWatts = sample.power.CPUWatts + sample.power.GPUWatts + sample.power.ANEWatts
+	}
+	if sample.power.PackageWatts > 0 {
+		sample.power.Source = "powermetrics"
+	}
+	return sample
+}
@@ -0,0 +1,327 @@
+package sysmetrics
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	collectProcessesFunc     = collectProcesses
+	collectProcessEnergyFunc = collectProcessEnergy
+)
+
+// Process sort keys for the Processes card and the top_processes JSON field.
+const (
+	ProcessSortCPU    = "cpu"
+	ProcessSortMemory = "mem"
+	ProcessSortEnergy = "energy"
+)
+
+var ProcessSortKeys = []string{ProcessSortCPU, ProcessSortMemory, ProcessSortEnergy}
+
+func ValidProcessSort(key string) bool {
+	return slices.Contains(ProcessSortKeys, key)
+}
+
+func NextProcessSort(key string) string {
+	idx := slices.Index(ProcessSortKeys, key)
+	return ProcessSortKeys[(idx+1)%len(ProcessSortKeys)]
+}
+
+func collectProcesses() ([]ProcessInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if runtime.GOOS != "darwin" {
+		if runtime.GOOS != "linux" || !commandExists("ps") {
+			return nil, nil
+		}
+		// procps reads /proc; comm is the kernel task name, like macOS -c.
+		out, err := runCmd(ctx, "ps", "-eo", "pid=,ppid=,pcpu=,pmem=,rss=,comm=", "--sort=-pcpu")
+		if err != nil {
+			return nil, err
+		}
+		return parseProcessOutput(out), nil
+	}
+
+	out, err := runCmd(ctx, "ps", "-Aceo", "pid=,ppid=,pcpu=,pmem=,rss=,comm=", "-r")
+	if err != nil {
+		out, err = runCmd(ctx, "ps", "aux")
+		if err != nil {
+			return nil, err
+		}
+		return parsePsAuxOutput(out), nil
+	}
+	return parseProcessOutput(out), nil
+}
+
+func parseProcessOutput(raw string) []ProcessInfo {
+	procs := make([]ProcessInfo, 0, strings.Count(raw, "\n"))
+	for line := range strings.Lines(strings.TrimSpace(raw)) {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil || pid <= 0 {
+			continue
+		}
+		ppid, _ := strconv.Atoi(fields[1])
+		cpuVal, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			continue
+		}
+		memVal, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			continue
+		}
+
+		rssBytes := uint64(0)
+		commandStart := 4
+		if len(fields) >= 6 {
+			if rssKB, err := strconv.ParseUint(fields[4], 10, 64); err == nil {
+				rssBytes = rssKB * 1024
+				commandStart = 5
+			}
+		}
+
+		command := strings.Join(fields[commandStart:], " ")
+		if command == "" {
+			continue
+		}
+		procs = append(procs, ProcessInfo{
+			PID:         pid,
+			PPID:        ppid,
+			Name:        processNameFromCommand(command),
+			Command:     command,
+			CPU:         cpuVal,
+			Memory:      memVal,
+			MemoryBytes: rssBytes,
+		})
+	}
+	return procs
+}
+
+// parsePsAuxOutput parses the fallback "ps aux" format.
+// Columns: USER PID %CPU %MEM VSZ RSS TT STAT STARTED TIME COMMAND
+func parsePsAuxOutput(raw string) []ProcessInfo {
+	procs := make([]ProcessInfo, 0, strings.Count(raw, "\n"))
+	first := true
+	for line := range strings.Lines(strings.TrimSpace(raw)) {
+		if first {
+			first = false
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 11 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[1])
+		if err != nil || pid <= 0 {
+			continue
+		}
+		cpuVal, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			continue
+		}
+		memVal, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			continue
+		}
+		rssKB, err := strconv.ParseUint(fields[5], 10, 64)
+		if err != nil {
+			rssKB = 0
+		}
+		command := strings.Join(fields[10:], " ")
+		if command == "" {
+			continue
+		}
+		procs = append(procs, ProcessInfo{
+			PID:         pid,
+			PPID:        0,
+			Name:        processNameFromCommand(command),
+			Command:     command,
+			CPU:         cpuVal,
+			Memory:      memVal,
+			MemoryBytes: rssKB * 1024,
+		})
+	}
+	return procs
+}
+
+func processNameFromCommand(command string) string {
+	name := command
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	if spIdx := strings.Index(name, " "); spIdx >= 0 {
+		name = name[:spIdx]
+	}
+	return name
+}
+
+// collectProcessEnergy samples Activity Monitor's energy impact per PID.
+// top needs two samples to report a delta, so this only runs on the full
+// refresh and the result is merged into faster process snapshots.
+func collectProcessEnergy() map[int]float64 {
+	if runtime.GOOS != "darwin" || !commandExists("top") {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := runCmd(ctx, "top", "-l", "2", "-s", "0", "-o", "power", "-n", "20", "-stats", "pid,power")
+	if err != nil {
+		return nil
+	}
+	return parseTopPower(out)
+}
+
+// parseTopPower reads the last "PID POWER" table in top's logging output;
+// the first sample always reports zero.
+func parseTopPower(raw string) map[int]float64 {
+	var energy map[int]float64
+	for line := range strings.Lines(raw) {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "PID" && fields[1] == "POWER" {
+			energy = make(map[int]float64)
+			continue
+		}
+		if energy == nil || len(fields) != 2 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		if power, err := strconv.ParseFloat(fields[1], 64); err == nil && power > 0 {
+			energy[pid] = power
+		}
+	}
+	return energy
+}
+
+func applyProcessEnergy(processes []ProcessInfo, energy map[int]float64) {
+	if len(energy) == 0 {
+		return
+	}
+	for i := range processes {
+		processes[i].Energy = energy[processes[i].PID]
+	}
+}
+
+// topProcessLimit is how many processes a snapshot keeps. The card shows
+// the first processCardRows; the focus view lists them all.
+const topProcessLimit = 15
+
+// topProcesses returns the limit highest-ranked processes for sortKey.
+func topProcesses(processes []ProcessInfo, limit int, sortKey string) []ProcessInfo {
+	if limit <= 0 || len(processes) == 0 {
+		return nil
+	}
+
+	h := &processHeap{sortKey: sortKey}
+	heap.Init(h)
+	for _, proc := range processes {
+		if h.Len() < limit {
+			heap.Push(h, proc)
+			continue
+		}
+		if processRanksBy(sortKey, proc, h.procs[0]) {
+			heap.Pop(h)
+			heap.Push(h, proc)
+		}
+	}
+
+	top := make([]ProcessInfo, h.Len())
+	for i := range slices.Backward(top) {
+		top[i] = heap.Pop(h).(ProcessInfo)
+	}
+	return top
+}
+
+func FormatProcessLabel(proc ProcessInfo) string {
+	if proc.Name != "" {
+		return fmt.Sprintf("%s (%d)", proc.Name, proc.PID)
+	}
+	return fmt.Sprintf("pid %d", proc.PID)
+}
+
+// processRanksBy orders by the sort key first, then falls back to the CPU
+// ranking so ties (e.g. no energy data on Linux) stay stable.
+func processRanksBy(sortKey string, a, b ProcessInfo) bool {
+	switch sortKey {
+	case ProcessSortMemory:
+		if a.MemoryBytes != b.MemoryBytes {
+			return a.MemoryBytes > b.MemoryBytes
+		}
+		if a.Memory != b.Memory {
+			return a.Memory > b.Memory
+		}
+	case ProcessSortEnergy:
+		if a.Energy != b.Energy {
+			return a.Energy > b.Energy
+		}
+	}
+	return processRanksBefore(a, b)
+}
+
+// SortProcesses reorders an already-collected top list for display, so a
+// sort change shows immediately instead of on the next process refresh.
+func SortProcesses(processes []ProcessInfo, sortKey string) []ProcessInfo {
+	sorted := slices.Clone(processes)
+	slices.SortStableFunc(sorted, func(a, b ProcessInfo) int {
+		switch {
+		case processRanksBy(sortKey, a, b):
+			return -1
+		case processRanksBy(sortKey, b, a):
+			return 1
+		}
+		return 0
+	})
+	return sorted
+}
+
+func processRanksBefore(a, b ProcessInfo) bool {
+	if a.CPU != b.CPU {
+		return a.CPU > b.CPU
+	}
+	if a.Memory != b.Memory {
+		return a.Memory > b.Memory
+	}
+	return a.PID < b.PID
+}
+
+type processHeap struct {
+	procs   []ProcessInfo
+	sortKey string
+}
+
+func (h processHeap) Len() int { return len(h.procs) }
+
+func (h processHeap) Less(i, j int) bool {
+	return processRanksBy(h.sortKey, h.procs[j], h.procs[i])
+}
+
+func (h processHeap) Swap(i, j int) {
+	h.procs[i], h.procs[j] = h.procs[j], h.procs[i]
+}
+
+func (h *processHeap) Push(x any) {
+	h.procs = append(h.procs, x.(ProcessInfo))
+}
+
+func (h *processHeap) Pop() any {
+	old := h.procs
+	n := len(old)
+	x := old[n-1]
+	h.procs = old[:n-1]
+	return x
+}
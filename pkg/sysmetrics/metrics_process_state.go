@@ -0,0 +1,63 @@
+package sysmetrics
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const stuckProcessLimit = 10
+
+// collectStuckProcesses lists zombies and processes blocked in
+// uninterruptible wait, usually on a hung disk or network mount.
+func collectStuckProcesses() []StuckProcess {
+	if !commandExists("ps") {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, err := runCmd(ctx, "ps", "-Aeo", "pid=,ppid=,stat=,comm=")
+	if err != nil {
+		return nil
+	}
+	return parseStuckProcesses(out)
+}
+
+// parseStuckProcesses reads `ps -Aeo pid=,ppid=,stat=,comm=`. The first
+// letter of STAT is the state: Z for a zombie, D on Linux and U on macOS
+// for uninterruptible wait.
+func parseStuckProcesses(out string) []StuckProcess {
+	var stuck []StuckProcess
+	for line := range strings.Lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		var state string
+		switch fields[2][0] {
+		case 'Z':
+			state = "zombie"
+		case 'D', 'U':
+			state = "uninterruptible"
+		default:
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		stuck = append(stuck, StuckProcess{
+			PID:   pid,
+			PPID:  ppid,
+			Name:  filepath.Base(strings.Join(fields[3:], " ")),
+			State: state,
+		})
+		if len(stuck) == stuckProcessLimit {
+			break
+		}
+	}
+	return stuck
+}
@@ -0,0 +1,24 @@
+package sysmetrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStuckProcesses(t *testing.T) {
+	out := `    1     0 Ss   /sbin/launchd
+  412     1 Z    (defunct)
+  530     1 U    /sbin/mount_nfs
+  610   530 D+   kworker/u8:2
+  700     1 R+   /usr/bin/top
+`
+	got := parseStuckProcesses(out)
+	want := []StuckProcess{
+		{PID: 412, PPID: 1, Name: "(defunct)", State: "zombie"},
+		{PID: 530, PPID: 1, Name: "mount_nfs", State: "uninterruptible"},
+		{PID: 610, PPID: 530, Name: "u8:2", State: "uninterruptible"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseStuckProcesses() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+package sysmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	publicIPCacheTTL = 30 * time.Minute
+	publicIPTimeout  = 3 * time.Second
+)
+
+var (
+	publicIPv4Endpoint = "https://ipinfo.io/json"
+	publicIPv6Endpoint = "https://api6.ipify.org"
+	httpGetFunc        = httpGet
+)
+
+type ipinfoResponse struct {
+	IP      string `json:"ip"`
+	City    string `json:"city"`
+	Region  string `json:"region"`
+	Country string `json:"country"`
+	Org     string `json:"org"` // "AS15169 Google LLC"
+}
+
+func httpGet(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+}
+
+// EnablePublicIP opts in to resolving the public address through a third
+// party service. It is off by default because the lookup reveals the
+// machine's address to that service.
+func (c *Collector) EnablePublicIP() {
+	c.publicIPEnabled = true
+}
+
+// collectPublicIP refreshes at most every publicIPCacheTTL, or as soon as
+// the VPN state changes since that is when the answer moves.
+func (c *Collector) collectPublicIP(now time.Time, vpn VPNStatus) PublicIPStatus {
+	if !c.publicIPEnabled {
+		return PublicIPStatus{}
+	}
+	key := vpnStateKey(vpn)
+	if !c.lastPublicIPAt.IsZero() && now.Sub(c.lastPublicIPAt) < publicIPCacheTTL && key == c.publicIPVPNKey {
+		return c.cachedPublicIP
+	}
+	c.lastPublicIPAt = now
+	c.publicIPVPNKey = key
+	if status, ok := lookupPublicIP(now); ok {
+		c.cachedPublicIP = status
+	}
+	return c.cachedPublicIP
+}
+
+func lookupPublicIP(now time.Time) (PublicIPStatus, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), publicIPTimeout)
+	defer cancel()
+
+	body, err := httpGetFunc(ctx, publicIPv4Endpoint)
+	if err != nil {
+		return PublicIPStatus{}, false
+	}
+	var info ipinfoResponse
+	if err := json.Unmarshal(body, &info); err != nil || info.IP == "" {
+		return PublicIPStatus{}, false
+	}
+	status := PublicIPStatus{
+		IPv4:      info.IP,
+		City:      info.City,
+		Region:    info.Region,
+		Country:   info.Country,
+		CheckedAt: now,
+	}
+	if asn, org, found := strings.Cut(info.Org, " "); found && strings.HasPrefix(asn, "AS") {
+		status.ASN, status.Org = asn, org
+	} else {
+		status.Org = info.Org
+	}
+	// Most networks have no IPv6 route; a failure here is expected.
+	if body, err := httpGetFunc(ctx, publicIPv6Endpoint); err == nil {
+		if ip := strings.TrimSpace(string(body)); strings.Contains(ip, ":") {
+			status.IPv6 = ip
+		}
+	}
+	return status, true
+}
+
+func vpnStateKey(vpn VPNStatus) string {
+	var b strings.Builder
+	for _, conn := range vpn.Connections {
+		b.WriteString(conn.Name + ";")
+	}
+	b.WriteString(strings.Join(vpn.Tunnels, ";"))
+	if ts := vpn.Tailscale; ts != nil {
+		b.WriteString("|" + ts.State + "|" + ts.ExitNode)
+	}
+	return b.String()
+}
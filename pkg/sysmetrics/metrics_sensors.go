@@ -0,0 +1,126 @@
+package sysmetrics
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/sensors"
+)
+
+const (
+	sensorReadTimeout = 500 * time.Millisecond
+	SensorHistorySize = 60
+	// Readings outside this range are disconnected or uncalibrated probes.
+	sensorMinValidTemp = 1.0
+	sensorMaxValidTemp = 150.0
+)
+
+// gopsutil reads Apple Silicon HID sensors and Intel SMC keys through IOKit
+// (no cgo, no exec) and hwmon on Linux.
+var sensorTemperaturesFunc = sensors.TemperaturesWithContext
+
+// sensorGroup maps raw sensor keys onto the handful of components users
+// recognize. Groups are matched and displayed in order; the first hit wins.
+type sensorGroup struct {
+	label    string
+	keywords []string
+}
+
+var sensorGroups = []sensorGroup{
+	{label: "CPU", keywords: []string{"tdie", "acc mtr", "tc0", "cpu", "coretemp", "k10temp", "zenpower", "package", "tctl"}},
+	{label: "GPU", keywords: []string{"gpu", "tg0", "amdgpu", "radeon", "nouveau"}},
+	{label: "SSD", keywords: []string{"nand", "nvme", "ssd", "drivetemp", "th0"}},
+	{label: "Battery", keywords: []string{"battery", "gas gauge", "bat0", "bat1"}},
+}
+
+func classifySensor(key string) string {
+	lower := strings.ToLower(key)
+	for _, group := range sensorGroups {
+		for _, keyword := range group.keywords {
+			if strings.Contains(lower, keyword) {
+				return group.label
+			}
+		}
+	}
+	return ""
+}
+
+// groupSensorReadings reduces raw sensors to one reading per component,
+// keeping the hottest probe since that is the one that throttles.
+func groupSensorReadings(stats []sensors.TemperatureStat) []SensorReading {
+	hottest := make(map[string]sensors.TemperatureStat)
+	counts := make(map[string]int)
+	for _, stat := range stats {
+		if stat.Temperature < sensorMinValidTemp || stat.Temperature > sensorMaxValidTemp {
+			continue
+		}
+		label := classifySensor(stat.SensorKey)
+		if label == "" {
+			continue
+		}
+		counts[label]++
+		if prev, ok := hottest[label]; !ok || stat.Temperature > prev.Temperature {
+			hottest[label] = stat
+		}
+	}
+
+	readings := make([]SensorReading, 0, len(hottest))
+	for _, group := range sensorGroups {
+		stat, ok := hottest[group.label]
+		if !ok {
+			continue
+		}
+		note := stat.SensorKey
+		if counts[group.label] > 1 {
+			note += " (hottest of " + strconv.Itoa(counts[group.label]) + ")"
+		}
+		readings = append(readings, SensorReading{
+			Label: group.label,
+			Value: stat.Temperature,
+			Unit:  "°C",
+			Note:  note,
+		})
+	}
+	return readings
+}
+
+func (c *Collector) collectSensors() ([]SensorReading, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sensorReadTimeout)
+	defer cancel()
+
+	stats, err := sensorTemperaturesFunc(ctx)
+	// gopsutil returns partial readings alongside per-sensor warnings.
+	readings := groupSensorReadings(stats)
+	if len(readings) == 0 {
+		return nil, err
+	}
+
+	if c.sensorHistory == nil {
+		c.sensorHistory = make(map[string]*RingBuffer)
+	}
+	for i := range readings {
+		buf, ok := c.sensorHistory[readings[i].Label]
+		if !ok {
+			buf = NewRingBuffer(SensorHistorySize)
+			c.sensorHistory[readings[i].Label] = buf
+		}
+		buf.Add(readings[i].Value)
+		readings[i].History = buf.Slice()
+	}
+	return readings, nil
+}
+
+// applySensorTemps fills the CPU/GPU temperatures the CPU card and the health
+// score read, without overriding values a platform collector already set.
+func applySensorTemps(thermal *ThermalStatus, readings []SensorReading) {
+	for _, r := range readings {
+		switch {
+		case r.Label == "CPU" && thermal.CPUTemp == 0:
+			thermal.CPUTemp = r.Value
+		case r.Label == "GPU" && thermal.GPUTemp == 0:
+			thermal.GPUTemp = r.Value
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package sysmetrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/sensors"
+)
+
+func TestGroupSensorReadingsKeepsHottestPerComponent(t *testing.T) {
+	readings := groupSensorReadings([]sensors.TemperatureStat{
+		{SensorKey: "PMU tdie1", Temperature: 48.5},
+		{SensorKey: "PMU tdie3", Temperature: 61.2},
+		{SensorKey: "GPU MTR Temp Sensor1", Temperature: 44},
+		{SensorKey: "NAND CH0 temp", Temperature: 38},
+		{SensorKey: "gas gauge battery", Temperature: 31},
+		{SensorKey: "PMU tcal", Temperature: 52},
+		{SensorKey: "PMU tdie5", Temperature: -127},
+	})
+
+	want := []SensorReading{
+		{Label: "CPU", Value: 61.2, Unit: "°C", Note: "PMU tdie3 (hottest of 2)"},
+		{Label: "GPU", Value: 44, Unit: "°C", Note: "GPU MTR Temp Sensor1"},
+		{Label: "SSD", Value: 38, Unit: "°C", Note: "NAND CH0 temp"},
+		{Label: "Battery", Value: 31, Unit: "°C", Note: "gas gauge battery"},
+	}
+	if len(readings) != len(want) {
+		t.Fatalf("readings = %+v, want %d groups", readings, len(want))
+	}
+	for i := range want {
+		r := readings[i]
+		if r.Label != want[i].Label || r.Value != want[i].Value || r.Note != want[i].Note {
+			t.Errorf("readings[%d] = %+v, want %+v", i, r, want[i])
+		}
+	}
+}
+
+func TestClassifySensorLinuxHwmon(t *testing.T) {
+	cases := map[string]string{
+		"coretemp_package_id_0": "CPU",
+		"k10temp_tctl":          "CPU",
+		"amdgpu_edge":           "GPU",
+		"nvme_composite":        "SSD",
+		"acpitz":                "",
+	}
+	for key, want := range cases {
+		if got := classifySensor(key); got != want {
+			t.Errorf("classifySensor(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestCollectSensorsKeepsHistoryAndFillsThermal(t *testing.T) {
+	old := sensorTemperaturesFunc
+	defer func() { sensorTemperaturesFunc = old }()
+
+	temp := 50.0
+	sensorTemperaturesFunc = func(context.Context) ([]sensors.TemperatureStat, error) {
+		return []sensors.TemperatureStat{{SensorKey: "PMU tdie1", Temperature: temp}}, nil
+	}
+
+	c := &Collector{}
+	for _, v := range []float64{50, 55, 70} {
+		temp = v
+		if _, err := c.collectSensors(); err != nil {
+			t.Fatalf("collectSensors() error = %v", err)
+		}
+	}
+	readings, _ := c.collectSensors()
+	if len(readings) != 1 || len(readings[0].History) != 4 || readings[0].History[2] != 70 {
+		t.Fatalf("readings = %+v, want CPU history of four samples", readings)
+	}
+
+	thermal := ThermalStatus{GPUTemp: 40}
+	applySensorTemps(&thermal, []SensorReading{{Label: "CPU", Value: 70}, {Label: "GPU", Value: 55}})
+	if thermal.CPUTemp != 70 || thermal.GPUTemp != 40 {
+		t.Fatalf("thermal = %+v, want CPU filled and existing GPU kept", thermal)
+	}
+}
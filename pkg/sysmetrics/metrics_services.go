@@ -0,0 +1,166 @@
+package sysmetrics
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// brew services shells out to Ruby and takes a second or more.
+	servicesCmdTimeout = 5 * time.Second
+	// Services at or above this CPU percent are listed even when healthy.
+	ServiceCPUThreshold = 20.0
+)
+
+// Service managers a ServiceStatus can come from.
+const (
+	ServiceManagerBrew    = "brew"
+	ServiceManagerLaunchd = "launchd"
+)
+
+// collectServices lists Homebrew services and, on macOS, the user's own
+// launchd jobs that have failed or are busy. Apple's com.apple.* jobs exit
+// non-zero routinely and are left out.
+func collectServices() []ServiceStatus {
+	var services []ServiceStatus
+	if commandExists("brew") {
+		ctx, cancel := context.WithTimeout(context.Background(), servicesCmdTimeout)
+		if out, err := runCmd(ctx, "brew", "services", "list", "--json"); err == nil {
+			services = append(services, parseBrewServices(out)...)
+		}
+		cancel()
+	}
+	if runtime.GOOS == "darwin" && commandExists("launchctl") {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		if out, err := runCmd(ctx, "launchctl", "list"); err == nil {
+			services = append(services, parseLaunchctlList(out)...)
+		}
+		cancel()
+	}
+	if len(services) == 0 {
+		return nil
+	}
+	if commandExists("ps") {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		if out, err := runCmd(ctx, "ps", "-Aeo", "pid=,pcpu="); err == nil {
+			applyServiceCPU(services, parsePSCPU(out))
+		}
+		cancel()
+	}
+	return notableServices(services)
+}
+
+// parseBrewServices reads `brew services list --json`. Status is "started",
+// "stopped", "error", "scheduled", "none", or "other".
+func parseBrewServices(out string) []ServiceStatus {
+	var entries []struct {
+		Name     string `json:"name"`
+		Status   string `json:"status"`
+		PID      int    `json:"pid"`
+		ExitCode int    `json:"exit_code"`
+	}
+	if json.Unmarshal([]byte(out), &entries) != nil {
+		return nil
+	}
+	var services []ServiceStatus
+	for _, e := range entries {
+		state := "stopped"
+		switch {
+		case e.Status == "error":
+			state = "error"
+		case e.PID > 0:
+			state = "running"
+		}
+		services = append(services, ServiceStatus{
+			Name:     e.Name,
+			Manager:  ServiceManagerBrew,
+			State:    state,
+			PID:      e.PID,
+			ExitCode: e.ExitCode,
+		})
+	}
+	return services
+}
+
+// parseLaunchctlList reads `launchctl list` in the user's domain:
+//
+//	PID	Status	Label
+//	-	78	com.example.agent
+//	812	0	com.example.helper
+//
+// Status is the last exit code, or the negated signal that killed the job.
+// Homebrew's own jobs are reported by parseBrewServices instead.
+func parseLaunchctlList(out string) []ServiceStatus {
+	var services []ServiceStatus
+	for line := range strings.Lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] == "PID" {
+			continue
+		}
+		label := fields[2]
+		if strings.HasPrefix(label, "com.apple.") || strings.HasPrefix(label, "homebrew.mxcl.") {
+			continue
+		}
+		status, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		svc := ServiceStatus{Name: label, Manager: ServiceManagerLaunchd, State: "stopped", ExitCode: status}
+		if pid, err := strconv.Atoi(fields[0]); err == nil {
+			svc.PID = pid
+			svc.State = "running"
+		} else if status != 0 {
+			svc.State = "error"
+		}
+		services = append(services, svc)
+	}
+	return services
+}
+
+// parsePSCPU reads `ps -Aeo pid=,pcpu=` into CPU percent by pid.
+func parsePSCPU(out string) map[int]float64 {
+	usage := make(map[int]float64)
+	for line := range strings.Lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		cpu, err2 := strconv.ParseFloat(fields[1], 64)
+		if err1 == nil && err2 == nil {
+			usage[pid] = cpu
+		}
+	}
+	return usage
+}
+
+func applyServiceCPU(services []ServiceStatus, usage map[int]float64) {
+	for i, svc := range services {
+		if svc.PID > 0 {
+			services[i].CPU = usage[svc.PID]
+		}
+	}
+}
+
+// notableServices keeps failed and busy services, failures first.
+func notableServices(services []ServiceStatus) []ServiceStatus {
+	services = slices.DeleteFunc(services, func(svc ServiceStatus) bool {
+		return svc.State != "error" && svc.CPU < ServiceCPUThreshold
+	})
+	slices.SortStableFunc(services, func(a, b ServiceStatus) int {
+		if (a.State == "error") != (b.State == "error") {
+			if a.State == "error" {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(b.CPU, a.CPU)
+	})
+	return services
+}
@@ -0,0 +1,56 @@
+package sysmetrics
+
+import "testing"
+
+func TestParseBrewServices(t *testing.T) {
+	out := `[
+  {"name":"postgresql@16","service_name":"homebrew.mxcl.postgresql@16","running":false,"loaded":true,"status":"error","exit_code":1,"pid":null},
+  {"name":"redis","service_name":"homebrew.mxcl.redis","running":true,"loaded":true,"status":"started","pid":812,"exit_code":0},
+  {"name":"nginx","running":false,"loaded":false,"status":"none"}
+]`
+	got := parseBrewServices(out)
+	if len(got) != 3 {
+		t.Fatalf("got %d services, want 3: %+v", len(got), got)
+	}
+	if got[0].State != "error" || got[0].ExitCode != 1 || got[0].Manager != ServiceManagerBrew {
+		t.Fatalf("postgresql = %+v", got[0])
+	}
+	if got[1].State != "running" || got[1].PID != 812 {
+		t.Fatalf("redis = %+v", got[1])
+	}
+	if got[2].State != "stopped" {
+		t.Fatalf("nginx = %+v", got[2])
+	}
+}
+
+func TestParseLaunchctlList(t *testing.T) {
+	out := "PID\tStatus\tLabel\n" +
+		"-\t78\tcom.example.sync\n" +
+		"4410\t0\tcom.example.helper\n" +
+		"-\t0\tcom.example.idle\n" +
+		"-\t-9\tcom.apple.Safari.History\n" +
+		"-\t1\thomebrew.mxcl.postgresql@16\n"
+	got := parseLaunchctlList(out)
+	if len(got) != 3 {
+		t.Fatalf("got %d jobs, want 3: %+v", len(got), got)
+	}
+	if got[0].Name != "com.example.sync" || got[0].State != "error" || got[0].ExitCode != 78 {
+		t.Fatalf("sync = %+v", got[0])
+	}
+	if got[1].State != "running" || got[1].PID != 4410 || got[2].State != "stopped" {
+		t.Fatalf("jobs = %+v", got)
+	}
+}
+
+func TestNotableServicesKeepsFailedAndBusy(t *testing.T) {
+	services := []ServiceStatus{
+		{Name: "quiet", State: "running", PID: 1},
+		{Name: "busy", State: "running", PID: 2},
+		{Name: "broken", State: "error", ExitCode: 1},
+	}
+	applyServiceCPU(services, parsePSCPU("  1   0.3\n  2  87.5\n"))
+	got := notableServices(services)
+	if len(got) != 2 || got[0].Name != "broken" || got[1].Name != "busy" || got[1].CPU != 87.5 {
+		t.Fatalf("notable = %+v", got)
+	}
+}
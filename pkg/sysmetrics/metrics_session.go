@@ -0,0 +1,74 @@
+package sysmetrics
+
+import (
+	"context"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/host"
+)
+
+var hostUsersFunc = host.Users
+
+// collectSession reads who is logged in and, on macOS, why and when the
+// machine last woke from sleep.
+func collectSession() SessionInfo {
+	var info SessionInfo
+	if users, err := hostUsersFunc(); err == nil {
+		info.Users, info.Sessions = summarizeUsers(users)
+	}
+	if runtime.GOOS == "darwin" && commandExists("sysctl") {
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		// kern.wakereason is empty until the first wake after boot.
+		if out, err := runCmd(ctx, "sysctl", "-n", "kern.wakereason", "kern.waketime"); err == nil {
+			info.WakeReason, info.WakeTime = parseWakeSysctl(out)
+		}
+	}
+	return info
+}
+
+// summarizeUsers returns the distinct user names, in login order, and the
+// number of sessions (terminals) they hold.
+func summarizeUsers(users []host.UserStat) ([]string, int) {
+	var names []string
+	for _, u := range users {
+		if u.User != "" && !slices.Contains(names, u.User) {
+			names = append(names, u.User)
+		}
+	}
+	return names, len(users)
+}
+
+// parseWakeSysctl reads `sysctl -n kern.wakereason kern.waketime`:
+//
+//	EC.LidOpen (User)
+//	{ sec = 1773302400, usec = 120044 } Thu Mar 12 08:00:00 2026
+func parseWakeSysctl(out string) (string, time.Time) {
+	var reason string
+	var woke time.Time
+	for line := range strings.Lines(out) {
+		text := strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(text, "{ sec = "); ok {
+			secText, _, _ := strings.Cut(rest, ",")
+			if secs, err := strconv.ParseInt(secText, 10, 64); err == nil && secs > 0 {
+				woke = time.Unix(secs, 0)
+			}
+			continue
+		}
+		if reason == "" {
+			reason = text
+		}
+	}
+	return reason, woke
+}
+
+func bootTime(secs uint64) time.Time {
+	if secs == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(secs), 0)
+}
@@ -0,0 +1,28 @@
+package sysmetrics
+
+import (
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/host"
+)
+
+func TestParseWakeSysctl(t *testing.T) {
+	reason, woke := parseWakeSysctl("EC.LidOpen (User)\n{ sec = 1773302400, usec = 120044 } Thu Mar 12 08:00:00 2026\n")
+	if reason != "EC.LidOpen (User)" || woke.Unix() != 1773302400 {
+		t.Fatalf("reason=%q woke=%v", reason, woke)
+	}
+	if reason, woke := parseWakeSysctl("\n{ sec = 0, usec = 0 } Thu Jan  1 00:00:00 1970\n"); reason != "" || !woke.IsZero() {
+		t.Fatalf("no wake yet: reason=%q woke=%v", reason, woke)
+	}
+}
+
+func TestSummarizeUsers(t *testing.T) {
+	names, sessions := summarizeUsers([]host.UserStat{
+		{User: "tw93", Terminal: "console"},
+		{User: "tw93", Terminal: "ttys001"},
+		{User: "admin", Terminal: "ttys002"},
+	})
+	if len(names) != 2 || names[0] != "tw93" || names[1] != "admin" || sessions != 3 {
+		t.Fatalf("names=%v sessions=%d", names, sessions)
+	}
+}
@@ -0,0 +1,218 @@
+package sysmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	smartHealthTTL  = 10 * time.Minute
+	smartCmdTimeout = 3 * time.Second
+	// NVMe percentage_used is an endurance estimate; past this the drive is
+	// near its rated write budget even if it still works.
+	smartWearDegraded = 90
+)
+
+// Disk health states, worst last.
+const (
+	DiskHealthOK       = "ok"
+	DiskHealthDegraded = "degraded"
+	DiskHealthFailing  = "failing"
+)
+
+type smartctlScan struct {
+	Devices []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"devices"`
+}
+
+type smartctlReport struct {
+	Device struct {
+		Name string `json:"name"`
+	} `json:"device"`
+	ModelName   string `json:"model_name"`
+	SmartStatus *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	Temperature struct {
+		Current float64 `json:"current"`
+	} `json:"temperature"`
+	NVMe *struct {
+		CriticalWarning         int    `json:"critical_warning"`
+		AvailableSpare          int    `json:"available_spare"`
+		AvailableSpareThreshold int    `json:"available_spare_threshold"`
+		PercentageUsed          int    `json:"percentage_used"`
+		MediaErrors             uint64 `json:"media_errors"`
+	} `json:"nvme_smart_health_information_log"`
+	ATA *struct {
+		Table []struct {
+			ID  int `json:"id"`
+			Raw struct {
+				Value uint64 `json:"value"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+}
+
+// smartctlFunc keeps stdout on non-zero exits: smartctl sets exit bits for
+// any SMART warning, which is exactly the output worth reporting.
+var smartctlFunc = func(ctx context.Context, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, "smartctl", args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(out) > 0 {
+		return string(out), nil
+	}
+	return string(out), err
+}
+
+// ATA attributes that count remapped or unreadable sectors.
+var smartBadSectorAttrs = map[int]string{
+	5:   "reallocated sectors",
+	197: "pending sectors",
+	198: "uncorrectable sectors",
+}
+
+func (c *Collector) collectDiskHealth(now time.Time) []DiskHealth {
+	if !c.lastSmartAt.IsZero() && now.Sub(c.lastSmartAt) < smartHealthTTL {
+		return c.cachedSmart
+	}
+	c.cachedSmart = readDiskHealth()
+	c.lastSmartAt = now
+	return c.cachedSmart
+}
+
+func readDiskHealth() []DiskHealth {
+	if commandExists("smartctl") {
+		if health := readSmartctlHealth(); len(health) > 0 {
+			return health
+		}
+	}
+	if runtime.GOOS == "darwin" && commandExists("diskutil") {
+		ctx, cancel := context.WithTimeout(context.Background(), smartCmdTimeout)
+		defer cancel()
+		if out, err := runCmd(ctx, "diskutil", "info", "disk0"); err == nil {
+			if health, ok := parseDiskutilSmartStatus(out); ok {
+				return []DiskHealth{health}
+			}
+		}
+	}
+	return nil
+}
+
+func readSmartctlHealth() []DiskHealth {
+	ctx, cancel := context.WithTimeout(context.Background(), smartCmdTimeout)
+	defer cancel()
+
+	out, err := smartctlFunc(ctx, "--scan", "-j")
+	if err != nil {
+		return nil
+	}
+	var scan smartctlScan
+	if json.Unmarshal([]byte(out), &scan) != nil {
+		return nil
+	}
+
+	var health []DiskHealth
+	for _, dev := range scan.Devices {
+		raw, err := smartctlFunc(ctx, "-a", "-j", "-d", dev.Type, dev.Name)
+		if err != nil {
+			continue
+		}
+		if h, ok := parseSmartctlReport(raw); ok {
+			health = append(health, h)
+		}
+	}
+	return health
+}
+
+func parseSmartctlReport(raw string) (DiskHealth, bool) {
+	var report smartctlReport
+	if err := json.Unmarshal([]byte(raw), &report); err != nil || report.Device.Name == "" {
+		return DiskHealth{}, false
+	}
+
+	h := DiskHealth{
+		Device:      report.Device.Name,
+		Model:       strings.TrimSpace(report.ModelName),
+		Status:      DiskHealthOK,
+		Temperature: report.Temperature.Current,
+		Source:      "smartctl",
+	}
+	degrade := func(reason string) {
+		h.Reasons = append(h.Reasons, reason)
+		if h.Status == DiskHealthOK {
+			h.Status = DiskHealthDegraded
+		}
+	}
+
+	if nvme := report.NVMe; nvme != nil {
+		h.WearPercent = nvme.PercentageUsed
+		h.SparePercent = nvme.AvailableSpare
+		h.SpareThreshold = nvme.AvailableSpareThreshold
+		h.MediaErrors = nvme.MediaErrors
+		if nvme.CriticalWarning != 0 {
+			degrade(fmt.Sprintf("critical warning 0x%02x", nvme.CriticalWarning))
+		}
+		if nvme.AvailableSpareThreshold > 0 && nvme.AvailableSpare < nvme.AvailableSpareThreshold {
+			degrade(fmt.Sprintf("spare %d%% below %d%%", nvme.AvailableSpare, nvme.AvailableSpareThreshold))
+		}
+		if nvme.PercentageUsed >= smartWearDegraded {
+			degrade(fmt.Sprintf("wear %d%%", nvme.PercentageUsed))
+		}
+		if nvme.MediaErrors > 0 {
+			degrade(fmt.Sprintf("%d media errors", nvme.MediaErrors))
+		}
+	}
+	if ata := report.ATA; ata != nil {
+		for _, attr := range ata.Table {
+			if name, ok := smartBadSectorAttrs[attr.ID]; ok && attr.Raw.Value > 0 {
+				h.MediaErrors += attr.Raw.Value
+				degrade(fmt.Sprintf("%d %s", attr.Raw.Value, name))
+			}
+		}
+	}
+	if report.SmartStatus != nil && !report.SmartStatus.Passed {
+		h.Status = DiskHealthFailing
+		h.Reasons = append([]string{"SMART self-assessment failed"}, h.Reasons...)
+	}
+	return h, true
+}
+
+// parseDiskutilSmartStatus reads the pass/fail bit diskutil exposes when
+// smartctl is not installed. It carries no wear or error detail.
+func parseDiskutilSmartStatus(out string) (DiskHealth, bool) {
+	h := DiskHealth{Source: "diskutil"}
+	var status string
+	for line := range strings.Lines(out) {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "Device Identifier":
+			h.Device = "/dev/" + strings.TrimSpace(value)
+		case "Device / Media Name":
+			h.Model = strings.TrimSpace(value)
+		case "SMART Status":
+			status = strings.TrimSpace(value)
+		}
+	}
+	switch status {
+	case "Verified":
+		h.Status = DiskHealthOK
+	case "Failing":
+		h.Status = DiskHealthFailing
+		h.Reasons = []string{"SMART status failing"}
+	default:
+		// "Not Supported" (most external enclosures) or missing.
+		return DiskHealth{}, false
+	}
+	return h, true
+}
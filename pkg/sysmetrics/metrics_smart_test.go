@@ -0,0 +1,116 @@
+package sysmetrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const smartctlNVMeReport = `{
+  "device": {"name": "/dev/disk0", "type": "nvme"},
+  "model_name": "APPLE SSD AP1024Z",
+  "smart_status": {"passed": true},
+  "temperature": {"current": 36},
+  "nvme_smart_health_information_log": {
+    "critical_warning": 0,
+    "temperature": 36,
+    "available_spare": 100,
+    "available_spare_threshold": 99,
+    "percentage_used": 3,
+    "media_errors": 0
+  }
+}`
+
+func TestParseSmartctlReportNVMe(t *testing.T) {
+	h, ok := parseSmartctlReport(smartctlNVMeReport)
+	if !ok {
+		t.Fatal("parseSmartctlReport() rejected a valid report")
+	}
+	if h.Status != DiskHealthOK || h.WearPercent != 3 || h.SparePercent != 100 || h.Temperature != 36 {
+		t.Fatalf("parseSmartctlReport() = %+v", h)
+	}
+	if h.Device != "/dev/disk0" || h.Model != "APPLE SSD AP1024Z" || h.Source != "smartctl" {
+		t.Fatalf("unexpected identity: %+v", h)
+	}
+}
+
+func TestParseSmartctlReportFlagsDegradedAndFailing(t *testing.T) {
+	degraded := strings.NewReplacer(`"available_spare": 100`, `"available_spare": 40`, `"media_errors": 0`, `"media_errors": 7`).
+		Replace(smartctlNVMeReport)
+	h, _ := parseSmartctlReport(degraded)
+	if h.Status != DiskHealthDegraded || h.MediaErrors != 7 || len(h.Reasons) != 2 {
+		t.Fatalf("degraded report = %+v", h)
+	}
+	if h.Reasons[0] != "spare 40% below 99%" {
+		t.Fatalf("first reason = %q", h.Reasons[0])
+	}
+
+	ata := `{
+  "device": {"name": "/dev/sda", "type": "sat"},
+  "smart_status": {"passed": false},
+  "ata_smart_attributes": {"table": [
+    {"id": 5, "raw": {"value": 12}},
+    {"id": 9, "raw": {"value": 20000}}
+  ]}
+}`
+	h, _ = parseSmartctlReport(ata)
+	if h.Status != DiskHealthFailing || h.MediaErrors != 12 {
+		t.Fatalf("ata report = %+v", h)
+	}
+	if h.Reasons[0] != "SMART self-assessment failed" || h.Reasons[1] != "12 reallocated sectors" {
+		t.Fatalf("ata reasons = %v", h.Reasons)
+	}
+
+	if _, ok := parseSmartctlReport(`{"smartctl": {"exit_status": 2}}`); ok {
+		t.Fatal("report without a device should be rejected")
+	}
+}
+
+func TestParseDiskutilSmartStatus(t *testing.T) {
+	out := `   Device Identifier:         disk0
+   Device / Media Name:       APPLE SSD AP0512Z
+   SMART Status:              Verified
+`
+	h, ok := parseDiskutilSmartStatus(out)
+	if !ok || h.Status != DiskHealthOK || h.Device != "/dev/disk0" || h.Source != "diskutil" {
+		t.Fatalf("parseDiskutilSmartStatus() = %+v, %v", h, ok)
+	}
+	if _, ok := parseDiskutilSmartStatus(strings.Replace(out, "Verified", "Not Supported", 1)); ok {
+		t.Fatal("unsupported SMART status should be skipped")
+	}
+}
+
+func TestCollectDiskHealthCachesSmartctl(t *testing.T) {
+	origSmartctl := smartctlFunc
+	origCommandExists := commandExists
+	t.Cleanup(func() {
+		smartctlFunc = origSmartctl
+		commandExists = origCommandExists
+	})
+
+	commandExists = func(name string) bool { return name == "smartctl" }
+	calls := 0
+	smartctlFunc = func(ctx context.Context, args ...string) (string, error) {
+		calls++
+		if args[0] == "--scan" {
+			return `{"devices": [{"name": "/dev/disk0", "type": "nvme"}, {"name": "/dev/disk4", "type": "sat"}]}`, nil
+		}
+		if args[len(args)-1] == "/dev/disk4" {
+			return "", errors.New("permission denied")
+		}
+		return smartctlNVMeReport, nil
+	}
+
+	c := NewCollector(ProcessWatchOptions{})
+	now := time.Now()
+	got := c.collectDiskHealth(now)
+	if len(got) != 1 || got[0].Device != "/dev/disk0" {
+		t.Fatalf("collectDiskHealth() = %+v", got)
+	}
+	c.collectDiskHealth(now.Add(time.Minute))
+	if calls != 3 {
+		t.Fatalf("smartctl calls = %d, want 3 (cached within TTL)", calls)
+	}
+}
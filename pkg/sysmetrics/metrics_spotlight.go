@@ -0,0 +1,105 @@
+package sysmetrics
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Combined mds/mdworker CPU at or above this counts as indexing; idle
+	// Spotlight sits well under it.
+	spotlightIndexingCPU = 20.0
+	spotlightVolumesTTL  = 10 * time.Minute
+)
+
+// spotlightProcesses are the metadata server and its importers.
+var spotlightProcesses = map[string]bool{
+	"mds":             true,
+	"mds_stores":      true,
+	"mdworker":        true,
+	"mdworker_shared": true,
+	"mdsync":          true,
+}
+
+// collectSpotlight reports whether Spotlight is busy indexing. macOS has no
+// command that prints indexing progress, so activity is judged from the
+// CPU of mds and its workers; mdutil says which volumes are indexed.
+func (c *Collector) collectSpotlight(now time.Time) SpotlightStatus {
+	if runtime.GOOS != "darwin" || !commandExists("ps") {
+		return SpotlightStatus{}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var status SpotlightStatus
+	if out, err := runCmd(ctx, "ps", "-Aeo", "pcpu=,comm="); err == nil {
+		status.CPU, status.Workers = parseSpotlightCPU(out)
+	}
+	status.Indexing = status.CPU >= spotlightIndexingCPU
+
+	if commandExists("mdutil") && (c.lastSpotlightAt.IsZero() || now.Sub(c.lastSpotlightAt) >= spotlightVolumesTTL) {
+		if out, err := runCmd(ctx, "mdutil", "-as"); err == nil {
+			c.spotlightVolumes = parseMdutilStatus(out)
+			c.lastSpotlightAt = now
+		}
+	}
+	status.Volumes = c.spotlightVolumes
+	return status
+}
+
+// parseSpotlightCPU sums CPU over Spotlight processes in
+// `ps -Aeo pcpu=,comm=` and counts the running mdworkers.
+func parseSpotlightCPU(out string) (float64, int) {
+	var total float64
+	var workers int
+	for line := range strings.Lines(out) {
+		cpuText, comm, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		name := filepath.Base(strings.TrimSpace(comm))
+		if !spotlightProcesses[name] {
+			continue
+		}
+		if cpu, err := strconv.ParseFloat(cpuText, 64); err == nil {
+			total += cpu
+		}
+		if strings.HasPrefix(name, "mdworker") {
+			workers++
+		}
+	}
+	return total, workers
+}
+
+// parseMdutilStatus reads `mdutil -as`:
+//
+//	/:
+//		Indexing enabled.
+//	/Volumes/Backup:
+//		Indexing and searching disabled.
+func parseMdutilStatus(out string) []SpotlightVolume {
+	var volumes []SpotlightVolume
+	for line := range strings.Lines(out) {
+		text := strings.TrimSpace(line)
+		switch {
+		case text == "":
+		case !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, " ") && strings.HasSuffix(text, ":"):
+			volumes = append(volumes, SpotlightVolume{Path: strings.TrimSuffix(text, ":")})
+		case len(volumes) > 0 && volumes[len(volumes)-1].State == "":
+			v := &volumes[len(volumes)-1]
+			switch {
+			case strings.Contains(text, "disabled"):
+				v.State = "disabled"
+			case strings.Contains(text, "enabled"):
+				v.State = "enabled"
+			default:
+				v.State = "unknown"
+			}
+		}
+	}
+	return volumes
+}
@@ -0,0 +1,35 @@
+package sysmetrics
+
+import "testing"
+
+func TestParseSpotlightCPU(t *testing.T) {
+	out := ` 62.5 /System/Library/Frameworks/CoreServices.framework/Frameworks/Metadata.framework/Versions/A/Support/mds_stores
+ 18.0 /System/Library/Frameworks/CoreServices.framework/Frameworks/Metadata.framework/Versions/A/Support/mdworker_shared
+  9.1 /System/Library/Frameworks/CoreServices.framework/Frameworks/Metadata.framework/Versions/A/Support/mdworker_shared
+  4.0 /System/Library/Frameworks/CoreServices.framework/Frameworks/Metadata.framework/Versions/A/Support/mds
+ 40.0 /Applications/Safari.app/Contents/MacOS/Safari
+`
+	cpu, workers := parseSpotlightCPU(out)
+	if cpu < 93.5 || cpu > 93.7 || workers != 2 {
+		t.Fatalf("cpu=%v workers=%d", cpu, workers)
+	}
+}
+
+func TestParseMdutilStatus(t *testing.T) {
+	out := "/:\n\tIndexing enabled. \n/System/Volumes/Data:\n\tIndexing enabled. \n/Volumes/Backup:\n\tIndexing and searching disabled.\n/Volumes/NAS:\n\tError: unknown indexing state.\n"
+	got := parseMdutilStatus(out)
+	want := []SpotlightVolume{
+		{Path: "/", State: "enabled"},
+		{Path: "/System/Volumes/Data", State: "enabled"},
+		{Path: "/Volumes/Backup", State: "disabled"},
+		{Path: "/Volumes/NAS", State: "unknown"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("volume %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package sysmetrics
+
+import (
+	"context"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	storageCacheTTL = 2 * time.Minute
+	// APFS creates several housekeeping volumes (Preboot, Recovery, Update)
+	// that hold a few hundred MB; they only add noise to the panel.
+	StorageMinVolumeBytes = 1 << 30
+)
+
+func (c *Collector) collectStorage(now time.Time) StorageStatus {
+	if !c.lastStorageAt.IsZero() && now.Sub(c.lastStorageAt) < storageCacheTTL {
+		return c.cachedStorage
+	}
+	c.cachedStorage = readStorage()
+	c.lastStorageAt = now
+	return c.cachedStorage
+}
+
+func readStorage() StorageStatus {
+	partitions, err := diskPartitionsFunc(false)
+	if err != nil {
+		return StorageStatus{}
+	}
+	mounts := make(map[string]string, len(partitions))
+	for _, part := range partitions {
+		dev := strings.TrimPrefix(part.Device, "/dev/")
+		if _, ok := mounts[dev]; !ok {
+			mounts[dev] = part.Mountpoint
+		}
+	}
+
+	var storage StorageStatus
+	if runtime.GOOS == "darwin" && commandExists("diskutil") {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		out, err := runCmd(ctx, "diskutil", "apfs", "list", "-plist")
+		cancel()
+		if err == nil {
+			storage = parseAPFSList(out, mounts)
+			applyStartupPurgeable(&storage)
+		}
+	}
+
+	// Everything APFS did not cover: HFS+/exFAT externals on macOS and every
+	// volume on Linux. statfs is accurate for these, unlike shared APFS pools.
+	seen := make(map[string]bool)
+	for _, vol := range storage.Volumes {
+		seen[vol.Device] = true
+	}
+	for _, part := range partitions {
+		dev := strings.TrimPrefix(part.Device, "/dev/")
+		if seen[dev] || strings.EqualFold(part.Fstype, "apfs") || shouldSkipDiskPartition(part) {
+			continue
+		}
+		usage, err := diskUsageFunc(part.Mountpoint)
+		if err != nil || usage.Total < StorageMinVolumeBytes {
+			continue
+		}
+		seen[dev] = true
+		storage.Volumes = append(storage.Volumes, StorageVolume{
+			Name:     volumeName(part.Mountpoint),
+			Mount:    part.Mountpoint,
+			Device:   dev,
+			Fstype:   part.Fstype,
+			Used:     usage.Used,
+			Free:     usage.Free,
+			Total:    usage.Total,
+			External: strings.HasPrefix(part.Mountpoint, "/Volumes/") || strings.HasPrefix(part.Mountpoint, "/media/"),
+		})
+	}
+	return storage
+}
+
+// parseAPFSList reads `diskutil apfs list -plist`. Every volume in a
+// container draws from the same free space, so a volume's Total and Free
+// are the container's and only Used is its own.
+func parseAPFSList(out string, mounts map[string]string) StorageStatus {
+	root, err := decodePlist(out)
+	dict, ok := root.(map[string]any)
+	if err != nil || !ok {
+		return StorageStatus{}
+	}
+
+	var storage StorageStatus
+	for _, c := range plistDicts(dict, "Containers") {
+		container := APFSContainer{
+			Device: plistString(c, "ContainerReference"),
+			Total:  plistUint(c, "CapacityCeiling"),
+			Free:   plistUint(c, "CapacityFree"),
+		}
+		if container.Device == "" || container.Total == 0 {
+			continue
+		}
+		for _, v := range plistDicts(c, "Volumes") {
+			vol := StorageVolume{
+				Name:      plistString(v, "Name"),
+				Device:    plistString(v, "DeviceIdentifier"),
+				Fstype:    "apfs",
+				Used:      plistUint(v, "CapacityInUse"),
+				Free:      container.Free,
+				Total:     container.Total,
+				Container: container.Device,
+			}
+			if roles, _ := v["Roles"].([]any); len(roles) > 0 {
+				vol.Role, _ = roles[0].(string)
+			}
+			vol.Mount = mounts[vol.Device]
+			vol.External = strings.HasPrefix(vol.Mount, "/Volumes/")
+			if vol.Used < StorageMinVolumeBytes {
+				continue
+			}
+			container.Volumes = append(container.Volumes, vol.Device)
+			storage.Volumes = append(storage.Volumes, vol)
+		}
+		if len(container.Volumes) > 0 {
+			storage.Containers = append(storage.Containers, container)
+		}
+	}
+	return storage
+}
+
+// applyStartupPurgeable attributes the gap between Finder's free space and
+// the container's free space to purgeable data (caches, local snapshots).
+// Finder only reports the startup disk, so other containers show none.
+func applyStartupPurgeable(storage *StorageStatus) {
+	idx := slices.IndexFunc(storage.Containers, func(c APFSContainer) bool {
+		return slices.ContainsFunc(storage.Volumes, func(v StorageVolume) bool {
+			return v.Container == c.Device && (v.Mount == "/" || v.Mount == "/System/Volumes/Data")
+		})
+	})
+	if idx < 0 || !commandExists("osascript") {
+		return
+	}
+	finderFree, _, err := getFinderStartupDiskFreeBytes()
+	if err != nil {
+		return
+	}
+	if container := &storage.Containers[idx]; finderFree > container.Free {
+		container.Purgeable = finderFree - container.Free
+	}
+}
+
+func volumeName(mount string) string {
+	if mount == "/" {
+		return "root"
+	}
+	name := mount[strings.LastIndex(mount, "/")+1:]
+	if name == "" {
+		return mount
+	}
+	return name
+}
@@ -0,0 +1,142 @@
+package sysmetrics
+
+import (
+	"testing"
+
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+const apfsListPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Containers</key>
+	<array>
+		<dict>
+			<key>CapacityCeiling</key>
+			<integer>494384795648</integer>
+			<key>CapacityFree</key>
+			<integer>128849018880</integer>
+			<key>ContainerReference</key>
+			<string>disk3</string>
+			<key>Volumes</key>
+			<array>
+				<dict>
+					<key>CapacityInUse</key>
+					<integer>11811160064</integer>
+					<key>DeviceIdentifier</key>
+					<string>disk3s1</string>
+					<key>Encryption</key>
+					<false/>
+					<key>Name</key>
+					<string>Macintosh HD</string>
+					<key>Roles</key>
+					<array>
+						<string>System</string>
+					</array>
+				</dict>
+				<dict>
+					<key>CapacityInUse</key>
+					<integer>6442450944</integer>
+					<key>DeviceIdentifier</key>
+					<string>disk3s2</string>
+					<key>Name</key>
+					<string>Preboot</string>
+					<key>Roles</key>
+					<array>
+						<string>Preboot</string>
+					</array>
+				</dict>
+				<dict>
+					<key>CapacityInUse</key>
+					<integer>536870912</integer>
+					<key>DeviceIdentifier</key>
+					<string>disk3s3</string>
+					<key>Name</key>
+					<string>Recovery</string>
+				</dict>
+				<dict>
+					<key>CapacityInUse</key>
+					<integer>343597383680</integer>
+					<key>DeviceIdentifier</key>
+					<string>disk3s5</string>
+					<key>Name</key>
+					<string>Data</string>
+					<key>Roles</key>
+					<array>
+						<string>Data</string>
+					</array>
+				</dict>
+			</array>
+		</dict>
+	</array>
+</dict>
+</plist>`
+
+func TestParseAPFSListGroupsVolumesByContainer(t *testing.T) {
+	storage := parseAPFSList(apfsListPlist, map[string]string{
+		"disk3s1": "/",
+		"disk3s5": "/System/Volumes/Data",
+	})
+
+	if len(storage.Containers) != 1 {
+		t.Fatalf("containers = %+v", storage.Containers)
+	}
+	c := storage.Containers[0]
+	if c.Device != "disk3" || c.Total != 494384795648 || c.Free != 128849018880 {
+		t.Fatalf("container = %+v", c)
+	}
+	// Recovery is under StorageMinVolumeBytes and dropped.
+	if len(storage.Volumes) != 3 || len(c.Volumes) != 3 {
+		t.Fatalf("volumes = %+v", storage.Volumes)
+	}
+	data := storage.Volumes[2]
+	if data.Name != "Data" || data.Role != "Data" || data.Mount != "/System/Volumes/Data" || data.Container != "disk3" {
+		t.Fatalf("data volume = %+v", data)
+	}
+	if data.Used != 343597383680 || data.Free != c.Free || data.Total != c.Total {
+		t.Fatalf("data volume should share container space: %+v", data)
+	}
+
+	if got := parseAPFSList("not a plist", nil); len(got.Volumes) != 0 {
+		t.Fatalf("invalid plist should yield no volumes, got %+v", got)
+	}
+}
+
+func TestReadStorageIncludesNonAPFSVolumes(t *testing.T) {
+	origPartitions := diskPartitionsFunc
+	origUsage := diskUsageFunc
+	origCommandExists := commandExists
+	t.Cleanup(func() {
+		diskPartitionsFunc = origPartitions
+		diskUsageFunc = origUsage
+		commandExists = origCommandExists
+	})
+
+	commandExists = func(string) bool { return false }
+	diskPartitionsFunc = func(bool) ([]disk.PartitionStat, error) {
+		return []disk.PartitionStat{
+			{Device: "/dev/nvme0n1p2", Mountpoint: "/", Fstype: "ext4"},
+			{Device: "/dev/sdb1", Mountpoint: "/media/backup", Fstype: "exfat"},
+			{Device: "/dev/sdc1", Mountpoint: "/media/stick", Fstype: "vfat"},
+			{Device: "tmpfs", Mountpoint: "/run", Fstype: "tmpfs"},
+		}, nil
+	}
+	diskUsageFunc = func(path string) (*disk.UsageStat, error) {
+		switch path {
+		case "/":
+			return &disk.UsageStat{Total: 500 << 30, Used: 200 << 30, Free: 300 << 30}, nil
+		case "/media/backup":
+			return &disk.UsageStat{Total: 2 << 40, Used: 1 << 40, Free: 1 << 40}, nil
+		}
+		return &disk.UsageStat{Total: 256 << 20}, nil
+	}
+
+	storage := readStorage()
+	if len(storage.Volumes) != 2 || len(storage.Containers) != 0 {
+		t.Fatalf("readStorage() = %+v", storage)
+	}
+	if storage.Volumes[0].Name != "root" || storage.Volumes[1].Name != "backup" || !storage.Volumes[1].External {
+		t.Fatalf("unexpected volumes: %+v", storage.Volumes)
+	}
+}
@@ -0,0 +1,348 @@
+package sysmetrics
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNewRingBuffer(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+	}{
+		{"small buffer", 5},
+		{"standard buffer", 120},
+		{"single element", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rb := NewRingBuffer(tt.capacity)
+			if rb == nil {
+				t.Fatal("NewRingBuffer returned nil")
+			}
+			if rb.cap != tt.capacity {
+				t.Errorf("NewRingBuffer(%d).cap = %d, want %d", tt.capacity, rb.cap, tt.capacity)
+			}
+			if rb.size != 0 {
+				t.Errorf("NewRingBuffer(%d).size = %d, want 0", tt.capacity, rb.size)
+			}
+			if rb.index != 0 {
+				t.Errorf("NewRingBuffer(%d).index = %d, want 0", tt.capacity, rb.index)
+			}
+			if len(rb.data) != tt.capacity {
+				t.Errorf("len(NewRingBuffer(%d).data) = %d, want %d", tt.capacity, len(rb.data), tt.capacity)
+			}
+		})
+	}
+}
+
+func TestRingBuffer_EmptyBuffer(t *testing.T) {
+	rb := NewRingBuffer(5)
+	got := rb.Slice()
+
+	if got != nil {
+		t.Errorf("Slice() on empty buffer = %v, want nil", got)
+	}
+}
+
+func TestRingBuffer_AddWithinCapacity(t *testing.T) {
+	rb := NewRingBuffer(5)
+
+	// Add 3 elements (less than capacity)
+	rb.Add(1.0)
+	rb.Add(2.0)
+	rb.Add(3.0)
+
+	if rb.size != 3 {
+		t.Errorf("size after 3 adds = %d, want 3", rb.size)
+	}
+
+	got := rb.Slice()
+	want := []float64{1.0, 2.0, 3.0}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("Slice() = %v, want %v", got, want)
+	}
+}
+
+func TestRingBuffer_ExactCapacity(t *testing.T) {
+	rb := NewRingBuffer(5)
+
+	// Fill exactly to capacity
+	for i := 1; i <= 5; i++ {
+		rb.Add(float64(i))
+	}
+
+	if rb.size != 5 {
+		t.Errorf("size after filling to capacity = %d, want 5", rb.size)
+	}
+
+	got := rb.Slice()
+	want := []float64{1.0, 2.0, 3.0, 4.0, 5.0}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("Slice() = %v, want %v", got, want)
+	}
+}
+
+func TestRingBuffer_WrapAround(t *testing.T) {
+	rb := NewRingBuffer(5)
+
+	// Add 7 elements to trigger wrap-around (2 past capacity)
+	// Internal state after: data=[6, 7, 3, 4, 5], index=2, size=5
+	// Oldest element is at index 2 (value 3)
+	for i := 1; i <= 7; i++ {
+		rb.Add(float64(i))
+	}
+
+	if rb.size != 5 {
+		t.Errorf("size after wrap-around = %d, want 5", rb.size)
+	}
+
+	// Verify index points to oldest element position
+	if rb.index != 2 {
+		t.Errorf("index after adding 7 elements to cap-5 buffer = %d, want 2", rb.index)
+	}
+
+	got := rb.Slice()
+	// Should return chronological order: oldest (3) to newest (7)
+	want := []float64{3.0, 4.0, 5.0, 6.0, 7.0}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("Slice() = %v, want %v", got, want)
+	}
+}
+
+func TestRingBuffer_MultipleWrapArounds(t *testing.T) {
+	rb := NewRingBuffer(3)
+
+	// Add 10 elements (wraps multiple times)
+	for i := 1; i <= 10; i++ {
+		rb.Add(float64(i))
+	}
+
+	got := rb.Slice()
+	// Should have the last 3 values: 8, 9, 10
+	want := []float64{8.0, 9.0, 10.0}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("Slice() after 10 adds to cap-3 buffer = %v, want %v", got, want)
+	}
+}
+
+func TestRingBuffer_SingleElementBuffer(t *testing.T) {
+	rb := NewRingBuffer(1)
+
+	rb.Add(5.0)
+	if got := rb.Slice(); !slices.Equal(got, []float64{5.0}) {
+		t.Errorf("Slice() = %v, want [5.0]", got)
+	}
+
+	// Overwrite the single element
+	rb.Add(10.0)
+	if got := rb.Slice(); !slices.Equal(got, []float64{10.0}) {
+		t.Errorf("Slice() after overwrite = %v, want [10.0]", got)
+	}
+}
+
+func TestRingBuffer_SliceReturnsNewSlice(t *testing.T) {
+	rb := NewRingBuffer(3)
+	rb.Add(1.0)
+	rb.Add(2.0)
+
+	slice1 := rb.Slice()
+	slice2 := rb.Slice()
+
+	// Modify slice1 and verify slice2 is unaffected
+	// This ensures Slice() returns a copy, not a reference to internal data
+	slice1[0] = 999.0
+
+	if slice2[0] == 999.0 {
+		t.Error("Slice() should return a new copy, not a reference to internal data")
+	}
+}
+
+func TestRingBuffer_NegativeAndZeroValues(t *testing.T) {
+	rb := NewRingBuffer(4)
+
+	// Test that negative and zero values are handled correctly
+	rb.Add(-5.0)
+	rb.Add(0.0)
+	rb.Add(0.0)
+	rb.Add(3.5)
+
+	got := rb.Slice()
+	want := []float64{-5.0, 0.0, 0.0, 3.5}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("Slice() with negative/zero values = %v, want %v", got, want)
+	}
+}
+
+func TestCollectorAppliesCachedEnrichmentToFastSnapshot(t *testing.T) {
+	previous := MetricsSnapshot{
+		CPU:         CPUStatus{PCoreCount: 8, ECoreCount: 4},
+		Memory:      MemoryStatus{Cached: 512, Pressure: "warn"},
+		Hardware:    HardwareInfo{Model: "MacBook Pro", CPUModel: "M3", OSVersion: "macOS 15", RefreshRate: "120Hz"},
+		GPU:         []GPUStatus{{Name: "Apple GPU", Usage: 12}},
+		TrashSize:   42,
+		TrashApprox: true,
+		Proxy:       ProxyStatus{Enabled: true, Type: "HTTP", Host: "127.0.0.1:8080"},
+		Batteries:   []BatteryStatus{{Percent: 80, Capacity: 92}},
+		Thermal:     ThermalStatus{CPUTemp: 45},
+		Sensors:     []SensorReading{{Label: "Fan", Value: 1200, Unit: "rpm"}},
+		Bluetooth:   []BluetoothDevice{{Name: "Keyboard", Connected: true}},
+		TopProcesses: []ProcessInfo{
+			{PID: 42, Name: "Xcode", CPU: 82},
+		},
+		ProcessAlerts: []ProcessAlert{
+			{PID: 42, Name: "Xcode", CPU: 140, Status: "active"},
+		},
+	}
+
+	collector := NewCollector(ProcessWatchOptions{})
+	collector.cacheEnrichment(previous)
+	previous.GPU[0].Name = "mutated"
+
+	next := MetricsSnapshot{
+		UptimeSeconds: 60,
+		Hardware:      HardwareInfo{TotalRAM: "16G", DiskSize: "1T"},
+		CPU:           CPUStatus{Usage: 10},
+		Memory:        MemoryStatus{UsedPercent: 30, Pressure: "normal"},
+		Disks:         []DiskStatus{{Mount: "/", Total: 100, Used: 20, UsedPercent: 20}},
+		DiskIO:        DiskIOStatus{ReadRate: 1, WriteRate: 1},
+	}
+
+	collector.applyEnrichment(&next, false)
+
+	if next.Hardware.Model != "MacBook Pro" {
+		t.Fatalf("expected hardware details to be preserved, got %#v", next.Hardware)
+	}
+	if next.CPU.PCoreCount != 8 || next.CPU.ECoreCount != 4 {
+		t.Fatalf("expected CPU topology to be preserved, got %#v", next.CPU)
+	}
+	if next.Memory.Cached != 512 || next.Memory.Pressure != "warn" {
+		t.Fatalf("expected slow memory annotations to be preserved, got %#v", next.Memory)
+	}
+	if next.TrashSize != 42 || !next.TrashApprox {
+		t.Fatalf("expected trash metadata to be preserved, got size=%d approx=%v", next.TrashSize, next.TrashApprox)
+	}
+	if !next.Proxy.Enabled || next.Proxy.Host != "127.0.0.1:8080" {
+		t.Fatalf("expected proxy metadata to be preserved, got %#v", next.Proxy)
+	}
+	if len(next.GPU) != 1 || next.GPU[0].Name != "Apple GPU" {
+		t.Fatalf("expected GPU metadata to be preserved from cache, got %#v", next.GPU)
+	}
+	if len(next.Batteries) != 1 || next.Batteries[0].Capacity != 92 {
+		t.Fatalf("expected battery metadata to be preserved, got %#v", next.Batteries)
+	}
+	if next.Thermal.CPUTemp != 45 {
+		t.Fatalf("expected thermal metadata to be preserved, got %#v", next.Thermal)
+	}
+	if len(next.Bluetooth) != 1 || next.Bluetooth[0].Name != "Keyboard" {
+		t.Fatalf("expected Bluetooth metadata to be preserved, got %#v", next.Bluetooth)
+	}
+	if len(next.TopProcesses) != 1 || next.TopProcesses[0].Name != "Xcode" {
+		t.Fatalf("expected top processes to be preserved, got %#v", next.TopProcesses)
+	}
+	if len(next.ProcessAlerts) != 1 || next.ProcessAlerts[0].Status != "active" {
+		t.Fatalf("expected process alerts to be preserved, got %#v", next.ProcessAlerts)
+	}
+	if next.HealthScore == 0 || next.HealthScoreMsg == "" {
+		t.Fatalf("expected health score to be recalculated, got %d %q", next.HealthScore, next.HealthScoreMsg)
+	}
+}
+
+func TestCollectorAppliesZeroValueEnrichmentExactly(t *testing.T) {
+	collector := NewCollector(ProcessWatchOptions{})
+	collector.cacheEnrichment(MetricsSnapshot{
+		Memory: MemoryStatus{
+			Cached:   0,
+			Pressure: "",
+		},
+	})
+
+	next := MetricsSnapshot{
+		Memory: MemoryStatus{
+			Cached:   512,
+			Pressure: "critical",
+		},
+	}
+
+	collector.applyEnrichment(&next, false)
+
+	if next.Memory.Cached != 0 || next.Memory.Pressure != "" {
+		t.Fatalf("expected exact memory enrichment, got %#v", next.Memory)
+	}
+}
+
+func TestCollectorOverridesFastDisksWithCorrectedCache(t *testing.T) {
+	collector := NewCollector(ProcessWatchOptions{})
+	collector.cacheEnrichment(MetricsSnapshot{
+		Disks: []DiskStatus{
+			{Mount: "/", Total: 1000, Used: 600, UsedPercent: 60, External: false},
+		},
+	})
+
+	// Fast path produced raw statfs numbers that ignore APFS purgeable space.
+	next := MetricsSnapshot{
+		Disks: []DiskStatus{
+			{Mount: "/", Total: 1000, Used: 900, UsedPercent: 90, External: true},
+		},
+	}
+
+	collector.applyEnrichment(&next, false)
+
+	if len(next.Disks) != 1 {
+		t.Fatalf("expected one disk, got %#v", next.Disks)
+	}
+	if next.Disks[0].Used != 600 || next.Disks[0].UsedPercent != 60 || next.Disks[0].External {
+		t.Fatalf("expected corrected disk values from cache, got %#v", next.Disks[0])
+	}
+}
+
+func TestCollectorKeepsFastDisksWhenCacheHasNone(t *testing.T) {
+	collector := NewCollector(ProcessWatchOptions{})
+	// First full refresh failed to enumerate disks; the cache should not blank
+	// out the fast path's raw disks.
+	collector.cacheEnrichment(MetricsSnapshot{Disks: nil})
+
+	next := MetricsSnapshot{
+		Disks: []DiskStatus{
+			{Mount: "/", Total: 1000, Used: 900, UsedPercent: 90},
+		},
+	}
+
+	collector.applyEnrichment(&next, false)
+
+	if len(next.Disks) != 1 || next.Disks[0].Used != 900 {
+		t.Fatalf("expected raw fast disks to survive empty cache, got %#v", next.Disks)
+	}
+}
+
+func TestCollectorKeepsLiveProcessDataWhenApplyingEnrichment(t *testing.T) {
+	collector := NewCollector(ProcessWatchOptions{})
+	collector.cacheEnrichment(MetricsSnapshot{
+		TopProcesses: []ProcessInfo{{PID: 1, Name: "old", CPU: 10}},
+		ProcessAlerts: []ProcessAlert{
+			{PID: 1, Name: "old", Status: "active"},
+		},
+	})
+
+	next := MetricsSnapshot{
+		TopProcesses: []ProcessInfo{{PID: 2, Name: "new", CPU: 90}},
+		ProcessAlerts: []ProcessAlert{
+			{PID: 2, Name: "new", Status: "active"},
+		},
+	}
+
+	collector.applyEnrichment(&next, true)
+
+	if len(next.TopProcesses) != 1 || next.TopProcesses[0].Name != "new" {
+		t.Fatalf("expected live top process data, got %#v", next.TopProcesses)
+	}
+	if len(next.ProcessAlerts) != 1 || next.ProcessAlerts[0].Name != "new" {
+		t.Fatalf("expected live process alerts, got %#v", next.ProcessAlerts)
+	}
+}
@@ -0,0 +1,126 @@
+package sysmetrics
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultBackupWarnDays = 7
+
+// Time Machine keeps each destination's completed backup dates here. The
+// file is a world-readable binary plist, unlike `tmutil latestbackup`,
+// which needs Full Disk Access.
+var timeMachinePrefs = "/Library/Preferences/com.apple.TimeMachine.plist"
+
+// SetBackupWarnAge sets how long since the last completed backup before
+// Time Machine is reported as overdue.
+func (c *Collector) SetBackupWarnAge(age time.Duration) {
+	c.backupWarnAge = age
+}
+
+func (c *Collector) collectTimeMachine(now time.Time) TimeMachineStatus {
+	if runtime.GOOS != "darwin" || !commandExists("tmutil") || !commandExists("plutil") {
+		return TimeMachineStatus{}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var status TimeMachineStatus
+	if out, err := runCmd(ctx, "plutil", "-convert", "xml1", "-o", "-", timeMachinePrefs); err == nil {
+		status = parseTimeMachinePrefs(out)
+	}
+	if status.Destination == "" {
+		return TimeMachineStatus{}
+	}
+	if out, err := runCmd(ctx, "tmutil", "status"); err == nil {
+		applyTmutilStatus(&status, out)
+	}
+	status.Overdue = backupOverdue(status, now, c.backupWarnAge)
+	return status
+}
+
+// backupOverdue reports a destination with no completed backup within age.
+// A backup in progress is not overdue; a zero age disables the check.
+func backupOverdue(status TimeMachineStatus, now time.Time, age time.Duration) bool {
+	if age <= 0 || status.Running {
+		return false
+	}
+	return status.LastBackup.IsZero() || now.Sub(status.LastBackup) > age
+}
+
+// parseTimeMachinePrefs picks the destination with the newest entry in
+// SnapshotDates, or the first one when none has completed a backup.
+func parseTimeMachinePrefs(out string) TimeMachineStatus {
+	root, err := decodePlist(out)
+	if err != nil {
+		return TimeMachineStatus{}
+	}
+	prefs, _ := root.(map[string]any)
+	var status TimeMachineStatus
+	for _, dest := range plistDicts(prefs, "Destinations") {
+		name := plistString(dest, "LastKnownVolumeName")
+		if name == "" {
+			name = "Backup disk"
+		}
+		var latest time.Time
+		dates, _ := dest["SnapshotDates"].([]any)
+		for _, raw := range dates {
+			text, _ := raw.(string)
+			if t, err := time.Parse(time.RFC3339, text); err == nil && t.After(latest) {
+				latest = t
+			}
+		}
+		if status.Destination == "" || latest.After(status.LastBackup) {
+			status.Destination = name
+			status.LastBackup = latest
+		}
+	}
+	return status
+}
+
+// applyTmutilStatus reads the old-style plist `tmutil status` prints:
+//
+//	Backup session status:
+//	{
+//	    BackupPhase = Copying;
+//	    Percent = "0.4519";
+//	    Progress =     {
+//	        TimeRemaining = 1234;
+//	    };
+//	    Running = 1;
+//	}
+//
+// Percent is a 0-1 fraction and is -1 while the phase has no estimate.
+func applyTmutilStatus(status *TimeMachineStatus, out string) {
+	status.Percent = -1
+	for line := range strings.Lines(out) {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), " = ")
+		if !ok {
+			continue
+		}
+		key = strings.Trim(key, `"`)
+		value = strings.Trim(strings.TrimSuffix(value, ";"), `"`)
+		switch key {
+		case "Running":
+			status.Running = value == "1"
+		case "BackupPhase":
+			status.Phase = value
+		case "Percent":
+			if p, err := strconv.ParseFloat(value, 64); err == nil && p >= 0 && status.Percent < 0 {
+				status.Percent = p * 100
+			}
+		case "TimeRemaining":
+			if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+				status.TimeRemaining = secs
+			}
+		}
+	}
+	if !status.Running {
+		status.Phase = ""
+		status.Percent = -1
+		status.TimeRemaining = 0
+	}
+}
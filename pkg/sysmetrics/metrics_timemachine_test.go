@@ -0,0 +1,95 @@
+package sysmetrics
+
+import (
+	"testing"
+	"time"
+)
+
+const timeMachinePrefsXML = `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>AutoBackup</key>
+	<true/>
+	<key>Destinations</key>
+	<array>
+		<dict>
+			<key>LastKnownVolumeName</key>
+			<string>Old Drive</string>
+			<key>SnapshotDates</key>
+			<array>
+				<date>2026-01-10T09:00:00Z</date>
+			</array>
+		</dict>
+		<dict>
+			<key>LastKnownVolumeName</key>
+			<string>Backup</string>
+			<key>SnapshotDates</key>
+			<array>
+				<date>2026-03-01T08:00:00Z</date>
+				<date>2026-03-02T08:00:00Z</date>
+			</array>
+		</dict>
+	</array>
+</dict>
+</plist>`
+
+func TestParseTimeMachinePrefsPicksNewestDestination(t *testing.T) {
+	got := parseTimeMachinePrefs(timeMachinePrefsXML)
+	want := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	if got.Destination != "Backup" || !got.LastBackup.Equal(want) {
+		t.Fatalf("status = %+v", got)
+	}
+}
+
+func TestApplyTmutilStatus(t *testing.T) {
+	out := `Backup session status:
+{
+    BackupPhase = Copying;
+    ClientID = "com.apple.backupd";
+    Percent = "0.4519";
+    Progress =     {
+        Percent = "0.4519";
+        TimeRemaining = 754;
+        bytes = 1024;
+    };
+    Running = 1;
+    Stopped = 0;
+}
+`
+	var status TimeMachineStatus
+	applyTmutilStatus(&status, out)
+	if !status.Running || status.Phase != "Copying" || status.TimeRemaining != 754 {
+		t.Fatalf("status = %+v", status)
+	}
+	if status.Percent < 45.1 || status.Percent > 45.2 {
+		t.Fatalf("percent = %v", status.Percent)
+	}
+
+	status = TimeMachineStatus{}
+	applyTmutilStatus(&status, "Backup session status:\n{\n    ClientID = \"com.apple.backupd\";\n    Percent = \"-1\";\n    Running = 0;\n}\n")
+	if status.Running || status.Phase != "" || status.Percent != -1 {
+		t.Fatalf("idle status = %+v", status)
+	}
+}
+
+func TestBackupOverdue(t *testing.T) {
+	now := time.Date(2026, 3, 12, 8, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+	cases := []struct {
+		name   string
+		status TimeMachineStatus
+		age    time.Duration
+		want   bool
+	}{
+		{"recent", TimeMachineStatus{LastBackup: now.Add(-2 * time.Hour)}, week, false},
+		{"stale", TimeMachineStatus{LastBackup: now.Add(-10 * 24 * time.Hour)}, week, true},
+		{"never", TimeMachineStatus{}, week, true},
+		{"running", TimeMachineStatus{Running: true}, week, false},
+		{"disabled", TimeMachineStatus{}, 0, false},
+	}
+	for _, tc := range cases {
+		if got := backupOverdue(tc.status, now, tc.age); got != tc.want {
+			t.Errorf("%s: overdue = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
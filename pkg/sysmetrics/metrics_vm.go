@@ -0,0 +1,238 @@
+package sysmetrics
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const vmCmdTimeout = 2 * time.Second
+
+var readVMXFunc = readVMX
+
+// vmProcess is a host process backing one guest.
+type vmProcess struct {
+	pid  int
+	cpu  float64
+	rss  uint64
+	args []string
+}
+
+type prlctlVM struct {
+	Name     string `json:"Name"`
+	Hardware struct {
+		CPU struct {
+			CPUs int `json:"cpus"`
+		} `json:"cpu"`
+		Memory struct {
+			Size string `json:"size"` // "8192Mb"
+		} `json:"memory"`
+	} `json:"Hardware"`
+}
+
+// collectVMs finds running Parallels, VMware Fusion, QEMU (UTM), and
+// Virtualization.framework guests from the host process table and pairs each
+// with its configured vCPUs and memory.
+func collectVMs() []VirtualMachine {
+	if !commandExists("ps") {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), vmCmdTimeout)
+	out, err := runCmd(ctx, "ps", "-Aeo", "pid=,pcpu=,rss=,args=")
+	cancel()
+	if err != nil {
+		return nil
+	}
+	vms := parseVMProcesses(out)
+	if slices.ContainsFunc(vms, func(vm VirtualMachine) bool { return vm.Hypervisor == "Parallels" }) && commandExists("prlctl") {
+		ctx, cancel := context.WithTimeout(context.Background(), vmCmdTimeout)
+		if out, err := runCmd(ctx, "prlctl", "list", "-i", "-j"); err == nil {
+			applyPrlctlInfo(vms, out)
+		}
+		cancel()
+	}
+	slices.SortStableFunc(vms, func(a, b VirtualMachine) int {
+		return cmp.Compare(b.CPUPercent, a.CPUPercent)
+	})
+	return vms
+}
+
+// parseVMProcesses reads `ps -Aeo pid=,pcpu=,rss=,args=`.
+func parseVMProcesses(out string) []VirtualMachine {
+	var vms []VirtualMachine
+	for line := range strings.Lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		cpu, _ := strconv.ParseFloat(fields[1], 64)
+		rss, _ := strconv.ParseUint(fields[2], 10, 64)
+		proc := vmProcess{pid: pid, cpu: cpu, rss: rss * 1024, args: fields[3:]}
+		if vm, ok := vmFromProcess(proc); ok {
+			vms = append(vms, vm)
+		}
+	}
+	return vms
+}
+
+func vmFromProcess(proc vmProcess) (VirtualMachine, bool) {
+	vm := VirtualMachine{PID: proc.pid, CPUPercent: proc.cpu, MemoryUsed: proc.rss}
+	args := strings.Join(proc.args, " ")
+	// Executable paths can contain spaces ("VMware Fusion.app"), so the
+	// executable runs up to the first flag rather than the first field.
+	exe, _, _ := strings.Cut(args, " -")
+	exe = filepath.Base(exe)
+	switch {
+	case exe == "prl_vm_app":
+		vm.Hypervisor = "Parallels"
+		vm.Name = bundleName(args, ".pvm")
+	case exe == "vmware-vmx":
+		vm.Hypervisor = "VMware Fusion"
+		path := bundlePath(args, ".vmx")
+		vm.Name = strings.TrimSuffix(filepath.Base(path), ".vmx")
+		if path != "" {
+			vm.CPUs, vm.MemoryAllocated = readVMXFunc(path)
+		}
+	case strings.HasPrefix(exe, "qemu-system-"), exe == "QEMULauncher":
+		vm.Hypervisor = "QEMU"
+		if strings.Contains(args, "UTM.app") {
+			vm.Hypervisor = "UTM"
+		}
+		vm.Name, vm.CPUs, vm.MemoryAllocated = parseQEMUArgs(proc.args[1:])
+	case strings.HasPrefix(exe, "com.apple.Virtualization.VirtualMachine"):
+		// The XPC service carries no name or sizing on its command line.
+		vm.Hypervisor = "Virtualization"
+	default:
+		return VirtualMachine{}, false
+	}
+	if vm.Name == "" {
+		vm.Name = vm.Hypervisor
+	}
+	return vm, true
+}
+
+// bundlePath pulls the first path ending in ext out of a joined command
+// line. Bundle names often contain spaces, so the path runs back to the
+// nearest '/'-rooted token.
+func bundlePath(args, ext string) string {
+	end := strings.Index(args, ext)
+	if end < 0 {
+		return ""
+	}
+	start := strings.LastIndex(args[:end], " /")
+	if start < 0 {
+		start = strings.Index(args, "/") - 1
+	}
+	if start < -1 {
+		return ""
+	}
+	return args[start+1 : end+len(ext)]
+}
+
+func bundleName(args, ext string) string {
+	return strings.TrimSuffix(filepath.Base(bundlePath(args, ext)), ext)
+}
+
+// parseQEMUArgs reads -name, -smp, and -m. -smp is either "4" or
+// "cpus=4,sockets=1"; -m is MiB unless suffixed, optionally "size=4G".
+func parseQEMUArgs(args []string) (name string, cpus int, memory uint64) {
+	for i := 0; i+1 < len(args); i++ {
+		value := args[i+1]
+		switch args[i] {
+		case "-name":
+			name, _, _ = strings.Cut(strings.TrimPrefix(value, "guest="), ",")
+		case "-smp":
+			for part := range strings.SplitSeq(value, ",") {
+				if n, err := strconv.Atoi(strings.TrimPrefix(part, "cpus=")); err == nil {
+					cpus = n
+					break
+				}
+			}
+		case "-m":
+			size, _, _ := strings.Cut(strings.TrimPrefix(value, "size="), ",")
+			memory = parseQEMUMemory(size)
+		default:
+			continue
+		}
+		i++
+	}
+	return name, cpus, memory
+}
+
+func parseQEMUMemory(size string) uint64 {
+	scale := uint64(1 << 20)
+	switch {
+	case strings.HasSuffix(size, "G"):
+		scale, size = 1<<30, strings.TrimSuffix(size, "G")
+	case strings.HasSuffix(size, "M"):
+		size = strings.TrimSuffix(size, "M")
+	}
+	n, err := strconv.ParseUint(size, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n * scale
+}
+
+// readVMX reads numvcpus and memsize (MiB) from a VMware .vmx file.
+func readVMX(path string) (int, uint64) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0
+	}
+	defer f.Close()
+	return parseVMX(f)
+}
+
+func parseVMX(r io.Reader) (int, uint64) {
+	var cpus int
+	var memory uint64
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch strings.TrimSpace(key) {
+		case "numvcpus":
+			cpus, _ = strconv.Atoi(value)
+		case "memsize":
+			mb, _ := strconv.ParseUint(value, 10, 64)
+			memory = mb << 20
+		}
+	}
+	return cpus, memory
+}
+
+// applyPrlctlInfo fills Parallels sizing from `prlctl list -i -j`.
+func applyPrlctlInfo(vms []VirtualMachine, out string) {
+	var info []prlctlVM
+	if json.Unmarshal([]byte(out), &info) != nil {
+		return
+	}
+	for i := range vms {
+		if vms[i].Hypervisor != "Parallels" {
+			continue
+		}
+		for _, p := range info {
+			if p.Name != vms[i].Name {
+				continue
+			}
+			vms[i].CPUs = p.Hardware.CPU.CPUs
+			vms[i].MemoryAllocated = parseQEMUMemory(strings.TrimSuffix(p.Hardware.Memory.Size, "b"))
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package sysmetrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseVMProcesses(t *testing.T) {
+	orig := readVMXFunc
+	t.Cleanup(func() { readVMXFunc = orig })
+	var vmxPath string
+	readVMXFunc = func(path string) (int, uint64) {
+		vmxPath = path
+		return 2, 4 << 30
+	}
+
+	out := ` 1201  84.5 6291456 /Applications/Parallels Desktop.app/Contents/MacOS/prl_vm_app --openvm /Users/me/Parallels/Windows 11.pvm --ppid 1190
+ 1302  12.0 3145728 /Applications/VMware Fusion.app/Contents/Library/vmware-vmx -s vmx.stdio.keep=TRUE -# product=2;name=VMware Fusion; -@ duplex=3 /Users/me/Virtual Machines.localized/Ubuntu.vmwarevm/Ubuntu.vmx
+ 1403 150.0 2097152 /Applications/UTM.app/Contents/XPCServices/QEMUHelper.xpc/Contents/MacOS/QEMULauncher.app/Contents/MacOS/QEMULauncher -L /Applications/UTM.app/Contents/Resources/qemu -name Fedora -smp cpus=4,sockets=1 -m 8192
+ 1504   3.1  524288 /System/Library/Frameworks/Virtualization.framework/Versions/A/XPCServices/com.apple.Virtualization.VirtualMachine.xpc/Contents/MacOS/com.apple.Virtualization.VirtualMachine
+ 1605   0.0   10240 /usr/bin/vim notes.pvm
+`
+	vms := parseVMProcesses(out)
+	if len(vms) != 4 {
+		t.Fatalf("got %d VMs, want 4: %+v", len(vms), vms)
+	}
+	want := []struct {
+		name, hypervisor string
+		cpus             int
+		memory           uint64
+	}{
+		{"Windows 11", "Parallels", 0, 0},
+		{"Ubuntu", "VMware Fusion", 2, 4 << 30},
+		{"Fedora", "UTM", 4, 8192 << 20},
+		{"Virtualization", "Virtualization", 0, 0},
+	}
+	for i, w := range want {
+		vm := vms[i]
+		if vm.Name != w.name || vm.Hypervisor != w.hypervisor || vm.CPUs != w.cpus || vm.MemoryAllocated != w.memory {
+			t.Errorf("vm[%d] = %+v, want %+v", i, vm, w)
+		}
+	}
+	if vmxPath != "/Users/me/Virtual Machines.localized/Ubuntu.vmwarevm/Ubuntu.vmx" {
+		t.Errorf("vmx path = %q", vmxPath)
+	}
+	if vms[0].MemoryUsed != 6<<30 || vms[2].CPUPercent != 150 {
+		t.Errorf("usage = %+v / %+v", vms[0], vms[2])
+	}
+}
+
+func TestParseQEMUArgs(t *testing.T) {
+	name, cpus, memory := parseQEMUArgs(strings.Fields("-machine virt -name guest=alpine,debug-threads=on -smp 2 -m size=2G,slots=1"))
+	if name != "alpine" || cpus != 2 || memory != 2<<30 {
+		t.Fatalf("parseQEMUArgs() = %q, %d, %d", name, cpus, memory)
+	}
+}
+
+func TestParseVMXAndPrlctl(t *testing.T) {
+	cpus, memory := parseVMX(strings.NewReader("displayName = \"Ubuntu\"\nnumvcpus = \"4\"\nmemsize = \"6144\"\n"))
+	if cpus != 4 || memory != 6144<<20 {
+		t.Fatalf("parseVMX() = %d, %d", cpus, memory)
+	}
+
+	vms := []VirtualMachine{{Name: "Windows 11", Hypervisor: "Parallels"}}
+	applyPrlctlInfo(vms, `[{"ID":"{abc}","Name":"Windows 11","State":"running","Hardware":{"cpu":{"cpus":6},"memory":{"size":"8192Mb"}}}]`)
+	if vms[0].CPUs != 6 || vms[0].MemoryAllocated != 8<<30 {
+		t.Fatalf("applyPrlctlInfo() = %+v", vms[0])
+	}
+}
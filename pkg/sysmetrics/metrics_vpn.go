@@ -0,0 +1,130 @@
+package sysmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+const vpnCmdTimeout = 2 * time.Second
+
+// Tunnel interface prefixes. macOS keeps several IPv6-only utun devices for
+// system services, so a tunnel only counts once it carries an IPv4 address.
+var vpnInterfacePrefixes = []string{"utun", "tun", "wg", "tailscale", "ipsec", "ppp"}
+
+var interfaceIPsFunc = getInterfaceIPs
+
+type tailscaleStatusJSON struct {
+	BackendState   string `json:"BackendState"`
+	CurrentTailnet *struct {
+		Name string `json:"Name"`
+	} `json:"CurrentTailnet"`
+	Self *struct {
+		HostName     string   `json:"HostName"`
+		TailscaleIPs []string `json:"TailscaleIPs"`
+	} `json:"Self"`
+	Peer map[string]struct {
+		HostName string `json:"HostName"`
+		DNSName  string `json:"DNSName"`
+		Online   bool   `json:"Online"`
+		ExitNode bool   `json:"ExitNode"`
+	} `json:"Peer"`
+}
+
+func collectVPN() VPNStatus {
+	var status VPNStatus
+	if runtime.GOOS == "darwin" && commandExists("scutil") {
+		ctx, cancel := context.WithTimeout(context.Background(), vpnCmdTimeout)
+		if out, err := runCmd(ctx, "scutil", "--nc", "list"); err == nil {
+			status.Connections = parseScutilNCList(out)
+		}
+		cancel()
+	}
+	if commandExists("tailscale") {
+		ctx, cancel := context.WithTimeout(context.Background(), vpnCmdTimeout)
+		// tailscale exits non-zero when stopped or logged out; either way
+		// there is nothing to show.
+		if out, err := runCmd(ctx, "tailscale", "status", "--json"); err == nil {
+			status.Tailscale = parseTailscaleStatus(out)
+		}
+		cancel()
+	}
+	status.Tunnels = vpnTunnels(interfaceIPsFunc())
+	return status
+}
+
+// parseScutilNCList keeps connected entries from `scutil --nc list`, whose
+// rows carry the state, the quoted service name, and a trailing [type], e.g.
+// `(Connected) 6F1A... VPN (com.wireguard.macos) "Home" [VPN:com.wireguard.macos]`.
+func parseScutilNCList(out string) []VPNConnection {
+	var conns []VPNConnection
+	for line := range strings.Lines(out) {
+		if !strings.Contains(line, "(Connected)") {
+			continue
+		}
+		first := strings.Index(line, `"`)
+		last := strings.LastIndex(line, `"`)
+		if first < 0 || last <= first {
+			continue
+		}
+		conn := VPNConnection{Name: line[first+1 : last]}
+		if open := strings.LastIndex(line, "["); open > last {
+			conn.Type = strings.TrimSuffix(strings.TrimSpace(line[open+1:]), "]")
+		}
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
+func parseTailscaleStatus(out string) *TailscaleStatus {
+	var raw tailscaleStatusJSON
+	if err := json.Unmarshal([]byte(out), &raw); err != nil || raw.BackendState == "" {
+		return nil
+	}
+	ts := &TailscaleStatus{State: raw.BackendState}
+	if raw.CurrentTailnet != nil {
+		ts.Tailnet = raw.CurrentTailnet.Name
+	}
+	if raw.Self != nil {
+		ts.HostName = raw.Self.HostName
+		if len(raw.Self.TailscaleIPs) > 0 {
+			ts.IP = raw.Self.TailscaleIPs[0]
+		}
+	}
+	for _, peer := range raw.Peer {
+		ts.Peers++
+		if peer.Online {
+			ts.PeersOnline++
+		}
+		if peer.ExitNode {
+			ts.ExitNode = peer.HostName
+			if ts.ExitNode == "" {
+				ts.ExitNode = strings.Split(peer.DNSName, ".")[0]
+			}
+		}
+	}
+	return ts
+}
+
+func vpnTunnels(ips map[string]string) []string {
+	var tunnels []string
+	for name, ip := range ips {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(ip, "169.254.") {
+			continue
+		}
+		if slices.ContainsFunc(vpnInterfacePrefixes, func(p string) bool { return strings.HasPrefix(lower, p) }) {
+			tunnels = append(tunnels, name+" "+ip)
+		}
+	}
+	slices.Sort(tunnels)
+	return tunnels
+}
+
+// Active reports whether any VPN, tunnel, or running Tailscale was found.
+func (v VPNStatus) Active() bool {
+	return len(v.Connections) > 0 || len(v.Tunnels) > 0 || (v.Tailscale != nil && v.Tailscale.State == "Running")
+}
@@ -0,0 +1,55 @@
+package sysmetrics
+
+import "testing"
+
+func TestParseScutilNCList(t *testing.T) {
+	out := `Available network connection services in the current set (*=enabled):
+* (Disconnected)   1B2C... PPP --> L2TP   "Old Office"   [PPP:L2TP]
+* (Connected)      6F1A... VPN (com.wireguard.macos) "Home WG"  [VPN:com.wireguard.macos]
+`
+	conns := parseScutilNCList(out)
+	if len(conns) != 1 || conns[0].Name != "Home WG" || conns[0].Type != "VPN:com.wireguard.macos" {
+		t.Fatalf("parseScutilNCList() = %+v", conns)
+	}
+}
+
+func TestParseTailscaleStatus(t *testing.T) {
+	out := `{
+  "BackendState": "Running",
+  "CurrentTailnet": {"Name": "example.ts.net"},
+  "Self": {"HostName": "laptop", "TailscaleIPs": ["100.64.0.5", "fd7a::5"]},
+  "Peer": {
+    "a": {"HostName": "nas", "Online": true},
+    "b": {"HostName": "nyc-exit", "Online": true, "ExitNode": true},
+    "c": {"HostName": "phone", "Online": false}
+  }
+}`
+	ts := parseTailscaleStatus(out)
+	if ts == nil {
+		t.Fatal("parseTailscaleStatus() = nil")
+	}
+	if ts.State != "Running" || ts.Tailnet != "example.ts.net" || ts.IP != "100.64.0.5" {
+		t.Fatalf("identity = %+v", ts)
+	}
+	if ts.Peers != 3 || ts.PeersOnline != 2 || ts.ExitNode != "nyc-exit" {
+		t.Fatalf("peers = %+v", ts)
+	}
+	if parseTailscaleStatus("not json") != nil {
+		t.Fatal("invalid output should be ignored")
+	}
+}
+
+func TestVPNTunnelsNeedIPv4(t *testing.T) {
+	tunnels := vpnTunnels(map[string]string{
+		"en0":   "192.168.1.20",
+		"utun4": "10.8.0.2",
+		"utun1": "169.254.3.1",
+		"wg0":   "10.0.0.2",
+	})
+	if len(tunnels) != 2 || tunnels[0] != "utun4 10.8.0.2" || tunnels[1] != "wg0 10.0.0.2" {
+		t.Fatalf("vpnTunnels() = %v", tunnels)
+	}
+	if (VPNStatus{Tailscale: &TailscaleStatus{State: "Stopped"}}).Active() {
+		t.Fatal("stopped Tailscale should not count as active")
+	}
+}
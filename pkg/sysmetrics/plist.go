@@ -0,0 +1,119 @@
+package sysmetrics
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// decodePlist parses an XML property list into maps, slices, strings,
+// uint64/float64 numbers, and bools. It covers what diskutil and
+// system_profiler emit; data and date values decode as strings.
+func decodePlist(raw string) (any, error) {
+	dec := xml.NewDecoder(strings.NewReader(raw))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil, errors.New("plist: no root value")
+			}
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local != "plist" {
+			return decodePlistValue(dec, start)
+		}
+	}
+}
+
+func decodePlistValue(dec *xml.Decoder, start xml.StartElement) (any, error) {
+	switch start.Name.Local {
+	case "dict":
+		dict := make(map[string]any)
+		var key string
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if t.Name.Local == "key" {
+					if err := dec.DecodeElement(&key, &t); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				value, err := decodePlistValue(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				dict[key] = value
+			case xml.EndElement:
+				return dict, nil
+			}
+		}
+	case "array":
+		var list []any
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				value, err := decodePlistValue(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			case xml.EndElement:
+				return list, nil
+			}
+		}
+	case "true", "false":
+		if err := dec.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	}
+
+	var text string
+	if err := dec.DecodeElement(&text, &start); err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+	switch start.Name.Local {
+	case "integer":
+		if n, err := strconv.ParseUint(text, 10, 64); err == nil {
+			return n, nil
+		}
+		// Negative integers are rare in the keys we read; keep them signed.
+		return strconv.ParseInt(text, 10, 64)
+	case "real":
+		return strconv.ParseFloat(text, 64)
+	}
+	return text, nil
+}
+
+func plistString(dict map[string]any, key string) string {
+	s, _ := dict[key].(string)
+	return s
+}
+
+func plistUint(dict map[string]any, key string) uint64 {
+	n, _ := dict[key].(uint64)
+	return n
+}
+
+func plistDicts(dict map[string]any, key string) []map[string]any {
+	list, _ := dict[key].([]any)
+	out := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		if d, ok := item.(map[string]any); ok {
+			out = append(out, d)
+		}
+	}
+	return out
+}
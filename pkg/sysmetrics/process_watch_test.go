@@ -1,4 +1,4 @@
-package main
+package sysmetrics
 
 import (
 	"encoding/json"
@@ -76,7 +76,7 @@ func TestTopProcessesSortsByCPU(t *testing.T) {
 		{PID: 2, Name: "mid", CPU: 120, Memory: 8},
 	}
 
-	top := topProcesses(procs, 2)
+	top := topProcesses(procs, 2, ProcessSortCPU)
 	if len(top) != 2 {
 		t.Fatalf("topProcesses() len = %d, want 2", len(top))
 	}
@@ -85,6 +85,49 @@ func TestTopProcessesSortsByCPU(t *testing.T) {
 	}
 }
 
+func TestTopProcessesSortsByMemoryAndEnergy(t *testing.T) {
+	procs := []ProcessInfo{
+		{PID: 1, Name: "compiler", CPU: 180, MemoryBytes: 900 << 20},
+		{PID: 2, Name: "browser", CPU: 12, MemoryBytes: 3 << 30, Energy: 48},
+		{PID: 3, Name: "indexer", CPU: 40, MemoryBytes: 200 << 20, Energy: 95},
+	}
+
+	if top := topProcesses(procs, 1, ProcessSortMemory); top[0].Name != "browser" {
+		t.Fatalf("mem top = %+v, want browser", top)
+	}
+	if top := topProcesses(procs, 1, ProcessSortEnergy); top[0].Name != "indexer" {
+		t.Fatalf("energy top = %+v, want indexer", top)
+	}
+
+	sorted := SortProcesses(procs, ProcessSortEnergy)
+	if sorted[0].PID != 3 || sorted[1].PID != 2 || sorted[2].PID != 1 {
+		t.Fatalf("SortProcesses() = %+v", sorted)
+	}
+	if procs[0].PID != 1 {
+		t.Fatal("SortProcesses() should not reorder its input")
+	}
+	if NextProcessSort(ProcessSortEnergy) != ProcessSortCPU || NextProcessSort("") != ProcessSortCPU {
+		t.Fatal("NextProcessSort() should wrap back to cpu")
+	}
+}
+
+func TestParseTopPowerUsesLastSample(t *testing.T) {
+	out := `Processes: 512 total
+PID    POWER
+412    0.0
+88     0.0
+Processes: 512 total
+PID    POWER
+412    37.5
+88     2.1
+9      0.0
+`
+	energy := parseTopPower(out)
+	if len(energy) != 2 || energy[412] != 37.5 || energy[88] != 2.1 {
+		t.Fatalf("parseTopPower() = %v", energy)
+	}
+}
+
 func TestProcessNameFromCommand(t *testing.T) {
 	tests := []struct {
 		command string
@@ -188,27 +231,6 @@ func TestProcessWatcherResetsOnPIDReuse(t *testing.T) {
 	}
 }
 
-func TestRenderProcessAlertBar(t *testing.T) {
-	alerts := []ProcessAlert{
-		{PID: 10, Name: "node", CPU: 150, Threshold: 100, Window: "5m0s", Status: "active"},
-		{PID: 11, Name: "java", CPU: 130, Threshold: 100, Window: "5m0s", Status: "active"},
-	}
-
-	bar := renderProcessAlertBar(alerts, 120)
-	if !strings.Contains(bar, "ALERT") {
-		t.Fatalf("missing alert prefix: %q", bar)
-	}
-	if !strings.Contains(bar, "node (10)") {
-		t.Fatalf("missing lead process label: %q", bar)
-	}
-	if !strings.Contains(bar, "+1 more") {
-		t.Fatalf("missing additional alert count: %q", bar)
-	}
-	if strings.Contains(bar, "terminate") || strings.Contains(bar, "ignore") {
-		t.Fatalf("unexpected action text in read-only alert bar: %q", bar)
-	}
-}
-
 func TestMetricsSnapshotJSONIncludesProcessWatch(t *testing.T) {
 	snapshot := MetricsSnapshot{
 		ProcessWatch: ProcessWatchConfig{
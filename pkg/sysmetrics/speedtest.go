@@ -0,0 +1,96 @@
+package sysmetrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	speedTestDownloadBytes = 25_000_000
+	speedTestUploadBytes   = 10_000_000
+	speedTestHistorySize   = 10
+)
+
+var (
+	speedTestDownURL = "https://speed.cloudflare.com/__down?bytes=%d"
+	speedTestUpURL   = "https://speed.cloudflare.com/__up"
+	speedTestClient  = &http.Client{}
+)
+
+// RunSpeedTest measures latency with an empty download, then download and
+// upload throughput with fixed-size transfers against Cloudflare's endpoint.
+// It transfers about 35 MB; bound it with ctx.
+func RunSpeedTest(ctx context.Context) (SpeedTestResult, error) {
+	result := SpeedTestResult{At: time.Now(), Server: "speed.cloudflare.com"}
+
+	start := time.Now()
+	if _, err := speedTestTransfer(ctx, http.MethodGet, fmt.Sprintf(speedTestDownURL, 0), nil); err != nil {
+		return SpeedTestResult{}, fmt.Errorf("speed test latency: %w", err)
+	}
+	result.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+
+	start = time.Now()
+	n, err := speedTestTransfer(ctx, http.MethodGet, fmt.Sprintf(speedTestDownURL, speedTestDownloadBytes), nil)
+	if err != nil {
+		return SpeedTestResult{}, fmt.Errorf("speed test download: %w", err)
+	}
+	result.DownloadMbps = mbps(n, time.Since(start))
+
+	start = time.Now()
+	if _, err := speedTestTransfer(ctx, http.MethodPost, speedTestUpURL, make([]byte, speedTestUploadBytes)); err != nil {
+		return SpeedTestResult{}, fmt.Errorf("speed test upload: %w", err)
+	}
+	result.UploadMbps = mbps(speedTestUploadBytes, time.Since(start))
+	return result, nil
+}
+
+func speedTestTransfer(ctx context.Context, method, url string, body []byte) (int64, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := speedTestClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%s", resp.Status)
+	}
+	return io.Copy(io.Discard, resp.Body)
+}
+
+func mbps(n int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(n) * 8 / elapsed.Seconds() / 1e6
+}
+
+// SetSpeedTests seeds the results carried on every snapshot.
+func (c *Collector) SetSpeedTests(history []SpeedTestResult) {
+	c.watchMu.Lock()
+	c.speedTests = history
+	c.watchMu.Unlock()
+}
+
+// AddSpeedTest appends a result, keeping the newest speedTestHistorySize,
+// and returns the updated history.
+func (c *Collector) AddSpeedTest(result SpeedTestResult) []SpeedTestResult {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	history := append(c.speedTests, result)
+	if len(history) > speedTestHistorySize {
+		history = history[len(history)-speedTestHistorySize:]
+	}
+	c.speedTests = history
+	return history
+}
@@ -0,0 +1,66 @@
+package sysmetrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRunSpeedTestMeasuresTransfers(t *testing.T) {
+	var uploaded int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			uploaded, _ = io.Copy(io.Discard, r.Body)
+			return
+		}
+		n, _ := strconv.Atoi(r.URL.Query().Get("bytes"))
+		_, _ = w.Write(make([]byte, n))
+	}))
+	t.Cleanup(server.Close)
+
+	origDown, origUp := speedTestDownURL, speedTestUpURL
+	t.Cleanup(func() { speedTestDownURL, speedTestUpURL = origDown, origUp })
+	speedTestDownURL = server.URL + "/down?bytes=%d"
+	speedTestUpURL = server.URL + "/up"
+
+	result, err := RunSpeedTest(context.Background())
+	if err != nil {
+		t.Fatalf("RunSpeedTest() error = %v", err)
+	}
+	if result.DownloadMbps <= 0 || result.UploadMbps <= 0 || result.LatencyMs <= 0 {
+		t.Fatalf("RunSpeedTest() = %+v", result)
+	}
+	if uploaded != speedTestUploadBytes {
+		t.Fatalf("uploaded %d bytes, want %d", uploaded, speedTestUploadBytes)
+	}
+}
+
+func TestRunSpeedTestReportsHTTPErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	origDown := speedTestDownURL
+	t.Cleanup(func() { speedTestDownURL = origDown })
+	speedTestDownURL = server.URL + "/down?bytes=%d"
+
+	if _, err := RunSpeedTest(context.Background()); err == nil {
+		t.Fatal("RunSpeedTest() should fail on a non-200 response")
+	}
+}
+
+func TestAddSpeedTestKeepsNewestResults(t *testing.T) {
+	c := NewCollector(ProcessWatchOptions{})
+	var history []SpeedTestResult
+	for i := range speedTestHistorySize + 2 {
+		history = c.AddSpeedTest(SpeedTestResult{At: time.Unix(int64(i), 0), DownloadMbps: float64(i)})
+	}
+	if len(history) != speedTestHistorySize || history[0].DownloadMbps != 2 {
+		t.Fatalf("history = %+v", history)
+	}
+}
@@ -0,0 +1,62 @@
+package sysmetrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tw93/mole/internal/debuglog"
+)
+
+// staleCache serves the last good value straight away and, once it is older
+// than the caller's TTL, refreshes it on a background goroutine. Only the
+// very first read waits for a value. One refresh runs at a time, so refresh
+// may keep its own state without locking.
+type staleCache[T any] struct {
+	name       string // For the --debug log
+	mu         sync.Mutex
+	value      T
+	at         time.Time // When value was collected; zero until the first refresh
+	tried      time.Time // Last refresh, successful or not
+	refreshing bool
+}
+
+// Get returns the cached value and when it was collected. refresh reports
+// false to keep the previous value, e.g. when a tool fails.
+func (c *staleCache[T]) Get(now time.Time, ttl time.Duration, refresh func(time.Time) (T, bool)) (T, time.Time) {
+	c.mu.Lock()
+	first := c.tried.IsZero()
+	switch {
+	case c.refreshing:
+	case first:
+		c.refreshing, c.tried = true, now
+		c.mu.Unlock()
+		c.refresh(now, refresh)
+		c.mu.Lock()
+	case now.Sub(c.tried) >= ttl:
+		// A failing source is retried once per TTL, not on every read.
+		c.refreshing, c.tried = true, now
+		go c.refresh(now, refresh)
+	}
+	defer c.mu.Unlock()
+	debuglog.Cache(c.name, !first, now.Sub(c.at))
+	return c.value, c.at
+}
+
+// Peek returns the cached value without refreshing it.
+func (c *staleCache[T]) Peek() (T, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value, c.at
+}
+
+func (c *staleCache[T]) refresh(now time.Time, refresh func(time.Time) (T, bool)) {
+	value, ok := refresh(now)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshing = false
+	// Until something has been read, a failed read's value (usually the
+	// caller's placeholder) is better than nothing.
+	if ok || c.at.IsZero() {
+		c.value, c.at = value, now
+	}
+}
@@ -0,0 +1,65 @@
+package sysmetrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStaleCacheServesOldValueWhileRefreshing(t *testing.T) {
+	var c staleCache[int]
+	at := time.Date(2026, 3, 12, 9, 0, 0, 0, time.UTC)
+	release := make(chan struct{})
+	refreshed := make(chan struct{})
+	calls := 0
+	refresh := func(time.Time) (int, bool) {
+		calls++
+		if calls == 1 {
+			return 1, true
+		}
+		<-release
+		defer close(refreshed)
+		return 2, true
+	}
+
+	// The first read waits for a value.
+	if v, got := c.Get(at, time.Minute, refresh); v != 1 || !got.Equal(at) {
+		t.Fatalf("first Get = %d at %v", v, got)
+	}
+	if v, _ := c.Get(at.Add(30*time.Second), time.Minute, refresh); v != 1 || calls != 1 {
+		t.Fatalf("fresh value should be served without refreshing, got %d after %d calls", v, calls)
+	}
+
+	// Once stale, the old value comes back at once and one refresh starts.
+	later := at.Add(2 * time.Minute)
+	if v, got := c.Get(later, time.Minute, refresh); v != 1 || !got.Equal(at) {
+		t.Fatalf("stale Get = %d at %v, want the old value", v, got)
+	}
+	if v, _ := c.Get(later, time.Minute, refresh); v != 1 {
+		t.Fatalf("Get during refresh = %d", v)
+	}
+	close(release)
+	<-refreshed
+	for range 100 {
+		if v, got := c.Peek(); v == 2 && got.Equal(later) {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("background refresh never landed")
+}
+
+func TestStaleCacheKeepsValueWhenRefreshFails(t *testing.T) {
+	var c staleCache[string]
+	at := time.Date(2026, 3, 12, 9, 0, 0, 0, time.UTC)
+	c.Get(at, time.Minute, func(time.Time) (string, bool) { return "No Bluetooth info", false })
+	if v, _ := c.Peek(); v != "No Bluetooth info" {
+		t.Fatalf("first failed read should still fill the cache, got %q", v)
+	}
+
+	c = staleCache[string]{}
+	c.Get(at, time.Minute, func(time.Time) (string, bool) { return "AirPods", true })
+	c.refresh(at.Add(time.Hour), func(time.Time) (string, bool) { return "", false })
+	if v, got := c.Peek(); v != "AirPods" || !got.Equal(at) {
+		t.Fatalf("failed refresh replaced %q at %v", v, got)
+	}
+}
@@ -85,11 +85,11 @@ if [[ "$MODE" == "format" ]]; then
 
     if command -v goimports > /dev/null 2>&1; then
         echo -e "${YELLOW}Formatting Go code, goimports...${NC}"
-        goimports -w -local github.com/tw93/mole ./cmd ./internal
+        goimports -w -local github.com/tw93/mole ./cmd ./internal ./pkg
         echo -e "${GREEN}${ICON_SUCCESS} Go formatting complete${NC}\n"
     elif command -v go > /dev/null 2>&1; then
         echo -e "${YELLOW}Formatting Go code, gofmt...${NC}"
-        gofmt -w ./cmd ./internal
+        gofmt -w ./cmd ./internal ./pkg
         echo -e "${GREEN}${ICON_SUCCESS} Go formatting complete${NC}\n"
     else
         echo -e "${YELLOW}${ICON_WARNING} go not installed, skipping gofmt${NC}\n"
@@ -110,11 +110,11 @@ if [[ "$MODE" != "check" ]]; then
 
     if command -v goimports > /dev/null 2>&1; then
         echo -e "${YELLOW}2. Formatting Go code, goimports...${NC}"
-        goimports -w -local github.com/tw93/mole ./cmd ./internal
+        goimports -w -local github.com/tw93/mole ./cmd ./internal ./pkg
         echo -e "${GREEN}${ICON_SUCCESS} Go formatting applied${NC}\n"
     elif command -v go > /dev/null 2>&1; then
         echo -e "${YELLOW}2. Formatting Go code, gofmt...${NC}"
-        gofmt -w ./cmd ./internal
+        gofmt -w ./cmd ./internal ./pkg
         echo -e "${GREEN}${ICON_SUCCESS} Go formatting applied${NC}\n"
     fi
 fi
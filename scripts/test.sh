@@ -123,8 +123,11 @@ prepare_go_test_helpers() {
     TEST_GO_HELPER_DIR="$(mktemp -d "${TMPDIR:-/tmp}/mole-go-helpers.XXXXXX")"
     mkdir -p "$GO_TEST_CACHE"
 
-    if GOCACHE="$GO_TEST_CACHE" go build -o "$TEST_GO_HELPER_DIR/analyze-go" ./cmd/analyze > /dev/null 2>&1 &&
-        GOCACHE="$GO_TEST_CACHE" go build -o "$TEST_GO_HELPER_DIR/status-go" ./cmd/status > /dev/null 2>&1; then
+    # One mole binary under both names, as the Makefile links it; the name
+    # picks the command.
+    if GOCACHE="$GO_TEST_CACHE" go build -o "$TEST_GO_HELPER_DIR/mole-go" ./cmd/mole > /dev/null 2>&1 &&
+        ln -f "$TEST_GO_HELPER_DIR/mole-go" "$TEST_GO_HELPER_DIR/analyze-go" &&
+        ln -f "$TEST_GO_HELPER_DIR/mole-go" "$TEST_GO_HELPER_DIR/status-go"; then
         export MOLE_TEST_ANALYZE_BIN="$TEST_GO_HELPER_DIR/analyze-go"
         export MOLE_TEST_STATUS_BIN="$TEST_GO_HELPER_DIR/status-go"
     else
@@ -89,7 +89,7 @@ setup() {
 @test "completion bash includes current clean, analyze, history, and purge options only" {
 	run "$PROJECT_ROOT/bin/completion.sh" bash
 	[ "$status" -eq 0 ]
-	[[ "$output" == *"--dry-run -n --external --whitelist --debug --help -h"* ]]
+	[[ "$output" == *"--dry-run -n --external --whitelist --rules --debug --help -h"* ]]
 	[[ "$output" == *"--json --help -h"* ]]
 	[[ "$output" == *"--json --limit --help -h"* ]]
 	[[ "$output" == *"--paths --dry-run -n --include-empty --debug --help -h"* ]]
@@ -190,6 +190,17 @@ setup() {
 	[[ "$output" == *"updated"* ]]
 }
 
+@test "completion auto-install keeps the aliases line" {
+	mkdir -p "$HOME"
+	# shellcheck disable=SC2016
+	printf '%s\n' 'eval "$(mole completion zsh)"' 'eval "$(mole completion --aliases zsh)"' >"$HOME/.zshrc"
+
+	run env SHELL=/bin/zsh "$PROJECT_ROOT/bin/completion.sh"
+	[ "$status" -eq 0 ]
+	grep -q -- "--aliases zsh" "$HOME/.zshrc"
+	[ "$(grep -c "completion zsh" "$HOME/.zshrc")" -eq 1 ]
+}
+
 @test "completion --dry-run previews changes without writing config" {
 	run env SHELL=/bin/zsh "$PROJECT_ROOT/bin/completion.sh" --dry-run
 	[ "$status" -eq 0 ]
@@ -212,3 +223,65 @@ setup() {
 	run "$PROJECT_ROOT/bin/completion.sh" fish
 	[ "$status" -eq 0 ]
 }
+
+@test "completion --aliases prints shortcuts for each shell" {
+	run "$PROJECT_ROOT/bin/completion.sh" --aliases zsh
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"alias moc='mo clean'"* ]]
+	[[ "$output" == *"\${+commands[moa]}"* ]]
+
+	run "$PROJECT_ROOT/bin/completion.sh" --aliases fish
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"type -q mos; or abbr -a mos 'mo status'"* ]]
+
+	run env SHELL=/bin/bash "$PROJECT_ROOT/bin/completion.sh" --aliases
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"complete -F _mole_alias_mop mop"* ]]
+
+	run "$PROJECT_ROOT/bin/completion.sh" --aliases tcsh
+	[ "$status" -ne 0 ]
+}
+
+@test "completion bash completes aliases as their command" {
+	run bash -c "eval \"\$(\"$PROJECT_ROOT/bin/completion.sh\" bash)\"; eval \"\$(\"$PROJECT_ROOT/bin/completion.sh\" --aliases bash)\"; COMP_WORDS=(moc --d); COMP_CWORD=1; _mole_alias_moc; echo \"\${COMPREPLY[*]}\""
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"--dry-run --debug"* ]]
+}
+
+@test "completion bash completes config verbs" {
+	run bash -c "eval \"\$(\"$PROJECT_ROOT/bin/completion.sh\" bash)\"; COMP_WORDS=(mo config ''); COMP_CWORD=2; _mole_completions; echo \"\${COMPREPLY[*]}\""
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"path list get set --profile"* ]]
+}
+
+@test "completion completes --profile and analyze bookmarks from config" {
+	local root="$HOME/mole-copy"
+	mkdir -p "$root/bin"
+	cp -R "$PROJECT_ROOT/lib" "$root/"
+	cp "$PROJECT_ROOT/bin/completion.sh" "$root/bin/"
+	cat >"$root/bin/status-go" <<'STUB'
+#!/bin/bash
+[[ "$1" == "__profiles" ]] && printf 'battery\nwork\n'
+[[ "$1" == "__bookmarks" ]] && printf '/Volumes/Archive\n'
+exit 0
+STUB
+	chmod +x "$root/bin/status-go"
+
+	run bash -c "eval \"\$(\"$root/bin/completion.sh\" bash)\"; COMP_WORDS=(mo status --profile w); COMP_CWORD=3; _mole_completions; echo \"\${COMPREPLY[*]}\""
+	[ "$status" -eq 0 ]
+	[ "$output" = "work" ]
+
+	run bash -c "eval \"\$(\"$root/bin/completion.sh\" bash)\"; COMP_WORDS=(mo analyze /Volumes/Arc); COMP_CWORD=2; _mole_completions; echo \"\${COMPREPLY[*]}\""
+	[ "$status" -eq 0 ]
+	[ "$output" = "/Volumes/Archive" ]
+
+	run "$root/bin/completion.sh" zsh
+	[[ "$output" == *"_mole_profiles() {"* ]]
+	[[ "$output" == *"$root/bin/status-go __profiles"* ]]
+	[[ "$output" == *"$root/bin/status-go __bookmarks"* ]]
+
+	run "$root/bin/completion.sh" fish
+	[[ "$output" == *"-l profile -r -a \"($root/bin/status-go __profiles 2> /dev/null)\""* ]]
+	[[ "$output" == *"-a \"($root/bin/status-go __bookmarks 2> /dev/null)\" -d \"Saved folder\""* ]]
+	rm -rf "$root"
+}
@@ -0,0 +1,85 @@
+#!/usr/bin/env bats
+
+setup_file() {
+	PROJECT_ROOT="$(cd "${BATS_TEST_DIRNAME}/.." && pwd)"
+	export PROJECT_ROOT
+
+	ORIGINAL_HOME="${HOME:-}"
+	export ORIGINAL_HOME
+
+	HOME="$(mktemp -d "${BATS_TEST_DIRNAME}/tmp-orphans-home.XXXXXX")"
+	export HOME
+}
+
+teardown_file() {
+	if [[ "$HOME" == "${BATS_TEST_DIRNAME}/tmp-"* ]]; then
+		rm -rf "$HOME"
+	fi
+	if [[ -n "${ORIGINAL_HOME:-}" ]]; then
+		export HOME="$ORIGINAL_HOME"
+	fi
+}
+
+setup() {
+	# Safety: refuse to operate on a real home directory.
+	if [[ "$HOME" != "${BATS_TEST_DIRNAME}/tmp-"* ]]; then
+		printf 'FATAL: HOME is not a test temp dir: %s\n' "$HOME" >&2
+		return 1
+	fi
+	rm -rf "${HOME:?}/Library"
+	mkdir -p "$HOME/Library/Caches" "$HOME/Library/Application Support" "$HOME/Library/Preferences"
+}
+
+@test "orphans.sh rejects unknown options" {
+	run "$PROJECT_ROOT/bin/orphans.sh" --unknown-option
+
+	[ "$status" -eq 1 ]
+	[[ "$output" == *"Unknown option"* ]]
+}
+
+@test "orphans.sh reports an incomplete scan without Full Disk Access" {
+	rm -rf "$HOME/Library/Caches"
+
+	run "$PROJECT_ROOT/bin/orphans.sh" --list
+
+	[ "$status" -ne 0 ]
+	[[ "$output" == *"Scan incomplete"* ]]
+	[[ "$output" != *"No data left behind"* ]]
+}
+
+@test "scan_orphaned_data groups old data of uninstalled apps by bundle ID" {
+	mkdir -p "$HOME/Library/Caches/com.example.Gone" "$HOME/Library/Application Support/com.example.Gone"
+	mkdir -p "$HOME/Library/Caches/com.example.Installed" "$HOME/Library/Caches/com.example.Fresh"
+	mkdir -p "$HOME/Library/Application Support/Not A Bundle"
+	printf 'x%.0s' {1..5000} > "$HOME/Library/Caches/com.example.Gone/blob"
+	printf 'x%.0s' {1..5000} > "$HOME/Library/Application Support/com.example.Gone/blob"
+	printf 'x%.0s' {1..5000} > "$HOME/Library/Preferences/com.example.Gone.plist"
+	printf 'x%.0s' {1..5000} > "$HOME/Library/Caches/com.example.Installed/blob"
+	printf 'x%.0s' {1..5000} > "$HOME/Library/Caches/com.example.Fresh/blob"
+	printf 'x%.0s' {1..5000} > "$HOME/Library/Application Support/Not A Bundle/blob"
+	touch -t 202001010000 "$HOME/Library/Caches/com.example.Gone" \
+		"$HOME/Library/Application Support/com.example.Gone" \
+		"$HOME/Library/Preferences/com.example.Gone.plist" \
+		"$HOME/Library/Caches/com.example.Installed" \
+		"$HOME/Library/Application Support/Not A Bundle"
+
+	run env PATH="/usr/bin:/bin" bash -euo pipefail -c '
+        export MOLE_TEST_MODE=1
+        source "$1"
+        mdfind() { return 0; }
+        run_with_timeout() { shift; "$@"; }
+        installed=$(mktemp)
+        echo "com.example.Installed" > "$installed"
+        group_orphaned_data < <(scan_orphaned_data "$installed")
+        echo "count=${#ORPHAN_IDS[@]}"
+        echo "id=${ORPHAN_IDS[0]}"
+        echo "kinds=${ORPHAN_KINDS[0]}"
+        echo "paths=$(printf "%s\n" "${ORPHAN_PATHS[0]}" | wc -l | tr -d " ")"
+    ' bash "$PROJECT_ROOT/bin/orphans.sh"
+
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"count=1"* ]]
+	[[ "$output" == *"id=com.example.Gone"* ]]
+	[[ "$output" == *"kinds=Application Support, Caches, Preferences"* ]]
+	[[ "$output" == *"paths=3"* ]]
+}
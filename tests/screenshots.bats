@@ -0,0 +1,120 @@
+#!/usr/bin/env bats
+
+setup_file() {
+	PROJECT_ROOT="$(cd "${BATS_TEST_DIRNAME}/.." && pwd)"
+	export PROJECT_ROOT
+
+	ORIGINAL_HOME="${HOME:-}"
+	export ORIGINAL_HOME
+
+	HOME="$(mktemp -d "${BATS_TEST_DIRNAME}/tmp-screenshots-home.XXXXXX")"
+	export HOME
+}
+
+teardown_file() {
+	if [[ "$HOME" == "${BATS_TEST_DIRNAME}/tmp-"* ]]; then
+		rm -rf "$HOME"
+	fi
+	if [[ -n "${ORIGINAL_HOME:-}" ]]; then
+		export HOME="$ORIGINAL_HOME"
+	fi
+}
+
+setup() {
+	# Safety: refuse to operate on a real home directory.
+	if [[ "$HOME" != "${BATS_TEST_DIRNAME}/tmp-"* ]]; then
+		printf 'FATAL: HOME is not a test temp dir: %s\n' "$HOME" >&2
+		return 1
+	fi
+	rm -rf "${HOME:?}/Desktop" "${HOME:?}/Pictures"
+	mkdir -p "$HOME/Desktop"
+}
+
+@test "screenshots.sh rejects unknown options" {
+	run "$PROJECT_ROOT/bin/screenshots.sh" --unknown-option
+
+	[ "$status" -eq 1 ]
+	[[ "$output" == *"Unknown option"* ]]
+}
+
+@test "scan_screenshots finds captures on the Desktop and in the capture folder" {
+	mkdir -p "$HOME/Captures"
+	touch "$HOME/Desktop/Screenshot 2026-10-01 at 09.15.02.png" \
+		"$HOME/Desktop/Screen Shot 2019-04-02 at 10.00.00.png" \
+		"$HOME/Desktop/Screen Recording 2026-09-30 at 11.00.00.mov" \
+		"$HOME/Desktop/Screenshot notes.txt" \
+		"$HOME/Desktop/report.pdf" \
+		"$HOME/Captures/Snap 2026-10-02.png"
+
+	run env PATH="/usr/bin:/bin" bash -euo pipefail -c '
+        export MOLE_TEST_MODE=1
+        source "$1"
+        defaults() {
+            case "$*" in
+                *location*) echo "~/Captures" ;;
+                *name*) echo "Snap" ;;
+                *) return 1 ;;
+            esac
+        }
+        mdfind() { return 0; }
+        run_with_timeout() { shift; "$@"; }
+        screenshot_capture_dirs
+        dirs=()
+        while IFS= read -r dir; do dirs+=("$dir"); done < <(screenshot_capture_dirs)
+        scan_screenshots "${dirs[@]}" | cut -d"|" -f2,3 | sort
+    ' bash "$PROJECT_ROOT/bin/screenshots.sh"
+
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"$HOME/Desktop"$'\n'"$HOME/Captures"* ]]
+	[[ "$output" == *"screenshot|$HOME/Captures/Snap 2026-10-02.png"* ]]
+	[[ "$output" == *"recording|$HOME/Desktop/Screen Recording 2026-09-30 at 11.00.00.mov"* ]]
+	[[ "$output" == *"screenshot|$HOME/Desktop/Screen Shot 2019-04-02 at 10.00.00.png"* ]]
+	[[ "$output" != *"notes.txt"* ]]
+	[[ "$output" != *"report.pdf"* ]]
+}
+
+@test "group_screenshots buckets captures by age" {
+	run env PATH="/usr/bin:/bin" bash -euo pipefail -c '
+        export MOLE_TEST_MODE=1
+        source "$1"
+        now=$((400 * 86400))
+        group_screenshots "$now" <<EOF
+$((now - 2 * 86400))|screenshot|/d/a.png|100
+$((now - 3 * 86400))|recording|/d/b.mov|5000
+$((now - 200 * 86400))|screenshot|/d/c.png|300
+$((now - 399 * 86400))|screenshot|/d/d.png|200
+EOF
+        echo "labels=${SHOT_LABELS[*]}"
+        echo "counts=${SHOT_COUNTS[*]}"
+        echo "sizes=${SHOT_SIZES_KB[*]}"
+        echo "row=$(format_screenshot_row 0)"
+        echo "paths=$(printf "%s\n" "${SHOT_PATHS[1]}" | tr "\n" " ")"
+    ' bash "$PROJECT_ROOT/bin/screenshots.sh"
+
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"labels=This week Older than 6 months"* ]]
+	[[ "$output" == *"counts=2 2"* ]]
+	[[ "$output" == *"sizes=5100 500"* ]]
+	[[ "$output" == *"1 screenshot, 1 recording"* ]]
+	[[ "$output" == *"paths=/d/c.png /d/d.png "* ]]
+}
+
+@test "archive_screenshot files captures by month without overwriting" {
+	touch "$HOME/Desktop/Screenshot one.png"
+	mkdir -p "$HOME/Archive/2026-10"
+	touch "$HOME/Archive/2026-10/Screenshot one.png"
+
+	run env PATH="/usr/bin:/bin" bash -euo pipefail -c '
+        export MOLE_TEST_MODE=1
+        source "$1"
+        get_file_mtime() { echo 1791500000; }
+        date() { if [[ "$1" == "-r" ]]; then echo 2026-10; else command date "$@"; fi; }
+        SCREENSHOT_ARCHIVE_DIR="$HOME/Archive"
+        archive_screenshot "$HOME/Desktop/Screenshot one.png"
+    ' bash "$PROJECT_ROOT/bin/screenshots.sh"
+
+	[ "$status" -eq 0 ]
+	[ ! -e "$HOME/Desktop/Screenshot one.png" ]
+	[ -f "$HOME/Archive/2026-10/Screenshot one.png" ]
+	[ -f "$HOME/Archive/2026-10/Screenshot one 2.png" ]
+}
@@ -1818,6 +1818,25 @@ EOF
 	[[ "$output" == *"Test Application"* ]]
 }
 
+@test "match_apps_by_name finds by exact bundle ID" {
+	run bash --noprofile --norc <<'EOF'
+set -euo pipefail
+selected_apps=()
+apps_data=(
+	"1000|$HOME/Applications/TestApp.app|TestApp|com.example.TestApp|1.2 GB|1000000|1258291"
+	"1001|$HOME/Applications/Other.app|Other|com.example.Other|500 MB|1000001|512000"
+)
+source "$PROJECT_ROOT/tests/test_match_apps_helper.sh"
+match_apps_by_name "com.example.testapp"
+echo "count=${#selected_apps[@]}"
+echo "match=${selected_apps[0]}"
+EOF
+
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"count=1"* ]]
+	[[ "$output" == *"|TestApp|"* ]]
+}
+
 @test "match_apps_by_name warns on no match" {
 	run bash --noprofile --norc <<'EOF'
 set -euo pipefail